@@ -0,0 +1,424 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"github.com/tailscale/win"
+)
+
+// Anchors specifies the edges of its container a widget is anchored to.
+type Anchors uint8
+
+const (
+	AnchorLeft Anchors = 1 << iota
+	AnchorTop
+	AnchorRight
+	AnchorBottom
+
+	AnchorNone Anchors = 0
+	AnchorAll          = AnchorLeft | AnchorTop | AnchorRight | AnchorBottom
+)
+
+type anchorLayoutInfo struct {
+	anchors Anchors
+	bounds  Rectangle // in 1/96" units
+	margins Margins   // in 1/96" units, negative values are derived from bounds
+}
+
+var defaultAnchorLayoutInfo = anchorLayoutInfo{
+	anchors: AnchorLeft | AnchorTop,
+	margins: Margins{-1, -1, -1, -1},
+}
+
+// AnchorLayout positions widgets at absolute design-time bounds and keeps
+// the distance of each widget to the container edges it is anchored to
+// constant when the container is resized.
+//
+// A widget anchored to two opposite edges grows and shrinks with the
+// container. A widget anchored to neither of two opposite edges keeps its
+// position relative to the center of the container along that axis.
+type AnchorLayout struct {
+	LayoutBase
+	designSize96dpi Size
+	hwnd2Info       map[win.HWND]anchorLayoutInfo
+}
+
+func NewAnchorLayout() *AnchorLayout {
+	l := &AnchorLayout{
+		hwnd2Info: make(map[win.HWND]anchorLayoutInfo),
+	}
+	l.layout = l
+
+	return l
+}
+
+// DesignSize returns the size of the area inside the margins the item bounds
+// were designed for, in 1/96" units.
+func (l *AnchorLayout) DesignSize() Size {
+	return l.designSize96dpi
+}
+
+// SetDesignSize sets the size of the area inside the margins the item bounds
+// were designed for, in 1/96" units.
+//
+// If no design size is set, the extent of all item bounds is used.
+func (l *AnchorLayout) SetDesignSize(value Size) error {
+	if value == l.designSize96dpi {
+		return nil
+	}
+
+	if value.Width < 0 || value.Height < 0 {
+		return newError("design size cannot be negative")
+	}
+
+	l.designSize96dpi = value
+
+	if l.container != nil {
+		l.container.RequestLayout()
+	}
+
+	return nil
+}
+
+// Anchors returns the edges widget is anchored to. The default is
+// AnchorLeft | AnchorTop.
+func (l *AnchorLayout) Anchors(widget Widget) Anchors {
+	return l.info(widget).anchors
+}
+
+// SetAnchors sets the edges widget is anchored to.
+func (l *AnchorLayout) SetAnchors(widget Widget, anchors Anchors) error {
+	if anchors&^AnchorAll != 0 {
+		return newError("invalid Anchors value")
+	}
+
+	return l.updateInfo(widget, func(info *anchorLayoutInfo) {
+		info.anchors = anchors
+	})
+}
+
+// ItemBounds returns the design-time bounds of widget in 1/96" units.
+func (l *AnchorLayout) ItemBounds(widget Widget) Rectangle {
+	return l.info(widget).bounds
+}
+
+// SetItemBounds sets the design-time bounds of widget in 1/96" units,
+// relative to the area inside the margins.
+//
+// A zero width or height is replaced with the minimum width or height of
+// widget.
+func (l *AnchorLayout) SetItemBounds(widget Widget, bounds Rectangle) error {
+	if bounds.Width < 0 || bounds.Height < 0 {
+		return newError("bounds size cannot be negative")
+	}
+
+	return l.updateInfo(widget, func(info *anchorLayoutInfo) {
+		info.bounds = bounds
+	})
+}
+
+// ItemMargins returns the fixed distances of widget to the edges of the area
+// inside the margins in 1/96" units.
+func (l *AnchorLayout) ItemMargins(widget Widget) Margins {
+	return l.info(widget).margins
+}
+
+// SetItemMargins sets fixed distances of widget to the edges of the area
+// inside the margins in 1/96" units. They are only used for anchored edges.
+//
+// A negative value derives the distance from the item bounds and the design
+// size, which is the default.
+func (l *AnchorLayout) SetItemMargins(widget Widget, margins Margins) error {
+	return l.updateInfo(widget, func(info *anchorLayoutInfo) {
+		info.margins = margins
+	})
+}
+
+func (l *AnchorLayout) info(widget Widget) anchorLayoutInfo {
+	if info, ok := l.hwnd2Info[widget.Handle()]; ok {
+		return info
+	}
+
+	return defaultAnchorLayoutInfo
+}
+
+func (l *AnchorLayout) updateInfo(widget Widget, update func(info *anchorLayoutInfo)) error {
+	if l.container == nil {
+		return newError("container required")
+	}
+
+	handle := widget.Handle()
+
+	if !l.container.Children().containsHandle(handle) {
+		return newError("unknown widget")
+	}
+
+	info := l.info(widget)
+	update(&info)
+
+	if info == l.hwnd2Info[handle] {
+		return nil
+	}
+
+	l.hwnd2Info[handle] = info
+
+	l.container.RequestLayout()
+
+	return nil
+}
+
+func (l *AnchorLayout) CreateLayoutItem(ctx *LayoutContext) ContainerLayoutItem {
+	li := &anchorLayoutItem{
+		designSize96dpi: l.designSize96dpi,
+		hwnd2Info:       make(map[win.HWND]anchorLayoutInfo),
+	}
+
+	for hwnd, info := range l.hwnd2Info {
+		li.hwnd2Info[hwnd] = info
+	}
+
+	return li
+}
+
+type anchorLayoutItem struct {
+	ContainerLayoutItemBase
+	designSize96dpi Size
+	hwnd2Info       map[win.HWND]anchorLayoutInfo
+}
+
+// anchorLayoutItemGeometry holds the design-time geometry of an item in
+// native pixels.
+type anchorLayoutItemGeometry struct {
+	item    LayoutItem
+	anchors Anchors
+	bounds  Rectangle
+	margins Margins // negative values are derived from bounds
+	minSize Size
+	maxSize Size
+}
+
+func (li *anchorLayoutItem) LayoutFlags() LayoutFlags {
+	flags := ShrinkableHorz | ShrinkableVert | GrowableHorz | GrowableVert
+
+	for _, g := range li.itemGeometries() {
+		if g.anchors&(AnchorLeft|AnchorRight) == AnchorLeft|AnchorRight {
+			flags |= GreedyHorz
+		}
+		if g.anchors&(AnchorTop|AnchorBottom) == AnchorTop|AnchorBottom {
+			flags |= GreedyVert
+		}
+	}
+
+	return flags
+}
+
+func (li *anchorLayoutItem) IdealSize() Size {
+	margins := MarginsFrom96DPI(li.margins96dpi, li.ctx.dpi)
+	design := li.designSize(li.itemGeometries())
+
+	min := li.MinSize()
+
+	return Size{
+		maxi(min.Width, design.Width+margins.HNear+margins.HFar),
+		maxi(min.Height, design.Height+margins.VNear+margins.VFar),
+	}
+}
+
+func (li *anchorLayoutItem) MinSize() Size {
+	return li.MinSizeForSize(li.geometry.ClientSize)
+}
+
+func (li *anchorLayoutItem) HasHeightForWidth() bool {
+	return false
+}
+
+func (li *anchorLayoutItem) HeightForWidth(width int) int {
+	return li.MinSizeForSize(Size{width, li.geometry.ClientSize.Height}).Height
+}
+
+func (li *anchorLayoutItem) MinSizeForSize(size Size) Size {
+	margins := MarginsFrom96DPI(li.margins96dpi, li.ctx.dpi)
+
+	geometries := li.itemGeometries()
+	design := li.designSize(geometries)
+
+	var s Size
+
+	for _, g := range geometries {
+		s.Width = maxi(s.Width, anchorAxisMinSize(g.bounds.X, g.bounds.Width, g.minSize.Width, design.Width, g.margins.HNear, g.margins.HFar, g.anchors&AnchorLeft != 0, g.anchors&AnchorRight != 0))
+		s.Height = maxi(s.Height, anchorAxisMinSize(g.bounds.Y, g.bounds.Height, g.minSize.Height, design.Height, g.margins.VNear, g.margins.VFar, g.anchors&AnchorTop != 0, g.anchors&AnchorBottom != 0))
+	}
+
+	s.Width += margins.HNear + margins.HFar
+	s.Height += margins.VNear + margins.VFar
+
+	return s
+}
+
+func (li *anchorLayoutItem) PerformLayout() []LayoutResultItem {
+	margins := MarginsFrom96DPI(li.margins96dpi, li.ctx.dpi)
+
+	geometries := li.itemGeometries()
+	design := li.designSize(geometries)
+
+	avail := Size{
+		li.geometry.ClientSize.Width - margins.HNear - margins.HFar,
+		li.geometry.ClientSize.Height - margins.VNear - margins.VFar,
+	}
+
+	results := make([]LayoutResultItem, 0, len(geometries))
+
+	for _, g := range geometries {
+		x, w := anchorAxis(g.bounds.X, g.bounds.Width, g.minSize.Width, g.maxSize.Width, design.Width, avail.Width, g.margins.HNear, g.margins.HFar, g.anchors&AnchorLeft != 0, g.anchors&AnchorRight != 0)
+		y, h := anchorAxis(g.bounds.Y, g.bounds.Height, g.minSize.Height, g.maxSize.Height, design.Height, avail.Height, g.margins.VNear, g.margins.VFar, g.anchors&AnchorTop != 0, g.anchors&AnchorBottom != 0)
+
+		results = append(results, LayoutResultItem{
+			Item:   g.item,
+			Bounds: Rectangle{X: margins.HNear + x, Y: margins.VNear + y, Width: w, Height: h},
+		})
+	}
+
+	return results
+}
+
+// itemGeometries returns the design-time geometry of the items to lay out in
+// native pixels.
+func (li *anchorLayoutItem) itemGeometries() []anchorLayoutItemGeometry {
+	var geometries []anchorLayoutItemGeometry
+
+	for _, item := range li.children {
+		if !shouldLayoutItem(item) {
+			continue
+		}
+
+		info, ok := li.hwnd2Info[item.Handle()]
+		if !ok {
+			info = defaultAnchorLayoutInfo
+		}
+
+		g := anchorLayoutItemGeometry{
+			item:    item,
+			anchors: info.anchors,
+			bounds:  RectangleFrom96DPI(info.bounds, li.ctx.dpi),
+			minSize: li.MinSizeEffectiveForChild(item),
+			maxSize: item.Geometry().MaxSize,
+		}
+
+		if g.bounds.Width == 0 {
+			g.bounds.Width = g.minSize.Width
+		}
+		if g.bounds.Height == 0 {
+			g.bounds.Height = g.minSize.Height
+		}
+
+		g.margins = Margins{
+			anchorMarginFrom96DPI(info.margins.HNear, li.ctx.dpi),
+			anchorMarginFrom96DPI(info.margins.VNear, li.ctx.dpi),
+			anchorMarginFrom96DPI(info.margins.HFar, li.ctx.dpi),
+			anchorMarginFrom96DPI(info.margins.VFar, li.ctx.dpi),
+		}
+
+		geometries = append(geometries, g)
+	}
+
+	return geometries
+}
+
+// designSize returns the design size in native pixels.
+func (li *anchorLayoutItem) designSize(geometries []anchorLayoutItemGeometry) Size {
+	if li.designSize96dpi.Width > 0 && li.designSize96dpi.Height > 0 {
+		return SizeFrom96DPI(li.designSize96dpi, li.ctx.dpi)
+	}
+
+	var s Size
+	for _, g := range geometries {
+		s.Width = maxi(s.Width, g.bounds.X+g.bounds.Width+maxi(0, g.margins.HFar))
+		s.Height = maxi(s.Height, g.bounds.Y+g.bounds.Height+maxi(0, g.margins.VFar))
+	}
+
+	if li.designSize96dpi.Width > 0 {
+		s.Width = IntFrom96DPI(li.designSize96dpi.Width, li.ctx.dpi)
+	}
+	if li.designSize96dpi.Height > 0 {
+		s.Height = IntFrom96DPI(li.designSize96dpi.Height, li.ctx.dpi)
+	}
+
+	return s
+}
+
+func anchorMarginFrom96DPI(value, dpi int) int {
+	if value < 0 {
+		return -1
+	}
+
+	return IntFrom96DPI(value, dpi)
+}
+
+// anchorDistances returns the distances of an item to the near and far edges
+// along one axis. All values are in native pixels.
+func anchorDistances(pos, size, design, nearMargin, farMargin int) (near, far int) {
+	near = pos
+	if nearMargin >= 0 {
+		near = nearMargin
+	}
+
+	far = design - pos - size
+	if farMargin >= 0 {
+		far = farMargin
+	}
+
+	return
+}
+
+// anchorAxis returns the position and size of an item along one axis. All
+// values are in native pixels.
+func anchorAxis(pos, size, min, max, design, avail, nearMargin, farMargin int, anchorNear, anchorFar bool) (int, int) {
+	near, far := anchorDistances(pos, size, design, nearMargin, farMargin)
+
+	if anchorNear && anchorFar {
+		size = avail - near - far
+	}
+
+	if size < min {
+		size = min
+	}
+	if max > 0 && size > max {
+		size = max
+	}
+
+	switch {
+	case anchorNear:
+		return near, size
+
+	case anchorFar:
+		return avail - far - size, size
+
+	default:
+		return pos + (avail-design)/2, size
+	}
+}
+
+// anchorAxisMinSize returns the minimum extent of the area inside the margins
+// an item needs along one axis. All values are in native pixels.
+func anchorAxisMinSize(pos, size, min, design, nearMargin, farMargin int, anchorNear, anchorFar bool) int {
+	near, far := anchorDistances(pos, size, design, nearMargin, farMargin)
+
+	switch {
+	case anchorNear && anchorFar:
+		return maxi(0, near) + min + maxi(0, far)
+
+	case anchorNear:
+		return maxi(0, near) + maxi(size, min)
+
+	case anchorFar:
+		return maxi(size, min) + maxi(0, far)
+
+	default:
+		return maxi(size, min)
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"syscall"
+	"unsafe"
+
+	"github.com/tailscale/win"
+)
+
+// AnnouncementPriority specifies how urgently screen readers speak an
+// announcement made with Announce.
+type AnnouncementPriority int
+
+const (
+	// AnnouncementPolite announces the text once the screen reader finished
+	// speaking. Of several polite announcements that arrive meanwhile, only
+	// the last one is spoken, so frequent status updates do not pile up.
+	AnnouncementPolite AnnouncementPriority = iota
+
+	// AnnouncementAssertive interrupts what the screen reader is speaking.
+	// It should be reserved for announcements that need immediate attention,
+	// like a lost connection.
+	AnnouncementAssertive
+)
+
+// liveSettingPropID mirrors LiveSetting_Property_GUID, which Dynamic
+// Annotation accepts like the MSAA properties.
+var liveSettingPropID = win.MSAAPROPID{0xC12BCD8E, 0x2A8E, 0x4950, [8]byte{0x8A, 0xE7, 0x36, 0x25, 0x11, 0x1D, 0x58, 0xEB}}
+
+// Announce makes screen readers speak text, e.g. to tell users about changes
+// that happen in the background, like "Connected" or "3 results found", which
+// they would otherwise miss. The announcement is made from the active form,
+// or from the form that was active last if the application is inactive.
+//
+// Announcements use UI Automation notifications, which require Windows 10
+// version 1709. On older versions, they fall back to a live region, which not
+// all screen readers support.
+func Announce(text string, priority AnnouncementPriority) error {
+	fb := activeForm
+	if fb == nil {
+		fb = lastActiveForm
+	}
+	if fb == nil {
+		return newError("no form to announce from")
+	}
+
+	return fb.announce(text, priority)
+}
+
+func (fb *FormBase) announce(text string, priority AnnouncementPriority) error {
+	if procUiaRaiseNotificationEvent.Find() != nil {
+		return fb.announceWithLiveRegion(text, priority)
+	}
+
+	var provider *win.IUnknown
+	if hr := uiaHostProviderFromHwnd(fb.hWnd, (*unsafe.Pointer)(unsafe.Pointer(&provider))); win.FAILED(hr) {
+		return errorFromHRESULT("UiaHostProviderFromHwnd", hr)
+	}
+	defer syscall.SyscallN(provider.LpVtbl.Release, uintptr(unsafe.Pointer(provider)))
+
+	processing := int32(_NotificationProcessing_MostRecent)
+	if priority == AnnouncementAssertive {
+		processing = _NotificationProcessing_ImportantMostRecent
+	}
+
+	// Only the most recent announcement of the same activity is spoken.
+	textBSTR := bstrFromString(text)
+	defer win.SysFreeString(textBSTR)
+	activityBSTR := bstrFromString("walk.Announce")
+	defer win.SysFreeString(activityBSTR)
+
+	if hr := uiaRaiseNotificationEvent(unsafe.Pointer(provider), _NotificationKind_Other, processing, textBSTR, activityBSTR); win.FAILED(hr) {
+		return errorFromHRESULT("UiaRaiseNotificationEvent", hr)
+	}
+
+	return nil
+}
+
+// liveRegionPropIds are the properties announceWithLiveRegion sets.
+var liveRegionPropIds = []win.MSAAPROPID{
+	win.PROPID_ACC_NAME,
+	liveSettingPropID,
+}
+
+// announceWithLiveRegion announces text by making it the name of a live
+// region, an otherwise invisible child window of the *FormBase.
+func (fb *FormBase) announceWithLiveRegion(text string, priority AnnouncementPriority) error {
+	accPropServices := accessibilityServices()
+	if accPropServices == nil {
+		return newError("Dynamic Annotation not available")
+	}
+
+	if fb.liveRegionHWnd == 0 {
+		fb.liveRegionHWnd = win.CreateWindowEx(
+			0,
+			syscall.StringToUTF16Ptr("STATIC"),
+			nil,
+			win.WS_CHILD|win.WS_VISIBLE,
+			0, 0, 0, 0,
+			fb.hWnd,
+			0,
+			0,
+			nil)
+		if fb.liveRegionHWnd == 0 {
+			return lastError("CreateWindowEx")
+		}
+	}
+
+	liveSetting := int32(_LiveSetting_Polite)
+	if priority == AnnouncementAssertive {
+		liveSetting = _LiveSetting_Assertive
+	}
+
+	var v win.VARIANT
+	v.SetLong(liveSetting)
+	if hr := accPropServices.SetHwndProp(fb.liveRegionHWnd, win.OBJID_CLIENT, win.CHILDID_SELF, &liveSettingPropID, &v); win.FAILED(hr) {
+		return errorFromHRESULT("IAccPropServices.SetHwndProp", hr)
+	}
+	if hr := accPropServices.SetHwndPropStr(fb.liveRegionHWnd, win.OBJID_CLIENT, win.CHILDID_SELF, &win.PROPID_ACC_NAME, text); win.FAILED(hr) {
+		return errorFromHRESULT("IAccPropServices.SetHwndPropStr", hr)
+	}
+
+	win.NotifyWinEvent(_EVENT_OBJECT_LIVEREGIONCHANGED, fb.liveRegionHWnd, win.OBJID_CLIENT, win.CHILDID_SELF)
+
+	return nil
+}
+
+// disposeLiveRegion clears the properties of the live region of the
+// *FormBase, if announceWithLiveRegion created one. The window itself is
+// destroyed along with the form.
+func (fb *FormBase) disposeLiveRegion() {
+	if fb.liveRegionHWnd == 0 || accPropServices == nil {
+		return
+	}
+
+	accPropServices.ClearHwndProps(fb.liveRegionHWnd, win.OBJID_CLIENT, win.CHILDID_SELF, liveRegionPropIds)
+	fb.liveRegionHWnd = 0
+}
@@ -127,6 +127,24 @@ type Application struct {
 	pToolTip            *ToolTip
 	activeMessageLoops  int
 	runMsgFilters       bool
+	asyncModals         []Modal // shown without a message loop of their own
+
+	singleInstanceMutex      windows.Handle
+	singleInstanceWindow     win.HWND
+	instanceStartedPublisher GenericEventPublisher[[]string]
+
+	sessionWindow                     win.HWND
+	sessionEndingPublisher            GenericEventPublisher[*SessionEndQuery]
+	sessionEndedPublisher             GenericEventPublisher[SessionEndReason]
+	suspendingPublisher               EventPublisher
+	resumedPublisher                  EventPublisher
+	powerSource                       PowerSource
+	powerSourceChangedPublisher       GenericEventPublisher[PowerSource]
+	batteryPercentage                 int
+	batteryPercentageChangedPublisher GenericEventPublisher[int]
+	watchingDisplay                   bool
+	monitors                          []*Monitor // as seen last by DisplayChanged
+	displayChangedPublisher           EventPublisher
 }
 
 // Bare minimum initialization that must happen ASAP. While we typically do
@@ -303,7 +321,8 @@ func (app *Application) init() (finalInitOutsideOnce func() error, err error) {
 
 	icc := win.INITCOMMONCONTROLSEX{
 		DwSize: uint32(unsafe.Sizeof(win.INITCOMMONCONTROLSEX{})),
-		DwICC: win.ICC_BAR_CLASSES | win.ICC_LINK_CLASS | win.ICC_LISTVIEW_CLASSES |
+		DwICC: win.ICC_BAR_CLASSES | win.ICC_COOL_CLASSES | win.ICC_HOTKEY_CLASS |
+			win.ICC_INTERNET_CLASSES | win.ICC_LINK_CLASS | win.ICC_LISTVIEW_CLASSES |
 			win.ICC_PROGRESS_CLASS | win.ICC_STANDARD_CLASSES | win.ICC_TAB_CLASSES |
 			win.ICC_TREEVIEW_CLASSES,
 	}
@@ -311,6 +330,12 @@ func (app *Application) init() (finalInitOutsideOnce func() error, err error) {
 		return nil, errInitCommonControlsEx
 	}
 
+	// Drag and drop and several shell objects need OLE on the UI thread. It
+	// stays initialized until Run returns.
+	if hr := win.OleInitialize(); hr != win.S_OK && hr != win.S_FALSE {
+		return nil, errorFromHRESULT("OleInitialize", hr)
+	}
+
 	// Cloaking is a DWM feature that makes windows invisible, even if they're
 	// still "visible" in the traditional sense. This is used by features like
 	// virtual desktops. This hook allows us to gain insight as to when a window
@@ -397,6 +422,9 @@ func (app *Application) Run() int {
 	// Critical shutdown goes here; only the minimum necessary work to prevent
 	// data loss.
 
+	// This also renders data the application left on the clipboard.
+	win.OleUninitialize()
+
 	return exitCode
 }
 
@@ -429,6 +457,12 @@ func (app *Application) runMainMessageLoop() int {
 }
 
 func (app *Application) runPreTranslateHandler(msg *win.MSG) bool {
+	for i := len(app.asyncModals) - 1; i >= 0; i-- {
+		if app.asyncModals[i].OnPreTranslate(msg) {
+			return true
+		}
+	}
+
 	w := getMsgWindow(msg)
 	if w == nil {
 		return false
@@ -758,7 +792,9 @@ func (app *Application) runSyncLayout() {
 	}
 }
 
-func (app *Application) toolTip() *ToolTip {
+// ToolTip returns the ToolTip that displays the tool tip texts of all widgets,
+// e.g. to make it a balloon or to change its delays.
+func (app *Application) ToolTip() *ToolTip {
 	return app.pToolTip
 }
 
@@ -0,0 +1,222 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"unsafe"
+
+	"github.com/tailscale/win"
+)
+
+// AutocompleteOptions control how an Autocomplete offers its suggestions.
+type AutocompleteOptions uint32
+
+const (
+	// AutocompleteSuggest displays the matching suggestions in a drop down
+	// list.
+	AutocompleteSuggest AutocompleteOptions = _ACO_AUTOSUGGEST
+
+	// AutocompleteAppend completes the text with the first matching
+	// suggestion, selecting the completed part.
+	AutocompleteAppend AutocompleteOptions = _ACO_AUTOAPPEND
+
+	// AutocompleteUseTab makes the Tab key move through the suggestions.
+	AutocompleteUseTab AutocompleteOptions = _ACO_USETAB
+
+	// AutocompleteUpDownKeyDropsList makes the Up and Down keys display the
+	// list of suggestions.
+	AutocompleteUpDownKeyDropsList AutocompleteOptions = _ACO_UPDOWNKEYDROPSLIST
+
+	// AutocompleteNoPrefixFiltering displays all suggestions instead of only
+	// those starting with the text, leaving the filtering to the source.
+	AutocompleteNoPrefixFiltering AutocompleteOptions = _ACO_NOPREFIXFILTERING
+)
+
+// AutocompleteSource provides the suggestions of an Autocomplete.
+type AutocompleteSource interface {
+	// Suggestions returns the suggestions for text, the text of the edit
+	// control when autocompletion starts or is refreshed. It is called on a
+	// background thread, so it may block on slow lookups, but it must not
+	// access widgets.
+	Suggestions(text string) []string
+}
+
+// AutocompleteSourceFunc adapts a function to an AutocompleteSource.
+type AutocompleteSourceFunc func(text string) []string
+
+func (f AutocompleteSourceFunc) Suggestions(text string) []string {
+	return f(text)
+}
+
+// StringsAutocompleteSource is an AutocompleteSource suggesting fixed strings,
+// like the history of a field.
+type StringsAutocompleteSource []string
+
+func (s StringsAutocompleteSource) Suggestions(text string) []string {
+	return s
+}
+
+// Autocomplete offers suggestions while the user types into a LineEdit or an
+// editable ComboBox, using the autocompletion of the shell.
+type Autocomplete struct {
+	hwndEdit win.HWND
+	ac       *iAutoComplete2
+	enum     *autocompleteIEnumString
+	options  AutocompleteOptions
+	enabled  bool
+}
+
+func newAutocomplete(hwndEdit win.HWND, source AutocompleteSource, options AutocompleteOptions) (*Autocomplete, error) {
+	ac, err := newIAutoComplete2()
+	if err != nil {
+		return nil, err
+	}
+
+	a := &Autocomplete{
+		hwndEdit: hwndEdit,
+		ac:       ac,
+		enum:     newAutocompleteIEnumString(source),
+		enabled:  true,
+	}
+	a.enum.setText(windowText(hwndEdit))
+
+	if hr := ac.Init(hwndEdit, (*win.IUnknown)(unsafe.Pointer(a.enum))); win.FAILED(hr) {
+		ac.Release()
+		return nil, errorFromHRESULT("IAutoComplete.Init", hr)
+	}
+
+	if err := a.SetOptions(options); err != nil {
+		a.release()
+		return nil, err
+	}
+
+	return a, nil
+}
+
+// release lets go of the autocompletion object, when the edit control is
+// destroyed.
+func (a *Autocomplete) release() {
+	if a.ac != nil {
+		a.ac.Release()
+		a.ac = nil
+	}
+}
+
+// Source returns the source of the suggestions.
+func (a *Autocomplete) Source() AutocompleteSource {
+	a.enum.mutex.Lock()
+	defer a.enum.mutex.Unlock()
+
+	return a.enum.source
+}
+
+// SetSource sets the source of the suggestions and refreshes them.
+func (a *Autocomplete) SetSource(source AutocompleteSource) error {
+	a.enum.setSource(source)
+
+	return a.Refresh()
+}
+
+// Options returns how the suggestions are offered.
+func (a *Autocomplete) Options() AutocompleteOptions {
+	return a.options
+}
+
+// SetOptions sets how the suggestions are offered.
+func (a *Autocomplete) SetOptions(options AutocompleteOptions) error {
+	if a.ac == nil {
+		return newError("autocomplete released")
+	}
+
+	if hr := a.ac.SetOptions(uint32(options)); win.FAILED(hr) {
+		return errorFromHRESULT("IAutoComplete2.SetOptions", hr)
+	}
+
+	a.options = options
+
+	return nil
+}
+
+// Enabled returns whether suggestions are offered.
+func (a *Autocomplete) Enabled() bool {
+	return a.enabled
+}
+
+// SetEnabled sets whether suggestions are offered.
+func (a *Autocomplete) SetEnabled(enabled bool) error {
+	if a.ac == nil {
+		return newError("autocomplete released")
+	}
+
+	if hr := a.ac.Enable(enabled); win.FAILED(hr) {
+		return errorFromHRESULT("IAutoComplete.Enable", hr)
+	}
+
+	a.enabled = enabled
+
+	return nil
+}
+
+// Refresh makes the next suggestions be requested from the source again, like
+// after an asynchronous lookup completed.
+func (a *Autocomplete) Refresh() error {
+	if a.ac == nil {
+		return newError("autocomplete released")
+	}
+
+	p, hr := queryInterface((*win.IUnknown)(unsafe.Pointer(a.ac)), &iid_IAutoCompleteDropDown)
+	if win.FAILED(hr) {
+		return errorFromHRESULT("IAutoComplete.QueryInterface(IID_IAutoCompleteDropDown)", hr)
+	}
+	dd := (*iAutoCompleteDropDown)(p)
+	defer dd.Release()
+
+	a.enum.setText(windowText(a.hwndEdit))
+
+	if hr := dd.ResetEnumerator(); win.FAILED(hr) {
+		return errorFromHRESULT("IAutoCompleteDropDown.ResetEnumerator", hr)
+	}
+
+	return nil
+}
+
+// textChanged must be called when the text of the edit control changed.
+func (a *Autocomplete) textChanged() {
+	a.enum.setText(windowText(a.hwndEdit))
+}
+
+// setAutocomplete updates *a, the Autocomplete of the edit control hwndEdit,
+// to source and options, creating it if needed. A nil source disables it.
+func setAutocomplete(a **Autocomplete, hwndEdit win.HWND, source AutocompleteSource, options AutocompleteOptions) error {
+	if *a == nil {
+		if source == nil {
+			return nil
+		}
+
+		ac, err := newAutocomplete(hwndEdit, source, options)
+		if err != nil {
+			return err
+		}
+
+		*a = ac
+
+		return nil
+	}
+
+	if source == nil {
+		return (*a).SetEnabled(false)
+	}
+
+	(*a).enum.setSource(source)
+
+	if err := (*a).SetOptions(options); err != nil {
+		return err
+	}
+
+	return (*a).SetEnabled(true)
+}
@@ -0,0 +1,117 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"syscall"
+	"unsafe"
+
+	"github.com/tailscale/win"
+)
+
+var (
+	clsid_AutoComplete = win.CLSID{0x00BB2763, 0x6A77, 0x11D0, [8]byte{0xA5, 0x35, 0x00, 0xC0, 0x4F, 0xD7, 0xD0, 0x62}}
+
+	iid_IAutoComplete2        = win.IID{0xEAC04BC0, 0x3791, 0x11D2, [8]byte{0xBB, 0x95, 0x00, 0x60, 0x97, 0x7B, 0x46, 0x4C}}
+	iid_IAutoCompleteDropDown = win.IID{0x3CD141F4, 0x3C6A, 0x11D2, [8]byte{0xBC, 0xAA, 0x00, 0xC0, 0x4F, 0xD9, 0x29, 0xDB}}
+	iid_IEnumString           = win.IID{0x00000101, 0x0000, 0x0000, [8]byte{0xC0, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x46}}
+)
+
+const (
+	_ACO_AUTOSUGGEST        = 0x0001
+	_ACO_AUTOAPPEND         = 0x0002
+	_ACO_USETAB             = 0x0010
+	_ACO_UPDOWNKEYDROPSLIST = 0x0020
+	_ACO_NOPREFIXFILTERING  = 0x0100
+)
+
+type iAutoComplete2Vtbl struct {
+	win.IUnknownVtbl
+	Init       uintptr
+	Enable     uintptr
+	SetOptions uintptr
+	GetOptions uintptr
+}
+
+type iAutoComplete2 struct {
+	LpVtbl *iAutoComplete2Vtbl
+}
+
+// newIAutoComplete2 creates the autocompletion object of the shell.
+func newIAutoComplete2() (*iAutoComplete2, error) {
+	var ac *iAutoComplete2
+	if hr := win.CoCreateInstance(
+		&clsid_AutoComplete,
+		nil,
+		win.CLSCTX_INPROC_SERVER,
+		&iid_IAutoComplete2,
+		(*unsafe.Pointer)(unsafe.Pointer(&ac))); win.FAILED(hr) {
+
+		return nil, errorFromHRESULT("CoCreateInstance(CLSID_AutoComplete)", hr)
+	}
+
+	return ac, nil
+}
+
+func (obj *iAutoComplete2) Release() uint32 {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.Release,
+		uintptr(unsafe.Pointer(obj)))
+
+	return uint32(ret)
+}
+
+func (obj *iAutoComplete2) Init(hwndEdit win.HWND, punkACL *win.IUnknown) win.HRESULT {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.Init,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(hwndEdit),
+		uintptr(unsafe.Pointer(punkACL)),
+		0,
+		0)
+
+	return win.HRESULT(ret)
+}
+
+func (obj *iAutoComplete2) Enable(enable bool) win.HRESULT {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.Enable,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(win.BoolToBOOL(enable)))
+
+	return win.HRESULT(ret)
+}
+
+func (obj *iAutoComplete2) SetOptions(options uint32) win.HRESULT {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.SetOptions,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(options))
+
+	return win.HRESULT(ret)
+}
+
+type iAutoCompleteDropDownVtbl struct {
+	win.IUnknownVtbl
+	GetDropDownStatus uintptr
+	ResetEnumerator   uintptr
+}
+
+type iAutoCompleteDropDown struct {
+	LpVtbl *iAutoCompleteDropDownVtbl
+}
+
+func (obj *iAutoCompleteDropDown) Release() uint32 {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.Release,
+		uintptr(unsafe.Pointer(obj)))
+
+	return uint32(ret)
+}
+
+func (obj *iAutoCompleteDropDown) ResetEnumerator() win.HRESULT {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.ResetEnumerator,
+		uintptr(unsafe.Pointer(obj)))
+
+	return win.HRESULT(ret)
+}
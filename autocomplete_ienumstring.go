@@ -0,0 +1,165 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"sync"
+	"syscall"
+	"unsafe"
+
+	"github.com/tailscale/win"
+)
+
+type iEnumStringVtbl struct {
+	QueryInterface uintptr
+	AddRef         uintptr
+	Release        uintptr
+	Next           uintptr
+	Skip           uintptr
+	Reset          uintptr
+	Clone          uintptr
+}
+
+var autocompleteIEnumStringVtbl *iEnumStringVtbl
+
+func init() {
+	AppendToWalkInit(func() {
+		autocompleteIEnumStringVtbl = &iEnumStringVtbl{
+			syscall.NewCallback(autocomplete_IEnumString_QueryInterface),
+			syscall.NewCallback(autocomplete_IEnumString_AddRef),
+			syscall.NewCallback(autocomplete_IEnumString_Release),
+			syscall.NewCallback(autocomplete_IEnumString_Next),
+			syscall.NewCallback(autocomplete_IEnumString_Skip),
+			syscall.NewCallback(autocomplete_IEnumString_Reset),
+			syscall.NewCallback(autocomplete_IEnumString_Clone),
+		}
+	})
+}
+
+// autocompleteIEnumString enumerates the suggestions of an Autocomplete for
+// the autocompletion object of the shell, which calls it from a background
+// thread.
+type autocompleteIEnumString struct {
+	lpVtbl      *iEnumStringVtbl
+	mutex       sync.Mutex
+	source      AutocompleteSource
+	text        string
+	suggestions []string
+	index       int
+}
+
+func newAutocompleteIEnumString(source AutocompleteSource) *autocompleteIEnumString {
+	return &autocompleteIEnumString{lpVtbl: autocompleteIEnumStringVtbl, source: source}
+}
+
+func (enum *autocompleteIEnumString) setSource(source AutocompleteSource) {
+	enum.mutex.Lock()
+	defer enum.mutex.Unlock()
+
+	enum.source = source
+}
+
+// setText records text, the text of the edit control, for the next Reset.
+func (enum *autocompleteIEnumString) setText(text string) {
+	enum.mutex.Lock()
+	defer enum.mutex.Unlock()
+
+	enum.text = text
+}
+
+func autocomplete_IEnumString_QueryInterface(enum *autocompleteIEnumString, riid win.REFIID, ppvObject *unsafe.Pointer) uintptr {
+	if win.EqualREFIID(riid, &win.IID_IUnknown) || win.EqualREFIID(riid, &iid_IEnumString) {
+		*ppvObject = unsafe.Pointer(enum)
+		return win.S_OK
+	}
+
+	*ppvObject = nil
+	return win.E_NOINTERFACE
+}
+
+func autocomplete_IEnumString_AddRef(enum *autocompleteIEnumString) uintptr {
+	return 1
+}
+
+func autocomplete_IEnumString_Release(enum *autocompleteIEnumString) uintptr {
+	return 1
+}
+
+func autocomplete_IEnumString_Next(enum *autocompleteIEnumString, celt uintptr, rgelt **uint16, pceltFetched *uint32) uintptr {
+	enum.mutex.Lock()
+	defer enum.mutex.Unlock()
+
+	elts := unsafe.Slice(rgelt, celt)
+
+	var fetched int
+	for fetched < int(celt) && enum.index < len(enum.suggestions) {
+		buf, err := syscall.UTF16FromString(enum.suggestions[enum.index])
+		enum.index++
+		if err != nil {
+			continue
+		}
+
+		// The caller frees the strings using CoTaskMemFree.
+		p := coTaskMemAlloc(uintptr(len(buf)) * 2)
+		if p == nil {
+			break
+		}
+		copy(unsafe.Slice((*uint16)(p), len(buf)), buf)
+
+		elts[fetched] = (*uint16)(p)
+		fetched++
+	}
+
+	if pceltFetched != nil {
+		*pceltFetched = uint32(fetched)
+	}
+
+	if fetched < int(celt) {
+		return win.S_FALSE
+	}
+
+	return win.S_OK
+}
+
+func autocomplete_IEnumString_Skip(enum *autocompleteIEnumString, celt uintptr) uintptr {
+	enum.mutex.Lock()
+	defer enum.mutex.Unlock()
+
+	enum.index += int(celt)
+	if enum.index > len(enum.suggestions) {
+		enum.index = len(enum.suggestions)
+		return win.S_FALSE
+	}
+
+	return win.S_OK
+}
+
+func autocomplete_IEnumString_Reset(enum *autocompleteIEnumString) uintptr {
+	enum.mutex.Lock()
+	source, text := enum.source, enum.text
+	enum.mutex.Unlock()
+
+	// The source may take its time, so the mutex is not held meanwhile.
+	var suggestions []string
+	if source != nil {
+		suggestions = source.Suggestions(text)
+	}
+
+	enum.mutex.Lock()
+	defer enum.mutex.Unlock()
+
+	enum.suggestions = suggestions
+	enum.index = 0
+
+	return win.S_OK
+}
+
+func autocomplete_IEnumString_Clone(enum *autocompleteIEnumString, ppenum *unsafe.Pointer) uintptr {
+	*ppenum = nil
+	return win.E_NOTIMPL
+}
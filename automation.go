@@ -0,0 +1,738 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"strings"
+	"unsafe"
+
+	"github.com/tailscale/win"
+)
+
+// AutomationRole is the control type of an AutomationElement, which tells
+// screen readers and automation tools what kind of control it is.
+type AutomationRole int32
+
+const (
+	AutomationRoleButton AutomationRole = _UIA_ButtonControlTypeId + iota
+	AutomationRoleCalendar
+	AutomationRoleCheckBox
+	AutomationRoleComboBox
+	AutomationRoleEdit
+	AutomationRoleHyperlink
+	AutomationRoleImage
+	AutomationRoleListItem
+	AutomationRoleList
+	AutomationRoleMenu
+	AutomationRoleMenuBar
+	AutomationRoleMenuItem
+	AutomationRoleProgressBar
+	AutomationRoleRadioButton
+	AutomationRoleScrollBar
+	AutomationRoleSlider
+	AutomationRoleSpinner
+	AutomationRoleStatusBar
+	AutomationRoleTab
+	AutomationRoleTabItem
+	AutomationRoleText
+	AutomationRoleToolBar
+	AutomationRoleToolTip
+	AutomationRoleTree
+	AutomationRoleTreeItem
+	AutomationRoleCustom
+	AutomationRoleGroup
+	AutomationRoleThumb
+	AutomationRoleDataGrid
+	AutomationRoleDataItem
+	AutomationRoleDocument
+	AutomationRoleSplitButton
+	AutomationRoleWindow
+	AutomationRolePane
+	AutomationRoleHeader
+	AutomationRoleHeaderItem
+	AutomationRoleTable
+	AutomationRoleTitleBar
+	AutomationRoleSeparator
+)
+
+// AutomationToggleState is the state of an AutomationElement that can be
+// toggled, like a check box.
+type AutomationToggleState int32
+
+const (
+	AutomationToggleOff AutomationToggleState = iota
+	AutomationToggleOn
+	AutomationToggleIndeterminate
+)
+
+// AutomationSelectionOp is a change of the selection requested through UI
+// Automation.
+type AutomationSelectionOp int
+
+const (
+	AutomationSelect              AutomationSelectionOp = iota // select only the item
+	AutomationAddToSelection                                   // add the item to the selection
+	AutomationRemoveFromSelection                              // remove the item from the selection
+)
+
+// AutomationEvent is an event an AutomationElement can raise with RaiseEvent.
+// Focus, property, selection and structure changes are raised by walk.
+type AutomationEvent int32
+
+const (
+	AutomationEventToolTipOpened        AutomationEvent = _UIA_ToolTipOpenedEventId
+	AutomationEventToolTipClosed        AutomationEvent = _UIA_ToolTipClosedEventId
+	AutomationEventMenuOpened           AutomationEvent = _UIA_MenuOpenedEventId
+	AutomationEventMenuClosed           AutomationEvent = _UIA_MenuClosedEventId
+	AutomationEventLayoutInvalidated    AutomationEvent = _UIA_LayoutInvalidatedEventId
+	AutomationEventInvoked              AutomationEvent = _UIA_Invoke_InvokedEventId
+	AutomationEventSelectionInvalidated AutomationEvent = _UIA_Selection_InvalidatedEventId
+)
+
+// AutomationElement exposes a part of a CustomWidget to UI Automation, so
+// screen readers like Narrator and automation tools can find and operate it.
+// The elements of a CustomWidget form a tree below the one returned by
+// CustomWidget.AutomationRoot.
+//
+// Which control patterns an element supports follows from how it is set up:
+// it can be invoked once SetInvokeFunc was called, toggled once SetToggleFunc
+// was called, it has a value once SetValue was called, and it can be selected
+// if its parent is a selection container, see SetSelectionContainer.
+//
+// The setters raise the events UI Automation expects for the changes, so the
+// elements should be kept in sync with what the widget paints.
+type AutomationElement struct {
+	tree              *automationTree // nil while not part of a tree
+	parent            *AutomationElement
+	children          []*AutomationElement
+	id                int32
+	role              AutomationRole
+	localizedRole     string
+	name              string
+	helpText          string
+	automationID      string
+	bounds            Rectangle
+	disabled          bool
+	focusable         bool
+	focusFunc         func()
+	invokeFunc        func()
+	toggleFunc        func()
+	toggleState       AutomationToggleState
+	hasValue          bool
+	value             string
+	setValueFunc      func(value string) error
+	selectionFunc     func(item *AutomationElement, op AutomationSelectionOp)
+	selectsMultiple   bool
+	selectionRequired bool
+	isContainer       bool
+	selected          bool
+	providers         [automationProviderKindCount]*automationProvider
+}
+
+// automationTree holds the AutomationElements of a CustomWidget.
+type automationTree struct {
+	cw      *CustomWidget
+	root    *AutomationElement
+	focused *AutomationElement
+	nextID  int32
+}
+
+// NewAutomationElement returns a new AutomationElement, to be added to the
+// tree of a CustomWidget with AddChild or InsertChild.
+func NewAutomationElement(role AutomationRole, name string) *AutomationElement {
+	return &AutomationElement{role: role, name: name}
+}
+
+// AutomationRoot returns the AutomationElement that represents the
+// *CustomWidget itself. Once it was called, the *CustomWidget exposes the
+// elements added below it to UI Automation.
+//
+// The name and role of the root default to the ones of the window, so they
+// can also be set through Accessibility.
+func (cw *CustomWidget) AutomationRoot() *AutomationElement {
+	if cw.automation == nil {
+		tree := &automationTree{cw: cw}
+		tree.root = &AutomationElement{tree: tree}
+		cw.automation = tree
+	}
+
+	return cw.automation.root
+}
+
+// Parent returns the parent of the AutomationElement, or nil for the root and
+// elements that were not added yet.
+func (el *AutomationElement) Parent() *AutomationElement {
+	return el.parent
+}
+
+// Children returns the children of the AutomationElement, in the order
+// screen readers visit them.
+func (el *AutomationElement) Children() []*AutomationElement {
+	return append([]*AutomationElement(nil), el.children...)
+}
+
+// AddChild adds child as last child of the AutomationElement.
+func (el *AutomationElement) AddChild(child *AutomationElement) error {
+	return el.InsertChild(len(el.children), child)
+}
+
+// InsertChild inserts child as child of the AutomationElement at index.
+func (el *AutomationElement) InsertChild(index int, child *AutomationElement) error {
+	if child.parent != nil || child.isRoot() {
+		return newError("element already is part of a tree")
+	}
+	if index < 0 || index > len(el.children) {
+		return newError("index out of range")
+	}
+
+	el.children = append(el.children, nil)
+	copy(el.children[index+1:], el.children[index:])
+	el.children[index] = child
+	child.parent = el
+
+	if el.tree != nil {
+		child.attach(el.tree)
+		child.raiseStructureChanged(_StructureChangeType_ChildAdded, child.runtimeID())
+	}
+
+	return nil
+}
+
+// RemoveChild removes child from the children of the AutomationElement.
+// UI Automation clients that still refer to child or its descendants are
+// told that they are no longer available.
+func (el *AutomationElement) RemoveChild(child *AutomationElement) error {
+	for i, c := range el.children {
+		if c != child {
+			continue
+		}
+
+		el.children = append(el.children[:i], el.children[i+1:]...)
+		child.parent = nil
+
+		if el.tree != nil {
+			runtimeID := child.runtimeID()
+			child.detach()
+			el.raiseStructureChanged(_StructureChangeType_ChildRemoved, runtimeID)
+		}
+
+		return nil
+	}
+
+	return newError("element is not a child")
+}
+
+// Role returns the role of the AutomationElement.
+func (el *AutomationElement) Role() AutomationRole {
+	return el.role
+}
+
+// SetRole sets the role of the AutomationElement.
+func (el *AutomationElement) SetRole(role AutomationRole) {
+	if role != el.role {
+		el.role = role
+		el.raisePropertyChanged(_UIA_ControlTypePropertyId)
+	}
+}
+
+// LocalizedRole returns the text screen readers use for the role of the
+// AutomationElement, if it was set.
+func (el *AutomationElement) LocalizedRole() string {
+	return el.localizedRole
+}
+
+// SetLocalizedRole sets the text screen readers use for the role of the
+// AutomationElement, e.g. "color picker" for an AutomationRoleCustom element.
+// By default, Windows provides a text for the role.
+func (el *AutomationElement) SetLocalizedRole(localizedRole string) {
+	if localizedRole != el.localizedRole {
+		el.localizedRole = localizedRole
+		el.raisePropertyChanged(_UIA_LocalizedControlTypePropertyId)
+	}
+}
+
+// Name returns the name of the AutomationElement.
+func (el *AutomationElement) Name() string {
+	return el.name
+}
+
+// SetName sets the name of the AutomationElement, which screen readers
+// announce, so it should be the text the element shows, if any.
+func (el *AutomationElement) SetName(name string) {
+	if name != el.name {
+		el.name = name
+		el.raisePropertyChanged(_UIA_NamePropertyId)
+	}
+}
+
+// HelpText returns the help text of the AutomationElement.
+func (el *AutomationElement) HelpText() string {
+	return el.helpText
+}
+
+// SetHelpText sets the help text of the AutomationElement, like the text of a
+// tool tip.
+func (el *AutomationElement) SetHelpText(helpText string) {
+	if helpText != el.helpText {
+		el.helpText = helpText
+		el.raisePropertyChanged(_UIA_HelpTextPropertyId)
+	}
+}
+
+// AutomationID returns the identifier of the AutomationElement.
+func (el *AutomationElement) AutomationID() string {
+	return el.automationID
+}
+
+// SetAutomationID sets the identifier automation tools use to find the
+// AutomationElement, which should be unique among its siblings and not be
+// localized.
+func (el *AutomationElement) SetAutomationID(id string) {
+	if id != el.automationID {
+		el.automationID = id
+		el.raisePropertyChanged(_UIA_AutomationIdPropertyId)
+	}
+}
+
+// Bounds returns the bounds of the AutomationElement.
+func (el *AutomationElement) Bounds() Rectangle {
+	return el.bounds
+}
+
+// SetBounds sets the bounds of the AutomationElement, in client coordinates
+// of the CustomWidget and native pixels. The bounds of the root are the ones
+// of the CustomWidget and cannot be set.
+func (el *AutomationElement) SetBounds(bounds Rectangle) {
+	el.bounds = bounds
+}
+
+// Enabled returns whether the AutomationElement is enabled.
+func (el *AutomationElement) Enabled() bool {
+	return !el.disabled
+}
+
+// SetEnabled sets whether the AutomationElement is enabled. Disabled elements
+// cannot be invoked, toggled or selected through UI Automation.
+func (el *AutomationElement) SetEnabled(enabled bool) {
+	if enabled != !el.disabled {
+		el.disabled = !enabled
+		el.raisePropertyChanged(_UIA_IsEnabledPropertyId)
+	}
+}
+
+// Focusable returns whether the AutomationElement can have the keyboard
+// focus.
+func (el *AutomationElement) Focusable() bool {
+	return el.focusable
+}
+
+// SetFocusable sets whether the AutomationElement can have the keyboard
+// focus, i.e. whether the widget lets the user move the focus to it.
+func (el *AutomationElement) SetFocusable(focusable bool) {
+	if focusable != el.focusable {
+		el.focusable = focusable
+		el.raisePropertyChanged(_UIA_IsKeyboardFocusablePropertyId)
+	}
+}
+
+// SetFocusFunc sets the function that is called when a UI Automation client
+// moves the focus to the AutomationElement. The element is already focused
+// when it is called, so it only has to update the widget.
+func (el *AutomationElement) SetFocusFunc(focus func()) {
+	el.focusFunc = focus
+}
+
+// Focused returns whether the AutomationElement is the focused element of its
+// tree.
+func (el *AutomationElement) Focused() bool {
+	return el.tree != nil && el.tree.focused == el
+}
+
+// SetFocused makes the AutomationElement the focused element of its tree. It
+// should be called whenever the widget moves its focus indicator. Screen
+// readers follow the focus while the widget has the keyboard focus.
+func (el *AutomationElement) SetFocused() {
+	if el.tree == nil || el.isRoot() {
+		return
+	}
+
+	el.tree.focused = el
+
+	if el.tree.cw.Focused() {
+		el.raiseEvent(_UIA_AutomationFocusChangedEventId)
+	}
+}
+
+// SetInvokeFunc sets the function that is called when the AutomationElement
+// is invoked through UI Automation, e.g. for a button. Setting a function
+// makes the element invokable. It is called after the request was answered,
+// so it may show dialogs.
+func (el *AutomationElement) SetInvokeFunc(invoke func()) {
+	el.invokeFunc = invoke
+}
+
+// SetToggleFunc sets the function that is called when the AutomationElement
+// is toggled through UI Automation, e.g. for a check box. Setting a function
+// makes the element toggleable. It is expected to call SetToggleState.
+func (el *AutomationElement) SetToggleFunc(toggle func()) {
+	el.toggleFunc = toggle
+}
+
+// ToggleState returns the toggle state of the AutomationElement.
+func (el *AutomationElement) ToggleState() AutomationToggleState {
+	return el.toggleState
+}
+
+// SetToggleState sets the toggle state of the AutomationElement.
+func (el *AutomationElement) SetToggleState(state AutomationToggleState) {
+	if state != el.toggleState {
+		el.toggleState = state
+		el.raisePropertyChanged(_UIA_ToggleToggleStatePropertyId)
+	}
+}
+
+// Value returns the value of the AutomationElement.
+func (el *AutomationElement) Value() string {
+	return el.value
+}
+
+// SetValue sets the value of the AutomationElement, e.g. the text of an edit
+// field or the selected color of a color picker. Setting a value makes the
+// element have one, which is read-only unless SetValueFunc is called.
+func (el *AutomationElement) SetValue(value string) {
+	hadValue := el.hasValue
+	el.hasValue = true
+
+	if value != el.value || !hadValue {
+		el.value = value
+		el.raisePropertyChanged(_UIA_ValueValuePropertyId)
+	}
+}
+
+// SetValueFunc sets the function that is called when a UI Automation client
+// sets the value of the AutomationElement, making the value writable. It is
+// expected to call SetValue, or to return an error if value is invalid.
+func (el *AutomationElement) SetValueFunc(set func(value string) error) {
+	el.hasValue = true
+	el.setValueFunc = set
+}
+
+// SetSelectionContainer makes the AutomationElement a container whose
+// children can be selected, like a list. selectsMultiple is whether more than
+// one child can be selected and selectionRequired whether one must be.
+//
+// change is called when a UI Automation client changes the selection and is
+// expected to call SetSelected for the affected children.
+func (el *AutomationElement) SetSelectionContainer(selectsMultiple, selectionRequired bool, change func(item *AutomationElement, op AutomationSelectionOp)) {
+	el.isContainer = true
+	el.selectsMultiple = selectsMultiple
+	el.selectionRequired = selectionRequired
+	el.selectionFunc = change
+}
+
+// Selected returns whether the AutomationElement is selected.
+func (el *AutomationElement) Selected() bool {
+	return el.selected
+}
+
+// SetSelected sets whether the AutomationElement, a child of a selection
+// container, is selected. Selecting a child of a container that does not
+// select multiple children deselects its siblings.
+func (el *AutomationElement) SetSelected(selected bool) {
+	if selected == el.selected {
+		return
+	}
+
+	el.selected = selected
+	el.raisePropertyChanged(_UIA_SelectionItemIsSelectedPropertyId)
+
+	container := el.parent
+	if container == nil || !container.isContainer {
+		return
+	}
+
+	switch {
+	case !selected:
+		el.raiseEvent(_UIA_SelectionItem_ElementRemovedFromSelectionEventId)
+
+	case container.selectsMultiple:
+		el.raiseEvent(_UIA_SelectionItem_ElementAddedToSelectionEventId)
+
+	default:
+		for _, sibling := range container.children {
+			if sibling != el && sibling.selected {
+				sibling.selected = false
+				sibling.raisePropertyChanged(_UIA_SelectionItemIsSelectedPropertyId)
+			}
+		}
+
+		el.raiseEvent(_UIA_SelectionItem_ElementSelectedEventId)
+	}
+}
+
+// RaiseEvent tells UI Automation clients that event occurred on the
+// AutomationElement.
+func (el *AutomationElement) RaiseEvent(event AutomationEvent) {
+	el.raiseEvent(int32(event))
+}
+
+func (el *AutomationElement) isRoot() bool {
+	return el.tree != nil && el.tree.root == el
+}
+
+// available returns whether UI Automation clients can still use the
+// AutomationElement.
+func (el *AutomationElement) available() bool {
+	return el.tree != nil
+}
+
+// attach makes the AutomationElement and its descendants part of tree.
+func (el *AutomationElement) attach(tree *automationTree) {
+	tree.nextID++
+	el.tree = tree
+	el.id = tree.nextID
+
+	for _, child := range el.children {
+		child.attach(tree)
+	}
+}
+
+// detach removes the AutomationElement and its descendants from their tree.
+func (el *AutomationElement) detach() {
+	for _, child := range el.children {
+		child.detach()
+	}
+
+	if el.tree.focused == el {
+		el.tree.focused = nil
+	}
+	el.tree = nil
+
+	el.disconnect()
+}
+
+// disconnect releases the references UI Automation holds to the providers of
+// the AutomationElement.
+func (el *AutomationElement) disconnect() {
+	if p := el.providers[automationProviderSimple]; p != nil && p.refs > 0 {
+		uiaDisconnectProvider(unsafe.Pointer(p))
+	}
+}
+
+// runtimeID returns the identifier of the AutomationElement within the
+// window of its tree. The root has none, the window provides it.
+func (el *AutomationElement) runtimeID() []int32 {
+	return []int32{_UiaAppendRuntimeId, el.id}
+}
+
+// supports returns whether the AutomationElement implements the interface of
+// providers of kind.
+func (el *AutomationElement) supports(kind automationProviderKind) bool {
+	switch kind {
+	case automationProviderSimple, automationProviderFragment:
+		return true
+
+	case automationProviderFragmentRoot:
+		return el.isRoot()
+
+	case automationProviderInvoke:
+		return el.invokeFunc != nil
+
+	case automationProviderToggle:
+		return el.toggleFunc != nil
+
+	case automationProviderValue:
+		return el.hasValue
+
+	case automationProviderSelection:
+		return el.isContainer
+
+	case automationProviderSelectionItem:
+		return el.parent != nil && el.parent.isContainer
+	}
+
+	return false
+}
+
+// propertyValue stores the value of the property with id in v, or leaves v
+// empty to let UI Automation use a default.
+func (el *AutomationElement) propertyValue(id int32, v *win.VARIANT) {
+	*v = win.VARIANT{}
+
+	root := el.isRoot()
+
+	switch id {
+	case _UIA_ControlTypePropertyId:
+		if el.role != 0 {
+			v.SetLong(int32(el.role))
+		} else if !root {
+			v.SetLong(int32(AutomationRoleCustom))
+		}
+
+	case _UIA_LocalizedControlTypePropertyId:
+		if el.localizedRole != "" {
+			v.SetBSTR(bstrFromString(el.localizedRole))
+		}
+
+	case _UIA_NamePropertyId:
+		if el.name != "" {
+			v.SetBSTR(bstrFromString(el.name))
+		}
+
+	case _UIA_HelpTextPropertyId:
+		if el.helpText != "" {
+			v.SetBSTR(bstrFromString(el.helpText))
+		}
+
+	case _UIA_AutomationIdPropertyId:
+		if el.automationID != "" {
+			v.SetBSTR(bstrFromString(el.automationID))
+		}
+
+	case _UIA_IsEnabledPropertyId:
+		if !root {
+			v.SetBool(variantBool(!el.disabled && el.tree.cw.Enabled()))
+		}
+
+	case _UIA_IsKeyboardFocusablePropertyId:
+		if !root {
+			v.SetBool(variantBool(el.focusable))
+		}
+
+	case _UIA_HasKeyboardFocusPropertyId:
+		if !root {
+			v.SetBool(variantBool(el.Focused() && el.tree.cw.Focused()))
+		}
+
+	case _UIA_ToggleToggleStatePropertyId:
+		if el.toggleFunc != nil {
+			v.SetLong(int32(el.toggleState))
+		}
+
+	case _UIA_ValueValuePropertyId:
+		if el.hasValue {
+			v.SetBSTR(bstrFromString(el.value))
+		}
+
+	case _UIA_SelectionItemIsSelectedPropertyId:
+		if el.supports(automationProviderSelectionItem) {
+			v.SetBool(variantBool(el.selected))
+		}
+	}
+}
+
+// raiseEvent raises the UI Automation event with id on the AutomationElement,
+// if any client listens.
+func (el *AutomationElement) raiseEvent(id int32) {
+	if el.tree == nil || !uiaClientsAreListening() {
+		return
+	}
+
+	p := el.provider(automationProviderSimple)
+	defer p.release()
+
+	uiaRaiseAutomationEvent(unsafe.Pointer(p), id)
+}
+
+// raisePropertyChanged tells UI Automation clients, if any, that the property
+// with id of the AutomationElement changed.
+func (el *AutomationElement) raisePropertyChanged(id int32) {
+	if el.tree == nil || !uiaClientsAreListening() {
+		return
+	}
+
+	var oldValue, newValue win.VARIANT
+	el.propertyValue(id, &newValue)
+	defer variantClear(&newValue)
+
+	p := el.provider(automationProviderSimple)
+	defer p.release()
+
+	uiaRaiseAutomationPropertyChangedEvent(unsafe.Pointer(p), id, &oldValue, &newValue)
+}
+
+// raiseStructureChanged tells UI Automation clients, if any, that the
+// children of the AutomationElement changed.
+func (el *AutomationElement) raiseStructureChanged(changeType int32, runtimeID []int32) {
+	if el.tree == nil || !uiaClientsAreListening() {
+		return
+	}
+
+	p := el.provider(automationProviderSimple)
+	defer p.release()
+
+	uiaRaiseStructureChangedEvent(unsafe.Pointer(p), changeType, runtimeID)
+}
+
+// elementFromPoint returns the deepest descendant of the AutomationElement
+// whose bounds contain pt, in client coordinates, or the element itself.
+func (el *AutomationElement) elementFromPoint(pt Point) *AutomationElement {
+	// Later children are painted on top of earlier ones.
+	for i := len(el.children) - 1; i >= 0; i-- {
+		child := el.children[i]
+
+		b := child.bounds
+		if pt.X >= b.X && pt.X < b.X+b.Width && pt.Y >= b.Y && pt.Y < b.Y+b.Height {
+			return child.elementFromPoint(pt)
+		}
+	}
+
+	return el
+}
+
+// getObject answers WM_GETOBJECT requests for the root provider.
+func (tree *automationTree) getObject(wParam, lParam uintptr) (uintptr, bool) {
+	if int32(lParam) != _UiaRootObjectId {
+		return 0, false
+	}
+
+	p := tree.root.provider(automationProviderSimple)
+	defer p.release()
+
+	return uiaReturnRawElementProvider(tree.cw.hWnd, wParam, lParam, unsafe.Pointer(p)), true
+}
+
+// focusChanged raises a focus event for the focused element, once the widget
+// received the keyboard focus.
+func (tree *automationTree) focusChanged() {
+	if tree.focused != nil {
+		tree.focused.raiseEvent(_UIA_AutomationFocusChangedEventId)
+	}
+}
+
+// destroy disconnects the elements of the tree from UI Automation, once the
+// window of the widget is destroyed.
+func (tree *automationTree) destroy() {
+	uiaReturnRawElementProvider(tree.cw.hWnd, 0, 0, nil)
+
+	for _, child := range tree.root.children {
+		child.detach()
+	}
+
+	tree.focused = nil
+	tree.root.tree = nil
+	tree.root.disconnect()
+}
+
+// bstrFromString returns s as a BSTR, which the receiver frees. It ends at
+// the first null character.
+func bstrFromString(s string) *uint16 {
+	if i := strings.IndexByte(s, 0); i >= 0 {
+		s = s[:i]
+	}
+
+	return win.SysAllocString(s)
+}
+
+func variantBool(value bool) win.VARIANT_BOOL {
+	if value {
+		return win.VARIANT_TRUE
+	}
+
+	return win.VARIANT_FALSE
+}
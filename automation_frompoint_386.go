@@ -0,0 +1,30 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"math"
+	"syscall"
+	"unsafe"
+
+	"github.com/tailscale/win"
+)
+
+// newElementProviderFromPointCallback returns the callback implementing
+// IRawElementProviderFragmentRoot::ElementProviderFromPoint. On 386 the
+// doubles are passed on the stack, so the callback receives them as halves.
+func newElementProviderFromPointCallback() uintptr {
+	return syscall.NewCallback(automationProvider_IRawElementProviderFragmentRoot_ElementProviderFromPoint)
+}
+
+func automationProvider_IRawElementProviderFragmentRoot_ElementProviderFromPoint(p *automationProvider, xLo, xHi, yLo, yHi uintptr, ret *unsafe.Pointer) uintptr {
+	x := math.Float64frombits(uint64(xHi)<<32 | uint64(xLo))
+	y := math.Float64frombits(uint64(yHi)<<32 | uint64(yLo))
+
+	return p.elementProviderFromPoint(win.POINT{X: int32(x), Y: int32(y)}, ret)
+}
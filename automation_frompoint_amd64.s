@@ -0,0 +1,19 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+#include "textflag.h"
+
+// The caller passes this in CX, x in X1, y in X2 and pRetVal in R9. The
+// callback reads its arguments from CX, DX, R8 and R9.
+TEXT elementProviderFromPointTrampoline<>(SB),NOSPLIT|NOFRAME,$0
+	MOVQ X1, DX
+	MOVQ X2, R8
+	MOVQ ·elementProviderFromPointCallback(SB), AX
+	JMP AX
+
+GLOBL ·elementProviderFromPointTrampolineAddr(SB), RODATA, $8
+DATA ·elementProviderFromPointTrampolineAddr(SB)/8, $elementProviderFromPointTrampoline<>(SB)
@@ -0,0 +1,20 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+#include "textflag.h"
+
+// The caller passes this in R0, x in F0, y in F1 and pRetVal in R1. The
+// callback reads its arguments from R0 to R3.
+TEXT elementProviderFromPointTrampoline<>(SB),NOSPLIT|NOFRAME,$0
+	MOVD R1, R3
+	FMOVD F0, R1
+	FMOVD F1, R2
+	MOVD ·elementProviderFromPointCallback(SB), R9
+	JMP (R9)
+
+GLOBL ·elementProviderFromPointTrampolineAddr(SB), RODATA, $8
+DATA ·elementProviderFromPointTrampolineAddr(SB)/8, $elementProviderFromPointTrampoline<>(SB)
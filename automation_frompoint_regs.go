@@ -0,0 +1,44 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows && (amd64 || arm64)
+// +build windows
+// +build amd64 arm64
+
+package walk
+
+import (
+	"math"
+	"syscall"
+	"unsafe"
+
+	"github.com/tailscale/win"
+)
+
+// On amd64 and arm64 the doubles are passed in floating-point registers,
+// which callbacks do not read. The caller instead calls a trampoline, written
+// in assembly, which moves their bits into the integer registers of the
+// arguments following the this pointer and jumps to the callback.
+var (
+	// elementProviderFromPointCallback is the callback the trampoline jumps to.
+	elementProviderFromPointCallback uintptr
+
+	// elementProviderFromPointTrampolineAddr is the address of the trampoline.
+	elementProviderFromPointTrampolineAddr uintptr
+)
+
+// newElementProviderFromPointCallback returns the callback implementing
+// IRawElementProviderFragmentRoot::ElementProviderFromPoint.
+func newElementProviderFromPointCallback() uintptr {
+	elementProviderFromPointCallback = syscall.NewCallback(automationProvider_IRawElementProviderFragmentRoot_ElementProviderFromPoint)
+
+	return elementProviderFromPointTrampolineAddr
+}
+
+func automationProvider_IRawElementProviderFragmentRoot_ElementProviderFromPoint(p *automationProvider, xBits, yBits uintptr, ret *unsafe.Pointer) uintptr {
+	x := math.Float64frombits(uint64(xBits))
+	y := math.Float64frombits(uint64(yBits))
+
+	return p.elementProviderFromPoint(win.POINT{X: int32(x), Y: int32(y)}, ret)
+}
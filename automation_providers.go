@@ -0,0 +1,675 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"sync"
+	"syscall"
+	"unsafe"
+
+	"github.com/tailscale/win"
+)
+
+var (
+	iid_IRawElementProviderSimple       = win.IID{0xD6DD68D1, 0x86FD, 0x4332, [8]byte{0x86, 0x66, 0x9A, 0xBE, 0xDE, 0xA2, 0xD2, 0x4C}}
+	iid_IRawElementProviderFragment     = win.IID{0xF7063DA8, 0x8359, 0x439C, [8]byte{0x92, 0x97, 0xBB, 0xC5, 0x29, 0x9A, 0x7D, 0x87}}
+	iid_IRawElementProviderFragmentRoot = win.IID{0x620CE2A5, 0xAB8F, 0x40A9, [8]byte{0x86, 0xCB, 0xDE, 0x3C, 0x75, 0x59, 0x9B, 0x58}}
+	iid_IInvokeProvider                 = win.IID{0x54FCB24B, 0xE18E, 0x47A2, [8]byte{0xB4, 0xD3, 0xEC, 0xCB, 0xE7, 0x75, 0x99, 0xA2}}
+	iid_IToggleProvider                 = win.IID{0x56D00BD0, 0xC4F4, 0x433C, [8]byte{0xA8, 0x36, 0x1A, 0x52, 0xA5, 0x7E, 0x08, 0x92}}
+	iid_IValueProvider                  = win.IID{0xC7935180, 0x6FB3, 0x4201, [8]byte{0xB1, 0x74, 0x7D, 0xF7, 0x3A, 0xDB, 0xF6, 0x4A}}
+	iid_ISelectionProvider              = win.IID{0xFB8B03AF, 0x3BDF, 0x48D4, [8]byte{0xBD, 0x36, 0x1A, 0x65, 0x79, 0x3B, 0xE1, 0x68}}
+	iid_ISelectionItemProvider          = win.IID{0x2ACAD808, 0xB2D4, 0x452D, [8]byte{0xA4, 0x07, 0x91, 0xFF, 0x1A, 0xD1, 0x67, 0xB2}}
+)
+
+// automationProviderKind is a UI Automation provider interface an
+// AutomationElement implements, each by a separate COM object.
+type automationProviderKind int
+
+const (
+	automationProviderSimple automationProviderKind = iota
+	automationProviderFragment
+	automationProviderFragmentRoot
+	automationProviderInvoke
+	automationProviderToggle
+	automationProviderValue
+	automationProviderSelection
+	automationProviderSelectionItem
+	automationProviderKindCount
+)
+
+var automationProviderIIDs = [automationProviderKindCount]*win.IID{
+	&iid_IRawElementProviderSimple,
+	&iid_IRawElementProviderFragment,
+	&iid_IRawElementProviderFragmentRoot,
+	&iid_IInvokeProvider,
+	&iid_IToggleProvider,
+	&iid_IValueProvider,
+	&iid_ISelectionProvider,
+	&iid_ISelectionItemProvider,
+}
+
+// automationPatternProviders maps UI Automation control pattern ids to the
+// kinds of the providers that implement them.
+var automationPatternProviders = map[int32]automationProviderKind{
+	_UIA_InvokePatternId:        automationProviderInvoke,
+	_UIA_SelectionPatternId:     automationProviderSelection,
+	_UIA_ValuePatternId:         automationProviderValue,
+	_UIA_SelectionItemPatternId: automationProviderSelectionItem,
+	_UIA_TogglePatternId:        automationProviderToggle,
+}
+
+type iRawElementProviderSimpleVtbl struct {
+	win.IUnknownVtbl
+	ProviderOptions        uintptr
+	GetPatternProvider     uintptr
+	GetPropertyValue       uintptr
+	HostRawElementProvider uintptr
+}
+
+type iRawElementProviderFragmentVtbl struct {
+	win.IUnknownVtbl
+	Navigate                 uintptr
+	GetRuntimeId             uintptr
+	BoundingRectangle        uintptr
+	GetEmbeddedFragmentRoots uintptr
+	SetFocus                 uintptr
+	FragmentRoot             uintptr
+}
+
+type iRawElementProviderFragmentRootVtbl struct {
+	win.IUnknownVtbl
+	ElementProviderFromPoint uintptr
+	GetFocus                 uintptr
+}
+
+type iInvokeProviderVtbl struct {
+	win.IUnknownVtbl
+	Invoke uintptr
+}
+
+type iToggleProviderVtbl struct {
+	win.IUnknownVtbl
+	Toggle      uintptr
+	ToggleState uintptr
+}
+
+type iValueProviderVtbl struct {
+	win.IUnknownVtbl
+	SetValue   uintptr
+	Value      uintptr
+	IsReadOnly uintptr
+}
+
+type iSelectionProviderVtbl struct {
+	win.IUnknownVtbl
+	GetSelection        uintptr
+	CanSelectMultiple   uintptr
+	IsSelectionRequired uintptr
+}
+
+type iSelectionItemProviderVtbl struct {
+	win.IUnknownVtbl
+	Select              uintptr
+	AddToSelection      uintptr
+	RemoveFromSelection uintptr
+	IsSelected          uintptr
+	SelectionContainer  uintptr
+}
+
+var automationProviderVtbls [automationProviderKindCount]unsafe.Pointer
+
+func init() {
+	AppendToWalkInit(func() {
+		unknown := win.IUnknownVtbl{
+			QueryInterface: syscall.NewCallback(automationProvider_IUnknown_QueryInterface),
+			AddRef:         syscall.NewCallback(automationProvider_IUnknown_AddRef),
+			Release:        syscall.NewCallback(automationProvider_IUnknown_Release),
+		}
+
+		// ElementProviderFromPoint takes two doubles, which need help per
+		// architecture to reach a callback.
+		elementProviderFromPoint := newElementProviderFromPointCallback()
+
+		automationProviderVtbls = [automationProviderKindCount]unsafe.Pointer{
+			unsafe.Pointer(&iRawElementProviderSimpleVtbl{
+				unknown,
+				syscall.NewCallback(automationProvider_IRawElementProviderSimple_ProviderOptions),
+				syscall.NewCallback(automationProvider_IRawElementProviderSimple_GetPatternProvider),
+				syscall.NewCallback(automationProvider_IRawElementProviderSimple_GetPropertyValue),
+				syscall.NewCallback(automationProvider_IRawElementProviderSimple_HostRawElementProvider),
+			}),
+			unsafe.Pointer(&iRawElementProviderFragmentVtbl{
+				unknown,
+				syscall.NewCallback(automationProvider_IRawElementProviderFragment_Navigate),
+				syscall.NewCallback(automationProvider_IRawElementProviderFragment_GetRuntimeId),
+				syscall.NewCallback(automationProvider_IRawElementProviderFragment_BoundingRectangle),
+				syscall.NewCallback(automationProvider_IRawElementProviderFragment_GetEmbeddedFragmentRoots),
+				syscall.NewCallback(automationProvider_IRawElementProviderFragment_SetFocus),
+				syscall.NewCallback(automationProvider_IRawElementProviderFragment_FragmentRoot),
+			}),
+			unsafe.Pointer(&iRawElementProviderFragmentRootVtbl{
+				unknown,
+				elementProviderFromPoint,
+				syscall.NewCallback(automationProvider_IRawElementProviderFragmentRoot_GetFocus),
+			}),
+			unsafe.Pointer(&iInvokeProviderVtbl{
+				unknown,
+				syscall.NewCallback(automationProvider_IInvokeProvider_Invoke),
+			}),
+			unsafe.Pointer(&iToggleProviderVtbl{
+				unknown,
+				syscall.NewCallback(automationProvider_IToggleProvider_Toggle),
+				syscall.NewCallback(automationProvider_IToggleProvider_ToggleState),
+			}),
+			unsafe.Pointer(&iValueProviderVtbl{
+				unknown,
+				syscall.NewCallback(automationProvider_IValueProvider_SetValue),
+				syscall.NewCallback(automationProvider_IValueProvider_Value),
+				syscall.NewCallback(automationProvider_IValueProvider_IsReadOnly),
+			}),
+			unsafe.Pointer(&iSelectionProviderVtbl{
+				unknown,
+				syscall.NewCallback(automationProvider_ISelectionProvider_GetSelection),
+				syscall.NewCallback(automationProvider_ISelectionProvider_CanSelectMultiple),
+				syscall.NewCallback(automationProvider_ISelectionProvider_IsSelectionRequired),
+			}),
+			unsafe.Pointer(&iSelectionItemProviderVtbl{
+				unknown,
+				syscall.NewCallback(automationProvider_ISelectionItemProvider_Select),
+				syscall.NewCallback(automationProvider_ISelectionItemProvider_AddToSelection),
+				syscall.NewCallback(automationProvider_ISelectionItemProvider_RemoveFromSelection),
+				syscall.NewCallback(automationProvider_ISelectionItemProvider_IsSelected),
+				syscall.NewCallback(automationProvider_ISelectionItemProvider_SelectionContainer),
+			}),
+		}
+	})
+}
+
+// automationProvider is a COM object implementing one provider interface of
+// an AutomationElement. Unlike other COM objects of walk, it is reference
+// counted, as UI Automation clients may hold on to it for as long as they
+// like, even after the element was removed.
+type automationProvider struct {
+	lpVtbl unsafe.Pointer
+	refs   int32
+	el     *AutomationElement
+}
+
+var (
+	automationProvidersMutex sync.Mutex
+
+	// automationProvidersReferenced keeps the providers UI Automation holds
+	// references to from being garbage collected.
+	automationProvidersReferenced = make(map[*automationProvider]struct{})
+)
+
+// provider returns the provider of kind for the AutomationElement, with a
+// reference the caller has to release.
+func (el *AutomationElement) provider(kind automationProviderKind) *automationProvider {
+	automationProvidersMutex.Lock()
+	p := el.providers[kind]
+	if p == nil {
+		p = &automationProvider{lpVtbl: automationProviderVtbls[kind], el: el}
+		el.providers[kind] = p
+	}
+	automationProvidersMutex.Unlock()
+
+	p.addRef()
+
+	return p
+}
+
+func (p *automationProvider) addRef() uintptr {
+	automationProvidersMutex.Lock()
+	defer automationProvidersMutex.Unlock()
+
+	p.refs++
+	if p.refs == 1 {
+		automationProvidersReferenced[p] = struct{}{}
+	}
+
+	return uintptr(p.refs)
+}
+
+func (p *automationProvider) release() uintptr {
+	automationProvidersMutex.Lock()
+	defer automationProvidersMutex.Unlock()
+
+	p.refs--
+	if p.refs == 0 {
+		delete(automationProvidersReferenced, p)
+	}
+
+	return uintptr(p.refs)
+}
+
+// returnProvider stores the provider of kind for el in ret, or nil if el is
+// nil.
+func returnProvider(el *AutomationElement, kind automationProviderKind, ret *unsafe.Pointer) uintptr {
+	if el == nil {
+		*ret = nil
+	} else {
+		*ret = unsafe.Pointer(el.provider(kind))
+	}
+
+	return win.S_OK
+}
+
+func automationProvider_IUnknown_QueryInterface(p *automationProvider, riid win.REFIID, ppvObject *unsafe.Pointer) uintptr {
+	el := p.el
+
+	// The simple provider is the identity of the element.
+	if win.EqualREFIID(riid, &win.IID_IUnknown) {
+		return returnProvider(el, automationProviderSimple, ppvObject)
+	}
+
+	for kind, iid := range automationProviderIIDs {
+		if win.EqualREFIID(riid, iid) && el.supports(automationProviderKind(kind)) {
+			return returnProvider(el, automationProviderKind(kind), ppvObject)
+		}
+	}
+
+	*ppvObject = nil
+	return win.E_NOINTERFACE
+}
+
+func automationProvider_IUnknown_AddRef(p *automationProvider) uintptr {
+	return p.addRef()
+}
+
+func automationProvider_IUnknown_Release(p *automationProvider) uintptr {
+	return p.release()
+}
+
+func automationProvider_IRawElementProviderSimple_ProviderOptions(p *automationProvider, ret *int32) uintptr {
+	// Without UseComThreading, UI Automation calls providers on its own
+	// threads. With it, calls are marshaled to the UI thread, which is a
+	// single-threaded apartment, as walk widgets must only be used there.
+	*ret = _ProviderOptions_ServerSideProvider | _ProviderOptions_UseComThreading
+
+	return win.S_OK
+}
+
+func automationProvider_IRawElementProviderSimple_GetPatternProvider(p *automationProvider, patternId uintptr, ret *unsafe.Pointer) uintptr {
+	*ret = nil
+
+	if !p.el.available() {
+		return _UIA_E_ELEMENTNOTAVAILABLE
+	}
+
+	if kind, ok := automationPatternProviders[int32(patternId)]; ok && p.el.supports(kind) {
+		return returnProvider(p.el, kind, ret)
+	}
+
+	return win.S_OK
+}
+
+func automationProvider_IRawElementProviderSimple_GetPropertyValue(p *automationProvider, propertyId uintptr, ret *win.VARIANT) uintptr {
+	*ret = win.VARIANT{}
+
+	if !p.el.available() {
+		return _UIA_E_ELEMENTNOTAVAILABLE
+	}
+
+	p.el.propertyValue(int32(propertyId), ret)
+
+	return win.S_OK
+}
+
+func automationProvider_IRawElementProviderSimple_HostRawElementProvider(p *automationProvider, ret *unsafe.Pointer) uintptr {
+	*ret = nil
+
+	// The window provides the properties the root does not.
+	if p.el.isRoot() {
+		return uintptr(uiaHostProviderFromHwnd(p.el.tree.cw.hWnd, ret))
+	}
+
+	return win.S_OK
+}
+
+func automationProvider_IRawElementProviderFragment_Navigate(p *automationProvider, direction uintptr, ret *unsafe.Pointer) uintptr {
+	*ret = nil
+
+	el := p.el
+	if !el.available() {
+		return _UIA_E_ELEMENTNOTAVAILABLE
+	}
+
+	var target *AutomationElement
+
+	switch int32(direction) {
+	case _NavigateDirection_Parent:
+		// The parent of the root is the window, which UI Automation knows.
+		target = el.parent
+
+	case _NavigateDirection_NextSibling, _NavigateDirection_PreviousSibling:
+		if el.parent == nil {
+			break
+		}
+
+		siblings := el.parent.children
+		for i, sibling := range siblings {
+			if sibling != el {
+				continue
+			}
+
+			if int32(direction) == _NavigateDirection_NextSibling && i+1 < len(siblings) {
+				target = siblings[i+1]
+			} else if int32(direction) == _NavigateDirection_PreviousSibling && i > 0 {
+				target = siblings[i-1]
+			}
+			break
+		}
+
+	case _NavigateDirection_FirstChild:
+		if len(el.children) > 0 {
+			target = el.children[0]
+		}
+
+	case _NavigateDirection_LastChild:
+		if len(el.children) > 0 {
+			target = el.children[len(el.children)-1]
+		}
+	}
+
+	return returnProvider(target, automationProviderFragment, ret)
+}
+
+func automationProvider_IRawElementProviderFragment_GetRuntimeId(p *automationProvider, ret **win.SAFEARRAY) uintptr {
+	*ret = nil
+
+	el := p.el
+	if !el.available() {
+		return _UIA_E_ELEMENTNOTAVAILABLE
+	}
+
+	if el.isRoot() {
+		return win.S_OK
+	}
+
+	runtimeID := el.runtimeID()
+
+	sa := safeArrayCreateVector(win.VT_I4, len(runtimeID))
+	if sa == nil {
+		return win.E_OUTOFMEMORY
+	}
+
+	for i := range runtimeID {
+		safeArrayPutElement(sa, int32(i), unsafe.Pointer(&runtimeID[i]))
+	}
+
+	*ret = sa
+
+	return win.S_OK
+}
+
+func automationProvider_IRawElementProviderFragment_BoundingRectangle(p *automationProvider, ret *uiaRect) uintptr {
+	*ret = uiaRect{}
+
+	el := p.el
+	if !el.available() {
+		return _UIA_E_ELEMENTNOTAVAILABLE
+	}
+
+	// The window provides the bounds of the root.
+	if el.isRoot() {
+		return win.S_OK
+	}
+
+	pt := win.POINT{X: int32(el.bounds.X), Y: int32(el.bounds.Y)}
+	win.ClientToScreen(el.tree.cw.hWnd, &pt)
+
+	*ret = uiaRect{
+		left:   float64(pt.X),
+		top:    float64(pt.Y),
+		width:  float64(el.bounds.Width),
+		height: float64(el.bounds.Height),
+	}
+
+	return win.S_OK
+}
+
+func automationProvider_IRawElementProviderFragment_GetEmbeddedFragmentRoots(p *automationProvider, ret **win.SAFEARRAY) uintptr {
+	*ret = nil
+
+	return win.S_OK
+}
+
+func automationProvider_IRawElementProviderFragment_SetFocus(p *automationProvider) uintptr {
+	el := p.el
+	if !el.available() {
+		return _UIA_E_ELEMENTNOTAVAILABLE
+	}
+
+	el.tree.cw.SetFocus()
+
+	if el.isRoot() || !el.focusable {
+		return win.S_OK
+	}
+
+	el.SetFocused()
+
+	if el.focusFunc != nil {
+		el.focusFunc()
+	}
+
+	return win.S_OK
+}
+
+func automationProvider_IRawElementProviderFragment_FragmentRoot(p *automationProvider, ret *unsafe.Pointer) uintptr {
+	*ret = nil
+
+	if !p.el.available() {
+		return _UIA_E_ELEMENTNOTAVAILABLE
+	}
+
+	return returnProvider(p.el.tree.root, automationProviderFragmentRoot, ret)
+}
+
+func (p *automationProvider) elementProviderFromPoint(pt win.POINT, ret *unsafe.Pointer) uintptr {
+	*ret = nil
+
+	el := p.el
+	if !el.available() {
+		return _UIA_E_ELEMENTNOTAVAILABLE
+	}
+
+	win.ScreenToClient(el.tree.cw.hWnd, &pt)
+
+	target := el.elementFromPoint(Point{int(pt.X), int(pt.Y)})
+	if target == el {
+		// UI Automation uses the root itself then.
+		target = nil
+	}
+
+	return returnProvider(target, automationProviderFragment, ret)
+}
+
+func automationProvider_IRawElementProviderFragmentRoot_GetFocus(p *automationProvider, ret *unsafe.Pointer) uintptr {
+	*ret = nil
+
+	if !p.el.available() {
+		return _UIA_E_ELEMENTNOTAVAILABLE
+	}
+
+	return returnProvider(p.el.tree.focused, automationProviderFragment, ret)
+}
+
+// operable returns the HRESULT for a request to operate el, or S_OK if it can
+// be operated.
+func (el *AutomationElement) operable() uintptr {
+	switch {
+	case !el.available():
+		return _UIA_E_ELEMENTNOTAVAILABLE
+
+	case el.disabled || !el.tree.cw.Enabled():
+		return _UIA_E_ELEMENTNOTENABLED
+	}
+
+	return win.S_OK
+}
+
+func automationProvider_IInvokeProvider_Invoke(p *automationProvider) uintptr {
+	el := p.el
+	if hr := el.operable(); hr != win.S_OK {
+		return hr
+	}
+
+	if invoke := el.invokeFunc; invoke != nil {
+		el.raiseEvent(_UIA_Invoke_InvokedEventId)
+
+		// Invoke must not block, e.g. on a dialog the function shows.
+		el.tree.cw.Synchronize(invoke)
+	}
+
+	return win.S_OK
+}
+
+func automationProvider_IToggleProvider_Toggle(p *automationProvider) uintptr {
+	el := p.el
+	if hr := el.operable(); hr != win.S_OK {
+		return hr
+	}
+
+	if el.toggleFunc != nil {
+		el.toggleFunc()
+	}
+
+	return win.S_OK
+}
+
+func automationProvider_IToggleProvider_ToggleState(p *automationProvider, ret *int32) uintptr {
+	*ret = int32(p.el.toggleState)
+
+	return win.S_OK
+}
+
+func automationProvider_IValueProvider_SetValue(p *automationProvider, value *uint16) uintptr {
+	el := p.el
+	if hr := el.operable(); hr != win.S_OK {
+		return hr
+	}
+
+	if el.setValueFunc == nil {
+		return _UIA_E_ELEMENTNOTENABLED
+	}
+
+	if err := el.setValueFunc(win.UTF16PtrToString(value)); err != nil {
+		return win.E_INVALIDARG
+	}
+
+	return win.S_OK
+}
+
+func automationProvider_IValueProvider_Value(p *automationProvider, ret **uint16) uintptr {
+	*ret = bstrFromString(p.el.value)
+
+	return win.S_OK
+}
+
+func automationProvider_IValueProvider_IsReadOnly(p *automationProvider, ret *win.BOOL) uintptr {
+	*ret = win.BoolToBOOL(p.el.setValueFunc == nil)
+
+	return win.S_OK
+}
+
+func automationProvider_ISelectionProvider_GetSelection(p *automationProvider, ret **win.SAFEARRAY) uintptr {
+	*ret = nil
+
+	el := p.el
+	if !el.available() {
+		return _UIA_E_ELEMENTNOTAVAILABLE
+	}
+
+	var selected []*AutomationElement
+	for _, child := range el.children {
+		if child.selected {
+			selected = append(selected, child)
+		}
+	}
+
+	sa := safeArrayCreateVector(win.VT_UNKNOWN, len(selected))
+	if sa == nil {
+		return win.E_OUTOFMEMORY
+	}
+
+	for i, child := range selected {
+		// The array takes its own reference.
+		cp := child.provider(automationProviderSimple)
+		safeArrayPutElement(sa, int32(i), unsafe.Pointer(cp))
+		cp.release()
+	}
+
+	*ret = sa
+
+	return win.S_OK
+}
+
+func automationProvider_ISelectionProvider_CanSelectMultiple(p *automationProvider, ret *win.BOOL) uintptr {
+	*ret = win.BoolToBOOL(p.el.selectsMultiple)
+
+	return win.S_OK
+}
+
+func automationProvider_ISelectionProvider_IsSelectionRequired(p *automationProvider, ret *win.BOOL) uintptr {
+	*ret = win.BoolToBOOL(p.el.selectionRequired)
+
+	return win.S_OK
+}
+
+// changeSelection asks the container of el to apply op to el.
+func (el *AutomationElement) changeSelection(op AutomationSelectionOp) uintptr {
+	if hr := el.operable(); hr != win.S_OK {
+		return hr
+	}
+
+	container := el.parent
+	if container == nil || container.selectionFunc == nil {
+		return _UIA_E_INVALIDOPERATION
+	}
+
+	if op == AutomationAddToSelection && !container.selectsMultiple && !el.selected {
+		for _, sibling := range container.children {
+			if sibling.selected {
+				return _UIA_E_INVALIDOPERATION
+			}
+		}
+	}
+
+	container.selectionFunc(el, op)
+
+	return win.S_OK
+}
+
+func automationProvider_ISelectionItemProvider_Select(p *automationProvider) uintptr {
+	return p.el.changeSelection(AutomationSelect)
+}
+
+func automationProvider_ISelectionItemProvider_AddToSelection(p *automationProvider) uintptr {
+	return p.el.changeSelection(AutomationAddToSelection)
+}
+
+func automationProvider_ISelectionItemProvider_RemoveFromSelection(p *automationProvider) uintptr {
+	return p.el.changeSelection(AutomationRemoveFromSelection)
+}
+
+func automationProvider_ISelectionItemProvider_IsSelected(p *automationProvider, ret *win.BOOL) uintptr {
+	*ret = win.BoolToBOOL(p.el.selected)
+
+	return win.S_OK
+}
+
+func automationProvider_ISelectionItemProvider_SelectionContainer(p *automationProvider, ret *unsafe.Pointer) uintptr {
+	*ret = nil
+
+	if !p.el.available() {
+		return _UIA_E_ELEMENTNOTAVAILABLE
+	}
+
+	return returnProvider(p.el.parent, automationProviderSimple, ret)
+}
@@ -26,6 +26,7 @@ type Bitmap struct {
 	size               Size // in native pixels
 	dpi                int
 	transparencyStatus transparencyStatus
+	nineGridMargins    Margins // in 1/96" units
 }
 
 type transparencyStatus byte
@@ -396,6 +397,18 @@ func (bmp *Bitmap) Dispose() {
 	}
 }
 
+// NineGridMargins returns the default margins, in 1/96" units, used when the
+// bitmap is drawn using Canvas.DrawImageNineGridPixels with zero margins.
+func (bmp *Bitmap) NineGridMargins() Margins {
+	return bmp.nineGridMargins
+}
+
+// SetNineGridMargins sets the default margins, in 1/96" units, used when the
+// bitmap is drawn using Canvas.DrawImageNineGridPixels with zero margins.
+func (bmp *Bitmap) SetNineGridMargins(margins Margins) {
+	bmp.nineGridMargins = margins
+}
+
 // Size returns bitmap size in 1/96" units.
 func (bmp *Bitmap) Size() Size {
 	return SizeTo96DPI(bmp.size, bmp.dpi)
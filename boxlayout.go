@@ -71,6 +71,7 @@ func (l *BoxLayout) SetOrientation(value Orientation) error {
 	return nil
 }
 
+// StretchFactor returns the stretch factor of widget, which defaults to 1.
 func (l *BoxLayout) StretchFactor(widget Widget) int {
 	if factor, ok := l.hwnd2StretchFactor[widget.Handle()]; ok {
 		return factor
@@ -79,6 +80,11 @@ func (l *BoxLayout) StretchFactor(widget Widget) int {
 	return 1
 }
 
+// SetStretchFactor sets the stretch factor of widget.
+//
+// Space left over after all items got their minimum or ideal size is shared
+// among the items that can grow, in proportion to their stretch factors.
+// Greedy items are served first, then stretchy spacers, then all others.
 func (l *BoxLayout) SetStretchFactor(widget Widget, factor int) error {
 	if factor != l.StretchFactor(widget) {
 		if l.container == nil {
@@ -469,7 +475,21 @@ func boxLayoutItems(container ContainerLayoutItem, items []LayoutItem, orientati
 			s2 = prefSizes2[i]
 		}
 
-		align := item.Geometry().Alignment
+		// Honor the min and max size on the cross axis too, without leaving
+		// the space of the layout.
+		geometry := item.Geometry()
+		min2, max2 := geometry.MinSize.Height, geometry.MaxSize.Height
+		if orientation == Vertical {
+			min2, max2 = geometry.MinSize.Width, geometry.MaxSize.Width
+		}
+		if max2 > 0 && s2 > max2 {
+			s2 = max2
+		}
+		if s2 < min2 {
+			s2 = mini(min2, space2)
+		}
+
+		align := geometry.Alignment
 		if align == AlignHVDefault {
 			align = alignment
 		}
@@ -7,6 +7,8 @@
 package walk
 
 import (
+	"image"
+	"image/color"
 	"unsafe"
 
 	"github.com/tailscale/win"
@@ -267,7 +269,8 @@ func (b *HatchBrush) simple() bool {
 
 type BitmapBrush struct {
 	brushBase
-	bitmap *Bitmap
+	bitmap     *Bitmap
+	ownsBitmap bool
 }
 
 func NewBitmapBrush(bitmap *Bitmap) (*BitmapBrush, error) {
@@ -283,6 +286,22 @@ func NewBitmapBrush(bitmap *Bitmap) (*BitmapBrush, error) {
 	return &BitmapBrush{brushBase: brushBase{hBrush: hBrush}, bitmap: bitmap}, nil
 }
 
+func (b *BitmapBrush) Dispose() {
+	b.brushBase.Dispose()
+
+	if b.ownsBitmap {
+		b.bitmap.Dispose()
+	}
+}
+
+func (b *BitmapBrush) detachWindow(wb *WindowBase) {
+	b.brushBase.detachWindow(wb)
+
+	if b.hBrush == 0 && b.ownsBitmap {
+		b.bitmap.Dispose()
+	}
+}
+
 func (b *BitmapBrush) logbrush() *win.LOGBRUSH {
 	return &win.LOGBRUSH{LbStyle: win.BS_DIBPATTERN, LbColor: win.DIB_RGB_COLORS, LbHatch: uintptr(b.bitmap.hPackedDIB)}
 }
@@ -295,6 +314,42 @@ func (b *BitmapBrush) simple() bool {
 	return false
 }
 
+// NewPatternBrush creates a BitmapBrush from an 8x8 monochrome pattern, like
+// HatchBrush but with an arbitrary pattern and an opaque background. Each
+// byte of pattern describes one row, the most significant bit being the
+// leftmost pixel. Set bits are painted using foreground, cleared bits using
+// background.
+func NewPatternBrush(pattern [8]byte, foreground, background Color) (*BitmapBrush, error) {
+	fg := color.RGBA{foreground.R(), foreground.G(), foreground.B(), 0xff}
+	bg := color.RGBA{background.R(), background.G(), background.B(), 0xff}
+
+	im := image.NewRGBA(image.Rect(0, 0, 8, 8))
+	for y, row := range pattern {
+		for x := 0; x < 8; x++ {
+			if row&(0x80>>x) != 0 {
+				im.SetRGBA(x, y, fg)
+			} else {
+				im.SetRGBA(x, y, bg)
+			}
+		}
+	}
+
+	bmp, err := NewBitmapFromImageForDPI(im, 96)
+	if err != nil {
+		return nil, err
+	}
+
+	brush, err := NewBitmapBrush(bmp)
+	if err != nil {
+		bmp.Dispose()
+		return nil, err
+	}
+
+	brush.ownsBitmap = true
+
+	return brush, nil
+}
+
 type GradientStop struct {
 	Offset float64
 	Color  Color
@@ -80,6 +80,7 @@ func (b *Button) Image() Image {
 
 func (b *Button) SetImage(image Image) error {
 	var typ, handle uintptr
+	var pins iconCachePins
 	switch img := image.(type) {
 	case nil:
 
@@ -92,7 +93,7 @@ func (b *Button) SetImage(image Image) error {
 		handle = uintptr(img.handleForDPI(b.DPI()))
 
 	default:
-		bmp, err := iconCache.Bitmap(image, b.DPI())
+		bmp, err := iconCache.pinnedBitmap(&pins, image, b.DPI())
 		if err != nil {
 			return err
 		}
@@ -103,6 +104,9 @@ func (b *Button) SetImage(image Image) error {
 
 	b.SendMessage(win.BM_SETIMAGE, typ, handle)
 
+	iconCache.unpin(&b.imagePins)
+	b.imagePins = pins
+
 	b.image = image
 
 	b.RequestLayout()
@@ -0,0 +1,444 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"sort"
+	"time"
+	"unsafe"
+
+	"github.com/tailscale/win"
+)
+
+// Calendar displays one or more months, using the SysMonthCal32 common
+// control, and lets the user select a date or, if created with
+// NewCalendarWithMultiSelect, a range of consecutive dates.
+//
+// Dates can be displayed in bold, e.g. to mark days that have appointments.
+// Since only a few months are visible at a time, apps can attach to
+// VisibleRangeChanged and provide the bold dates lazily.
+type Calendar struct {
+	WidgetBase
+	boldDates                    map[time.Time]bool
+	dayStateBuf                  []uint32
+	visibleFirst                 time.Time
+	visibleLast                  time.Time
+	selectionChangedPublisher    EventPublisher
+	visibleRangeChangedPublisher EventPublisher
+}
+
+// NewCalendar returns a new Calendar that allows selecting a single date as
+// child of parent.
+func NewCalendar(parent Container) (*Calendar, error) {
+	return newCalendar(parent, 0)
+}
+
+// NewCalendarWithMultiSelect returns a new Calendar that allows selecting a
+// range of consecutive dates as child of parent. By default, up to 7 days can
+// be selected, see SetMaxSelectionCount.
+func NewCalendarWithMultiSelect(parent Container) (*Calendar, error) {
+	return newCalendar(parent, _MCS_MULTISELECT)
+}
+
+func newCalendar(parent Container, style uint32) (*Calendar, error) {
+	cal := &Calendar{boldDates: make(map[time.Time]bool)}
+
+	if err := InitWidget(
+		cal,
+		parent,
+		"SysMonthCal32",
+		win.WS_TABSTOP|win.WS_VISIBLE|_MCS_DAYSTATE|style,
+		0); err != nil {
+		return nil, err
+	}
+
+	if FocusEffect != nil {
+		cal.GraphicsEffects().Add(FocusEffect)
+	}
+
+	cal.visibleFirst, cal.visibleLast = cal.VisibleRange()
+
+	cal.MustRegisterProperty("Date", NewProperty(
+		func() interface{} {
+			return cal.Date()
+		},
+		func(v interface{}) error {
+			return cal.SetDate(assertTimeOr(v, time.Time{}))
+		},
+		cal.selectionChangedPublisher.Event()))
+
+	return cal, nil
+}
+
+// dateOf returns the date part of t, at midnight local time.
+func dateOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+}
+
+func dateFromSystemTime(st *win.SYSTEMTIME) time.Time {
+	return time.Date(int(st.WYear), time.Month(st.WMonth), int(st.WDay), 0, 0, 0, 0, time.Local)
+}
+
+func systemTimeFromDate(t time.Time) win.SYSTEMTIME {
+	return win.SYSTEMTIME{
+		WYear:      uint16(t.Year()),
+		WMonth:     uint16(t.Month()),
+		WDay:       uint16(t.Day()),
+		WDayOfWeek: uint16(t.Weekday()),
+	}
+}
+
+// MultiSelect returns whether a range of dates can be selected.
+func (cal *Calendar) MultiSelect() bool {
+	return cal.hasStyleBits(_MCS_MULTISELECT)
+}
+
+// Date returns the selected date. If a range is selected, its first date is
+// returned.
+func (cal *Calendar) Date() time.Time {
+	start, _ := cal.SelectionRange()
+
+	return start
+}
+
+// SetDate selects date. If a range can be selected, it is collapsed to date.
+func (cal *Calendar) SetDate(date time.Time) error {
+	if cal.MultiSelect() {
+		return cal.SetSelectionRange(date, date)
+	}
+
+	date = dateOf(date)
+	if date.Equal(cal.Date()) {
+		return nil
+	}
+
+	st := systemTimeFromDate(date)
+	if 0 == cal.SendMessage(_MCM_SETCURSEL, 0, uintptr(unsafe.Pointer(&st))) {
+		return newError("SendMessage(MCM_SETCURSEL)")
+	}
+
+	cal.selectionChangedPublisher.Publish()
+
+	return nil
+}
+
+// SelectionRange returns the first and last selected date. If only a single
+// date can be selected, both are the same.
+func (cal *Calendar) SelectionRange() (start, end time.Time) {
+	if !cal.MultiSelect() {
+		var st win.SYSTEMTIME
+		if 0 == cal.SendMessage(_MCM_GETCURSEL, 0, uintptr(unsafe.Pointer(&st))) {
+			return
+		}
+
+		date := dateFromSystemTime(&st)
+
+		return date, date
+	}
+
+	var st [2]win.SYSTEMTIME
+	if 0 == cal.SendMessage(_MCM_GETSELRANGE, 0, uintptr(unsafe.Pointer(&st[0]))) {
+		return
+	}
+
+	return dateFromSystemTime(&st[0]), dateFromSystemTime(&st[1])
+}
+
+// SetSelectionRange selects the dates from start through end. The Calendar
+// must have been created with NewCalendarWithMultiSelect and the range must
+// not exceed MaxSelectionCount days.
+func (cal *Calendar) SetSelectionRange(start, end time.Time) error {
+	if !cal.MultiSelect() {
+		return newError("multi-selection not enabled")
+	}
+
+	start, end = dateOf(start), dateOf(end)
+	if end.Before(start) {
+		return newError("invalid range")
+	}
+
+	if oldStart, oldEnd := cal.SelectionRange(); start.Equal(oldStart) && end.Equal(oldEnd) {
+		return nil
+	}
+
+	st := [2]win.SYSTEMTIME{systemTimeFromDate(start), systemTimeFromDate(end)}
+	if 0 == cal.SendMessage(_MCM_SETSELRANGE, 0, uintptr(unsafe.Pointer(&st[0]))) {
+		return newError("SendMessage(MCM_SETSELRANGE)")
+	}
+
+	cal.selectionChangedPublisher.Publish()
+
+	return nil
+}
+
+// MaxSelectionCount returns the maximum number of days that can be selected.
+func (cal *Calendar) MaxSelectionCount() int {
+	if !cal.MultiSelect() {
+		return 1
+	}
+
+	return int(cal.SendMessage(_MCM_GETMAXSELCOUNT, 0, 0))
+}
+
+// SetMaxSelectionCount sets the maximum number of days that can be selected.
+func (cal *Calendar) SetMaxSelectionCount(count int) error {
+	if !cal.MultiSelect() {
+		return newError("multi-selection not enabled")
+	}
+
+	if 0 == cal.SendMessage(_MCM_SETMAXSELCOUNT, uintptr(count), 0) {
+		return newError("SendMessage(MCM_SETMAXSELCOUNT)")
+	}
+
+	return nil
+}
+
+// SelectionChanged returns the event that is published when the selected date
+// or range changes.
+func (cal *Calendar) SelectionChanged() *Event {
+	return cal.selectionChangedPublisher.Event()
+}
+
+// Range returns the minimum and maximum selectable date. A zero time means
+// there is no limit.
+func (cal *Calendar) Range() (min, max time.Time) {
+	var st [2]win.SYSTEMTIME
+
+	ret := cal.SendMessage(_MCM_GETRANGE, 0, uintptr(unsafe.Pointer(&st[0])))
+
+	if ret&win.GDTR_MIN > 0 {
+		min = dateFromSystemTime(&st[0])
+	}
+
+	if ret&win.GDTR_MAX > 0 {
+		max = dateFromSystemTime(&st[1])
+	}
+
+	return
+}
+
+// SetRange sets the minimum and maximum selectable date. A zero time means
+// there is no limit.
+func (cal *Calendar) SetRange(min, max time.Time) error {
+	if !min.IsZero() && !max.IsZero() && dateOf(max).Before(dateOf(min)) {
+		return newError("invalid range")
+	}
+
+	var st [2]win.SYSTEMTIME
+	var wParam uintptr
+
+	if !min.IsZero() {
+		wParam |= win.GDTR_MIN
+		st[0] = systemTimeFromDate(min)
+	}
+
+	if !max.IsZero() {
+		wParam |= win.GDTR_MAX
+		st[1] = systemTimeFromDate(max)
+	}
+
+	if 0 == cal.SendMessage(_MCM_SETRANGE, wParam, uintptr(unsafe.Pointer(&st[0]))) {
+		return newError("SendMessage(MCM_SETRANGE)")
+	}
+
+	return nil
+}
+
+// BoldDates returns the dates that are displayed in bold, in ascending order.
+func (cal *Calendar) BoldDates() []time.Time {
+	dates := make([]time.Time, 0, len(cal.boldDates))
+	for date := range cal.boldDates {
+		dates = append(dates, date)
+	}
+
+	sort.Slice(dates, func(i, j int) bool {
+		return dates[i].Before(dates[j])
+	})
+
+	return dates
+}
+
+// SetBoldDates sets the dates that are displayed in bold, replacing any
+// previous ones.
+func (cal *Calendar) SetBoldDates(dates []time.Time) {
+	cal.boldDates = make(map[time.Time]bool, len(dates))
+	for _, date := range dates {
+		cal.boldDates[dateOf(date)] = true
+	}
+
+	cal.updateDayState()
+}
+
+// SetDateBold sets whether date is displayed in bold.
+func (cal *Calendar) SetDateBold(date time.Time, bold bool) {
+	date = dateOf(date)
+	if bold == cal.boldDates[date] {
+		return
+	}
+
+	if bold {
+		cal.boldDates[date] = true
+	} else {
+		delete(cal.boldDates, date)
+	}
+
+	cal.updateDayState()
+}
+
+// VisibleRange returns the first and last date that is currently displayed,
+// including the days of adjacent months that fill up the first and last week.
+func (cal *Calendar) VisibleRange() (first, last time.Time) {
+	var st [2]win.SYSTEMTIME
+	if 0 == cal.SendMessage(_MCM_GETMONTHRANGE, _GMR_DAYSTATE, uintptr(unsafe.Pointer(&st[0]))) {
+		return
+	}
+
+	return dateFromSystemTime(&st[0]), dateFromSystemTime(&st[1])
+}
+
+// VisibleRangeChanged returns the event that is published when the user
+// navigates to other months or the number of displayed months changes. Apps
+// can use it to provide bold dates for VisibleRange on demand.
+func (cal *Calendar) VisibleRangeChanged() *Event {
+	return cal.visibleRangeChangedPublisher.Event()
+}
+
+// WeekNumbers returns whether week numbers are displayed left of each week.
+func (cal *Calendar) WeekNumbers() bool {
+	return cal.hasStyleBits(_MCS_WEEKNUMBERS)
+}
+
+// SetWeekNumbers sets whether week numbers are displayed left of each week.
+func (cal *Calendar) SetWeekNumbers(weekNumbers bool) error {
+	if err := cal.ensureStyleBits(_MCS_WEEKNUMBERS, weekNumbers); err != nil {
+		return err
+	}
+
+	cal.RequestLayout()
+
+	return nil
+}
+
+// TodayLinkVisible returns whether the link to today's date is displayed below
+// the months.
+func (cal *Calendar) TodayLinkVisible() bool {
+	return !cal.hasStyleBits(_MCS_NOTODAY)
+}
+
+// SetTodayLinkVisible sets whether the link to today's date is displayed below
+// the months.
+func (cal *Calendar) SetTodayLinkVisible(visible bool) error {
+	if err := cal.ensureStyleBits(_MCS_NOTODAY, !visible); err != nil {
+		return err
+	}
+
+	cal.RequestLayout()
+
+	return nil
+}
+
+// dayStates returns the MONTHDAYSTATE bit masks for count months, starting
+// with the month of start.
+func (cal *Calendar) dayStates(start *win.SYSTEMTIME, count int) []uint32 {
+	states := make([]uint32, count)
+
+	first := int(start.WYear)*12 + int(start.WMonth) - 1
+	for date := range cal.boldDates {
+		if i := date.Year()*12 + int(date.Month()) - 1 - first; i >= 0 && i < count {
+			states[i] |= 1 << uint(date.Day()-1)
+		}
+	}
+
+	return states
+}
+
+// updateDayState pushes the bold dates of the displayed months to the control.
+func (cal *Calendar) updateDayState() {
+	var st [2]win.SYSTEMTIME
+	count := int(cal.SendMessage(_MCM_GETMONTHRANGE, _GMR_DAYSTATE, uintptr(unsafe.Pointer(&st[0]))))
+	if count == 0 {
+		return
+	}
+
+	states := cal.dayStates(&st[0], count)
+
+	cal.SendMessage(_MCM_SETDAYSTATE, uintptr(count), uintptr(unsafe.Pointer(&states[0])))
+}
+
+func (cal *Calendar) checkVisibleRange() {
+	first, last := cal.VisibleRange()
+	if first.Equal(cal.visibleFirst) && last.Equal(cal.visibleLast) {
+		return
+	}
+
+	cal.visibleFirst, cal.visibleLast = first, last
+
+	cal.visibleRangeChangedPublisher.Publish()
+}
+
+func (cal *Calendar) WndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) uintptr {
+	switch msg {
+	case win.WM_NOTIFY:
+		switch ((*win.NMHDR)(unsafe.Pointer(lParam))).Code {
+		case _MCN_SELCHANGE:
+			cal.selectionChangedPublisher.Publish()
+
+		case _MCN_GETDAYSTATE:
+			nmds := (*nmDayState)(unsafe.Pointer(lParam))
+
+			// The control copies the states after we return, so the buffer
+			// is kept alive until the next request.
+			cal.dayStateBuf = cal.dayStates(&nmds.stStart, int(nmds.cDayState))
+			nmds.prgDayState = &cal.dayStateBuf[0]
+
+			// Handlers may call SetBoldDates, which must not happen before
+			// the control has taken the states from above.
+			cal.Synchronize(cal.checkVisibleRange)
+
+		case _MCN_VIEWCHANGE:
+			cal.Synchronize(cal.checkVisibleRange)
+		}
+
+	case win.WM_SIZE:
+		// The number of displayed months follows the size.
+		cal.Synchronize(cal.checkVisibleRange)
+	}
+
+	return cal.WidgetBase.WndProc(hwnd, msg, wParam, lParam)
+}
+
+func (cal *Calendar) CreateLayoutItem(ctx *LayoutContext) LayoutItem {
+	var rc win.RECT
+	cal.SendMessage(_MCM_GETMINREQRECT, 0, uintptr(unsafe.Pointer(&rc)))
+
+	size := Size{int(rc.Right - rc.Left), int(rc.Bottom - rc.Top)}
+	if todayWidth := int(cal.SendMessage(_MCM_GETMAXTODAYWIDTH, 0, 0)); cal.TodayLinkVisible() && todayWidth > size.Width {
+		size.Width = todayWidth
+	}
+
+	return &calendarLayoutItem{
+		minSize: size,
+	}
+}
+
+type calendarLayoutItem struct {
+	LayoutItemBase
+	minSize Size // in native pixels
+}
+
+func (*calendarLayoutItem) LayoutFlags() LayoutFlags {
+	return GrowableHorz | GrowableVert
+}
+
+func (li *calendarLayoutItem) IdealSize() Size {
+	return li.minSize
+}
+
+func (li *calendarLayoutItem) MinSize() Size {
+	return li.minSize
+}
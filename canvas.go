@@ -318,6 +318,90 @@ func (c *Canvas) DrawImageStretchedPixels(image Image, bounds Rectangle) error {
 	return image.drawStretched(c.hdc, bounds)
 }
 
+// DrawImageNineGrid draws image stretched to bounds in 1/96" units, while
+// preserving the corners and edges described by margins.
+//
+// Deprecated: Newer applications should use DrawImageNineGridPixels.
+func (c *Canvas) DrawImageNineGrid(image Image, bounds Rectangle, margins Margins) error {
+	return c.DrawImageNineGridPixels(image, RectangleFrom96DPI(bounds, c.DPI()), margins)
+}
+
+// DrawImageNineGridPixels draws image stretched to bounds in native pixels.
+// The corners of image described by margins, in 1/96" units, are drawn
+// unstretched, the edges are stretched along one axis only and the center is
+// stretched along both axes. If margins is zero and image is a *Bitmap, the
+// bitmap's NineGridMargins are used.
+func (c *Canvas) DrawImageNineGridPixels(image Image, bounds Rectangle, margins Margins) error {
+	if image == nil {
+		return newError("image cannot be nil")
+	}
+
+	dpi := c.DPI()
+
+	bmp, ok := image.(*Bitmap)
+	if !ok {
+		var err error
+		if bmp, err = iconCache.Bitmap(image, dpi); err != nil {
+			return err
+		}
+	}
+
+	if margins.isZero() {
+		margins = bmp.nineGridMargins
+	}
+
+	srcRects := nineGridRects(Rectangle{Width: bmp.size.Width, Height: bmp.size.Height}, MarginsFrom96DPI(margins, bmp.dpi))
+	dstRects := nineGridRects(bounds, MarginsFrom96DPI(margins, dpi))
+
+	for i := range srcRects {
+		src, dst := srcRects[i], dstRects[i]
+		if src.Width <= 0 || src.Height <= 0 || dst.Width <= 0 || dst.Height <= 0 {
+			continue
+		}
+
+		if err := bmp.alphaBlendPart(c.hdc, dst, src, 0xff); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// nineGridRects splits bounds into a 3x3 grid of rectangles, row by row, whose
+// outer cells have the extents given by margins. Margins that do not fit into
+// bounds are shrunk proportionally.
+func nineGridRects(bounds Rectangle, margins Margins) (rects [9]Rectangle) {
+	fit := func(near, far, extent int) (int, int) {
+		if near+far <= extent {
+			return near, far
+		}
+		if near+far == 0 {
+			return 0, 0
+		}
+		n := near * extent / (near + far)
+		return n, extent - n
+	}
+
+	left, right := fit(margins.HNear, margins.HFar, bounds.Width)
+	top, bottom := fit(margins.VNear, margins.VFar, bounds.Height)
+
+	xs := [4]int{bounds.X, bounds.X + left, bounds.X + bounds.Width - right, bounds.X + bounds.Width}
+	ys := [4]int{bounds.Y, bounds.Y + top, bounds.Y + bounds.Height - bottom, bounds.Y + bounds.Height}
+
+	for row := 0; row < 3; row++ {
+		for col := 0; col < 3; col++ {
+			rects[row*3+col] = Rectangle{
+				X:      xs[col],
+				Y:      ys[row],
+				Width:  xs[col+1] - xs[col],
+				Height: ys[row+1] - ys[row],
+			}
+		}
+	}
+
+	return
+}
+
 // DrawBitmapWithOpacity draws bitmap with opacity at given location in 1/96" units stretched.
 //
 // Deprecated: Newer applications should use DrawBitmapWithOpacityPixels.
@@ -424,6 +508,27 @@ func (c *Canvas) DrawPolylinePixels(pen Pen, points []Point) error {
 	})
 }
 
+// FillPolygonPixels draws a filled polygon with given vertices in native
+// pixels.
+func (c *Canvas) FillPolygonPixels(brush Brush, points []Point) error {
+	if len(points) < 3 {
+		return nil
+	}
+
+	pts := make([]win.POINT, len(points))
+	for i, p := range points {
+		pts[i] = p.toPOINT()
+	}
+
+	return c.withBrushAndPen(brush, nullPenSingleton, func() error {
+		if !polygon(c.hdc, &pts[0], int32(len(pts))) {
+			return newError("Polygon failed")
+		}
+
+		return nil
+	})
+}
+
 // rectangle draws a rectangle in 1/96" units. sizeCorrection parameter is in native pixels.
 //
 // Deprecated: Newer applications should use rectanglePixels.
@@ -0,0 +1,46 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"testing"
+)
+
+func TestNineGridRects(t *testing.T) {
+	testCases := []struct {
+		bounds  Rectangle
+		margins Margins
+		want    [9]Rectangle
+	}{
+		{
+			Rectangle{0, 0, 100, 50},
+			Margins{HNear: 10, VNear: 5, HFar: 20, VFar: 15},
+			[9]Rectangle{
+				{0, 0, 10, 5}, {10, 0, 70, 5}, {80, 0, 20, 5},
+				{0, 5, 10, 30}, {10, 5, 70, 30}, {80, 5, 20, 30},
+				{0, 35, 10, 15}, {10, 35, 70, 15}, {80, 35, 20, 15},
+			},
+		},
+		{
+			// Margins exceeding bounds are shrunk proportionally.
+			Rectangle{10, 20, 10, 4},
+			Margins{HNear: 10, VNear: 4, HFar: 10, VFar: 4},
+			[9]Rectangle{
+				{10, 20, 5, 2}, {15, 20, 0, 2}, {15, 20, 5, 2},
+				{10, 22, 5, 0}, {15, 22, 0, 0}, {15, 22, 5, 0},
+				{10, 22, 5, 2}, {15, 22, 0, 2}, {15, 22, 5, 2},
+			},
+		},
+	}
+
+	for _, c := range testCases {
+		if got := nineGridRects(c.bounds, c.margins); got != c.want {
+			t.Errorf("nineGridRects(%v, %v) got %v, want %v", c.bounds, c.margins, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,578 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"github.com/tailscale/win"
+)
+
+const cardWindowClass = `\o/ Walk_Card_Class \o/`
+
+func init() {
+	AppendToWalkInit(func() {
+		MustRegisterWindowClass(cardWindowClass)
+	})
+}
+
+// Card is a container drawing a rounded, elevated surface around its children,
+// optionally headed by a title, to visually group parts of a layout.
+//
+// The colors of the surface follow the background the Card is placed on. On a
+// light background the surface has the system window color, on a dark one,
+// like that of an application in dark mode, it is a slightly lighter shade of
+// the background.
+type Card struct {
+	WidgetBase
+	composite             *Composite
+	surfaceBrush          *SolidColorBrush
+	title                 string
+	cornerRadius          int     // in 1/96" units
+	elevation             int     // in 1/96" units
+	padding               Margins // in 1/96" units
+	titleChangedPublisher EventPublisher
+}
+
+// NewCard creates a new Card as child of parent.
+func NewCard(parent Container) (*Card, error) {
+	c := &Card{
+		cornerRadius: 8,
+		elevation:    4,
+		padding:      Margins{12, 12, 12, 12},
+	}
+
+	if err := InitWidget(
+		c,
+		parent,
+		cardWindowClass,
+		win.WS_VISIBLE,
+		win.WS_EX_CONTROLPARENT); err != nil {
+		return nil, err
+	}
+
+	succeeded := false
+	defer func() {
+		if !succeeded {
+			c.Dispose()
+		}
+	}()
+
+	var err error
+	if c.composite, err = NewComposite(c); err != nil {
+		return nil, err
+	}
+	c.composite.name = "composite"
+
+	c.SetBackground(NullBrush())
+
+	if err := c.updateSurface(); err != nil {
+		return nil, err
+	}
+
+	c.MustRegisterProperty("Title", NewProperty(
+		func() interface{} {
+			return c.Title()
+		},
+		func(v interface{}) error {
+			return c.SetTitle(assertStringOr(v, ""))
+		},
+		c.titleChangedPublisher.Event()))
+
+	succeeded = true
+
+	return c, nil
+}
+
+func (c *Card) Dispose() {
+	c.WidgetBase.Dispose()
+
+	if c.surfaceBrush != nil {
+		c.surfaceBrush.Dispose()
+		c.surfaceBrush = nil
+	}
+}
+
+func (c *Card) AsContainerBase() *ContainerBase {
+	if c.composite == nil {
+		return nil
+	}
+
+	return c.composite.AsContainerBase()
+}
+
+func (c *Card) Children() *WidgetList {
+	if c.composite == nil {
+		// Without this we would get into trouble in NewComposite.
+		return nil
+	}
+
+	return c.composite.Children()
+}
+
+func (c *Card) Layout() Layout {
+	if c.composite == nil {
+		return nil
+	}
+
+	return c.composite.Layout()
+}
+
+func (c *Card) SetLayout(value Layout) error {
+	return c.composite.SetLayout(value)
+}
+
+func (c *Card) DataBinder() *DataBinder {
+	return c.composite.dataBinder
+}
+
+func (c *Card) SetDataBinder(dataBinder *DataBinder) {
+	c.composite.SetDataBinder(dataBinder)
+}
+
+func (c *Card) Persistent() bool {
+	return c.composite.Persistent()
+}
+
+func (c *Card) SetPersistent(value bool) {
+	c.composite.SetPersistent(value)
+}
+
+func (c *Card) SaveState() error {
+	return c.composite.SaveState()
+}
+
+func (c *Card) RestoreState() error {
+	return c.composite.RestoreState()
+}
+
+func (c *Card) applyEnabled(enabled bool) {
+	c.WidgetBase.applyEnabled(enabled)
+
+	if c.composite != nil {
+		c.composite.applyEnabled(enabled)
+	}
+}
+
+func (c *Card) applyFont(font *Font) {
+	c.WidgetBase.applyFont(font)
+
+	if c.composite != nil {
+		c.composite.applyFont(font)
+	}
+}
+
+func (c *Card) ApplyDPI(dpi int) {
+	c.WidgetBase.ApplyDPI(dpi)
+
+	if c.composite != nil {
+		c.composite.ApplyDPI(dpi)
+	}
+}
+
+func (c *Card) ApplySysColors() {
+	c.WidgetBase.ApplySysColors()
+
+	c.updateSurface()
+}
+
+func (c *Card) SetSuspended(suspend bool) {
+	c.composite.SetSuspended(suspend)
+	c.WidgetBase.SetSuspended(suspend)
+	c.Invalidate()
+}
+
+func (c *Card) MouseDown() *MouseEvent {
+	return c.composite.MouseDown()
+}
+
+func (c *Card) MouseMove() *MouseEvent {
+	return c.composite.MouseMove()
+}
+
+func (c *Card) MouseUp() *MouseEvent {
+	return c.composite.MouseUp()
+}
+
+// Title returns the text displayed above the children, or an empty string if
+// the Card has no header.
+func (c *Card) Title() string {
+	return c.title
+}
+
+// SetTitle sets the text displayed above the children. An empty title removes
+// the header.
+func (c *Card) SetTitle(title string) error {
+	if title == c.title {
+		return nil
+	}
+
+	c.title = title
+
+	c.Invalidate()
+	c.RequestLayout()
+
+	c.titleChangedPublisher.Publish()
+
+	return nil
+}
+
+// TitleChanged returns the event that is published when the title changed.
+func (c *Card) TitleChanged() *Event {
+	return c.titleChangedPublisher.Event()
+}
+
+// CornerRadius returns the radius of the corners of the surface in 1/96"
+// units.
+func (c *Card) CornerRadius() int {
+	return c.cornerRadius
+}
+
+// SetCornerRadius sets the radius of the corners of the surface in 1/96"
+// units.
+func (c *Card) SetCornerRadius(radius int) error {
+	if radius < 0 {
+		return newError("radius must not be negative")
+	}
+
+	c.cornerRadius = radius
+
+	c.Invalidate()
+
+	return nil
+}
+
+// Elevation returns the extent of the shadow below the surface in 1/96"
+// units.
+func (c *Card) Elevation() int {
+	return c.elevation
+}
+
+// SetElevation sets the extent of the shadow below the surface in 1/96"
+// units. 0 draws the surface flat, with its border only.
+func (c *Card) SetElevation(elevation int) error {
+	if elevation < 0 {
+		return newError("elevation must not be negative")
+	}
+
+	c.elevation = elevation
+
+	c.Invalidate()
+	c.RequestLayout()
+
+	return nil
+}
+
+// Padding returns the space between the edges of the surface and the header
+// and children in 1/96" units.
+func (c *Card) Padding() Margins {
+	return c.padding
+}
+
+// SetPadding sets the space between the edges of the surface and the header
+// and children in 1/96" units.
+func (c *Card) SetPadding(padding Margins) error {
+	if padding.HNear < 0 || padding.VNear < 0 || padding.HFar < 0 || padding.VFar < 0 {
+		return newError("padding must not be negative")
+	}
+
+	c.padding = padding
+
+	c.Invalidate()
+	c.RequestLayout()
+
+	return nil
+}
+
+func (c *Card) titleFont() *Font {
+	font := c.Font()
+
+	if bold, err := NewFont(font.Family(), font.PointSize(), font.Style()|FontBold); err == nil {
+		return bold
+	}
+
+	return font
+}
+
+// cardColors returns the colors of the surface, its border and the title on
+// background.
+func cardColors(background Color) (surface, border, text Color) {
+	luminance := (299*int(background.R()) + 587*int(background.G()) + 114*int(background.B())) / 1000
+
+	if luminance < 0x80 {
+		white := RGB(0xFF, 0xFF, 0xFF)
+		surface = blendColors(white, background, 0.06)
+		return surface, blendColors(white, background, 0.12), blendColors(white, surface, 0.9)
+	}
+
+	surface = Color(win.GetSysColor(win.COLOR_WINDOW))
+	text = Color(win.GetSysColor(win.COLOR_WINDOWTEXT))
+	return surface, blendColors(text, surface, 0.12), text
+}
+
+// backgroundColor returns the color of the background the Card is placed on.
+func (c *Card) backgroundColor() Color {
+	bg, _ := c.backgroundEffective()
+
+	switch bg := bg.(type) {
+	case *SolidColorBrush:
+		return bg.Color()
+
+	case *SystemColorBrush:
+		return bg.Color()
+	}
+
+	return Color(win.GetSysColor(win.COLOR_BTNFACE))
+}
+
+// updateSurface makes the composite use the color of the surface, which
+// depends on the background the Card is placed on.
+func (c *Card) updateSurface() error {
+	surface, _, _ := cardColors(c.backgroundColor())
+	if c.surfaceBrush != nil && c.surfaceBrush.Color() == surface {
+		return nil
+	}
+
+	brush, err := NewSolidColorBrush(surface)
+	if err != nil {
+		return err
+	}
+
+	c.composite.SetBackground(brush)
+
+	if c.surfaceBrush != nil {
+		c.surfaceBrush.Dispose()
+	}
+	c.surfaceBrush = brush
+
+	c.composite.Invalidate()
+
+	return nil
+}
+
+// surfaceBounds returns the bounds of the surface within bounds, leaving room
+// for the shadow, in native pixels.
+func (c *Card) surfaceBounds(bounds Rectangle) Rectangle {
+	elevation := c.IntFrom96DPI(c.elevation)
+	offset := elevation / 2
+	top := elevation - offset
+
+	return Rectangle{
+		X:      bounds.X + elevation,
+		Y:      bounds.Y + top,
+		Width:  bounds.Width - 2*elevation,
+		Height: bounds.Height - top - elevation - offset,
+	}
+}
+
+func (c *Card) paint(canvas *Canvas, bounds Rectangle) error {
+	bp, err := BeginBufferedPaint(canvas, bounds, win.BPBF_COMPATIBLEBITMAP)
+	if err != nil {
+		return err
+	}
+	defer bp.End()
+
+	buffered, err := bp.Canvas()
+	if err != nil {
+		return err
+	}
+	defer buffered.Dispose()
+
+	if bg, wnd := c.backgroundEffective(); bg != nil {
+		c.prepareDCForBackground(buffered.HDC(), c.hWnd, wnd)
+
+		if err := buffered.FillRectanglePixels(bg, bounds); err != nil {
+			return err
+		}
+	}
+
+	background := c.backgroundColor()
+	surfaceColor, borderColor, textColor := cardColors(background)
+
+	cb := c.ClientBoundsPixels()
+	surface := c.surfaceBounds(cb)
+	radius := c.IntFrom96DPI(c.cornerRadius)
+
+	// The shadow fades out over the elevation, drawn from its outer edge
+	// inwards.
+	elevation := c.IntFrom96DPI(c.elevation)
+	for i := elevation; i > 0; i-- {
+		weight := float64(elevation-i+1) / float64(elevation+1)
+
+		brush, err := NewSolidColorBrush(blendColors(RGB(0, 0, 0), background, 0.12*weight*weight))
+		if err != nil {
+			return err
+		}
+
+		shadow := Rectangle{surface.X - i, surface.Y - i + elevation/2, surface.Width + 2*i, surface.Height + 2*i}
+		err = buffered.FillRoundedRectanglePixels(brush, shadow, Size{2 * (radius + i), 2 * (radius + i)})
+		brush.Dispose()
+		if err != nil {
+			return err
+		}
+	}
+
+	surfaceBrush, err := NewSolidColorBrush(surfaceColor)
+	if err != nil {
+		return err
+	}
+	defer surfaceBrush.Dispose()
+
+	borderBrush, err := NewSolidColorBrush(borderColor)
+	if err != nil {
+		return err
+	}
+	defer borderBrush.Dispose()
+
+	borderPen, err := NewGeometricPen(PenSolid|PenInsideFrame, c.IntFrom96DPI(1), borderBrush)
+	if err != nil {
+		return err
+	}
+	defer borderPen.Dispose()
+
+	if err := buffered.roundedRectanglePixels(surfaceBrush, borderPen, surface, Size{2 * radius, 2 * radius}, 0); err != nil {
+		return err
+	}
+
+	if c.title != "" {
+		padding := MarginsFrom96DPI(c.padding, c.DPI())
+		font := c.titleFont()
+
+		color := textColor
+		if !c.Enabled() {
+			color = blendColors(color, surfaceColor, 0.5)
+		}
+
+		titleBounds := Rectangle{
+			X:      surface.X + padding.HNear,
+			Y:      surface.Y + padding.VNear,
+			Width:  surface.Width - padding.HNear - padding.HFar,
+			Height: calculateTextSize(c.title, font, c.DPI(), 0, c.hWnd).Height,
+		}
+		if err := buffered.DrawTextPixels(c.title, font, color, titleBounds, TextLeft|TextTop|TextSingleLine|TextEndEllipsis|TextNoPrefix); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *Card) WndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) uintptr {
+	switch msg {
+	case win.WM_PAINT:
+		var ps win.PAINTSTRUCT
+
+		hdc := win.BeginPaint(hwnd, &ps)
+		defer win.EndPaint(hwnd, &ps)
+
+		canvas, err := newCanvasFromHDC(hdc)
+		if err != nil {
+			return 0
+		}
+		defer canvas.Dispose()
+
+		c.updateSurface()
+		c.paint(canvas, c.ClientBoundsPixels())
+
+		return 0
+
+	case win.WM_ERASEBKGND:
+		// Everything is drawn in WM_PAINT.
+		return 1
+
+	case win.WM_SIZE:
+		c.Invalidate()
+	}
+
+	return c.WidgetBase.WndProc(hwnd, msg, wParam, lParam)
+}
+
+func (c *Card) CreateLayoutItem(ctx *LayoutContext) LayoutItem {
+	elevation := IntFrom96DPI(c.elevation, ctx.dpi)
+	padding := MarginsFrom96DPI(c.padding, ctx.dpi)
+
+	// The insets leave room for the shadow and the padding around the
+	// composite.
+	offset := elevation / 2
+	insets := Margins{
+		HNear: elevation + padding.HNear,
+		VNear: elevation - offset + padding.VNear,
+		HFar:  elevation + padding.HFar,
+		VFar:  elevation + offset + padding.VFar,
+	}
+
+	if c.title != "" {
+		insets.VNear += calculateTextSize(c.title, c.titleFont(), ctx.dpi, 0, c.hWnd).Height + padding.VNear/2
+	}
+
+	li := &cardLayoutItem{
+		insets: insets,
+	}
+
+	content := CreateLayoutItemsForContainerWithContext(c.composite, ctx)
+	content.AsLayoutItemBase().parent = li
+
+	li.children = append(li.children, content)
+
+	return li
+}
+
+type cardLayoutItem struct {
+	ContainerLayoutItemBase
+	insets Margins // in native pixels
+}
+
+func (li *cardLayoutItem) content() LayoutItem {
+	return li.children[0]
+}
+
+func (li *cardLayoutItem) LayoutFlags() LayoutFlags {
+	return li.content().LayoutFlags()
+}
+
+func (li *cardLayoutItem) grow(size Size) Size {
+	size.Width += li.insets.HNear + li.insets.HFar
+	size.Height += li.insets.VNear + li.insets.VFar
+
+	return size
+}
+
+func (li *cardLayoutItem) MinSize() Size {
+	return li.grow(li.content().(MinSizer).MinSize())
+}
+
+func (li *cardLayoutItem) MinSizeForSize(size Size) Size {
+	return li.MinSize()
+}
+
+func (li *cardLayoutItem) IdealSize() Size {
+	return li.grow(li.content().(IdealSizer).IdealSize())
+}
+
+func (li *cardLayoutItem) HasHeightForWidth() bool {
+	hfw, ok := li.content().(HeightForWidther)
+	return ok && hfw.HasHeightForWidth()
+}
+
+func (li *cardLayoutItem) HeightForWidth(width int) int {
+	return li.content().(HeightForWidther).HeightForWidth(width-li.insets.HNear-li.insets.HFar) + li.insets.VNear + li.insets.VFar
+}
+
+func (li *cardLayoutItem) PerformLayout() []LayoutResultItem {
+	size := li.geometry.ClientSize
+
+	return []LayoutResultItem{
+		{
+			Item: li.content(),
+			Bounds: Rectangle{
+				X:      li.insets.HNear,
+				Y:      li.insets.VNear,
+				Width:  size.Width - li.insets.HNear - li.insets.HFar,
+				Height: size.Height - li.insets.VNear - li.insets.VFar,
+			},
+		},
+	}
+}
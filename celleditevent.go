@@ -0,0 +1,63 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+type cellEditEventHandlerInfo struct {
+	handler CellEditEventHandler
+	once    bool
+}
+
+type CellEditEventHandler func(row, col int, value interface{})
+
+type CellEditEvent struct {
+	handlers []cellEditEventHandlerInfo
+}
+
+func (e *CellEditEvent) Attach(handler CellEditEventHandler) int {
+	handlerInfo := cellEditEventHandlerInfo{handler, false}
+
+	for i, h := range e.handlers {
+		if h.handler == nil {
+			e.handlers[i] = handlerInfo
+			return i
+		}
+	}
+
+	e.handlers = append(e.handlers, handlerInfo)
+
+	return len(e.handlers) - 1
+}
+
+func (e *CellEditEvent) Detach(handle int) {
+	e.handlers[handle].handler = nil
+}
+
+func (e *CellEditEvent) Once(handler CellEditEventHandler) {
+	i := e.Attach(handler)
+	e.handlers[i].once = true
+}
+
+type CellEditEventPublisher struct {
+	event CellEditEvent
+}
+
+func (p *CellEditEventPublisher) Event() *CellEditEvent {
+	return &p.event
+}
+
+func (p *CellEditEventPublisher) Publish(row, col int, value interface{}) {
+	for i, h := range p.event.handlers {
+		if h.handler != nil {
+			h.handler(row, col, value)
+
+			if h.once {
+				p.event.Detach(i)
+			}
+		}
+	}
+}
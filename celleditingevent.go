@@ -0,0 +1,63 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+type cellEditingEventHandlerInfo struct {
+	handler CellEditingEventHandler
+	once    bool
+}
+
+type CellEditingEventHandler func(row, col int, value interface{}, canceled *bool)
+
+type CellEditingEvent struct {
+	handlers []cellEditingEventHandlerInfo
+}
+
+func (e *CellEditingEvent) Attach(handler CellEditingEventHandler) int {
+	handlerInfo := cellEditingEventHandlerInfo{handler, false}
+
+	for i, h := range e.handlers {
+		if h.handler == nil {
+			e.handlers[i] = handlerInfo
+			return i
+		}
+	}
+
+	e.handlers = append(e.handlers, handlerInfo)
+
+	return len(e.handlers) - 1
+}
+
+func (e *CellEditingEvent) Detach(handle int) {
+	e.handlers[handle].handler = nil
+}
+
+func (e *CellEditingEvent) Once(handler CellEditingEventHandler) {
+	i := e.Attach(handler)
+	e.handlers[i].once = true
+}
+
+type CellEditingEventPublisher struct {
+	event CellEditingEvent
+}
+
+func (p *CellEditingEventPublisher) Event() *CellEditingEvent {
+	return &p.event
+}
+
+func (p *CellEditingEventPublisher) Publish(row, col int, value interface{}, canceled *bool) {
+	for i, h := range p.event.handlers {
+		if h.handler != nil {
+			h.handler(row, col, value, canceled)
+
+			if h.once {
+				p.event.Detach(i)
+			}
+		}
+	}
+}
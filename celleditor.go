@@ -0,0 +1,210 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// CellEditor supplies the widget used to edit the cells of a TableViewColumn.
+//
+// TableView positions the widget over the cell while editing and disposes it
+// afterwards.
+type CellEditor interface {
+	// CreateEditor returns a new widget as child of parent, initialized to
+	// display value.
+	CreateEditor(parent Container, value interface{}) (Widget, error)
+
+	// EditorValue returns the value currently entered in editor, which was
+	// returned by CreateEditor.
+	EditorValue(editor Widget) (interface{}, error)
+}
+
+// TextCellEditor edits cells as text in a LineEdit. Values are committed as
+// string.
+type TextCellEditor struct{}
+
+func (TextCellEditor) CreateEditor(parent Container, value interface{}) (Widget, error) {
+	le, err := NewLineEdit(parent)
+	if err != nil {
+		return nil, err
+	}
+
+	if value != nil {
+		le.SetText(fmt.Sprint(value))
+	}
+	le.SetTextSelection(0, -1)
+
+	return le, nil
+}
+
+func (TextCellEditor) EditorValue(editor Widget) (interface{}, error) {
+	return editor.(*LineEdit).Text(), nil
+}
+
+// ComboBoxCellEditor edits cells by choosing from Items in a ComboBox. If
+// Editable is true, other text may be entered, too. Values are committed as
+// string.
+type ComboBoxCellEditor struct {
+	Items    []string
+	Editable bool
+}
+
+func (e ComboBoxCellEditor) CreateEditor(parent Container, value interface{}) (Widget, error) {
+	var cb *ComboBox
+	var err error
+	if e.Editable {
+		cb, err = NewComboBox(parent)
+	} else {
+		cb, err = NewDropDownBox(parent)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cb.SetModel(e.Items); err != nil {
+		cb.Dispose()
+		return nil, err
+	}
+
+	if value != nil {
+		text := fmt.Sprint(value)
+
+		for i, item := range e.Items {
+			if item == text {
+				cb.SetCurrentIndex(i)
+				return cb, nil
+			}
+		}
+
+		if e.Editable {
+			cb.SetText(text)
+		}
+	}
+
+	return cb, nil
+}
+
+func (ComboBoxCellEditor) EditorValue(editor Widget) (interface{}, error) {
+	return editor.(*ComboBox).Text(), nil
+}
+
+// CheckBoxCellEditor edits boolean cells with a CheckBox.
+type CheckBoxCellEditor struct{}
+
+func (CheckBoxCellEditor) CreateEditor(parent Container, value interface{}) (Widget, error) {
+	cb, err := NewCheckBox(parent)
+	if err != nil {
+		return nil, err
+	}
+
+	checked, _ := value.(bool)
+	cb.SetChecked(checked)
+
+	return cb, nil
+}
+
+func (CheckBoxCellEditor) EditorValue(editor Widget) (interface{}, error) {
+	return editor.(*CheckBox).Checked(), nil
+}
+
+// DateCellEditor edits time.Time cells with a DateEdit. Format is passed to
+// DateEdit.SetFormat, if not empty.
+type DateCellEditor struct {
+	Format string
+}
+
+func (e DateCellEditor) CreateEditor(parent Container, value interface{}) (Widget, error) {
+	de, err := NewDateEdit(parent)
+	if err != nil {
+		return nil, err
+	}
+
+	if e.Format != "" {
+		if err := de.SetFormat(e.Format); err != nil {
+			de.Dispose()
+			return nil, err
+		}
+	}
+
+	if t, ok := value.(time.Time); ok && !t.IsZero() {
+		de.SetDate(t)
+	}
+
+	return de, nil
+}
+
+func (DateCellEditor) EditorValue(editor Widget) (interface{}, error) {
+	return editor.(*DateEdit).Date(), nil
+}
+
+// NumberCellEditor edits numeric cells with a NumberEdit. If MinValue and
+// MaxValue are both zero, the range is not limited. Values are committed as
+// float64.
+type NumberCellEditor struct {
+	Decimals int
+	MinValue float64
+	MaxValue float64
+}
+
+func (e NumberCellEditor) CreateEditor(parent Container, value interface{}) (Widget, error) {
+	ne, err := NewNumberEdit(parent)
+	if err != nil {
+		return nil, err
+	}
+
+	succeeded := false
+	defer func() {
+		if !succeeded {
+			ne.Dispose()
+		}
+	}()
+
+	if err := ne.SetDecimals(e.Decimals); err != nil {
+		return nil, err
+	}
+
+	if e.MinValue != 0 || e.MaxValue != 0 {
+		if err := ne.SetRange(e.MinValue, e.MaxValue); err != nil {
+			return nil, err
+		}
+	}
+
+	if f, ok := cellFloat64(value); ok {
+		if err := ne.SetValue(f); err != nil {
+			return nil, err
+		}
+	}
+
+	succeeded = true
+
+	return ne, nil
+}
+
+func (NumberCellEditor) EditorValue(editor Widget) (interface{}, error) {
+	return editor.(*NumberEdit).Value(), nil
+}
+
+func cellFloat64(value interface{}) (float64, bool) {
+	v := reflect.ValueOf(value)
+
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(v.Uint()), true
+	}
+
+	return 0, false
+}
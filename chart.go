@@ -0,0 +1,962 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"math"
+	"strconv"
+	"unsafe"
+
+	"github.com/tailscale/win"
+)
+
+const chartWindowClass = `\o/ Walk_Chart_Class \o/`
+
+func init() {
+	AppendToWalkInit(func() {
+		MustRegisterWindowClass(chartWindowClass)
+	})
+}
+
+// ChartKind specifies how a Chart displays its series.
+type ChartKind int
+
+const (
+	ChartLine ChartKind = iota
+	ChartBar
+	ChartArea
+)
+
+const (
+	chartDefaultCapacity = 60
+	chartPadding96dpi    = 6
+	chartLineWidth96dpi  = 2
+)
+
+// chartPalette provides the colors of series that were added without one.
+var chartPalette = []Color{
+	RGB(0x00, 0x78, 0xD4),
+	RGB(0xF7, 0x63, 0x0C),
+	RGB(0x10, 0x89, 0x3E),
+	RGB(0x88, 0x17, 0x98),
+	RGB(0xE8, 0x11, 0x23),
+	RGB(0x00, 0x99, 0xBC),
+}
+
+// ChartSeries is a named sequence of values displayed by a Chart.
+type ChartSeries struct {
+	chart *Chart
+	index int
+	name  string
+	color Color
+}
+
+// Name returns the name of the series, as displayed in the legend and
+// tooltips.
+func (s *ChartSeries) Name() string {
+	return s.name
+}
+
+// SetName sets the name of the series, as displayed in the legend and
+// tooltips.
+func (s *ChartSeries) SetName(name string) {
+	s.name = name
+
+	s.chart.Invalidate()
+}
+
+// Color returns the color of the series.
+func (s *ChartSeries) Color() Color {
+	return s.color
+}
+
+// SetColor sets the color of the series.
+func (s *ChartSeries) SetColor(color Color) {
+	s.color = color
+
+	s.chart.Invalidate()
+}
+
+// Value returns the value of the series at index, or NaN if the point has no
+// value for it.
+func (s *ChartSeries) Value(index int) float64 {
+	return s.chart.value(s.index, index)
+}
+
+type chartPoint struct {
+	label  string
+	values []float64
+}
+
+type chartAxis struct {
+	min, max, step float64
+}
+
+// Chart plots one or more series of values as lines, bars or filled areas.
+//
+// Points are appended to a ring buffer of Capacity points, so the oldest point
+// is dropped once it is full. This makes Chart suitable for live monitoring,
+// e.g. of throughput or latency. Each point has a label, displayed on the X
+// axis, and one value per series.
+//
+// Hovering the mouse over the chart displays the values of the nearest point.
+type Chart struct {
+	WidgetBase
+	kind           ChartKind
+	series         []*ChartSeries
+	points         []chartPoint
+	first          int // index of the oldest point in points
+	count          int
+	appended       int // total number of points ever appended
+	minValue       float64
+	maxValue       float64
+	legendVisible  bool
+	valueFormatter func(value float64) string
+	hotIndex       int
+
+	// Set while painting, to tell which parts need repainting later on.
+	fontHeight int
+	plotBounds Rectangle
+	axis       chartAxis
+}
+
+// NewChart returns a new line Chart with a capacity of 60 points as child of
+// parent.
+func NewChart(parent Container) (*Chart, error) {
+	c := &Chart{
+		points:        make([]chartPoint, chartDefaultCapacity),
+		legendVisible: true,
+		hotIndex:      -1,
+	}
+
+	if err := InitWidget(
+		c,
+		parent,
+		chartWindowClass,
+		win.WS_VISIBLE,
+		0); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Kind returns how the series are displayed.
+func (c *Chart) Kind() ChartKind {
+	return c.kind
+}
+
+// SetKind sets how the series are displayed.
+func (c *Chart) SetKind(kind ChartKind) {
+	if kind == c.kind {
+		return
+	}
+
+	c.kind = kind
+
+	c.Invalidate()
+}
+
+// AddSeries adds a series to the chart and returns it. If color is zero, one
+// is picked from a default palette.
+//
+// Points that were appended before the series was added have no value for it.
+func (c *Chart) AddSeries(name string, color Color) *ChartSeries {
+	if color == 0 {
+		color = chartPalette[len(c.series)%len(chartPalette)]
+	}
+
+	s := &ChartSeries{
+		chart: c,
+		index: len(c.series),
+		name:  name,
+		color: color,
+	}
+
+	c.series = append(c.series, s)
+
+	c.Invalidate()
+
+	return s
+}
+
+// Series returns the series of the chart, in the order they were added.
+func (c *Chart) Series() []*ChartSeries {
+	return append([]*ChartSeries(nil), c.series...)
+}
+
+// Capacity returns the maximum number of points the chart keeps.
+func (c *Chart) Capacity() int {
+	return len(c.points)
+}
+
+// SetCapacity sets the maximum number of points the chart keeps. If there are
+// more points, the oldest ones are dropped.
+func (c *Chart) SetCapacity(capacity int) error {
+	if capacity < 1 {
+		return newError("capacity must be positive")
+	}
+
+	if capacity == len(c.points) {
+		return nil
+	}
+
+	count := c.count
+	if count > capacity {
+		count = capacity
+	}
+
+	points := make([]chartPoint, capacity)
+	for i := range points[:count] {
+		points[i] = *c.point(c.count - count + i)
+	}
+
+	c.points = points
+	c.first = 0
+	c.count = count
+	c.hotIndex = -1
+
+	c.Invalidate()
+
+	return nil
+}
+
+// Append adds a point with label and one value per series, in the order the
+// series were added. If the chart is at capacity, the oldest point is dropped.
+// NaN values leave a gap.
+func (c *Chart) Append(label string, values ...float64) {
+	p := chartPoint{label, append([]float64(nil), values...)}
+
+	if c.count < len(c.points) {
+		c.points[(c.first+c.count)%len(c.points)] = p
+		c.count++
+	} else {
+		c.points[c.first] = p
+		c.first = (c.first + 1) % len(c.points)
+	}
+
+	c.appended++
+
+	c.invalidatePoints()
+}
+
+// Clear removes all points.
+func (c *Chart) Clear() {
+	for i := range c.points {
+		c.points[i] = chartPoint{}
+	}
+
+	c.first = 0
+	c.count = 0
+	c.hotIndex = -1
+
+	c.Invalidate()
+}
+
+// Len returns the number of points.
+func (c *Chart) Len() int {
+	return c.count
+}
+
+// Label returns the label of the point at index, where 0 is the oldest point.
+func (c *Chart) Label(index int) string {
+	return c.point(index).label
+}
+
+func (c *Chart) point(index int) *chartPoint {
+	return &c.points[(c.first+index)%len(c.points)]
+}
+
+func (c *Chart) value(series, index int) float64 {
+	if values := c.point(index).values; series < len(values) {
+		return values[series]
+	}
+
+	return math.NaN()
+}
+
+// ValueRange returns the range of the value axis. If min equals max, the
+// range is determined from the values.
+func (c *Chart) ValueRange() (min, max float64) {
+	return c.minValue, c.maxValue
+}
+
+// SetValueRange sets the range of the value axis. If min equals max, the
+// range is determined from the values.
+func (c *Chart) SetValueRange(min, max float64) error {
+	if min > max {
+		return newError("min must not be greater than max")
+	}
+
+	c.minValue, c.maxValue = min, max
+
+	c.Invalidate()
+
+	return nil
+}
+
+// LegendVisible returns whether the names of the series are displayed above
+// the plot.
+func (c *Chart) LegendVisible() bool {
+	return c.legendVisible
+}
+
+// SetLegendVisible sets whether the names of the series are displayed above
+// the plot.
+func (c *Chart) SetLegendVisible(visible bool) {
+	if visible == c.legendVisible {
+		return
+	}
+
+	c.legendVisible = visible
+
+	c.Invalidate()
+}
+
+// ValueFormatter returns the function that formats values for the value axis
+// and tooltips, if any.
+func (c *Chart) ValueFormatter() func(value float64) string {
+	return c.valueFormatter
+}
+
+// SetValueFormatter sets the function that formats values for the value axis
+// and tooltips, e.g. to append a unit. If nil, values are formatted as plain
+// numbers.
+func (c *Chart) SetValueFormatter(formatter func(value float64) string) {
+	c.valueFormatter = formatter
+
+	c.Invalidate()
+}
+
+// formatValue formats value for display. step is the distance between ticks
+// of the value axis, which determines the number of decimals, or zero.
+func (c *Chart) formatValue(value, step float64) string {
+	if c.valueFormatter != nil {
+		return c.valueFormatter(value)
+	}
+
+	if step == 0 {
+		return strconv.FormatFloat(value, 'g', 6, 64)
+	}
+
+	decimals := 0
+	if step < 1 {
+		decimals = int(math.Ceil(-math.Log10(step)))
+	}
+
+	return strconv.FormatFloat(value, 'f', decimals, 64)
+}
+
+// computeAxis returns the value axis for a plot height native pixels high.
+func (c *Chart) computeAxis(height int) chartAxis {
+	min, max := c.minValue, c.maxValue
+	auto := min == max
+
+	if auto {
+		// The axis always includes zero, so bars and areas have a baseline
+		// and values are not exaggerated.
+		min, max = 0, 0
+
+		for i := 0; i < c.count; i++ {
+			for _, v := range c.point(i).values {
+				if math.IsNaN(v) || math.IsInf(v, 0) {
+					continue
+				}
+
+				min = math.Min(min, v)
+				max = math.Max(max, v)
+			}
+		}
+
+		if min == max {
+			max = min + 1
+		}
+	}
+
+	ticks := 2
+	if c.fontHeight > 0 && height/(2*c.fontHeight) > ticks {
+		ticks = height / (2 * c.fontHeight)
+	}
+
+	// Round the step to 1, 2 or 5 times a power of 10.
+	raw := (max - min) / float64(ticks)
+	magnitude := math.Pow(10, math.Floor(math.Log10(raw)))
+	step := 10 * magnitude
+	for _, f := range []float64{1, 2, 5} {
+		if raw <= f*magnitude {
+			step = f * magnitude
+			break
+		}
+	}
+
+	if auto {
+		min = math.Floor(min/step) * step
+		max = math.Ceil(max/step) * step
+	}
+
+	return chartAxis{min, max, step}
+}
+
+// xForIndex returns the horizontal center of the point at index.
+func (c *Chart) xForIndex(index int) int {
+	return c.plotBounds.X + int((float64(index)+0.5)*float64(c.plotBounds.Width)/float64(len(c.points)))
+}
+
+func (c *Chart) yForValue(value float64) int {
+	pb := c.plotBounds
+
+	f := (value - c.axis.min) / (c.axis.max - c.axis.min)
+	f = math.Max(0, math.Min(1, f))
+
+	return pb.Y + pb.Height - 1 - int(math.Round(f*float64(pb.Height-1)))
+}
+
+// indexAt returns the index of the point at p, or -1.
+func (c *Chart) indexAt(p Point) int {
+	pb := c.plotBounds
+	if pb.Width <= 0 || p.X < pb.X || p.X >= pb.X+pb.Width || p.Y < pb.Y || p.Y >= pb.Y+pb.Height {
+		return -1
+	}
+
+	if index := (p.X - pb.X) * len(c.points) / pb.Width; index < c.count {
+		return index
+	}
+
+	return -1
+}
+
+func (c *Chart) setHotIndex(index int) {
+	if index == c.hotIndex {
+		return
+	}
+
+	if c.hotIndex == -1 {
+		var tme win.TRACKMOUSEEVENT
+		tme.CbSize = uint32(unsafe.Sizeof(tme))
+		tme.DwFlags = win.TME_LEAVE
+		tme.HwndTrack = c.hWnd
+
+		win.TrackMouseEvent(&tme)
+	}
+
+	c.hotIndex = index
+
+	c.invalidateRectangle(c.plotBounds)
+}
+
+// invalidatePoints invalidates what changes when points are appended. Unless
+// the value axis changes, the legend and the value axis labels are left
+// alone.
+func (c *Chart) invalidatePoints() {
+	pb := c.plotBounds
+	if pb.Width <= 0 || c.computeAxis(pb.Height) != c.axis {
+		c.Invalidate()
+		return
+	}
+
+	cb := c.ClientBoundsPixels()
+
+	c.invalidateRectangle(pb)
+	c.invalidateRectangle(Rectangle{cb.X, pb.Y + pb.Height, cb.Width, cb.Y + cb.Height - pb.Y - pb.Height})
+}
+
+func (c *Chart) invalidateRectangle(bounds Rectangle) {
+	rc := bounds.toRECT()
+
+	win.InvalidateRect(c.hWnd, &rc, false)
+}
+
+func (c *Chart) paint(canvas *Canvas, bounds Rectangle) error {
+	bp, err := BeginBufferedPaint(canvas, bounds, win.BPBF_COMPATIBLEBITMAP)
+	if err != nil {
+		return err
+	}
+	defer bp.End()
+
+	buffered, err := bp.Canvas()
+	if err != nil {
+		return err
+	}
+	defer buffered.Dispose()
+
+	if bg, wnd := c.backgroundEffective(); bg != nil {
+		c.prepareDCForBackground(buffered.HDC(), c.hWnd, wnd)
+
+		if err := buffered.FillRectanglePixels(bg, bounds); err != nil {
+			return err
+		}
+	} else {
+		brush, err := NewSystemColorBrush(SysColorWindow)
+		if err != nil {
+			return err
+		}
+		err = buffered.FillRectanglePixels(brush, bounds)
+		brush.Dispose()
+		if err != nil {
+			return err
+		}
+	}
+
+	font := c.Font()
+	dpi := c.DPI()
+	padding := IntFrom96DPI(chartPadding96dpi, dpi)
+	textColor := Color(win.GetSysColor(win.COLOR_WINDOWTEXT))
+	grayTextColor := Color(win.GetSysColor(win.COLOR_GRAYTEXT))
+
+	if c.fontHeight, err = buffered.fontHeight(font); err != nil {
+		return err
+	}
+	fontHeight := c.fontHeight
+
+	measure := func(text string) int {
+		b, _, _ := buffered.MeasureTextPixels(text, font, Rectangle{Width: 10000, Height: 10000}, TextSingleLine|TextNoPrefix)
+		return b.Width
+	}
+
+	// Lay out the legend, the value axis labels and the plot.
+	cb := c.ClientBoundsPixels()
+
+	legendHeight := 0
+	if c.legendVisible && len(c.series) > 0 {
+		legendHeight = fontHeight + padding
+	}
+
+	plotTop := cb.Y + padding + legendHeight
+	plotHeight := cb.Y + cb.Height - padding - fontHeight - padding/2 - plotTop
+
+	c.axis = c.computeAxis(plotHeight)
+	axis := c.axis
+
+	var ticks []float64
+	for v := axis.min; v <= axis.max+axis.step/2; v += axis.step {
+		ticks = append(ticks, v)
+	}
+
+	labelsWidth := 0
+	for _, v := range ticks {
+		if w := measure(c.formatValue(v, axis.step)); w > labelsWidth {
+			labelsWidth = w
+		}
+	}
+
+	plotLeft := cb.X + padding + labelsWidth + padding/2
+
+	c.plotBounds = Rectangle{plotLeft, plotTop, cb.X + cb.Width - padding - plotLeft, plotHeight}
+	pb := c.plotBounds
+
+	if pb.Width <= 0 || pb.Height <= 0 {
+		return nil
+	}
+
+	gridPen, err := NewCosmeticPen(PenSolid, Color(win.GetSysColor(win.COLOR_3DLIGHT)))
+	if err != nil {
+		return err
+	}
+	defer gridPen.Dispose()
+
+	axisPen, err := NewCosmeticPen(PenSolid, grayTextColor)
+	if err != nil {
+		return err
+	}
+	defer axisPen.Dispose()
+
+	// Value axis
+	for _, v := range ticks {
+		y := c.yForValue(v)
+
+		if err := buffered.DrawLinePixels(gridPen, Point{pb.X, y}, Point{pb.X + pb.Width, y}); err != nil {
+			return err
+		}
+
+		rc := Rectangle{cb.X + padding, y - fontHeight/2, labelsWidth, fontHeight}
+		if err := buffered.DrawTextPixels(c.formatValue(v, axis.step), font, grayTextColor, rc, TextRight|TextSingleLine|TextNoPrefix); err != nil {
+			return err
+		}
+	}
+
+	if err := buffered.DrawLinePixels(axisPen, Point{pb.X, pb.Y + pb.Height - 1}, Point{pb.X + pb.Width, pb.Y + pb.Height - 1}); err != nil {
+		return err
+	}
+
+	// Labels of the points, skipping some if they would overlap. Which ones
+	// are skipped depends on the total number of points appended, so labels
+	// don't jump around while scrolling.
+	labelWidth := 0
+	for i := 0; i < c.count; i++ {
+		if w := measure(c.Label(i)); w > labelWidth {
+			labelWidth = w
+		}
+	}
+
+	if labelWidth > 0 {
+		slotWidth := float64(pb.Width) / float64(len(c.points))
+		labelStep := int(math.Ceil(float64(labelWidth+padding) / slotWidth))
+
+		for i := 0; i < c.count; i++ {
+			if (c.appended-c.count+i)%labelStep != 0 {
+				continue
+			}
+
+			rc := Rectangle{c.xForIndex(i) - labelWidth/2, pb.Y + pb.Height + padding/2, labelWidth, fontHeight}
+			if err := buffered.DrawTextPixels(c.Label(i), font, grayTextColor, rc, TextCenter|TextSingleLine|TextNoPrefix); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Series
+	if err := c.paintSeries(buffered); err != nil {
+		return err
+	}
+
+	// Legend
+	if legendHeight > 0 {
+		swatchSize := fontHeight * 2 / 3
+		x := pb.X
+		y := cb.Y + padding
+
+		for _, s := range c.series {
+			brush, err := NewSolidColorBrush(s.color)
+			if err != nil {
+				return err
+			}
+			err = buffered.FillRectanglePixels(brush, Rectangle{x, y + (fontHeight-swatchSize)/2, swatchSize, swatchSize})
+			brush.Dispose()
+			if err != nil {
+				return err
+			}
+
+			x += swatchSize + padding/2
+
+			w := measure(s.name)
+			if err := buffered.DrawTextPixels(s.name, font, textColor, Rectangle{x, y, w, fontHeight}, TextLeft|TextSingleLine|TextNoPrefix); err != nil {
+				return err
+			}
+
+			x += w + 2*padding
+		}
+	}
+
+	if c.hotIndex >= 0 && c.hotIndex < c.count {
+		return c.paintToolTip(buffered, font, fontHeight, padding)
+	}
+
+	return nil
+}
+
+// segments returns the points of the series at index, in native pixels, split
+// where values are missing.
+func (c *Chart) segments(series int) [][]Point {
+	var segments [][]Point
+	var segment []Point
+
+	for i := 0; i < c.count; i++ {
+		v := c.value(series, i)
+		if math.IsNaN(v) {
+			if len(segment) > 0 {
+				segments = append(segments, segment)
+				segment = nil
+			}
+			continue
+		}
+
+		segment = append(segment, Point{c.xForIndex(i), c.yForValue(v)})
+	}
+
+	if len(segment) > 0 {
+		segments = append(segments, segment)
+	}
+
+	return segments
+}
+
+func (c *Chart) paintSeries(canvas *Canvas) error {
+	if c.count == 0 || len(c.series) == 0 {
+		return nil
+	}
+
+	pb := c.plotBounds
+
+	baseline := 0.0
+	if baseline < c.axis.min {
+		baseline = c.axis.min
+	} else if baseline > c.axis.max {
+		baseline = c.axis.max
+	}
+	baseY := c.yForValue(baseline)
+
+	if c.kind == ChartBar {
+		slotWidth := float64(pb.Width) / float64(len(c.points))
+		groupWidth := int(slotWidth * 0.8)
+		barWidth := groupWidth / len(c.series)
+		if barWidth < 1 {
+			barWidth = 1
+		}
+
+		for _, s := range c.series {
+			brush, err := NewSolidColorBrush(s.color)
+			if err != nil {
+				return err
+			}
+
+			for i := 0; i < c.count; i++ {
+				v := c.value(s.index, i)
+				if math.IsNaN(v) {
+					continue
+				}
+
+				y := c.yForValue(v)
+				top, bottom := y, baseY
+				if top > bottom {
+					top, bottom = bottom, top
+				}
+
+				x := c.xForIndex(i) - groupWidth/2 + s.index*barWidth
+				if err := canvas.FillRectanglePixels(brush, Rectangle{x, top, barWidth, bottom - top + 1}); err != nil {
+					brush.Dispose()
+					return err
+				}
+			}
+
+			brush.Dispose()
+		}
+
+		return nil
+	}
+
+	windowColor := Color(win.GetSysColor(win.COLOR_WINDOW))
+
+	for _, s := range c.series {
+		segments := c.segments(s.index)
+
+		if c.kind == ChartArea {
+			// Areas are filled with a lighter shade, so the ones of series
+			// added earlier remain recognizable below.
+			fillBrush, err := NewSolidColorBrush(blendColors(s.color, windowColor, 0.35))
+			if err != nil {
+				return err
+			}
+
+			for _, segment := range segments {
+				polygon := append(segment,
+					Point{segment[len(segment)-1].X, baseY},
+					Point{segment[0].X, baseY})
+
+				if err := canvas.FillPolygonPixels(fillBrush, polygon); err != nil {
+					fillBrush.Dispose()
+					return err
+				}
+			}
+
+			fillBrush.Dispose()
+		}
+
+		brush, err := NewSolidColorBrush(s.color)
+		if err != nil {
+			return err
+		}
+
+		pen, err := NewGeometricPen(PenSolid|PenCapRound|PenJoinRound, chartLineWidth96dpi, brush)
+		if err != nil {
+			brush.Dispose()
+			return err
+		}
+
+		for _, segment := range segments {
+			if len(segment) == 1 {
+				// A single value has no line to draw, so mark it instead.
+				segment = append(segment, segment[0])
+			}
+
+			if err := canvas.DrawPolylinePixels(pen, segment); err != nil {
+				pen.Dispose()
+				brush.Dispose()
+				return err
+			}
+		}
+
+		pen.Dispose()
+		brush.Dispose()
+	}
+
+	return nil
+}
+
+// paintToolTip draws a box with the label and values of the hot point.
+func (c *Chart) paintToolTip(canvas *Canvas, font *Font, fontHeight, padding int) error {
+	pb := c.plotBounds
+	x := c.xForIndex(c.hotIndex)
+
+	linePen, err := NewCosmeticPen(PenDot, Color(win.GetSysColor(win.COLOR_GRAYTEXT)))
+	if err != nil {
+		return err
+	}
+	defer linePen.Dispose()
+
+	if err := canvas.DrawLinePixels(linePen, Point{x, pb.Y}, Point{x, pb.Y + pb.Height}); err != nil {
+		return err
+	}
+
+	var lines []string
+	if label := c.Label(c.hotIndex); label != "" {
+		lines = append(lines, label)
+	}
+	firstSeriesLine := len(lines)
+	for _, s := range c.series {
+		value := "-"
+		if v := s.Value(c.hotIndex); !math.IsNaN(v) {
+			value = c.formatValue(v, 0)
+		}
+
+		lines = append(lines, s.name+": "+value)
+	}
+
+	swatchSize := fontHeight * 2 / 3
+
+	width := 0
+	for i, line := range lines {
+		b, _, err := canvas.MeasureTextPixels(line, font, Rectangle{Width: 10000, Height: 10000}, TextSingleLine|TextNoPrefix)
+		if err != nil {
+			return err
+		}
+
+		if i >= firstSeriesLine {
+			b.Width += swatchSize + padding/2
+		}
+		if b.Width > width {
+			width = b.Width
+		}
+	}
+
+	box := Rectangle{Width: width + 2*padding, Height: len(lines)*fontHeight + padding}
+	box.X = x + padding
+	if box.X+box.Width > pb.X+pb.Width {
+		box.X = x - padding - box.Width
+	}
+	if box.X < pb.X {
+		box.X = pb.X
+	}
+	box.Y = pb.Y + padding
+
+	bgBrush, err := NewSystemColorBrush(SysColorInfoBk)
+	if err != nil {
+		return err
+	}
+	defer bgBrush.Dispose()
+
+	if err := canvas.FillRectanglePixels(bgBrush, box); err != nil {
+		return err
+	}
+
+	borderPen, err := NewCosmeticPen(PenSolid, Color(win.GetSysColor(win.COLOR_GRAYTEXT)))
+	if err != nil {
+		return err
+	}
+	defer borderPen.Dispose()
+
+	if err := canvas.DrawRectanglePixels(borderPen, box); err != nil {
+		return err
+	}
+
+	infoTextColor := Color(win.GetSysColor(win.COLOR_INFOTEXT))
+
+	for i, line := range lines {
+		rc := Rectangle{box.X + padding, box.Y + padding/2 + i*fontHeight, width, fontHeight}
+
+		if i >= firstSeriesLine {
+			brush, err := NewSolidColorBrush(c.series[i-firstSeriesLine].color)
+			if err != nil {
+				return err
+			}
+			err = canvas.FillRectanglePixels(brush, Rectangle{rc.X, rc.Y + (fontHeight-swatchSize)/2, swatchSize, swatchSize})
+			brush.Dispose()
+			if err != nil {
+				return err
+			}
+
+			rc.X += swatchSize + padding/2
+			rc.Width -= swatchSize + padding/2
+		}
+
+		if err := canvas.DrawTextPixels(line, font, infoTextColor, rc, TextLeft|TextSingleLine|TextNoPrefix); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// blendColors returns the color that is weight of the way from background
+// to color.
+func blendColors(color, background Color, weight float64) Color {
+	blend := func(a, b byte) byte {
+		return byte(math.Round(float64(b) + (float64(a)-float64(b))*weight))
+	}
+
+	return RGB(blend(color.R(), background.R()), blend(color.G(), background.G()), blend(color.B(), background.B()))
+}
+
+func (c *Chart) WndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) uintptr {
+	switch msg {
+	case win.WM_PAINT:
+		var ps win.PAINTSTRUCT
+
+		hdc := win.BeginPaint(hwnd, &ps)
+		defer win.EndPaint(hwnd, &ps)
+
+		canvas, err := newCanvasFromHDC(hdc)
+		if err != nil {
+			return 0
+		}
+		defer canvas.Dispose()
+
+		c.paint(canvas, rectangleFromRECT(ps.RcPaint))
+
+		return 0
+
+	case win.WM_ERASEBKGND:
+		// Everything is drawn in WM_PAINT.
+		return 1
+
+	case win.WM_MOUSEMOVE:
+		c.setHotIndex(c.indexAt(Point{int(win.GET_X_LPARAM(lParam)), int(win.GET_Y_LPARAM(lParam))}))
+
+	case win.WM_MOUSELEAVE:
+		c.setHotIndex(-1)
+
+	case win.WM_SIZE:
+		c.Invalidate()
+	}
+
+	return c.WidgetBase.WndProc(hwnd, msg, wParam, lParam)
+}
+
+func (c *Chart) CreateLayoutItem(ctx *LayoutContext) LayoutItem {
+	return &chartLayoutItem{
+		idealSize: SizeFrom96DPI(Size{300, 200}, ctx.dpi),
+		minSize:   SizeFrom96DPI(Size{120, 80}, ctx.dpi),
+	}
+}
+
+type chartLayoutItem struct {
+	LayoutItemBase
+	idealSize Size // in native pixels
+	minSize   Size // in native pixels
+}
+
+func (*chartLayoutItem) LayoutFlags() LayoutFlags {
+	return ShrinkableHorz | GrowableHorz | GreedyHorz | ShrinkableVert | GrowableVert | GreedyVert
+}
+
+func (li *chartLayoutItem) IdealSize() Size {
+	return li.idealSize
+}
+
+func (li *chartLayoutItem) MinSize() Size {
+	return li.minSize
+}
@@ -7,11 +7,13 @@
 package walk
 
 import (
+	"reflect"
 	"strconv"
 
 	"github.com/tailscale/win"
 )
 
+// CheckState is the state of a CheckBox.
 type CheckState int
 
 const (
@@ -20,6 +22,106 @@ const (
 	CheckIndeterminate CheckState = win.BST_INDETERMINATE
 )
 
+func (cs CheckState) String() string {
+	switch cs {
+	case CheckUnchecked:
+		return "Unchecked"
+
+	case CheckChecked:
+		return "Checked"
+
+	case CheckIndeterminate:
+		return "Indeterminate"
+	}
+
+	return "CheckState(" + strconv.Itoa(int(cs)) + ")"
+}
+
+// CheckStateFromBool returns CheckChecked or CheckUnchecked for checked.
+func CheckStateFromBool(checked bool) CheckState {
+	if checked {
+		return CheckChecked
+	}
+
+	return CheckUnchecked
+}
+
+// AggregateCheckState returns the state of a check box standing for states:
+// CheckChecked or CheckUnchecked if all of them are, CheckIndeterminate
+// otherwise. It returns CheckUnchecked if there are no states.
+func AggregateCheckState(states ...CheckState) CheckState {
+	var checked, unchecked int
+	for _, state := range states {
+		switch state {
+		case CheckChecked:
+			checked++
+
+		case CheckUnchecked:
+			unchecked++
+		}
+	}
+
+	switch {
+	case checked == len(states) && checked > 0:
+		return CheckChecked
+
+	case unchecked == len(states):
+		return CheckUnchecked
+	}
+
+	return CheckIndeterminate
+}
+
+// checkStateFromValue converts the value of a data source field bound to the
+// CheckState property. Besides CheckState and int, this may be a bool or a
+// *bool, with nil standing for CheckIndeterminate.
+func checkStateFromValue(v interface{}) CheckState {
+	switch v := v.(type) {
+	case CheckState:
+		return v
+
+	case int:
+		return CheckState(v)
+
+	case bool:
+		return CheckStateFromBool(v)
+
+	case *bool:
+		if v == nil {
+			return CheckIndeterminate
+		}
+		return CheckStateFromBool(*v)
+	}
+
+	if rv := reflect.ValueOf(v); rv.IsValid() && rv.CanInt() {
+		return CheckState(rv.Int())
+	}
+
+	return CheckUnchecked
+}
+
+// checkStateToValue converts state to a value for a data source field of type
+// t bound to the CheckState property, the reverse of checkStateFromValue.
+func checkStateToValue(state CheckState, t reflect.Type) (interface{}, bool) {
+	switch {
+	case t.Kind() == reflect.Bool:
+		return reflect.ValueOf(state == CheckChecked).Convert(t).Interface(), true
+
+	case t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Bool:
+		if state == CheckIndeterminate {
+			return reflect.Zero(t).Interface(), true
+		}
+		v := reflect.New(t.Elem())
+		v.Elem().SetBool(state == CheckChecked)
+		return v.Interface(), true
+
+	case reflect.TypeOf(state).ConvertibleTo(t):
+		return reflect.ValueOf(state).Convert(t).Interface(), true
+	}
+
+	return nil, false
+}
+
 var checkBoxCheckSize Size // in native pixels
 
 type CheckBox struct {
@@ -51,7 +153,7 @@ func NewCheckBox(parent Container) (*CheckBox, error) {
 			return cb.CheckState()
 		},
 		func(v interface{}) error {
-			cb.SetCheckState(CheckState(assertIntOr(v, 0)))
+			cb.SetCheckState(checkStateFromValue(v))
 
 			return nil
 		},
@@ -93,17 +195,28 @@ func (cb *CheckBox) CheckState() CheckState {
 	return CheckState(cb.SendMessage(win.BM_GETCHECK, 0, 0))
 }
 
+// SetCheckState sets the state of cb. Setting CheckIndeterminate makes cb
+// tristate.
 func (cb *CheckBox) SetCheckState(state CheckState) {
 	if state == cb.CheckState() {
 		return
 	}
 
+	if state == CheckIndeterminate && !cb.Tristate() {
+		cb.SetTristate(true)
+	}
+
 	cb.SendMessage(win.BM_SETCHECK, uintptr(state), 0)
 
 	cb.checkedChangedPublisher.Publish()
 	cb.checkStateChangedPublisher.Publish()
 }
 
+// SetChecked checks or unchecks cb, also if it is indeterminate.
+func (cb *CheckBox) SetChecked(checked bool) {
+	cb.SetCheckState(CheckStateFromBool(checked))
+}
+
 func (cb *CheckBox) CheckStateChanged() *Event {
 	return cb.checkStateChangedPublisher.Event()
 }
@@ -0,0 +1,119 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+// CheckBoxGroup ties a parent CheckBox to child CheckBoxes, like one to select
+// all items of a list. The parent is checked or unchecked if all children are,
+// and indeterminate otherwise. Checking or unchecking the parent does the same
+// to all children; clicking it while indeterminate unchecks them.
+type CheckBoxGroup struct {
+	parent       *CheckBox
+	children     []*CheckBox
+	parentHandle int
+	childHandles []int
+	updating     bool
+}
+
+// NewCheckBoxGroup makes parent tristate and ties it to children.
+func NewCheckBoxGroup(parent *CheckBox, children ...*CheckBox) (*CheckBoxGroup, error) {
+	if parent == nil {
+		return nil, newError("parent must not be nil")
+	}
+
+	if err := parent.SetTristate(true); err != nil {
+		return nil, err
+	}
+
+	g := &CheckBoxGroup{
+		parent:   parent,
+		children: append([]*CheckBox(nil), children...),
+	}
+
+	g.parentHandle = parent.CheckStateChanged().Attach(g.parentCheckStateChanged)
+
+	for _, child := range g.children {
+		g.childHandles = append(g.childHandles, child.CheckStateChanged().Attach(g.update))
+	}
+
+	g.update()
+
+	return g, nil
+}
+
+// Parent returns the CheckBox standing for the children.
+func (g *CheckBoxGroup) Parent() *CheckBox {
+	return g.parent
+}
+
+// Children returns the CheckBoxes the parent stands for.
+func (g *CheckBoxGroup) Children() []*CheckBox {
+	return g.children
+}
+
+// Dispose unties the parent from the children.
+func (g *CheckBoxGroup) Dispose() {
+	if g.parent == nil {
+		return
+	}
+
+	g.parent.CheckStateChanged().Detach(g.parentHandle)
+
+	for i, child := range g.children {
+		child.CheckStateChanged().Detach(g.childHandles[i])
+	}
+
+	g.parent = nil
+	g.children = nil
+	g.childHandles = nil
+}
+
+// State returns the aggregated state of the children.
+func (g *CheckBoxGroup) State() CheckState {
+	states := make([]CheckState, len(g.children))
+	for i, child := range g.children {
+		states[i] = child.CheckState()
+	}
+
+	return AggregateCheckState(states...)
+}
+
+func (g *CheckBoxGroup) update() {
+	if g.updating {
+		return
+	}
+
+	g.updating = true
+	defer func() {
+		g.updating = false
+	}()
+
+	g.parent.SetCheckState(g.State())
+}
+
+func (g *CheckBoxGroup) parentCheckStateChanged() {
+	if g.updating {
+		return
+	}
+
+	g.updating = true
+	defer func() {
+		g.updating = false
+	}()
+
+	state := g.parent.CheckState()
+	if state == CheckIndeterminate {
+		// Clicking the checked parent cycles to indeterminate, which only
+		// the children can make it.
+		state = CheckUnchecked
+		g.parent.SetCheckState(state)
+	}
+
+	for _, child := range g.children {
+		child.SetCheckState(state)
+	}
+}
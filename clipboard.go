@@ -7,6 +7,9 @@
 package walk
 
 import (
+	"bytes"
+	"fmt"
+	"strconv"
 	"syscall"
 	"unsafe"
 
@@ -121,36 +124,105 @@ func (c *ClipboardService) Text() (text string, err error) {
 // SetText sets the current text data of the clipboard.
 func (c *ClipboardService) SetText(s string) error {
 	return c.withOpenClipboard(func() error {
-		utf16, err := syscall.UTF16FromString(s)
-		if err != nil {
-			return err
+		return c.setText(s)
+	})
+}
+
+// setTextAndHTML replaces the contents of the clipboard with text and an HTML
+// fragment representing the same data, so applications like spreadsheets and
+// word processors can paste it with formatting.
+func (c *ClipboardService) setTextAndHTML(text, htmlFragment string) error {
+	return c.withOpenClipboard(func() error {
+		if !win.EmptyClipboard() {
+			return lastError("EmptyClipboard")
 		}
 
-		hMem := win.GlobalAlloc(win.GMEM_MOVEABLE, uintptr(len(utf16)*2))
-		if hMem == 0 {
-			return lastError("GlobalAlloc")
+		if err := c.setText(text); err != nil {
+			return err
 		}
 
-		p := win.GlobalLock(hMem)
-		if p == nil {
-			return lastError("GlobalLock()")
+		format := registerClipboardFormat(clipboardFormatHTML)
+		if format == 0 {
+			return lastError("RegisterClipboardFormat")
 		}
 
-		win.MoveMemory(p, unsafe.Pointer(&utf16[0]), uintptr(len(utf16)*2))
+		return c.setData(format, clipboardHTML(htmlFragment))
+	})
+}
+
+func (c *ClipboardService) setText(s string) error {
+	utf16, err := syscall.UTF16FromString(s)
+	if err != nil {
+		return err
+	}
 
-		win.GlobalUnlock(hMem)
+	return c.setData(win.CF_UNICODETEXT, unsafe.Slice((*byte)(unsafe.Pointer(&utf16[0])), len(utf16)*2))
+}
+
+func (c *ClipboardService) setData(format uint32, data []byte) error {
+	hMem, err := globalAllocBytes(data)
+	if err != nil {
+		return err
+	}
+
+	if 0 == win.SetClipboardData(format, win.HANDLE(hMem)) {
+		// We need to free hMem.
+		defer win.GlobalFree(hMem)
+
+		return lastError("SetClipboardData")
+	}
 
-		if 0 == win.SetClipboardData(win.CF_UNICODETEXT, win.HANDLE(hMem)) {
-			// We need to free hMem.
-			defer win.GlobalFree(hMem)
+	// The system now owns the memory referred to by hMem.
 
-			return lastError("SetClipboardData")
+	return nil
+}
+
+// clipboardHTML returns fragment in the CF_HTML clipboard format, i.e. UTF-8
+// with a header of byte offsets.
+func clipboardHTML(fragment string) []byte {
+	const (
+		header = "Version:0.9\r\nStartHTML:%010d\r\nEndHTML:%010d\r\nStartFragment:%010d\r\nEndFragment:%010d\r\n"
+		prefix = "<html><body>\r\n<!--StartFragment-->"
+		suffix = "<!--EndFragment-->\r\n</body></html>"
+	)
+
+	// All offsets have a fixed width, so the header length is known upfront.
+	startHTML := len(fmt.Sprintf(header, 0, 0, 0, 0))
+	startFragment := startHTML + len(prefix)
+	endFragment := startFragment + len(fragment)
+	endHTML := endFragment + len(suffix)
+
+	return []byte(fmt.Sprintf(header, startHTML, endHTML, startFragment, endFragment) + prefix + fragment + suffix + "\x00")
+}
+
+// fragmentFromClipboardHTML returns the fragment of data, which is in the
+// CF_HTML clipboard format.
+func fragmentFromClipboardHTML(data []byte) (string, error) {
+	offset := func(name string) int {
+		i := bytes.Index(data, []byte(name+":"))
+		if i < 0 {
+			return -1
 		}
 
-		// The system now owns the memory referred to by hMem.
+		digits := data[i+len(name)+1:]
+		if j := bytes.IndexAny(digits, "\r\n"); j >= 0 {
+			digits = digits[:j]
+		}
 
-		return nil
-	})
+		n, err := strconv.Atoi(string(digits))
+		if err != nil {
+			return -1
+		}
+
+		return n
+	}
+
+	start, end := offset("StartFragment"), offset("EndFragment")
+	if start < 0 || end < start || end > len(data) {
+		return "", newError("invalid CF_HTML data")
+	}
+
+	return string(data[start:end]), nil
 }
 
 func (c *ClipboardService) withOpenClipboard(f func() error) error {
@@ -161,3 +233,24 @@ func (c *ClipboardService) withOpenClipboard(f func() error) error {
 
 	return f()
 }
+
+// globalAllocBytes returns movable global memory holding a copy of data, which
+// the caller must free, unless it passes ownership to the system.
+func globalAllocBytes(data []byte) (win.HGLOBAL, error) {
+	hMem := win.GlobalAlloc(win.GMEM_MOVEABLE, uintptr(len(data)))
+	if hMem == 0 {
+		return 0, lastError("GlobalAlloc")
+	}
+
+	p := win.GlobalLock(hMem)
+	if p == nil {
+		win.GlobalFree(hMem)
+		return 0, lastError("GlobalLock()")
+	}
+
+	win.MoveMemory(p, unsafe.Pointer(&data[0]), uintptr(len(data)))
+
+	win.GlobalUnlock(hMem)
+
+	return hMem, nil
+}
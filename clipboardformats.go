@@ -0,0 +1,393 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/png"
+	"unsafe"
+
+	"github.com/tailscale/win"
+)
+
+// The names of registered clipboard formats that walk supports.
+const (
+	clipboardFormatHTML = "HTML Format"
+	clipboardFormatPNG  = "PNG"
+	clipboardFormatRTF  = "Rich Text Format"
+)
+
+// ContainsHTML returns whether the clipboard currently contains an HTML
+// fragment.
+func (c *ClipboardService) ContainsHTML() (bool, error) {
+	return c.containsNamed(clipboardFormatHTML)
+}
+
+// HTML returns the current HTML fragment of the clipboard.
+func (c *ClipboardService) HTML() (fragment string, err error) {
+	data, err := c.Data(clipboardFormatHTML)
+	if err != nil {
+		return "", err
+	}
+
+	return fragmentFromClipboardHTML(data)
+}
+
+// SetHTML replaces the contents of the clipboard with an HTML fragment. Use
+// SetContents to provide a plain text version as well.
+func (c *ClipboardService) SetHTML(fragment string) error {
+	return c.replaceWith(clipboardFormatHTML, clipboardHTML(fragment))
+}
+
+// ContainsRTF returns whether the clipboard currently contains rich text.
+func (c *ClipboardService) ContainsRTF() (bool, error) {
+	return c.containsNamed(clipboardFormatRTF)
+}
+
+// RTF returns the current rich text of the clipboard, as RTF document.
+func (c *ClipboardService) RTF() (string, error) {
+	data, err := c.Data(clipboardFormatRTF)
+	if err != nil {
+		return "", err
+	}
+
+	if i := bytes.IndexByte(data, 0); i >= 0 {
+		data = data[:i]
+	}
+
+	return string(data), nil
+}
+
+// SetRTF replaces the contents of the clipboard with rich text, an RTF
+// document.
+func (c *ClipboardService) SetRTF(rtf string) error {
+	return c.replaceWith(clipboardFormatRTF, append([]byte(rtf), 0))
+}
+
+// ContainsImage returns whether the clipboard currently contains an image.
+func (c *ClipboardService) ContainsImage() (available bool, err error) {
+	err = c.withOpenClipboard(func() error {
+		available = win.IsClipboardFormatAvailable(win.CF_DIB)
+		if !available {
+			if format := registerClipboardFormat(clipboardFormatPNG); format != 0 {
+				available = win.IsClipboardFormatAvailable(format)
+			}
+		}
+
+		return nil
+	})
+
+	return
+}
+
+// Image returns the current image of the clipboard. PNG data, which keeps
+// transparency, is preferred over a device-independent bitmap.
+func (c *ClipboardService) Image() (bmp *Bitmap, err error) {
+	var im image.Image
+
+	err = c.withOpenClipboard(func() error {
+		if format := registerClipboardFormat(clipboardFormatPNG); format != 0 && win.IsClipboardFormatAvailable(format) {
+			return c.withClipboardData(format, func(data []byte) (err error) {
+				im, err = png.Decode(bytes.NewReader(data))
+				return wrapError(err)
+			})
+		}
+
+		return c.withClipboardData(win.CF_DIB, func(data []byte) (err error) {
+			im, err = imageFromDIB(data)
+			return err
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return NewBitmapFromImage(im)
+}
+
+// SetImage replaces the contents of the clipboard with bmp, as PNG data for
+// applications that support transparency and as device-independent bitmap for
+// all others.
+func (c *ClipboardService) SetImage(bmp *Bitmap) error {
+	im, err := bmp.ToImage()
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, im); err != nil {
+		return wrapError(err)
+	}
+
+	return c.withOpenClipboard(func() error {
+		if !win.EmptyClipboard() {
+			return lastError("EmptyClipboard")
+		}
+
+		if err := c.setData(win.CF_DIB, dibFromImage(im)); err != nil {
+			return err
+		}
+
+		format := registerClipboardFormat(clipboardFormatPNG)
+		if format == 0 {
+			return lastError("RegisterClipboardFormat")
+		}
+
+		return c.setData(format, buf.Bytes())
+	})
+}
+
+// ContainsFiles returns whether the clipboard currently contains file paths,
+// e.g. of files copied in Explorer.
+func (c *ClipboardService) ContainsFiles() (available bool, err error) {
+	err = c.withOpenClipboard(func() error {
+		available = win.IsClipboardFormatAvailable(win.CF_HDROP)
+
+		return nil
+	})
+
+	return
+}
+
+// Files returns the current file paths of the clipboard.
+func (c *ClipboardService) Files() (paths []string, err error) {
+	err = c.withOpenClipboard(func() error {
+		hDrop := win.HDROP(win.GetClipboardData(win.CF_HDROP))
+		if hDrop == 0 {
+			return lastError("GetClipboardData")
+		}
+
+		paths = dragQueryFiles(hDrop)
+
+		return nil
+	})
+
+	return
+}
+
+// SetFiles replaces the contents of the clipboard with file paths, which
+// Explorer pastes as copies of the files. The paths should be absolute.
+func (c *ClipboardService) SetFiles(paths []string) error {
+	data, err := dropFilesBytes(paths)
+	if err != nil {
+		return err
+	}
+
+	return c.withOpenClipboard(func() error {
+		if !win.EmptyClipboard() {
+			return lastError("EmptyClipboard")
+		}
+
+		return c.setData(win.CF_HDROP, data)
+	})
+}
+
+// ContainsData returns whether the clipboard currently contains data in the
+// custom clipboard format named format.
+func (c *ClipboardService) ContainsData(format string) (bool, error) {
+	return c.containsNamed(format)
+}
+
+// Data returns the current data of the clipboard in the custom clipboard
+// format named format.
+func (c *ClipboardService) Data(format string) (data []byte, err error) {
+	id := registerClipboardFormat(format)
+	if id == 0 {
+		return nil, lastError("RegisterClipboardFormat")
+	}
+
+	err = c.withOpenClipboard(func() error {
+		return c.withClipboardData(id, func(d []byte) error {
+			data = append([]byte(nil), d...)
+
+			return nil
+		})
+	})
+
+	return
+}
+
+// SetData replaces the contents of the clipboard with data in the custom
+// clipboard format named format. Applications agree on a format by its name.
+func (c *ClipboardService) SetData(format string, data []byte) error {
+	if len(data) == 0 {
+		// Global memory cannot be empty.
+		data = []byte{0}
+	}
+
+	return c.replaceWith(format, data)
+}
+
+// SetContents replaces the contents of the clipboard with all formats data
+// holds at once, so applications can paste the one they understand best. This
+// way, copy and drag can share the code that provides the data. The drag image
+// of data is ignored.
+func (c *ClipboardService) SetContents(data *DragData) error {
+	return c.withOpenClipboard(func() error {
+		if !win.EmptyClipboard() {
+			return lastError("EmptyClipboard")
+		}
+
+		return data.forEachFormat(c.setData)
+	})
+}
+
+func (c *ClipboardService) containsNamed(name string) (available bool, err error) {
+	format := registerClipboardFormat(name)
+	if format == 0 {
+		return false, lastError("RegisterClipboardFormat")
+	}
+
+	err = c.withOpenClipboard(func() error {
+		available = win.IsClipboardFormatAvailable(format)
+
+		return nil
+	})
+
+	return
+}
+
+// replaceWith replaces the contents of the clipboard with data in the
+// registered format named name.
+func (c *ClipboardService) replaceWith(name string, data []byte) error {
+	format := registerClipboardFormat(name)
+	if format == 0 {
+		return lastError("RegisterClipboardFormat")
+	}
+
+	return c.withOpenClipboard(func() error {
+		if !win.EmptyClipboard() {
+			return lastError("EmptyClipboard")
+		}
+
+		return c.setData(format, data)
+	})
+}
+
+// withClipboardData calls f with the data of the clipboard in format, which is
+// only valid while f runs. The clipboard must be open.
+func (c *ClipboardService) withClipboardData(format uint32, f func(data []byte) error) error {
+	hMem := win.HGLOBAL(win.GetClipboardData(format))
+	if hMem == 0 {
+		return lastError("GetClipboardData")
+	}
+
+	p := win.GlobalLock(hMem)
+	if p == nil {
+		return lastError("GlobalLock()")
+	}
+	defer win.GlobalUnlock(hMem)
+
+	return f(unsafe.Slice((*byte)(p), globalSize(hMem)))
+}
+
+// imageFromDIB returns the image in data, a packed device-independent bitmap
+// as in CF_DIB. Only 24 and 32 bits per pixel are supported, as used by
+// screenshots and images copied from most applications.
+func imageFromDIB(data []byte) (image.Image, error) {
+	if len(data) < 40 {
+		return nil, newError("invalid DIB")
+	}
+
+	headerSize := int(binary.LittleEndian.Uint32(data[0:]))
+	width := int(int32(binary.LittleEndian.Uint32(data[4:])))
+	height := int(int32(binary.LittleEndian.Uint32(data[8:])))
+	bitCount := int(binary.LittleEndian.Uint16(data[14:]))
+	compression := binary.LittleEndian.Uint32(data[16:])
+	colorsUsed := int(binary.LittleEndian.Uint32(data[32:]))
+
+	if bitCount != 24 && bitCount != 32 || compression != win.BI_RGB && compression != win.BI_BITFIELDS {
+		return nil, newError("unsupported DIB format")
+	}
+
+	offset := headerSize + colorsUsed*4
+	if compression == win.BI_BITFIELDS && headerSize == 40 {
+		// The color masks follow the header.
+		offset += 12
+	}
+
+	bottomUp := height > 0
+	if !bottomUp {
+		height = -height
+	}
+
+	stride := (width*bitCount + 31) / 32 * 4
+	if width <= 0 || height <= 0 || offset+stride*height > len(data) {
+		return nil, newError("invalid DIB")
+	}
+
+	im := image.NewNRGBA(image.Rect(0, 0, width, height))
+
+	// 32-bit DIBs often leave the alpha channel unused, i.e. all zero.
+	opaque := true
+	if bitCount == 32 {
+		for y := 0; y < height && opaque; y++ {
+			row := data[offset+y*stride:]
+			for x := 0; x < width; x++ {
+				if row[x*4+3] != 0 {
+					opaque = false
+					break
+				}
+			}
+		}
+	}
+
+	for y := 0; y < height; y++ {
+		row := data[offset+y*stride:]
+
+		dstY := y
+		if bottomUp {
+			dstY = height - 1 - y
+		}
+
+		for x := 0; x < width; x++ {
+			px := row[x*bitCount/8:]
+
+			a := byte(0xFF)
+			if bitCount == 32 && !opaque {
+				a = px[3]
+			}
+
+			im.SetNRGBA(x, dstY, color.NRGBA{px[2], px[1], px[0], a})
+		}
+	}
+
+	return im, nil
+}
+
+// dibFromImage returns im as a packed device-independent bitmap with 32 bits
+// per pixel, as in CF_DIB.
+func dibFromImage(im image.Image) []byte {
+	bounds := im.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	header := win.BITMAPINFOHEADER{
+		BiWidth:       int32(width),
+		BiHeight:      int32(height),
+		BiPlanes:      1,
+		BiBitCount:    32,
+		BiCompression: win.BI_RGB,
+		BiSizeImage:   uint32(width * height * 4),
+	}
+	header.BiSize = uint32(unsafe.Sizeof(header))
+
+	data := append([]byte(nil), unsafe.Slice((*byte)(unsafe.Pointer(&header)), unsafe.Sizeof(header))...)
+
+	// The rows are stored bottom-up.
+	for y := bounds.Max.Y - 1; y >= bounds.Min.Y; y-- {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := color.NRGBAModel.Convert(im.At(x, y)).(color.NRGBA)
+			data = append(data, c.B, c.G, c.R, c.A)
+		}
+	}
+
+	return data
+}
@@ -0,0 +1,695 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"unsafe"
+
+	"github.com/tailscale/win"
+)
+
+const (
+	codeEditGutterPadding96dpi = 4
+	codeEditMarkerWidth96dpi   = 3
+	codeEditDefaultTabWidth    = 4
+
+	// codeEditMaxDiffCells limits the work done to find the changed lines
+	// after each edit. Beyond it, all lines between the first and the last
+	// change are marked.
+	codeEditMaxDiffCells = 1 << 20
+)
+
+// CodeEdit is a TextEdit for editing code or configuration files.
+//
+// It uses a monospaced font, does not wrap lines and indents new lines like
+// the previous one. A gutter displays the line numbers and marks the lines
+// changed since the text was set, and the line containing the caret is
+// highlighted.
+type CodeEdit struct {
+	TextEdit
+	tabWidth                    int
+	insertSpaces                bool
+	lineNumbersVisible          bool
+	changeMarkersVisible        bool
+	currentLineHighlighted      bool
+	gutterWidth                 int // in native pixels
+	currentLine                 int
+	firstVisibleLine            int
+	lineBand                    Rectangle
+	lineBrush                   *BitmapBrush
+	baseline                    []string
+	changedLines                map[int]bool
+	currentLineChangedPublisher EventPublisher
+}
+
+// NewCodeEdit creates a new CodeEdit as child of parent.
+func NewCodeEdit(parent Container) (*CodeEdit, error) {
+	ce := &CodeEdit{
+		tabWidth:               codeEditDefaultTabWidth,
+		lineNumbersVisible:     true,
+		changeMarkersVisible:   true,
+		currentLineHighlighted: true,
+	}
+
+	if err := ce.TextEdit.init(
+		ce,
+		parent,
+		win.WS_HSCROLL|win.WS_VSCROLL|win.ES_AUTOHSCROLL|win.ES_AUTOVSCROLL); err != nil {
+		return nil, err
+	}
+
+	succeeded := false
+	defer func() {
+		if !succeeded {
+			ce.Dispose()
+		}
+	}()
+
+	font, err := NewFont("Consolas", 10, 0)
+	if err != nil {
+		return nil, err
+	}
+	ce.SetFont(font)
+
+	ce.readOnlyChangedPublisher.Event().Attach(func() {
+		ce.updateLineBrush()
+		ce.Invalidate()
+	})
+
+	ce.ClearChangeMarkers()
+
+	succeeded = true
+
+	return ce, nil
+}
+
+func (ce *CodeEdit) Dispose() {
+	if ce.lineBrush != nil {
+		ce.lineBrush.Dispose()
+		ce.lineBrush = nil
+	}
+
+	ce.TextEdit.Dispose()
+}
+
+func (ce *CodeEdit) applyFont(font *Font) {
+	ce.TextEdit.applyFont(font)
+
+	ce.updateTabStops()
+	ce.updateGutter()
+	ce.updateLineBrush()
+}
+
+// TabWidth returns the number of columns between tab stops.
+func (ce *CodeEdit) TabWidth() int {
+	return ce.tabWidth
+}
+
+// SetTabWidth sets the number of columns between tab stops, 4 by default.
+func (ce *CodeEdit) SetTabWidth(width int) error {
+	if width < 1 {
+		return newError("width must be positive")
+	}
+
+	ce.tabWidth = width
+
+	ce.updateTabStops()
+
+	return ce.Invalidate()
+}
+
+// InsertSpaces returns whether the Tab key inserts spaces up to the next tab
+// stop instead of a tab character.
+func (ce *CodeEdit) InsertSpaces() bool {
+	return ce.insertSpaces
+}
+
+// SetInsertSpaces sets whether the Tab key inserts spaces up to the next tab
+// stop instead of a tab character.
+func (ce *CodeEdit) SetInsertSpaces(insertSpaces bool) {
+	ce.insertSpaces = insertSpaces
+}
+
+// LineNumbersVisible returns whether the gutter displays the line numbers.
+func (ce *CodeEdit) LineNumbersVisible() bool {
+	return ce.lineNumbersVisible
+}
+
+// SetLineNumbersVisible sets whether the gutter displays the line numbers.
+func (ce *CodeEdit) SetLineNumbersVisible(visible bool) {
+	ce.lineNumbersVisible = visible
+
+	ce.updateGutter()
+}
+
+// ChangeMarkersVisible returns whether the gutter marks the lines changed
+// since the text was set or ClearChangeMarkers was called.
+func (ce *CodeEdit) ChangeMarkersVisible() bool {
+	return ce.changeMarkersVisible
+}
+
+// SetChangeMarkersVisible sets whether the gutter marks the lines changed
+// since the text was set or ClearChangeMarkers was called.
+func (ce *CodeEdit) SetChangeMarkersVisible(visible bool) {
+	ce.changeMarkersVisible = visible
+
+	ce.updateGutter()
+}
+
+// CurrentLineHighlighted returns whether the line containing the caret is
+// highlighted.
+func (ce *CodeEdit) CurrentLineHighlighted() bool {
+	return ce.currentLineHighlighted
+}
+
+// SetCurrentLineHighlighted sets whether the line containing the caret is
+// highlighted.
+func (ce *CodeEdit) SetCurrentLineHighlighted(highlighted bool) {
+	ce.currentLineHighlighted = highlighted
+
+	ce.updateLineBrush()
+	ce.Invalidate()
+}
+
+// ClearChangeMarkers makes the current text the one changes are marked
+// against.
+func (ce *CodeEdit) ClearChangeMarkers() {
+	ce.baseline = ce.lines()
+	ce.changedLines = nil
+
+	ce.invalidateGutter()
+}
+
+// ChangedLines returns the numbers, starting at 1, of the lines added or
+// modified since the text was set or ClearChangeMarkers was called.
+func (ce *CodeEdit) ChangedLines() []int {
+	lines := make([]int, 0, len(ce.changedLines))
+	for line := range ce.changedLines {
+		lines = append(lines, line+1)
+	}
+
+	sort.Ints(lines)
+
+	return lines
+}
+
+// LineCount returns the number of lines of the text.
+func (ce *CodeEdit) LineCount() int {
+	return int(ce.SendMessage(win.EM_GETLINECOUNT, 0, 0))
+}
+
+// CurrentLine returns the number, starting at 1, of the line containing the
+// caret.
+func (ce *CodeEdit) CurrentLine() int {
+	return int(ce.SendMessage(win.EM_LINEFROMCHAR, ^uintptr(0), 0)) + 1
+}
+
+// CurrentLineChanged returns the event that is published when the caret moves
+// to another line.
+func (ce *CodeEdit) CurrentLineChanged() *Event {
+	return ce.currentLineChangedPublisher.Event()
+}
+
+// GoToLine moves the caret to the start of the line numbered line, starting
+// at 1, and scrolls it into view.
+func (ce *CodeEdit) GoToLine(line int) error {
+	if line < 1 || line > ce.LineCount() {
+		return newError("line out of range")
+	}
+
+	index := int(ce.SendMessage(win.EM_LINEINDEX, uintptr(line-1), 0))
+	ce.SetTextSelection(index, index)
+	ce.ScrollToCaret()
+
+	ce.updateCurrentLine()
+
+	return nil
+}
+
+func (ce *CodeEdit) lines() []string {
+	return strings.Split(ce.Text(), "\r\n")
+}
+
+// lineText returns the text of the line at index line, starting at 0.
+func (ce *CodeEdit) lineText(line int) string {
+	index := ce.SendMessage(win.EM_LINEINDEX, uintptr(line), 0)
+	length := int(ce.SendMessage(win.EM_LINELENGTH, index, 0))
+
+	// EM_GETLINE expects the size of the buffer in its first word.
+	buf := make([]uint16, length+1)
+	buf[0] = uint16(len(buf))
+
+	n := ce.SendMessage(win.EM_GETLINE, uintptr(line), uintptr(unsafe.Pointer(&buf[0])))
+
+	return syscall.UTF16ToString(buf[:n])
+}
+
+func (ce *CodeEdit) lineHeight() int {
+	return ce.calculateTextSizeImpl("gM").Height
+}
+
+func (ce *CodeEdit) updateTabStops() {
+	if ce.hWnd == 0 {
+		return
+	}
+
+	// Tab stops are in dialog template units, 4 of which make an average
+	// character width.
+	stops := uint32(ce.tabWidth * 4)
+	ce.SendMessage(win.EM_SETTABSTOPS, 1, uintptr(unsafe.Pointer(&stops)))
+}
+
+// insertTab inserts spaces up to the next tab stop.
+func (ce *CodeEdit) insertTab() {
+	line := ce.CurrentLine() - 1
+	start, _ := ce.TextSelection()
+	lineStart := int(ce.SendMessage(win.EM_LINEINDEX, uintptr(line), 0))
+
+	var column int
+	for i, c := range syscall.StringToUTF16(ce.lineText(line)) {
+		if i >= start-lineStart || c == 0 {
+			break
+		}
+		if c == '\t' {
+			column += ce.tabWidth - column%ce.tabWidth
+		} else {
+			column++
+		}
+	}
+
+	ce.ReplaceSelectedText(strings.Repeat(" ", ce.tabWidth-column%ce.tabWidth), true)
+}
+
+// insertNewLine starts a new line with the indentation of the current one.
+func (ce *CodeEdit) insertNewLine() {
+	line := ce.CurrentLine() - 1
+	start, _ := ce.TextSelection()
+	lineStart := int(ce.SendMessage(win.EM_LINEINDEX, uintptr(line), 0))
+
+	text := ce.lineText(line)
+	indent := text[:len(text)-len(strings.TrimLeft(text, " \t"))]
+	if n := start - lineStart; n < len(indent) {
+		indent = indent[:n]
+	}
+
+	ce.ReplaceSelectedText("\r\n"+indent, true)
+}
+
+func (ce *CodeEdit) updateGutter() {
+	if ce.hWnd == 0 {
+		return
+	}
+
+	padding := ce.IntFrom96DPI(codeEditGutterPadding96dpi)
+
+	var width int
+	if ce.lineNumbersVisible || ce.changeMarkersVisible {
+		width = 2 * padding
+		if ce.lineNumbersVisible {
+			digits := len(strconv.Itoa(ce.LineCount()))
+			if digits < 2 {
+				digits = 2
+			}
+			width += ce.calculateTextSizeImpl(strings.Repeat("0", digits)).Width
+		}
+		if ce.changeMarkersVisible {
+			width += ce.IntFrom96DPI(codeEditMarkerWidth96dpi)
+		}
+	}
+
+	if width == ce.gutterWidth {
+		ce.invalidateGutter()
+		return
+	}
+
+	ce.gutterWidth = width
+
+	margin := width
+	if width > 0 {
+		margin += padding
+	}
+	ce.SendMessage(win.EM_SETMARGINS, _EC_LEFTMARGIN, uintptr(win.MAKELONG(uint16(margin), 0)))
+
+	ce.Invalidate()
+}
+
+func (ce *CodeEdit) invalidateGutter() {
+	if ce.hWnd == 0 || ce.gutterWidth == 0 {
+		return
+	}
+
+	cb := ce.ClientBoundsPixels()
+	rc := win.RECT{Right: int32(ce.gutterWidth), Bottom: int32(cb.Height)}
+	win.InvalidateRect(ce.hWnd, &rc, false)
+}
+
+// currentLineBand returns the bounds of the line containing the caret in
+// native pixels.
+func (ce *CodeEdit) currentLineBand() Rectangle {
+	var rc win.RECT
+	ce.SendMessage(win.EM_GETRECT, 0, uintptr(unsafe.Pointer(&rc)))
+
+	lineHeight := ce.lineHeight()
+	first := int(ce.SendMessage(win.EM_GETFIRSTVISIBLELINE, 0, 0))
+
+	return Rectangle{
+		X:      ce.gutterWidth,
+		Y:      int(rc.Top) + (ce.CurrentLine()-1-first)*lineHeight,
+		Width:  ce.ClientBoundsPixels().Width - ce.gutterWidth,
+		Height: lineHeight,
+	}
+}
+
+func (ce *CodeEdit) backgroundColor() Color {
+	if ce.ReadOnly() {
+		return Color(win.GetSysColor(win.COLOR_BTNFACE))
+	}
+
+	return Color(win.GetSysColor(win.COLOR_WINDOW))
+}
+
+// updateLineBrush recreates the brush the edit control paints its background
+// with, which includes the highlight of the current line.
+func (ce *CodeEdit) updateLineBrush() {
+	if ce.hWnd == 0 {
+		return
+	}
+
+	cb := ce.ClientBoundsPixels()
+	if cb.Width <= 0 || cb.Height <= 0 {
+		return
+	}
+
+	ce.lineBand = ce.currentLineBand()
+
+	bmp, err := NewBitmapForDPI(cb.Size(), ce.DPI())
+	if err != nil {
+		return
+	}
+
+	if err := ce.paintLineBitmap(bmp, cb); err != nil {
+		bmp.Dispose()
+		return
+	}
+
+	brush, err := NewBitmapBrush(bmp)
+	if err != nil {
+		bmp.Dispose()
+		return
+	}
+	brush.ownsBitmap = true
+
+	if ce.lineBrush != nil {
+		ce.lineBrush.Dispose()
+	}
+	ce.lineBrush = brush
+}
+
+func (ce *CodeEdit) paintLineBitmap(bmp *Bitmap, bounds Rectangle) error {
+	canvas, err := NewCanvasFromImage(bmp)
+	if err != nil {
+		return err
+	}
+	defer canvas.Dispose()
+
+	bg := ce.backgroundColor()
+
+	bgBrush, err := NewSolidColorBrush(bg)
+	if err != nil {
+		return err
+	}
+	defer bgBrush.Dispose()
+
+	if err := canvas.FillRectanglePixels(bgBrush, bounds); err != nil {
+		return err
+	}
+
+	if !ce.currentLineHighlighted {
+		return nil
+	}
+
+	bandBrush, err := NewSolidColorBrush(blendColor(Color(win.GetSysColor(win.COLOR_HIGHLIGHT)), bg, 0x20))
+	if err != nil {
+		return err
+	}
+	defer bandBrush.Dispose()
+
+	return canvas.FillRectanglePixels(bandBrush, ce.lineBand)
+}
+
+// updateCurrentLine moves the highlight to the line containing the caret, if
+// it moved or scrolled.
+func (ce *CodeEdit) updateCurrentLine() {
+	line := ce.CurrentLine()
+	first := int(ce.SendMessage(win.EM_GETFIRSTVISIBLELINE, 0, 0))
+	if line == ce.currentLine && first == ce.firstVisibleLine && ce.currentLineBand() == ce.lineBand {
+		return
+	}
+
+	changed := line != ce.currentLine
+	ce.currentLine = line
+	ce.firstVisibleLine = first
+
+	oldBand := ce.lineBand.toRECT()
+	ce.updateLineBrush()
+	newBand := ce.lineBand.toRECT()
+
+	win.InvalidateRect(ce.hWnd, &oldBand, true)
+	win.InvalidateRect(ce.hWnd, &newBand, true)
+	ce.invalidateGutter()
+
+	if changed {
+		ce.currentLineChangedPublisher.Publish()
+	}
+}
+
+func (ce *CodeEdit) updateChangedLines() {
+	ce.changedLines = changedLines(ce.baseline, ce.lines())
+
+	ce.invalidateGutter()
+}
+
+// changedLines returns the indexes of the lines of lines added or modified
+// compared to base.
+func changedLines(base, lines []string) map[int]bool {
+	changed := make(map[int]bool)
+
+	prefix := 0
+	for prefix < len(base) && prefix < len(lines) && base[prefix] == lines[prefix] {
+		prefix++
+	}
+
+	suffix := 0
+	for suffix < len(base)-prefix && suffix < len(lines)-prefix &&
+		base[len(base)-1-suffix] == lines[len(lines)-1-suffix] {
+		suffix++
+	}
+
+	a := base[prefix : len(base)-suffix]
+	b := lines[prefix : len(lines)-suffix]
+
+	if len(a) == 0 || len(a)*len(b) > codeEditMaxDiffCells {
+		for i := range b {
+			changed[prefix+i] = true
+		}
+		return changed
+	}
+
+	// The lines of b that are not part of a longest common subsequence of a
+	// and b were changed.
+	w := len(b) + 1
+	lengths := make([]int32, (len(a)+1)*w)
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lengths[i*w+j] = lengths[(i+1)*w+j+1] + 1
+			} else if l := lengths[(i+1)*w+j]; l > lengths[i*w+j+1] {
+				lengths[i*w+j] = l
+			} else {
+				lengths[i*w+j] = lengths[i*w+j+1]
+			}
+		}
+	}
+
+	for i, j := 0, 0; j < len(b); {
+		switch {
+		case i < len(a) && a[i] == b[j]:
+			i++
+			j++
+
+		case i < len(a) && lengths[(i+1)*w+j] >= lengths[i*w+j+1]:
+			i++
+
+		default:
+			changed[prefix+j] = true
+			j++
+		}
+	}
+
+	return changed
+}
+
+func (ce *CodeEdit) paintGutter() error {
+	if ce.gutterWidth == 0 {
+		return nil
+	}
+
+	hdc := win.GetDC(ce.hWnd)
+	defer win.ReleaseDC(ce.hWnd, hdc)
+
+	canvas, err := newCanvasFromHDC(hdc)
+	if err != nil {
+		return err
+	}
+	defer canvas.Dispose()
+
+	cb := ce.ClientBoundsPixels()
+
+	bgBrush, err := NewSolidColorBrush(Color(win.GetSysColor(win.COLOR_BTNFACE)))
+	if err != nil {
+		return err
+	}
+	defer bgBrush.Dispose()
+
+	if err := canvas.FillRectanglePixels(bgBrush, Rectangle{0, 0, ce.gutterWidth, cb.Height}); err != nil {
+		return err
+	}
+
+	markerBrush, err := NewSolidColorBrush(Color(win.GetSysColor(win.COLOR_HIGHLIGHT)))
+	if err != nil {
+		return err
+	}
+	defer markerBrush.Dispose()
+
+	var rc win.RECT
+	ce.SendMessage(win.EM_GETRECT, 0, uintptr(unsafe.Pointer(&rc)))
+
+	padding := ce.IntFrom96DPI(codeEditGutterPadding96dpi)
+	var markerWidth int
+	if ce.changeMarkersVisible {
+		markerWidth = ce.IntFrom96DPI(codeEditMarkerWidth96dpi)
+	}
+
+	lineHeight := ce.lineHeight()
+	first := int(ce.SendMessage(win.EM_GETFIRSTVISIBLELINE, 0, 0))
+	count := ce.LineCount()
+	current := ce.CurrentLine() - 1
+	font := ce.Font()
+
+	for line := first; line < count; line++ {
+		y := int(rc.Top) + (line-first)*lineHeight
+		if y >= cb.Height {
+			break
+		}
+
+		if markerWidth > 0 && ce.changedLines[line] {
+			bounds := Rectangle{ce.gutterWidth - markerWidth, y, markerWidth, lineHeight}
+			if err := canvas.FillRectanglePixels(markerBrush, bounds); err != nil {
+				return err
+			}
+		}
+
+		if ce.lineNumbersVisible {
+			color := Color(win.GetSysColor(win.COLOR_GRAYTEXT))
+			if line == current {
+				color = Color(win.GetSysColor(win.COLOR_WINDOWTEXT))
+			}
+
+			bounds := Rectangle{padding, y, ce.gutterWidth - 2*padding - markerWidth, lineHeight}
+			if err := canvas.DrawTextPixels(strconv.Itoa(line+1), font, color, bounds, TextRight|TextVCenter|TextSingleLine); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (ce *CodeEdit) ctlColorBrush(hdc win.HDC) win.HBRUSH {
+	if ce.lineBrush == nil {
+		return 0
+	}
+
+	// The text is drawn over the highlight of the current line.
+	win.SetBkMode(hdc, win.TRANSPARENT)
+
+	return ce.lineBrush.hBrush
+}
+
+func (ce *CodeEdit) WndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) uintptr {
+	switch msg {
+	case win.WM_PAINT:
+		result := ce.TextEdit.WndProc(hwnd, msg, wParam, lParam)
+
+		ce.paintGutter()
+
+		return result
+
+	case win.WM_GETDLGCODE:
+		return ce.TextEdit.WndProc(hwnd, msg, wParam, lParam) | win.DLGC_WANTTAB
+
+	case win.WM_CHAR:
+		if ce.ReadOnly() {
+			break
+		}
+
+		switch wParam {
+		case '\t':
+			if ce.insertSpaces && !ShiftDown() {
+				ce.insertTab()
+				return 0
+			}
+
+		case '\r':
+			ce.insertNewLine()
+			return 0
+		}
+
+	case win.WM_SETTEXT:
+		result := ce.TextEdit.WndProc(hwnd, msg, wParam, lParam)
+
+		ce.ClearChangeMarkers()
+		ce.updateGutter()
+		ce.updateCurrentLine()
+
+		return result
+
+	case win.WM_COMMAND:
+		switch win.HIWORD(uint32(wParam)) {
+		case win.EN_CHANGE:
+			ce.updateChangedLines()
+			ce.updateGutter()
+			ce.updateCurrentLine()
+
+		case win.EN_HSCROLL, win.EN_VSCROLL:
+			ce.updateCurrentLine()
+		}
+
+	case win.WM_SIZE:
+		result := ce.TextEdit.WndProc(hwnd, msg, wParam, lParam)
+
+		ce.updateLineBrush()
+		ce.Invalidate()
+
+		return result
+
+	case win.WM_KEYDOWN, win.WM_KEYUP, win.WM_LBUTTONDOWN, win.WM_LBUTTONUP, win.WM_MOUSEMOVE,
+		win.WM_MOUSEWHEEL, win.WM_HSCROLL, win.WM_VSCROLL, win.EM_SETSEL, win.EM_SCROLLCARET:
+		result := ce.TextEdit.WndProc(hwnd, msg, wParam, lParam)
+
+		ce.updateCurrentLine()
+
+		return result
+	}
+
+	return ce.TextEdit.WndProc(hwnd, msg, wParam, lParam)
+}
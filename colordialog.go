@@ -0,0 +1,141 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unsafe"
+
+	"github.com/tailscale/win"
+)
+
+const colorDialogCustomColorsSettingsKey = "ColorDialogCustomColors"
+
+// sharedCustomColors holds the custom colors of ColorDialogs without
+// CustomColors of their own.
+var sharedCustomColors *[16]win.COLORREF
+
+// ColorDialog lets the user pick a color using the common color dialog.
+type ColorDialog struct {
+	// Color is the color initially selected, and the one picked after the
+	// dialog was accepted.
+	Color Color
+
+	// CustomColors holds the colors of the up to 16 custom color slots of the
+	// dialog, as edited by the user after it was accepted. If nil, the custom
+	// colors are shared by all ColorDialogs of the application and persisted
+	// in the Settings of the application, if there are any.
+	CustomColors []Color
+
+	// FullOpen makes the dialog display the controls for creating custom
+	// colors right away.
+	FullOpen bool
+
+	// PreventFullOpen disables creating custom colors.
+	PreventFullOpen bool
+}
+
+// Show displays the dialog modal to owner, which may be nil.
+func (dlg *ColorDialog) Show(owner Form) (accepted bool, err error) {
+	var custom *[16]win.COLORREF
+	if dlg.CustomColors != nil {
+		custom = new([16]win.COLORREF)
+		for i := 0; i < len(custom) && i < len(dlg.CustomColors); i++ {
+			custom[i] = win.COLORREF(dlg.CustomColors[i])
+		}
+	} else {
+		custom = loadSharedCustomColors()
+	}
+
+	var cc win.CHOOSECOLOR
+	cc.LStructSize = uint32(unsafe.Sizeof(cc))
+	if owner != nil {
+		cc.HwndOwner = owner.Handle()
+	}
+	cc.RgbResult = win.COLORREF(dlg.Color)
+	cc.LpCustColors = custom
+	cc.Flags = win.CC_RGBINIT
+	if dlg.FullOpen {
+		cc.Flags |= win.CC_FULLOPEN
+	}
+	if dlg.PreventFullOpen {
+		cc.Flags |= win.CC_PREVENTFULLOPEN
+	}
+
+	if !win.ChooseColor(&cc) {
+		if errno := win.CommDlgExtendedError(); errno != 0 {
+			err = newError(fmt.Sprintf("Error %d", errno))
+		}
+		return
+	}
+
+	dlg.Color = Color(cc.RgbResult)
+
+	if dlg.CustomColors != nil {
+		for i := 0; i < len(custom) && i < len(dlg.CustomColors); i++ {
+			dlg.CustomColors[i] = Color(custom[i])
+		}
+	} else {
+		saveSharedCustomColors()
+	}
+
+	return true, nil
+}
+
+// loadSharedCustomColors returns the shared custom colors, loading them from
+// the settings of the application first, if needed.
+func loadSharedCustomColors() *[16]win.COLORREF {
+	if sharedCustomColors != nil {
+		return sharedCustomColors
+	}
+
+	sharedCustomColors = new([16]win.COLORREF)
+
+	// Unused slots are white, like in the dialog itself.
+	for i := range sharedCustomColors {
+		sharedCustomColors[i] = win.COLORREF(RGB(0xFF, 0xFF, 0xFF))
+	}
+
+	settings := App().Settings()
+	if settings == nil {
+		return sharedCustomColors
+	}
+
+	state, ok := settings.Get(colorDialogCustomColorsSettingsKey)
+	if !ok {
+		return sharedCustomColors
+	}
+
+	for i, field := range strings.Fields(state) {
+		if i >= len(sharedCustomColors) {
+			break
+		}
+
+		if c, err := strconv.ParseUint(field, 16, 32); err == nil {
+			sharedCustomColors[i] = win.COLORREF(c)
+		}
+	}
+
+	return sharedCustomColors
+}
+
+func saveSharedCustomColors() {
+	settings := App().Settings()
+	if settings == nil || sharedCustomColors == nil {
+		return
+	}
+
+	fields := make([]string, len(sharedCustomColors))
+	for i, c := range sharedCustomColors {
+		fields[i] = fmt.Sprintf("%06x", uint32(c))
+	}
+
+	settings.Put(colorDialogCustomColorsSettingsKey, strings.Join(fields, " "))
+}
@@ -0,0 +1,328 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"fmt"
+
+	"github.com/tailscale/win"
+)
+
+const (
+	colorWellInset96dpi            = 6
+	colorWellCheckerSize96dpi      = 4
+	colorWellOpaque           byte = 0xFF
+)
+
+// ColorWell is a button displaying a color swatch, which opens a ColorDialog
+// to pick another color when clicked.
+//
+// If alpha is enabled, the swatch displays the opacity of the color over a
+// checkerboard, and clicking the button first offers a menu for choosing the
+// opacity.
+type ColorWell struct {
+	Button
+	color                 Color
+	alpha                 byte
+	alphaEnabled          bool
+	customColors          []Color
+	colorChangedPublisher EventPublisher
+}
+
+// NewColorWell returns a new black ColorWell as child of parent.
+func NewColorWell(parent Container) (*ColorWell, error) {
+	cw := &ColorWell{alpha: colorWellOpaque}
+
+	if err := InitWidget(
+		cw,
+		parent,
+		"BUTTON",
+		win.WS_TABSTOP|win.WS_VISIBLE|win.BS_PUSHBUTTON,
+		0); err != nil {
+		return nil, err
+	}
+
+	cw.Button.init()
+
+	cw.GraphicsEffects().Add(InteractionEffect)
+	cw.GraphicsEffects().Add(FocusEffect)
+
+	cw.Clicked().Attach(func() {
+		cw.showPicker()
+	})
+
+	cw.MustRegisterProperty("Color", NewProperty(
+		func() interface{} {
+			return cw.Color()
+		},
+		func(v interface{}) error {
+			c, _ := v.(Color)
+			cw.SetColor(c)
+			return nil
+		},
+		cw.colorChangedPublisher.Event()))
+
+	return cw, nil
+}
+
+// Color returns the color displayed by the ColorWell.
+func (cw *ColorWell) Color() Color {
+	return cw.color
+}
+
+// SetColor sets the color displayed by the ColorWell.
+func (cw *ColorWell) SetColor(color Color) {
+	cw.setColor(color, cw.alpha)
+}
+
+// Alpha returns the opacity of the color, from 0 for transparent to 255 for
+// opaque.
+func (cw *ColorWell) Alpha() byte {
+	return cw.alpha
+}
+
+// SetAlpha sets the opacity of the color, from 0 for transparent to 255 for
+// opaque.
+func (cw *ColorWell) SetAlpha(alpha byte) {
+	cw.setColor(cw.color, alpha)
+}
+
+// AlphaEnabled returns whether the user can choose the opacity of the color.
+func (cw *ColorWell) AlphaEnabled() bool {
+	return cw.alphaEnabled
+}
+
+// SetAlphaEnabled sets whether the user can choose the opacity of the color.
+// Disabling it makes the color opaque.
+func (cw *ColorWell) SetAlphaEnabled(enabled bool) {
+	cw.alphaEnabled = enabled
+
+	if !enabled {
+		cw.SetAlpha(colorWellOpaque)
+	}
+}
+
+// CustomColors returns the custom colors offered by the ColorDialog, or nil
+// if those shared by the application are offered.
+func (cw *ColorWell) CustomColors() []Color {
+	return cw.customColors
+}
+
+// SetCustomColors sets the custom colors offered by the ColorDialog. Passing
+// nil offers those shared by the application, which is the default.
+func (cw *ColorWell) SetCustomColors(colors []Color) {
+	cw.customColors = colors
+}
+
+// ColorChanged returns the event that is published when the color or its
+// opacity changes.
+func (cw *ColorWell) ColorChanged() *Event {
+	return cw.colorChangedPublisher.Event()
+}
+
+// ShowColorDialog opens a ColorDialog for picking another color.
+func (cw *ColorWell) ShowColorDialog() (accepted bool, err error) {
+	dlg := &ColorDialog{
+		Color:        cw.color,
+		CustomColors: cw.customColors,
+	}
+
+	if accepted, err = dlg.Show(cw.Form()); accepted {
+		cw.SetColor(dlg.Color)
+	}
+
+	return
+}
+
+func (cw *ColorWell) setColor(color Color, alpha byte) {
+	if color == cw.color && alpha == cw.alpha {
+		return
+	}
+
+	cw.color = color
+	cw.alpha = alpha
+
+	cw.Invalidate()
+
+	cw.colorChangedPublisher.Publish()
+}
+
+func (cw *ColorWell) showPicker() {
+	if !cw.alphaEnabled {
+		cw.ShowColorDialog()
+		return
+	}
+
+	menu, err := NewMenu()
+	if err != nil {
+		return
+	}
+	defer menu.Dispose()
+
+	chooseAction := NewAction()
+	chooseAction.SetText(tr("Choose Color…", "walk"))
+	chooseAction.Triggered().Attach(func() {
+		cw.ShowColorDialog()
+	})
+	menu.Actions().Add(chooseAction)
+	menu.Actions().Add(NewSeparatorAction())
+
+	for _, percent := range []int{100, 75, 50, 25, 0} {
+		alpha := byte(percent * int(colorWellOpaque) / 100)
+
+		action := NewAction()
+		action.SetText(fmt.Sprintf(tr("Opacity %d%%", "walk"), percent))
+		action.SetCheckable(true)
+		action.SetChecked(alpha == cw.alpha)
+		action.Triggered().Attach(func() {
+			cw.SetAlpha(alpha)
+		})
+		menu.Actions().Add(action)
+	}
+
+	var rc win.RECT
+	win.GetWindowRect(cw.hWnd, &rc)
+
+	actionId := uint16(win.TrackPopupMenuEx(
+		menu.hMenu,
+		win.TPM_NOANIMATION|win.TPM_RETURNCMD,
+		rc.Left,
+		rc.Bottom,
+		cw.hWnd,
+		nil))
+
+	if actionId != 0 {
+		if action, ok := actionsById[actionId]; ok {
+			action.raiseTriggered()
+		}
+	}
+}
+
+// swatchBounds returns the bounds of the color swatch in native pixels.
+func (cw *ColorWell) swatchBounds() Rectangle {
+	cb := cw.ClientBoundsPixels()
+	inset := cw.IntFrom96DPI(colorWellInset96dpi)
+
+	return Rectangle{cb.X + inset, cb.Y + inset, cb.Width - 2*inset, cb.Height - 2*inset}
+}
+
+// blendColor returns fg with opacity alpha over bg.
+func blendColor(fg, bg Color, alpha byte) Color {
+	blend := func(f, b byte) byte {
+		return byte((int(f)*int(alpha) + int(b)*(255-int(alpha)) + 127) / 255)
+	}
+
+	return RGB(blend(fg.R(), bg.R()), blend(fg.G(), bg.G()), blend(fg.B(), bg.B()))
+}
+
+func (cw *ColorWell) paintSwatch() error {
+	b := cw.swatchBounds()
+	if b.Width <= 0 || b.Height <= 0 {
+		return nil
+	}
+
+	hdc := win.GetDC(cw.hWnd)
+	defer win.ReleaseDC(cw.hWnd, hdc)
+
+	canvas, err := newCanvasFromHDC(hdc)
+	if err != nil {
+		return err
+	}
+	defer canvas.Dispose()
+
+	color := cw.color
+	if !cw.Enabled() {
+		color = blendColor(color, Color(win.GetSysColor(win.COLOR_BTNFACE)), 0x60)
+	}
+
+	fill := func(c Color, bounds Rectangle) error {
+		brush, err := NewSolidColorBrush(c)
+		if err != nil {
+			return err
+		}
+		defer brush.Dispose()
+
+		return canvas.FillRectanglePixels(brush, bounds)
+	}
+
+	if cw.alpha == colorWellOpaque {
+		if err := fill(color, b); err != nil {
+			return err
+		}
+	} else {
+		// A checkerboard makes the opacity visible.
+		light := blendColor(color, RGB(0xFF, 0xFF, 0xFF), cw.alpha)
+		dark := blendColor(color, RGB(0xC0, 0xC0, 0xC0), cw.alpha)
+
+		size := cw.IntFrom96DPI(colorWellCheckerSize96dpi)
+		for y := 0; y < b.Height; y += size {
+			for x := 0; x < b.Width; x += size {
+				c := light
+				if (x/size+y/size)%2 == 1 {
+					c = dark
+				}
+
+				square := Rectangle{b.X + x, b.Y + y, mini(size, b.Width-x), mini(size, b.Height-y)}
+				if err := fill(c, square); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	borderBrush, err := NewSystemColorBrush(SysColorBtnShadow)
+	if err != nil {
+		return err
+	}
+	defer borderBrush.Dispose()
+
+	pen, err := NewGeometricPen(PenSolid|PenInsideFrame, 1, borderBrush)
+	if err != nil {
+		return err
+	}
+	defer pen.Dispose()
+
+	return canvas.DrawRectanglePixels(pen, b)
+}
+
+func (cw *ColorWell) WndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) uintptr {
+	switch msg {
+	case win.WM_PAINT:
+		// The swatch is drawn over the button.
+		result := cw.Button.WndProc(hwnd, msg, wParam, lParam)
+
+		cw.paintSwatch()
+
+		return result
+	}
+
+	return cw.Button.WndProc(hwnd, msg, wParam, lParam)
+}
+
+func (cw *ColorWell) CreateLayoutItem(ctx *LayoutContext) LayoutItem {
+	return &colorWellLayoutItem{
+		idealSize: cw.dialogBaseUnitsToPixels(Size{30, 14}),
+	}
+}
+
+type colorWellLayoutItem struct {
+	LayoutItemBase
+	idealSize Size // in native pixels
+}
+
+func (*colorWellLayoutItem) LayoutFlags() LayoutFlags {
+	return 0
+}
+
+func (li *colorWellLayoutItem) IdealSize() Size {
+	return li.idealSize
+}
+
+func (li *colorWellLayoutItem) MinSize() Size {
+	return li.idealSize
+}
@@ -42,6 +42,7 @@ type ComboBox struct {
 	editOrigWndProcPtr           uintptr
 	editing                      bool
 	persistent                   bool
+	autocomplete                 *Autocomplete
 }
 
 var comboBoxEditWndProcPtr uintptr
@@ -119,15 +120,28 @@ func NewDropDownBox(parent Container) (*ComboBox, error) {
 }
 
 func newComboBoxWithStyle(parent Container, style uint32) (*ComboBox, error) {
-	cb := &ComboBox{prevCurIndex: -1, selChangeIndex: -1, precision: 2}
+	cb := new(ComboBox)
+
+	if err := cb.init(cb, parent, style); err != nil {
+		return nil, err
+	}
+
+	return cb, nil
+}
+
+// init creates the window of cb, which is embedded in widget.
+func (cb *ComboBox) init(widget Widget, parent Container, style uint32) error {
+	cb.prevCurIndex = -1
+	cb.selChangeIndex = -1
+	cb.precision = 2
 
 	if err := InitWidget(
-		cb,
+		widget,
 		parent,
 		"COMBOBOX",
 		win.WS_TABSTOP|win.WS_VISIBLE|win.WS_VSCROLL|style,
 		0); err != nil {
-		return nil, err
+		return err
 	}
 
 	succeeded := false
@@ -232,7 +246,7 @@ func newComboBoxWithStyle(parent Container, style uint32) (*ComboBox, error) {
 
 	succeeded = true
 
-	return cb, nil
+	return nil
 }
 
 func (cb *ComboBox) applyFont(font *Font) {
@@ -615,6 +629,22 @@ func (cb *ComboBox) SetTextSelection(start, end int) {
 	cb.SendMessage(win.CB_SETEDITSEL, 0, uintptr(win.MAKELONG(uint16(start), uint16(end))))
 }
 
+// Autocomplete returns the Autocomplete of the edit field, if any.
+func (cb *ComboBox) Autocomplete() *Autocomplete {
+	return cb.autocomplete
+}
+
+// SetAutocomplete makes the edit field of an editable ComboBox offer the
+// suggestions of source as specified by options. A nil source disables the
+// suggestions.
+func (cb *ComboBox) SetAutocomplete(source AutocompleteSource, options AutocompleteOptions) error {
+	if !cb.Editable() {
+		return newError("ComboBox is not editable")
+	}
+
+	return setAutocomplete(&cb.autocomplete, win.GetWindow(cb.hWnd, win.GW_CHILD), source, options)
+}
+
 func (cb *ComboBox) TextChanged() *Event {
 	return cb.textChangedPublisher.Event()
 }
@@ -663,6 +693,9 @@ func (cb *ComboBox) WndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) u
 		case win.CBN_EDITCHANGE:
 			cb.editing = true
 			cb.selChangeIndex = -1
+			if cb.autocomplete != nil {
+				cb.autocomplete.textChanged()
+			}
 			cb.textChangedPublisher.Publish()
 
 		case win.CBN_SELCHANGE:
@@ -699,6 +732,11 @@ func (cb *ComboBox) WndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) u
 			return 0
 		}
 
+	case win.WM_DESTROY:
+		if cb.autocomplete != nil {
+			cb.autocomplete.release()
+		}
+
 	case win.WM_WINDOWPOSCHANGED:
 		wp := (*win.WINDOWPOS)(unsafe.Pointer(lParam))
 
@@ -743,6 +781,8 @@ func (cb *ComboBox) CreateLayoutItem(ctx *LayoutContext) LayoutItem {
 	return &comboBoxLayoutItem{
 		layoutFlags: layoutFlags,
 		idealSize:   Size{w, h},
+		textHeight:  cb.dialogBaseUnits().Height,
+		textAscent:  cb.fontAscent(),
 	}
 }
 
@@ -750,6 +790,14 @@ type comboBoxLayoutItem struct {
 	LayoutItemBase
 	layoutFlags LayoutFlags
 	idealSize   Size // in native pixels
+	textHeight  int  // in native pixels
+	textAscent  int  // in native pixels
+}
+
+// Baseline returns the baseline of the text in the selection field, which
+// is vertically centered.
+func (li *comboBoxLayoutItem) Baseline(size Size) int {
+	return (size.Height-li.textHeight)/2 + li.textAscent
 }
 
 func (li *comboBoxLayoutItem) LayoutFlags() LayoutFlags {
@@ -0,0 +1,191 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"sync"
+	"syscall"
+	"unsafe"
+
+	"github.com/tailscale/win"
+)
+
+// CommandLinkButton is a push button displaying a title, a supplementary note
+// and an arrow or shield glyph, as used for wizard-style choices.
+type CommandLinkButton struct {
+	Button
+	noteChangedPublisher EventPublisher
+	shield               bool
+}
+
+// NewCommandLinkButton creates a new CommandLinkButton as child of parent.
+func NewCommandLinkButton(parent Container) (*CommandLinkButton, error) {
+	clb := new(CommandLinkButton)
+
+	if err := InitWidget(
+		clb,
+		parent,
+		"BUTTON",
+		win.WS_TABSTOP|win.WS_VISIBLE|_BS_COMMANDLINK,
+		0); err != nil {
+		return nil, err
+	}
+
+	clb.Button.init()
+
+	clb.GraphicsEffects().Add(InteractionEffect)
+	clb.GraphicsEffects().Add(FocusEffect)
+
+	clb.MustRegisterProperty("Note", NewProperty(
+		func() interface{} {
+			return clb.Note()
+		},
+		func(v interface{}) error {
+			return clb.SetNote(assertStringOr(v, ""))
+		},
+		clb.noteChangedPublisher.Event()))
+
+	return clb, nil
+}
+
+// Note returns the supplementary text displayed below the title.
+func (clb *CommandLinkButton) Note() string {
+	length := clb.SendMessage(win.BCM_GETNOTELENGTH, 0, 0)
+	if length == 0 {
+		return ""
+	}
+
+	buf := make([]uint16, length+1)
+	size := uint32(len(buf))
+	clb.SendMessage(win.BCM_GETNOTE, uintptr(unsafe.Pointer(&size)), uintptr(unsafe.Pointer(&buf[0])))
+
+	return syscall.UTF16ToString(buf)
+}
+
+// SetNote sets the supplementary text displayed below the title.
+func (clb *CommandLinkButton) SetNote(note string) error {
+	if note == clb.Note() {
+		return nil
+	}
+
+	note16, err := syscall.UTF16PtrFromString(note)
+	if err != nil {
+		return err
+	}
+
+	if win.FALSE == clb.SendMessage(win.BCM_SETNOTE, 0, uintptr(unsafe.Pointer(note16))) {
+		return newError("BCM_SETNOTE failed")
+	}
+
+	clb.RequestLayout()
+
+	clb.noteChangedPublisher.Publish()
+
+	return nil
+}
+
+// NoteChanged returns an Event that is published when the note changes.
+func (clb *CommandLinkButton) NoteChanged() *Event {
+	return clb.noteChangedPublisher.Event()
+}
+
+// Shield returns whether the button displays the UAC shield glyph instead of
+// the arrow.
+func (clb *CommandLinkButton) Shield() bool {
+	return clb.shield
+}
+
+// SetShield sets whether the button displays the UAC shield glyph instead of
+// the arrow, indicating that the action requires elevation.
+func (clb *CommandLinkButton) SetShield(shield bool) {
+	clb.SendMessage(win.BCM_SETSHIELD, 0, uintptr(win.BoolToBOOL(shield)))
+
+	clb.shield = shield
+}
+
+func (clb *CommandLinkButton) CreateLayoutItem(ctx *LayoutContext) LayoutItem {
+	var s win.SIZE
+	clb.SendMessage(win.BCM_GETIDEALSIZE, 0, uintptr(unsafe.Pointer(&s)))
+
+	return &commandLinkButtonLayoutItem{
+		buttonLayoutItem: buttonLayoutItem{
+			idealSize: sizeFromSIZE(s),
+		},
+		width2Height: make(map[int]int),
+		title:        clb.Text(),
+		note:         clb.Note(),
+		font:         clb.Font(),
+		minWidth:     clb.dialogBaseUnitsToPixels(Size{100, 0}).Width,
+	}
+}
+
+type commandLinkButtonLayoutItem struct {
+	buttonLayoutItem
+	mutex        sync.Mutex
+	width2Height map[int]int // in native pixels
+	title        string
+	note         string
+	font         *Font
+	minWidth     int // in native pixels
+}
+
+func (*commandLinkButtonLayoutItem) LayoutFlags() LayoutFlags {
+	return GrowableHorz
+}
+
+func (li *commandLinkButtonLayoutItem) IdealSize() Size {
+	return maxSize(li.idealSize, Size{li.minWidth, 0})
+}
+
+func (li *commandLinkButtonLayoutItem) MinSize() Size {
+	return Size{li.minWidth, li.HeightForWidth(li.minWidth)}
+}
+
+func (li *commandLinkButtonLayoutItem) HasHeightForWidth() bool {
+	return true
+}
+
+// HeightForWidth estimates the height needed to fit title and note wrapped
+// to width. The control can only be queried on the UI thread, so the space
+// taken by glyph and padding is derived from the unconstrained ideal size.
+func (li *commandLinkButtonLayoutItem) HeightForWidth(width int) int {
+	if width >= li.idealSize.Width {
+		return li.idealSize.Height
+	}
+
+	li.mutex.Lock()
+	defer li.mutex.Unlock()
+
+	if height, ok := li.width2Height[width]; ok {
+		return height
+	}
+
+	dpi := li.ctx.dpi
+
+	titleSize := calculateTextSize(li.title, li.font, dpi, 0, li.handle)
+	var noteSize Size
+	if li.note != "" {
+		noteSize = calculateTextSize(li.note, li.font, dpi, 0, li.handle)
+	}
+
+	chrome := Size{
+		li.idealSize.Width - maxi(titleSize.Width, noteSize.Width),
+		li.idealSize.Height - titleSize.Height - noteSize.Height,
+	}
+
+	textWidth := maxi(width-chrome.Width, 1)
+
+	height := chrome.Height + calculateTextSize(li.title, li.font, dpi, textWidth, li.handle).Height
+	if li.note != "" {
+		height += calculateTextSize(li.note, li.font, dpi, textWidth, li.handle).Height
+	}
+
+	li.width2Height[width] = height
+
+	return height
+}
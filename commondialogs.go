@@ -9,6 +9,8 @@ package walk
 import (
 	"fmt"
 	"path/filepath"
+	"runtime"
+	"strings"
 	"syscall"
 	"unsafe"
 )
@@ -26,6 +28,36 @@ type FileDialog struct {
 	FilterIndex    int
 	Flags          uint32
 	ShowReadOnlyCB bool
+
+	// The following fields are only supported by the common item dialog,
+	// which is displayed instead of the legacy one if any of them is set.
+	// It ignores Flags and ShowReadOnlyCB.
+
+	// DefaultDirPath is the folder the dialog starts in if the user has not
+	// picked one before. InitialDirPath takes precedence over it.
+	DefaultDirPath string
+
+	// PinnedDirPaths are folders the dialog offers in its navigation pane,
+	// in addition to the default ones.
+	PinnedDirPaths []string
+
+	// DefaultExtensions holds the extension a save dialog appends to file
+	// names lacking one, for each filter in Filter. If there is none for a
+	// filter, the first extension of its pattern is appended.
+	DefaultExtensions []string
+
+	// Controls are displayed by the dialog in addition to its own. They
+	// reflect the choices of the user after the dialog was accepted.
+	Controls []FileDialogControl
+
+	// OnOverwrite, if not nil, is called instead of prompting the user before
+	// a save dialog overwrites the existing file at path. The file is only
+	// overwritten if OnOverwrite returns true.
+	OnOverwrite func(path string) bool
+
+	// OverwritePrompt makes a save dialog ask the user before overwriting an
+	// existing file.
+	OverwritePrompt bool
 }
 
 func (dlg *FileDialog) show(owner Form, fun func(ofn *win.OPENFILENAME) bool, flags uint32) (accepted bool, err error) {
@@ -55,6 +87,10 @@ func (dlg *FileDialog) show(owner Form, fun func(ofn *win.OPENFILENAME) bool, fl
 		ofn.Flags |= win.OFN_HIDEREADONLY
 	}
 
+	if dlg.OverwritePrompt {
+		ofn.Flags |= win.OFN_OVERWRITEPROMPT
+	}
+
 	var fileBuf []uint16
 	if flags&win.OFN_ALLOWMULTISELECT > 0 {
 		fileBuf = make([]uint16, 65536)
@@ -116,14 +152,26 @@ func (dlg *FileDialog) show(owner Form, fun func(ofn *win.OPENFILENAME) bool, fl
 }
 
 func (dlg *FileDialog) ShowOpen(owner Form) (accepted bool, err error) {
+	if dlg.usesItemDialog() {
+		return dlg.showItemDialog(owner, false, _FOS_FILEMUSTEXIST)
+	}
+
 	return dlg.show(owner, win.GetOpenFileName, win.OFN_NOCHANGEDIR)
 }
 
 func (dlg *FileDialog) ShowOpenMultiple(owner Form) (accepted bool, err error) {
+	if dlg.usesItemDialog() {
+		return dlg.showItemDialog(owner, false, _FOS_FILEMUSTEXIST|_FOS_ALLOWMULTISELECT)
+	}
+
 	return dlg.show(owner, win.GetOpenFileName, win.OFN_ALLOWMULTISELECT|win.OFN_EXPLORER|win.OFN_NOCHANGEDIR)
 }
 
 func (dlg *FileDialog) ShowSave(owner Form) (accepted bool, err error) {
+	if dlg.usesItemDialog() {
+		return dlg.showItemDialog(owner, true, 0)
+	}
+
 	return dlg.show(owner, win.GetSaveFileName, win.OFN_NOCHANGEDIR)
 }
 
@@ -162,7 +210,19 @@ func init() {
 	})
 }
 
+// ShowBrowseFolder lets the user pick a folder, whose path is stored in
+// FilePath.
+//
+// The dialog offers an editable path box, and the places in PinnedDirPaths.
+// On systems lacking the common item dialog, the folder is picked from a tree
+// rooted at InitialDirPath instead.
 func (dlg *FileDialog) ShowBrowseFolder(owner Form) (accepted bool, err error) {
+	if fd, err := newIFileDialog(&clsid_FileOpenDialog, &iid_IFileOpenDialog); err == nil {
+		defer fd.Release()
+
+		return dlg.showIFileDialog(owner, fd, false, _FOS_PICKFOLDERS)
+	}
+
 	var ownerHwnd win.HWND
 	if owner != nil {
 		ownerHwnd = owner.Handle()
@@ -194,3 +254,253 @@ func (dlg *FileDialog) ShowBrowseFolder(owner Form) (accepted bool, err error) {
 	accepted = dlg.FilePath != ""
 	return
 }
+
+// ShowBrowseFolders lets the user pick one or more folders, whose paths are
+// stored in FilePaths. FilePath is set to the first one.
+func (dlg *FileDialog) ShowBrowseFolders(owner Form) (accepted bool, err error) {
+	return dlg.showItemDialog(owner, false, _FOS_PICKFOLDERS|_FOS_ALLOWMULTISELECT)
+}
+
+// usesItemDialog returns whether the FileDialog uses features only the common
+// item dialog supports.
+func (dlg *FileDialog) usesItemDialog() bool {
+	return dlg.DefaultDirPath != "" ||
+		len(dlg.PinnedDirPaths) > 0 ||
+		len(dlg.DefaultExtensions) > 0 ||
+		len(dlg.Controls) > 0 ||
+		dlg.OnOverwrite != nil
+}
+
+// filterSpecs returns the names and patterns of the filters in Filter, which
+// separates them using '|'.
+func (dlg *FileDialog) filterSpecs() (names, patterns []string) {
+	parts := strings.Split(dlg.Filter, "|")
+	for i := 0; i+1 < len(parts); i += 2 {
+		names = append(names, parts[i])
+		patterns = append(patterns, parts[i+1])
+	}
+
+	return
+}
+
+// defaultExtension returns the extension to append to file names lacking one
+// for the filter at index, which starts at 1.
+func (dlg *FileDialog) defaultExtension(index int) string {
+	if index >= 1 && index <= len(dlg.DefaultExtensions) && dlg.DefaultExtensions[index-1] != "" {
+		return strings.TrimPrefix(dlg.DefaultExtensions[index-1], ".")
+	}
+
+	_, patterns := dlg.filterSpecs()
+	if index < 1 || index > len(patterns) {
+		return ""
+	}
+
+	for _, pattern := range strings.Split(patterns[index-1], ";") {
+		ext := strings.TrimPrefix(filepath.Ext(strings.TrimSpace(pattern)), ".")
+		if ext != "" && !strings.ContainsAny(ext, "*?") {
+			return ext
+		}
+	}
+
+	return ""
+}
+
+// applyDefaultExtension makes fd append the default extension of the filter
+// at index, which starts at 1.
+func (dlg *FileDialog) applyDefaultExtension(fd *iFileDialog, index int) {
+	fd.SetDefaultExtension(syscall.StringToUTF16Ptr(dlg.defaultExtension(index)))
+}
+
+// showItemDialog displays the common item dialog for saving if save is true,
+// or for opening otherwise.
+func (dlg *FileDialog) showItemDialog(owner Form, save bool, options uint32) (accepted bool, err error) {
+	clsid, iid := &clsid_FileOpenDialog, &iid_IFileOpenDialog
+	if save {
+		clsid, iid = &clsid_FileSaveDialog, &iid_IFileSaveDialog
+	}
+
+	fd, err := newIFileDialog(clsid, iid)
+	if err != nil {
+		return false, err
+	}
+	defer fd.Release()
+
+	return dlg.showIFileDialog(owner, fd, save, options)
+}
+
+func (dlg *FileDialog) showIFileDialog(owner Form, fd *iFileDialog, save bool, options uint32) (accepted bool, err error) {
+	var fos uint32
+	if hr := fd.GetOptions(&fos); win.FAILED(hr) {
+		return false, errorFromHRESULT("IFileDialog.GetOptions", hr)
+	}
+
+	fos |= options | _FOS_FORCEFILESYSTEM | _FOS_PATHMUSTEXIST | _FOS_NOCHANGEDIR
+
+	// Like the legacy dialog, save dialogs only prompt if asked to.
+	if save && (dlg.OverwritePrompt || dlg.OnOverwrite != nil) {
+		fos |= _FOS_OVERWRITEPROMPT
+	} else {
+		fos &^= _FOS_OVERWRITEPROMPT
+	}
+
+	if hr := fd.SetOptions(fos); win.FAILED(hr) {
+		return false, errorFromHRESULT("IFileDialog.SetOptions", hr)
+	}
+
+	if dlg.Title != "" {
+		if hr := fd.SetTitle(syscall.StringToUTF16Ptr(dlg.Title)); win.FAILED(hr) {
+			return false, errorFromHRESULT("IFileDialog.SetTitle", hr)
+		}
+	}
+
+	// Folders that do not exist (anymore) are skipped.
+	withItem := func(path string, f func(item *iShellItem) win.HRESULT) {
+		if path == "" {
+			return
+		}
+
+		item, err := newIShellItemFromPath(path)
+		if err != nil {
+			return
+		}
+		defer item.Release()
+
+		f(item)
+	}
+
+	pickFolders := options&_FOS_PICKFOLDERS != 0
+
+	var specs []comdlgFilterSpec
+	if !pickFolders {
+		names, patterns := dlg.filterSpecs()
+		for i := range names {
+			specs = append(specs, comdlgFilterSpec{
+				syscall.StringToUTF16Ptr(names[i]),
+				syscall.StringToUTF16Ptr(patterns[i]),
+			})
+		}
+
+		if len(specs) > 0 {
+			if hr := fd.SetFileTypes(specs); win.FAILED(hr) {
+				return false, errorFromHRESULT("IFileDialog.SetFileTypes", hr)
+			}
+
+			index := dlg.FilterIndex
+			if index < 1 {
+				index = 1
+			}
+			fd.SetFileTypeIndex(uint32(index))
+
+			if save {
+				dlg.applyDefaultExtension(fd, index)
+			}
+		}
+
+		if dlg.FilePath != "" {
+			fd.SetFileName(syscall.StringToUTF16Ptr(filepath.Base(dlg.FilePath)))
+
+			if dir := filepath.Dir(dlg.FilePath); dir != "." && dlg.InitialDirPath == "" {
+				withItem(dir, fd.SetFolder)
+			}
+		}
+	}
+
+	withItem(dlg.DefaultDirPath, fd.SetDefaultFolder)
+	withItem(dlg.InitialDirPath, fd.SetFolder)
+
+	for _, path := range dlg.PinnedDirPaths {
+		withItem(path, func(item *iShellItem) win.HRESULT {
+			return fd.AddPlace(item, _FDAP_BOTTOM)
+		})
+	}
+
+	var fdc *iFileDialogCustomize
+	if len(dlg.Controls) > 0 {
+		p, hr := queryInterface((*win.IUnknown)(unsafe.Pointer(fd)), &iid_IFileDialogCustomize)
+		if win.FAILED(hr) {
+			return false, errorFromHRESULT("QueryInterface(IID_IFileDialogCustomize)", hr)
+		}
+		fdc = (*iFileDialogCustomize)(p)
+		defer fdc.Release()
+
+		for i, control := range dlg.Controls {
+			if err := control.addTo(fdc, fileDialogControlID(i)); err != nil {
+				return false, err
+			}
+		}
+	}
+
+	// The events update the default extension when the filter changes and
+	// call OnOverwrite.
+	var events *fileDialogIFileDialogEvents
+	if save {
+		events = newFileDialogIFileDialogEvents(dlg)
+
+		var cookie uint32
+		if hr := fd.Advise(unsafe.Pointer(events), &cookie); win.FAILED(hr) {
+			return false, errorFromHRESULT("IFileDialog.Advise", hr)
+		}
+		defer fd.Unadvise(cookie)
+	}
+
+	var ownerHwnd win.HWND
+	if owner != nil {
+		ownerHwnd = owner.Handle()
+	}
+
+	hr := fd.Show(ownerHwnd)
+
+	// The dialog only holds uintptrs to these.
+	runtime.KeepAlive(specs)
+	runtime.KeepAlive(events)
+
+	if hr == _HRESULT_ERROR_CANCELLED {
+		return false, nil
+	} else if win.FAILED(hr) {
+		return false, errorFromHRESULT("IFileDialog.Show", hr)
+	}
+
+	if fos&_FOS_ALLOWMULTISELECT != 0 {
+		var items *iShellItemArray
+		if hr := fd.GetResults(&items); win.FAILED(hr) {
+			return false, errorFromHRESULT("IFileOpenDialog.GetResults", hr)
+		}
+		defer items.Release()
+
+		paths, err := items.paths()
+		if err != nil {
+			return false, err
+		}
+		if len(paths) == 0 {
+			return false, nil
+		}
+
+		dlg.FilePaths = paths
+		dlg.FilePath = paths[0]
+	} else {
+		var item *iShellItem
+		if hr := fd.GetResult(&item); win.FAILED(hr) {
+			return false, errorFromHRESULT("IFileDialog.GetResult", hr)
+		}
+		defer item.Release()
+
+		if dlg.FilePath, err = item.path(); err != nil {
+			return false, err
+		}
+	}
+
+	if len(specs) > 0 {
+		var index uint32
+		if hr := fd.GetFileTypeIndex(&index); !win.FAILED(hr) {
+			dlg.FilterIndex = int(index)
+		}
+	}
+
+	for i, control := range dlg.Controls {
+		if err := control.readFrom(fdc, fileDialogControlID(i)); err != nil {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
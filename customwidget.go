@@ -37,6 +37,7 @@ type CustomWidget struct {
 	paintPixels         PaintFunc // in native pixels
 	invalidatesOnResize bool
 	paintMode           PaintMode
+	automation          *automationTree // once AutomationRoot was called
 }
 
 // NewCustomWidget creates and initializes a new custom draw widget.
@@ -177,6 +178,23 @@ func (cw *CustomWidget) WndProc(hwnd win.HWND, msg uint32, wParam, lParam uintpt
 		if cw.invalidatesOnResize {
 			cw.Invalidate()
 		}
+
+	case win.WM_GETOBJECT:
+		if cw.automation != nil {
+			if ret, ok := cw.automation.getObject(wParam, lParam); ok {
+				return ret
+			}
+		}
+
+	case win.WM_SETFOCUS:
+		if cw.automation != nil {
+			cw.automation.focusChanged()
+		}
+
+	case win.WM_DESTROY:
+		if cw.automation != nil {
+			cw.automation.destroy()
+		}
 	}
 
 	return cw.WidgetBase.WndProc(hwnd, msg, wParam, lParam)
@@ -601,6 +601,12 @@ func (f *reflectField) Set(value interface{}) error {
 		return nil
 	}
 
+	if cs, ok := value.(CheckState); ok {
+		if v, ok := checkStateToValue(cs, f.value.Type()); ok {
+			value = v
+		}
+	}
+
 	f.value.Set(reflect.ValueOf(value))
 
 	return nil
@@ -0,0 +1,96 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package declarative
+
+import (
+	"errors"
+
+	"github.com/tailscale/walk"
+)
+
+type Anchors uint8
+
+const (
+	AnchorNone   = Anchors(walk.AnchorNone)
+	AnchorLeft   = Anchors(walk.AnchorLeft)
+	AnchorTop    = Anchors(walk.AnchorTop)
+	AnchorRight  = Anchors(walk.AnchorRight)
+	AnchorBottom = Anchors(walk.AnchorBottom)
+	AnchorAll    = Anchors(walk.AnchorAll)
+)
+
+type Anchor struct {
+	DesignSize Size
+	Margins    Margins
+}
+
+func (a Anchor) Create() (walk.Layout, error) {
+	l := walk.NewAnchorLayout()
+
+	if err := l.SetMargins(a.Margins.toW()); err != nil {
+		return nil, err
+	}
+
+	if err := l.SetDesignSize(a.DesignSize.toW()); err != nil {
+		return nil, err
+	}
+
+	return l, nil
+}
+
+// Anchored places Widget inside a container using an Anchor layout.
+type Anchored struct {
+	Anchors      Anchors
+	Bounds       Rectangle
+	FixedMargins bool
+	Margins      Margins
+	NoAnchors    bool
+	Widget       Widget
+}
+
+func (a Anchored) Create(builder *Builder) error {
+	parent := builder.Parent()
+
+	l, ok := parent.Layout().(*walk.AnchorLayout)
+	if !ok {
+		return errors.New("Anchored requires a parent with an Anchor layout")
+	}
+
+	index := parent.Children().Len()
+
+	if err := a.Widget.Create(builder); err != nil {
+		return err
+	}
+
+	if parent.Children().Len() <= index {
+		return errors.New("Anchored did not create a widget")
+	}
+
+	w := parent.Children().At(index)
+
+	anchors := walk.Anchors(a.Anchors)
+	if anchors == walk.AnchorNone && !a.NoAnchors {
+		anchors = walk.AnchorLeft | walk.AnchorTop
+	}
+
+	if err := l.SetAnchors(w, anchors); err != nil {
+		return err
+	}
+
+	if err := l.SetItemBounds(w, a.Bounds.toW()); err != nil {
+		return err
+	}
+
+	if a.FixedMargins {
+		if err := l.SetItemMargins(w, a.Margins.toW()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
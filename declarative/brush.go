@@ -35,6 +35,25 @@ func (scb SystemColorBrush) Create() (walk.Brush, error) {
 	return walk.NewSystemColorBrush(scb.Color)
 }
 
+type HatchBrush struct {
+	Color walk.Color
+	Style walk.HatchStyle
+}
+
+func (hb HatchBrush) Create() (walk.Brush, error) {
+	return walk.NewHatchBrush(hb.Color, hb.Style)
+}
+
+type PatternBrush struct {
+	Pattern    [8]byte
+	Foreground walk.Color
+	Background walk.Color
+}
+
+func (pb PatternBrush) Create() (walk.Brush, error) {
+	return walk.NewPatternBrush(pb.Pattern, pb.Foreground, pb.Background)
+}
+
 type BitmapBrush struct {
 	Image interface{}
 }
@@ -0,0 +1,117 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package declarative
+
+import (
+	"time"
+
+	"github.com/tailscale/walk"
+)
+
+type Calendar struct {
+	// Window
+
+	Accessibility      Accessibility
+	Background         Brush
+	ContextMenuItems   []MenuItem
+	DoubleBuffering    bool
+	Enabled            Property
+	Font               Font
+	MaxSize            Size
+	MinSize            Size
+	Name               string
+	OnBoundsChanged    walk.EventHandler
+	OnKeyDown          walk.KeyEventHandler
+	OnKeyPress         walk.KeyEventHandler
+	OnKeyUp            walk.KeyEventHandler
+	OnMouseDown        walk.MouseEventHandler
+	OnMouseMove        walk.MouseEventHandler
+	OnMouseUp          walk.MouseEventHandler
+	OnSizeChanged      walk.EventHandler
+	Persistent         bool
+	RightToLeftReading bool
+	ToolTipText        Property
+	Visible            Property
+
+	// Widget
+
+	Alignment          Alignment2D
+	AlwaysConsumeSpace bool
+	Column             int
+	ColumnSpan         int
+	GraphicsEffects    []walk.WidgetGraphicsEffect
+	Row                int
+	RowSpan            int
+	StretchFactor      int
+
+	// Calendar
+
+	AssignTo              **walk.Calendar
+	BoldDates             []time.Time
+	Date                  Property
+	HideTodayLink         bool
+	MaxDate               time.Time
+	MaxSelectionCount     int
+	MinDate               time.Time
+	MultiSelect           bool
+	OnSelectionChanged    walk.EventHandler
+	OnVisibleRangeChanged walk.EventHandler
+	WeekNumbers           bool
+}
+
+func (c Calendar) Create(builder *Builder) error {
+	var w *walk.Calendar
+	var err error
+
+	if c.MultiSelect {
+		w, err = walk.NewCalendarWithMultiSelect(builder.Parent())
+	} else {
+		w, err = walk.NewCalendar(builder.Parent())
+	}
+	if err != nil {
+		return err
+	}
+
+	if c.AssignTo != nil {
+		*c.AssignTo = w
+	}
+
+	return builder.InitWidget(c, w, func() error {
+		if err := w.SetRange(c.MinDate, c.MaxDate); err != nil {
+			return err
+		}
+
+		if c.MaxSelectionCount > 0 {
+			if err := w.SetMaxSelectionCount(c.MaxSelectionCount); err != nil {
+				return err
+			}
+		}
+
+		if err := w.SetWeekNumbers(c.WeekNumbers); err != nil {
+			return err
+		}
+
+		if err := w.SetTodayLinkVisible(!c.HideTodayLink); err != nil {
+			return err
+		}
+
+		if len(c.BoldDates) > 0 {
+			w.SetBoldDates(c.BoldDates)
+		}
+
+		if c.OnSelectionChanged != nil {
+			w.SelectionChanged().Attach(c.OnSelectionChanged)
+		}
+
+		if c.OnVisibleRangeChanged != nil {
+			w.VisibleRangeChanged().Attach(c.OnVisibleRangeChanged)
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,106 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package declarative
+
+import (
+	"github.com/tailscale/walk"
+)
+
+type Card struct {
+	// Window
+
+	Accessibility      Accessibility
+	Background         Brush
+	ContextMenuItems   []MenuItem
+	DoubleBuffering    bool
+	Enabled            Property
+	Font               Font
+	MaxSize            Size
+	MinSize            Size
+	Name               string
+	OnBoundsChanged    walk.EventHandler
+	OnKeyDown          walk.KeyEventHandler
+	OnKeyPress         walk.KeyEventHandler
+	OnKeyUp            walk.KeyEventHandler
+	OnMouseDown        walk.MouseEventHandler
+	OnMouseMove        walk.MouseEventHandler
+	OnMouseUp          walk.MouseEventHandler
+	OnSizeChanged      walk.EventHandler
+	Persistent         bool
+	RightToLeftReading bool
+	ToolTipText        Property
+	Visible            Property
+
+	// Widget
+
+	Alignment          Alignment2D
+	AlwaysConsumeSpace bool
+	Column             int
+	ColumnSpan         int
+	GraphicsEffects    []walk.WidgetGraphicsEffect
+	Row                int
+	RowSpan            int
+	StretchFactor      int
+
+	// Container
+
+	Children   []Widget
+	DataBinder DataBinder
+
+	// Card
+
+	AssignTo     **walk.Card
+	CornerRadius int
+	Elevation    int
+	Flat         bool
+	Padding      Margins
+	Title        Property
+}
+
+func (c Card) Create(builder *Builder) error {
+	w, err := walk.NewCard(builder.Parent())
+	if err != nil {
+		return err
+	}
+
+	if c.AssignTo != nil {
+		*c.AssignTo = w
+	}
+
+	w.SetSuspended(true)
+	builder.Defer(func() error {
+		w.SetSuspended(false)
+		return nil
+	})
+
+	return builder.InitWidget(c, w, func() error {
+		if c.CornerRadius > 0 {
+			if err := w.SetCornerRadius(c.CornerRadius); err != nil {
+				return err
+			}
+		}
+
+		if c.Flat {
+			if err := w.SetElevation(0); err != nil {
+				return err
+			}
+		} else if c.Elevation > 0 {
+			if err := w.SetElevation(c.Elevation); err != nil {
+				return err
+			}
+		}
+
+		if !c.Padding.isZero() {
+			if err := w.SetPadding(c.Padding.toW()); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
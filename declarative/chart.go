@@ -0,0 +1,101 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package declarative
+
+import (
+	"github.com/tailscale/walk"
+)
+
+// ChartSeries describes a series of a Chart. If Color is zero, one is picked
+// from a default palette.
+type ChartSeries struct {
+	Color walk.Color
+	Name  string
+}
+
+type Chart struct {
+	// Window
+
+	Accessibility      Accessibility
+	Background         Brush
+	ContextMenuItems   []MenuItem
+	DoubleBuffering    bool
+	Enabled            Property
+	Font               Font
+	MaxSize            Size
+	MinSize            Size
+	Name               string
+	OnBoundsChanged    walk.EventHandler
+	OnKeyDown          walk.KeyEventHandler
+	OnKeyPress         walk.KeyEventHandler
+	OnKeyUp            walk.KeyEventHandler
+	OnMouseDown        walk.MouseEventHandler
+	OnMouseMove        walk.MouseEventHandler
+	OnMouseUp          walk.MouseEventHandler
+	OnSizeChanged      walk.EventHandler
+	Persistent         bool
+	RightToLeftReading bool
+	ToolTipText        Property
+	Visible            Property
+
+	// Widget
+
+	Alignment          Alignment2D
+	AlwaysConsumeSpace bool
+	Column             int
+	ColumnSpan         int
+	GraphicsEffects    []walk.WidgetGraphicsEffect
+	Row                int
+	RowSpan            int
+	StretchFactor      int
+
+	// Chart
+
+	AssignTo       **walk.Chart
+	Capacity       int
+	HideLegend     bool
+	Kind           walk.ChartKind
+	MaxValue       float64
+	MinValue       float64
+	Series         []ChartSeries
+	ValueFormatter func(value float64) string
+}
+
+func (c Chart) Create(builder *Builder) error {
+	w, err := walk.NewChart(builder.Parent())
+	if err != nil {
+		return err
+	}
+
+	if c.AssignTo != nil {
+		*c.AssignTo = w
+	}
+
+	return builder.InitWidget(c, w, func() error {
+		w.SetKind(c.Kind)
+
+		if c.Capacity > 0 {
+			if err := w.SetCapacity(c.Capacity); err != nil {
+				return err
+			}
+		}
+
+		if err := w.SetValueRange(c.MinValue, c.MaxValue); err != nil {
+			return err
+		}
+
+		w.SetLegendVisible(!c.HideLegend)
+		w.SetValueFormatter(c.ValueFormatter)
+
+		for _, s := range c.Series {
+			w.AddSeries(s.Name, s.Color)
+		}
+
+		return nil
+	})
+}
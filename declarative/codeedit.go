@@ -0,0 +1,103 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package declarative
+
+import (
+	"github.com/tailscale/walk"
+)
+
+type CodeEdit struct {
+	// Window
+
+	Accessibility      Accessibility
+	Background         Brush
+	ContextMenuItems   []MenuItem
+	DoubleBuffering    bool
+	Enabled            Property
+	Font               Font
+	MaxSize            Size
+	MinSize            Size
+	Name               string
+	OnBoundsChanged    walk.EventHandler
+	OnKeyDown          walk.KeyEventHandler
+	OnKeyPress         walk.KeyEventHandler
+	OnKeyUp            walk.KeyEventHandler
+	OnMouseDown        walk.MouseEventHandler
+	OnMouseMove        walk.MouseEventHandler
+	OnMouseUp          walk.MouseEventHandler
+	OnSizeChanged      walk.EventHandler
+	Persistent         bool
+	RightToLeftReading bool
+	ToolTipText        Property
+	Visible            Property
+
+	// Widget
+
+	Alignment          Alignment2D
+	AlwaysConsumeSpace bool
+	Column             int
+	ColumnSpan         int
+	GraphicsEffects    []walk.WidgetGraphicsEffect
+	Row                int
+	RowSpan            int
+	StretchFactor      int
+
+	// CodeEdit
+
+	AssignTo               **walk.CodeEdit
+	HideChangeMarkers      bool
+	HideLineNumbers        bool
+	InsertSpaces           bool
+	MaxLength              int
+	NoCurrentLineHighlight bool
+	OnCurrentLineChanged   walk.EventHandler
+	OnTextChanged          walk.EventHandler
+	ReadOnly               Property
+	TabWidth               int
+	Text                   Property
+	TextColor              walk.Color
+}
+
+func (ce CodeEdit) Create(builder *Builder) error {
+	w, err := walk.NewCodeEdit(builder.Parent())
+	if err != nil {
+		return err
+	}
+
+	if ce.AssignTo != nil {
+		*ce.AssignTo = w
+	}
+
+	return builder.InitWidget(ce, w, func() error {
+		w.SetTextColor(ce.TextColor)
+		w.SetInsertSpaces(ce.InsertSpaces)
+		w.SetLineNumbersVisible(!ce.HideLineNumbers)
+		w.SetChangeMarkersVisible(!ce.HideChangeMarkers)
+		w.SetCurrentLineHighlighted(!ce.NoCurrentLineHighlight)
+
+		if ce.TabWidth > 0 {
+			if err := w.SetTabWidth(ce.TabWidth); err != nil {
+				return err
+			}
+		}
+
+		if ce.MaxLength > 0 {
+			w.SetMaxLength(ce.MaxLength)
+		}
+
+		if ce.OnCurrentLineChanged != nil {
+			w.CurrentLineChanged().Attach(ce.OnCurrentLineChanged)
+		}
+
+		if ce.OnTextChanged != nil {
+			w.TextChanged().Attach(ce.OnTextChanged)
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,77 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package declarative
+
+import (
+	"github.com/tailscale/walk"
+)
+
+type ColorWell struct {
+	// Window
+
+	Accessibility      Accessibility
+	Background         Brush
+	ContextMenuItems   []MenuItem
+	DoubleBuffering    bool
+	Enabled            Property
+	Font               Font
+	MaxSize            Size
+	MinSize            Size
+	Name               string
+	OnBoundsChanged    walk.EventHandler
+	OnKeyDown          walk.KeyEventHandler
+	OnKeyPress         walk.KeyEventHandler
+	OnKeyUp            walk.KeyEventHandler
+	OnMouseDown        walk.MouseEventHandler
+	OnMouseMove        walk.MouseEventHandler
+	OnMouseUp          walk.MouseEventHandler
+	OnSizeChanged      walk.EventHandler
+	Persistent         bool
+	RightToLeftReading bool
+	ToolTipText        Property
+	Visible            Property
+
+	// Widget
+
+	Alignment          Alignment2D
+	AlwaysConsumeSpace bool
+	Column             int
+	ColumnSpan         int
+	GraphicsEffects    []walk.WidgetGraphicsEffect
+	Row                int
+	RowSpan            int
+	StretchFactor      int
+
+	// ColorWell
+
+	AssignTo       **walk.ColorWell
+	AlphaEnabled   bool
+	Color          Property
+	OnColorChanged walk.EventHandler
+}
+
+func (cw ColorWell) Create(builder *Builder) error {
+	w, err := walk.NewColorWell(builder.Parent())
+	if err != nil {
+		return err
+	}
+
+	if cw.AssignTo != nil {
+		*cw.AssignTo = w
+	}
+
+	return builder.InitWidget(cw, w, func() error {
+		w.SetAlphaEnabled(cw.AlphaEnabled)
+
+		if cw.OnColorChanged != nil {
+			w.ColorChanged().Attach(cw.OnColorChanged)
+		}
+
+		return nil
+	})
+}
@@ -53,6 +53,8 @@ type ComboBox struct {
 	// ComboBox
 
 	AssignTo              **walk.ComboBox
+	AutocompleteOptions   walk.AutocompleteOptions
+	AutocompleteSource    walk.AutocompleteSource
 	BindingMember         string
 	CurrentIndex          Property
 	DisplayMember         string
@@ -106,6 +108,16 @@ func (cb ComboBox) Create(builder *Builder) error {
 			return err
 		}
 
+		if cb.AutocompleteSource != nil {
+			options := cb.AutocompleteOptions
+			if options == 0 {
+				options = walk.AutocompleteSuggest
+			}
+			if err := w.SetAutocomplete(cb.AutocompleteSource, options); err != nil {
+				return err
+			}
+		}
+
 		if cb.OnCurrentIndexChanged != nil {
 			w.CurrentIndexChanged().Attach(cb.OnCurrentIndexChanged)
 		}
@@ -0,0 +1,82 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package declarative
+
+import (
+	"github.com/tailscale/walk"
+)
+
+type CommandLinkButton struct {
+	// Window
+
+	Accessibility      Accessibility
+	Background         Brush
+	ContextMenuItems   []MenuItem
+	DoubleBuffering    bool
+	Enabled            Property
+	Font               Font
+	MaxSize            Size
+	MinSize            Size
+	Name               string
+	OnBoundsChanged    walk.EventHandler
+	OnKeyDown          walk.KeyEventHandler
+	OnKeyPress         walk.KeyEventHandler
+	OnKeyUp            walk.KeyEventHandler
+	OnMouseDown        walk.MouseEventHandler
+	OnMouseMove        walk.MouseEventHandler
+	OnMouseUp          walk.MouseEventHandler
+	OnSizeChanged      walk.EventHandler
+	Persistent         bool
+	RightToLeftReading bool
+	ToolTipText        Property
+	Visible            Property
+
+	// Widget
+
+	Alignment          Alignment2D
+	AlwaysConsumeSpace bool
+	Column             int
+	ColumnSpan         int
+	GraphicsEffects    []walk.WidgetGraphicsEffect
+	Row                int
+	RowSpan            int
+	StretchFactor      int
+
+	// Button
+
+	Image     Property
+	OnClicked walk.EventHandler
+	Text      Property
+
+	// CommandLinkButton
+
+	AssignTo **walk.CommandLinkButton
+	Note     Property
+	Shield   bool
+}
+
+func (clb CommandLinkButton) Create(builder *Builder) error {
+	w, err := walk.NewCommandLinkButton(builder.Parent())
+	if err != nil {
+		return err
+	}
+
+	if clb.AssignTo != nil {
+		*clb.AssignTo = w
+	}
+
+	return builder.InitWidget(clb, w, func() error {
+		w.SetShield(clb.Shield)
+
+		if clb.OnClicked != nil {
+			w.Clicked().Attach(clb.OnClicked)
+		}
+
+		return nil
+	})
+}
@@ -45,11 +45,12 @@ type Dialog struct {
 
 	// Form
 
-	Expressions func() map[string]walk.Expression
-	Functions   map[string]func(args ...interface{}) (interface{}, error)
-	Icon        Property
-	Title       Property
-	Size        Size
+	Expressions   func() map[string]walk.Expression
+	Functions     map[string]func(args ...interface{}) (interface{}, error)
+	Icon          Property
+	Title         Property
+	Size          Size
+	SizeToContent bool
 
 	// Dialog
 
@@ -126,6 +127,8 @@ func (d Dialog) Create(owner walk.Form) error {
 		return err
 	}
 
+	w.SetSizeToContent(d.SizeToContent)
+
 	return builder.InitWidget(fi, w, func() error {
 		if d.Size.Width > 0 && d.Size.Height > 0 {
 			if err := w.SetSize(d.Size.toW()); err != nil {
@@ -0,0 +1,103 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package declarative
+
+import (
+	"github.com/tailscale/walk"
+)
+
+type Expander struct {
+	// Window
+
+	Accessibility      Accessibility
+	Background         Brush
+	ContextMenuItems   []MenuItem
+	DoubleBuffering    bool
+	Enabled            Property
+	Font               Font
+	MaxSize            Size
+	MinSize            Size
+	Name               string
+	OnBoundsChanged    walk.EventHandler
+	OnKeyDown          walk.KeyEventHandler
+	OnKeyPress         walk.KeyEventHandler
+	OnKeyUp            walk.KeyEventHandler
+	OnMouseDown        walk.MouseEventHandler
+	OnMouseMove        walk.MouseEventHandler
+	OnMouseUp          walk.MouseEventHandler
+	OnSizeChanged      walk.EventHandler
+	Persistent         bool
+	RightToLeftReading bool
+	ToolTipText        Property
+	Visible            Property
+
+	// Widget
+
+	Alignment          Alignment2D
+	AlwaysConsumeSpace bool
+	Column             int
+	ColumnSpan         int
+	GraphicsEffects    []walk.WidgetGraphicsEffect
+	Row                int
+	RowSpan            int
+	StretchFactor      int
+
+	// Container
+
+	Children   []Widget
+	DataBinder DataBinder
+	Layout     Layout
+
+	// Expander
+
+	AssignTo          **walk.Expander
+	Expanded          Property
+	HeaderItems       []Widget
+	NoAnimation       bool
+	OnExpandedChanged walk.EventHandler
+	Title             Property
+}
+
+func (e Expander) Create(builder *Builder) error {
+	w, err := walk.NewExpander(builder.Parent())
+	if err != nil {
+		return err
+	}
+
+	if e.AssignTo != nil {
+		*e.AssignTo = w
+	}
+
+	w.SetSuspended(true)
+	builder.Defer(func() error {
+		w.SetSuspended(false)
+		return nil
+	})
+
+	return builder.InitWidget(e, w, func() error {
+		w.SetAnimated(!e.NoAnimation)
+
+		oldParent := builder.parent
+		builder.parent = w.HeaderBar()
+		defer func() {
+			builder.parent = oldParent
+		}()
+
+		for _, item := range e.HeaderItems {
+			if err := item.Create(builder); err != nil {
+				return err
+			}
+		}
+
+		if e.OnExpandedChanged != nil {
+			w.ExpandedChanged().Attach(e.OnExpandedChanged)
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,91 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package declarative
+
+import (
+	"github.com/tailscale/walk"
+)
+
+type FilterComboBox struct {
+	// Window
+
+	Accessibility      Accessibility
+	Background         Brush
+	ContextMenuItems   []MenuItem
+	DoubleBuffering    bool
+	Enabled            Property
+	Font               Font
+	MaxSize            Size
+	MinSize            Size
+	Name               string
+	OnBoundsChanged    walk.EventHandler
+	OnKeyDown          walk.KeyEventHandler
+	OnKeyPress         walk.KeyEventHandler
+	OnKeyUp            walk.KeyEventHandler
+	OnMouseDown        walk.MouseEventHandler
+	OnMouseMove        walk.MouseEventHandler
+	OnMouseUp          walk.MouseEventHandler
+	OnSizeChanged      walk.EventHandler
+	Persistent         bool
+	RightToLeftReading bool
+	ToolTipText        Property
+	Visible            Property
+
+	// Widget
+
+	Alignment          Alignment2D
+	AlwaysConsumeSpace bool
+	Column             int
+	ColumnSpan         int
+	GraphicsEffects    []walk.WidgetGraphicsEffect
+	Row                int
+	RowSpan            int
+	StretchFactor      int
+
+	// FilterComboBox
+
+	AssignTo              **walk.FilterComboBox
+	CurrentIndex          Property
+	Filter                walk.ItemFilter
+	Items                 []string
+	OnCurrentIndexChanged walk.EventHandler
+	OnTextChanged         walk.EventHandler
+	Text                  Property
+}
+
+func (fcb FilterComboBox) Create(builder *Builder) error {
+	w, err := walk.NewFilterComboBox(builder.Parent())
+	if err != nil {
+		return err
+	}
+
+	if fcb.AssignTo != nil {
+		*fcb.AssignTo = w
+	}
+
+	return builder.InitWidget(fcb, w, func() error {
+		if fcb.Filter != nil {
+			if err := w.SetFilter(fcb.Filter); err != nil {
+				return err
+			}
+		}
+
+		if err := w.SetItems(fcb.Items); err != nil {
+			return err
+		}
+
+		if fcb.OnCurrentIndexChanged != nil {
+			w.CurrentIndexChanged().Attach(fcb.OnCurrentIndexChanged)
+		}
+		if fcb.OnTextChanged != nil {
+			w.TextChanged().Attach(fcb.OnTextChanged)
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,84 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package declarative
+
+import (
+	"github.com/tailscale/walk"
+)
+
+type FontPicker struct {
+	// Window
+
+	Accessibility      Accessibility
+	Background         Brush
+	ContextMenuItems   []MenuItem
+	DoubleBuffering    bool
+	Enabled            Property
+	Font               Font
+	MaxSize            Size
+	MinSize            Size
+	Name               string
+	OnBoundsChanged    walk.EventHandler
+	OnKeyDown          walk.KeyEventHandler
+	OnKeyPress         walk.KeyEventHandler
+	OnKeyUp            walk.KeyEventHandler
+	OnMouseDown        walk.MouseEventHandler
+	OnMouseMove        walk.MouseEventHandler
+	OnMouseUp          walk.MouseEventHandler
+	OnSizeChanged      walk.EventHandler
+	Persistent         bool
+	RightToLeftReading bool
+	ToolTipText        Property
+	Visible            Property
+
+	// Widget
+
+	Alignment          Alignment2D
+	AlwaysConsumeSpace bool
+	Column             int
+	ColumnSpan         int
+	GraphicsEffects    []walk.WidgetGraphicsEffect
+	Row                int
+	RowSpan            int
+	StretchFactor      int
+
+	// FontPicker
+
+	AssignTo              **walk.FontPicker
+	OnSelectedFontChanged walk.EventHandler
+	SelectedFont          Font
+}
+
+func (fp FontPicker) Create(builder *Builder) error {
+	w, err := walk.NewFontPicker(builder.Parent())
+	if err != nil {
+		return err
+	}
+
+	if fp.AssignTo != nil {
+		*fp.AssignTo = w
+	}
+
+	return builder.InitWidget(fp, w, func() error {
+		font, err := fp.SelectedFont.Create()
+		if err != nil {
+			return err
+		}
+		if font != nil {
+			if err := w.SetSelectedFont(font); err != nil {
+				return err
+			}
+		}
+
+		if fp.OnSelectedFontChanged != nil {
+			w.SelectedFontChanged().Attach(fp.OnSelectedFontChanged)
+		}
+
+		return nil
+	})
+}
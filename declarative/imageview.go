@@ -19,6 +19,7 @@ const (
 	ImageViewModeShrink  = ImageViewMode(walk.ImageViewModeShrink)
 	ImageViewModeZoom    = ImageViewMode(walk.ImageViewModeZoom)
 	ImageViewModeStretch = ImageViewMode(walk.ImageViewModeStretch)
+	ImageViewModeViewer  = ImageViewMode(walk.ImageViewModeViewer)
 )
 
 type ImageView struct {
@@ -59,10 +60,12 @@ type ImageView struct {
 
 	// ImageView
 
-	AssignTo **walk.ImageView
-	Image    Property
-	Margin   Property
-	Mode     ImageViewMode
+	AssignTo          **walk.ImageView
+	Checkerboard      bool
+	Image             Property
+	Margin            Property
+	Mode              ImageViewMode
+	OnViewportChanged walk.EventHandler
 }
 
 func (iv ImageView) Create(builder *Builder) error {
@@ -77,6 +80,11 @@ func (iv ImageView) Create(builder *Builder) error {
 
 	return builder.InitWidget(iv, w, func() error {
 		w.SetMode(walk.ImageViewMode(iv.Mode))
+		w.SetCheckerboard(iv.Checkerboard)
+
+		if iv.OnViewportChanged != nil {
+			w.ViewportChanged().Attach(iv.OnViewportChanged)
+		}
 
 		return nil
 	})
@@ -0,0 +1,104 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package declarative
+
+import (
+	"github.com/tailscale/walk"
+)
+
+type InfoBarSeverity int
+
+const (
+	InfoBarInformation = InfoBarSeverity(walk.InfoBarInformation)
+	InfoBarWarning     = InfoBarSeverity(walk.InfoBarWarning)
+	InfoBarError       = InfoBarSeverity(walk.InfoBarError)
+)
+
+type InfoBar struct {
+	// Window
+
+	Accessibility      Accessibility
+	Background         Brush
+	ContextMenuItems   []MenuItem
+	DoubleBuffering    bool
+	Enabled            Property
+	Font               Font
+	MaxSize            Size
+	MinSize            Size
+	Name               string
+	OnBoundsChanged    walk.EventHandler
+	OnKeyDown          walk.KeyEventHandler
+	OnKeyPress         walk.KeyEventHandler
+	OnKeyUp            walk.KeyEventHandler
+	OnMouseDown        walk.MouseEventHandler
+	OnMouseMove        walk.MouseEventHandler
+	OnMouseUp          walk.MouseEventHandler
+	OnSizeChanged      walk.EventHandler
+	RightToLeftReading bool
+	ToolTipText        Property
+
+	// Widget
+
+	Alignment          Alignment2D
+	AlwaysConsumeSpace bool
+	Column             int
+	ColumnSpan         int
+	GraphicsEffects    []walk.WidgetGraphicsEffect
+	Row                int
+	RowSpan            int
+	StretchFactor      int
+
+	// Container
+
+	DataBinder DataBinder
+
+	// InfoBar
+
+	Actions           []Widget
+	AssignTo          **walk.InfoBar
+	CloseButtonHidden bool
+	Message           Property
+	NoAnimation       bool
+	OnOpenChanged     walk.EventHandler
+	Open              Property
+	Severity          Property
+}
+
+func (ib InfoBar) Create(builder *Builder) error {
+	w, err := walk.NewInfoBar(builder.Parent())
+	if err != nil {
+		return err
+	}
+
+	if ib.AssignTo != nil {
+		*ib.AssignTo = w
+	}
+
+	return builder.InitWidget(ib, w, func() error {
+		w.SetAnimated(!ib.NoAnimation)
+		w.SetCloseButtonVisible(!ib.CloseButtonHidden)
+
+		oldParent := builder.parent
+		builder.parent = w.Actions()
+		defer func() {
+			builder.parent = oldParent
+		}()
+
+		for _, action := range ib.Actions {
+			if err := action.Create(builder); err != nil {
+				return err
+			}
+		}
+
+		if ib.OnOpenChanged != nil {
+			w.OpenChanged().Attach(ib.OnOpenChanged)
+		}
+
+		return nil
+	})
+}
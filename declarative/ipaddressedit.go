@@ -0,0 +1,74 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package declarative
+
+import (
+	"github.com/tailscale/walk"
+)
+
+type IPAddressEdit struct {
+	// Window
+
+	Accessibility      Accessibility
+	Background         Brush
+	ContextMenuItems   []MenuItem
+	DoubleBuffering    bool
+	Enabled            Property
+	Font               Font
+	MaxSize            Size
+	MinSize            Size
+	Name               string
+	OnBoundsChanged    walk.EventHandler
+	OnKeyDown          walk.KeyEventHandler
+	OnKeyPress         walk.KeyEventHandler
+	OnKeyUp            walk.KeyEventHandler
+	OnMouseDown        walk.MouseEventHandler
+	OnMouseMove        walk.MouseEventHandler
+	OnMouseUp          walk.MouseEventHandler
+	OnSizeChanged      walk.EventHandler
+	Persistent         bool
+	RightToLeftReading bool
+	ToolTipText        Property
+	Visible            Property
+
+	// Widget
+
+	Alignment          Alignment2D
+	AlwaysConsumeSpace bool
+	Column             int
+	ColumnSpan         int
+	GraphicsEffects    []walk.WidgetGraphicsEffect
+	Row                int
+	RowSpan            int
+	StretchFactor      int
+
+	// IPAddressEdit
+
+	Address          Property
+	AssignTo         **walk.IPAddressEdit
+	OnAddressChanged walk.EventHandler
+}
+
+func (ipe IPAddressEdit) Create(builder *Builder) error {
+	w, err := walk.NewIPAddressEdit(builder.Parent())
+	if err != nil {
+		return err
+	}
+
+	if ipe.AssignTo != nil {
+		*ipe.AssignTo = w
+	}
+
+	return builder.InitWidget(ipe, w, func() error {
+		if ipe.OnAddressChanged != nil {
+			w.AddressChanged().Attach(ipe.OnAddressChanged)
+		}
+
+		return nil
+	})
+}
@@ -123,13 +123,14 @@ func (vb VBox) Create() (walk.Layout, error) {
 }
 
 type Grid struct {
-	Rows        int
-	Columns     int
-	Margins     Margins
-	Alignment   Alignment2D
-	Spacing     int
-	MarginsZero bool
-	SpacingZero bool
+	Rows              int
+	Columns           int
+	Margins           Margins
+	Alignment         Alignment2D
+	Spacing           int
+	MarginsZero       bool
+	SpacingZero       bool
+	BaselineAlignment bool
 }
 
 func (g Grid) Create() (walk.Layout, error) {
@@ -151,15 +152,35 @@ func (g Grid) Create() (walk.Layout, error) {
 		return nil, err
 	}
 
+	l.SetBaselineAlignment(g.BaselineAlignment)
+
 	return l, nil
 }
 
+type FlowJustification int
+
+const (
+	FlowJustifyDefault      = FlowJustification(walk.FlowJustifyDefault)
+	FlowJustifyNear         = FlowJustification(walk.FlowJustifyNear)
+	FlowJustifyCenter       = FlowJustification(walk.FlowJustifyCenter)
+	FlowJustifyFar          = FlowJustification(walk.FlowJustifyFar)
+	FlowJustifySpaceBetween = FlowJustification(walk.FlowJustifySpaceBetween)
+)
+
 type Flow struct {
-	Margins     Margins
-	Alignment   Alignment2D
-	Spacing     int
-	MarginsZero bool
-	SpacingZero bool
+	Margins              Margins
+	Alignment            Alignment2D
+	Spacing              int
+	HSpacing             int
+	VSpacing             int
+	MarginsZero          bool
+	SpacingZero          bool
+	HSpacingZero         bool
+	VSpacingZero         bool
+	RowAlignment         Alignment1D
+	Justification        FlowJustification
+	LastRowJustification FlowJustification
+	RightToLeft          bool
 }
 
 func (f Flow) Create() (walk.Layout, error) {
@@ -177,5 +198,67 @@ func (f Flow) Create() (walk.Layout, error) {
 		return nil, err
 	}
 
+	if f.HSpacing > 0 || f.HSpacingZero {
+		if err := l.SetHSpacing(f.HSpacing); err != nil {
+			return nil, err
+		}
+	}
+
+	if f.VSpacing > 0 || f.VSpacingZero {
+		if err := l.SetVSpacing(f.VSpacing); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := l.SetRowAlignment(walk.Alignment1D(f.RowAlignment)); err != nil {
+		return nil, err
+	}
+
+	if err := l.SetJustification(walk.FlowJustification(f.Justification)); err != nil {
+		return nil, err
+	}
+
+	if err := l.SetLastRowJustification(walk.FlowJustification(f.LastRowJustification)); err != nil {
+		return nil, err
+	}
+
+	l.SetRightToLeft(f.RightToLeft)
+
+	return l, nil
+}
+
+type UniformGrid struct {
+	Margins     Margins
+	Spacing     int
+	MarginsZero bool
+	SpacingZero bool
+	Rows        int
+	Columns     int
+	MinCellSize Size
+}
+
+func (ug UniformGrid) Create() (walk.Layout, error) {
+	l := walk.NewUniformGridLayout()
+
+	if err := setLayoutMargins(l, ug.Margins, ug.MarginsZero); err != nil {
+		return nil, err
+	}
+
+	if err := setLayoutSpacing(l, ug.Spacing, ug.SpacingZero); err != nil {
+		return nil, err
+	}
+
+	if err := l.SetRows(ug.Rows); err != nil {
+		return nil, err
+	}
+
+	if err := l.SetColumns(ug.Columns); err != nil {
+		return nil, err
+	}
+
+	if err := l.SetMinCellSize(ug.MinCellSize.toW()); err != nil {
+		return nil, err
+	}
+
 	return l, nil
 }
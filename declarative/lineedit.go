@@ -56,17 +56,20 @@ type LineEdit struct {
 
 	// LineEdit
 
-	AssignTo          **walk.LineEdit
-	CaseMode          CaseMode
-	CueBanner         string
-	MaxLength         int
-	OnEditingFinished walk.EventHandler
-	OnTextChanged     walk.EventHandler
-	PasswordMode      bool
-	ReadOnly          Property
-	Text              Property
-	TextAlignment     Alignment1D
-	TextColor         walk.Color
+	AssignTo            **walk.LineEdit
+	AutocompleteOptions walk.AutocompleteOptions
+	AutocompleteSource  walk.AutocompleteSource
+	CaseMode            CaseMode
+	CueBanner           string
+	InputMask           string
+	MaxLength           int
+	OnEditingFinished   walk.EventHandler
+	OnTextChanged       walk.EventHandler
+	PasswordMode        bool
+	ReadOnly            Property
+	Text                Property
+	TextAlignment       Alignment1D
+	TextColor           walk.Color
 }
 
 func (le LineEdit) Create(builder *Builder) error {
@@ -98,6 +101,26 @@ func (le LineEdit) Create(builder *Builder) error {
 			return err
 		}
 
+		if le.InputMask != "" {
+			mask, err := walk.NewInputMask(le.InputMask, nil)
+			if err != nil {
+				return err
+			}
+			if err := w.SetInputMask(mask); err != nil {
+				return err
+			}
+		}
+
+		if le.AutocompleteSource != nil {
+			options := le.AutocompleteOptions
+			if options == 0 {
+				options = walk.AutocompleteSuggest
+			}
+			if err := w.SetAutocomplete(le.AutocompleteSource, options); err != nil {
+				return err
+			}
+		}
+
 		if le.OnEditingFinished != nil {
 			w.EditingFinished().Attach(le.OnEditingFinished)
 		}
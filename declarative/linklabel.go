@@ -51,6 +51,7 @@ type LinkLabel struct {
 	AssignTo        **walk.LinkLabel
 	OnLinkActivated walk.LinkLabelLinkEventHandler
 	Text            Property
+	WordWrap        bool
 }
 
 func (ll LinkLabel) Create(builder *Builder) error {
@@ -64,6 +65,8 @@ func (ll LinkLabel) Create(builder *Builder) error {
 	}
 
 	return builder.InitWidget(ll, w, func() error {
+		w.SetWordWrap(ll.WordWrap)
+
 		if ll.OnLinkActivated != nil {
 			w.LinkActivated().Attach(ll.OnLinkActivated)
 		}
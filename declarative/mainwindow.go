@@ -43,9 +43,10 @@ type MainWindow struct {
 
 	// Form
 
-	Icon  Property
-	Size  Size
-	Title Property
+	Icon          Property
+	Size          Size
+	SizeToContent bool
+	Title         Property
 
 	// MainWindow
 
@@ -122,6 +123,8 @@ func (mw MainWindow) Create() error {
 		return err
 	}
 
+	w.SetSizeToContent(mw.SizeToContent)
+
 	return builder.InitWidget(fi, w, func() error {
 		if len(mw.ToolBar.Items) > 0 {
 			var tb *walk.ToolBar
@@ -151,6 +154,21 @@ func (mw MainWindow) Create() error {
 			if sbi.Width > 0 {
 				s.SetWidth(sbi.Width)
 			}
+			s.SetAutoSize(sbi.AutoSize)
+			s.SetRightAligned(sbi.RightAligned)
+			if sbi.PaintPixels != nil {
+				s.SetPaintFunc(sbi.PaintPixels)
+			}
+			if sbi.Widget != nil {
+				if err := sbi.Widget.Create(builder); err != nil {
+					return err
+				}
+
+				children := w.Children()
+				if err := s.SetWidget(children.At(children.Len() - 1)); err != nil {
+					return err
+				}
+			}
 			if sbi.OnClicked != nil {
 				s.Clicked().Attach(sbi.OnClicked)
 			}
@@ -201,10 +219,14 @@ func (mw MainWindow) Create() error {
 }
 
 type StatusBarItem struct {
-	AssignTo    **walk.StatusBarItem
-	Icon        *walk.Icon
-	Text        string
-	ToolTipText string
-	Width       int
-	OnClicked   walk.EventHandler
+	AssignTo     **walk.StatusBarItem
+	AutoSize     bool
+	Icon         *walk.Icon
+	PaintPixels  walk.PaintFunc
+	RightAligned bool
+	Text         string
+	ToolTipText  string
+	Widget       Widget
+	Width        int
+	OnClicked    walk.EventHandler
 }
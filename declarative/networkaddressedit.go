@@ -0,0 +1,90 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package declarative
+
+import (
+	"github.com/tailscale/walk"
+)
+
+type NetworkAddressEdit struct {
+	// Window
+
+	Accessibility      Accessibility
+	Background         Brush
+	ContextMenuItems   []MenuItem
+	DoubleBuffering    bool
+	Enabled            Property
+	Font               Font
+	MaxSize            Size
+	MinSize            Size
+	Name               string
+	OnBoundsChanged    walk.EventHandler
+	OnKeyDown          walk.KeyEventHandler
+	OnKeyPress         walk.KeyEventHandler
+	OnKeyUp            walk.KeyEventHandler
+	OnMouseDown        walk.MouseEventHandler
+	OnMouseMove        walk.MouseEventHandler
+	OnMouseUp          walk.MouseEventHandler
+	OnSizeChanged      walk.EventHandler
+	Persistent         bool
+	RightToLeftReading bool
+	ToolTipText        Property
+	Visible            Property
+
+	// Widget
+
+	Alignment          Alignment2D
+	AlwaysConsumeSpace bool
+	Column             int
+	ColumnSpan         int
+	GraphicsEffects    []walk.WidgetGraphicsEffect
+	Row                int
+	RowSpan            int
+	StretchFactor      int
+
+	// NetworkAddressEdit
+
+	AssignTo          **walk.NetworkAddressEdit
+	CueBanner         string
+	Kind              walk.NetworkAddressKind
+	OnEditingFinished walk.EventHandler
+	OnTextChanged     walk.EventHandler
+	ReadOnly          Property
+	Text              Property
+	TextColor         walk.Color
+}
+
+func (nae NetworkAddressEdit) Create(builder *Builder) error {
+	w, err := walk.NewNetworkAddressEdit(builder.Parent(), nae.Kind)
+	if err != nil {
+		return err
+	}
+
+	if nae.AssignTo != nil {
+		*nae.AssignTo = w
+	}
+
+	return builder.InitWidget(nae, w, func() error {
+		w.SetTextColor(nae.TextColor)
+
+		if nae.CueBanner != "" {
+			if err := w.SetCueBanner(nae.CueBanner); err != nil {
+				return err
+			}
+		}
+
+		if nae.OnEditingFinished != nil {
+			w.EditingFinished().Attach(nae.OnEditingFinished)
+		}
+		if nae.OnTextChanged != nil {
+			w.TextChanged().Attach(nae.OnTextChanged)
+		}
+
+		return nil
+	})
+}
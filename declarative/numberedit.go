@@ -48,6 +48,7 @@ type NumberEdit struct {
 
 	// NumberEdit
 
+	Accelerations      []walk.UpDownAcceleration
 	AssignTo           **walk.NumberEdit
 	Decimals           int
 	Increment          float64
@@ -60,6 +61,7 @@ type NumberEdit struct {
 	Suffix             Property
 	TextColor          walk.Color
 	Value              Property
+	Wrap               bool
 }
 
 func (ne NumberEdit) Create(builder *Builder) error {
@@ -98,6 +100,12 @@ func (ne NumberEdit) Create(builder *Builder) error {
 			return err
 		}
 
+		if err := w.SetAccelerations(ne.Accelerations); err != nil {
+			return err
+		}
+
+		w.SetWrap(ne.Wrap)
+
 		if ne.OnValueChanged != nil {
 			w.ValueChanged().Attach(ne.OnValueChanged)
 		}
@@ -48,11 +48,13 @@ type ProgressBar struct {
 
 	// ProgressBar
 
-	AssignTo    **walk.ProgressBar
-	MarqueeMode bool
-	MaxValue    int
-	MinValue    int
-	Value       int
+	AssignTo        **walk.ProgressBar
+	MarqueeInterval int
+	MarqueeMode     bool
+	MaxValue        int
+	MinValue        int
+	State           walk.ProgressBarState
+	Value           int
 }
 
 func (pb ProgressBar) Create(builder *Builder) error {
@@ -71,10 +73,20 @@ func (pb ProgressBar) Create(builder *Builder) error {
 		}
 		w.SetValue(pb.Value)
 
+		if err := w.SetMarqueeInterval(pb.MarqueeInterval); err != nil {
+			return err
+		}
+
 		if err := w.SetMarqueeMode(pb.MarqueeMode); err != nil {
 			return err
 		}
 
+		if pb.State != 0 {
+			if err := w.SetState(pb.State); err != nil {
+				return err
+			}
+		}
+
 		return nil
 	})
 }
@@ -0,0 +1,113 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package declarative
+
+import (
+	"github.com/tailscale/walk"
+)
+
+type RangeSlider struct {
+	// Window
+
+	Accessibility      Accessibility
+	Background         Brush
+	ContextMenuItems   []MenuItem
+	DoubleBuffering    bool
+	Enabled            Property
+	Font               Font
+	MaxSize            Size
+	MinSize            Size
+	Name               string
+	OnBoundsChanged    walk.EventHandler
+	OnKeyDown          walk.KeyEventHandler
+	OnKeyPress         walk.KeyEventHandler
+	OnKeyUp            walk.KeyEventHandler
+	OnMouseDown        walk.MouseEventHandler
+	OnMouseMove        walk.MouseEventHandler
+	OnMouseUp          walk.MouseEventHandler
+	OnSizeChanged      walk.EventHandler
+	Persistent         bool
+	RightToLeftReading bool
+	ToolTipText        Property
+	Visible            Property
+
+	// Widget
+
+	Alignment          Alignment2D
+	AlwaysConsumeSpace bool
+	Column             int
+	ColumnSpan         int
+	GraphicsEffects    []walk.WidgetGraphicsEffect
+	Row                int
+	RowSpan            int
+	StretchFactor      int
+
+	// RangeSlider
+
+	AssignTo          **walk.RangeSlider
+	LineSize          int
+	LowerValue        Property
+	MaxValue          int
+	MinValue          int
+	OnValuesChanged   walk.EventHandler
+	PageSize          int
+	SnapToTicks       bool
+	TickFrequency     int
+	TickLabelsVisible bool
+	ToolTipsHidden    bool
+	Tracking          bool
+	UpperValue        Property
+	ValueFormatter    func(value int) string
+}
+
+func (rs RangeSlider) Create(builder *Builder) error {
+	w, err := walk.NewRangeSlider(builder.Parent())
+	if err != nil {
+		return err
+	}
+
+	if rs.AssignTo != nil {
+		*rs.AssignTo = w
+	}
+
+	return builder.InitWidget(rs, w, func() error {
+		w.SetPersistent(rs.Persistent)
+		if rs.LineSize > 0 {
+			w.SetLineSize(rs.LineSize)
+		}
+		if rs.PageSize > 0 {
+			w.SetPageSize(rs.PageSize)
+		}
+		w.SetTracking(rs.Tracking)
+		w.SetToolTipsHidden(rs.ToolTipsHidden)
+
+		if rs.MaxValue > rs.MinValue {
+			if err := w.SetRange(rs.MinValue, rs.MaxValue); err != nil {
+				return err
+			}
+			if err := w.SetValues(rs.MinValue, rs.MaxValue); err != nil {
+				return err
+			}
+		}
+
+		w.SetValueFormatter(rs.ValueFormatter)
+		w.SetSnapToTicks(rs.SnapToTicks)
+		if rs.TickFrequency > 0 {
+			if err := w.SetTickFrequency(rs.TickFrequency); err != nil {
+				return err
+			}
+		}
+		w.SetTickLabelsVisible(rs.TickLabelsVisible)
+
+		if rs.OnValuesChanged != nil {
+			w.ValuesChanged().Attach(rs.OnValuesChanged)
+		}
+
+		return nil
+	})
+}
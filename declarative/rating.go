@@ -0,0 +1,87 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package declarative
+
+import (
+	"github.com/tailscale/walk"
+)
+
+type Rating struct {
+	// Window
+
+	Accessibility      Accessibility
+	Background         Brush
+	ContextMenuItems   []MenuItem
+	DoubleBuffering    bool
+	Enabled            Property
+	Font               Font
+	MaxSize            Size
+	MinSize            Size
+	Name               string
+	OnBoundsChanged    walk.EventHandler
+	OnKeyDown          walk.KeyEventHandler
+	OnKeyPress         walk.KeyEventHandler
+	OnKeyUp            walk.KeyEventHandler
+	OnMouseDown        walk.MouseEventHandler
+	OnMouseMove        walk.MouseEventHandler
+	OnMouseUp          walk.MouseEventHandler
+	OnSizeChanged      walk.EventHandler
+	Persistent         bool
+	RightToLeftReading bool
+	ToolTipText        Property
+	Visible            Property
+
+	// Widget
+
+	Alignment          Alignment2D
+	AlwaysConsumeSpace bool
+	Column             int
+	ColumnSpan         int
+	GraphicsEffects    []walk.WidgetGraphicsEffect
+	Row                int
+	RowSpan            int
+	StretchFactor      int
+
+	// Rating
+
+	AssignTo       **walk.Rating
+	Color          walk.Color
+	HalfSteps      bool
+	MaxValue       int
+	OnValueChanged walk.EventHandler
+	ReadOnly       Property
+	Value          Property
+}
+
+func (r Rating) Create(builder *Builder) error {
+	w, err := walk.NewRating(builder.Parent())
+	if err != nil {
+		return err
+	}
+
+	if r.AssignTo != nil {
+		*r.AssignTo = w
+	}
+
+	return builder.InitWidget(r, w, func() error {
+		if r.MaxValue > 0 {
+			if err := w.SetMaxValue(r.MaxValue); err != nil {
+				return err
+			}
+		}
+
+		w.SetColor(r.Color)
+		w.SetHalfSteps(r.HalfSteps)
+
+		if r.OnValueChanged != nil {
+			w.ValueChanged().Attach(r.OnValueChanged)
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,133 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package declarative
+
+import (
+	"github.com/tailscale/walk"
+)
+
+// ResponsiveBreakpoint makes a ResponsiveContainer use Layout while it is at
+// least MinWidth wide.
+type ResponsiveBreakpoint struct {
+	MinWidth int
+	Layout   Layout
+}
+
+type ResponsiveContainer struct {
+	// Window
+
+	Accessibility      Accessibility
+	Background         Brush
+	ContextMenuItems   []MenuItem
+	DoubleBuffering    bool
+	Enabled            Property
+	Font               Font
+	MaxSize            Size
+	MinSize            Size
+	Name               string
+	OnBoundsChanged    walk.EventHandler
+	OnKeyDown          walk.KeyEventHandler
+	OnKeyPress         walk.KeyEventHandler
+	OnKeyUp            walk.KeyEventHandler
+	OnMouseDown        walk.MouseEventHandler
+	OnMouseMove        walk.MouseEventHandler
+	OnMouseUp          walk.MouseEventHandler
+	OnSizeChanged      walk.EventHandler
+	Persistent         bool
+	RightToLeftReading bool
+	ToolTipText        Property
+	Visible            Property
+
+	// Widget
+
+	Alignment          Alignment2D
+	AlwaysConsumeSpace bool
+	Column             int
+	ColumnSpan         int
+	GraphicsEffects    []walk.WidgetGraphicsEffect
+	Row                int
+	RowSpan            int
+	StretchFactor      int
+
+	// Container
+
+	Children   []Widget
+	DataBinder DataBinder
+	Layout     Layout
+
+	// ResponsiveContainer
+
+	AssignTo            **walk.ResponsiveContainer
+	Breakpoints         []ResponsiveBreakpoint
+	OnLayoutModeChanged walk.EventHandler
+}
+
+func (rc ResponsiveContainer) Create(builder *Builder) error {
+	w, err := walk.NewResponsiveContainer(builder.Parent())
+	if err != nil {
+		return err
+	}
+
+	if rc.AssignTo != nil {
+		*rc.AssignTo = w
+	}
+
+	w.SetSuspended(true)
+	builder.Defer(func() error {
+		w.SetSuspended(false)
+		return nil
+	})
+
+	return builder.InitWidget(rc, w, func() error {
+		type StretchFactorer interface {
+			StretchFactor(widget walk.Widget) int
+			SetStretchFactor(widget walk.Widget, factor int) error
+		}
+
+		// The StretchFactor of the children has only been applied to the
+		// base layout so far.
+		base, _ := w.Layout().(StretchFactorer)
+
+		breakpoints := make([]walk.ResponsiveBreakpoint, 0, len(rc.Breakpoints))
+
+		for _, bp := range rc.Breakpoints {
+			if bp.Layout == nil {
+				continue
+			}
+
+			l, err := bp.Layout.Create()
+			if err != nil {
+				return err
+			}
+
+			breakpoints = append(breakpoints, walk.ResponsiveBreakpoint{MinWidth: bp.MinWidth, Layout: l})
+		}
+
+		if err := w.SetBreakpoints(breakpoints); err != nil {
+			return err
+		}
+
+		for _, bp := range breakpoints {
+			if sf, ok := bp.Layout.(StretchFactorer); ok && base != nil {
+				children := w.Children()
+				for i := 0; i < children.Len(); i++ {
+					child := children.At(i)
+					if err := sf.SetStretchFactor(child, base.StretchFactor(child)); err != nil {
+						return err
+					}
+				}
+			}
+		}
+
+		if rc.OnLayoutModeChanged != nil {
+			w.LayoutModeChanged().Attach(rc.OnLayoutModeChanged)
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,106 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package declarative
+
+import (
+	"github.com/tailscale/walk"
+	"github.com/tailscale/win"
+)
+
+type RichTextEdit struct {
+	// Window
+
+	Accessibility      Accessibility
+	Background         Brush
+	ContextMenuItems   []MenuItem
+	DoubleBuffering    bool
+	Enabled            Property
+	Font               Font
+	MaxSize            Size
+	MinSize            Size
+	Name               string
+	OnBoundsChanged    walk.EventHandler
+	OnKeyDown          walk.KeyEventHandler
+	OnKeyPress         walk.KeyEventHandler
+	OnKeyUp            walk.KeyEventHandler
+	OnMouseDown        walk.MouseEventHandler
+	OnMouseMove        walk.MouseEventHandler
+	OnMouseUp          walk.MouseEventHandler
+	OnSizeChanged      walk.EventHandler
+	Persistent         bool
+	RightToLeftReading bool
+	ToolTipText        Property
+	Visible            Property
+
+	// Widget
+
+	Alignment          Alignment2D
+	AlwaysConsumeSpace bool
+	Column             int
+	ColumnSpan         int
+	GraphicsEffects    []walk.WidgetGraphicsEffect
+	Row                int
+	RowSpan            int
+	StretchFactor      int
+
+	// RichTextEdit
+
+	AssignTo           **walk.RichTextEdit
+	AutoURLDetect      bool
+	HScroll            bool
+	OnLinkClicked      walk.StringEventHandler
+	OnSelectionChanged walk.EventHandler
+	OnTextChanged      walk.EventHandler
+	ReadOnly           Property
+	RTF                string
+	Text               Property
+}
+
+func (rte RichTextEdit) Create(builder *Builder) error {
+	var style uint32
+	if rte.HScroll {
+		style |= win.WS_HSCROLL | win.ES_AUTOHSCROLL
+	}
+
+	w, err := walk.NewRichTextEditWithStyle(builder.Parent(), style)
+	if err != nil {
+		return err
+	}
+
+	if rte.AssignTo != nil {
+		*rte.AssignTo = w
+	}
+
+	return builder.InitWidget(rte, w, func() error {
+		if rte.AutoURLDetect {
+			if err := w.SetAutoURLDetect(true); err != nil {
+				return err
+			}
+		}
+
+		if rte.RTF != "" {
+			if err := w.SetRTF(rte.RTF); err != nil {
+				return err
+			}
+		}
+
+		if rte.OnLinkClicked != nil {
+			w.LinkClicked().Attach(rte.OnLinkClicked)
+		}
+
+		if rte.OnSelectionChanged != nil {
+			w.SelectionChanged().Attach(rte.OnSelectionChanged)
+		}
+
+		if rte.OnTextChanged != nil {
+			w.TextChanged().Attach(rte.OnTextChanged)
+		}
+
+		return nil
+	})
+}
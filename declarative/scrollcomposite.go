@@ -0,0 +1,88 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package declarative
+
+import (
+	"github.com/tailscale/walk"
+)
+
+type ScrollComposite struct {
+	// Window
+
+	Accessibility      Accessibility
+	Background         Brush
+	ContextMenuItems   []MenuItem
+	DoubleBuffering    bool
+	Enabled            Property
+	Font               Font
+	MaxSize            Size
+	MinSize            Size
+	Name               string
+	OnBoundsChanged    walk.EventHandler
+	OnKeyDown          walk.KeyEventHandler
+	OnKeyPress         walk.KeyEventHandler
+	OnKeyUp            walk.KeyEventHandler
+	OnMouseDown        walk.MouseEventHandler
+	OnMouseMove        walk.MouseEventHandler
+	OnMouseUp          walk.MouseEventHandler
+	OnSizeChanged      walk.EventHandler
+	Persistent         bool
+	RightToLeftReading bool
+	ToolTipText        Property
+	Visible            Property
+
+	// Widget
+
+	Alignment          Alignment2D
+	AlwaysConsumeSpace bool
+	Column             int
+	ColumnSpan         int
+	GraphicsEffects    []walk.WidgetGraphicsEffect
+	Row                int
+	RowSpan            int
+	StretchFactor      int
+
+	// Container
+
+	Children   []Widget
+	DataBinder DataBinder
+	Layout     Layout
+
+	// ScrollComposite
+
+	AssignTo         **walk.ScrollComposite
+	HorizontalFixed  bool
+	SmoothScrolling  bool
+	VerticalFixed    bool
+	WheelScrollLines int
+}
+
+func (sc ScrollComposite) Create(builder *Builder) error {
+	w, err := walk.NewScrollComposite(builder.Parent())
+	if err != nil {
+		return err
+	}
+
+	if sc.AssignTo != nil {
+		*sc.AssignTo = w
+	}
+
+	w.SetSuspended(true)
+	builder.Defer(func() error {
+		w.SetSuspended(false)
+		return nil
+	})
+
+	w.SetScrollbars(!sc.HorizontalFixed, !sc.VerticalFixed)
+	w.SetSmoothScrolling(sc.SmoothScrolling)
+	w.SetWheelScrollLines(sc.WheelScrollLines)
+
+	return builder.InitWidget(sc, w, func() error {
+		return nil
+	})
+}
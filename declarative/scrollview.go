@@ -54,9 +54,11 @@ type ScrollView struct {
 
 	// ScrollView
 
-	AssignTo        **walk.ScrollView
-	HorizontalFixed bool
-	VerticalFixed   bool
+	AssignTo         **walk.ScrollView
+	HorizontalFixed  bool
+	SmoothScrolling  bool
+	VerticalFixed    bool
+	WheelScrollLines int
 }
 
 func (sv ScrollView) Create(builder *Builder) error {
@@ -76,6 +78,8 @@ func (sv ScrollView) Create(builder *Builder) error {
 	})
 
 	w.SetScrollbars(!sv.HorizontalFixed, !sv.VerticalFixed)
+	w.SetSmoothScrolling(sv.SmoothScrolling)
+	w.SetWheelScrollLines(sv.WheelScrollLines)
 
 	return builder.InitWidget(sv, w, func() error {
 		return nil
@@ -0,0 +1,93 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package declarative
+
+import (
+	"time"
+
+	"github.com/tailscale/walk"
+)
+
+type SearchBox struct {
+	// Window
+
+	Accessibility      Accessibility
+	Background         Brush
+	ContextMenuItems   []MenuItem
+	DoubleBuffering    bool
+	Enabled            Property
+	Font               Font
+	MaxSize            Size
+	MinSize            Size
+	Name               string
+	OnBoundsChanged    walk.EventHandler
+	OnKeyDown          walk.KeyEventHandler
+	OnKeyPress         walk.KeyEventHandler
+	OnKeyUp            walk.KeyEventHandler
+	OnMouseDown        walk.MouseEventHandler
+	OnMouseMove        walk.MouseEventHandler
+	OnMouseUp          walk.MouseEventHandler
+	OnSizeChanged      walk.EventHandler
+	Persistent         bool
+	RightToLeftReading bool
+	ToolTipText        Property
+	Visible            Property
+
+	// Widget
+
+	Alignment          Alignment2D
+	AlwaysConsumeSpace bool
+	Column             int
+	ColumnSpan         int
+	GraphicsEffects    []walk.WidgetGraphicsEffect
+	Row                int
+	RowSpan            int
+	StretchFactor      int
+
+	// SearchBox
+
+	AssignTo          **walk.SearchBox
+	CueBanner         string
+	DebounceInterval  time.Duration
+	OnResultActivated walk.IntEventHandler
+	OnTextChanged     walk.EventHandler
+	Text              Property
+}
+
+func (sb SearchBox) Create(builder *Builder) error {
+	w, err := walk.NewSearchBox(builder.Parent())
+	if err != nil {
+		return err
+	}
+
+	if sb.AssignTo != nil {
+		*sb.AssignTo = w
+	}
+
+	return builder.InitWidget(sb, w, func() error {
+		if sb.CueBanner != "" {
+			if err := w.SetCueBanner(sb.CueBanner); err != nil {
+				return err
+			}
+		}
+
+		if sb.DebounceInterval != 0 {
+			w.SetDebounceInterval(sb.DebounceInterval)
+		}
+
+		if sb.OnResultActivated != nil {
+			w.ResultActivated().Attach(sb.OnResultActivated)
+		}
+
+		if sb.OnTextChanged != nil {
+			w.TextChanged().Attach(sb.OnTextChanged)
+		}
+
+		return nil
+	})
+}
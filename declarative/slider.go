@@ -48,16 +48,20 @@ type Slider struct {
 
 	// Slider
 
-	AssignTo       **walk.Slider
-	LineSize       int
-	MaxValue       int
-	MinValue       int
-	Orientation    Orientation
-	OnValueChanged walk.EventHandler
-	PageSize       int
-	ToolTipsHidden bool
-	Tracking       bool
-	Value          Property
+	AssignTo          **walk.Slider
+	LineSize          int
+	MaxValue          int
+	MinValue          int
+	Orientation       Orientation
+	OnValueChanged    walk.EventHandler
+	PageSize          int
+	SnapToTicks       bool
+	TickFrequency     int
+	TickLabelsVisible bool
+	ToolTipsHidden    bool
+	Tracking          bool
+	Value             Property
+	ValueFormatter    func(value int) string
 }
 
 func (sl Slider) Create(builder *Builder) error {
@@ -87,6 +91,17 @@ func (sl Slider) Create(builder *Builder) error {
 			w.SetRange(sl.MinValue, sl.MaxValue)
 		}
 
+		w.SetValueFormatter(sl.ValueFormatter)
+		w.SetSnapToTicks(sl.SnapToTicks)
+		if sl.TickFrequency > 0 {
+			if err := w.SetTickFrequency(sl.TickFrequency); err != nil {
+				return err
+			}
+		}
+		if err := w.SetTickLabelsVisible(sl.TickLabelsVisible); err != nil {
+			return err
+		}
+
 		if sl.OnValueChanged != nil {
 			w.ValueChanged().Attach(sl.OnValueChanged)
 		}
@@ -55,6 +55,7 @@ type SplitButton struct {
 	// SplitButton
 
 	AssignTo       **walk.SplitButton
+	DefaultAction  *walk.Action
 	ImageAboveText bool
 	MenuItems      []MenuItem
 }
@@ -76,6 +77,12 @@ func (sb SplitButton) Create(builder *Builder) error {
 			return err
 		}
 
+		if sb.DefaultAction != nil {
+			if err := w.SetDefaultAction(sb.DefaultAction); err != nil {
+				return err
+			}
+		}
+
 		if sb.OnClicked != nil {
 			w.Clicked().Attach(sb.OnClicked)
 		}
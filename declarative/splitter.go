@@ -53,8 +53,10 @@ type HSplitter struct {
 
 	// Splitter
 
-	AssignTo    **walk.Splitter
-	HandleWidth int
+	AssignTo     **walk.Splitter
+	HandleWidth  int
+	Proportional bool
+	SnapPoints   []float64
 }
 
 func (s HSplitter) Create(builder *Builder) error {
@@ -80,6 +82,9 @@ func (s HSplitter) Create(builder *Builder) error {
 			}
 		}
 
+		w.SetProportional(s.Proportional)
+		w.SetSnapPoints(s.SnapPoints)
+
 		return nil
 	})
 }
@@ -124,8 +129,10 @@ type VSplitter struct {
 
 	// Splitter
 
-	AssignTo    **walk.Splitter
-	HandleWidth int
+	AssignTo     **walk.Splitter
+	HandleWidth  int
+	Proportional bool
+	SnapPoints   []float64
 }
 
 func (s VSplitter) Create(builder *Builder) error {
@@ -151,6 +158,9 @@ func (s VSplitter) Create(builder *Builder) error {
 			}
 		}
 
+		w.SetProportional(s.Proportional)
+		w.SetSnapPoints(s.SnapPoints)
+
 		return nil
 	})
 }
@@ -58,15 +58,20 @@ type TableView struct {
 	ColumnsSizable              Property
 	CustomHeaderHeight          int
 	CustomRowHeight             int
+	FrozenColumnCount           int
 	ItemStateChangedEventDelay  int
 	HeaderHidden                bool
 	LastColumnStretched         bool
 	Model                       interface{}
 	MultiSelection              bool
 	NotSortableByHeaderClick    bool
+	OnCellEditCommitted         walk.CellEditEventHandler
 	OnCurrentIndexChanged       walk.EventHandler
+	OnFilterChanged             walk.EventHandler
+	OnGroupTaskLinkClicked      walk.StringEventHandler
 	OnItemActivated             walk.EventHandler
 	OnSelectedIndexesChanged    walk.EventHandler
+	Reorderable                 bool
 	SelectionHiddenWithoutFocus bool
 	StyleCell                   func(style *walk.CellStyle)
 }
@@ -160,6 +165,12 @@ func (tv TableView) Create(builder *Builder) error {
 			w.SetCellStyler(styler)
 		}
 
+		if tv.FrozenColumnCount > 0 {
+			if err := w.SetFrozenColumnCount(tv.FrozenColumnCount); err != nil {
+				return err
+			}
+		}
+
 		w.SetAlternatingRowBG(tv.AlternatingRowBG)
 		w.SetCheckBoxes(tv.CheckBoxes)
 		w.SetItemStateChangedEventDelay(tv.ItemStateChangedEventDelay)
@@ -169,6 +180,7 @@ func (tv TableView) Create(builder *Builder) error {
 		if err := w.SetMultiSelection(tv.MultiSelection); err != nil {
 			return err
 		}
+		w.SetReorderable(tv.Reorderable)
 		if err := w.SetSelectionHiddenWithoutFocus(tv.SelectionHiddenWithoutFocus); err != nil {
 			return err
 		}
@@ -179,12 +191,21 @@ func (tv TableView) Create(builder *Builder) error {
 		if tv.OnCurrentIndexChanged != nil {
 			w.CurrentIndexChanged().Attach(tv.OnCurrentIndexChanged)
 		}
+		if tv.OnCellEditCommitted != nil {
+			w.CellEditCommitted().Attach(tv.OnCellEditCommitted)
+		}
 		if tv.OnSelectedIndexesChanged != nil {
 			w.SelectedIndexesChanged().Attach(tv.OnSelectedIndexesChanged)
 		}
 		if tv.OnItemActivated != nil {
 			w.ItemActivated().Attach(tv.OnItemActivated)
 		}
+		if tv.OnFilterChanged != nil {
+			w.FilterChanged().Attach(tv.OnFilterChanged)
+		}
+		if tv.OnGroupTaskLinkClicked != nil {
+			w.GroupTaskLinkClicked().Attach(tv.OnGroupTaskLinkClicked)
+		}
 
 		return nil
 	})
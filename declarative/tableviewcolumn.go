@@ -29,6 +29,7 @@ type TableViewColumn struct {
 	Width      int
 	Hidden     bool
 	Frozen     bool
+	Editor     walk.CellEditor
 	StyleCell  func(style *walk.CellStyle)
 	LessFunc   func(i, j int) bool
 	FormatFunc func(value interface{}) string
@@ -64,6 +65,7 @@ func (tvc TableViewColumn) Create(tv *walk.TableView) error {
 	}
 	w.SetLessFunc(tvc.LessFunc)
 	w.SetFormatFunc(tvc.FormatFunc)
+	w.SetEditor(tvc.Editor)
 
 	return tv.Columns().Add(w)
 }
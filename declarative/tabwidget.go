@@ -10,6 +10,15 @@ import (
 	"github.com/tailscale/walk"
 )
 
+type TabPosition int
+
+const (
+	TabPositionTop    = TabPosition(walk.TabPositionTop)
+	TabPositionBottom = TabPosition(walk.TabPositionBottom)
+	TabPositionLeft   = TabPosition(walk.TabPositionLeft)
+	TabPositionRight  = TabPosition(walk.TabPositionRight)
+)
+
 type TabWidget struct {
 	// Window
 
@@ -51,8 +60,16 @@ type TabWidget struct {
 	AssignTo              **walk.TabWidget
 	ContentMargins        Margins
 	ContentMarginsZero    bool
+	NewTabButtonVisible   bool
 	OnCurrentIndexChanged walk.EventHandler
+	OnNewTabRequested     walk.EventHandler
+	OnPageClosing         walk.TabPageClosingEventHandler
+	OnPageMoved           walk.IntRangeEventHandler
 	Pages                 []TabPage
+	TabPosition           TabPosition
+	TabStyler             walk.TabStyler
+	TabsClosable          bool
+	TabsMovable           bool
 }
 
 func (tw TabWidget) Create(builder *Builder) error {
@@ -66,6 +83,21 @@ func (tw TabWidget) Create(builder *Builder) error {
 	}
 
 	return builder.InitWidget(tw, w, func() error {
+		if err := w.SetTabsClosable(tw.TabsClosable); err != nil {
+			return err
+		}
+		w.SetTabsMovable(tw.TabsMovable)
+		w.SetNewTabButtonVisible(tw.NewTabButtonVisible)
+
+		if err := w.SetTabPosition(walk.TabPosition(tw.TabPosition)); err != nil {
+			return err
+		}
+		if tw.TabStyler != nil {
+			if err := w.SetTabStyler(tw.TabStyler); err != nil {
+				return err
+			}
+		}
+
 		for _, tp := range tw.Pages {
 			var wp *walk.TabPage
 			if tp.AssignTo == nil {
@@ -88,6 +120,15 @@ func (tw TabWidget) Create(builder *Builder) error {
 		if tw.OnCurrentIndexChanged != nil {
 			w.CurrentIndexChanged().Attach(tw.OnCurrentIndexChanged)
 		}
+		if tw.OnNewTabRequested != nil {
+			w.NewTabRequested().Attach(tw.OnNewTabRequested)
+		}
+		if tw.OnPageClosing != nil {
+			w.PageClosing().Attach(tw.OnPageClosing)
+		}
+		if tw.OnPageMoved != nil {
+			w.PageMoved().Attach(tw.OnPageMoved)
+		}
 
 		return nil
 	})
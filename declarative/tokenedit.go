@@ -0,0 +1,96 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package declarative
+
+import (
+	"github.com/tailscale/walk"
+)
+
+type TokenEdit struct {
+	// Window
+
+	Accessibility      Accessibility
+	Background         Brush
+	ContextMenuItems   []MenuItem
+	DoubleBuffering    bool
+	Enabled            Property
+	Font               Font
+	MaxSize            Size
+	MinSize            Size
+	Name               string
+	OnBoundsChanged    walk.EventHandler
+	OnKeyDown          walk.KeyEventHandler
+	OnKeyPress         walk.KeyEventHandler
+	OnKeyUp            walk.KeyEventHandler
+	OnMouseDown        walk.MouseEventHandler
+	OnMouseMove        walk.MouseEventHandler
+	OnMouseUp          walk.MouseEventHandler
+	OnSizeChanged      walk.EventHandler
+	Persistent         bool
+	RightToLeftReading bool
+	ToolTipText        Property
+	Visible            Property
+
+	// Widget
+
+	Alignment          Alignment2D
+	AlwaysConsumeSpace bool
+	Column             int
+	ColumnSpan         int
+	GraphicsEffects    []walk.WidgetGraphicsEffect
+	Row                int
+	RowSpan            int
+	StretchFactor      int
+
+	// TokenEdit
+
+	AssignTo        **walk.TokenEdit
+	CueBanner       string
+	OnTokensChanged walk.EventHandler
+	Separators      string
+	Suggestions     []string
+	Tokens          Property
+	Validator       walk.Validator
+}
+
+func (te TokenEdit) Create(builder *Builder) error {
+	w, err := walk.NewTokenEdit(builder.Parent())
+	if err != nil {
+		return err
+	}
+
+	if te.AssignTo != nil {
+		*te.AssignTo = w
+	}
+
+	return builder.InitWidget(te, w, func() error {
+		if te.CueBanner != "" {
+			if err := w.SetCueBanner(te.CueBanner); err != nil {
+				return err
+			}
+		}
+
+		if te.Separators != "" {
+			w.SetSeparators(te.Separators)
+		}
+
+		if te.Suggestions != nil {
+			w.SetSuggestions(te.Suggestions)
+		}
+
+		if te.Validator != nil {
+			w.SetValidator(te.Validator)
+		}
+
+		if te.OnTokensChanged != nil {
+			w.TokensChanged().Attach(te.OnTokensChanged)
+		}
+
+		return nil
+	})
+}
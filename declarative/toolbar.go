@@ -57,12 +57,14 @@ type ToolBar struct {
 
 	// ToolBar
 
-	Actions     []*walk.Action // Deprecated, use Items instead
-	AssignTo    **walk.ToolBar
-	ButtonStyle ToolBarButtonStyle
-	Items       []MenuItem
-	MaxTextRows int
-	Orientation Orientation
+	Actions         []*walk.Action // Deprecated, use Items instead
+	AssignTo        **walk.ToolBar
+	ButtonStyle     ToolBarButtonStyle
+	Customizable    bool
+	Items           []MenuItem
+	MaxTextRows     int
+	Orientation     Orientation
+	OverflowEnabled bool
 }
 
 func (tb ToolBar) Create(builder *Builder) error {
@@ -90,6 +92,16 @@ func (tb ToolBar) Create(builder *Builder) error {
 			return err
 		}
 
+		if err := w.SetCustomizable(tb.Customizable); err != nil {
+			return err
+		}
+
+		if tb.OverflowEnabled {
+			if err := w.SetOverflowEnabled(true); err != nil {
+				return err
+			}
+		}
+
 		if len(tb.Items) > 0 {
 			builder.deferBuildActions(w.Actions(), tb.Items)
 		} else {
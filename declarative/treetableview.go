@@ -0,0 +1,102 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package declarative
+
+import (
+	"github.com/tailscale/walk"
+)
+
+type TreeTableView struct {
+	// Window
+
+	Accessibility      Accessibility
+	Background         Brush
+	ContextMenuItems   []MenuItem
+	DoubleBuffering    bool
+	Enabled            Property
+	Font               Font
+	MaxSize            Size
+	MinSize            Size
+	Name               string
+	OnBoundsChanged    walk.EventHandler
+	OnKeyDown          walk.KeyEventHandler
+	OnKeyPress         walk.KeyEventHandler
+	OnKeyUp            walk.KeyEventHandler
+	OnMouseDown        walk.MouseEventHandler
+	OnMouseMove        walk.MouseEventHandler
+	OnMouseUp          walk.MouseEventHandler
+	OnSizeChanged      walk.EventHandler
+	Persistent         bool
+	RightToLeftReading bool
+	ToolTipText        Property
+	Visible            Property
+
+	// Widget
+
+	Alignment          Alignment2D
+	AlwaysConsumeSpace bool
+	Column             int
+	ColumnSpan         int
+	GraphicsEffects    []walk.WidgetGraphicsEffect
+	Row                int
+	RowSpan            int
+	StretchFactor      int
+
+	// TreeTableView
+
+	AlternatingRowBG      bool
+	AssignTo              **walk.TreeTableView
+	Columns               []TableViewColumn
+	LastColumnStretched   bool
+	Model                 walk.TreeTableModel
+	OnCurrentIndexChanged walk.EventHandler
+	OnExpandedChanged     walk.TreeItemEventHandler
+	OnItemActivated       walk.EventHandler
+}
+
+func (tv TreeTableView) Create(builder *Builder) error {
+	w, err := walk.NewTreeTableView(builder.Parent())
+	if err != nil {
+		return err
+	}
+
+	if tv.AssignTo != nil {
+		*tv.AssignTo = w
+	}
+
+	return builder.InitWidget(tv, w, func() error {
+		for i := range tv.Columns {
+			if err := tv.Columns[i].Create(w.TableView); err != nil {
+				return err
+			}
+		}
+
+		if err := w.SetModel(tv.Model); err != nil {
+			return err
+		}
+
+		w.SetAlternatingRowBG(tv.AlternatingRowBG)
+		if err := w.SetLastColumnStretched(tv.LastColumnStretched); err != nil {
+			return err
+		}
+
+		if tv.OnCurrentIndexChanged != nil {
+			w.CurrentIndexChanged().Attach(tv.OnCurrentIndexChanged)
+		}
+
+		if tv.OnExpandedChanged != nil {
+			w.ExpandedChanged().Attach(tv.OnExpandedChanged)
+		}
+
+		if tv.OnItemActivated != nil {
+			w.ItemActivated().Attach(tv.OnItemActivated)
+		}
+
+		return nil
+	})
+}
@@ -49,11 +49,14 @@ type TreeView struct {
 	// TreeView
 
 	AssignTo             **walk.TreeView
+	CheckBoxes           bool
 	ItemHeight           int
 	Model                walk.TreeModel
+	OnCheckedChanged     walk.TreeItemEventHandler
 	OnCurrentItemChanged walk.EventHandler
 	OnExpandedChanged    walk.TreeItemEventHandler
 	OnItemActivated      walk.EventHandler
+	Reorderable          bool
 }
 
 func (tv TreeView) Create(builder *Builder) error {
@@ -71,10 +74,20 @@ func (tv TreeView) Create(builder *Builder) error {
 			w.SetItemHeight(w.IntFrom96DPI(tv.ItemHeight)) // VERIFY: Item height should resize on DPI change.
 		}
 
+		if err := w.SetCheckBoxes(tv.CheckBoxes); err != nil {
+			return err
+		}
+
 		if err := w.SetModel(tv.Model); err != nil {
 			return err
 		}
 
+		w.SetReorderable(tv.Reorderable)
+
+		if tv.OnCheckedChanged != nil {
+			w.CheckedChanged().Attach(tv.OnCheckedChanged)
+		}
+
 		if tv.OnCurrentItemChanged != nil {
 			w.CurrentItemChanged().Attach(tv.OnCurrentItemChanged)
 		}
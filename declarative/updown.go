@@ -0,0 +1,112 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package declarative
+
+import (
+	"github.com/tailscale/walk"
+)
+
+type UpDown struct {
+	// Window
+
+	Accessibility      Accessibility
+	Background         Brush
+	ContextMenuItems   []MenuItem
+	DoubleBuffering    bool
+	Enabled            Property
+	Font               Font
+	MaxSize            Size
+	MinSize            Size
+	Name               string
+	OnBoundsChanged    walk.EventHandler
+	OnKeyDown          walk.KeyEventHandler
+	OnKeyPress         walk.KeyEventHandler
+	OnKeyUp            walk.KeyEventHandler
+	OnMouseDown        walk.MouseEventHandler
+	OnMouseMove        walk.MouseEventHandler
+	OnMouseUp          walk.MouseEventHandler
+	OnSizeChanged      walk.EventHandler
+	Persistent         bool
+	RightToLeftReading bool
+	ToolTipText        Property
+	Visible            Property
+
+	// Widget
+
+	Alignment          Alignment2D
+	AlwaysConsumeSpace bool
+	Column             int
+	ColumnSpan         int
+	GraphicsEffects    []walk.WidgetGraphicsEffect
+	Row                int
+	RowSpan            int
+	StretchFactor      int
+
+	// UpDown
+
+	Accelerations  []walk.UpDownAcceleration
+	AssignTo       **walk.UpDown
+	Buddy          **walk.LineEdit
+	Hexadecimal    bool
+	Increment      int
+	MaxValue       int
+	MinValue       int
+	OnValueChanged walk.EventHandler
+	Value          Property
+	Wrap           bool
+}
+
+func (ud UpDown) Create(builder *Builder) error {
+	w, err := walk.NewUpDown(builder.Parent())
+	if err != nil {
+		return err
+	}
+
+	if ud.AssignTo != nil {
+		*ud.AssignTo = w
+	}
+
+	return builder.InitWidget(ud, w, func() error {
+		if ud.MinValue != 0 || ud.MaxValue != 0 {
+			if err := w.SetRange(ud.MinValue, ud.MaxValue); err != nil {
+				return err
+			}
+		}
+
+		if len(ud.Accelerations) > 0 {
+			if err := w.SetAccelerations(ud.Accelerations); err != nil {
+				return err
+			}
+		} else if ud.Increment > 0 {
+			if err := w.SetIncrement(ud.Increment); err != nil {
+				return err
+			}
+		}
+
+		if err := w.SetHexadecimal(ud.Hexadecimal); err != nil {
+			return err
+		}
+
+		if err := w.SetWrap(ud.Wrap); err != nil {
+			return err
+		}
+
+		if ud.Buddy != nil {
+			// The buddy may be declared after the UpDown.
+			builder.Defer(func() error {
+				return w.SetBuddy(*ud.Buddy)
+			})
+		}
+
+		if ud.OnValueChanged != nil {
+			w.ValueChanged().Attach(ud.OnValueChanged)
+		}
+
+		return nil
+	})
+}
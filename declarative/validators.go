@@ -35,6 +35,14 @@ func (re Regexp) Create() (walk.Validator, error) {
 	return walk.NewRegexpValidator(re.Pattern)
 }
 
+type NetworkAddress struct {
+	Kind walk.NetworkAddressKind
+}
+
+func (na NetworkAddress) Create() (walk.Validator, error) {
+	return walk.NewNetworkAddressValidator(na.Kind), nil
+}
+
 type SelRequired struct {
 }
 
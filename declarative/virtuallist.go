@@ -0,0 +1,102 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package declarative
+
+import (
+	"errors"
+
+	"github.com/tailscale/walk"
+)
+
+type VirtualList struct {
+	// Window
+
+	Accessibility      Accessibility
+	Background         Brush
+	ContextMenuItems   []MenuItem
+	DoubleBuffering    bool
+	Enabled            Property
+	Font               Font
+	MaxSize            Size
+	MinSize            Size
+	Name               string
+	OnBoundsChanged    walk.EventHandler
+	OnKeyDown          walk.KeyEventHandler
+	OnKeyPress         walk.KeyEventHandler
+	OnKeyUp            walk.KeyEventHandler
+	OnMouseDown        walk.MouseEventHandler
+	OnMouseMove        walk.MouseEventHandler
+	OnMouseUp          walk.MouseEventHandler
+	OnSizeChanged      walk.EventHandler
+	Persistent         bool
+	RightToLeftReading bool
+	ToolTipText        Property
+	Visible            Property
+
+	// Widget
+
+	Alignment          Alignment2D
+	AlwaysConsumeSpace bool
+	Column             int
+	ColumnSpan         int
+	GraphicsEffects    []walk.WidgetGraphicsEffect
+	Row                int
+	RowSpan            int
+	StretchFactor      int
+
+	// Container
+
+	DataBinder DataBinder
+
+	// VirtualList
+
+	AssignTo    **walk.VirtualList
+	BindRow     walk.VirtualListRowBinder
+	ItemCount   int
+	RowHeight   int
+	RowTemplate func() Widget
+}
+
+func (vl VirtualList) Create(builder *Builder) error {
+	if vl.RowTemplate == nil || vl.BindRow == nil {
+		return errors.New("VirtualList requires RowTemplate and BindRow")
+	}
+
+	createRow := func(parent walk.Container) (walk.Widget, error) {
+		index := parent.Children().Len()
+
+		if err := vl.RowTemplate().Create(NewBuilder(parent)); err != nil {
+			return nil, err
+		}
+
+		if parent.Children().Len() <= index {
+			return nil, errors.New("VirtualList RowTemplate did not create a widget")
+		}
+
+		return parent.Children().At(index), nil
+	}
+
+	w, err := walk.NewVirtualList(builder.Parent(), createRow, vl.BindRow)
+	if err != nil {
+		return err
+	}
+
+	if vl.AssignTo != nil {
+		*vl.AssignTo = w
+	}
+
+	return builder.InitWidget(vl, w, func() error {
+		if vl.RowHeight > 0 {
+			if err := w.SetRowHeight(vl.RowHeight); err != nil {
+				return err
+			}
+		}
+
+		return w.SetItemCount(vl.ItemCount)
+	})
+}
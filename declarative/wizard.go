@@ -0,0 +1,229 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package declarative
+
+import (
+	"errors"
+
+	"github.com/tailscale/walk"
+)
+
+type Wizard struct {
+	// Window
+
+	Accessibility      Accessibility
+	Background         Brush
+	ContextMenuItems   []MenuItem
+	DoubleBuffering    bool
+	Enabled            Property
+	Font               Font
+	MaxSize            Size
+	MinSize            Size
+	Name               string
+	OnBoundsChanged    walk.EventHandler
+	OnKeyDown          walk.KeyEventHandler
+	OnKeyPress         walk.KeyEventHandler
+	OnKeyUp            walk.KeyEventHandler
+	OnMouseDown        walk.MouseEventHandler
+	OnMouseMove        walk.MouseEventHandler
+	OnMouseUp          walk.MouseEventHandler
+	OnSizeChanged      walk.EventHandler
+	RightToLeftLayout  bool
+	RightToLeftReading bool
+
+	// Form
+
+	Expressions   func() map[string]walk.Expression
+	Functions     map[string]func(args ...interface{}) (interface{}, error)
+	Icon          Property
+	Title         Property
+	Size          Size
+	SizeToContent bool
+
+	// Wizard
+
+	AssignTo             **walk.Wizard
+	Glyph                interface{}
+	OnCurrentPageChanged walk.EventHandler
+	Pages                []WizardPage
+	TaskDialogStyle      bool
+}
+
+func (wz Wizard) Create(owner walk.Form) error {
+	w, err := walk.NewWizard(owner)
+	if err != nil {
+		return err
+	}
+
+	if wz.AssignTo != nil {
+		*wz.AssignTo = w
+	}
+
+	fi := formInfo{
+		// Window
+		Background:         wz.Background,
+		ContextMenuItems:   wz.ContextMenuItems,
+		DoubleBuffering:    wz.DoubleBuffering,
+		Enabled:            wz.Enabled,
+		Font:               wz.Font,
+		MaxSize:            wz.MaxSize,
+		MinSize:            wz.MinSize,
+		Name:               wz.Name,
+		OnBoundsChanged:    wz.OnBoundsChanged,
+		OnKeyDown:          wz.OnKeyDown,
+		OnKeyPress:         wz.OnKeyPress,
+		OnKeyUp:            wz.OnKeyUp,
+		OnMouseDown:        wz.OnMouseDown,
+		OnMouseMove:        wz.OnMouseMove,
+		OnMouseUp:          wz.OnMouseUp,
+		OnSizeChanged:      wz.OnSizeChanged,
+		RightToLeftReading: wz.RightToLeftReading,
+		Accessibility:      wz.Accessibility,
+
+		// Form
+		Icon:  wz.Icon,
+		Title: wz.Title,
+	}
+
+	builder := NewBuilder(nil)
+
+	w.SetSuspended(true)
+	builder.Defer(func() error {
+		w.SetSuspended(false)
+		return nil
+	})
+
+	if err := w.SetRightToLeftLayout(wz.RightToLeftLayout); err != nil {
+		return err
+	}
+
+	w.SetSizeToContent(wz.SizeToContent)
+
+	return builder.InitWidget(fi, w, func() error {
+		if wz.Size.Width > 0 && wz.Size.Height > 0 {
+			if err := w.SetSize(wz.Size.toW()); err != nil {
+				return err
+			}
+		}
+
+		if err := w.SetTaskDialogStyle(wz.TaskDialogStyle); err != nil {
+			return err
+		}
+
+		if wz.Glyph != nil {
+			glyph, err := walk.ImageFrom(wz.Glyph)
+			if err != nil {
+				return err
+			}
+
+			if err := w.SetGlyph(glyph); err != nil {
+				return err
+			}
+		}
+
+		for _, page := range wz.Pages {
+			if err := page.Create(builder); err != nil {
+				return err
+			}
+		}
+
+		if wz.Expressions != nil {
+			for name, expr := range wz.Expressions() {
+				builder.expressions[name] = expr
+			}
+		}
+		if wz.Functions != nil {
+			for name, fn := range wz.Functions {
+				builder.functions[name] = fn
+			}
+		}
+
+		if wz.OnCurrentPageChanged != nil {
+			w.CurrentPageChanged().Attach(wz.OnCurrentPageChanged)
+		}
+
+		return nil
+	})
+}
+
+// WizardPage is a page of a Wizard. It can only be created as one of its
+// Pages.
+type WizardPage struct {
+	// Window
+
+	Accessibility      Accessibility
+	Background         Brush
+	ContextMenuItems   []MenuItem
+	DoubleBuffering    bool
+	Enabled            Property
+	Font               Font
+	Name               string
+	OnBoundsChanged    walk.EventHandler
+	OnKeyDown          walk.KeyEventHandler
+	OnKeyPress         walk.KeyEventHandler
+	OnKeyUp            walk.KeyEventHandler
+	OnMouseDown        walk.MouseEventHandler
+	OnMouseMove        walk.MouseEventHandler
+	OnMouseUp          walk.MouseEventHandler
+	OnSizeChanged      walk.EventHandler
+	RightToLeftReading bool
+
+	// Container
+
+	Children   []Widget
+	DataBinder DataBinder
+	Layout     Layout
+
+	// WizardPage
+
+	AssignTo          **walk.WizardPage
+	Description       string
+	NextPage          func() *walk.WizardPage
+	OnCompleteChanged walk.EventHandler
+	OnEntered         walk.EventHandler
+	OnValidating      walk.CancelEventHandler
+	Title             string
+}
+
+func (wp WizardPage) Create(builder *Builder) error {
+	wz, ok := builder.Parent().(*walk.Wizard)
+	if !ok {
+		return errors.New("WizardPage.Create: a WizardPage must be one of the Pages of a Wizard.")
+	}
+
+	w, err := wz.AddPage(wp.Title)
+	if err != nil {
+		return err
+	}
+
+	if wp.AssignTo != nil {
+		*wp.AssignTo = w
+	}
+
+	return builder.InitWidget(wp, w, func() error {
+		if err := w.SetDescription(wp.Description); err != nil {
+			return err
+		}
+
+		if wp.NextPage != nil {
+			w.SetNextPageFunc(wp.NextPage)
+		}
+
+		if wp.OnCompleteChanged != nil {
+			w.CompleteChanged().Attach(wp.OnCompleteChanged)
+		}
+		if wp.OnEntered != nil {
+			w.Entered().Attach(wp.OnEntered)
+		}
+		if wp.OnValidating != nil {
+			w.Validating().Attach(wp.OnValidating)
+		}
+
+		return nil
+	})
+}
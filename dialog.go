@@ -48,6 +48,9 @@ type Dialog struct {
 	defaultButton        *PushButton
 	cancelButton         *PushButton
 	centerInOwnerWhenRun bool
+	sheet                bool
+	async                *asyncModal
+	id2Control           map[int]Widget
 }
 
 func NewDialog(owner Form) (*Dialog, error) {
@@ -171,16 +174,9 @@ func (dlg *Dialog) Show() {
 			size = dlg.SizePixels()
 		}
 
-		if dlg.owner != nil {
-			ob := dlg.owner.BoundsPixels()
-
+		if dlg.owner != nil && dlg.startupPosition == StartupPositionDefault {
 			if dlg.centerInOwnerWhenRun {
-				dlg.SetBoundsPixels(fitRectToScreen(dlg.hWnd, Rectangle{
-					ob.X + (ob.Width-size.Width)/2,
-					ob.Y + (ob.Height-size.Height)/2,
-					size.Width,
-					size.Height,
-				}))
+				dlg.SetBoundsPixels(centerRectOn(dlg.owner.BoundsPixels(), size))
 			}
 		} else {
 			b := dlg.BoundsPixels()
@@ -253,6 +249,19 @@ func (dlg *Dialog) WndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) ui
 				}
 			}
 		}
+
+	case win.WM_CLOSE:
+		ret := dlg.FormBase.WndProc(hwnd, msg, wParam, lParam)
+		if dlg.async != nil && !dlg.started {
+			dlg.finishModalAsync(true)
+		}
+		return ret
+
+	case win.WM_DESTROY:
+		// The owner may be destroyed while the Dialog is still shown.
+		if dlg.async != nil {
+			dlg.finishModalAsync(false)
+		}
 	}
 
 	return dlg.FormBase.WndProc(hwnd, msg, wParam, lParam)
@@ -0,0 +1,216 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"github.com/tailscale/win"
+)
+
+const sheetOverlayWindowClass = `\o/ Walk_SheetOverlay_Class \o/`
+
+func init() {
+	AppendToWalkInit(func() {
+		MustRegisterWindowClass(sheetOverlayWindowClass)
+	})
+}
+
+// asyncModal is the state of a Dialog shown using ShowModalAsync.
+type asyncModal struct {
+	done            func(result int)
+	owner           Form
+	ownerWasEnabled bool
+	overlay         *sheetOverlay
+}
+
+// Sheet returns whether ShowModalAsync dims the client area of the owner.
+func (dlg *Dialog) Sheet() bool {
+	return dlg.sheet
+}
+
+// SetSheet sets whether ShowModalAsync dims the client area of the owner
+// while the Dialog is shown, to make clear that the owner cannot be used.
+func (dlg *Dialog) SetSheet(sheet bool) {
+	dlg.sheet = sheet
+}
+
+// ShowModalAsync shows the Dialog modal to owner and returns immediately.
+// Unlike Run, it does not start a nested message loop.
+//
+// The owner is disabled until the Dialog is closed, after which the Dialog is
+// disposed and done is called with its result. If owner is nil, the current
+// owner of the Dialog is used.
+func (dlg *Dialog) ShowModalAsync(owner Form, done func(result int)) error {
+	if dlg.async != nil {
+		return newError("dialog is shown already")
+	}
+
+	if owner != nil && owner != dlg.owner {
+		if err := dlg.SetOwner(owner); err != nil {
+			return err
+		}
+		dlg.centerInOwnerWhenRun = true
+	}
+
+	am := &asyncModal{done: done, owner: dlg.owner}
+
+	if am.owner != nil {
+		am.ownerWasEnabled = !win.EnableWindow(am.owner.Handle(), false)
+
+		if dlg.sheet {
+			overlay, err := newSheetOverlay(am.owner)
+			if err != nil {
+				if am.ownerWasEnabled {
+					win.EnableWindow(am.owner.Handle(), true)
+				}
+				return err
+			}
+			am.overlay = overlay
+		}
+	}
+
+	dlg.async = am
+	App().asyncModals = append(App().asyncModals, dlg)
+
+	dlg.Show()
+
+	return nil
+}
+
+// finishModalAsync ends the modal state of a Dialog shown using
+// ShowModalAsync. The owner is enabled again before the Dialog is hidden, so
+// that it is activated instead of some other application.
+func (dlg *Dialog) finishModalAsync(dispose bool) {
+	am := dlg.async
+	dlg.async = nil
+
+	app := App()
+	for i, modal := range app.asyncModals {
+		if modal == Modal(dlg) {
+			app.asyncModals = append(app.asyncModals[:i], app.asyncModals[i+1:]...)
+			break
+		}
+	}
+
+	if am.overlay != nil {
+		am.overlay.Dispose()
+	}
+
+	if am.owner != nil && am.ownerWasEnabled {
+		win.EnableWindow(am.owner.Handle(), true)
+	}
+
+	if dispose {
+		win.ShowWindow(dlg.hWnd, win.SW_HIDE)
+	}
+
+	result := dlg.result
+
+	app.Synchronize(func() {
+		if dispose {
+			dlg.Dispose()
+		}
+
+		if am.done != nil {
+			am.done(result)
+		}
+	})
+}
+
+// sheetOverlay is a translucent layered window that dims the client area of
+// the owner of a Dialog shown as sheet.
+type sheetOverlay struct {
+	WindowBase
+	owner                Form
+	boundsChangedHandle  int
+	visibleChangedHandle int
+}
+
+func newSheetOverlay(owner Form) (*sheetOverlay, error) {
+	so := &sheetOverlay{owner: owner}
+
+	if err := InitWindow(
+		so,
+		nil,
+		sheetOverlayWindowClass,
+		win.WS_POPUP|win.WS_DISABLED,
+		win.WS_EX_LAYERED|win.WS_EX_NOACTIVATE|win.WS_EX_TOOLWINDOW); err != nil {
+		return nil, err
+	}
+
+	// Owned windows stay above their owner and are hidden with it.
+	win.SetWindowLongPtr(so.hWnd, win.GWL_HWNDPARENT, uintptr(owner.Handle()))
+
+	setLayeredWindowAttributes(so.hWnd, 0, 0x60, _LWA_ALPHA)
+
+	ob := owner.AsWindowBase()
+	so.boundsChangedHandle = ob.BoundsChanged().Attach(so.update)
+	so.visibleChangedHandle = ob.VisibleChanged().Attach(so.update)
+
+	so.update()
+
+	return so, nil
+}
+
+func (so *sheetOverlay) Dispose() {
+	if so.hWnd != 0 {
+		ob := so.owner.AsWindowBase()
+		ob.BoundsChanged().Detach(so.boundsChangedHandle)
+		ob.VisibleChanged().Detach(so.visibleChangedHandle)
+	}
+
+	so.WindowBase.Dispose()
+}
+
+func (so *sheetOverlay) update() {
+	if so.hWnd == 0 {
+		return
+	}
+
+	if !so.owner.Visible() || so.owner.IsDisposed() {
+		win.ShowWindow(so.hWnd, win.SW_HIDE)
+		return
+	}
+
+	ohwnd := so.owner.Handle()
+
+	var rc win.RECT
+	win.GetClientRect(ohwnd, &rc)
+
+	pt := win.POINT{X: rc.Left, Y: rc.Top}
+	win.ClientToScreen(ohwnd, &pt)
+
+	win.SetWindowPos(
+		so.hWnd,
+		0,
+		pt.X,
+		pt.Y,
+		rc.Right-rc.Left,
+		rc.Bottom-rc.Top,
+		win.SWP_NOACTIVATE|win.SWP_NOZORDER|win.SWP_SHOWWINDOW)
+}
+
+func (so *sheetOverlay) WndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) uintptr {
+	switch msg {
+	case win.WM_ERASEBKGND:
+		var rc win.RECT
+		win.GetClientRect(hwnd, &rc)
+		win.FillRect(win.HDC(wParam), &rc, win.HBRUSH(win.GetStockObject(win.BLACK_BRUSH)))
+		return 1
+
+	case win.WM_PAINT:
+		var ps win.PAINTSTRUCT
+		win.BeginPaint(hwnd, &ps)
+		win.EndPaint(hwnd, &ps)
+		return 0
+
+	case win.WM_MOUSEACTIVATE:
+		return _MA_NOACTIVATE
+	}
+
+	return so.WindowBase.WndProc(hwnd, msg, wParam, lParam)
+}
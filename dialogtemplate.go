@@ -0,0 +1,539 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"encoding/binary"
+	"strings"
+	"syscall"
+	"unicode/utf16"
+	"unsafe"
+
+	"github.com/tailscale/win"
+)
+
+// dialogTemplate is a parsed DLGTEMPLATE or DLGTEMPLATEEX.
+type dialogTemplate struct {
+	style         uint32
+	exStyle       uint32
+	bounds        Rectangle // in dialog units
+	title         string
+	fontFamily    string
+	fontPointSize int
+	fontStyle     FontStyle
+	items         []dialogTemplateItem
+}
+
+// dialogTemplateItem is a parsed DLGITEMTEMPLATE or DLGITEMTEMPLATEEX.
+type dialogTemplateItem struct {
+	id      int
+	style   uint32
+	exStyle uint32
+	bounds  Rectangle // in dialog units
+	class   string
+	title   string
+}
+
+// dialogTemplateClasses maps the predefined class atoms of dialog templates
+// to class names.
+var dialogTemplateClasses = map[uint16]string{
+	0x0080: "BUTTON",
+	0x0081: "EDIT",
+	0x0082: "STATIC",
+	0x0083: "LISTBOX",
+	0x0084: "SCROLLBAR",
+	0x0085: "COMBOBOX",
+}
+
+// NewDialogFromResource creates a *Dialog from the dialog template resource
+// name of the executable. See NewDialogFromTemplate.
+func NewDialogFromResource(owner Form, name string) (*Dialog, error) {
+	return newDialogFromResource(owner, syscall.StringToUTF16Ptr(name))
+}
+
+// NewDialogFromResourceId creates a *Dialog from the dialog template resource
+// id of the executable. See NewDialogFromTemplate.
+func NewDialogFromResourceId(owner Form, id int) (*Dialog, error) {
+	return newDialogFromResource(owner, win.MAKEINTRESOURCE(uintptr(id)))
+}
+
+func newDialogFromResource(owner Form, res *uint16) (*Dialog, error) {
+	hModule := win.HMODULE(win.GetModuleHandle(nil))
+	if hModule == win.HMODULE(0) {
+		return nil, lastError("GetModuleHandle")
+	}
+
+	hres := win.FindResource(hModule, res, win.MAKEINTRESOURCE(5) /*RT_DIALOG*/)
+	if hres == win.HRSRC(0) {
+		return nil, lastError("FindResource")
+	}
+
+	size := win.SizeofResource(hModule, hres)
+	if size == 0 {
+		return nil, lastError("SizeofResource")
+	}
+
+	hResLoad := win.LoadResource(hModule, hres)
+	if hResLoad == win.HGLOBAL(0) {
+		return nil, lastError("LoadResource")
+	}
+
+	ptr := win.LockResource(hResLoad)
+	if ptr == 0 {
+		return nil, lastError("LockResource")
+	}
+
+	return NewDialogFromTemplate(owner, unsafe.Slice((*byte)(unsafe.Pointer(ptr)), size))
+}
+
+// NewDialogFromTemplate creates a *Dialog from a compiled dialog template, as
+// the resource compiler produces for the DIALOG and DIALOGEX statements of .rc
+// files, so existing dialog layouts can be reused.
+//
+// The controls of the template are created as walk widgets, which
+// ControlByID returns by their control ID:
+//
+//	BUTTON push buttons                 *PushButton
+//	BUTTON check boxes                  *CheckBox
+//	BUTTON radio buttons                *RadioButton
+//	EDIT with ES_MULTILINE              *TextEdit
+//	EDIT                                *LineEdit
+//	STATIC with text                    *Label
+//	LISTBOX                             *ListBox
+//	COMBOBOX                            *ComboBox
+//	msctls_progress32                   *ProgressBar
+//	msctls_trackbar32                   *Slider
+//
+// Controls of other classes, like group boxes and icons, are created as
+// plain widgets of their class.
+//
+// The controls keep the bounds of the template, using an AnchorLayout.
+// Clicking the buttons with the IDs IDOK and IDCANCEL accepts and cancels the
+// dialog, respectively.
+func NewDialogFromTemplate(owner Form, template []byte) (*Dialog, error) {
+	dt, err := parseDialogTemplate(template)
+	if err != nil {
+		return nil, err
+	}
+
+	var dlg *Dialog
+	if dt.style&win.WS_THICKFRAME != 0 {
+		dlg, err = NewDialog(owner)
+	} else {
+		dlg, err = NewDialogWithFixedSize(owner)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	succeeded := false
+	defer func() {
+		if !succeeded {
+			dlg.Dispose()
+		}
+	}()
+
+	if err := dlg.applyTemplate(dt); err != nil {
+		return nil, err
+	}
+
+	succeeded = true
+
+	return dlg, nil
+}
+
+// ControlByID returns the widget that was created for the control with id of
+// the template the *Dialog was created from, or nil if there is none.
+func (dlg *Dialog) ControlByID(id int) Widget {
+	return dlg.id2Control[id]
+}
+
+func (dlg *Dialog) applyTemplate(dt *dialogTemplate) error {
+	if err := dlg.SetTitle(dt.title); err != nil {
+		return err
+	}
+
+	if dt.fontFamily != "" {
+		font, err := NewFont(dt.fontFamily, dt.fontPointSize, dt.fontStyle)
+		if err != nil {
+			return err
+		}
+		dlg.SetFont(font)
+	}
+
+	layout := NewAnchorLayout()
+	if err := layout.SetMargins(Margins{}); err != nil {
+		return err
+	}
+	if err := dlg.SetLayout(layout); err != nil {
+		return err
+	}
+
+	dpi := dlg.DPI()
+
+	clientSize := dlg.dialogBaseUnitsToPixels(dt.bounds.Size())
+	if err := layout.SetDesignSize(SizeTo96DPI(clientSize, dpi)); err != nil {
+		return err
+	}
+	if err := dlg.SetClientSizePixels(clientSize); err != nil {
+		return err
+	}
+
+	dlg.id2Control = make(map[int]Widget)
+
+	for i := range dt.items {
+		item := &dt.items[i]
+
+		w, err := dlg.createTemplateControl(item)
+		if err != nil {
+			return err
+		}
+
+		if s, ok := w.(interface{ SetText(string) error }); ok && item.title != "" {
+			if err := s.SetText(item.title); err != nil {
+				return err
+			}
+		}
+
+		if item.style&win.WS_DISABLED != 0 {
+			w.SetEnabled(false)
+		}
+		if item.style&win.WS_VISIBLE == 0 {
+			w.SetVisible(false)
+		}
+
+		location := dlg.dialogBaseUnitsToPixels(Size{item.bounds.X, item.bounds.Y})
+		size := dlg.dialogBaseUnitsToPixels(item.bounds.Size())
+		bounds := RectangleTo96DPI(Rectangle{location.Width, location.Height, size.Width, size.Height}, dpi)
+
+		// Widgets must not grow beyond the template bounds to fit their text.
+		if err := w.SetMinMaxSize(Size{}, bounds.Size()); err != nil {
+			return err
+		}
+		if err := layout.SetItemBounds(w, bounds); err != nil {
+			return err
+		}
+
+		if item.id != -1 {
+			dlg.id2Control[item.id] = w
+		}
+	}
+
+	return nil
+}
+
+func (dlg *Dialog) createTemplateControl(item *dialogTemplateItem) (Widget, error) {
+	switch {
+	case strings.EqualFold(item.class, "BUTTON"):
+		switch item.style & _BS_TYPEMASK {
+		case win.BS_PUSHBUTTON, win.BS_DEFPUSHBUTTON:
+			return dlg.createTemplatePushButton(item)
+
+		case win.BS_CHECKBOX, win.BS_AUTOCHECKBOX:
+			return NewCheckBox(dlg)
+
+		case win.BS_3STATE, win.BS_AUTO3STATE:
+			cb, err := NewCheckBox(dlg)
+			if err != nil {
+				return nil, err
+			}
+			if err := cb.SetTristate(true); err != nil {
+				return nil, err
+			}
+			return cb, nil
+
+		case win.BS_RADIOBUTTON, win.BS_AUTORADIOBUTTON:
+			return NewRadioButton(dlg)
+		}
+
+	case strings.EqualFold(item.class, "EDIT"):
+		if item.style&win.ES_MULTILINE != 0 {
+			te, err := NewTextEdit(dlg)
+			if err != nil {
+				return nil, err
+			}
+			if err := te.SetReadOnly(item.style&win.ES_READONLY != 0); err != nil {
+				return nil, err
+			}
+			return te, nil
+		}
+
+		le, err := NewLineEdit(dlg)
+		if err != nil {
+			return nil, err
+		}
+		le.SetPasswordMode(item.style&win.ES_PASSWORD != 0)
+		if err := le.SetReadOnly(item.style&win.ES_READONLY != 0); err != nil {
+			return nil, err
+		}
+		return le, nil
+
+	case strings.EqualFold(item.class, "STATIC"):
+		var alignment Alignment1D
+		switch item.style & win.SS_TYPEMASK {
+		case win.SS_LEFT, win.SS_LEFTNOWORDWRAP, win.SS_SIMPLE:
+			alignment = AlignNear
+
+		case win.SS_CENTER:
+			alignment = AlignCenter
+
+		case win.SS_RIGHT:
+			alignment = AlignFar
+
+		default:
+			return newDialogTemplateControl(dlg, item)
+		}
+
+		l, err := NewLabel(dlg)
+		if err != nil {
+			return nil, err
+		}
+		if err := l.SetTextAlignment(alignment); err != nil {
+			return nil, err
+		}
+		return l, nil
+
+	case strings.EqualFold(item.class, "LISTBOX"):
+		return NewListBoxWithStyle(dlg, item.style&(win.LBS_EXTENDEDSEL|win.LBS_MULTIPLESEL))
+
+	case strings.EqualFold(item.class, "COMBOBOX"):
+		if item.style&win.CBS_DROPDOWNLIST == win.CBS_DROPDOWNLIST {
+			return NewDropDownBox(dlg)
+		}
+		return NewComboBox(dlg)
+
+	case strings.EqualFold(item.class, "msctls_progress32"):
+		return NewProgressBar(dlg)
+
+	case strings.EqualFold(item.class, "msctls_trackbar32"):
+		if item.style&win.TBS_VERT != 0 {
+			return NewSliderWithOrientation(dlg, Vertical)
+		}
+		return NewSlider(dlg)
+	}
+
+	return newDialogTemplateControl(dlg, item)
+}
+
+func (dlg *Dialog) createTemplatePushButton(item *dialogTemplateItem) (*PushButton, error) {
+	pb, err := NewPushButton(dlg)
+	if err != nil {
+		return nil, err
+	}
+
+	switch item.id {
+	case win.IDOK:
+		pb.Clicked().Attach(dlg.Accept)
+
+	case win.IDCANCEL:
+		pb.Clicked().Attach(dlg.Cancel)
+		if err := dlg.SetCancelButton(pb); err != nil {
+			return nil, err
+		}
+	}
+
+	if item.style&_BS_TYPEMASK == win.BS_DEFPUSHBUTTON {
+		if err := dlg.SetDefaultButton(pb); err != nil {
+			return nil, err
+		}
+	}
+
+	return pb, nil
+}
+
+// dialogTemplateControl is a control of a dialog template that has no walk
+// widget counterpart.
+type dialogTemplateControl struct {
+	WidgetBase
+}
+
+func newDialogTemplateControl(parent Container, item *dialogTemplateItem) (*dialogTemplateControl, error) {
+	c := new(dialogTemplateControl)
+
+	if err := InitWidget(
+		c,
+		parent,
+		item.class,
+		item.style|win.WS_VISIBLE,
+		item.exStyle); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+func (c *dialogTemplateControl) CreateLayoutItem(ctx *LayoutContext) LayoutItem {
+	return NewGreedyLayoutItem()
+}
+
+// parseDialogTemplate parses data as DLGTEMPLATEEX, or as DLGTEMPLATE if it
+// does not start with the signature of the former.
+func parseDialogTemplate(data []byte) (*dialogTemplate, error) {
+	r := &dialogTemplateReader{data: data}
+
+	dt := new(dialogTemplate)
+
+	ex := len(data) >= 4 && binary.LittleEndian.Uint16(data[2:]) == 0xFFFF
+	if ex {
+		if version := r.uint16(); version != 1 {
+			return nil, newError("unsupported dialog template version")
+		}
+		r.uint16() // signature
+		r.uint32() // help ID
+		dt.exStyle = r.uint32()
+		dt.style = r.uint32()
+	} else {
+		dt.style = r.uint32()
+		dt.exStyle = r.uint32()
+	}
+
+	count := int(r.uint16())
+	dt.bounds = r.rectangle()
+
+	r.sz() // menu
+	r.sz() // window class
+	dt.title = r.sz()
+
+	if dt.style&win.DS_SETFONT != 0 {
+		dt.fontPointSize = int(r.uint16())
+		if ex {
+			if r.uint16() >= win.FW_BOLD {
+				dt.fontStyle |= FontBold
+			}
+			if r.byte() != 0 {
+				dt.fontStyle |= FontItalic
+			}
+			r.byte() // charset
+		}
+		dt.fontFamily = r.sz()
+	}
+
+	for i := 0; i < count && r.err == nil; i++ {
+		r.align(4)
+
+		var item dialogTemplateItem
+		if ex {
+			r.uint32() // help ID
+			item.exStyle = r.uint32()
+			item.style = r.uint32()
+			item.bounds = r.rectangle()
+			item.id = int(int32(r.uint32()))
+		} else {
+			item.style = r.uint32()
+			item.exStyle = r.uint32()
+			item.bounds = r.rectangle()
+			item.id = int(int16(r.uint16()))
+		}
+
+		if atom, ok := r.ordinal(); ok {
+			if item.class, ok = dialogTemplateClasses[atom]; !ok {
+				return nil, newError("unknown control class in dialog template")
+			}
+		} else {
+			item.class = r.sz()
+		}
+
+		// Titles that are resource ordinals, as for icons, are not supported.
+		if _, ok := r.ordinal(); !ok {
+			item.title = r.sz()
+		}
+
+		r.skip(int(r.uint16())) // creation data
+
+		dt.items = append(dt.items, item)
+	}
+
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	return dt, nil
+}
+
+// dialogTemplateReader reads the little-endian fields of a dialog template.
+// Reading past the end sets err and returns zero values.
+type dialogTemplateReader struct {
+	data []byte
+	pos  int
+	err  error
+}
+
+func (r *dialogTemplateReader) next(n int) []byte {
+	if r.err != nil || r.pos+n > len(r.data) {
+		if r.err == nil {
+			r.err = newError("dialog template truncated")
+		}
+		return make([]byte, n)
+	}
+
+	b := r.data[r.pos : r.pos+n]
+	r.pos += n
+
+	return b
+}
+
+func (r *dialogTemplateReader) skip(n int) {
+	r.next(n)
+}
+
+func (r *dialogTemplateReader) align(n int) {
+	if rem := r.pos % n; rem != 0 && r.pos < len(r.data) {
+		r.skip(n - rem)
+	}
+}
+
+func (r *dialogTemplateReader) byte() byte {
+	return r.next(1)[0]
+}
+
+func (r *dialogTemplateReader) uint16() uint16 {
+	return binary.LittleEndian.Uint16(r.next(2))
+}
+
+func (r *dialogTemplateReader) uint32() uint32 {
+	return binary.LittleEndian.Uint32(r.next(4))
+}
+
+func (r *dialogTemplateReader) rectangle() Rectangle {
+	x := int16(r.uint16())
+	y := int16(r.uint16())
+	cx := int16(r.uint16())
+	cy := int16(r.uint16())
+
+	return Rectangle{int(x), int(y), int(cx), int(cy)}
+}
+
+// ordinal reads a resource ordinal, i.e. 0xFFFF followed by the value, if
+// there is one at the current position.
+func (r *dialogTemplateReader) ordinal() (uint16, bool) {
+	if r.err != nil || r.pos+2 > len(r.data) || binary.LittleEndian.Uint16(r.data[r.pos:]) != 0xFFFF {
+		return 0, false
+	}
+
+	r.skip(2)
+
+	return r.uint16(), true
+}
+
+// sz reads a null-terminated UTF-16 string, or skips an ordinal.
+func (r *dialogTemplateReader) sz() string {
+	if _, ok := r.ordinal(); ok {
+		return ""
+	}
+
+	var s []uint16
+	for r.err == nil {
+		c := r.uint16()
+		if c == 0 {
+			break
+		}
+		s = append(s, c)
+	}
+
+	return string(utf16.Decode(s))
+}
@@ -0,0 +1,187 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"encoding/binary"
+	"reflect"
+	"testing"
+	"unicode/utf16"
+
+	"github.com/tailscale/win"
+)
+
+// dialogTemplateBuilder writes dialog templates the way the resource compiler
+// does.
+type dialogTemplateBuilder []byte
+
+func (b *dialogTemplateBuilder) uint16(v uint16) {
+	*b = binary.LittleEndian.AppendUint16(*b, v)
+}
+
+func (b *dialogTemplateBuilder) uint32(v uint32) {
+	*b = binary.LittleEndian.AppendUint32(*b, v)
+}
+
+func (b *dialogTemplateBuilder) rectangle(x, y, cx, cy uint16) {
+	b.uint16(x)
+	b.uint16(y)
+	b.uint16(cx)
+	b.uint16(cy)
+}
+
+func (b *dialogTemplateBuilder) sz(s string) {
+	for _, c := range utf16.Encode([]rune(s)) {
+		b.uint16(c)
+	}
+	b.uint16(0)
+}
+
+func (b *dialogTemplateBuilder) ordinal(v uint16) {
+	b.uint16(0xFFFF)
+	b.uint16(v)
+}
+
+func (b *dialogTemplateBuilder) align() {
+	for len(*b)%4 != 0 {
+		*b = append(*b, 0)
+	}
+}
+
+func TestParseDialogTemplateEx(t *testing.T) {
+	var b dialogTemplateBuilder
+	b.uint16(1)
+	b.uint16(0xFFFF)
+	b.uint32(0) // help ID
+	b.uint32(0) // extended style
+	b.uint32(win.WS_CAPTION | win.WS_SYSMENU | win.DS_SETFONT)
+	b.uint16(2)
+	b.rectangle(0, 0, 200, 100)
+	b.uint16(0) // menu
+	b.uint16(0) // class
+	b.sz("Settings")
+	b.uint16(9)
+	b.uint16(win.FW_BOLD)
+	b = append(b, 1, 0)
+	b.sz("Segoe UI")
+
+	b.align()
+	b.uint32(0)
+	b.uint32(0)
+	b.uint32(win.WS_VISIBLE | win.WS_TABSTOP | win.BS_DEFPUSHBUTTON)
+	b.rectangle(140, 80, 50, 14)
+	b.uint32(win.IDOK)
+	b.ordinal(0x0080)
+	b.sz("OK")
+	b.uint16(0)
+
+	b.align()
+	b.uint32(0)
+	b.uint32(0)
+	b.uint32(win.WS_VISIBLE | win.WS_BORDER)
+	b.rectangle(10, 10, 100, 12)
+	b.uint32(1001)
+	b.sz("msctls_progress32")
+	b.uint16(0)
+	b.uint16(2)
+	b = append(b, 0xAB, 0xCD)
+
+	dt, err := parseDialogTemplate(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &dialogTemplate{
+		style:         win.WS_CAPTION | win.WS_SYSMENU | win.DS_SETFONT,
+		bounds:        Rectangle{0, 0, 200, 100},
+		title:         "Settings",
+		fontFamily:    "Segoe UI",
+		fontPointSize: 9,
+		fontStyle:     FontBold | FontItalic,
+		items: []dialogTemplateItem{
+			{
+				id:     win.IDOK,
+				style:  win.WS_VISIBLE | win.WS_TABSTOP | win.BS_DEFPUSHBUTTON,
+				bounds: Rectangle{140, 80, 50, 14},
+				class:  "BUTTON",
+				title:  "OK",
+			},
+			{
+				id:     1001,
+				style:  win.WS_VISIBLE | win.WS_BORDER,
+				bounds: Rectangle{10, 10, 100, 12},
+				class:  "msctls_progress32",
+			},
+		},
+	}
+
+	if !reflect.DeepEqual(dt, want) {
+		t.Errorf("got %+v, want %+v", dt, want)
+	}
+}
+
+func TestParseDialogTemplate(t *testing.T) {
+	var b dialogTemplateBuilder
+	b.uint32(win.WS_CAPTION | win.DS_SETFONT)
+	b.uint32(0)
+	b.uint16(1)
+	b.rectangle(0, 0, 120, 60)
+	b.uint16(0)
+	b.uint16(0)
+	b.sz("About")
+	b.uint16(8)
+	b.sz("MS Shell Dlg")
+
+	b.align()
+	b.uint32(win.WS_VISIBLE | win.SS_ICON)
+	b.uint32(0)
+	b.rectangle(0xFFF6, 5, 20, 20)
+	b.uint16(0xFFFF)
+	b.ordinal(0x0082)
+	b.ordinal(1) // icon resource
+	b.uint16(0)
+
+	dt, err := parseDialogTemplate(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &dialogTemplate{
+		style:         win.WS_CAPTION | win.DS_SETFONT,
+		bounds:        Rectangle{0, 0, 120, 60},
+		title:         "About",
+		fontFamily:    "MS Shell Dlg",
+		fontPointSize: 8,
+		items: []dialogTemplateItem{
+			{
+				id:     -1,
+				style:  win.WS_VISIBLE | win.SS_ICON,
+				bounds: Rectangle{-10, 5, 20, 20},
+				class:  "STATIC",
+			},
+		},
+	}
+
+	if !reflect.DeepEqual(dt, want) {
+		t.Errorf("got %+v, want %+v", dt, want)
+	}
+}
+
+func TestParseDialogTemplateTruncated(t *testing.T) {
+	var b dialogTemplateBuilder
+	b.uint16(1)
+	b.uint16(0xFFFF)
+	b.uint32(0)
+	b.uint32(0)
+	b.uint32(win.WS_CAPTION)
+	b.uint16(3)
+
+	if _, err := parseDialogTemplate(b); err == nil {
+		t.Error("got no error for truncated template")
+	}
+}
@@ -0,0 +1,284 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"syscall"
+	"unsafe"
+
+	"github.com/tailscale/win"
+)
+
+// DropEffect specifies what happens to data that is dropped. The effects
+// allowed by the source of a drag are combined using bitwise OR.
+type DropEffect uint32
+
+const (
+	DropEffectNone DropEffect = _DROPEFFECT_NONE // the data is not dropped
+	DropEffectCopy DropEffect = _DROPEFFECT_COPY // the data is copied
+	DropEffectMove DropEffect = _DROPEFFECT_MOVE // the data is moved, i.e. the source removes it
+	DropEffectLink DropEffect = _DROPEFFECT_LINK // the target links to the data
+)
+
+// DragData holds the data a drag started by WindowBase.DoDragDrop carries, in
+// as many formats as the source can provide, so any target can pick the one it
+// understands best.
+type DragData struct {
+	text        *string
+	html        *string
+	files       []string
+	formats     []string
+	data        [][]byte
+	image       *Bitmap
+	imageOffset Point
+}
+
+// NewDragData returns a new, empty DragData.
+func NewDragData() *DragData {
+	return new(DragData)
+}
+
+// SetText sets the plain text the DragData carries.
+func (dd *DragData) SetText(text string) {
+	dd.text = &text
+}
+
+// SetHTML sets the HTML fragment the DragData carries, e.g. for word
+// processors. It is usually accompanied by a plain text version.
+func (dd *DragData) SetHTML(fragment string) {
+	dd.html = &fragment
+}
+
+// SetFiles sets the paths of the files the DragData carries, e.g. for
+// Explorer. The paths should be absolute.
+func (dd *DragData) SetFiles(paths []string) {
+	dd.files = append([]string(nil), paths...)
+}
+
+// SetData sets data in the custom clipboard format named format, e.g. for
+// drags between windows of the same application. Applications agree on a
+// format by its name.
+func (dd *DragData) SetData(format string, data []byte) {
+	for i, f := range dd.formats {
+		if f == format {
+			dd.data[i] = data
+			return
+		}
+	}
+
+	dd.formats = append(dd.formats, format)
+	dd.data = append(dd.data, data)
+}
+
+// SetImage sets the image shown below the mouse pointer while the DragData is
+// dragged. offset is the position of the mouse pointer within image, in native
+// pixels. Without an image, the window the drag starts from provides one, if
+// it can, like list and tree views.
+func (dd *DragData) SetImage(image *Bitmap, offset Point) {
+	dd.image = image
+	dd.imageOffset = offset
+}
+
+// DoDragDrop lets the user drag data from the *WindowBase, which is typically
+// started from a MouseMove handler once the mouse moved a few pixels with a
+// button pressed. allowed holds the effects the *WindowBase supports.
+//
+// DoDragDrop returns once the data was dropped or the drag was canceled. It
+// returns the effect the drop target chose, or DropEffectNone if the drag was
+// canceled. If it returns DropEffectMove, the caller is expected to remove the
+// data from the *WindowBase.
+func (wb *WindowBase) DoDragDrop(data *DragData, allowed DropEffect) (DropEffect, error) {
+	dataObject, err := data.newDataObject()
+	if err != nil {
+		return DropEffectNone, err
+	}
+	defer dataObjectRelease(dataObject)
+
+	data.initializeDragImage(wb.hWnd, dataObject)
+
+	button := uint32(win.MK_LBUTTON)
+	if win.GetKeyState(win.VK_LBUTTON) >= 0 && win.GetKeyState(win.VK_RBUTTON) < 0 {
+		button = win.MK_RBUTTON
+	}
+
+	var effect uint32
+	switch hr := doDragDrop(dataObject, unsafe.Pointer(newDropSource(button)), uint32(allowed), &effect); hr {
+	case _DRAGDROP_S_DROP:
+		return DropEffect(effect) & allowed, nil
+
+	case _DRAGDROP_S_CANCEL:
+		return DropEffectNone, nil
+
+	default:
+		return DropEffectNone, errorFromHRESULT("DoDragDrop", hr)
+	}
+}
+
+// newDataObject returns a data object that holds the formats of the DragData.
+func (dd *DragData) newDataObject() (*win.IDataObject, error) {
+	var dataObject *win.IDataObject
+	if hr := shCreateDataObject(&iid_IDataObject, (*unsafe.Pointer)(unsafe.Pointer(&dataObject))); win.FAILED(hr) {
+		return nil, errorFromHRESULT("SHCreateDataObject", hr)
+	}
+
+	err := dd.forEachFormat(func(format uint32, data []byte) error {
+		hMem, err := globalAllocBytes(data)
+		if err != nil {
+			return err
+		}
+
+		fe := hGlobalFormatEtc(format)
+		medium := stgMedium{tymed: _TYMED_HGLOBAL, handle: uintptr(hMem)}
+
+		if hr := dataObjectSetData(dataObject, &fe, &medium, true); win.FAILED(hr) {
+			win.GlobalFree(hMem)
+			return errorFromHRESULT("IDataObject.SetData", hr)
+		}
+
+		return nil
+	})
+	if err != nil {
+		dataObjectRelease(dataObject)
+		return nil, err
+	}
+
+	return dataObject, nil
+}
+
+// forEachFormat calls f with each format the DragData holds and the data in
+// that format, until f returns an error.
+func (dd *DragData) forEachFormat(f func(format uint32, data []byte) error) error {
+	set := func(format uint32, data []byte) error {
+		if format == 0 {
+			return lastError("RegisterClipboardFormat")
+		}
+
+		return f(format, data)
+	}
+
+	if dd.text != nil {
+		text, err := utf16Bytes(*dd.text)
+		if err != nil {
+			return err
+		}
+
+		if err := set(win.CF_UNICODETEXT, text); err != nil {
+			return err
+		}
+	}
+
+	if dd.html != nil {
+		if err := set(registerClipboardFormat(clipboardFormatHTML), clipboardHTML(*dd.html)); err != nil {
+			return err
+		}
+	}
+
+	if len(dd.files) > 0 {
+		files, err := dropFilesBytes(dd.files)
+		if err != nil {
+			return err
+		}
+
+		if err := set(win.CF_HDROP, files); err != nil {
+			return err
+		}
+	}
+
+	for i, format := range dd.formats {
+		data := dd.data[i]
+		if len(data) == 0 {
+			// Global memory cannot be empty.
+			data = []byte{0}
+		}
+
+		if err := set(registerClipboardFormat(format), data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// initializeDragImage sets up the image shown while dataObject is dragged from
+// hwnd. Drags work without an image, so failures are ignored.
+func (dd *DragData) initializeDragImage(hwnd win.HWND, dataObject *win.IDataObject) {
+	var helper *iDragSourceHelper
+	if hr := win.CoCreateInstance(
+		&clsid_DragDropHelper,
+		nil,
+		win.CLSCTX_INPROC_SERVER,
+		&iid_IDragSourceHelper,
+		(*unsafe.Pointer)(unsafe.Pointer(&helper))); win.FAILED(hr) {
+
+		return
+	}
+	defer helper.Release()
+
+	if dd.image == nil {
+		helper.InitializeFromWindow(hwnd, nil, dataObject)
+		return
+	}
+
+	im, err := dd.image.ToImage()
+	if err != nil {
+		return
+	}
+
+	hBmp, err := hBitmapFromImage(im, dd.image.dpi)
+	if err != nil {
+		return
+	}
+
+	size := dd.image.Size()
+
+	di := shDragImage{
+		sizeDragImage: win.SIZE{CX: int32(size.Width), CY: int32(size.Height)},
+		ptOffset:      win.POINT{X: int32(dd.imageOffset.X), Y: int32(dd.imageOffset.Y)},
+		hbmpDragImage: hBmp,
+		crColorKey:    0xFFFFFFFF, // CLR_NONE, the image has an alpha channel
+	}
+
+	// On success, the helper owns the bitmap.
+	if hr := helper.InitializeFromBitmap(&di, dataObject); win.FAILED(hr) {
+		win.DeleteObject(win.HGDIOBJ(hBmp))
+	}
+}
+
+// dropFilesBytes returns paths in the CF_HDROP format, i.e. a DROPFILES
+// followed by the null-terminated paths and another null terminator.
+func dropFilesBytes(paths []string) ([]byte, error) {
+	var list []uint16
+	for _, path := range paths {
+		path16, err := syscall.UTF16FromString(path)
+		if err != nil {
+			return nil, wrapError(err)
+		}
+
+		list = append(list, path16...)
+	}
+	list = append(list, 0)
+
+	header := dropFiles{
+		pFiles: uint32(unsafe.Sizeof(dropFiles{})),
+		fWide:  win.TRUE,
+	}
+
+	data := append([]byte(nil), unsafe.Slice((*byte)(unsafe.Pointer(&header)), unsafe.Sizeof(header))...)
+
+	return append(data, unsafe.Slice((*byte)(unsafe.Pointer(&list[0])), len(list)*2)...), nil
+}
+
+// utf16Bytes returns s as null-terminated UTF-16, e.g. for CF_UNICODETEXT.
+func utf16Bytes(s string) ([]byte, error) {
+	utf16, err := syscall.UTF16FromString(s)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	return unsafe.Slice((*byte)(unsafe.Pointer(&utf16[0])), len(utf16)*2), nil
+}
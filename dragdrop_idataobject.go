@@ -0,0 +1,72 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"syscall"
+	"unsafe"
+
+	"github.com/tailscale/win"
+)
+
+var iid_IDataObject = win.IID{0x0000010E, 0x0000, 0x0000, [8]byte{0xC0, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x46}}
+
+// The win package declares IDataObject without methods, so these funcs wrap
+// the ones walk uses.
+
+func dataObjectAddRef(obj *win.IDataObject) uint32 {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.AddRef,
+		uintptr(unsafe.Pointer(obj)))
+
+	return uint32(ret)
+}
+
+func dataObjectRelease(obj *win.IDataObject) uint32 {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.Release,
+		uintptr(unsafe.Pointer(obj)))
+
+	return uint32(ret)
+}
+
+func dataObjectGetData(obj *win.IDataObject, format *formatEtc, medium *stgMedium) win.HRESULT {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.GetData,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(unsafe.Pointer(format)),
+		uintptr(unsafe.Pointer(medium)))
+
+	return win.HRESULT(ret)
+}
+
+func dataObjectQueryGetData(obj *win.IDataObject, format *formatEtc) win.HRESULT {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.QueryGetData,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(unsafe.Pointer(format)))
+
+	return win.HRESULT(ret)
+}
+
+func dataObjectSetData(obj *win.IDataObject, format *formatEtc, medium *stgMedium, release bool) win.HRESULT {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.SetData,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(unsafe.Pointer(format)),
+		uintptr(unsafe.Pointer(medium)),
+		uintptr(win.BoolToBOOL(release)))
+
+	return win.HRESULT(ret)
+}
+
+// hGlobalFormatEtc returns a FORMATETC for the content of format in global
+// memory.
+func hGlobalFormatEtc(format uint32) formatEtc {
+	return formatEtc{
+		cfFormat: uint16(format),
+		dwAspect: _DVASPECT_CONTENT,
+		lindex:   -1,
+		tymed:    _TYMED_HGLOBAL,
+	}
+}
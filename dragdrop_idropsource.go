@@ -0,0 +1,124 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"syscall"
+	"unsafe"
+
+	"github.com/tailscale/win"
+)
+
+var (
+	iid_IDragSourceHelper = win.IID{0xDE5BF786, 0x477A, 0x11D2, [8]byte{0x83, 0x9D, 0x00, 0xC0, 0x4F, 0xD9, 0x18, 0xD0}}
+	iid_IDropSource       = win.IID{0x00000121, 0x0000, 0x0000, [8]byte{0xC0, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x46}}
+)
+
+type iDropSourceVtbl struct {
+	win.IUnknownVtbl
+	QueryContinueDrag uintptr
+	GiveFeedback      uintptr
+}
+
+var dropSourceIDropSourceVtbl *iDropSourceVtbl
+
+func init() {
+	AppendToWalkInit(func() {
+		dropSourceIDropSourceVtbl = &iDropSourceVtbl{
+			win.IUnknownVtbl{
+				QueryInterface: syscall.NewCallback(dropSource_IDropSource_QueryInterface),
+				AddRef:         syscall.NewCallback(dropSource_IDropSource_AddRef),
+				Release:        syscall.NewCallback(dropSource_IDropSource_Release),
+			},
+			syscall.NewCallback(dropSource_IDropSource_QueryContinueDrag),
+			syscall.NewCallback(dropSource_IDropSource_GiveFeedback),
+		}
+	})
+}
+
+// dropSource is the IDropSource of a drag started by WindowBase.DoDragDrop.
+type dropSource struct {
+	lpVtbl *iDropSourceVtbl
+	button uint32 // the MK_* flag of the mouse button that drags
+}
+
+func newDropSource(button uint32) *dropSource {
+	return &dropSource{lpVtbl: dropSourceIDropSourceVtbl, button: button}
+}
+
+func dropSource_IDropSource_QueryInterface(ds *dropSource, riid win.REFIID, ppvObject *unsafe.Pointer) uintptr {
+	if win.EqualREFIID(riid, &win.IID_IUnknown) || win.EqualREFIID(riid, &iid_IDropSource) {
+		*ppvObject = unsafe.Pointer(ds)
+		return win.S_OK
+	}
+
+	*ppvObject = nil
+	return win.E_NOINTERFACE
+}
+
+func dropSource_IDropSource_AddRef(ds *dropSource) uintptr {
+	return 1
+}
+
+func dropSource_IDropSource_Release(ds *dropSource) uintptr {
+	return 1
+}
+
+func dropSource_IDropSource_QueryContinueDrag(ds *dropSource, escapePressed win.BOOL, keyState uint32) uintptr {
+	const buttons = win.MK_LBUTTON | win.MK_MBUTTON | win.MK_RBUTTON
+
+	if escapePressed != win.FALSE || keyState&buttons&^ds.button != 0 {
+		return _DRAGDROP_S_CANCEL
+	}
+
+	if keyState&ds.button == 0 {
+		return _DRAGDROP_S_DROP
+	}
+
+	return win.S_OK
+}
+
+func dropSource_IDropSource_GiveFeedback(ds *dropSource, effect uint32) uintptr {
+	return _DRAGDROP_S_USEDEFAULTCURSORS
+}
+
+type iDragSourceHelperVtbl struct {
+	win.IUnknownVtbl
+	InitializeFromBitmap uintptr
+	InitializeFromWindow uintptr
+}
+
+type iDragSourceHelper struct {
+	LpVtbl *iDragSourceHelperVtbl
+}
+
+func (obj *iDragSourceHelper) Release() uint32 {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.Release,
+		uintptr(unsafe.Pointer(obj)))
+
+	return uint32(ret)
+}
+
+func (obj *iDragSourceHelper) InitializeFromBitmap(image *shDragImage, dataObject *win.IDataObject) win.HRESULT {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.InitializeFromBitmap,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(unsafe.Pointer(image)),
+		uintptr(unsafe.Pointer(dataObject)))
+
+	return win.HRESULT(ret)
+}
+
+func (obj *iDragSourceHelper) InitializeFromWindow(hwnd win.HWND, pt *win.POINT, dataObject *win.IDataObject) win.HRESULT {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.InitializeFromWindow,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(hwnd),
+		uintptr(unsafe.Pointer(pt)),
+		uintptr(unsafe.Pointer(dataObject)))
+
+	return win.HRESULT(ret)
+}
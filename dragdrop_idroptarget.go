@@ -0,0 +1,191 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"syscall"
+	"unsafe"
+
+	"github.com/tailscale/win"
+)
+
+var (
+	clsid_DragDropHelper  = win.CLSID{0x4657278A, 0x411B, 0x11D2, [8]byte{0x83, 0x9A, 0x00, 0xC0, 0x4F, 0xD9, 0x18, 0xD0}}
+	iid_IDropTarget       = win.IID{0x00000122, 0x0000, 0x0000, [8]byte{0xC0, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x46}}
+	iid_IDropTargetHelper = win.IID{0x4657278B, 0x411B, 0x11D2, [8]byte{0x83, 0x9A, 0x00, 0xC0, 0x4F, 0xD9, 0x18, 0xD0}}
+)
+
+type iDropTargetVtbl struct {
+	win.IUnknownVtbl
+	DragEnter uintptr
+	DragOver  uintptr
+	DragLeave uintptr
+	Drop      uintptr
+}
+
+var dropTargetIDropTargetVtbl *iDropTargetVtbl
+
+func init() {
+	AppendToWalkInit(func() {
+		// DragEnter, DragOver and Drop take a POINTL by value, which is
+		// passed as two stack slots on 386.
+		dragEnter := syscall.NewCallback(dropTarget_IDropTarget_DragEnter)
+		dragOver := syscall.NewCallback(dropTarget_IDropTarget_DragOver)
+		drop := syscall.NewCallback(dropTarget_IDropTarget_Drop)
+		if unsafe.Sizeof(uintptr(0)) == 4 {
+			dragEnter = syscall.NewCallback(dropTarget_IDropTarget_DragEnter32)
+			dragOver = syscall.NewCallback(dropTarget_IDropTarget_DragOver32)
+			drop = syscall.NewCallback(dropTarget_IDropTarget_Drop32)
+		}
+
+		dropTargetIDropTargetVtbl = &iDropTargetVtbl{
+			win.IUnknownVtbl{
+				QueryInterface: syscall.NewCallback(dropTarget_IDropTarget_QueryInterface),
+				AddRef:         syscall.NewCallback(dropTarget_IDropTarget_AddRef),
+				Release:        syscall.NewCallback(dropTarget_IDropTarget_Release),
+			},
+			dragEnter,
+			dragOver,
+			syscall.NewCallback(dropTarget_IDropTarget_DragLeave),
+			drop,
+		}
+	})
+}
+
+// dropTarget is the IDropTarget a window is registered with while it accepts
+// drops, see WindowBase.EnableDrop and WindowBase.EnableFileDrop.
+type dropTarget struct {
+	lpVtbl   *iDropTargetVtbl
+	wb       *WindowBase
+	helper   *iDropTargetHelper
+	data     *DropData // of the current drag
+	files    []string  // of the current drag, if file drop is enabled
+	effect   uint32    // the effect reported for the current drag
+	fileDrop bool      // whether effect was decided by file drop
+}
+
+func newDropTarget(wb *WindowBase) *dropTarget {
+	return &dropTarget{lpVtbl: dropTargetIDropTargetVtbl, wb: wb}
+}
+
+func dropTarget_IDropTarget_QueryInterface(dt *dropTarget, riid win.REFIID, ppvObject *unsafe.Pointer) uintptr {
+	if win.EqualREFIID(riid, &win.IID_IUnknown) || win.EqualREFIID(riid, &iid_IDropTarget) {
+		*ppvObject = unsafe.Pointer(dt)
+		return win.S_OK
+	}
+
+	*ppvObject = nil
+	return win.E_NOINTERFACE
+}
+
+func dropTarget_IDropTarget_AddRef(dt *dropTarget) uintptr {
+	return 1
+}
+
+func dropTarget_IDropTarget_Release(dt *dropTarget) uintptr {
+	return 1
+}
+
+func dropTarget_IDropTarget_DragEnter(dt *dropTarget, dataObject *win.IDataObject, keyState uint32, pt uintptr, effect *uint32) uintptr {
+	dt.dragEnter(dataObject, keyState, win.POINT{X: int32(pt), Y: int32(uint64(pt) >> 32)}, effect)
+
+	return win.S_OK
+}
+
+func dropTarget_IDropTarget_DragEnter32(dt *dropTarget, dataObject *win.IDataObject, keyState uint32, x, y uintptr, effect *uint32) uintptr {
+	dt.dragEnter(dataObject, keyState, win.POINT{X: int32(x), Y: int32(y)}, effect)
+
+	return win.S_OK
+}
+
+func dropTarget_IDropTarget_DragOver(dt *dropTarget, keyState uint32, pt uintptr, effect *uint32) uintptr {
+	dt.dragOver(keyState, win.POINT{X: int32(pt), Y: int32(uint64(pt) >> 32)}, effect)
+
+	return win.S_OK
+}
+
+func dropTarget_IDropTarget_DragOver32(dt *dropTarget, keyState uint32, x, y uintptr, effect *uint32) uintptr {
+	dt.dragOver(keyState, win.POINT{X: int32(x), Y: int32(y)}, effect)
+
+	return win.S_OK
+}
+
+func dropTarget_IDropTarget_DragLeave(dt *dropTarget) uintptr {
+	dt.dragLeave()
+
+	return win.S_OK
+}
+
+func dropTarget_IDropTarget_Drop(dt *dropTarget, dataObject *win.IDataObject, keyState uint32, pt uintptr, effect *uint32) uintptr {
+	dt.drop(dataObject, keyState, win.POINT{X: int32(pt), Y: int32(uint64(pt) >> 32)}, effect)
+
+	return win.S_OK
+}
+
+func dropTarget_IDropTarget_Drop32(dt *dropTarget, dataObject *win.IDataObject, keyState uint32, x, y uintptr, effect *uint32) uintptr {
+	dt.drop(dataObject, keyState, win.POINT{X: int32(x), Y: int32(y)}, effect)
+
+	return win.S_OK
+}
+
+type iDropTargetHelperVtbl struct {
+	win.IUnknownVtbl
+	DragEnter uintptr
+	DragLeave uintptr
+	DragOver  uintptr
+	Drop      uintptr
+	Show      uintptr
+}
+
+type iDropTargetHelper struct {
+	LpVtbl *iDropTargetHelperVtbl
+}
+
+func (obj *iDropTargetHelper) Release() uint32 {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.Release,
+		uintptr(unsafe.Pointer(obj)))
+
+	return uint32(ret)
+}
+
+func (obj *iDropTargetHelper) DragEnter(hwndTarget win.HWND, dataObject *win.IDataObject, pt *win.POINT, effect uint32) win.HRESULT {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.DragEnter,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(hwndTarget),
+		uintptr(unsafe.Pointer(dataObject)),
+		uintptr(unsafe.Pointer(pt)),
+		uintptr(effect))
+
+	return win.HRESULT(ret)
+}
+
+func (obj *iDropTargetHelper) DragLeave() win.HRESULT {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.DragLeave,
+		uintptr(unsafe.Pointer(obj)))
+
+	return win.HRESULT(ret)
+}
+
+func (obj *iDropTargetHelper) DragOver(pt *win.POINT, effect uint32) win.HRESULT {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.DragOver,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(unsafe.Pointer(pt)),
+		uintptr(effect))
+
+	return win.HRESULT(ret)
+}
+
+func (obj *iDropTargetHelper) Drop(dataObject *win.IDataObject, pt *win.POINT, effect uint32) win.HRESULT {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.Drop,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(unsafe.Pointer(dataObject)),
+		uintptr(unsafe.Pointer(pt)),
+		uintptr(effect))
+
+	return win.HRESULT(ret)
+}
@@ -7,8 +7,6 @@
 package walk
 
 import (
-	"syscall"
-
 	"github.com/tailscale/win"
 )
 
@@ -70,16 +68,7 @@ func (p *DropFilesEventPublisher) Event(hWnd win.HWND) *DropFilesEvent {
 }
 
 func (p *DropFilesEventPublisher) Publish(hDrop win.HDROP) {
-	var files []string
-
-	n := win.DragQueryFile(hDrop, 0xFFFFFFFF, nil, 0)
-	for i := 0; i < int(n); i++ {
-		bufSize := uint(512)
-		buf := make([]uint16, bufSize)
-		if win.DragQueryFile(hDrop, uint(i), &buf[0], bufSize) > 0 {
-			files = append(files, syscall.UTF16ToString(buf))
-		}
-	}
+	files := dragQueryFiles(hDrop)
 	win.DragFinish(hDrop)
 
 	for i, h := range p.event.handlers {
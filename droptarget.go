@@ -0,0 +1,436 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"unsafe"
+
+	"github.com/tailscale/win"
+)
+
+// DropData provides the data of a drag that is over a window. It is only valid
+// while the handler it was passed to runs.
+type DropData struct {
+	dataObject *win.IDataObject
+}
+
+// ContainsText returns whether the DropData holds plain text.
+func (dd *DropData) ContainsText() bool {
+	return dd.contains(win.CF_UNICODETEXT)
+}
+
+// ContainsHTML returns whether the DropData holds an HTML fragment.
+func (dd *DropData) ContainsHTML() bool {
+	return dd.contains(registerClipboardFormat(clipboardFormatHTML))
+}
+
+// ContainsFiles returns whether the DropData holds file paths.
+func (dd *DropData) ContainsFiles() bool {
+	return dd.contains(win.CF_HDROP)
+}
+
+// ContainsData returns whether the DropData holds data in the custom clipboard
+// format named format.
+func (dd *DropData) ContainsData(format string) bool {
+	return dd.contains(registerClipboardFormat(format))
+}
+
+// Text returns the plain text the DropData holds.
+func (dd *DropData) Text() (text string, err error) {
+	err = dd.withHGlobal(win.CF_UNICODETEXT, func(hMem win.HGLOBAL, p unsafe.Pointer) error {
+		text = win.UTF16PtrToString((*uint16)(p))
+
+		return nil
+	})
+
+	return
+}
+
+// HTML returns the HTML fragment the DropData holds.
+func (dd *DropData) HTML() (fragment string, err error) {
+	data, err := dd.data(registerClipboardFormat(clipboardFormatHTML))
+	if err != nil {
+		return "", err
+	}
+
+	return fragmentFromClipboardHTML(data)
+}
+
+// Files returns the paths of the files the DropData holds.
+func (dd *DropData) Files() (paths []string, err error) {
+	err = dd.withHGlobal(win.CF_HDROP, func(hMem win.HGLOBAL, p unsafe.Pointer) error {
+		paths = dragQueryFiles(win.HDROP(hMem))
+
+		return nil
+	})
+
+	return
+}
+
+// Data returns the data the DropData holds in the custom clipboard format
+// named format.
+func (dd *DropData) Data(format string) ([]byte, error) {
+	return dd.data(registerClipboardFormat(format))
+}
+
+func (dd *DropData) contains(format uint32) bool {
+	if dd.dataObject == nil || format == 0 {
+		return false
+	}
+
+	fe := hGlobalFormatEtc(format)
+
+	return dataObjectQueryGetData(dd.dataObject, &fe) == win.S_OK
+}
+
+func (dd *DropData) data(format uint32) (data []byte, err error) {
+	err = dd.withHGlobal(format, func(hMem win.HGLOBAL, p unsafe.Pointer) error {
+		data = append([]byte(nil), unsafe.Slice((*byte)(p), globalSize(hMem))...)
+
+		return nil
+	})
+
+	return
+}
+
+// withHGlobal calls f with the content of the DropData in format, locked in
+// global memory.
+func (dd *DropData) withHGlobal(format uint32, f func(hMem win.HGLOBAL, p unsafe.Pointer) error) error {
+	if dd.dataObject == nil {
+		return newError("the drop data is no longer valid")
+	}
+
+	if format == 0 {
+		return lastError("RegisterClipboardFormat")
+	}
+
+	fe := hGlobalFormatEtc(format)
+
+	var medium stgMedium
+	if hr := dataObjectGetData(dd.dataObject, &fe, &medium); win.FAILED(hr) {
+		return errorFromHRESULT("IDataObject.GetData", hr)
+	}
+	defer releaseStgMedium(&medium)
+
+	hMem := win.HGLOBAL(medium.handle)
+
+	p := win.GlobalLock(hMem)
+	if p == nil {
+		return lastError("GlobalLock()")
+	}
+	defer win.GlobalUnlock(hMem)
+
+	return f(hMem, p)
+}
+
+// DropInfo describes a drag that is over a window or is dropped onto it.
+type DropInfo struct {
+	// Data holds the data of the drag.
+	Data *DropData
+
+	// Point is the position of the mouse pointer, in client coordinates of
+	// the window and native pixels.
+	Point Point
+
+	// Modifiers holds the modifier keys that are pressed.
+	Modifiers Modifiers
+
+	// AllowedEffects holds the effects the source of the drag allows.
+	AllowedEffects DropEffect
+
+	// SuggestedEffect is the allowed effect the user asks for using the
+	// modifier keys, as in Explorer: Ctrl copies, Shift moves and Ctrl+Shift
+	// or Alt links. Without modifiers, it is the first allowed one of copy,
+	// move and link.
+	SuggestedEffect DropEffect
+
+	// Effect is set by handlers of DragEntered and DragMoved to the effect
+	// of dropping the data at Point, typically SuggestedEffect, and is
+	// DropEffectNone by default, which refuses the drop. Handlers of Dropped
+	// get the effect that was set last and can change it to report a
+	// different outcome to the source.
+	Effect DropEffect
+}
+
+// EnableDrop lets the user drop data onto the *WindowBase. Handlers of
+// DragEntered and DragMoved decide whether the data can be dropped and with
+// which effect, which the mouse pointer shows, and handlers of Dropped then
+// take the data.
+//
+// Drags over descendants of the *WindowBase that do not accept drops
+// themselves are handled by the *WindowBase.
+func (wb *WindowBase) EnableDrop() error {
+	if err := wb.ensureDropTarget(); err != nil {
+		return err
+	}
+
+	wb.dropEnabled = true
+
+	return nil
+}
+
+// DisableDrop stops the *WindowBase from accepting data dropped onto it.
+func (wb *WindowBase) DisableDrop() {
+	wb.dropEnabled = false
+
+	wb.releaseUnusedDropTarget()
+}
+
+// DropEnabled returns whether the *WindowBase accepts data dropped onto it.
+func (wb *WindowBase) DropEnabled() bool {
+	return wb.dropEnabled
+}
+
+// DragEntered returns the event that is published when a drag entered the
+// *WindowBase, once EnableDrop was called.
+func (wb *WindowBase) DragEntered() *GenericEvent[*DropInfo] {
+	return wb.dragEnteredPublisher.Event()
+}
+
+// DragMoved returns the event that is published when a drag moved over the
+// *WindowBase or the modifier keys changed, once EnableDrop was called.
+func (wb *WindowBase) DragMoved() *GenericEvent[*DropInfo] {
+	return wb.dragMovedPublisher.Event()
+}
+
+// DragLeft returns the event that is published when a drag left the
+// *WindowBase or was canceled, once EnableDrop was called.
+func (wb *WindowBase) DragLeft() *Event {
+	return wb.dragLeftPublisher.Event()
+}
+
+// Dropped returns the event that is published when data was dropped onto the
+// *WindowBase with an effect other than DropEffectNone, once EnableDrop was
+// called.
+func (wb *WindowBase) Dropped() *GenericEvent[*DropInfo] {
+	return wb.droppedPublisher.Event()
+}
+
+// ensureDropTarget registers the *WindowBase as drop target, if it is not yet.
+func (wb *WindowBase) ensureDropTarget() error {
+	if wb.dropTarget != nil {
+		return nil
+	}
+
+	dt := newDropTarget(wb)
+
+	if hr := registerDragDrop(wb.hWnd, unsafe.Pointer(dt)); win.FAILED(hr) {
+		return errorFromHRESULT("RegisterDragDrop", hr)
+	}
+
+	// The helper shows the drag image of the source while data is dragged
+	// over the window. Without it, only the mouse pointer changes.
+	win.CoCreateInstance(
+		&clsid_DragDropHelper,
+		nil,
+		win.CLSCTX_INPROC_SERVER,
+		&iid_IDropTargetHelper,
+		(*unsafe.Pointer)(unsafe.Pointer(&dt.helper)))
+
+	wb.dropTarget = dt
+
+	return nil
+}
+
+// releaseUnusedDropTarget revokes the drop target of the *WindowBase, unless it
+// still accepts drops.
+func (wb *WindowBase) releaseUnusedDropTarget() {
+	if !wb.dropEnabled && !wb.fileDropEnabled {
+		wb.releaseDropTarget()
+	}
+}
+
+func (wb *WindowBase) releaseDropTarget() {
+	dt := wb.dropTarget
+	if dt == nil {
+		return
+	}
+
+	revokeDragDrop(wb.hWnd)
+
+	dt.releaseData()
+
+	if dt.helper != nil {
+		dt.helper.Release()
+		dt.helper = nil
+	}
+
+	wb.dropTarget = nil
+}
+
+func (dt *dropTarget) dragEnter(dataObject *win.IDataObject, keyState uint32, pt win.POINT, effect *uint32) {
+	dt.setData(dataObject)
+
+	*effect = dt.evaluate(&dt.wb.dragEnteredPublisher, keyState, pt, *effect)
+
+	if dt.helper != nil {
+		dt.helper.DragEnter(dt.wb.hWnd, dataObject, &pt, *effect)
+	}
+}
+
+func (dt *dropTarget) dragOver(keyState uint32, pt win.POINT, effect *uint32) {
+	*effect = dt.evaluate(&dt.wb.dragMovedPublisher, keyState, pt, *effect)
+
+	if dt.helper != nil {
+		dt.helper.DragOver(&pt, *effect)
+	}
+}
+
+func (dt *dropTarget) dragLeave() {
+	dt.releaseData()
+
+	if dt.helper != nil {
+		dt.helper.DragLeave()
+	}
+
+	if dt.wb.dropEnabled {
+		dt.wb.dragLeftPublisher.Publish()
+	}
+}
+
+func (dt *dropTarget) drop(dataObject *win.IDataObject, keyState uint32, pt win.POINT, effect *uint32) {
+	if dt.data == nil {
+		// Drop is supposed to follow DragEnter, but be lenient.
+		dt.setData(dataObject)
+		dt.evaluate(&dt.wb.dragEnteredPublisher, keyState, pt, *effect)
+	}
+	defer dt.releaseData()
+
+	allowed := *effect
+	*effect = dt.effect
+
+	if dt.helper != nil {
+		dt.helper.Drop(dataObject, &pt, *effect)
+	}
+
+	if *effect == _DROPEFFECT_NONE {
+		return
+	}
+
+	if dt.fileDrop {
+		dt.wb.filesDroppedPublisher.Publish(dt.newFileDrop(keyState, pt))
+		return
+	}
+
+	info := dt.newDropInfo(keyState, pt, allowed)
+	info.Effect = DropEffect(dt.effect)
+
+	dt.wb.droppedPublisher.Publish(info)
+
+	*effect = uint32(info.Effect) & allowed
+}
+
+// evaluate publishes the DragEntered or DragMoved event of the window, if it
+// accepts drops, and otherwise checks whether it accepts the drag as file
+// drop. It returns the effect of dropping at pt, in screen coordinates, given
+// the effects allowed by the source.
+func (dt *dropTarget) evaluate(publisher *GenericEventPublisher[*DropInfo], keyState uint32, pt win.POINT, allowed uint32) uint32 {
+	dt.effect = _DROPEFFECT_NONE
+	dt.fileDrop = false
+
+	if !dt.wb.Enabled() {
+		return dt.effect
+	}
+
+	if dt.wb.dropEnabled {
+		info := dt.newDropInfo(keyState, pt, allowed)
+		publisher.Publish(info)
+
+		dt.effect = uint32(info.Effect) & allowed
+	}
+
+	if dt.effect == _DROPEFFECT_NONE && dt.wb.fileDropEnabled && dt.acceptsFiles(keyState, pt, allowed) {
+		dt.effect = _DROPEFFECT_COPY
+		dt.fileDrop = true
+	}
+
+	return dt.effect
+}
+
+func (dt *dropTarget) setData(dataObject *win.IDataObject) {
+	dt.releaseData()
+
+	dataObjectAddRef(dataObject)
+	dt.data = &DropData{dataObject: dataObject}
+
+	if dt.wb.fileDropEnabled {
+		dt.files, _ = dt.data.Files()
+	}
+}
+
+func (dt *dropTarget) releaseData() {
+	if dt.data == nil {
+		return
+	}
+
+	dataObjectRelease(dt.data.dataObject)
+
+	// Handlers may hold on to the DropData, so make it fail instead of
+	// accessing a released data object.
+	dt.data.dataObject = nil
+	dt.data = nil
+	dt.files = nil
+}
+
+func (dt *dropTarget) newDropInfo(keyState uint32, pt win.POINT, allowed uint32) *DropInfo {
+	win.ScreenToClient(dt.wb.hWnd, &pt)
+
+	modifiers := modifiersFromKeyState(keyState)
+
+	return &DropInfo{
+		Data:            dt.data,
+		Point:           Point{int(pt.X), int(pt.Y)},
+		Modifiers:       modifiers,
+		AllowedEffects:  DropEffect(allowed),
+		SuggestedEffect: suggestedDropEffect(modifiers, DropEffect(allowed)),
+	}
+}
+
+// suggestedDropEffect returns the effect out of allowed the user asks for
+// using modifiers.
+func suggestedDropEffect(modifiers Modifiers, allowed DropEffect) DropEffect {
+	var preferred []DropEffect
+	switch {
+	case modifiers&ModAlt != 0, modifiers&(ModControl|ModShift) == ModControl|ModShift:
+		preferred = []DropEffect{DropEffectLink}
+
+	case modifiers&ModControl != 0:
+		preferred = []DropEffect{DropEffectCopy}
+
+	case modifiers&ModShift != 0:
+		preferred = []DropEffect{DropEffectMove}
+
+	default:
+		preferred = []DropEffect{DropEffectCopy, DropEffectMove, DropEffectLink}
+	}
+
+	for _, effect := range preferred {
+		if allowed&effect != 0 {
+			return effect
+		}
+	}
+
+	return DropEffectNone
+}
+
+// modifiersFromKeyState returns the modifier keys in keyState, a combination
+// of MK_* flags.
+func modifiersFromKeyState(keyState uint32) Modifiers {
+	var modifiers Modifiers
+	if keyState&win.MK_SHIFT != 0 {
+		modifiers |= ModShift
+	}
+	if keyState&win.MK_CONTROL != 0 {
+		modifiers |= ModControl
+	}
+	if keyState&_MK_ALT != 0 {
+		modifiers |= ModAlt
+	}
+
+	return modifiers
+}
@@ -0,0 +1,685 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"math"
+	"time"
+
+	"github.com/tailscale/win"
+)
+
+const (
+	expanderWindowClass       = `\o/ Walk_Expander_Class \o/`
+	expanderHeaderWindowClass = `\o/ Walk_ExpanderHeader_Class \o/`
+)
+
+func init() {
+	AppendToWalkInit(func() {
+		MustRegisterWindowClass(expanderWindowClass)
+		MustRegisterWindowClass(expanderHeaderWindowClass)
+	})
+}
+
+const (
+	expanderTimerId               = 1
+	expanderTimerElapse           = 16  // in milliseconds
+	expanderAnimationDuration     = 150 // in milliseconds
+	expanderChevronSize96dpi      = 8
+	expanderHeaderPadding96dpi    = 4
+	expanderHeaderBarSpacing96dpi = 2
+)
+
+// Expander is a container with a clickable header that collapses and expands
+// its content, for progressive disclosure of settings. The header shows a
+// chevron and the title, followed by the widgets of the HeaderBar, like
+// buttons acting on the content.
+type Expander struct {
+	WidgetBase
+	header                   *expanderHeader
+	headerBar                *Composite
+	composite                *Composite
+	expanded                 bool
+	animated                 bool
+	expansion                float64 // 0 when collapsed, 1 when expanded
+	animationFrom            float64
+	animationStart           time.Time
+	titleChangedPublisher    EventPublisher
+	expandedChangedPublisher EventPublisher
+}
+
+// NewExpander creates a new expanded Expander as child of parent.
+func NewExpander(parent Container) (*Expander, error) {
+	e := &Expander{
+		expanded:  true,
+		animated:  true,
+		expansion: 1,
+	}
+
+	if err := InitWidget(
+		e,
+		parent,
+		expanderWindowClass,
+		win.WS_VISIBLE,
+		win.WS_EX_CONTROLPARENT); err != nil {
+		return nil, err
+	}
+
+	succeeded := false
+	defer func() {
+		if !succeeded {
+			e.Dispose()
+		}
+	}()
+
+	var err error
+
+	if e.header, err = newExpanderHeader(e); err != nil {
+		return nil, err
+	}
+
+	if e.headerBar, err = NewComposite(e); err != nil {
+		return nil, err
+	}
+	e.headerBar.name = "headerBar"
+
+	headerBarLayout := NewHBoxLayout()
+	headerBarLayout.SetMargins(Margins{})
+	headerBarLayout.SetSpacing(expanderHeaderBarSpacing96dpi)
+	if err := e.headerBar.SetLayout(headerBarLayout); err != nil {
+		return nil, err
+	}
+
+	if e.composite, err = NewComposite(e); err != nil {
+		return nil, err
+	}
+	e.composite.name = "composite"
+
+	e.SetBackground(NullBrush())
+
+	e.MustRegisterProperty("Title", NewProperty(
+		func() interface{} {
+			return e.Title()
+		},
+		func(v interface{}) error {
+			return e.SetTitle(assertStringOr(v, ""))
+		},
+		e.titleChangedPublisher.Event()))
+
+	e.MustRegisterProperty("Expanded", NewBoolProperty(
+		func() bool {
+			return e.Expanded()
+		},
+		func(v bool) error {
+			e.SetExpanded(v)
+			return nil
+		},
+		e.ExpandedChanged()))
+
+	succeeded = true
+
+	return e, nil
+}
+
+func (e *Expander) AsContainerBase() *ContainerBase {
+	if e.composite == nil {
+		return nil
+	}
+
+	return e.composite.AsContainerBase()
+}
+
+func (e *Expander) Children() *WidgetList {
+	if e.composite == nil {
+		// Without this we would get into trouble in NewComposite.
+		return nil
+	}
+
+	return e.composite.Children()
+}
+
+func (e *Expander) Layout() Layout {
+	if e.composite == nil {
+		return nil
+	}
+
+	return e.composite.Layout()
+}
+
+func (e *Expander) SetLayout(value Layout) error {
+	return e.composite.SetLayout(value)
+}
+
+func (e *Expander) DataBinder() *DataBinder {
+	return e.composite.dataBinder
+}
+
+func (e *Expander) SetDataBinder(dataBinder *DataBinder) {
+	e.composite.SetDataBinder(dataBinder)
+}
+
+// HeaderBar returns the Composite to the right of the title, for widgets
+// acting on the content.
+func (e *Expander) HeaderBar() *Composite {
+	return e.headerBar
+}
+
+func (e *Expander) Persistent() bool {
+	return e.composite.Persistent()
+}
+
+func (e *Expander) SetPersistent(value bool) {
+	e.composite.SetPersistent(value)
+}
+
+func (e *Expander) SaveState() error {
+	return e.composite.SaveState()
+}
+
+func (e *Expander) RestoreState() error {
+	return e.composite.RestoreState()
+}
+
+func (e *Expander) applyEnabled(enabled bool) {
+	e.WidgetBase.applyEnabled(enabled)
+
+	if e.header != nil {
+		e.header.applyEnabled(enabled)
+	}
+
+	if e.headerBar != nil {
+		e.headerBar.applyEnabled(enabled)
+	}
+
+	if e.composite != nil {
+		e.composite.applyEnabled(enabled)
+	}
+}
+
+func (e *Expander) applyFont(font *Font) {
+	e.WidgetBase.applyFont(font)
+
+	if e.header != nil {
+		e.header.applyFont(font)
+	}
+
+	if e.headerBar != nil {
+		e.headerBar.applyFont(font)
+	}
+
+	if e.composite != nil {
+		e.composite.applyFont(font)
+	}
+}
+
+func (e *Expander) ApplyDPI(dpi int) {
+	e.WidgetBase.ApplyDPI(dpi)
+
+	if e.header != nil {
+		e.header.ApplyDPI(dpi)
+	}
+
+	if e.headerBar != nil {
+		e.headerBar.ApplyDPI(dpi)
+	}
+
+	if e.composite != nil {
+		e.composite.ApplyDPI(dpi)
+	}
+}
+
+func (e *Expander) SetSuspended(suspend bool) {
+	e.composite.SetSuspended(suspend)
+	e.WidgetBase.SetSuspended(suspend)
+	e.Invalidate()
+}
+
+func (e *Expander) MouseDown() *MouseEvent {
+	return e.composite.MouseDown()
+}
+
+func (e *Expander) MouseMove() *MouseEvent {
+	return e.composite.MouseMove()
+}
+
+func (e *Expander) MouseUp() *MouseEvent {
+	return e.composite.MouseUp()
+}
+
+// Title returns the text displayed in the header.
+func (e *Expander) Title() string {
+	return windowText(e.header.hWnd)
+}
+
+// SetTitle sets the text displayed in the header.
+func (e *Expander) SetTitle(title string) error {
+	if title == e.Title() {
+		return nil
+	}
+
+	if err := setWindowText(e.header.hWnd, title); err != nil {
+		return err
+	}
+
+	e.header.Invalidate()
+	e.RequestLayout()
+
+	e.titleChangedPublisher.Publish()
+
+	return nil
+}
+
+// TitleChanged returns the event that is published when the title changed.
+func (e *Expander) TitleChanged() *Event {
+	return e.titleChangedPublisher.Event()
+}
+
+// Expanded returns whether the content is displayed.
+func (e *Expander) Expanded() bool {
+	return e.expanded
+}
+
+// SetExpanded displays or hides the content, animating the change if
+// Animated.
+func (e *Expander) SetExpanded(expanded bool) {
+	if expanded == e.expanded {
+		return
+	}
+
+	e.expanded = expanded
+
+	if !expanded && win.IsChild(e.composite.hWnd, win.GetFocus()) {
+		e.header.SetFocus()
+	}
+
+	if e.animated && win.IsWindowVisible(e.hWnd) {
+		e.animationFrom = e.expansion
+		e.animationStart = time.Now()
+
+		setWindowVisible(e.composite.hWnd, true)
+
+		win.SetTimer(e.hWnd, expanderTimerId, expanderTimerElapse, 0)
+	} else {
+		win.KillTimer(e.hWnd, expanderTimerId)
+
+		e.setExpansion(e.target())
+	}
+
+	e.header.Invalidate()
+
+	e.expandedChangedPublisher.Publish()
+}
+
+// ExpandedChanged returns the event that is published when the content was
+// expanded or collapsed.
+func (e *Expander) ExpandedChanged() *Event {
+	return e.expandedChangedPublisher.Event()
+}
+
+// Animated returns whether expanding and collapsing is animated.
+func (e *Expander) Animated() bool {
+	return e.animated
+}
+
+// SetAnimated sets whether expanding and collapsing is animated.
+func (e *Expander) SetAnimated(animated bool) {
+	e.animated = animated
+}
+
+func (e *Expander) target() float64 {
+	if e.expanded {
+		return 1
+	}
+
+	return 0
+}
+
+func (e *Expander) setExpansion(expansion float64) {
+	e.expansion = expansion
+
+	setWindowVisible(e.composite.hWnd, expansion > 0)
+
+	e.RequestLayout()
+}
+
+func (e *Expander) animate() {
+	progress := float64(time.Since(e.animationStart).Milliseconds()) / expanderAnimationDuration
+	if progress >= 1 {
+		progress = 1
+
+		win.KillTimer(e.hWnd, expanderTimerId)
+	}
+
+	// Ease out, slowing down towards the end.
+	progress = 1 - (1-progress)*(1-progress)
+
+	e.setExpansion(e.animationFrom + (e.target()-e.animationFrom)*progress)
+}
+
+func (e *Expander) WndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) uintptr {
+	switch msg {
+	case win.WM_TIMER:
+		if wParam == expanderTimerId {
+			e.animate()
+			return 0
+		}
+	}
+
+	return e.WidgetBase.WndProc(hwnd, msg, wParam, lParam)
+}
+
+func (e *Expander) CreateLayoutItem(ctx *LayoutContext) LayoutItem {
+	li := &expanderLayoutItem{
+		expansion: e.expansion,
+	}
+
+	headerItem := createLayoutItemForWidgetWithContext(e.header, ctx)
+	headerBarItem := CreateLayoutItemsForContainerWithContext(e.headerBar, ctx)
+	contentItem := CreateLayoutItemsForContainerWithContext(e.composite, ctx)
+
+	for _, item := range []LayoutItem{headerItem, headerBarItem, contentItem} {
+		item.AsLayoutItemBase().parent = li
+		li.children = append(li.children, item)
+	}
+
+	li.headerHeight = headerItem.(IdealSizer).IdealSize().Height
+	if h := headerBarItem.(IdealSizer).IdealSize().Height; h > li.headerHeight {
+		li.headerHeight = h
+	}
+
+	return li
+}
+
+type expanderLayoutItem struct {
+	ContainerLayoutItemBase
+	headerHeight int     // in native pixels
+	expansion    float64 // 0 when collapsed, 1 when expanded
+}
+
+func (li *expanderLayoutItem) header() LayoutItem {
+	return li.children[0]
+}
+
+func (li *expanderLayoutItem) headerBar() LayoutItem {
+	return li.children[1]
+}
+
+func (li *expanderLayoutItem) content() LayoutItem {
+	return li.children[2]
+}
+
+// contentHeight returns the part of height of the content that is displayed.
+func (li *expanderLayoutItem) contentHeight(height int) int {
+	return int(math.Round(float64(height) * li.expansion))
+}
+
+func (li *expanderLayoutItem) LayoutFlags() LayoutFlags {
+	flags := li.content().LayoutFlags() | GrowableHorz | ShrinkableHorz
+	if li.expansion < 1 {
+		flags &^= GrowableVert | GreedyVert
+	}
+
+	return flags
+}
+
+func (li *expanderLayoutItem) MinSize() Size {
+	header := li.header().(MinSizer).MinSize()
+	headerBar := li.headerBar().(IdealSizer).IdealSize()
+	content := li.content().(MinSizer).MinSize()
+
+	return Size{
+		Width:  maxi(header.Width+headerBar.Width, content.Width),
+		Height: li.headerHeight + li.contentHeight(content.Height),
+	}
+}
+
+func (li *expanderLayoutItem) MinSizeForSize(size Size) Size {
+	return li.MinSize()
+}
+
+func (li *expanderLayoutItem) IdealSize() Size {
+	header := li.header().(IdealSizer).IdealSize()
+	headerBar := li.headerBar().(IdealSizer).IdealSize()
+	content := li.content().(IdealSizer).IdealSize()
+
+	return Size{
+		Width:  maxi(header.Width+headerBar.Width, content.Width),
+		Height: li.headerHeight + li.contentHeight(content.Height),
+	}
+}
+
+func (li *expanderLayoutItem) HasHeightForWidth() bool {
+	hfw, ok := li.content().(HeightForWidther)
+	return ok && hfw.HasHeightForWidth()
+}
+
+func (li *expanderLayoutItem) HeightForWidth(width int) int {
+	return li.headerHeight + li.contentHeight(li.content().(HeightForWidther).HeightForWidth(width))
+}
+
+func (li *expanderLayoutItem) PerformLayout() []LayoutResultItem {
+	size := li.geometry.ClientSize
+
+	headerBar := li.headerBar().(IdealSizer).IdealSize()
+	if headerBar.Width > size.Width {
+		headerBar.Width = size.Width
+	}
+
+	// While animating, the content keeps the height it has when expanded and
+	// is clipped.
+	contentHeight := size.Height - li.headerHeight
+	if li.expansion < 1 {
+		full := li.content().(IdealSizer).IdealSize().Height
+		if li.HasHeightForWidth() {
+			full = li.content().(HeightForWidther).HeightForWidth(size.Width)
+		}
+
+		contentHeight = maxi(contentHeight, full)
+	}
+
+	return []LayoutResultItem{
+		{
+			Item:   li.header(),
+			Bounds: Rectangle{Width: size.Width - headerBar.Width, Height: li.headerHeight},
+		},
+		{
+			Item:   li.headerBar(),
+			Bounds: Rectangle{X: size.Width - headerBar.Width, Y: (li.headerHeight - headerBar.Height) / 2, Width: headerBar.Width, Height: headerBar.Height},
+		},
+		{
+			Item:   li.content(),
+			Bounds: Rectangle{Y: li.headerHeight, Width: size.Width, Height: contentHeight},
+		},
+	}
+}
+
+// expanderHeader is the clickable header of an Expander, displaying the chevron
+// and the title.
+type expanderHeader struct {
+	WidgetBase
+	expander *Expander
+}
+
+func newExpanderHeader(expander *Expander) (*expanderHeader, error) {
+	h := &expanderHeader{expander: expander}
+
+	if err := InitWidget(
+		h,
+		expander,
+		expanderHeaderWindowClass,
+		win.WS_TABSTOP|win.WS_VISIBLE,
+		0); err != nil {
+		return nil, err
+	}
+
+	h.SetBackground(NullBrush())
+
+	return h, nil
+}
+
+func (h *expanderHeader) paint(canvas *Canvas, bounds Rectangle) error {
+	bp, err := BeginBufferedPaint(canvas, bounds, win.BPBF_COMPATIBLEBITMAP)
+	if err != nil {
+		return err
+	}
+	defer bp.End()
+
+	buffered, err := bp.Canvas()
+	if err != nil {
+		return err
+	}
+	defer buffered.Dispose()
+
+	if bg, wnd := h.backgroundEffective(); bg != nil {
+		h.prepareDCForBackground(buffered.HDC(), h.hWnd, wnd)
+
+		if err := buffered.FillRectanglePixels(bg, bounds); err != nil {
+			return err
+		}
+	} else {
+		brush, err := NewSystemColorBrush(SysColorBtnFace)
+		if err != nil {
+			return err
+		}
+		err = buffered.FillRectanglePixels(brush, bounds)
+		brush.Dispose()
+		if err != nil {
+			return err
+		}
+	}
+
+	cb := h.ClientBoundsPixels()
+	padding := h.IntFrom96DPI(expanderHeaderPadding96dpi)
+	chevron := h.IntFrom96DPI(expanderChevronSize96dpi)
+
+	color := Color(win.GetSysColor(win.COLOR_BTNTEXT))
+	if !h.Enabled() {
+		color = Color(win.GetSysColor(win.COLOR_GRAYTEXT))
+	}
+
+	brush, err := NewSolidColorBrush(color)
+	if err != nil {
+		return err
+	}
+	defer brush.Dispose()
+
+	pen, err := NewGeometricPen(PenSolid|PenCapRound|PenJoinRound, 1, brush)
+	if err != nil {
+		return err
+	}
+	defer pen.Dispose()
+
+	cx, cy := cb.X+padding+chevron/2, cb.Y+cb.Height/2
+	var points []Point
+	if h.expander.expanded {
+		points = []Point{{cx - chevron/2, cy - chevron/4}, {cx, cy + chevron/4}, {cx + chevron/2, cy - chevron/4}}
+	} else {
+		points = []Point{{cx - chevron/4, cy - chevron/2}, {cx + chevron/4, cy}, {cx - chevron/4, cy + chevron/2}}
+	}
+	if err := buffered.DrawPolylinePixels(pen, points); err != nil {
+		return err
+	}
+
+	textBounds := Rectangle{
+		X:      cb.X + 2*padding + chevron,
+		Y:      cb.Y,
+		Width:  cb.Width - 3*padding - chevron,
+		Height: cb.Height,
+	}
+	if err := buffered.DrawTextPixels(windowText(h.hWnd), h.Font(), color, textBounds, TextLeft|TextVCenter|TextSingleLine|TextEndEllipsis|TextNoPrefix); err != nil {
+		return err
+	}
+
+	if h.Focused() && h.FocusCuesVisible() {
+		rc := cb.toRECT()
+		win.DrawFocusRect(buffered.HDC(), &rc)
+	}
+
+	return nil
+}
+
+func (h *expanderHeader) WndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) uintptr {
+	switch msg {
+	case win.WM_PAINT:
+		var ps win.PAINTSTRUCT
+
+		hdc := win.BeginPaint(hwnd, &ps)
+		defer win.EndPaint(hwnd, &ps)
+
+		canvas, err := newCanvasFromHDC(hdc)
+		if err != nil {
+			return 0
+		}
+		defer canvas.Dispose()
+
+		h.paint(canvas, h.ClientBoundsPixels())
+
+		return 0
+
+	case win.WM_ERASEBKGND:
+		// Everything is drawn in WM_PAINT.
+		return 1
+
+	case win.WM_GETDLGCODE:
+		return win.DLGC_WANTARROWS
+
+	case win.WM_LBUTTONDOWN:
+		h.SetFocus()
+		h.expander.SetExpanded(!h.expander.expanded)
+
+	case win.WM_KEYDOWN:
+		switch Key(wParam) {
+		case KeySpace:
+			h.expander.SetExpanded(!h.expander.expanded)
+
+		case KeyLeft:
+			h.expander.SetExpanded(false)
+
+		case KeyRight:
+			h.expander.SetExpanded(true)
+		}
+
+	case win.WM_SETFOCUS, win.WM_KILLFOCUS, win.WM_SIZE:
+		h.Invalidate()
+	}
+
+	return h.WidgetBase.WndProc(hwnd, msg, wParam, lParam)
+}
+
+func (h *expanderHeader) CreateLayoutItem(ctx *LayoutContext) LayoutItem {
+	padding := IntFrom96DPI(expanderHeaderPadding96dpi, ctx.dpi)
+	chevron := IntFrom96DPI(expanderChevronSize96dpi, ctx.dpi)
+
+	text := h.calculateTextSizeImpl(windowText(h.hWnd))
+	if text.Height == 0 {
+		text.Height = h.calculateTextSizeImpl("gM").Height
+	}
+
+	return &expanderHeaderLayoutItem{
+		minSize:   Size{3*padding + chevron, text.Height + 2*padding},
+		idealSize: Size{3*padding + chevron + text.Width, text.Height + 2*padding},
+	}
+}
+
+type expanderHeaderLayoutItem struct {
+	LayoutItemBase
+	minSize   Size // in native pixels
+	idealSize Size // in native pixels
+}
+
+func (*expanderHeaderLayoutItem) LayoutFlags() LayoutFlags {
+	return ShrinkableHorz | GrowableHorz
+}
+
+func (li *expanderHeaderLayoutItem) IdealSize() Size {
+	return li.idealSize
+}
+
+func (li *expanderHeaderLayoutItem) MinSize() Size {
+	return li.minSize
+}
@@ -0,0 +1,480 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"syscall"
+	"unsafe"
+
+	"github.com/tailscale/win"
+)
+
+var (
+	clsid_FileOpenDialog = win.CLSID{0xDC1C5A9C, 0xE88A, 0x4DDE, [8]byte{0xA5, 0xA1, 0x60, 0xF8, 0x2A, 0x20, 0xAE, 0xF7}}
+	clsid_FileSaveDialog = win.CLSID{0xC0B4E2F3, 0xBA21, 0x4773, [8]byte{0x8D, 0xBA, 0x33, 0x5E, 0xC9, 0x46, 0xEB, 0x8B}}
+
+	iid_IFileDialogCustomize = win.IID{0xE6FDD21A, 0x163F, 0x4975, [8]byte{0x9C, 0x8C, 0xA6, 0x9F, 0x1B, 0xA3, 0x70, 0x34}}
+	iid_IFileDialogEvents    = win.IID{0x973510DB, 0x7D7F, 0x452B, [8]byte{0x89, 0x75, 0x74, 0xA8, 0x58, 0x28, 0xD3, 0x54}}
+	iid_IFileOpenDialog      = win.IID{0xD57C7288, 0xD4AD, 0x4768, [8]byte{0xBE, 0x02, 0x9D, 0x96, 0x95, 0x32, 0xD9, 0x60}}
+	iid_IFileSaveDialog      = win.IID{0x84BCCD23, 0x5FDE, 0x4CDB, [8]byte{0xAE, 0xA4, 0xAF, 0x64, 0xB8, 0x3D, 0x78, 0xAB}}
+	iid_IShellItem           = win.IID{0x43826D1E, 0xE718, 0x42EE, [8]byte{0xBC, 0x55, 0xA1, 0xE2, 0x61, 0xC3, 0x7B, 0xFE}}
+)
+
+const (
+	_FOS_OVERWRITEPROMPT  = 0x00000002
+	_FOS_NOCHANGEDIR      = 0x00000008
+	_FOS_PICKFOLDERS      = 0x00000020
+	_FOS_FORCEFILESYSTEM  = 0x00000040
+	_FOS_ALLOWMULTISELECT = 0x00000200
+	_FOS_PATHMUSTEXIST    = 0x00000800
+	_FOS_FILEMUSTEXIST    = 0x00001000
+
+	_FDAP_BOTTOM = 0
+	_FDAP_TOP    = 1
+
+	_FDEOR_DEFAULT = 0
+	_FDEOR_ACCEPT  = 1
+	_FDEOR_REFUSE  = 2
+
+	_SIGDN_FILESYSPATH = 0x80058000
+
+	// _HRESULT_ERROR_CANCELLED is HRESULT_FROM_WIN32(ERROR_CANCELLED), which
+	// IModalWindow.Show returns if the user cancelled the dialog.
+	_HRESULT_ERROR_CANCELLED = -0x7FF8FB39
+)
+
+// comdlgFilterSpec mirrors COMDLG_FILTERSPEC.
+type comdlgFilterSpec struct {
+	pszName *uint16
+	pszSpec *uint16
+}
+
+// iFileDialogVtbl is the vtable of IFileOpenDialog. Save dialogs share all
+// but the last two entries, so GetResults must only be called on open
+// dialogs.
+type iFileDialogVtbl struct {
+	win.IUnknownVtbl
+	Show                uintptr
+	SetFileTypes        uintptr
+	SetFileTypeIndex    uintptr
+	GetFileTypeIndex    uintptr
+	Advise              uintptr
+	Unadvise            uintptr
+	SetOptions          uintptr
+	GetOptions          uintptr
+	SetDefaultFolder    uintptr
+	SetFolder           uintptr
+	GetFolder           uintptr
+	GetCurrentSelection uintptr
+	SetFileName         uintptr
+	GetFileName         uintptr
+	SetTitle            uintptr
+	SetOkButtonLabel    uintptr
+	SetFileNameLabel    uintptr
+	GetResult           uintptr
+	AddPlace            uintptr
+	SetDefaultExtension uintptr
+	Close               uintptr
+	SetClientGuid       uintptr
+	ClearClientData     uintptr
+	SetFilter           uintptr
+	GetResults          uintptr
+	GetSelectedItems    uintptr
+}
+
+type iFileDialog struct {
+	LpVtbl *iFileDialogVtbl
+}
+
+// newIFileDialog creates the common item dialog of class clsid, for opening
+// or saving files or picking folders.
+func newIFileDialog(clsid *win.CLSID, iid *win.IID) (*iFileDialog, error) {
+	var fd *iFileDialog
+	if hr := win.CoCreateInstance(
+		clsid,
+		nil,
+		win.CLSCTX_INPROC_SERVER,
+		iid,
+		(*unsafe.Pointer)(unsafe.Pointer(&fd))); win.FAILED(hr) {
+
+		return nil, errorFromHRESULT("CoCreateInstance(CLSID_FileDialog)", hr)
+	}
+
+	return fd, nil
+}
+
+func (obj *iFileDialog) Release() uint32 {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.Release,
+		uintptr(unsafe.Pointer(obj)))
+
+	return uint32(ret)
+}
+
+func (obj *iFileDialog) Show(owner win.HWND) win.HRESULT {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.Show,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(owner))
+
+	return win.HRESULT(ret)
+}
+
+func (obj *iFileDialog) SetFileTypes(specs []comdlgFilterSpec) win.HRESULT {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.SetFileTypes,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(len(specs)),
+		uintptr(unsafe.Pointer(&specs[0])))
+
+	return win.HRESULT(ret)
+}
+
+func (obj *iFileDialog) SetFileTypeIndex(index uint32) win.HRESULT {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.SetFileTypeIndex,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(index))
+
+	return win.HRESULT(ret)
+}
+
+func (obj *iFileDialog) GetFileTypeIndex(index *uint32) win.HRESULT {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.GetFileTypeIndex,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(unsafe.Pointer(index)))
+
+	return win.HRESULT(ret)
+}
+
+func (obj *iFileDialog) Advise(events unsafe.Pointer, cookie *uint32) win.HRESULT {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.Advise,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(events),
+		uintptr(unsafe.Pointer(cookie)))
+
+	return win.HRESULT(ret)
+}
+
+func (obj *iFileDialog) Unadvise(cookie uint32) win.HRESULT {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.Unadvise,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(cookie))
+
+	return win.HRESULT(ret)
+}
+
+func (obj *iFileDialog) SetOptions(options uint32) win.HRESULT {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.SetOptions,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(options))
+
+	return win.HRESULT(ret)
+}
+
+func (obj *iFileDialog) GetOptions(options *uint32) win.HRESULT {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.GetOptions,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(unsafe.Pointer(options)))
+
+	return win.HRESULT(ret)
+}
+
+func (obj *iFileDialog) SetDefaultFolder(item *iShellItem) win.HRESULT {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.SetDefaultFolder,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(unsafe.Pointer(item)))
+
+	return win.HRESULT(ret)
+}
+
+func (obj *iFileDialog) SetFolder(item *iShellItem) win.HRESULT {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.SetFolder,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(unsafe.Pointer(item)))
+
+	return win.HRESULT(ret)
+}
+
+func (obj *iFileDialog) SetFileName(name *uint16) win.HRESULT {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.SetFileName,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(unsafe.Pointer(name)))
+
+	return win.HRESULT(ret)
+}
+
+func (obj *iFileDialog) SetTitle(title *uint16) win.HRESULT {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.SetTitle,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(unsafe.Pointer(title)))
+
+	return win.HRESULT(ret)
+}
+
+func (obj *iFileDialog) GetResult(item **iShellItem) win.HRESULT {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.GetResult,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(unsafe.Pointer(item)))
+
+	return win.HRESULT(ret)
+}
+
+func (obj *iFileDialog) AddPlace(item *iShellItem, fdap uint32) win.HRESULT {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.AddPlace,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(unsafe.Pointer(item)),
+		uintptr(fdap))
+
+	return win.HRESULT(ret)
+}
+
+func (obj *iFileDialog) SetDefaultExtension(extension *uint16) win.HRESULT {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.SetDefaultExtension,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(unsafe.Pointer(extension)))
+
+	return win.HRESULT(ret)
+}
+
+func (obj *iFileDialog) GetResults(items **iShellItemArray) win.HRESULT {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.GetResults,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(unsafe.Pointer(items)))
+
+	return win.HRESULT(ret)
+}
+
+type iFileDialogCustomizeVtbl struct {
+	win.IUnknownVtbl
+	EnableOpenDropDown     uintptr
+	AddMenu                uintptr
+	AddPushButton          uintptr
+	AddComboBox            uintptr
+	AddRadioButtonList     uintptr
+	AddCheckButton         uintptr
+	AddEditBox             uintptr
+	AddSeparator           uintptr
+	AddText                uintptr
+	SetControlLabel        uintptr
+	GetControlState        uintptr
+	SetControlState        uintptr
+	GetEditBoxText         uintptr
+	SetEditBoxText         uintptr
+	GetCheckButtonState    uintptr
+	SetCheckButtonState    uintptr
+	AddControlItem         uintptr
+	RemoveControlItem      uintptr
+	RemoveAllControlItems  uintptr
+	GetControlItemState    uintptr
+	SetControlItemState    uintptr
+	GetSelectedControlItem uintptr
+	SetSelectedControlItem uintptr
+	StartVisualGroup       uintptr
+	EndVisualGroup         uintptr
+	MakeProminent          uintptr
+	SetControlItemText     uintptr
+}
+
+type iFileDialogCustomize struct {
+	LpVtbl *iFileDialogCustomizeVtbl
+}
+
+func (obj *iFileDialogCustomize) Release() uint32 {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.Release,
+		uintptr(unsafe.Pointer(obj)))
+
+	return uint32(ret)
+}
+
+func (obj *iFileDialogCustomize) AddComboBox(id uint32) win.HRESULT {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.AddComboBox,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(id))
+
+	return win.HRESULT(ret)
+}
+
+func (obj *iFileDialogCustomize) AddCheckButton(id uint32, label *uint16, checked bool) win.HRESULT {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.AddCheckButton,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(id),
+		uintptr(unsafe.Pointer(label)),
+		uintptr(win.BoolToBOOL(checked)))
+
+	return win.HRESULT(ret)
+}
+
+func (obj *iFileDialogCustomize) GetCheckButtonState(id uint32, checked *win.BOOL) win.HRESULT {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.GetCheckButtonState,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(id),
+		uintptr(unsafe.Pointer(checked)))
+
+	return win.HRESULT(ret)
+}
+
+func (obj *iFileDialogCustomize) AddControlItem(id, itemId uint32, label *uint16) win.HRESULT {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.AddControlItem,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(id),
+		uintptr(itemId),
+		uintptr(unsafe.Pointer(label)))
+
+	return win.HRESULT(ret)
+}
+
+func (obj *iFileDialogCustomize) GetSelectedControlItem(id uint32, itemId *uint32) win.HRESULT {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.GetSelectedControlItem,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(id),
+		uintptr(unsafe.Pointer(itemId)))
+
+	return win.HRESULT(ret)
+}
+
+func (obj *iFileDialogCustomize) SetSelectedControlItem(id, itemId uint32) win.HRESULT {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.SetSelectedControlItem,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(id),
+		uintptr(itemId))
+
+	return win.HRESULT(ret)
+}
+
+func (obj *iFileDialogCustomize) StartVisualGroup(id uint32, label *uint16) win.HRESULT {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.StartVisualGroup,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(id),
+		uintptr(unsafe.Pointer(label)))
+
+	return win.HRESULT(ret)
+}
+
+func (obj *iFileDialogCustomize) EndVisualGroup() win.HRESULT {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.EndVisualGroup,
+		uintptr(unsafe.Pointer(obj)))
+
+	return win.HRESULT(ret)
+}
+
+type iShellItemVtbl struct {
+	win.IUnknownVtbl
+	BindToHandler  uintptr
+	GetParent      uintptr
+	GetDisplayName uintptr
+	GetAttributes  uintptr
+	Compare        uintptr
+}
+
+type iShellItem struct {
+	LpVtbl *iShellItemVtbl
+}
+
+// newIShellItemFromPath returns the shell item of the file system object at
+// path.
+func newIShellItemFromPath(path string) (*iShellItem, error) {
+	var item *iShellItem
+	if hr := shCreateItemFromParsingName(
+		syscall.StringToUTF16Ptr(path),
+		&iid_IShellItem,
+		(*unsafe.Pointer)(unsafe.Pointer(&item))); win.FAILED(hr) {
+
+		return nil, errorFromHRESULT("SHCreateItemFromParsingName", hr)
+	}
+
+	return item, nil
+}
+
+func (obj *iShellItem) Release() uint32 {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.Release,
+		uintptr(unsafe.Pointer(obj)))
+
+	return uint32(ret)
+}
+
+func (obj *iShellItem) GetDisplayName(sigdn uint32, name **uint16) win.HRESULT {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.GetDisplayName,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(sigdn),
+		uintptr(unsafe.Pointer(name)))
+
+	return win.HRESULT(ret)
+}
+
+// path returns the file system path of the item.
+func (obj *iShellItem) path() (string, error) {
+	var name *uint16
+	if hr := obj.GetDisplayName(_SIGDN_FILESYSPATH, &name); win.FAILED(hr) {
+		return "", errorFromHRESULT("IShellItem.GetDisplayName", hr)
+	}
+	defer win.CoTaskMemFree(uintptr(unsafe.Pointer(name)))
+
+	return win.UTF16PtrToString(name), nil
+}
+
+type iShellItemArrayVtbl struct {
+	win.IUnknownVtbl
+	BindToHandler              uintptr
+	GetPropertyStore           uintptr
+	GetPropertyDescriptionList uintptr
+	GetAttributes              uintptr
+	GetCount                   uintptr
+	GetItemAt                  uintptr
+	EnumItems                  uintptr
+}
+
+type iShellItemArray struct {
+	LpVtbl *iShellItemArrayVtbl
+}
+
+func (obj *iShellItemArray) Release() uint32 {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.Release,
+		uintptr(unsafe.Pointer(obj)))
+
+	return uint32(ret)
+}
+
+func (obj *iShellItemArray) GetCount(count *uint32) win.HRESULT {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.GetCount,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(unsafe.Pointer(count)))
+
+	return win.HRESULT(ret)
+}
+
+func (obj *iShellItemArray) GetItemAt(index uint32, item **iShellItem) win.HRESULT {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.GetItemAt,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(index),
+		uintptr(unsafe.Pointer(item)))
+
+	return win.HRESULT(ret)
+}
+
+// paths returns the file system paths of the items.
+func (obj *iShellItemArray) paths() ([]string, error) {
+	var count uint32
+	if hr := obj.GetCount(&count); win.FAILED(hr) {
+		return nil, errorFromHRESULT("IShellItemArray.GetCount", hr)
+	}
+
+	paths := make([]string, 0, count)
+	for i := uint32(0); i < count; i++ {
+		var item *iShellItem
+		if hr := obj.GetItemAt(i, &item); win.FAILED(hr) {
+			return nil, errorFromHRESULT("IShellItemArray.GetItemAt", hr)
+		}
+
+		path, err := item.path()
+		item.Release()
+		if err != nil {
+			return nil, err
+		}
+
+		paths = append(paths, path)
+	}
+
+	return paths, nil
+}
@@ -0,0 +1,129 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"syscall"
+	"unsafe"
+
+	"github.com/tailscale/win"
+)
+
+type iFileDialogEventsVtbl struct {
+	QueryInterface    uintptr
+	AddRef            uintptr
+	Release           uintptr
+	OnFileOk          uintptr
+	OnFolderChanging  uintptr
+	OnFolderChange    uintptr
+	OnSelectionChange uintptr
+	OnShareViolation  uintptr
+	OnTypeChange      uintptr
+	OnOverwrite       uintptr
+}
+
+var fileDialogIFileDialogEventsVtbl *iFileDialogEventsVtbl
+
+func init() {
+	AppendToWalkInit(func() {
+		fileDialogIFileDialogEventsVtbl = &iFileDialogEventsVtbl{
+			syscall.NewCallback(fileDialog_IFileDialogEvents_QueryInterface),
+			syscall.NewCallback(fileDialog_IFileDialogEvents_AddRef),
+			syscall.NewCallback(fileDialog_IFileDialogEvents_Release),
+			syscall.NewCallback(fileDialog_IFileDialogEvents_OnFileOk),
+			syscall.NewCallback(fileDialog_IFileDialogEvents_OnFolderChanging),
+			syscall.NewCallback(fileDialog_IFileDialogEvents_OnFolderChange),
+			syscall.NewCallback(fileDialog_IFileDialogEvents_OnSelectionChange),
+			syscall.NewCallback(fileDialog_IFileDialogEvents_OnShareViolation),
+			syscall.NewCallback(fileDialog_IFileDialogEvents_OnTypeChange),
+			syscall.NewCallback(fileDialog_IFileDialogEvents_OnOverwrite),
+		}
+	})
+}
+
+// fileDialogIFileDialogEvents is how the common item dialog notifies a
+// FileDialog of the filter changing and of existing files about to be
+// overwritten.
+type fileDialogIFileDialogEvents struct {
+	lpVtbl *iFileDialogEventsVtbl
+	dialog *FileDialog
+}
+
+func newFileDialogIFileDialogEvents(dialog *FileDialog) *fileDialogIFileDialogEvents {
+	return &fileDialogIFileDialogEvents{fileDialogIFileDialogEventsVtbl, dialog}
+}
+
+func fileDialog_IFileDialogEvents_QueryInterface(events *fileDialogIFileDialogEvents, riid win.REFIID, ppvObject *unsafe.Pointer) uintptr {
+	if win.EqualREFIID(riid, &win.IID_IUnknown) || win.EqualREFIID(riid, &iid_IFileDialogEvents) {
+		*ppvObject = unsafe.Pointer(events)
+		return win.S_OK
+	}
+
+	*ppvObject = nil
+	return win.E_NOINTERFACE
+}
+
+func fileDialog_IFileDialogEvents_AddRef(events *fileDialogIFileDialogEvents) uintptr {
+	return 1
+}
+
+func fileDialog_IFileDialogEvents_Release(events *fileDialogIFileDialogEvents) uintptr {
+	return 1
+}
+
+func fileDialog_IFileDialogEvents_OnFileOk(events *fileDialogIFileDialogEvents, fd *iFileDialog) uintptr {
+	return win.E_NOTIMPL
+}
+
+func fileDialog_IFileDialogEvents_OnFolderChanging(events *fileDialogIFileDialogEvents, fd *iFileDialog, folder *iShellItem) uintptr {
+	return win.E_NOTIMPL
+}
+
+func fileDialog_IFileDialogEvents_OnFolderChange(events *fileDialogIFileDialogEvents, fd *iFileDialog) uintptr {
+	return win.E_NOTIMPL
+}
+
+func fileDialog_IFileDialogEvents_OnSelectionChange(events *fileDialogIFileDialogEvents, fd *iFileDialog) uintptr {
+	return win.E_NOTIMPL
+}
+
+func fileDialog_IFileDialogEvents_OnShareViolation(events *fileDialogIFileDialogEvents, fd *iFileDialog, item *iShellItem, response *uint32) uintptr {
+	return win.E_NOTIMPL
+}
+
+func fileDialog_IFileDialogEvents_OnTypeChange(events *fileDialogIFileDialogEvents, fd *iFileDialog) uintptr {
+	var index uint32
+	if hr := fd.GetFileTypeIndex(&index); win.FAILED(hr) {
+		return uintptr(hr)
+	}
+
+	events.dialog.applyDefaultExtension(fd, int(index))
+
+	return win.S_OK
+}
+
+func fileDialog_IFileDialogEvents_OnOverwrite(events *fileDialogIFileDialogEvents, fd *iFileDialog, item *iShellItem, response *uint32) uintptr {
+	if events.dialog.OnOverwrite == nil {
+		*response = _FDEOR_DEFAULT
+		return win.S_OK
+	}
+
+	path, err := item.path()
+	if err != nil {
+		*response = _FDEOR_DEFAULT
+		return win.S_OK
+	}
+
+	if events.dialog.OnOverwrite(path) {
+		*response = _FDEOR_ACCEPT
+	} else {
+		*response = _FDEOR_REFUSE
+	}
+
+	return win.S_OK
+}
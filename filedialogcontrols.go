@@ -0,0 +1,113 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"syscall"
+
+	"github.com/tailscale/win"
+)
+
+// fileDialogGroupIDFlag distinguishes the ids of the groups labeling
+// controls from those of the controls.
+const fileDialogGroupIDFlag = 0x8000
+
+// fileDialogControlID returns the id of the control at index in the Controls
+// of a FileDialog.
+func fileDialogControlID(index int) uint32 {
+	return uint32(index + 1)
+}
+
+// FileDialogControl is a control a FileDialog displays in addition to its
+// own, like a FileDialogCheckBox or a FileDialogComboBox.
+type FileDialogControl interface {
+	addTo(fdc *iFileDialogCustomize, id uint32) error
+	readFrom(fdc *iFileDialogCustomize, id uint32) error
+}
+
+// FileDialogCheckBox is a check box displayed by a FileDialog.
+type FileDialogCheckBox struct {
+	// Text is displayed next to the check box.
+	Text string
+
+	// Checked is the initial state of the check box, and the one chosen by
+	// the user after the dialog was accepted.
+	Checked bool
+}
+
+func (cb *FileDialogCheckBox) addTo(fdc *iFileDialogCustomize, id uint32) error {
+	if hr := fdc.AddCheckButton(id, syscall.StringToUTF16Ptr(cb.Text), cb.Checked); win.FAILED(hr) {
+		return errorFromHRESULT("IFileDialogCustomize.AddCheckButton", hr)
+	}
+
+	return nil
+}
+
+func (cb *FileDialogCheckBox) readFrom(fdc *iFileDialogCustomize, id uint32) error {
+	var checked win.BOOL
+	if hr := fdc.GetCheckButtonState(id, &checked); win.FAILED(hr) {
+		return errorFromHRESULT("IFileDialogCustomize.GetCheckButtonState", hr)
+	}
+
+	cb.Checked = checked != 0
+
+	return nil
+}
+
+// FileDialogComboBox is a drop down list displayed by a FileDialog.
+type FileDialogComboBox struct {
+	// Label, if not empty, is displayed before the combo box.
+	Label string
+
+	// Items are the choices offered by the combo box.
+	Items []string
+
+	// CurrentIndex is the index of the item initially chosen, and of the one
+	// chosen by the user after the dialog was accepted, or -1 for none.
+	CurrentIndex int
+}
+
+func (cb *FileDialogComboBox) addTo(fdc *iFileDialogCustomize, id uint32) error {
+	if cb.Label != "" {
+		if hr := fdc.StartVisualGroup(id|fileDialogGroupIDFlag, syscall.StringToUTF16Ptr(cb.Label)); win.FAILED(hr) {
+			return errorFromHRESULT("IFileDialogCustomize.StartVisualGroup", hr)
+		}
+		defer fdc.EndVisualGroup()
+	}
+
+	if hr := fdc.AddComboBox(id); win.FAILED(hr) {
+		return errorFromHRESULT("IFileDialogCustomize.AddComboBox", hr)
+	}
+
+	for i, item := range cb.Items {
+		if hr := fdc.AddControlItem(id, uint32(i), syscall.StringToUTF16Ptr(item)); win.FAILED(hr) {
+			return errorFromHRESULT("IFileDialogCustomize.AddControlItem", hr)
+		}
+	}
+
+	if cb.CurrentIndex >= 0 && cb.CurrentIndex < len(cb.Items) {
+		if hr := fdc.SetSelectedControlItem(id, uint32(cb.CurrentIndex)); win.FAILED(hr) {
+			return errorFromHRESULT("IFileDialogCustomize.SetSelectedControlItem", hr)
+		}
+	}
+
+	return nil
+}
+
+func (cb *FileDialogComboBox) readFrom(fdc *iFileDialogCustomize, id uint32) error {
+	var itemId uint32
+	if hr := fdc.GetSelectedControlItem(id, &itemId); win.FAILED(hr) {
+		// Nothing has been chosen.
+		cb.CurrentIndex = -1
+		return nil
+	}
+
+	cb.CurrentIndex = int(itemId)
+
+	return nil
+}
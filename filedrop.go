@@ -0,0 +1,112 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"syscall"
+
+	"github.com/tailscale/win"
+)
+
+// FileDrop describes files that are dragged over or dropped onto a window.
+type FileDrop struct {
+	// Files holds the paths of the files.
+	Files []string
+
+	// Point is the position of the mouse pointer, in client coordinates of
+	// the window and native pixels.
+	Point Point
+
+	// Modifiers holds the modifier keys that are pressed.
+	Modifiers Modifiers
+}
+
+// EnableFileDrop lets the user drop files onto the *WindowBase, e.g. from
+// Explorer. The files are delivered by FilesDropped.
+//
+// accept decides whether the files being dragged can be dropped at the
+// position of the mouse pointer and may be nil to accept any files. The mouse
+// pointer shows whether they can be dropped while they are being dragged.
+//
+// Files dropped onto descendants of the *WindowBase that do not accept files
+// themselves are dropped onto the *WindowBase, so enabling file drop for a
+// Form covers all of its widgets.
+//
+// If drop is enabled as well, see EnableDrop, handlers of DragEntered and
+// DragMoved take precedence. File drop via EnableFileDrop is independent of
+// the DropFiles event.
+func (wb *WindowBase) EnableFileDrop(accept func(drop *FileDrop) bool) error {
+	if err := wb.ensureDropTarget(); err != nil {
+		return err
+	}
+
+	wb.fileDropEnabled = true
+	wb.fileDropAccept = accept
+
+	return nil
+}
+
+// DisableFileDrop stops the *WindowBase from accepting files dropped onto it.
+func (wb *WindowBase) DisableFileDrop() {
+	wb.fileDropEnabled = false
+	wb.fileDropAccept = nil
+
+	wb.releaseUnusedDropTarget()
+}
+
+// FileDropEnabled returns whether the *WindowBase accepts files dropped onto
+// it.
+func (wb *WindowBase) FileDropEnabled() bool {
+	return wb.fileDropEnabled
+}
+
+// FilesDropped returns the event that is published when files were dropped
+// onto the *WindowBase, once EnableFileDrop was called.
+func (wb *WindowBase) FilesDropped() *GenericEvent[*FileDrop] {
+	return wb.filesDroppedPublisher.Event()
+}
+
+// acceptsFiles returns whether the files of the current drag can be dropped
+// at pt, in screen coordinates, given the effects allowed by the source.
+func (dt *dropTarget) acceptsFiles(keyState uint32, pt win.POINT, allowed uint32) bool {
+	// Dropped files are always copied, as moving them would make the source
+	// delete them.
+	if len(dt.files) == 0 || allowed&_DROPEFFECT_COPY == 0 {
+		return false
+	}
+
+	accept := dt.wb.fileDropAccept
+
+	return accept == nil || accept(dt.newFileDrop(keyState, pt))
+}
+
+func (dt *dropTarget) newFileDrop(keyState uint32, pt win.POINT) *FileDrop {
+	win.ScreenToClient(dt.wb.hWnd, &pt)
+
+	return &FileDrop{
+		Files:     append([]string(nil), dt.files...),
+		Point:     Point{int(pt.X), int(pt.Y)},
+		Modifiers: modifiersFromKeyState(keyState),
+	}
+}
+
+// dragQueryFiles returns the paths of the files in hDrop.
+func dragQueryFiles(hDrop win.HDROP) []string {
+	var files []string
+
+	n := win.DragQueryFile(hDrop, 0xFFFFFFFF, nil, 0)
+	for i := uint(0); i < n; i++ {
+		size := win.DragQueryFile(hDrop, i, nil, 0) + 1
+		buf := make([]uint16, size)
+		if win.DragQueryFile(hDrop, i, &buf[0], size) > 0 {
+			files = append(files, syscall.UTF16ToString(buf))
+		}
+	}
+
+	return files
+}
@@ -0,0 +1,401 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"syscall"
+	"unsafe"
+
+	"github.com/tailscale/win"
+)
+
+const filterComboBoxTextInset96dpi = 2
+
+// FilterComboBox is an editable combo box whose drop down list only shows the
+// items matching the text typed so far, with the matching parts in bold.
+//
+// The items are matched by an ItemFilter, by default one matching the items
+// starting with the text.
+type FilterComboBox struct {
+	WidgetBase
+	items                        []string
+	filter                       ItemFilter
+	matches                      []FilterMatch
+	currentIndex                 int
+	boldFont                     *Font
+	textChangedPublisher         EventPublisher
+	currentIndexChangedPublisher EventPublisher
+}
+
+// NewFilterComboBox creates a new FilterComboBox as child of parent.
+func NewFilterComboBox(parent Container) (*FilterComboBox, error) {
+	fcb := &FilterComboBox{
+		filter:       NewPrefixItemFilter(),
+		currentIndex: -1,
+	}
+
+	if err := InitWidget(
+		fcb,
+		parent,
+		"COMBOBOX",
+		win.WS_TABSTOP|win.WS_VISIBLE|win.WS_VSCROLL|win.CBS_DROPDOWN|win.CBS_AUTOHSCROLL|win.CBS_OWNERDRAWFIXED|win.CBS_HASSTRINGS,
+		0); err != nil {
+		return nil, err
+	}
+
+	succeeded := false
+	defer func() {
+		if !succeeded {
+			fcb.Dispose()
+		}
+	}()
+
+	fcb.GraphicsEffects().Add(InteractionEffect)
+	fcb.GraphicsEffects().Add(FocusEffect)
+
+	fcb.MustRegisterProperty("CurrentIndex", NewProperty(
+		func() interface{} {
+			return fcb.CurrentIndex()
+		},
+		func(v interface{}) error {
+			return fcb.SetCurrentIndex(assertIntOr(v, -1))
+		},
+		fcb.CurrentIndexChanged()))
+
+	fcb.MustRegisterProperty("Text", NewProperty(
+		func() interface{} {
+			return fcb.Text()
+		},
+		func(v interface{}) error {
+			return fcb.SetText(assertStringOr(v, ""))
+		},
+		fcb.TextChanged()))
+
+	fcb.updateItemHeights()
+
+	succeeded = true
+
+	return fcb, nil
+}
+
+func (fcb *FilterComboBox) applyFont(font *Font) {
+	fcb.WidgetBase.applyFont(font)
+
+	fcb.boldFont = nil
+
+	fcb.updateItemHeights()
+}
+
+func (fcb *FilterComboBox) updateItemHeights() {
+	if fcb.hWnd == 0 {
+		return
+	}
+
+	height := fcb.calculateTextSizeImpl("gM").Height + fcb.IntFrom96DPI(2*filterComboBoxTextInset96dpi)
+
+	fcb.SendMessage(win.CB_SETITEMHEIGHT, 0, uintptr(height))
+	fcb.SendMessage(win.CB_SETITEMHEIGHT, ^uintptr(0), uintptr(height))
+}
+
+// Items returns the items offered by the FilterComboBox.
+func (fcb *FilterComboBox) Items() []string {
+	return fcb.items
+}
+
+// SetItems sets the items offered by the FilterComboBox.
+func (fcb *FilterComboBox) SetItems(items []string) error {
+	fcb.items = items
+	fcb.filter.SetItems(items)
+
+	fcb.currentIndex = -1
+	fcb.setMatches(fcb.allMatches())
+
+	fcb.RequestLayout()
+
+	return nil
+}
+
+// Filter returns the ItemFilter matching the items with the text.
+func (fcb *FilterComboBox) Filter() ItemFilter {
+	return fcb.filter
+}
+
+// SetFilter sets the ItemFilter matching the items with the text, like one
+// returned by NewPrefixItemFilter or NewFuzzyItemFilter.
+func (fcb *FilterComboBox) SetFilter(filter ItemFilter) error {
+	if filter == nil {
+		return newError("filter must not be nil")
+	}
+
+	fcb.filter = filter
+	filter.SetItems(fcb.items)
+
+	fcb.setMatches(fcb.allMatches())
+
+	return nil
+}
+
+// CurrentIndex returns the index of the item chosen, or -1 if the text is not
+// one of the items.
+func (fcb *FilterComboBox) CurrentIndex() int {
+	return fcb.currentIndex
+}
+
+// SetCurrentIndex chooses the item at index, or none if index is -1.
+func (fcb *FilterComboBox) SetCurrentIndex(index int) error {
+	if index < -1 || index >= len(fcb.items) {
+		return newError("invalid index")
+	}
+
+	fcb.setMatches(fcb.allMatches())
+
+	fcb.SendMessage(win.CB_SETCURSEL, uintptr(index), 0)
+
+	var text string
+	if index >= 0 {
+		text = fcb.items[index]
+	}
+	if err := fcb.setText(text); err != nil {
+		return err
+	}
+
+	fcb.setCurrentIndex(index)
+	fcb.textChangedPublisher.Publish()
+
+	return nil
+}
+
+func (fcb *FilterComboBox) setCurrentIndex(index int) {
+	if index == fcb.currentIndex {
+		return
+	}
+
+	fcb.currentIndex = index
+	fcb.currentIndexChangedPublisher.Publish()
+}
+
+// CurrentIndexChanged returns the event that is published when another item
+// is chosen, or the text no longer is an item.
+func (fcb *FilterComboBox) CurrentIndexChanged() *Event {
+	return fcb.currentIndexChangedPublisher.Event()
+}
+
+func (fcb *FilterComboBox) Text() string {
+	return fcb.text()
+}
+
+// SetText sets the text, choosing the item equal to it, if any.
+func (fcb *FilterComboBox) SetText(text string) error {
+	index := -1
+	for i, item := range fcb.items {
+		if item == text {
+			index = i
+			break
+		}
+	}
+
+	if index >= 0 {
+		return fcb.SetCurrentIndex(index)
+	}
+
+	fcb.setMatches(fcb.allMatches())
+
+	if err := fcb.setText(text); err != nil {
+		return err
+	}
+
+	fcb.setCurrentIndex(-1)
+	fcb.textChangedPublisher.Publish()
+
+	return nil
+}
+
+func (fcb *FilterComboBox) TextChanged() *Event {
+	return fcb.textChangedPublisher.Event()
+}
+
+func (fcb *FilterComboBox) TextSelection() (start, end int) {
+	var s, e uint32
+	fcb.SendMessage(win.CB_GETEDITSEL, uintptr(unsafe.Pointer(&s)), uintptr(unsafe.Pointer(&e)))
+	return int(s), int(e)
+}
+
+func (fcb *FilterComboBox) SetTextSelection(start, end int) {
+	fcb.SendMessage(win.CB_SETEDITSEL, 0, uintptr(win.MAKELONG(uint16(start), uint16(end))))
+}
+
+func (fcb *FilterComboBox) allMatches() []FilterMatch {
+	matches := make([]FilterMatch, len(fcb.items))
+	for i := range matches {
+		matches[i].Index = i
+	}
+
+	return matches
+}
+
+// setMatches replaces the items of the list with matches, keeping the text
+// and its selection.
+func (fcb *FilterComboBox) setMatches(matches []FilterMatch) {
+	text := fcb.Text()
+	start, end := fcb.TextSelection()
+
+	fcb.matches = matches
+
+	fcb.SendMessage(win.CB_RESETCONTENT, 0, 0)
+	for _, m := range matches {
+		fcb.SendMessage(win.CB_ADDSTRING, 0, uintptr(unsafe.Pointer(syscall.StringToUTF16Ptr(fcb.items[m.Index]))))
+	}
+
+	fcb.setText(text)
+	fcb.SetTextSelection(start, end)
+}
+
+// applyFilter lists the items matching the text typed and drops the list down
+// while there are any.
+func (fcb *FilterComboBox) applyFilter() {
+	text := fcb.Text()
+
+	if text == "" {
+		fcb.setMatches(fcb.allMatches())
+	} else {
+		fcb.setMatches(fcb.filter.Filter(text))
+	}
+
+	dropped := fcb.SendMessage(win.CB_GETDROPPEDSTATE, 0, 0) != 0
+	if dropped {
+		// The list only adapts its height when dropped down.
+		fcb.SendMessage(win.CB_SHOWDROPDOWN, win.FALSE, 0)
+	}
+
+	if text != "" && len(fcb.matches) > 0 {
+		start, end := fcb.TextSelection()
+
+		fcb.SendMessage(win.CB_SHOWDROPDOWN, win.TRUE, 0)
+
+		// Dropping the list down selects the first item starting with the
+		// text and hides the cursor.
+		fcb.SendMessage(win.CB_SETCURSEL, ^uintptr(0), 0)
+		fcb.setText(text)
+		fcb.SetTextSelection(start, end)
+		win.SetCursor(win.LoadCursor(0, win.MAKEINTRESOURCE(win.IDC_ARROW)))
+	}
+
+	index := -1
+	for _, m := range fcb.matches {
+		if fcb.items[m.Index] == text {
+			index = m.Index
+			break
+		}
+	}
+	fcb.setCurrentIndex(index)
+}
+
+func (fcb *FilterComboBox) drawItem(dis *win.DRAWITEMSTRUCT) {
+	bgColor, textColor := win.COLOR_WINDOW, win.COLOR_WINDOWTEXT
+	if dis.ItemState&win.ODS_SELECTED != 0 {
+		bgColor, textColor = win.COLOR_HIGHLIGHT, win.COLOR_HIGHLIGHTTEXT
+	} else if dis.ItemState&win.ODS_DISABLED != 0 {
+		textColor = win.COLOR_GRAYTEXT
+	}
+
+	win.FillRect(dis.HDC, &dis.RcItem, win.GetSysColorBrush(bgColor))
+
+	index := int(int32(dis.ItemID))
+	if index < 0 || index >= len(fcb.matches) {
+		return
+	}
+	m := fcb.matches[index]
+	item := fcb.items[m.Index]
+
+	dpi := fcb.DPI()
+	font := fcb.Font()
+	if fcb.boldFont == nil {
+		fcb.boldFont, _ = NewFont(font.Family(), font.PointSize(), font.Style()|FontBold)
+	}
+	boldFont := font
+	if fcb.boldFont != nil {
+		boldFont = fcb.boldFont
+	}
+
+	oldFont := win.SelectObject(dis.HDC, win.HGDIOBJ(font.handleForDPI(dpi)))
+	defer win.SelectObject(dis.HDC, oldFont)
+
+	win.SetBkMode(dis.HDC, win.TRANSPARENT)
+	win.SetTextColor(dis.HDC, win.COLORREF(win.GetSysColor(textColor)))
+
+	rc := dis.RcItem
+	rc.Left += int32(fcb.IntFrom96DPI(filterComboBoxTextInset96dpi))
+
+	drawSegment := func(text string, f *Font) {
+		if text == "" || rc.Left >= rc.Right {
+			return
+		}
+
+		win.SelectObject(dis.HDC, win.HGDIOBJ(f.handleForDPI(dpi)))
+
+		buf := syscall.StringToUTF16(text)
+		win.DrawTextEx(dis.HDC, &buf[0], int32(len(buf)-1), &rc, win.DT_SINGLELINE|win.DT_VCENTER|win.DT_NOPREFIX, nil)
+
+		var size win.SIZE
+		win.GetTextExtentPoint32(dis.HDC, &buf[0], int32(len(buf)-1), &size)
+		rc.Left += size.CX
+	}
+
+	var pos int
+	for _, h := range m.Highlights {
+		if h[0] < pos || h[1] > len(item) || h[0] > h[1] {
+			continue
+		}
+
+		drawSegment(item[pos:h[0]], font)
+		drawSegment(item[h[0]:h[1]], boldFont)
+		pos = h[1]
+	}
+	drawSegment(item[pos:], font)
+
+	if dis.ItemState&win.ODS_FOCUS != 0 && dis.ItemState&win.ODS_NOFOCUSRECT == 0 {
+		win.DrawFocusRect(dis.HDC, &dis.RcItem)
+	}
+}
+
+func (*FilterComboBox) NeedsWmSize() bool {
+	return true
+}
+
+func (fcb *FilterComboBox) WndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) uintptr {
+	switch msg {
+	case win.WM_COMMAND:
+		switch win.HIWORD(uint32(wParam)) {
+		case win.CBN_EDITCHANGE:
+			fcb.applyFilter()
+			fcb.textChangedPublisher.Publish()
+
+		case win.CBN_SELCHANGE:
+			if sel := int(int32(fcb.SendMessage(win.CB_GETCURSEL, 0, 0))); sel >= 0 && sel < len(fcb.matches) {
+				fcb.setCurrentIndex(fcb.matches[sel].Index)
+				fcb.textChangedPublisher.Publish()
+			}
+		}
+
+	case win.WM_DRAWITEM:
+		fcb.drawItem((*win.DRAWITEMSTRUCT)(unsafe.Pointer(lParam)))
+
+		return win.TRUE
+	}
+
+	return fcb.WidgetBase.WndProc(hwnd, msg, wParam, lParam)
+}
+
+func (fcb *FilterComboBox) CreateLayoutItem(ctx *LayoutContext) LayoutItem {
+	defaultSize := fcb.dialogBaseUnitsToPixels(Size{30, 12})
+
+	return &comboBoxLayoutItem{
+		layoutFlags: GrowableHorz | GreedyHorz,
+		idealSize:   Size{defaultSize.Width, defaultSize.Height + 1},
+	}
+}
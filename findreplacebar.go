@@ -0,0 +1,698 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/tailscale/win"
+)
+
+// findHighlightROP is the ternary raster operation DPa, which combines the
+// destination with the brush using AND. It tints the background of light
+// themes while leaving dark text readable.
+const findHighlightROP = 0x00A000C9
+
+var (
+	findHighlightColor        = RGB(0xFF, 0xF0, 0x80)
+	findHighlightCurrentColor = RGB(0xFF, 0xB0, 0x40)
+)
+
+// FindReplaceTarget is a text widget a FindReplaceBar can search, like a
+// TextEdit or a RichTextEdit.
+type FindReplaceTarget interface {
+	Widget
+	ReadOnly() bool
+	ReplaceSelectedText(text string, canUndo bool)
+	SetTextSelection(start, end int)
+	TextChanged() *Event
+	TextSelection() (start, end int)
+	searchText() string
+	setFindHighlights(highlights findHighlights)
+}
+
+// FindReplaceBarPosition is where a FindReplaceBar docks relative to its
+// target.
+type FindReplaceBarPosition int
+
+const (
+	FindReplaceBarAbove FindReplaceBarPosition = iota
+	FindReplaceBarBelow
+)
+
+// textMatch is a match of the query of a FindReplaceBar.
+type textMatch struct {
+	// start and end are positions in UTF-16 code units, like those of
+	// selections.
+	start, end int
+
+	// text is the matched text.
+	text string
+
+	// submatches are the byte offsets of the submatches in the searched text.
+	submatches []int
+}
+
+// findHighlights are the matches a FindReplaceBar highlights in its target.
+type findHighlights struct {
+	matches []textMatch
+	current int // index into matches, or -1
+}
+
+// paint highlights the matches in the edit control of wb, where posFromChar
+// returns the client position in native pixels of the character at an index.
+func (fh *findHighlights) paint(wb *WindowBase, posFromChar func(index int) (Point, bool)) {
+	if len(fh.matches) == 0 {
+		return
+	}
+
+	hdc := win.GetDC(wb.hWnd)
+	defer win.ReleaseDC(wb.hWnd, hdc)
+
+	brush, err := NewSolidColorBrush(findHighlightColor)
+	if err != nil {
+		return
+	}
+	defer brush.Dispose()
+
+	currentBrush, err := NewSolidColorBrush(findHighlightCurrentColor)
+	if err != nil {
+		return
+	}
+	defer currentBrush.Dispose()
+
+	oldBrush := win.SelectObject(hdc, win.HGDIOBJ(brush.hBrush))
+	defer win.SelectObject(hdc, oldBrush)
+
+	cb := wb.ClientBoundsPixels()
+	lineHeight := wb.calculateTextSizeImpl("gM").Height
+
+	for i, m := range fh.matches {
+		p0, ok := posFromChar(m.start)
+		if !ok || p0.Y+lineHeight <= 0 {
+			continue
+		}
+		if p0.Y >= cb.Height {
+			break
+		}
+
+		// Matches spanning lines are only highlighted on their first line.
+		x1 := p0.X + wb.calculateTextSizeImpl(strings.SplitN(m.text, "\r", 2)[0]).Width
+		if p1, ok := posFromChar(m.end); ok && p1.Y == p0.Y {
+			x1 = p1.X
+		}
+
+		if i == fh.current {
+			win.SelectObject(hdc, win.HGDIOBJ(currentBrush.hBrush))
+		}
+		win.BitBlt(hdc, int32(p0.X), int32(p0.Y), int32(x1-p0.X), int32(lineHeight), 0, 0, 0, findHighlightROP)
+		if i == fh.current {
+			win.SelectObject(hdc, win.HGDIOBJ(brush.hBrush))
+		}
+	}
+}
+
+// FindReplaceBar is a bar for finding and replacing text in a TextEdit or
+// RichTextEdit. It docks above or below its target and is hidden until the
+// user presses Ctrl+F or Ctrl+H in the target. F3 and Shift+F3 move to the
+// next and previous match, and Escape hides the bar.
+//
+// All matches are highlighted while the bar is visible. The query can be
+// matched case sensitively, as a whole word or as a regular expression, in
+// which case replacements can refer to submatches like in
+// regexp.Regexp.Expand.
+type FindReplaceBar struct {
+	*Composite
+	target           FindReplaceTarget
+	findEdit         *LineEdit
+	previousButton   *PushButton
+	nextButton       *PushButton
+	matchCase        *CheckBox
+	wholeWord        *CheckBox
+	useRegexp        *CheckBox
+	status           *Label
+	closeButton      *PushButton
+	replaceEdit      *LineEdit
+	replaceButton    *PushButton
+	replaceAllButton *PushButton
+	text             string
+	re               *regexp.Regexp
+	matches          []textMatch
+	anchor           int
+	replacing        bool
+}
+
+// NewFindReplaceBar returns a new hidden FindReplaceBar for target, inserted
+// into the children of the parent of target at position. The parent must lay
+// out its children in order, like with a box layout.
+func NewFindReplaceBar(target FindReplaceTarget, position FindReplaceBarPosition) (*FindReplaceBar, error) {
+	parent := target.Parent()
+	if parent == nil {
+		return nil, newError("target has no parent")
+	}
+
+	composite, err := NewComposite(parent)
+	if err != nil {
+		return nil, err
+	}
+
+	frb := &FindReplaceBar{Composite: composite, target: target}
+
+	succeeded := false
+	defer func() {
+		if !succeeded {
+			frb.Dispose()
+		}
+	}()
+
+	if err := InitWrapperWindow(frb); err != nil {
+		return nil, err
+	}
+
+	frb.SetVisible(false)
+
+	children := parent.Children()
+	if err := children.Remove(frb); err != nil {
+		return nil, err
+	}
+	index := children.Index(target)
+	if position == FindReplaceBarBelow {
+		index++
+	}
+	if err := children.Insert(index, frb); err != nil {
+		return nil, err
+	}
+
+	layout := NewGridLayout()
+	layout.SetMargins(Margins{})
+	if err := frb.SetLayout(layout); err != nil {
+		return nil, err
+	}
+
+	if frb.findEdit, err = NewLineEdit(frb); err != nil {
+		return nil, err
+	}
+	if err := frb.findEdit.SetCueBanner(tr("Find", "walk")); err != nil {
+		return nil, err
+	}
+	frb.findEdit.TextChanged().Attach(func() {
+		frb.search(false)
+	})
+	frb.findEdit.KeyDown().Attach(func(key Key) {
+		if key == KeyReturn {
+			if ShiftDown() {
+				frb.FindPrevious()
+			} else {
+				frb.FindNext()
+			}
+		}
+	})
+
+	if frb.previousButton, err = frb.newButton(tr("Previous", "walk"), func() {
+		frb.FindPrevious()
+	}); err != nil {
+		return nil, err
+	}
+	if frb.nextButton, err = frb.newButton(tr("Next", "walk"), func() {
+		frb.FindNext()
+	}); err != nil {
+		return nil, err
+	}
+
+	if frb.matchCase, err = frb.newOption(tr("Match case", "walk")); err != nil {
+		return nil, err
+	}
+	if frb.wholeWord, err = frb.newOption(tr("Whole word", "walk")); err != nil {
+		return nil, err
+	}
+	if frb.useRegexp, err = frb.newOption(tr("Regular expression", "walk")); err != nil {
+		return nil, err
+	}
+
+	if frb.status, err = NewLabel(frb); err != nil {
+		return nil, err
+	}
+
+	if frb.closeButton, err = frb.newButton(tr("Close", "walk"), frb.Close); err != nil {
+		return nil, err
+	}
+
+	if frb.replaceEdit, err = NewLineEdit(frb); err != nil {
+		return nil, err
+	}
+	if err := frb.replaceEdit.SetCueBanner(tr("Replace", "walk")); err != nil {
+		return nil, err
+	}
+	frb.replaceEdit.KeyDown().Attach(func(key Key) {
+		if key == KeyReturn {
+			frb.Replace()
+		}
+	})
+
+	if frb.replaceButton, err = frb.newButton(tr("Replace", "walk"), frb.Replace); err != nil {
+		return nil, err
+	}
+	if frb.replaceAllButton, err = frb.newButton(tr("Replace All", "walk"), func() {
+		frb.ReplaceAll()
+	}); err != nil {
+		return nil, err
+	}
+
+	for _, c := range []struct {
+		widget Widget
+		bounds Rectangle
+	}{
+		{frb.findEdit, Rectangle{0, 0, 1, 1}},
+		{frb.previousButton, Rectangle{1, 0, 1, 1}},
+		{frb.nextButton, Rectangle{2, 0, 1, 1}},
+		{frb.matchCase, Rectangle{3, 0, 1, 1}},
+		{frb.wholeWord, Rectangle{4, 0, 1, 1}},
+		{frb.useRegexp, Rectangle{5, 0, 1, 1}},
+		{frb.status, Rectangle{6, 0, 1, 1}},
+		{frb.closeButton, Rectangle{7, 0, 1, 1}},
+		{frb.replaceEdit, Rectangle{0, 1, 1, 1}},
+		{frb.replaceButton, Rectangle{1, 1, 1, 1}},
+		{frb.replaceAllButton, Rectangle{2, 1, 2, 1}},
+	} {
+		if err := layout.SetRange(c.widget, c.bounds); err != nil {
+			return nil, err
+		}
+	}
+	if err := layout.SetColumnStretchFactor(0, 1); err != nil {
+		return nil, err
+	}
+
+	for _, w := range []Widget{frb.findEdit, frb.replaceEdit, target} {
+		w.KeyDown().Attach(frb.handleKeyDown)
+	}
+
+	target.TextChanged().Attach(func() {
+		if frb.Visible() && !frb.replacing {
+			frb.search(true)
+		}
+	})
+
+	frb.setReplaceVisible(false)
+
+	succeeded = true
+
+	return frb, nil
+}
+
+func (frb *FindReplaceBar) newButton(text string, clicked func()) (*PushButton, error) {
+	pb, err := NewPushButton(frb)
+	if err != nil {
+		return nil, err
+	}
+	if err := pb.SetText(text); err != nil {
+		return nil, err
+	}
+	pb.Clicked().Attach(clicked)
+
+	return pb, nil
+}
+
+func (frb *FindReplaceBar) newOption(text string) (*CheckBox, error) {
+	cb, err := NewCheckBox(frb)
+	if err != nil {
+		return nil, err
+	}
+	if err := cb.SetText(text); err != nil {
+		return nil, err
+	}
+	cb.CheckedChanged().Attach(func() {
+		frb.search(true)
+	})
+
+	return cb, nil
+}
+
+func (frb *FindReplaceBar) handleKeyDown(key Key) {
+	switch {
+	case key == KeyF && ControlDown():
+		frb.ShowFind()
+
+	case key == KeyH && ControlDown():
+		frb.ShowReplace()
+
+	case key == KeyF3:
+		if ShiftDown() {
+			frb.FindPrevious()
+		} else {
+			frb.FindNext()
+		}
+
+	case key == KeyEscape && frb.Visible():
+		frb.Close()
+	}
+}
+
+// Target returns the widget searched by the bar.
+func (frb *FindReplaceBar) Target() FindReplaceTarget {
+	return frb.target
+}
+
+// FindText returns the query the bar searches for.
+func (frb *FindReplaceBar) FindText() string {
+	return frb.findEdit.Text()
+}
+
+// SetFindText sets the query the bar searches for.
+func (frb *FindReplaceBar) SetFindText(text string) error {
+	return frb.findEdit.SetText(text)
+}
+
+// ReplaceText returns the text matches are replaced with.
+func (frb *FindReplaceBar) ReplaceText() string {
+	return frb.replaceEdit.Text()
+}
+
+// SetReplaceText sets the text matches are replaced with.
+func (frb *FindReplaceBar) SetReplaceText(text string) error {
+	return frb.replaceEdit.SetText(text)
+}
+
+// MatchCase returns whether the query is matched case sensitively.
+func (frb *FindReplaceBar) MatchCase() bool {
+	return frb.matchCase.Checked()
+}
+
+// SetMatchCase sets whether the query is matched case sensitively.
+func (frb *FindReplaceBar) SetMatchCase(matchCase bool) {
+	frb.matchCase.SetChecked(matchCase)
+}
+
+// WholeWord returns whether the query only matches whole words.
+func (frb *FindReplaceBar) WholeWord() bool {
+	return frb.wholeWord.Checked()
+}
+
+// SetWholeWord sets whether the query only matches whole words.
+func (frb *FindReplaceBar) SetWholeWord(wholeWord bool) {
+	frb.wholeWord.SetChecked(wholeWord)
+}
+
+// UseRegexp returns whether the query is a regular expression in the syntax of
+// package regexp.
+func (frb *FindReplaceBar) UseRegexp() bool {
+	return frb.useRegexp.Checked()
+}
+
+// SetUseRegexp sets whether the query is a regular expression in the syntax of
+// package regexp.
+func (frb *FindReplaceBar) SetUseRegexp(useRegexp bool) {
+	frb.useRegexp.SetChecked(useRegexp)
+}
+
+// ShowFind displays the bar without its replace controls and focuses the
+// query, which is initialized with the text selected in the target, if any.
+func (frb *FindReplaceBar) ShowFind() {
+	frb.show(false)
+}
+
+// ShowReplace displays the bar with its replace controls, unless the target
+// is read-only, and focuses the query.
+func (frb *FindReplaceBar) ShowReplace() {
+	frb.show(!frb.target.ReadOnly())
+}
+
+func (frb *FindReplaceBar) show(replace bool) {
+	start, end := frb.target.TextSelection()
+	frb.anchor = start
+
+	if start < end {
+		if units := utf16.Encode([]rune(frb.target.searchText())); end <= len(units) {
+			if selected := string(utf16.Decode(units[start:end])); !strings.ContainsAny(selected, "\r\n") {
+				frb.findEdit.SetText(selected)
+			}
+		}
+	}
+
+	frb.setReplaceVisible(replace)
+	frb.SetVisible(true)
+
+	frb.findEdit.SetFocus()
+	frb.findEdit.SetTextSelection(0, -1)
+
+	frb.search(true)
+}
+
+// Close hides the bar and its highlights and focuses the target.
+func (frb *FindReplaceBar) Close() {
+	frb.SetVisible(false)
+
+	frb.matches = nil
+	frb.target.setFindHighlights(findHighlights{current: -1})
+
+	frb.target.SetFocus()
+}
+
+func (frb *FindReplaceBar) setReplaceVisible(visible bool) {
+	for _, w := range []Widget{frb.replaceEdit, frb.replaceButton, frb.replaceAllButton} {
+		w.SetVisible(visible)
+	}
+}
+
+// FindNext selects the next match after the selection in the target, wrapping
+// around at the end of the text, and reports whether there is one.
+func (frb *FindReplaceBar) FindNext() bool {
+	frb.search(true)
+
+	_, end := frb.target.TextSelection()
+
+	for i, m := range frb.matches {
+		if m.start >= end {
+			return frb.selectMatch(i)
+		}
+	}
+
+	return frb.selectMatch(0)
+}
+
+// FindPrevious selects the previous match before the selection in the target,
+// wrapping around at the start of the text, and reports whether there is one.
+func (frb *FindReplaceBar) FindPrevious() bool {
+	frb.search(true)
+
+	start, _ := frb.target.TextSelection()
+
+	for i := len(frb.matches) - 1; i >= 0; i-- {
+		if frb.matches[i].end <= start {
+			return frb.selectMatch(i)
+		}
+	}
+
+	return frb.selectMatch(len(frb.matches) - 1)
+}
+
+// Replace replaces the selected match, if any, and selects the next one.
+func (frb *FindReplaceBar) Replace() {
+	if frb.target.ReadOnly() {
+		return
+	}
+
+	frb.search(true)
+
+	if i := frb.selectedMatch(); i >= 0 {
+		m := frb.matches[i]
+
+		frb.target.ReplaceSelectedText(frb.replacement(m), true)
+
+		frb.search(true)
+	}
+
+	frb.FindNext()
+}
+
+// ReplaceAll replaces all matches and returns how many there were.
+func (frb *FindReplaceBar) ReplaceAll() int {
+	if frb.target.ReadOnly() {
+		return 0
+	}
+
+	frb.search(true)
+
+	matches := frb.matches
+
+	frb.replacing = true
+	// Going backwards keeps the positions of the remaining matches valid.
+	for i := len(matches) - 1; i >= 0; i-- {
+		m := matches[i]
+
+		frb.target.SetTextSelection(m.start, m.end)
+		frb.target.ReplaceSelectedText(frb.replacement(m), true)
+	}
+	frb.replacing = false
+
+	frb.search(true)
+
+	return len(matches)
+}
+
+func (frb *FindReplaceBar) replacement(m textMatch) string {
+	if !frb.useRegexp.Checked() {
+		return frb.replaceEdit.Text()
+	}
+
+	return string(frb.re.ExpandString(nil, frb.replaceEdit.Text(), frb.text, m.submatches))
+}
+
+// selectedMatch returns the index of the match selected in the target, or -1.
+func (frb *FindReplaceBar) selectedMatch() int {
+	start, end := frb.target.TextSelection()
+
+	for i, m := range frb.matches {
+		if m.start == start && m.end == end {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func (frb *FindReplaceBar) selectMatch(index int) bool {
+	if index < 0 || index >= len(frb.matches) {
+		return false
+	}
+
+	m := frb.matches[index]
+	frb.target.SetTextSelection(m.start, m.end)
+	frb.target.SendMessage(win.EM_SCROLLCARET, 0, 0)
+	frb.anchor = m.start
+
+	frb.updateHighlights()
+
+	return true
+}
+
+// search finds the matches of the query in the target. Unless keepSelection
+// is set, it selects the first match at or after the position the search
+// started at, so the selection follows the query as it is typed.
+func (frb *FindReplaceBar) search(keepSelection bool) {
+	frb.matches = nil
+	frb.re = nil
+
+	query := frb.findEdit.Text()
+	if query == "" {
+		frb.status.SetText("")
+		frb.updateHighlights()
+		return
+	}
+
+	pattern := query
+	if !frb.useRegexp.Checked() {
+		pattern = regexp.QuoteMeta(pattern)
+	}
+	if frb.wholeWord.Checked() {
+		pattern = `\b(?:` + pattern + `)\b`
+	}
+	if !frb.matchCase.Checked() {
+		pattern = "(?i)" + pattern
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		frb.status.SetText(tr("Invalid expression", "walk"))
+		frb.updateHighlights()
+		return
+	}
+
+	frb.re = re
+	frb.text = frb.target.searchText()
+
+	all := re.FindAllStringSubmatchIndex(frb.text, -1)
+
+	offsets := make([]int, 0, 2*len(all))
+	for _, loc := range all {
+		offsets = append(offsets, loc[0], loc[1])
+	}
+	offsets = utf16Offsets(frb.text, offsets)
+
+	for i, loc := range all {
+		if loc[0] == loc[1] {
+			continue
+		}
+
+		frb.matches = append(frb.matches, textMatch{
+			start:      offsets[2*i],
+			end:        offsets[2*i+1],
+			text:       frb.text[loc[0]:loc[1]],
+			submatches: loc,
+		})
+	}
+
+	if !keepSelection {
+		index := -1
+		for i, m := range frb.matches {
+			if m.start >= frb.anchor {
+				index = i
+				break
+			}
+		}
+		if index == -1 && len(frb.matches) > 0 {
+			index = 0
+		}
+
+		if index >= 0 {
+			frb.selectMatch(index)
+			return
+		}
+	}
+
+	frb.updateHighlights()
+}
+
+func (frb *FindReplaceBar) updateHighlights() {
+	current := frb.selectedMatch()
+
+	switch {
+	case frb.re == nil:
+
+	case len(frb.matches) == 0:
+		frb.status.SetText(tr("No matches", "walk"))
+
+	case current >= 0:
+		frb.status.SetText(fmt.Sprintf(tr("%d of %d", "walk"), current+1, len(frb.matches)))
+
+	default:
+		frb.status.SetText(fmt.Sprintf(tr("%d matches", "walk"), len(frb.matches)))
+	}
+
+	enabled := len(frb.matches) > 0
+	for _, w := range []Widget{frb.previousButton, frb.nextButton, frb.replaceButton, frb.replaceAllButton} {
+		w.SetEnabled(enabled)
+	}
+
+	frb.target.setFindHighlights(findHighlights{frb.matches, current})
+}
+
+// utf16Offsets converts offsets, which are ascending byte offsets into s, to
+// offsets in UTF-16 code units.
+func utf16Offsets(s string, offsets []int) []int {
+	result := make([]int, len(offsets))
+
+	var b, u int
+	for i, offset := range offsets {
+		for b < offset {
+			r, size := utf8.DecodeRuneInString(s[b:])
+			b += size
+			if r >= 0x10000 {
+				u += 2
+			} else {
+				u++
+			}
+		}
+		result[i] = u
+	}
+
+	return result
+}
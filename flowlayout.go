@@ -10,9 +10,42 @@ import (
 	"github.com/tailscale/win"
 )
 
+// FlowJustification specifies how the items of a FlowLayout row are
+// distributed along the row.
+type FlowJustification int
+
+const (
+	// FlowJustifyDefault lays out a row like an HBoxLayout would, honoring
+	// stretch factors and the alignment of the layout.
+	FlowJustifyDefault FlowJustification = iota
+
+	// FlowJustifyNear packs the items of a row at their minimum width towards
+	// the start of the row.
+	FlowJustifyNear
+
+	// FlowJustifyCenter packs the items of a row at their minimum width
+	// in the center of the row.
+	FlowJustifyCenter
+
+	// FlowJustifyFar packs the items of a row at their minimum width towards
+	// the end of the row.
+	FlowJustifyFar
+
+	// FlowJustifySpaceBetween keeps the items of a row at their minimum width
+	// and distributes the remaining space evenly between them.
+	FlowJustifySpaceBetween
+)
+
 type FlowLayout struct {
 	LayoutBase
-	hwnd2StretchFactor map[win.HWND]int
+	hwnd2StretchFactor   map[win.HWND]int
+	hwnd2Alignment       map[win.HWND]Alignment1D
+	hSpacing96dpi        int
+	vSpacing96dpi        int
+	rowAlignment         Alignment1D
+	justification        FlowJustification
+	lastRowJustification FlowJustification
+	rightToLeft          bool
 }
 
 func NewFlowLayout() *FlowLayout {
@@ -22,6 +55,9 @@ func NewFlowLayout() *FlowLayout {
 			spacing96dpi: 6,
 		},
 		hwnd2StretchFactor: make(map[win.HWND]int),
+		hwnd2Alignment:     make(map[win.HWND]Alignment1D),
+		hSpacing96dpi:      -1,
+		vSpacing96dpi:      -1,
 	}
 	l.layout = l
 
@@ -59,23 +95,215 @@ func (l *FlowLayout) SetStretchFactor(widget Widget, factor int) error {
 	return nil
 }
 
+// HSpacing returns the horizontal spacing between the items of a row in
+// 1/96" units. Unless set explicitly, it follows Spacing.
+func (l *FlowLayout) HSpacing() int {
+	if l.hSpacing96dpi < 0 {
+		return l.spacing96dpi
+	}
+
+	return l.hSpacing96dpi
+}
+
+// SetHSpacing sets the horizontal spacing between the items of a row in
+// 1/96" units.
+func (l *FlowLayout) SetHSpacing(value int) error {
+	return l.setSpacing96dpi(&l.hSpacing96dpi, value)
+}
+
+// VSpacing returns the vertical spacing between rows in 1/96" units.
+// Unless set explicitly, it follows Spacing.
+func (l *FlowLayout) VSpacing() int {
+	if l.vSpacing96dpi < 0 {
+		return l.spacing96dpi
+	}
+
+	return l.vSpacing96dpi
+}
+
+// SetVSpacing sets the vertical spacing between rows in 1/96" units.
+func (l *FlowLayout) SetVSpacing(value int) error {
+	return l.setSpacing96dpi(&l.vSpacing96dpi, value)
+}
+
+func (l *FlowLayout) setSpacing96dpi(spacing *int, value int) error {
+	if value == *spacing {
+		return nil
+	}
+
+	if value < 0 {
+		return newError("spacing cannot be negative")
+	}
+
+	*spacing = value
+
+	if l.container != nil {
+		l.container.RequestLayout()
+	}
+
+	return nil
+}
+
+// RowAlignment returns the default vertical alignment of items within their
+// row.
+func (l *FlowLayout) RowAlignment() Alignment1D {
+	return l.rowAlignment
+}
+
+// SetRowAlignment sets the default vertical alignment of items within their
+// row. AlignDefault positions items according to the layout's Alignment.
+func (l *FlowLayout) SetRowAlignment(alignment Alignment1D) error {
+	if alignment != l.rowAlignment {
+		if alignment < AlignDefault || alignment > AlignFar {
+			return newError("invalid Alignment value")
+		}
+
+		l.rowAlignment = alignment
+
+		if l.container != nil {
+			l.container.RequestLayout()
+		}
+	}
+
+	return nil
+}
+
+// ItemAlignment returns the vertical alignment of widget within its row.
+func (l *FlowLayout) ItemAlignment(widget Widget) Alignment1D {
+	if alignment, ok := l.hwnd2Alignment[widget.Handle()]; ok {
+		return alignment
+	}
+
+	return AlignDefault
+}
+
+// SetItemAlignment sets the vertical alignment of widget within its row,
+// overriding RowAlignment. AlignDefault reverts to RowAlignment.
+func (l *FlowLayout) SetItemAlignment(widget Widget, alignment Alignment1D) error {
+	if alignment != l.ItemAlignment(widget) {
+		if l.container == nil {
+			return newError("container required")
+		}
+
+		handle := widget.Handle()
+
+		if !l.container.Children().containsHandle(handle) {
+			return newError("unknown widget")
+		}
+		if alignment < AlignDefault || alignment > AlignFar {
+			return newError("invalid Alignment value")
+		}
+
+		if alignment == AlignDefault {
+			delete(l.hwnd2Alignment, handle)
+		} else {
+			l.hwnd2Alignment[handle] = alignment
+		}
+
+		l.container.RequestLayout()
+	}
+
+	return nil
+}
+
+// Justification returns how the items of all rows but the last one are
+// distributed along their row.
+func (l *FlowLayout) Justification() FlowJustification {
+	return l.justification
+}
+
+// SetJustification sets how the items of all rows but the last one are
+// distributed along their row.
+func (l *FlowLayout) SetJustification(justification FlowJustification) error {
+	return l.setJustification(&l.justification, justification)
+}
+
+// LastRowJustification returns how the items of the last row are distributed
+// along the row.
+func (l *FlowLayout) LastRowJustification() FlowJustification {
+	return l.lastRowJustification
+}
+
+// SetLastRowJustification sets how the items of the last row are distributed
+// along the row.
+func (l *FlowLayout) SetLastRowJustification(justification FlowJustification) error {
+	return l.setJustification(&l.lastRowJustification, justification)
+}
+
+func (l *FlowLayout) setJustification(target *FlowJustification, justification FlowJustification) error {
+	if justification == *target {
+		return nil
+	}
+
+	if justification < FlowJustifyDefault || justification > FlowJustifySpaceBetween {
+		return newError("invalid FlowJustification value")
+	}
+
+	*target = justification
+
+	if l.container != nil {
+		l.container.RequestLayout()
+	}
+
+	return nil
+}
+
+// RightToLeft returns whether items flow from the right to the left.
+func (l *FlowLayout) RightToLeft() bool {
+	return l.rightToLeft
+}
+
+// SetRightToLeft sets whether items flow from the right to the left.
+//
+// There is no need to set this when the container already mirrors its
+// coordinates, e.g. because of Form.SetRightToLeftLayout.
+func (l *FlowLayout) SetRightToLeft(rightToLeft bool) {
+	if rightToLeft == l.rightToLeft {
+		return
+	}
+
+	l.rightToLeft = rightToLeft
+
+	if l.container != nil {
+		l.container.RequestLayout()
+	}
+}
+
 func (l *FlowLayout) CreateLayoutItem(ctx *LayoutContext) ContainerLayoutItem {
 	li := &flowLayoutItem{
-		size2MinSize:       make(map[Size]Size),
-		hwnd2StretchFactor: make(map[win.HWND]int),
+		size2MinSize:         make(map[Size]Size),
+		hwnd2StretchFactor:   make(map[win.HWND]int),
+		hwnd2Alignment:       make(map[win.HWND]Alignment1D),
+		hSpacing96dpi:        l.HSpacing(),
+		vSpacing96dpi:        l.VSpacing(),
+		rowAlignment:         l.rowAlignment,
+		justification:        l.justification,
+		lastRowJustification: l.lastRowJustification,
+		rightToLeft:          l.rightToLeft,
 	}
 
 	for hwnd, sf := range l.hwnd2StretchFactor {
 		li.hwnd2StretchFactor[hwnd] = sf
 	}
 
+	for hwnd, alignment := range l.hwnd2Alignment {
+		li.hwnd2Alignment[hwnd] = alignment
+	}
+
 	return li
 }
 
 type flowLayoutItem struct {
 	ContainerLayoutItemBase
-	size2MinSize       map[Size]Size // in native pixels
-	hwnd2StretchFactor map[win.HWND]int
+	size2MinSize         map[Size]Size // in native pixels
+	hwnd2StretchFactor   map[win.HWND]int
+	hwnd2Alignment       map[win.HWND]Alignment1D
+	hSpacing96dpi        int
+	vSpacing96dpi        int
+	rowAlignment         Alignment1D
+	justification        FlowJustification
+	lastRowJustification FlowJustification
+	rightToLeft          bool
 }
 
 type flowLayoutSection struct {
@@ -106,7 +334,8 @@ func (li *flowLayoutItem) MinSizeForSize(size Size) Size {
 		return min
 	}
 
-	spacing := IntFrom96DPI(li.spacing96dpi, li.ctx.dpi)
+	hSpacing := IntFrom96DPI(li.hSpacing96dpi, li.ctx.dpi)
+	vSpacing := IntFrom96DPI(li.vSpacing96dpi, li.ctx.dpi)
 	margins := MarginsFrom96DPI(li.margins96dpi, li.ctx.dpi)
 
 	bounds := Rectangle{Width: size.Width}
@@ -117,50 +346,34 @@ func (li *flowLayoutItem) MinSizeForSize(size Size) Size {
 	var maxPrimary int
 
 	for i, section := range sections {
-		var items []LayoutItem
 		var sectionMinWidth int
 		for _, sectionItem := range section.items {
-			items = append(items, sectionItem.item)
-
 			sectionMinWidth += sectionItem.minSize.Width
 		}
-		sectionMinWidth += (len(section.items) - 1) * spacing
+		sectionMinWidth += (len(section.items) - 1) * hSpacing
 		maxPrimary = maxi(maxPrimary, sectionMinWidth)
 
 		bounds.Height = section.secondaryMinSize
 
-		margins96dpi := li.margins96dpi
-		if i > 0 {
-			margins96dpi.VNear = 0
-		}
-		if i < len(sections)-1 {
-			margins96dpi.VFar = 0
-		}
+		margins96dpi := li.sectionMargins96dpi(i, len(sections))
 
-		layoutItems := boxLayoutItems(li, items, Horizontal, li.alignment, bounds, margins96dpi, li.spacing96dpi, li.hwnd2StretchFactor)
+		layoutItems := li.sectionLayoutItems(section, bounds, margins96dpi, i == len(sections)-1)
 
 		var maxSecondary int
 
 		for _, item := range layoutItems {
-			if hfw, ok := item.Item.(HeightForWidther); ok && hfw.HasHeightForWidth() {
-				item.Bounds.Height = hfw.HeightForWidth(item.Bounds.Width)
-			} else {
-				min := li.MinSizeEffectiveForChild(item.Item)
-				item.Bounds.Height = min.Height
-			}
-
-			maxSecondary = maxi(maxSecondary, item.Bounds.Height)
+			maxSecondary = maxi(maxSecondary, li.itemHeight(item))
 		}
 
 		s.Height += maxSecondary
 
-		bounds.Y += maxSecondary + spacing
+		bounds.Y += maxSecondary + vSpacing
 	}
 
 	s.Width = maxPrimary
 
 	s.Width += margins.HNear + margins.HFar
-	s.Height += margins.VNear + margins.VFar + (len(sections)-1)*spacing
+	s.Height += margins.VNear + margins.VFar + (len(sections)-1)*vSpacing
 
 	if s.Width > 0 && s.Height > 0 {
 		li.size2MinSize[size] = s
@@ -170,7 +383,7 @@ func (li *flowLayoutItem) MinSizeForSize(size Size) Size {
 }
 
 func (li *flowLayoutItem) PerformLayout() []LayoutResultItem {
-	spacing := IntFrom96DPI(li.spacing96dpi, li.ctx.dpi)
+	vSpacing := IntFrom96DPI(li.vSpacing96dpi, li.ctx.dpi)
 	bounds := Rectangle{Width: li.geometry.ClientSize.Width, Height: li.geometry.ClientSize.Height}
 
 	sections := li.sectionsForPrimarySize(bounds.Width)
@@ -178,51 +391,177 @@ func (li *flowLayoutItem) PerformLayout() []LayoutResultItem {
 	var resultItems []LayoutResultItem
 
 	for i, section := range sections {
-		var items []LayoutItem
-		for _, sectionItem := range section.items {
-			items = append(items, sectionItem.item)
-		}
-
 		bounds.Height = section.secondaryMinSize
 
-		margins96dpi := li.margins96dpi
-		if i > 0 {
-			margins96dpi.VNear = 0
-		}
-		if i < len(sections)-1 {
-			margins96dpi.VFar = 0
-		}
+		margins96dpi := li.sectionMargins96dpi(i, len(sections))
+		last := i == len(sections)-1
 
-		layoutItems := boxLayoutItems(li, items, Horizontal, li.alignment, bounds, margins96dpi, li.spacing96dpi, li.hwnd2StretchFactor)
+		layoutItems := li.sectionLayoutItems(section, bounds, margins96dpi, last)
 
 		margins := MarginsFrom96DPI(margins96dpi, li.ctx.dpi)
 
 		var maxSecondary int
+		heights := make([]int, len(layoutItems))
 
-		for _, item := range layoutItems {
-			if hfw, ok := item.Item.(HeightForWidther); ok && hfw.HasHeightForWidth() {
-				item.Bounds.Height = hfw.HeightForWidth(item.Bounds.Width)
-			} else {
-				item.Bounds.Height = li.MinSizeEffectiveForChild(item.Item).Height
-			}
+		for j, item := range layoutItems {
+			heights[j] = li.itemHeight(item)
 
-			maxSecondary = maxi(maxSecondary, item.Bounds.Height)
+			maxSecondary = maxi(maxSecondary, heights[j])
 		}
 
 		bounds.Height = maxSecondary + margins.VNear + margins.VFar
 
-		resultItems = append(resultItems, boxLayoutItems(li, items, Horizontal, li.alignment, bounds, margins96dpi, li.spacing96dpi, li.hwnd2StretchFactor)...)
+		layoutItems = li.sectionLayoutItems(section, bounds, margins96dpi, last)
+
+		rowY := bounds.Y + margins.VNear
+
+		for j := range layoutItems {
+			item := &layoutItems[j]
+
+			alignment, ok := li.hwnd2Alignment[item.Item.Handle()]
+			if !ok {
+				alignment = li.rowAlignment
+			}
+
+			switch alignment {
+			case AlignNear:
+				item.Bounds.Y = rowY
+
+			case AlignCenter:
+				item.Bounds.Y = rowY + (maxSecondary-heights[j])/2
+
+			case AlignFar:
+				item.Bounds.Y = rowY + maxSecondary - heights[j]
 
-		bounds.Y += bounds.Height + spacing
+			default:
+				continue
+			}
+
+			item.Bounds.Height = heights[j]
+		}
+
+		resultItems = append(resultItems, layoutItems...)
+
+		bounds.Y += bounds.Height + vSpacing
+	}
+
+	if li.rightToLeft {
+		for i := range resultItems {
+			b := &resultItems[i].Bounds
+			b.X = bounds.Width - b.X - b.Width
+		}
 	}
 
 	return resultItems
 }
 
+// sectionMargins96dpi returns the margins of section i of count sections in
+// 1/96" units.
+func (li *flowLayoutItem) sectionMargins96dpi(i, count int) Margins {
+	margins96dpi := li.margins96dpi
+	if i > 0 {
+		margins96dpi.VNear = 0
+	}
+	if i < count-1 {
+		margins96dpi.VFar = 0
+	}
+
+	return margins96dpi
+}
+
+// itemHeight returns the height of a laid out item in native pixels.
+func (li *flowLayoutItem) itemHeight(item LayoutResultItem) int {
+	if hfw, ok := item.Item.(HeightForWidther); ok && hfw.HasHeightForWidth() {
+		return hfw.HeightForWidth(item.Bounds.Width)
+	}
+
+	return li.MinSizeEffectiveForChild(item.Item).Height
+}
+
+// sectionLayoutItems lays out the items of section horizontally. bounds
+// parameter is in native pixels.
+func (li *flowLayoutItem) sectionLayoutItems(section flowLayoutSection, bounds Rectangle, margins96dpi Margins, last bool) []LayoutResultItem {
+	justification := li.justification
+	if last {
+		justification = li.lastRowJustification
+	}
+
+	if justification == FlowJustifyDefault {
+		items := make([]LayoutItem, len(section.items))
+		for i, sectionItem := range section.items {
+			items[i] = sectionItem.item
+		}
+
+		return boxLayoutItems(li, items, Horizontal, li.alignment, bounds, margins96dpi, li.hSpacing96dpi, li.hwnd2StretchFactor)
+	}
+
+	if len(section.items) == 0 {
+		return nil
+	}
+
+	margins := MarginsFrom96DPI(margins96dpi, li.ctx.dpi)
+	hSpacing := IntFrom96DPI(li.hSpacing96dpi, li.ctx.dpi)
+
+	gaps := len(section.items) - 1
+
+	used := gaps * hSpacing
+	for _, sectionItem := range section.items {
+		used += sectionItem.minSize.Width
+	}
+
+	excess := maxi(0, bounds.Width-margins.HNear-margins.HFar-used)
+
+	x := bounds.X + margins.HNear
+	switch justification {
+	case FlowJustifyCenter:
+		x += excess / 2
+
+	case FlowJustifyFar:
+		x += excess
+	}
+
+	y := bounds.Y + margins.VNear
+	height := bounds.Height - margins.VNear - margins.VFar
+
+	results := make([]LayoutResultItem, len(section.items))
+
+	for i, sectionItem := range section.items {
+		itemX := x
+		if justification == FlowJustifySpaceBetween && gaps > 0 {
+			itemX += excess * i / gaps
+		}
+
+		bounds := Rectangle{X: itemX, Width: sectionItem.minSize.Width}
+
+		if sectionItem.item.LayoutFlags()&GrowableVert != 0 {
+			bounds.Height = height
+		} else {
+			bounds.Height = mini(height, li.itemHeight(LayoutResultItem{Item: sectionItem.item, Bounds: bounds}))
+		}
+
+		switch li.alignment {
+		case AlignHNearVNear, AlignHCenterVNear, AlignHFarVNear:
+			bounds.Y = y
+
+		case AlignHNearVFar, AlignHCenterVFar, AlignHFarVFar:
+			bounds.Y = y + height - bounds.Height
+
+		default:
+			bounds.Y = y + (height-bounds.Height)/2
+		}
+
+		results[i] = LayoutResultItem{Item: sectionItem.item, Bounds: bounds}
+
+		x += sectionItem.minSize.Width + hSpacing
+	}
+
+	return results
+}
+
 // sectionsForPrimarySize calculates sections for primary width in native pixels.
 func (li *flowLayoutItem) sectionsForPrimarySize(primarySize int) []flowLayoutSection {
 	margins := MarginsFrom96DPI(li.margins96dpi, li.ctx.dpi)
-	spacing := IntFrom96DPI(li.spacing96dpi, li.ctx.dpi)
+	spacing := IntFrom96DPI(li.hSpacing96dpi, li.ctx.dpi)
 
 	var sections []flowLayoutSection
 
@@ -0,0 +1,179 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+
+	"github.com/tailscale/win"
+)
+
+// fontDialogMaxPointSize is the largest size the font dialog accepts.
+const fontDialogMaxPointSize = 1638
+
+// fontDialogsByHwnd maps the dialogs currently displayed with an Apply button
+// to their FontDialog.
+var fontDialogsByHwnd = make(map[win.HWND]*FontDialog)
+
+var fontDialogHookProcPtr uintptr
+
+func init() {
+	AppendToWalkInit(func() {
+		fontDialogHookProcPtr = syscall.NewCallback(fontDialogHookProc)
+	})
+}
+
+// FontDialog lets the user pick a font using the common font dialog.
+type FontDialog struct {
+	// Font is the font initially selected, and the one picked after the
+	// dialog was accepted.
+	Font *Font
+
+	// Color is the text color initially selected, and the one picked after
+	// the dialog was accepted. It can only be changed if Effects is set.
+	Color Color
+
+	// Effects makes the dialog offer the strikeout and underline styles and
+	// the text color.
+	Effects bool
+
+	// ScriptFiltering makes the dialog list only the fonts supporting the
+	// character set Charset, like win.GREEK_CHARSET.
+	ScriptFiltering bool
+	Charset         byte
+
+	// FixedScript prevents the user from picking another script.
+	FixedScript bool
+
+	// FixedPitchOnly makes the dialog list only monospaced fonts.
+	FixedPitchOnly bool
+
+	// MinPointSize and MaxPointSize limit the sizes the user can pick, if
+	// they are not 0.
+	MinPointSize int
+	MaxPointSize int
+
+	// Apply, if not nil, makes the dialog display an Apply button, which
+	// passes the font and color currently selected to Apply without closing
+	// the dialog.
+	Apply func(font *Font, color Color)
+}
+
+// Show displays the dialog modal to owner, which may be nil.
+func (dlg *FontDialog) Show(owner Form) (accepted bool, err error) {
+	dpi := screenDPI()
+
+	var lf win.LOGFONT
+	if dlg.Font != nil {
+		if p := dlg.Font.LOGFONTForDPI(dpi); p != nil {
+			lf = *p
+		}
+	}
+
+	var cf chooseFont
+	cf.lStructSize = uint32(unsafe.Sizeof(cf))
+	if owner != nil {
+		cf.hwndOwner = owner.Handle()
+	}
+	cf.lpLogFont = &lf
+	cf.rgbColors = win.COLORREF(dlg.Color)
+	cf.flags = _CF_SCREENFONTS | _CF_NOVERTFONTS
+	if dlg.Font != nil {
+		cf.flags |= _CF_INITTOLOGFONTSTRUCT
+	}
+	if dlg.Effects {
+		cf.flags |= _CF_EFFECTS
+	}
+	if dlg.ScriptFiltering {
+		lf.LfCharSet = dlg.Charset
+		cf.flags |= _CF_SELECTSCRIPT | _CF_INITTOLOGFONTSTRUCT
+	}
+	if dlg.FixedScript {
+		cf.flags |= _CF_NOSCRIPTSEL
+	}
+	if dlg.FixedPitchOnly {
+		cf.flags |= _CF_FIXEDPITCHONLY
+	}
+	if dlg.MinPointSize > 0 || dlg.MaxPointSize > 0 {
+		cf.flags |= _CF_LIMITSIZE
+		cf.nSizeMin = int32(dlg.MinPointSize)
+		cf.nSizeMax = fontDialogMaxPointSize
+		if dlg.MaxPointSize > 0 {
+			cf.nSizeMax = int32(dlg.MaxPointSize)
+		}
+	}
+	if dlg.Apply != nil {
+		cf.flags |= _CF_APPLY | _CF_ENABLEHOOK
+		cf.lpfnHook = fontDialogHookProcPtr
+		cf.lCustData = uintptr(unsafe.Pointer(dlg))
+	}
+
+	if !showChooseFont(&cf) {
+		if errno := win.CommDlgExtendedError(); errno != 0 {
+			err = newError(fmt.Sprintf("Error %d", errno))
+		}
+		return
+	}
+
+	font, err := newFontFromLOGFONT(&lf, dpi)
+	if err != nil {
+		return false, err
+	}
+
+	dlg.Font = font
+	if dlg.Effects {
+		dlg.Color = Color(cf.rgbColors)
+	}
+
+	return true, nil
+}
+
+// apply passes the current selection of the dialog hwnd to Apply.
+func (dlg *FontDialog) apply(hwnd win.HWND) {
+	var lf win.LOGFONT
+	win.SendMessage(hwnd, _WM_CHOOSEFONT_GETLOGFONT, 0, uintptr(unsafe.Pointer(&lf)))
+
+	font, err := newFontFromLOGFONT(&lf, screenDPI())
+	if err != nil {
+		return
+	}
+
+	color := dlg.Color
+	if dlg.Effects {
+		// The dialog provides the color only when it is closed, so it is
+		// taken from the color combo box.
+		hwndColor := win.GetDlgItem(hwnd, _CMB4)
+		if index := win.SendMessage(hwndColor, win.CB_GETCURSEL, 0, 0); index != win.CB_ERR {
+			color = Color(win.SendMessage(hwndColor, win.CB_GETITEMDATA, index, 0))
+		}
+	}
+
+	dlg.Apply(font, color)
+}
+
+func fontDialogHookProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) uintptr {
+	switch msg {
+	case win.WM_INITDIALOG:
+		cf := (*chooseFont)(unsafe.Pointer(lParam))
+		fontDialogsByHwnd[hwnd] = (*FontDialog)(unsafe.Pointer(cf.lCustData))
+
+	case win.WM_COMMAND:
+		if win.LOWORD(uint32(wParam)) == _PSH3 {
+			if dlg, ok := fontDialogsByHwnd[hwnd]; ok {
+				dlg.apply(hwnd)
+			}
+		}
+
+	case win.WM_DESTROY:
+		delete(fontDialogsByHwnd, hwnd)
+	}
+
+	return 0
+}
@@ -0,0 +1,487 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"unsafe"
+
+	"github.com/tailscale/win"
+)
+
+const (
+	fontFamilyComboBoxItemHeight96dpi = 22
+	fontFamilyComboBoxTextInset96dpi  = 4
+	fontPickerSizeMaxWidth96dpi       = 56
+)
+
+var fontPickerPointSizes = []int{8, 9, 10, 11, 12, 14, 16, 18, 20, 22, 24, 26, 28, 36, 48, 72}
+
+// FontPicker is a widget for picking a font. It combines a list of the
+// installed font families, each rendered in its own face, with a list of
+// sizes and toggles for the bold, italic and underline styles.
+type FontPicker struct {
+	*Composite
+	family                       *fontFamilyComboBox
+	size                         *ComboBox
+	toggles                      []*fontPickerToggle
+	selectedFont                 *Font
+	updating                     bool
+	selectedFontChangedPublisher EventPublisher
+}
+
+type fontPickerToggle struct {
+	*CheckBox
+	style FontStyle
+}
+
+// NewFontPicker returns a new FontPicker as child of parent, initially
+// selecting the font of parent.
+func NewFontPicker(parent Container) (*FontPicker, error) {
+	composite, err := NewComposite(parent)
+	if err != nil {
+		return nil, err
+	}
+
+	fp := &FontPicker{Composite: composite}
+
+	succeeded := false
+	defer func() {
+		if !succeeded {
+			fp.Dispose()
+		}
+	}()
+
+	if err := InitWrapperWindow(fp); err != nil {
+		return nil, err
+	}
+
+	layout := NewHBoxLayout()
+	layout.SetMargins(Margins{})
+	if err := fp.SetLayout(layout); err != nil {
+		return nil, err
+	}
+
+	if fp.family, err = newFontFamilyComboBox(fp); err != nil {
+		return nil, err
+	}
+	fp.family.CurrentIndexChanged().Attach(func() {
+		fp.updateFromControls(fp.selectedFont.PointSize())
+	})
+
+	if fp.size, err = NewComboBox(fp); err != nil {
+		return nil, err
+	}
+	sizes := make([]string, len(fontPickerPointSizes))
+	for i, size := range fontPickerPointSizes {
+		sizes[i] = strconv.Itoa(size)
+	}
+	if err := fp.size.SetModel(sizes); err != nil {
+		return nil, err
+	}
+	if err := fp.size.SetMinMaxSize(Size{}, Size{fontPickerSizeMaxWidth96dpi, 0}); err != nil {
+		return nil, err
+	}
+	fp.size.CurrentIndexChanged().Attach(func() {
+		if i := fp.size.CurrentIndex(); i >= 0 {
+			fp.updateFromControls(fontPickerPointSizes[i])
+		}
+	})
+	fp.size.EditingFinished().Attach(func() {
+		size, err := strconv.Atoi(strings.TrimSpace(fp.size.Text()))
+		if err != nil || size <= 0 {
+			fp.updateControls()
+			return
+		}
+
+		fp.updateFromControls(size)
+	})
+
+	for _, t := range []struct {
+		text    string
+		toolTip string
+		style   FontStyle
+	}{
+		{tr("B", "walk"), tr("Bold", "walk"), FontBold},
+		{tr("I", "walk"), tr("Italic", "walk"), FontItalic},
+		{tr("U", "walk"), tr("Underline", "walk"), FontUnderline},
+	} {
+		cb, err := NewCheckBox(fp)
+		if err != nil {
+			return nil, err
+		}
+		if err := cb.ensureStyleBits(win.BS_PUSHLIKE, true); err != nil {
+			return nil, err
+		}
+		if err := cb.SetText(t.text); err != nil {
+			return nil, err
+		}
+		if err := cb.SetToolTipText(t.toolTip); err != nil {
+			return nil, err
+		}
+		cb.CheckedChanged().Attach(func() {
+			fp.updateFromControls(fp.selectedFont.PointSize())
+		})
+
+		fp.toggles = append(fp.toggles, &fontPickerToggle{cb, t.style})
+	}
+
+	fp.applyToggleFonts(fp.Font())
+
+	if err := fp.SetSelectedFont(fp.Font()); err != nil {
+		return nil, err
+	}
+
+	fp.MustRegisterProperty("SelectedFont", NewProperty(
+		func() interface{} {
+			return fp.SelectedFont()
+		},
+		func(v interface{}) error {
+			font, ok := v.(*Font)
+			if !ok {
+				return ErrInvalidType
+			}
+
+			return fp.SetSelectedFont(font)
+		},
+		fp.selectedFontChangedPublisher.Event()))
+
+	succeeded = true
+
+	return fp, nil
+}
+
+func (fp *FontPicker) applyFont(font *Font) {
+	fp.Composite.applyFont(font)
+
+	fp.applyToggleFonts(font)
+}
+
+// applyToggleFonts renders the text of each style toggle in its style.
+func (fp *FontPicker) applyToggleFonts(font *Font) {
+	if font == nil {
+		return
+	}
+
+	for _, toggle := range fp.toggles {
+		if f, err := NewFont(font.Family(), font.PointSize(), toggle.style); err == nil {
+			toggle.SetFont(f)
+		}
+	}
+}
+
+// SelectedFont returns the font picked by the user.
+func (fp *FontPicker) SelectedFont() *Font {
+	return fp.selectedFont
+}
+
+// SetSelectedFont sets the font displayed as picked.
+func (fp *FontPicker) SetSelectedFont(font *Font) error {
+	if font == nil {
+		return newError("font cannot be nil")
+	}
+
+	if font == fp.selectedFont {
+		return nil
+	}
+
+	fp.selectedFont = font
+
+	fp.updateControls()
+
+	fp.selectedFontChangedPublisher.Publish()
+
+	return nil
+}
+
+// SelectedFontChanged returns the event that is published when the selected
+// font changes.
+func (fp *FontPicker) SelectedFontChanged() *Event {
+	return fp.selectedFontChangedPublisher.Event()
+}
+
+// ShowFontDialog opens a FontDialog for picking a font with more options.
+func (fp *FontPicker) ShowFontDialog() (accepted bool, err error) {
+	dlg := &FontDialog{Font: fp.selectedFont}
+
+	if accepted, err = dlg.Show(fp.Form()); accepted {
+		err = fp.SetSelectedFont(dlg.Font)
+	}
+
+	return
+}
+
+// updateControls makes the controls display the selected font.
+func (fp *FontPicker) updateControls() {
+	fp.updating = true
+	defer func() {
+		fp.updating = false
+	}()
+
+	fp.family.SetCurrentIndex(fp.family.indexOf(fp.selectedFont.Family()))
+
+	sizeIndex := -1
+	for i, size := range fontPickerPointSizes {
+		if size == fp.selectedFont.PointSize() {
+			sizeIndex = i
+			break
+		}
+	}
+	fp.size.SetCurrentIndex(sizeIndex)
+	if sizeIndex == -1 {
+		fp.size.SetText(strconv.Itoa(fp.selectedFont.PointSize()))
+	}
+
+	for _, toggle := range fp.toggles {
+		toggle.SetChecked(fp.selectedFont.Style()&toggle.style != 0)
+	}
+}
+
+// updateFromControls selects the font the controls display, with the
+// specified size.
+func (fp *FontPicker) updateFromControls(pointSize int) {
+	if fp.updating {
+		return
+	}
+
+	family := fp.selectedFont.Family()
+	if i := fp.family.CurrentIndex(); i >= 0 {
+		family = fp.family.families[i]
+	}
+
+	// The strikeout style has no toggle and is kept.
+	style := fp.selectedFont.Style() & FontStrikeOut
+	for _, toggle := range fp.toggles {
+		if toggle.Checked() {
+			style |= toggle.style
+		}
+	}
+
+	font, err := NewFont(family, pointSize, style)
+	if err != nil {
+		return
+	}
+
+	fp.SetSelectedFont(font)
+}
+
+// fontFamilyComboBox is a drop down box listing the installed font families,
+// each rendered in its own face.
+type fontFamilyComboBox struct {
+	ComboBox
+	families []string
+	charsets map[string]byte
+	hFonts   map[string]win.HFONT
+}
+
+func newFontFamilyComboBox(parent Container) (*fontFamilyComboBox, error) {
+	ffcb := new(fontFamilyComboBox)
+
+	if err := ffcb.ComboBox.init(
+		ffcb,
+		parent,
+		win.CBS_DROPDOWNLIST|win.CBS_OWNERDRAWFIXED|win.CBS_HASSTRINGS); err != nil {
+		return nil, err
+	}
+
+	ffcb.families, ffcb.charsets = fontFamilies()
+
+	if err := ffcb.SetModel(ffcb.families); err != nil {
+		ffcb.Dispose()
+		return nil, err
+	}
+
+	ffcb.updateItemHeights()
+
+	return ffcb, nil
+}
+
+func (ffcb *fontFamilyComboBox) Dispose() {
+	ffcb.disposePreviewFonts()
+
+	ffcb.ComboBox.Dispose()
+}
+
+func (ffcb *fontFamilyComboBox) applyFont(font *Font) {
+	ffcb.ComboBox.applyFont(font)
+
+	// The previews are rendered in the size of the font.
+	ffcb.disposePreviewFonts()
+	ffcb.updateItemHeights()
+}
+
+func (ffcb *fontFamilyComboBox) disposePreviewFonts() {
+	for family, hFont := range ffcb.hFonts {
+		win.DeleteObject(win.HGDIOBJ(hFont))
+		delete(ffcb.hFonts, family)
+	}
+}
+
+// indexOf returns the index of family, or -1 if it is not installed.
+func (ffcb *fontFamilyComboBox) indexOf(family string) int {
+	for i, f := range ffcb.families {
+		if strings.EqualFold(f, family) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func (ffcb *fontFamilyComboBox) updateItemHeights() {
+	if ffcb.hWnd == 0 {
+		return
+	}
+
+	height := ffcb.IntFrom96DPI(fontFamilyComboBoxItemHeight96dpi)
+
+	ffcb.SendMessage(win.CB_SETITEMHEIGHT, 0, uintptr(height))
+
+	// The selection field keeps the height of regular combo boxes.
+	fieldHeight := ffcb.calculateTextSizeImpl("gM").Height + ffcb.IntFrom96DPI(2)
+	ffcb.SendMessage(win.CB_SETITEMHEIGHT, ^uintptr(0), uintptr(fieldHeight))
+}
+
+// previewFont returns the font for rendering the name of family.
+func (ffcb *fontFamilyComboBox) previewFont(family string) win.HFONT {
+	font := ffcb.Font()
+	dpi := ffcb.DPI()
+
+	// The names of symbol fonts would be unreadable in their own face.
+	charset := ffcb.charsets[family]
+	if charset == win.SYMBOL_CHARSET {
+		return font.handleForDPI(dpi)
+	}
+
+	if hFont, ok := ffcb.hFonts[family]; ok {
+		return hFont
+	}
+
+	lf := font.LOGFONTForDPI(dpi)
+	if lf == nil {
+		return font.handleForDPI(dpi)
+	}
+
+	lf.LfCharSet = charset
+	lf.LfFaceName = [win.LF_FACESIZE]uint16{}
+	copy(lf.LfFaceName[:win.LF_FACESIZE-1], syscall.StringToUTF16(family))
+
+	hFont := win.CreateFontIndirect(lf)
+	if hFont == 0 {
+		return font.handleForDPI(dpi)
+	}
+
+	if ffcb.hFonts == nil {
+		ffcb.hFonts = make(map[string]win.HFONT)
+	}
+	ffcb.hFonts[family] = hFont
+
+	return hFont
+}
+
+func (ffcb *fontFamilyComboBox) drawItem(dis *win.DRAWITEMSTRUCT) {
+	bgColor, textColor := win.COLOR_WINDOW, win.COLOR_WINDOWTEXT
+	if dis.ItemState&win.ODS_SELECTED != 0 {
+		bgColor, textColor = win.COLOR_HIGHLIGHT, win.COLOR_HIGHLIGHTTEXT
+	} else if dis.ItemState&win.ODS_DISABLED != 0 {
+		textColor = win.COLOR_GRAYTEXT
+	}
+
+	win.FillRect(dis.HDC, &dis.RcItem, win.GetSysColorBrush(bgColor))
+
+	index := int(int32(dis.ItemID))
+	if index < 0 || index >= len(ffcb.families) {
+		return
+	}
+	family := ffcb.families[index]
+
+	oldFont := win.SelectObject(dis.HDC, win.HGDIOBJ(ffcb.previewFont(family)))
+	defer win.SelectObject(dis.HDC, oldFont)
+
+	win.SetBkMode(dis.HDC, win.TRANSPARENT)
+	win.SetTextColor(dis.HDC, win.COLORREF(win.GetSysColor(textColor)))
+
+	rc := dis.RcItem
+	rc.Left += int32(ffcb.IntFrom96DPI(fontFamilyComboBoxTextInset96dpi))
+
+	text := syscall.StringToUTF16(family)
+	win.DrawTextEx(
+		dis.HDC,
+		&text[0],
+		int32(len(text)-1),
+		&rc,
+		win.DT_SINGLELINE|win.DT_VCENTER|win.DT_NOPREFIX|win.DT_END_ELLIPSIS,
+		nil)
+
+	if dis.ItemState&win.ODS_FOCUS != 0 && dis.ItemState&win.ODS_NOFOCUSRECT == 0 {
+		win.DrawFocusRect(dis.HDC, &dis.RcItem)
+	}
+}
+
+func (ffcb *fontFamilyComboBox) WndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) uintptr {
+	switch msg {
+	case win.WM_DRAWITEM:
+		ffcb.drawItem((*win.DRAWITEMSTRUCT)(unsafe.Pointer(lParam)))
+
+		return win.TRUE
+	}
+
+	return ffcb.ComboBox.WndProc(hwnd, msg, wParam, lParam)
+}
+
+var fontFamiliesCallbackPtr uintptr
+
+func init() {
+	AppendToWalkInit(func() {
+		fontFamiliesCallbackPtr = syscall.NewCallback(fontFamiliesCallback)
+	})
+}
+
+func fontFamiliesCallback(lf *win.LOGFONT, tm *win.TEXTMETRIC, fontType uint32, lParam uintptr) uintptr {
+	charsets := *(*map[string]byte)(unsafe.Pointer(lParam))
+
+	family := win.UTF16PtrToString(&lf.LfFaceName[0])
+
+	// Families prefixed with @ are the vertical variants of others.
+	if strings.HasPrefix(family, "@") {
+		return 1
+	}
+
+	// A family is enumerated once per character set it supports.
+	if _, ok := charsets[family]; !ok || lf.LfCharSet == win.ANSI_CHARSET {
+		charsets[family] = lf.LfCharSet
+	}
+
+	return 1
+}
+
+// fontFamilies returns the names of the installed font families in
+// alphabetical order, along with a character set each supports.
+func fontFamilies() ([]string, map[string]byte) {
+	hdc := win.GetDC(0)
+	defer win.ReleaseDC(0, hdc)
+
+	charsets := make(map[string]byte)
+
+	lf := win.LOGFONT{LfCharSet: win.DEFAULT_CHARSET}
+	enumFontFamiliesEx(hdc, &lf, fontFamiliesCallbackPtr, uintptr(unsafe.Pointer(&charsets)))
+
+	families := make([]string, 0, len(charsets))
+	for family := range charsets {
+		families = append(families, family)
+	}
+
+	sort.Slice(families, func(i, j int) bool {
+		return strings.ToLower(families[i]) < strings.ToLower(families[j])
+	})
+
+	return families, charsets
+}
@@ -33,6 +33,10 @@ var (
 	taskbarButtonCreatedMsgId uint32
 
 	activeForm *FormBase
+
+	// lastActiveForm is the Form that was active most recently, even if the
+	// application is not active anymore.
+	lastActiveForm *FormBase
 )
 
 func init() {
@@ -68,6 +72,37 @@ type Form interface {
 	// SetRightToLeftLayout sets whether coordinates on the x axis of the
 	// Form increase from right to left.
 	SetRightToLeftLayout(rtl bool) error
+
+	// SizeToContent returns whether the Form sizes itself to the ideal size
+	// of its content.
+	SizeToContent() bool
+
+	// SetSizeToContent sets whether the Form sizes itself to the ideal size
+	// of its content.
+	SetSizeToContent(value bool)
+
+	// DPIChanged returns the event that is published after the Form has
+	// been rescaled to a new DPI, e.g. because it was moved to a monitor
+	// with a different scale factor.
+	DPIChanged() *GenericEvent[DPIChange]
+
+	// Shortcuts returns the ShortcutMap that binds keyboard shortcuts of the
+	// Form to handlers.
+	Shortcuts() *ShortcutMap
+
+	// SaveWindowPlacement writes the position, size and maximized state of
+	// the Form and the monitor it is on to App().Settings().
+	SaveWindowPlacement() error
+
+	// RestoreWindowPlacement restores what SaveWindowPlacement wrote, making
+	// sure the Form ends up on a monitor that is currently available.
+	RestoreWindowPlacement() error
+}
+
+// DPIChange describes a change of the DPI of a Form.
+type DPIChange struct {
+	Old int
+	New int
 }
 
 type FormBase struct {
@@ -86,9 +121,21 @@ type FormBase struct {
 	startingPublisher           EventPublisher
 	titleChangedPublisher       EventPublisher
 	iconChangedPublisher        EventPublisher
+	dpiChangedPublisher         GenericEventPublisher[DPIChange]
 	progressIndicator           *ProgressIndicator
+	taskbarButton               *TaskbarButton
+	shortcuts                   *ShortcutMap
+	opacity                     float64
+	layered                     bool
+	fade                        *formFade
+	cornerPreference            CornerPreference
 	icon                        Image
 	prevFocusHWnd               win.HWND
+	initialFocus                Widget
+	startupPosition             StartupPosition
+	startupPositionApplied      bool
+	requestingAttention         bool
+	liveRegionHWnd              win.HWND
 	proposedSize                Size // in native pixels
 	closeReason                 CloseReason
 	inSizingLoop                bool
@@ -96,6 +143,29 @@ type FormBase struct {
 	isInRestoreState            bool
 	started                     bool
 	layoutScheduled             bool
+	sizeToContent               bool
+	sizedToContent              bool
+	dpi                         int // DPI the Form was last scaled to
+	customMaximizeButton        Widget
+	customMaximizeButtonPressed bool
+	customCaption               bool
+	borderless                  bool
+	fullscreen                  *fullscreenState
+	fullscreenOptions           FullscreenOptions
+	fullscreenChangedPublisher  EventPublisher
+	alwaysOnTop                 bool
+	alwaysOnTopChangedPublisher EventPublisher
+	captionWidgets              []Widget
+	clientDrag                  bool
+	clientDragPredicate         func(window Window) bool
+	windowState                 WindowState
+	position                    Point // in native pixels, while not minimized
+	monitor                     win.HMONITOR
+	minimizedPublisher          EventPublisher
+	maximizedPublisher          EventPublisher
+	restoredPublisher           EventPublisher
+	movedPublisher              EventPublisher
+	movedToMonitorPublisher     EventPublisher
 }
 
 func (fb *FormBase) init(form Form) error {
@@ -143,6 +213,9 @@ func (fb *FormBase) init(form Form) error {
 		win.ChangeWindowMessageFilterEx(fb.hWnd, taskbarButtonCreatedMsgId, win.MSGFLT_ALLOW, nil)
 	}
 
+	fb.dpi = fb.DPI()
+	fb.alwaysOnTop = win.GetWindowLong(fb.hWnd, win.GWL_EXSTYLE)&win.WS_EX_TOPMOST != 0
+
 	fb.performLayout, fb.layoutResults, fb.inSizeLoop, fb.updateStopwatch, fb.quitLayoutPerformer = startLayoutPerformer(fb)
 	return nil
 }
@@ -164,6 +237,7 @@ func (fb *FormBase) start() {
 	}
 
 	fb.started = true
+	fb.initKeyboardCues()
 	fb.startingPublisher.Publish()
 
 	fb.SetBoundsPixels(fb.BoundsPixels())
@@ -181,8 +255,19 @@ func (fb *FormBase) start() {
 func (fb *FormBase) Dispose() {
 	if fb.hWnd != 0 && fb.quitLayoutPerformer != nil {
 		fb.quitLayoutPerformer <- struct{}{}
+		fb.performLayout = nil
+	}
+
+	if fb.taskbarButton != nil {
+		fb.taskbarButton.releaseThumbnailButtons()
+	}
+
+	if lastActiveForm == fb {
+		lastActiveForm = nil
 	}
 
+	fb.disposeLiveRegion()
+
 	fb.WindowBase.Dispose()
 }
 
@@ -366,6 +451,88 @@ func (fb *FormBase) SetRightToLeftLayout(rtl bool) error {
 	return fb.ensureExtendedStyleBits(win.WS_EX_LAYOUTRTL, rtl)
 }
 
+// SizeToContent returns whether the FormBase sizes itself to the ideal size
+// of its content.
+func (fb *FormBase) SizeToContent() bool {
+	return fb.sizeToContent
+}
+
+// SetSizeToContent sets whether the FormBase sizes itself to the ideal size
+// of its content.
+//
+// The FormBase is centered in its owner or on its monitor when it is first
+// sized to its content and resized again whenever its content changes,
+// honoring MinSize and MaxSize. Resizing the FormBase interactively turns
+// this off.
+func (fb *FormBase) SetSizeToContent(value bool) {
+	if value == fb.sizeToContent {
+		return
+	}
+
+	fb.sizeToContent = value
+
+	if value && fb.started {
+		fb.startLayout()
+	}
+}
+
+// applySizeToContent resizes the FormBase to the ideal size of its content.
+func (fb *FormBase) applySizeToContent() {
+	if fb.clientComposite.layout == nil {
+		return
+	}
+
+	li := CreateLayoutItemsForContainer(fb.clientComposite)
+
+	cs := li.MinSize()
+	if is, ok := li.(IdealSizer); ok {
+		cs = maxSize(cs, is.IdealSize())
+	}
+	if li.HasHeightForWidth() {
+		cs.Height = maxi(li.MinSizeForSize(cs).Height, li.HeightForWidth(cs.Width))
+	}
+
+	size := maxSize(fb.sizeFromClientSizePixels(cs), fb.MinSizePixels())
+	if max := fb.MaxSizePixels(); max.Width > 0 || max.Height > 0 {
+		if max.Width > 0 {
+			size.Width = mini(size.Width, max.Width)
+		}
+		if max.Height > 0 {
+			size.Height = mini(size.Height, max.Height)
+		}
+	}
+
+	b := fb.BoundsPixels()
+
+	if fb.sizedToContent && size == b.Size() {
+		return
+	}
+
+	bounds := Rectangle{X: b.X, Y: b.Y, Width: size.Width, Height: size.Height}
+
+	if !fb.sizedToContent {
+		fb.sizedToContent = true
+
+		area := b
+		if fb.owner != nil {
+			area = fb.owner.BoundsPixels()
+		} else {
+			var mi win.MONITORINFO
+			mi.CbSize = uint32(unsafe.Sizeof(mi))
+
+			if win.GetMonitorInfo(win.MonitorFromWindow(fb.hWnd, win.MONITOR_DEFAULTTONEAREST), &mi) {
+				area = rectangleFromRECT(mi.RcWork)
+			}
+		}
+
+		bounds.X = area.X + (area.Width-size.Width)/2
+		bounds.Y = area.Y + (area.Height-size.Height)/2
+	}
+
+	fb.proposedSize = size
+	fb.SetBoundsPixels(fitRectToScreen(fb.hWnd, bounds))
+}
+
 func (fb *FormBase) HandleKeyDown(msg *win.MSG) bool {
 	ret := false
 
@@ -424,6 +591,24 @@ func (fb *FormBase) HandleKeyDown(msg *win.MSG) bool {
 		}
 	}
 
+	// In-cell editors of TableView
+	for hwnd := msg.HWnd; hwnd != 0; hwnd = win.GetParent(hwnd) {
+		if tv, ok := windowFromHandle(hwnd).(*TableView); ok {
+			if tv.handleEditorKeyDown(key, mods) {
+				return true
+			}
+
+			break
+		}
+	}
+
+	// Shortcut map
+	if fb.shortcuts != nil {
+		if fb.shortcuts.handleShortcut(msg.HWnd, Shortcut{mods, key}) {
+			return true
+		}
+	}
+
 	// Shortcut actions
 	hwnd := msg.HWnd
 	for hwnd != 0 {
@@ -463,6 +648,12 @@ func (fb *FormBase) Starting() *Event {
 	return fb.startingPublisher.Event()
 }
 
+// DPIChanged returns the event that is published after the *FormBase has been
+// rescaled to a new DPI. Handlers can use it to swap DPI-specific resources.
+func (fb *FormBase) DPIChanged() *GenericEvent[DPIChange] {
+	return fb.dpiChangedPublisher.Event()
+}
+
 func (fb *FormBase) Activating() *Event {
 	return fb.activatingPublisher.Event()
 }
@@ -513,6 +704,7 @@ func (fb *FormBase) Icon() Image {
 
 func (fb *FormBase) SetIcon(icon Image) error {
 	var hIconSmall, hIconBig uintptr
+	var pins iconCachePins
 
 	if icon != nil {
 		dpi := fb.DPI()
@@ -520,7 +712,7 @@ func (fb *FormBase) SetIcon(icon Image) error {
 
 		smallHeight := int(win.GetSystemMetricsForDpi(win.SM_CYSMICON, uint32(dpi)))
 		smallDPI := int(math.Round(float64(smallHeight) / float64(size96dpi.Height) * 96.0))
-		smallIcon, err := iconCache.Icon(icon, smallDPI)
+		smallIcon, err := iconCache.pinnedIcon(&pins, icon, smallDPI)
 		if err != nil {
 			return err
 		}
@@ -528,8 +720,9 @@ func (fb *FormBase) SetIcon(icon Image) error {
 
 		bigHeight := int(win.GetSystemMetricsForDpi(win.SM_CYICON, uint32(dpi)))
 		bigDPI := int(math.Round(float64(bigHeight) / float64(size96dpi.Height) * 96.0))
-		bigIcon, err := iconCache.Icon(icon, bigDPI)
+		bigIcon, err := iconCache.pinnedIcon(&pins, icon, bigDPI)
 		if err != nil {
+			iconCache.unpin(&pins)
 			return err
 		}
 		hIconBig = uintptr(bigIcon.handleForDPI(bigDPI))
@@ -538,6 +731,9 @@ func (fb *FormBase) SetIcon(icon Image) error {
 	fb.SendMessage(win.WM_SETICON, 0, hIconSmall)
 	fb.SendMessage(win.WM_SETICON, 1, hIconBig)
 
+	iconCache.unpin(&fb.imagePins)
+	fb.imagePins = pins
+
 	fb.icon = icon
 
 	fb.iconChangedPublisher.Publish()
@@ -556,6 +752,8 @@ func (fb *FormBase) Hide() {
 func (fb *FormBase) Show() {
 	fb.proposedSize = maxSize(SizeFrom96DPI(fb.minSize96dpi, fb.DPI()), fb.SizePixels())
 
+	fb.applyStartupPosition(fb.proposedSize)
+
 	if p, ok := fb.window.(Persistable); ok && p.Persistent() && App().Settings() != nil {
 		p.RestoreState()
 	}
@@ -670,6 +868,10 @@ func (fb *FormBase) startLayout() bool {
 		return false
 	}
 
+	if fb.sizeToContent && !fb.inSizingLoop {
+		fb.applySizeToContent()
+	}
+
 	cs := fb.clientSizeFromSizePixels(fb.proposedSize)
 	min := CreateLayoutItemsForContainer(fb.clientComposite).MinSizeForSize(fb.proposedSize)
 
@@ -687,26 +889,44 @@ func (fb *FormBase) startLayout() bool {
 	cli := CreateLayoutItemsForContainer(fb)
 	cli.Geometry().ClientSize = cs
 
+	layoutStats.passes.Add(1)
+
 	fb.performLayout <- cli
 
 	return true
 }
 
 func (fb *FormBase) WndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) uintptr {
+	if result, handled := fb.handleCustomCaptionMessage(msg, wParam, lParam); handled {
+		return result
+	}
+
 	switch msg {
+	case win.WM_TIMER:
+		if wParam == formFadeTimerId && fb.fade != nil {
+			fb.animateFade()
+			return 0
+		}
+
 	case win.WM_ACTIVATE:
 		switch win.LOWORD(uint32(wParam)) {
 		case win.WA_ACTIVE, win.WA_CLICKACTIVE:
-			if fb.prevFocusHWnd != 0 {
-				win.SetFocus(fb.prevFocusHWnd)
+			// Minimized forms must not take the focus.
+			if win.HIWORD(uint32(wParam)) == 0 {
+				fb.restoreFocus()
 			}
 
 			activeForm = fb
+			lastActiveForm = fb
+
+			if fb.requestingAttention {
+				fb.StopRequestingAttention()
+			}
 
 			fb.activatingPublisher.Publish()
 
 		case win.WA_INACTIVE:
-			fb.prevFocusHWnd = win.GetFocus()
+			fb.saveFocus()
 
 			activeForm = nil
 
@@ -716,27 +936,50 @@ func (fb *FormBase) WndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) u
 		return 0
 
 	case win.WM_CLOSE:
+		// The reason only applies to this close.
+		reason := fb.closeReason
 		fb.closeReason = CloseReasonUnknown
 		var canceled bool
-		fb.closingPublisher.Publish(&canceled, fb.closeReason)
-		if !canceled {
-			if p, ok := fb.window.(Persistable); ok && p.Persistent() && App().Settings() != nil {
-				p.SaveState()
-			}
-			fb.started = false
+		fb.closingPublisher.Publish(&canceled, reason)
+		if canceled {
+			// Window procedures wrapping this one, e.g. the one of MainWindow,
+			// must not go on closing.
+			return 1
+		}
+		if p, ok := fb.window.(Persistable); ok && p.Persistent() && App().Settings() != nil {
+			p.SaveState()
 		}
+		fb.started = false
 		return 0
 
 	case win.WM_COMMAND:
+		if win.HIWORD(uint32(wParam)) == _THBN_CLICKED && fb.taskbarButton != nil {
+			fb.taskbarButton.handleThumbnailButtonClicked(int(win.LOWORD(uint32(wParam))))
+			return 0
+		}
+
 		return fb.clientComposite.WndProc(hwnd, msg, wParam, lParam)
 
+	case win.WM_DRAWITEM:
+		if dis := (*win.DRAWITEMSTRUCT)(unsafe.Pointer(lParam)); dis.CtlType != win.ODT_MENU {
+			return fb.clientComposite.WndProc(hwnd, msg, wParam, lParam)
+		}
+
 	case win.WM_GETMINMAXINFO:
+		mmi := (*win.MINMAXINFO)(unsafe.Pointer(lParam))
+
+		maxTrackSize := SizeFrom96DPI(fb.maxSize96dpi, fb.DPI())
+		if maxTrackSize.Width > 0 {
+			mmi.PtMaxTrackSize.X = int32(maxTrackSize.Width)
+		}
+		if maxTrackSize.Height > 0 {
+			mmi.PtMaxTrackSize.Y = int32(maxTrackSize.Height)
+		}
+
 		if fb.Suspended() || fb.proposedSize == (Size{}) {
 			break
 		}
 
-		mmi := (*win.MINMAXINFO)(unsafe.Pointer(lParam))
-
 		var min Size
 		if layout := fb.clientComposite.layout; layout != nil {
 			size := fb.clientSizeFromSizePixels(fb.proposedSize)
@@ -770,9 +1013,18 @@ func (fb *FormBase) WndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) u
 		fb.inSizingLoop = false
 		fb.inSizeLoop <- false
 
+	case win.WM_SIZING:
+		fb.sizeToContent = false
+
 	case win.WM_WINDOWPOSCHANGED:
 		wp := (*win.WINDOWPOS)(unsafe.Pointer(lParam))
 
+		fb.updateWindowState(wp)
+
+		if wp.Flags&win.SWP_NOZORDER == 0 {
+			fb.updateAlwaysOnTop()
+		}
+
 		if wp.Flags&win.SWP_SHOWWINDOW != 0 {
 			fb.startLayout()
 		}
@@ -811,7 +1063,6 @@ func (fb *FormBase) WndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) u
 	case win.WM_DPICHANGED:
 		wasSuspended := fb.Suspended()
 		fb.SetSuspended(true)
-		defer fb.SetSuspended(wasSuspended)
 
 		dpi := int(win.HIWORD(uint32(wParam)))
 
@@ -827,16 +1078,42 @@ func (fb *FormBase) WndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) u
 		if fb.progressIndicator != nil {
 			fb.progressIndicator.SetOverlayIcon(fb.progressIndicator.overlayIcon, fb.progressIndicator.overlayIconDescription)
 		}
+		if fb.taskbarButton != nil {
+			fb.taskbarButton.updateThumbnailButtons()
+		}
 		applyDPIToDescendants(fb.window, dpi)
 
-		fb.SetSuspended(wasSuspended)
+		fb.SetIcon(fb.icon)
 
+		// Apply the suggested rectangle as is, while redrawing is still
+		// disabled, so the Form never shows up half scaled. The min size
+		// constraints of the layout are deliberately not applied here, as
+		// they would make the Form drift when moved back and forth between
+		// monitors.
 		rc := (*win.RECT)(unsafe.Pointer(lParam))
 		bounds := rectangleFromRECT(*rc)
 		fb.proposedSize = bounds.Size()
-		fb.window.SetBoundsPixels(bounds)
+		win.SetWindowPos(
+			fb.hWnd,
+			0,
+			int32(bounds.X),
+			int32(bounds.Y),
+			int32(bounds.Width),
+			int32(bounds.Height),
+			win.SWP_NOZORDER|win.SWP_NOACTIVATE|win.SWP_FRAMECHANGED)
 
-		fb.SetIcon(fb.icon)
+		fb.SetSuspended(wasSuspended)
+
+		if !wasSuspended {
+			win.RedrawWindow(fb.hWnd, nil, 0, win.RDW_ERASE|win.RDW_FRAME|win.RDW_INVALIDATE|win.RDW_ALLCHILDREN)
+		}
+
+		if oldDPI := fb.dpi; oldDPI != dpi {
+			fb.dpi = dpi
+			fb.dpiChangedPublisher.Publish(DPIChange{Old: oldDPI, New: dpi})
+		}
+
+		return 0
 
 	case win.WM_SYSCOMMAND:
 		if wParam == win.SC_CLOSE {
@@ -851,6 +1128,11 @@ func (fb *FormBase) WndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) u
 		if fb.progressIndicator == nil && (major > 6 || (major == 6 && minor > 0)) {
 			fb.progressIndicator, _ = newTaskbarList3(fb.hWnd)
 		}
+		if fb.taskbarButton != nil {
+			// The button is created again whenever Explorer restarts, so the
+			// state has to be reapplied every time.
+			fb.taskbarButton.apply()
+		}
 	}
 
 	return fb.WindowBase.WndProc(hwnd, msg, wParam, lParam)
@@ -867,6 +1149,23 @@ func (fb *FormBase) OnPreTranslate(msg *win.MSG) bool {
 	return DefaultModalPreTranslate(fb, msg)
 }
 
+// scheduleLayout schedules a layout of the FormBase to be started once the
+// current message has been handled, coalescing all requests made until then.
+func (fb *FormBase) scheduleLayout() {
+	layoutStats.requests.Add(1)
+
+	if fb.layoutScheduled {
+		layoutStats.coalesced.Add(1)
+		return
+	}
+
+	fb.layoutScheduled = true
+
+	// The active form usually starts the layout in OnPostDispatch. This covers
+	// all other forms and messages not dispatched to the form itself.
+	App().Synchronize(fb.OnPostDispatch)
+}
+
 func (fb *FormBase) OnPostDispatch() {
 	if !fb.layoutScheduled {
 		return
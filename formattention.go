@@ -0,0 +1,54 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"github.com/tailscale/win"
+)
+
+// RequestAttention flashes the *FormBase to signal the user that something
+// happened that needs attention, e.g. that a long running operation finished
+// while the user was working in another application.
+//
+// The taskbar button, and unless flashTaskbarOnly is true also the title bar,
+// flash count times and then stay highlighted. If count is 0 or less, they
+// flash until the *FormBase is activated. Either way, flashing stops when it
+// is activated. Nothing happens if it is the foreground window already.
+func (fb *FormBase) RequestAttention(count int, flashTaskbarOnly bool) {
+	if win.GetForegroundWindow() == fb.hWnd {
+		return
+	}
+
+	fwi := flashWInfo{
+		hwnd:    fb.hWnd,
+		dwFlags: _FLASHW_ALL | _FLASHW_TIMERNOFG,
+	}
+	if flashTaskbarOnly {
+		fwi.dwFlags = _FLASHW_TRAY | _FLASHW_TIMERNOFG
+	}
+	if count > 0 {
+		fwi.uCount = uint32(count)
+	}
+
+	flashWindowEx(&fwi)
+
+	fb.requestingAttention = true
+}
+
+// StopRequestingAttention stops the flashing started by RequestAttention and
+// restores the normal look of the *FormBase.
+func (fb *FormBase) StopRequestingAttention() {
+	fb.requestingAttention = false
+
+	fwi := flashWInfo{
+		hwnd:    fb.hWnd,
+		dwFlags: _FLASHW_STOP,
+	}
+
+	flashWindowEx(&fwi)
+}
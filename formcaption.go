@@ -0,0 +1,389 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"unsafe"
+
+	"github.com/tailscale/win"
+)
+
+// CustomCaption returns whether the *FormBase draws its own caption instead
+// of the standard title bar.
+func (fb *FormBase) CustomCaption() bool {
+	return fb.customCaption
+}
+
+// SetCustomCaption sets whether the *FormBase draws its own caption instead
+// of the standard title bar.
+//
+// A custom caption removes the title bar but keeps the resizable frame and
+// its shadow, extending the frame into the client area, so the client area
+// reaches up to the top edge of the window. The top edge still resizes the
+// window. Use SetCaptionWidgets to declare which widgets can be used to drag
+// the window, which includes snapping, shaking and maximizing on double-click,
+// and SetCustomMaximizeButton for Snap Layouts.
+func (fb *FormBase) SetCustomCaption(value bool) error {
+	if value == fb.customCaption {
+		return nil
+	}
+
+	fb.customCaption = value
+
+	return fb.updateFrame()
+}
+
+// Borderless returns whether the *FormBase has no visible frame.
+func (fb *FormBase) Borderless() bool {
+	return fb.borderless
+}
+
+// SetBorderless sets whether the *FormBase has no visible frame, so that the
+// client area covers the whole window, e.g. for a completely custom skin.
+//
+// Unlike removing the frame styles, this keeps the behavior of a standard
+// window: it can still be resized at its edges, it casts the DWM shadow, it
+// is animated when minimized or maximized and, when maximized, it covers
+// the work area of its monitor, not the taskbar. Use SetCaptionWidgets to
+// make it movable.
+func (fb *FormBase) SetBorderless(value bool) error {
+	if value == fb.borderless {
+		return nil
+	}
+
+	fb.borderless = value
+
+	if value {
+		// The frame styles are what makes the system treat the window like a
+		// standard one.
+		fb.ensureStyleBits(win.WS_CAPTION|win.WS_THICKFRAME, true)
+	}
+
+	return fb.updateFrame()
+}
+
+// updateFrame applies the custom caption and borderless settings to the
+// frame of the *FormBase.
+func (fb *FormBase) updateFrame() error {
+	var margins win.MARGINS
+	switch {
+	case fb.borderless:
+		// Extending the frame at all makes DWM draw the shadow.
+		margins = win.MARGINS{LeftWidth: 1, RightWidth: 1, TopHeight: 1, BottomHeight: 1}
+
+	case fb.customCaption:
+		// Keeps DWM drawing the top border of the frame.
+		margins.TopHeight = 1
+	}
+	if hr := dwmExtendFrameIntoClientArea(fb.hWnd, &margins); win.FAILED(hr) {
+		return errorFromHRESULT("DwmExtendFrameIntoClientArea", hr)
+	}
+
+	// Makes the system recalculate the non-client area.
+	if !win.SetWindowPos(fb.hWnd, 0, 0, 0, 0, 0, win.SWP_NOMOVE|win.SWP_NOSIZE|win.SWP_NOZORDER|win.SWP_NOACTIVATE|win.SWP_FRAMECHANGED) {
+		return lastError("SetWindowPos")
+	}
+
+	return nil
+}
+
+// CaptionWidgets returns the widgets that act as caption of the *FormBase.
+func (fb *FormBase) CaptionWidgets() []Widget {
+	return append([]Widget(nil), fb.captionWidgets...)
+}
+
+// SetCaptionWidgets makes widgets, which must be descendants of the *FormBase,
+// act as its caption, so they can be used to move it like the standard title
+// bar. This is typically the composite forming a custom title bar and the
+// label showing the title.
+//
+// Only the area of the widgets themselves acts as caption, their children,
+// like buttons, keep receiving mouse input.
+func (fb *FormBase) SetCaptionWidgets(widgets ...Widget) error {
+	for _, widget := range widgets {
+		if win.GetAncestor(widget.Handle(), win.GA_ROOT) != fb.hWnd {
+			return newError("widget must be a descendant of the form")
+		}
+	}
+
+	fb.captionWidgets = append([]Widget(nil), widgets...)
+
+	return nil
+}
+
+// isCaptionWidget returns whether window is one of the caption widgets of
+// the *FormBase.
+func (fb *FormBase) isCaptionWidget(window Window) bool {
+	for _, widget := range fb.captionWidgets {
+		if Window(widget) == window {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hitsCaptionWidget returns whether pt, in screen coordinates, is in one of
+// the caption widgets of the *FormBase.
+func (fb *FormBase) hitsCaptionWidget(pt Point) bool {
+	for _, widget := range fb.captionWidgets {
+		if widget.IsDisposed() || !widget.Visible() {
+			continue
+		}
+
+		var rc win.RECT
+		if win.GetWindowRect(widget.Handle(), &rc) && rectangleFromRECT(rc).contains(pt) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// frameThickness returns the thickness of the resizable frame of the
+// *FormBase in native pixels.
+func (fb *FormBase) frameThickness() int {
+	dpi := uint32(fb.DPI())
+
+	return int(win.GetSystemMetricsForDpi(win.SM_CYFRAME, dpi) + win.GetSystemMetricsForDpi(_SM_CXPADDEDBORDER, dpi))
+}
+
+// borderHitTest returns the hit test code for the resizable frame of a
+// borderless *FormBase at pt, in screen coordinates.
+func (fb *FormBase) borderHitTest(pt Point) uintptr {
+	bounds := fb.BoundsPixels()
+	border := fb.frameThickness()
+
+	left := pt.X < bounds.X+border
+	right := pt.X >= bounds.X+bounds.Width-border
+	top := pt.Y < bounds.Y+border
+	bottom := pt.Y >= bounds.Y+bounds.Height-border
+
+	switch {
+	case top && left:
+		return win.HTTOPLEFT
+	case top && right:
+		return win.HTTOPRIGHT
+	case bottom && left:
+		return win.HTBOTTOMLEFT
+	case bottom && right:
+		return win.HTBOTTOMRIGHT
+	case left:
+		return win.HTLEFT
+	case right:
+		return win.HTRIGHT
+	case top:
+		return win.HTTOP
+	case bottom:
+		return win.HTBOTTOM
+	}
+
+	return win.HTCLIENT
+}
+
+// CustomMaximizeButton returns the widget acting as maximize button of a
+// custom caption of the *FormBase, or nil.
+func (fb *FormBase) CustomMaximizeButton() Widget {
+	return fb.customMaximizeButton
+}
+
+// SetCustomMaximizeButton makes widget, which must be a descendant of the
+// *FormBase, act as the maximize button of a custom caption.
+//
+// The area of widget is reported to the system as maximize button, so that
+// hovering it shows the Windows 11 Snap Layouts flyout. Clicking it maximizes
+// or restores the *FormBase. Since mouse input over that area goes to the
+// *FormBase, widget does not receive mouse events itself. Buttons are shown
+// pressed while the mouse button is down.
+//
+// Pass nil to remove the custom maximize button.
+func (fb *FormBase) SetCustomMaximizeButton(widget Widget) error {
+	if widget != nil && win.GetAncestor(widget.Handle(), win.GA_ROOT) != fb.hWnd {
+		return newError("widget must be a descendant of the form")
+	}
+
+	fb.setCustomMaximizeButtonPressed(false)
+	fb.customMaximizeButton = widget
+
+	return nil
+}
+
+// hitsCustomMaximizeButton returns whether pt, in screen coordinates, is in
+// the custom maximize button of the *FormBase.
+func (fb *FormBase) hitsCustomMaximizeButton(pt Point) bool {
+	mb := fb.customMaximizeButton
+	if mb == nil || mb.IsDisposed() || !mb.Visible() {
+		return false
+	}
+
+	var rc win.RECT
+	if !win.GetWindowRect(mb.Handle(), &rc) {
+		return false
+	}
+
+	return rectangleFromRECT(rc).contains(pt)
+}
+
+func (fb *FormBase) setCustomMaximizeButtonPressed(pressed bool) {
+	if pressed == fb.customMaximizeButtonPressed {
+		return
+	}
+
+	fb.customMaximizeButtonPressed = pressed
+
+	if mb := fb.customMaximizeButton; mb != nil && !mb.IsDisposed() {
+		if _, ok := mb.(*PushButton); ok {
+			mb.SendMessage(win.BM_SETSTATE, uintptr(win.BoolToBOOL(pressed)), 0)
+		}
+	}
+}
+
+// handleCustomCaptionMessage handles the non-client messages needed for a
+// custom caption. It returns whether msg has been handled.
+func (fb *FormBase) handleCustomCaptionMessage(msg uint32, wParam, lParam uintptr) (result uintptr, handled bool) {
+	if !fb.borderless && !fb.customCaption && fb.customMaximizeButton == nil && len(fb.captionWidgets) == 0 && !fb.clientDrag {
+		return 0, false
+	}
+
+	switch msg {
+	case win.WM_NCCALCSIZE:
+		if wParam == 0 {
+			break
+		}
+
+		if fb.borderless {
+			params := (*ncCalcSizeParams)(unsafe.Pointer(lParam))
+
+			// The client area is the whole window, except when maximized,
+			// as the window then extends beyond the work area of its monitor.
+			if win.IsZoomed(fb.hWnd) {
+				var mi win.MONITORINFO
+				mi.CbSize = uint32(unsafe.Sizeof(mi))
+
+				if win.GetMonitorInfo(win.MonitorFromWindow(fb.hWnd, win.MONITOR_DEFAULTTONEAREST), &mi) {
+					params.rgrc[0] = mi.RcWork
+				}
+			}
+
+			return 0, true
+		}
+
+		if !fb.customCaption {
+			break
+		}
+
+		params := (*ncCalcSizeParams)(unsafe.Pointer(lParam))
+		top := params.rgrc[0].Top
+
+		win.DefWindowProc(fb.hWnd, msg, wParam, lParam)
+
+		// Only remove the title bar, keeping the other borders. A maximized
+		// window extends beyond its monitor by the frame thickness.
+		params.rgrc[0].Top = top
+		if win.IsZoomed(fb.hWnd) {
+			params.rgrc[0].Top += int32(fb.frameThickness())
+		}
+
+		return 0, true
+
+	case win.WM_NCHITTEST:
+		pt := Point{int(win.GET_X_LPARAM(lParam)), int(win.GET_Y_LPARAM(lParam))}
+
+		if fb.hitsCustomMaximizeButton(pt) {
+			return win.HTMAXBUTTON, true
+		}
+
+		hit := win.DefWindowProc(fb.hWnd, msg, wParam, lParam)
+
+		// Neither maximized nor fullscreen windows can be resized.
+		resizable := !win.IsZoomed(fb.hWnd) && fb.fullscreen == nil
+
+		if hit == win.HTCLIENT && fb.borderless && resizable {
+			hit = fb.borderHitTest(pt)
+		} else if hit == win.HTCLIENT && fb.customCaption && resizable {
+			bounds := fb.BoundsPixels()
+			if border := fb.frameThickness(); pt.Y < bounds.Y+border {
+				switch {
+				case pt.X < bounds.X+border:
+					hit = win.HTTOPLEFT
+				case pt.X >= bounds.X+bounds.Width-border:
+					hit = win.HTTOPRIGHT
+				default:
+					hit = win.HTTOP
+				}
+			}
+		}
+
+		if hit == win.HTCLIENT && (fb.hitsCaptionWidget(pt) || fb.hitsClientDragSurface(pt)) {
+			hit = win.HTCAPTION
+		}
+
+		return hit, true
+
+	case win.WM_NCMOUSEMOVE:
+		if wParam != win.HTMAXBUTTON {
+			fb.setCustomMaximizeButtonPressed(false)
+			break
+		}
+
+		var tme win.TRACKMOUSEEVENT
+		tme.CbSize = uint32(unsafe.Sizeof(tme))
+		tme.DwFlags = win.TME_LEAVE | win.TME_NONCLIENT
+		tme.HwndTrack = fb.hWnd
+
+		win.TrackMouseEvent(&tme)
+
+		return 0, true
+
+	case win.WM_NCMOUSELEAVE:
+		fb.setCustomMaximizeButtonPressed(false)
+
+	case win.WM_NCLBUTTONDOWN, win.WM_NCLBUTTONDBLCLK:
+		if wParam == win.HTMAXBUTTON {
+			// DefWindowProc would track and draw the standard button.
+			fb.setCustomMaximizeButtonPressed(true)
+			return 0, true
+		}
+
+	case win.WM_NCLBUTTONUP:
+		if wParam == win.HTMAXBUTTON {
+			if fb.customMaximizeButtonPressed {
+				fb.setCustomMaximizeButtonPressed(false)
+
+				if win.IsZoomed(fb.hWnd) {
+					win.ShowWindow(fb.hWnd, win.SW_RESTORE)
+				} else {
+					win.ShowWindow(fb.hWnd, win.SW_MAXIMIZE)
+				}
+			}
+			return 0, true
+		}
+	}
+
+	return 0, false
+}
+
+// passesHitTestToForm returns whether the child window wb must let the
+// WM_NCHITTEST for pt, in screen coordinates, through to its form, because pt
+// is in the custom maximize button of the form or wb is a caption widget or
+// a client drag surface.
+func (wb *WindowBase) passesHitTestToForm(pt Point) bool {
+	root := win.GetAncestor(wb.hWnd, win.GA_ROOT)
+	if root == wb.hWnd {
+		return false
+	}
+
+	form, ok := windowFromHandle(root).(Form)
+	if !ok {
+		return false
+	}
+
+	fb := form.AsFormBase()
+
+	return fb.hitsCustomMaximizeButton(pt) || fb.isCaptionWidget(wb.window) || fb.isClientDragSurface(wb.window)
+}
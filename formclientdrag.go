@@ -0,0 +1,89 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"github.com/tailscale/win"
+)
+
+// EnableClientDrag makes dragging empty parts of the client area of the
+// *FormBase move it, like its title bar, e.g. the background of a custom
+// toolbar.
+//
+// predicate is called with the *FormBase or the widget under the mouse
+// pointer and returns whether it is empty space. If predicate is nil, the
+// *FormBase itself, composites, labels and image views are, while interactive
+// widgets like buttons and edits are not.
+func (fb *FormBase) EnableClientDrag(predicate func(window Window) bool) {
+	fb.clientDrag = true
+	fb.clientDragPredicate = predicate
+}
+
+// DisableClientDrag reverts EnableClientDrag.
+func (fb *FormBase) DisableClientDrag() {
+	fb.clientDrag = false
+	fb.clientDragPredicate = nil
+}
+
+// ClientDragEnabled returns whether dragging empty parts of the client area
+// of the *FormBase moves it.
+func (fb *FormBase) ClientDragEnabled() bool {
+	return fb.clientDrag
+}
+
+// isClientDragSurface returns whether window is empty space that moves the
+// *FormBase if dragged.
+func (fb *FormBase) isClientDragSurface(window Window) bool {
+	if !fb.clientDrag || window == nil {
+		return false
+	}
+
+	if fb.clientDragPredicate != nil {
+		return fb.clientDragPredicate(window)
+	}
+
+	switch window.(type) {
+	case *Composite, *Label, *ImageView:
+		return true
+	}
+
+	return window.AsWindowBase() == &fb.WindowBase
+}
+
+// hitsClientDragSurface returns whether pt, in screen coordinates, is on a
+// client drag surface of the *FormBase.
+func (fb *FormBase) hitsClientDragSurface(pt Point) bool {
+	if !fb.clientDrag {
+		return false
+	}
+
+	// Find the topmost visible descendant at pt. Native children of widgets,
+	// like the edit of a ComboBox, are attributed to the widget.
+	hwnd := fb.hWnd
+	for {
+		child := win.GetWindow(hwnd, win.GW_CHILD)
+		for ; child != 0; child = win.GetWindow(child, win.GW_HWNDNEXT) {
+			var rc win.RECT
+			if win.IsWindowVisible(child) && win.GetWindowRect(child, &rc) && rectangleFromRECT(rc).contains(pt) {
+				break
+			}
+		}
+		if child == 0 {
+			break
+		}
+		hwnd = child
+	}
+
+	for ; hwnd != 0; hwnd = win.GetParent(hwnd) {
+		if window := windowFromHandle(hwnd); window != nil {
+			return fb.isClientDragSurface(window)
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,88 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"unsafe"
+
+	"github.com/tailscale/win"
+	"golang.org/x/sys/windows"
+)
+
+// CornerPreference specifies how Windows 11 rounds the corners of a Form.
+type CornerPreference int
+
+const (
+	CornerPreferenceDefault    CornerPreference = _DWMWCP_DEFAULT    // let the system decide
+	CornerPreferenceSquare     CornerPreference = _DWMWCP_DONOTROUND // never round
+	CornerPreferenceRound      CornerPreference = _DWMWCP_ROUND      // round like top-level windows
+	CornerPreferenceSmallRound CornerPreference = _DWMWCP_ROUNDSMALL // round with a small radius, like menus and tooltips
+)
+
+// CornerPreference returns how the corners of the *FormBase are rounded.
+func (fb *FormBase) CornerPreference() CornerPreference {
+	return fb.cornerPreference
+}
+
+// SetCornerPreference sets how the corners of the *FormBase are rounded. Popup
+// style tool windows typically use CornerPreferenceSmallRound. It has no
+// effect before Windows 11.
+func (fb *FormBase) SetCornerPreference(preference CornerPreference) error {
+	if preference < CornerPreferenceDefault || preference > CornerPreferenceSmallRound {
+		return newError("invalid corner preference")
+	}
+
+	fb.cornerPreference = preference
+
+	if !isWindows11OrLater() {
+		return nil
+	}
+
+	value := uint32(preference)
+	if err := windows.DwmSetWindowAttribute(windows.HWND(fb.hWnd), windows.DWMWA_WINDOW_CORNER_PREFERENCE, unsafe.Pointer(&value), uint32(unsafe.Sizeof(value))); err != nil {
+		return wrapError(err)
+	}
+
+	return nil
+}
+
+// Cloaked returns whether the *FormBase is cloaked, either using SetCloaked
+// or by the system, e.g. because it is on another virtual desktop.
+func (fb *FormBase) Cloaked() bool {
+	var cloaked uint32
+	if err := windows.DwmGetWindowAttribute(windows.HWND(fb.hWnd), windows.DWMWA_CLOAKED, unsafe.Pointer(&cloaked), uint32(unsafe.Sizeof(cloaked))); err != nil {
+		return false
+	}
+
+	return cloaked != 0
+}
+
+// SetCloaked sets whether the *FormBase is cloaked. A cloaked window is not
+// drawn, but otherwise behaves as if it was visible: it keeps its place in the
+// z-order, and it is laid out and painted as usual.
+//
+// Unlike hiding or minimizing, cloaking and uncloaking is not animated. This
+// can be used to hide popups instantly, or to show a Form cloaked, position
+// it and let it finish its layout, and then reveal it without flickering.
+func (fb *FormBase) SetCloaked(cloaked bool) error {
+	var value win.BOOL
+	if cloaked {
+		value = 1
+	}
+
+	if err := windows.DwmSetWindowAttribute(windows.HWND(fb.hWnd), windows.DWMWA_CLOAK, unsafe.Pointer(&value), uint32(unsafe.Sizeof(value))); err != nil {
+		return wrapError(err)
+	}
+
+	return nil
+}
+
+// isWindows11OrLater returns whether the system runs Windows 11 or later.
+func isWindows11OrLater() bool {
+	return windows.RtlGetVersion().BuildNumber >= 22000
+}
@@ -0,0 +1,77 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"github.com/tailscale/win"
+)
+
+// InitialFocus returns the widget that receives the keyboard focus when the
+// *FormBase is shown, or nil for the first focusable one.
+func (fb *FormBase) InitialFocus() Widget {
+	return fb.initialFocus
+}
+
+// SetInitialFocus sets the widget, which must be a descendant of the
+// *FormBase, that receives the keyboard focus when the *FormBase is shown.
+// If it is nil or cannot be focused, the first focusable widget does.
+//
+// Later on, the *FormBase remembers which widget had the focus when it was
+// deactivated, e.g. because it showed a modal dialog, and focuses it again
+// when it is activated.
+func (fb *FormBase) SetInitialFocus(widget Widget) error {
+	if widget != nil && !win.IsChild(fb.hWnd, widget.Handle()) {
+		return newError("widget must be a descendant of the form")
+	}
+
+	fb.initialFocus = widget
+
+	return nil
+}
+
+// saveFocus remembers the focused descendant of the *FormBase, if any.
+func (fb *FormBase) saveFocus() {
+	if hwnd := win.GetFocus(); hwnd != 0 && win.IsChild(fb.hWnd, hwnd) {
+		fb.prevFocusHWnd = hwnd
+	}
+}
+
+// restoreFocus focuses the descendant that was focused when the *FormBase
+// was deactivated, or else the initial focus widget, and returns whether it
+// focused one.
+func (fb *FormBase) restoreFocus() bool {
+	if hwnd := fb.prevFocusHWnd; hwnd != 0 {
+		if fb.canFocus(hwnd) {
+			win.SetFocus(hwnd)
+			return true
+		}
+
+		// The widget is gone, hidden or disabled meanwhile.
+		fb.prevFocusHWnd = 0
+	}
+
+	if widget := fb.initialFocus; widget != nil && !widget.IsDisposed() && fb.canFocus(widget.Handle()) {
+		return widget.SetFocus() == nil
+	}
+
+	return false
+}
+
+// focusInitially moves the focus to a descendant of the *FormBase after it
+// was laid out while the focus was not on any.
+func (fb *FormBase) focusInitially() {
+	if !fb.restoreFocus() {
+		fb.clientComposite.focusFirstCandidateDescendant()
+	}
+}
+
+// canFocus returns whether hwnd is a descendant of the *FormBase that can be
+// focused.
+func (fb *FormBase) canFocus(hwnd win.HWND) bool {
+	return win.IsChild(fb.hWnd, hwnd) && win.IsWindowVisible(hwnd) && win.IsWindowEnabled(hwnd)
+}
@@ -0,0 +1,172 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"unsafe"
+
+	"github.com/tailscale/win"
+)
+
+// FullscreenOptions configures how a Form is shown in fullscreen mode.
+type FullscreenOptions struct {
+	// Monitor selects the monitor to cover by a point on it, in screen
+	// coordinates and native pixels. If nil, the monitor the Form is on is
+	// covered.
+	Monitor *Point
+
+	// HideCursor hides the mouse cursor while the Form is fullscreen.
+	HideCursor bool
+}
+
+// fullscreenState holds what is needed to leave fullscreen mode.
+type fullscreenState struct {
+	style        uint32
+	exStyle      uint32
+	placement    win.WINDOWPLACEMENT
+	cursorHidden bool
+}
+
+// Fullscreen returns whether the *FormBase covers a whole monitor without
+// frame.
+func (fb *FormBase) Fullscreen() bool {
+	return fb.fullscreen != nil
+}
+
+// SetFullscreen sets whether the *FormBase covers a whole monitor without
+// frame, e.g. for kiosk, presentation or media applications.
+//
+// Entering fullscreen mode saves the window styles and placement of the
+// *FormBase, which are restored when leaving it.
+func (fb *FormBase) SetFullscreen(value bool) error {
+	if value == fb.Fullscreen() {
+		return nil
+	}
+
+	var err error
+	if value {
+		err = fb.enterFullscreen()
+	} else {
+		err = fb.leaveFullscreen()
+	}
+	if err != nil {
+		return err
+	}
+
+	fb.fullscreenChangedPublisher.Publish()
+
+	return nil
+}
+
+// FullscreenOptions returns the options used when the *FormBase enters
+// fullscreen mode.
+func (fb *FormBase) FullscreenOptions() FullscreenOptions {
+	return fb.fullscreenOptions
+}
+
+// SetFullscreenOptions sets the options used when the *FormBase enters
+// fullscreen mode. If it already is fullscreen, they are applied
+// immediately.
+func (fb *FormBase) SetFullscreenOptions(opts FullscreenOptions) error {
+	fb.fullscreenOptions = opts
+
+	if !fb.Fullscreen() {
+		return nil
+	}
+
+	if err := fb.leaveFullscreen(); err != nil {
+		return err
+	}
+
+	return fb.enterFullscreen()
+}
+
+// FullscreenChanged returns the event that is published when the *FormBase
+// entered or left fullscreen mode.
+func (fb *FormBase) FullscreenChanged() *Event {
+	return fb.fullscreenChangedPublisher.Event()
+}
+
+func (fb *FormBase) enterFullscreen() error {
+	state := &fullscreenState{
+		style:   uint32(win.GetWindowLong(fb.hWnd, win.GWL_STYLE)),
+		exStyle: uint32(win.GetWindowLong(fb.hWnd, win.GWL_EXSTYLE)),
+	}
+	state.placement.Length = uint32(unsafe.Sizeof(state.placement))
+
+	if !win.GetWindowPlacement(fb.hWnd, &state.placement) {
+		return lastError("GetWindowPlacement")
+	}
+
+	var monitor win.HMONITOR
+	if pt := fb.fullscreenOptions.Monitor; pt != nil {
+		rc := Rectangle{pt.X, pt.Y, 1, 1}.toRECT()
+		monitor = monitorFromRect(&rc, win.MONITOR_DEFAULTTONEAREST)
+	} else {
+		monitor = win.MonitorFromWindow(fb.hWnd, win.MONITOR_DEFAULTTONEAREST)
+	}
+
+	var mi win.MONITORINFO
+	mi.CbSize = uint32(unsafe.Sizeof(mi))
+
+	if !win.GetMonitorInfo(monitor, &mi) {
+		return newError("GetMonitorInfo failed")
+	}
+
+	// A maximized window would keep its maximized bounds.
+	if win.IsZoomed(fb.hWnd) {
+		win.ShowWindow(fb.hWnd, win.SW_RESTORE)
+	}
+
+	win.SetWindowLong(fb.hWnd, win.GWL_STYLE, int32(state.style&^(win.WS_CAPTION|win.WS_THICKFRAME)))
+	win.SetWindowLong(fb.hWnd, win.GWL_EXSTYLE, int32(state.exStyle&^(win.WS_EX_DLGMODALFRAME|win.WS_EX_WINDOWEDGE|win.WS_EX_CLIENTEDGE|win.WS_EX_STATICEDGE)))
+
+	bounds := rectangleFromRECT(mi.RcMonitor)
+	if !win.SetWindowPos(
+		fb.hWnd,
+		win.HWND_TOP,
+		int32(bounds.X),
+		int32(bounds.Y),
+		int32(bounds.Width),
+		int32(bounds.Height),
+		win.SWP_NOOWNERZORDER|win.SWP_FRAMECHANGED) {
+
+		return lastError("SetWindowPos")
+	}
+
+	if fb.fullscreenOptions.HideCursor {
+		showCursor(false)
+		state.cursorHidden = true
+	}
+
+	fb.fullscreen = state
+
+	return nil
+}
+
+func (fb *FormBase) leaveFullscreen() error {
+	state := fb.fullscreen
+	fb.fullscreen = nil
+
+	if state.cursorHidden {
+		showCursor(true)
+	}
+
+	win.SetWindowLong(fb.hWnd, win.GWL_STYLE, int32(state.style))
+	win.SetWindowLong(fb.hWnd, win.GWL_EXSTYLE, int32(state.exStyle))
+
+	if !win.SetWindowPlacement(fb.hWnd, &state.placement) {
+		return lastError("SetWindowPlacement")
+	}
+
+	if !win.SetWindowPos(fb.hWnd, 0, 0, 0, 0, 0, win.SWP_NOMOVE|win.SWP_NOSIZE|win.SWP_NOZORDER|win.SWP_NOOWNERZORDER|win.SWP_FRAMECHANGED) {
+		return lastError("SetWindowPos")
+	}
+
+	return nil
+}
@@ -0,0 +1,197 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"time"
+	"unsafe"
+
+	"github.com/tailscale/win"
+)
+
+const (
+	formFadeTimerId         = 0x46414445 // "FADE"
+	formFadeTimerElapse     = 16         // in milliseconds
+	formFadeDefaultDuration = 200 * time.Millisecond
+)
+
+// formFade is the state of a running FadeIn or FadeOut.
+type formFade struct {
+	from     float64
+	to       float64
+	start    time.Time
+	duration time.Duration
+	hide     bool    // whether to hide the form at the end, for FadeOut
+	restore  float64 // the opacity to restore after hiding
+	done     func()
+}
+
+// Opacity returns the opacity of the *FormBase, from 0 for fully transparent
+// to 1 for fully opaque.
+func (fb *FormBase) Opacity() float64 {
+	if !fb.layered {
+		return 1
+	}
+
+	return fb.opacity
+}
+
+// SetOpacity sets the opacity of the *FormBase, from 0 for fully transparent
+// to 1 for fully opaque. Values outside of that range are clamped.
+//
+// A translucent form is a layered window, which it stays once SetOpacity was
+// called, even if set back to fully opaque.
+func (fb *FormBase) SetOpacity(opacity float64) error {
+	if opacity < 0 {
+		opacity = 0
+	} else if opacity > 1 {
+		opacity = 1
+	}
+
+	if !fb.layered {
+		if opacity == 1 {
+			return nil
+		}
+
+		if err := fb.ensureExtendedStyleBits(win.WS_EX_LAYERED, true); err != nil {
+			return err
+		}
+
+		fb.layered = true
+	}
+
+	fb.opacity = opacity
+
+	if !setLayeredWindowAttributes(fb.hWnd, 0, byte(opacity*255+0.5), _LWA_ALPHA) {
+		return lastError("SetLayeredWindowAttributes")
+	}
+
+	return nil
+}
+
+// FadeIn shows the *FormBase, fading it in from transparent to its current
+// opacity within duration, or 200 milliseconds if zero. done is called once
+// it is fully shown and may be nil.
+//
+// If the user turned off animations in the Windows settings, the *FormBase is
+// shown right away.
+func (fb *FormBase) FadeIn(duration time.Duration, done func()) {
+	to := fb.Opacity()
+	if fb.fade != nil {
+		to = fb.fade.restore
+		fb.stopFade()
+	}
+
+	if !clientAreaAnimationsEnabled() {
+		fb.window.(Form).Show()
+		if done != nil {
+			done()
+		}
+		return
+	}
+
+	if !fb.Visible() {
+		fb.SetOpacity(0)
+		fb.window.(Form).Show()
+	}
+
+	fb.startFade(&formFade{
+		from:     fb.Opacity(),
+		to:       to,
+		duration: duration,
+		restore:  to,
+		done:     done,
+	})
+}
+
+// FadeOut fades the *FormBase out from its current opacity to transparent
+// within duration, or 200 milliseconds if zero, and then hides it. Its opacity
+// is restored afterwards, so it is opaque again when shown the next time. done
+// is called once it is hidden and may be nil.
+//
+// If the user turned off animations in the Windows settings, the *FormBase is
+// hidden right away.
+func (fb *FormBase) FadeOut(duration time.Duration, done func()) {
+	restore := fb.Opacity()
+	if fb.fade != nil {
+		restore = fb.fade.restore
+		fb.stopFade()
+	}
+
+	if !clientAreaAnimationsEnabled() || !fb.Visible() {
+		fb.Hide()
+		if done != nil {
+			done()
+		}
+		return
+	}
+
+	fb.startFade(&formFade{
+		from:     fb.Opacity(),
+		to:       0,
+		duration: duration,
+		hide:     true,
+		restore:  restore,
+		done:     done,
+	})
+}
+
+func (fb *FormBase) startFade(fade *formFade) {
+	if fade.duration <= 0 {
+		fade.duration = formFadeDefaultDuration
+	}
+	fade.start = time.Now()
+
+	fb.fade = fade
+
+	if win.SetTimer(fb.hWnd, formFadeTimerId, formFadeTimerElapse, 0) == 0 {
+		fb.finishFade()
+	}
+}
+
+func (fb *FormBase) stopFade() {
+	win.KillTimer(fb.hWnd, formFadeTimerId)
+	fb.fade = nil
+}
+
+func (fb *FormBase) animateFade() {
+	fade := fb.fade
+
+	progress := float64(time.Since(fade.start)) / float64(fade.duration)
+	if progress >= 1 {
+		fb.finishFade()
+		return
+	}
+
+	fb.SetOpacity(fade.from + (fade.to-fade.from)*progress)
+}
+
+func (fb *FormBase) finishFade() {
+	fade := fb.fade
+	fb.stopFade()
+
+	if fade.hide {
+		fb.Hide()
+	}
+	fb.SetOpacity(fade.restore)
+
+	if fade.done != nil {
+		fade.done()
+	}
+}
+
+// clientAreaAnimationsEnabled returns whether the user allows animations in
+// the Windows settings.
+func clientAreaAnimationsEnabled() bool {
+	var enabled win.BOOL
+	if !win.SystemParametersInfo(_SPI_GETCLIENTAREAANIMATION, 0, unsafe.Pointer(&enabled), 0) {
+		return true
+	}
+
+	return enabled != 0
+}
@@ -0,0 +1,176 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"fmt"
+	"unsafe"
+
+	"github.com/tailscale/win"
+	"golang.org/x/sys/windows"
+)
+
+// placementKey returns the settings key the window placement of the
+// *FormBase is stored under.
+func (fb *FormBase) placementKey() string {
+	return fb.path() + ":placement"
+}
+
+// SaveWindowPlacement writes the normal bounds of the *FormBase, whether it is
+// maximized and the monitor it is on to App().Settings().
+func (fb *FormBase) SaveWindowPlacement() error {
+	return fb.saveWindowPlacement(fb.placementKey())
+}
+
+// saveWindowPlacement implements SaveWindowPlacement, storing the placement
+// under key.
+func (fb *FormBase) saveWindowPlacement(key string) error {
+	settings := App().Settings()
+	if settings == nil {
+		return newError("App().Settings() must not be nil")
+	}
+
+	var wp win.WINDOWPLACEMENT
+	wp.Length = uint32(unsafe.Sizeof(wp))
+
+	if !win.GetWindowPlacement(fb.hWnd, &wp) {
+		return lastError("GetWindowPlacement")
+	}
+
+	bounds := rectangleFromRECT(wp.RcNormalPosition)
+	offset := fb.workspaceOffset()
+	bounds.X += offset.X
+	bounds.Y += offset.Y
+
+	maximized := wp.ShowCmd == win.SW_SHOWMAXIMIZED ||
+		wp.ShowCmd == win.SW_SHOWMINIMIZED && wp.Flags&win.WPF_RESTORETOMAXIMIZED != 0
+
+	var mi monitorInfoEx
+	getMonitorInfoEx(win.MonitorFromWindow(fb.hWnd, win.MONITOR_DEFAULTTONEAREST), &mi)
+
+	state := fmt.Sprintf("%d %d %d %d %t %d %q",
+		bounds.X, bounds.Y, bounds.Width, bounds.Height,
+		maximized,
+		fb.DPI(),
+		windows.UTF16ToString(mi.szDevice[:]))
+
+	return settings.Put(key, state)
+}
+
+// RestoreWindowPlacement restores the placement written by
+// SaveWindowPlacement, if any, and shows the *FormBase.
+//
+// The placement is validated against the current monitor configuration. If
+// the monitor the *FormBase was on is gone or has a different scale factor,
+// it is placed on the nearest monitor and rescaled. It is always moved and,
+// if necessary, shrunk to lie within the work area of that monitor, so it
+// never ends up off-screen.
+func (fb *FormBase) RestoreWindowPlacement() error {
+	return fb.restoreWindowPlacement(fb.placementKey())
+}
+
+// restoreWindowPlacement implements RestoreWindowPlacement, reading the
+// placement stored under key.
+func (fb *FormBase) restoreWindowPlacement(key string) error {
+	settings := App().Settings()
+	if settings == nil {
+		return newError("App().Settings() must not be nil")
+	}
+
+	state, ok := settings.Get(key)
+	if !ok || state == "" {
+		return nil
+	}
+
+	var bounds Rectangle
+	var maximized bool
+	var dpi int
+	var device string
+
+	if _, err := fmt.Sscanf(state, "%d %d %d %d %t %d %q",
+		&bounds.X, &bounds.Y, &bounds.Width, &bounds.Height,
+		&maximized,
+		&dpi,
+		&device); err != nil {
+		return wrapError(err)
+	}
+
+	if bounds.Width <= 0 || bounds.Height <= 0 {
+		return newError("invalid window placement")
+	}
+
+	rc := bounds.toRECT()
+	monitor := monitorFromRect(&rc, win.MONITOR_DEFAULTTONEAREST)
+
+	var mi monitorInfoEx
+	if !getMonitorInfoEx(monitor, &mi) {
+		return newError("GetMonitorInfo failed")
+	}
+
+	// A different monitor or scale factor invalidates the saved size.
+	if monitorDPI := getDpiForMonitor(monitor); monitorDPI > 0 && dpi > 0 && monitorDPI != dpi {
+		bounds.Width = scaleInt(bounds.Width, float64(monitorDPI)/float64(dpi))
+		bounds.Height = scaleInt(bounds.Height, float64(monitorDPI)/float64(dpi))
+	}
+	if windows.UTF16ToString(mi.szDevice[:]) != device {
+		work := rectangleFromRECT(mi.RcWork)
+		bounds.X = work.X + (work.Width-bounds.Width)/2
+		bounds.Y = work.Y + (work.Height-bounds.Height)/2
+	}
+
+	bounds = fitRectToWorkArea(bounds, rectangleFromRECT(mi.RcWork))
+
+	offset := fb.workspaceOffset()
+	bounds.X -= offset.X
+	bounds.Y -= offset.Y
+
+	var wp win.WINDOWPLACEMENT
+	wp.Length = uint32(unsafe.Sizeof(wp))
+	wp.RcNormalPosition = bounds.toRECT()
+	wp.ShowCmd = win.SW_SHOWNORMAL
+	if maximized {
+		wp.ShowCmd = win.SW_SHOWMAXIMIZED
+	}
+
+	if !win.SetWindowPlacement(fb.hWnd, &wp) {
+		return lastError("SetWindowPlacement")
+	}
+
+	return nil
+}
+
+// workspaceOffset returns the offset of workspace coordinates, as used by
+// WINDOWPLACEMENT, from screen coordinates.
+func (fb *FormBase) workspaceOffset() Point {
+	if win.GetWindowLong(fb.hWnd, win.GWL_EXSTYLE)&win.WS_EX_TOOLWINDOW != 0 {
+		return Point{}
+	}
+
+	var mi win.MONITORINFO
+	mi.CbSize = uint32(unsafe.Sizeof(mi))
+
+	if !win.GetMonitorInfo(win.MonitorFromWindow(0, win.MONITOR_DEFAULTTOPRIMARY), &mi) {
+		return Point{}
+	}
+
+	return Point{
+		X: int(mi.RcWork.Left - mi.RcMonitor.Left),
+		Y: int(mi.RcWork.Top - mi.RcMonitor.Top),
+	}
+}
+
+// fitRectToWorkArea moves r into work, shrinking it if it is larger.
+func fitRectToWorkArea(r, work Rectangle) Rectangle {
+	r.Width = mini(r.Width, work.Width)
+	r.Height = mini(r.Height, work.Height)
+
+	r.X = maxi(work.X, mini(r.X, work.X+work.Width-r.Width))
+	r.Y = maxi(work.Y, mini(r.Y, work.Y+work.Height-r.Height))
+
+	return r
+}
@@ -0,0 +1,196 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"unsafe"
+
+	"github.com/tailscale/win"
+)
+
+// Side specifies a side of a rectangle.
+type Side int
+
+const (
+	SideBottom Side = iota
+	SideTop
+	SideRight
+	SideLeft
+)
+
+// StartupPosition specifies where a Form is placed when it is shown for the
+// first time.
+type StartupPosition int
+
+const (
+	// StartupPositionDefault leaves the position to the system, or for
+	// dialogs, centers them on their owner.
+	StartupPositionDefault StartupPosition = iota
+
+	// StartupPositionCenterOwner centers the Form on its owner, or on the
+	// monitor of the mouse pointer if it has none.
+	StartupPositionCenterOwner
+
+	// StartupPositionCenterMonitor centers the Form on the monitor of the
+	// mouse pointer, which is typically the one the user is working on.
+	StartupPositionCenterMonitor
+)
+
+// StartupPosition returns where the *FormBase is placed when it is shown for
+// the first time.
+func (fb *FormBase) StartupPosition() StartupPosition {
+	return fb.startupPosition
+}
+
+// SetStartupPosition sets where the *FormBase is placed when it is shown for
+// the first time. A placement restored from the settings takes precedence.
+func (fb *FormBase) SetStartupPosition(position StartupPosition) {
+	fb.startupPosition = position
+}
+
+// applyStartupPosition places the *FormBase according to its startup
+// position, using size, in native pixels, as its size.
+func (fb *FormBase) applyStartupPosition(size Size) {
+	if fb.startupPositionApplied {
+		return
+	}
+	fb.startupPositionApplied = true
+
+	switch fb.startupPosition {
+	case StartupPositionCenterOwner:
+		if fb.owner != nil {
+			fb.SetBoundsPixels(centerRectOn(fb.owner.BoundsPixels(), size))
+			return
+		}
+		fallthrough
+
+	case StartupPositionCenterMonitor:
+		var pt win.POINT
+		win.GetCursorPos(&pt)
+		fb.centerOnMonitor(Point{int(pt.X), int(pt.Y)}, size)
+	}
+}
+
+// CenterOnOwner centers the *FormBase on its owner, or on the monitor it is
+// on if it has no owner. It is kept within the work area of the monitor it
+// ends up on.
+func (fb *FormBase) CenterOnOwner() {
+	if fb.owner == nil {
+		fb.CenterOnMonitor(fb.BoundsPixels().Location())
+		return
+	}
+
+	fb.SetBoundsPixels(centerRectOn(fb.owner.BoundsPixels(), fb.SizePixels()))
+}
+
+// CenterOnMonitor centers the *FormBase on the work area of the monitor that
+// contains monitor, or is nearest to it, a point in screen coordinates and
+// native pixels. It is shrunk if it is larger than the work area.
+func (fb *FormBase) CenterOnMonitor(monitor Point) {
+	fb.centerOnMonitor(monitor, fb.SizePixels())
+}
+
+func (fb *FormBase) centerOnMonitor(monitor Point, size Size) {
+	work, ok := workAreaFromRect(Rectangle{monitor.X, monitor.Y, 1, 1})
+	if !ok {
+		return
+	}
+
+	bounds := Rectangle{
+		X:      work.X + (work.Width-size.Width)/2,
+		Y:      work.Y + (work.Height-size.Height)/2,
+		Width:  size.Width,
+		Height: size.Height,
+	}
+
+	fb.SetBoundsPixels(fitRectToWorkArea(bounds, work))
+}
+
+// PositionNear places the *FormBase next to anchor, a rectangle in screen
+// coordinates and native pixels, e.g. the bounds of the widget a popup
+// belongs to. It is placed at preferredSide of anchor, unless there is more
+// room at the opposite side, and aligned with the left or top edge of anchor.
+// It is kept within the work area of the monitor of anchor.
+func (fb *FormBase) PositionNear(anchor Rectangle, preferredSide Side) {
+	size := fb.SizePixels()
+
+	work, ok := workAreaFromRect(anchor)
+	if !ok {
+		return
+	}
+
+	bounds := Rectangle{Width: size.Width, Height: size.Height}
+
+	switch preferredSide {
+	case SideTop, SideBottom:
+		bounds.X = anchor.X
+
+		above := anchor.Y - work.Y
+		below := work.Y + work.Height - (anchor.Y + anchor.Height)
+
+		if preferredSide == SideTop && (above >= size.Height || above >= below) ||
+			preferredSide == SideBottom && below < size.Height && above > below {
+			bounds.Y = anchor.Y - size.Height
+		} else {
+			bounds.Y = anchor.Y + anchor.Height
+		}
+
+	case SideLeft, SideRight:
+		bounds.Y = anchor.Y
+
+		left := anchor.X - work.X
+		right := work.X + work.Width - (anchor.X + anchor.Width)
+
+		if preferredSide == SideLeft && (left >= size.Width || left >= right) ||
+			preferredSide == SideRight && right < size.Width && left > right {
+			bounds.X = anchor.X - size.Width
+		} else {
+			bounds.X = anchor.X + anchor.Width
+		}
+	}
+
+	fb.SetBoundsPixels(fitRectToWorkArea(bounds, work))
+}
+
+// centerRectOn returns a rectangle of size centered on area, kept within the
+// work area of the monitor it ends up on.
+func centerRectOn(area Rectangle, size Size) Rectangle {
+	return fitRectToMonitor(Rectangle{
+		X:      area.X + (area.Width-size.Width)/2,
+		Y:      area.Y + (area.Height-size.Height)/2,
+		Width:  size.Width,
+		Height: size.Height,
+	})
+}
+
+// fitRectToMonitor moves r into the work area of the monitor it mostly is on,
+// or is nearest to, shrinking it if it is larger. Unlike fitRectToScreen, it
+// does not depend on the monitor a window currently is on.
+func fitRectToMonitor(r Rectangle) Rectangle {
+	work, ok := workAreaFromRect(r)
+	if !ok {
+		return r
+	}
+
+	return fitRectToWorkArea(r, work)
+}
+
+// workAreaFromRect returns the work area of the monitor r, in screen
+// coordinates, mostly is on or is nearest to.
+func workAreaFromRect(r Rectangle) (Rectangle, bool) {
+	rc := r.toRECT()
+
+	var mi win.MONITORINFO
+	mi.CbSize = uint32(unsafe.Sizeof(mi))
+
+	if !win.GetMonitorInfo(monitorFromRect(&rc, win.MONITOR_DEFAULTTONEAREST), &mi) {
+		return Rectangle{}, false
+	}
+
+	return rectangleFromRECT(mi.RcWork), true
+}
@@ -0,0 +1,114 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"github.com/tailscale/win"
+)
+
+// WindowState is the state of a Form: normal, minimized or maximized.
+type WindowState int
+
+const (
+	WindowStateNormal WindowState = iota
+	WindowStateMinimized
+	WindowStateMaximized
+)
+
+// WindowState returns whether the *FormBase is minimized, maximized or
+// neither.
+func (fb *FormBase) WindowState() WindowState {
+	switch {
+	case win.IsIconic(fb.hWnd):
+		return WindowStateMinimized
+
+	case win.IsZoomed(fb.hWnd):
+		return WindowStateMaximized
+	}
+
+	return WindowStateNormal
+}
+
+// Minimized returns the event that is published when the *FormBase was
+// minimized. Applications can use it to pause rendering until it is restored.
+func (fb *FormBase) Minimized() *Event {
+	return fb.minimizedPublisher.Event()
+}
+
+// Maximized returns the event that is published when the *FormBase was
+// maximized, including when it is restored from being minimized while
+// maximized.
+func (fb *FormBase) Maximized() *Event {
+	return fb.maximizedPublisher.Event()
+}
+
+// Restored returns the event that is published when the *FormBase was
+// restored to its normal state after being minimized or maximized.
+func (fb *FormBase) Restored() *Event {
+	return fb.restoredPublisher.Event()
+}
+
+// Moved returns the event that is published when the position of the
+// *FormBase changed. It is not published for minimizing and restoring.
+func (fb *FormBase) Moved() *Event {
+	return fb.movedPublisher.Event()
+}
+
+// MovedToMonitor returns the event that is published when the *FormBase was
+// moved to another monitor, or more precisely, when the monitor it mostly is
+// on changed. Unlike DPIChanged, it is also published if both monitors have
+// the same scale factor.
+func (fb *FormBase) MovedToMonitor() *Event {
+	return fb.movedToMonitorPublisher.Event()
+}
+
+// updateWindowState publishes the window state events for the changes
+// described by wp.
+func (fb *FormBase) updateWindowState(wp *win.WINDOWPOS) {
+	if !win.IsWindowVisible(fb.hWnd) {
+		return
+	}
+
+	if state := fb.WindowState(); state != fb.windowState {
+		fb.windowState = state
+
+		switch state {
+		case WindowStateMinimized:
+			fb.minimizedPublisher.Publish()
+
+		case WindowStateMaximized:
+			fb.maximizedPublisher.Publish()
+
+		default:
+			fb.restoredPublisher.Publish()
+		}
+	}
+
+	if fb.windowState == WindowStateMinimized {
+		return
+	}
+
+	// The position and monitor the form is first shown at are no changes.
+	first := fb.monitor == 0
+
+	if wp.Flags&win.SWP_NOMOVE == 0 {
+		if pos := (Point{int(wp.X), int(wp.Y)}); pos != fb.position {
+			fb.position = pos
+			if !first {
+				fb.movedPublisher.Publish()
+			}
+		}
+	}
+
+	if monitor := win.MonitorFromWindow(fb.hWnd, win.MONITOR_DEFAULTTONEAREST); monitor != fb.monitor {
+		fb.monitor = monitor
+		if !first {
+			fb.movedToMonitorPublisher.Publish()
+		}
+	}
+}
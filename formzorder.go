@@ -0,0 +1,109 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"github.com/tailscale/win"
+)
+
+// AlwaysOnTop returns whether the *FormBase stays above all windows that are
+// not topmost, even when inactive.
+func (fb *FormBase) AlwaysOnTop() bool {
+	return fb.alwaysOnTop
+}
+
+// SetAlwaysOnTop sets whether the *FormBase stays above all windows that are
+// not topmost, even when inactive. The forms owned by the *FormBase follow it.
+func (fb *FormBase) SetAlwaysOnTop(value bool) error {
+	if value == fb.alwaysOnTop {
+		return nil
+	}
+
+	insertAfter := win.HWND_NOTOPMOST
+	if value {
+		insertAfter = win.HWND_TOPMOST
+	}
+
+	return fb.setZOrder(insertAfter, win.SWP_NOACTIVATE)
+}
+
+// AlwaysOnTopChanged returns the event that is published when the
+// *FormBase became topmost or stopped being topmost, including when this
+// was caused by another application.
+func (fb *FormBase) AlwaysOnTopChanged() *Event {
+	return fb.alwaysOnTopChangedPublisher.Event()
+}
+
+// BringToFront moves the *FormBase, along with the forms it owns, to the top
+// of the z-order of its group, that is of topmost or non-topmost windows, and
+// activates it.
+func (fb *FormBase) BringToFront() error {
+	return fb.setZOrder(win.HWND_TOP, 0)
+}
+
+// SendToBack moves the *FormBase to the bottom of the z-order. A topmost
+// *FormBase stops being topmost.
+func (fb *FormBase) SendToBack() error {
+	return fb.setZOrder(win.HWND_BOTTOM, win.SWP_NOACTIVATE)
+}
+
+// PlaceAbove moves the *FormBase directly above other in the z-order, without
+// activating it.
+func (fb *FormBase) PlaceAbove(other Form) error {
+	if other == nil {
+		return newError("other cannot be nil")
+	}
+
+	insertAfter := win.GetWindow(other.Handle(), win.GW_HWNDPREV)
+	if insertAfter == fb.hWnd {
+		return nil
+	}
+	if insertAfter == 0 {
+		insertAfter = win.HWND_TOP
+	}
+
+	return fb.setZOrder(insertAfter, win.SWP_NOACTIVATE)
+}
+
+// PlaceBelow moves the *FormBase directly below other in the z-order. A
+// *FormBase can not be placed below a form it owns, as owned windows are
+// always kept above their owner.
+func (fb *FormBase) PlaceBelow(other Form) error {
+	if other == nil {
+		return newError("other cannot be nil")
+	}
+
+	for owner := other.Owner(); owner != nil; owner = owner.Owner() {
+		if owner.Handle() == fb.hWnd {
+			return newError("a form cannot be placed below a form it owns")
+		}
+	}
+
+	return fb.setZOrder(other.Handle(), win.SWP_NOACTIVATE)
+}
+
+func (fb *FormBase) setZOrder(insertAfter win.HWND, flags uint32) error {
+	if !win.SetWindowPos(fb.hWnd, insertAfter, 0, 0, 0, 0, win.SWP_NOMOVE|win.SWP_NOSIZE|flags) {
+		return lastError("SetWindowPos")
+	}
+
+	return nil
+}
+
+// updateAlwaysOnTop publishes AlwaysOnTopChanged if the topmost state of the
+// *FormBase changed.
+func (fb *FormBase) updateAlwaysOnTop() {
+	topmost := win.GetWindowLong(fb.hWnd, win.GWL_EXSTYLE)&win.WS_EX_TOPMOST != 0
+	if topmost == fb.alwaysOnTop {
+		return
+	}
+
+	fb.alwaysOnTop = topmost
+
+	fb.alwaysOnTopChangedPublisher.Publish()
+}
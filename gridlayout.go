@@ -35,6 +35,7 @@ type GridLayout struct {
 	columnStretchFactors []int
 	widgetBase2Info      map[*WidgetBase]*gridLayoutWidgetInfo
 	cells                [][]gridLayoutCell
+	baselineAlignment    bool
 }
 
 func NewGridLayout() *GridLayout {
@@ -101,6 +102,28 @@ func (l *GridLayout) ensureSufficientSize(rows, columns int) {
 	}
 }
 
+// BaselineAlignment returns whether items in the same row that display text
+// are aligned on the baselines of their texts.
+func (l *GridLayout) BaselineAlignment() bool {
+	return l.baselineAlignment
+}
+
+// SetBaselineAlignment sets whether items in the same row that display text,
+// like labels, LineEdits and ComboBoxes, are aligned on the baselines of their
+// texts instead of according to their alignment. Items spanning multiple rows
+// are not affected.
+func (l *GridLayout) SetBaselineAlignment(value bool) {
+	if value == l.baselineAlignment {
+		return
+	}
+
+	l.baselineAlignment = value
+
+	if l.container != nil {
+		l.container.RequestLayout()
+	}
+}
+
 func (l *GridLayout) RowStretchFactor(row int) int {
 	if row < 0 {
 		// FIXME: Should we rather return an error?
@@ -309,6 +332,7 @@ func (l *GridLayout) CreateLayoutItem(ctx *LayoutContext) ContainerLayoutItem {
 		columnStretchFactors: append([]int(nil), l.columnStretchFactors...),
 		item2Info:            item2Info,
 		cells:                cells,
+		baselineAlignment:    l.baselineAlignment,
 	}
 }
 
@@ -321,6 +345,7 @@ type gridLayoutItem struct {
 	item2Info            map[LayoutItem]*gridLayoutItemInfo
 	cells                [][]gridLayoutItemCell
 	minSize              Size // in native pixels
+	baselineAlignment    bool
 }
 
 type gridLayoutItemInfo struct {
@@ -636,7 +661,22 @@ func (li *gridLayoutItem) PerformLayout() []LayoutResultItem {
 			h = mini(h, height)
 		}
 
-		alignment := item.Geometry().Alignment
+		// Honor the min and max size of the item within its cell.
+		geometry := item.Geometry()
+		if geometry.MaxSize.Width > 0 && w > geometry.MaxSize.Width {
+			w = geometry.MaxSize.Width
+		}
+		if geometry.MaxSize.Height > 0 && h > geometry.MaxSize.Height {
+			h = geometry.MaxSize.Height
+		}
+		if w < geometry.MinSize.Width {
+			w = mini(geometry.MinSize.Width, width)
+		}
+		if h < geometry.MinSize.Height {
+			h = mini(geometry.MinSize.Height, height)
+		}
+
+		alignment := geometry.Alignment
 		if alignment == AlignHVDefault {
 			alignment = li.alignment
 		}
@@ -664,9 +704,70 @@ func (li *gridLayoutItem) PerformLayout() []LayoutResultItem {
 		items = append(items, LayoutResultItem{Item: item, Bounds: Rectangle{X: x, Y: y, Width: w, Height: h}})
 	}
 
+	if li.baselineAlignment {
+		li.alignBaselines(items, heights)
+	}
+
 	return items
 }
 
+// alignBaselines moves items in the same row vertically, so the baselines of
+// their texts line up. heights are the row heights in native pixels.
+func (li *gridLayoutItem) alignBaselines(items []LayoutResultItem, heights []int) {
+	margins := MarginsFrom96DPI(li.margins96dpi, li.ctx.dpi)
+	spacing := IntFrom96DPI(li.spacing96dpi, li.ctx.dpi)
+
+	row2Indexes := make(map[int][]int)
+	for i, item := range items {
+		if _, ok := item.Item.(Baseliner); !ok {
+			continue
+		}
+
+		if info := li.item2Info[item.Item]; info.spanVert == 1 {
+			row2Indexes[info.cell.row] = append(row2Indexes[info.cell.row], i)
+		}
+	}
+
+	for row, indexes := range row2Indexes {
+		if len(indexes) < 2 {
+			continue
+		}
+
+		top := margins.VNear
+		for i := 0; i < row; i++ {
+			if h := heights[i]; h > 0 {
+				top += h + spacing
+			}
+		}
+		bottom := top + heights[row]
+
+		// The item with the lowest baseline keeps its position, the others
+		// move down to it.
+		var baseline int
+		for _, i := range indexes {
+			b := &items[i].Bounds
+			baseline = maxi(baseline, b.Y+items[i].Item.(Baseliner).Baseline(b.Size()))
+		}
+
+		overflow := 0
+		minTop := bottom
+		for _, i := range indexes {
+			b := &items[i].Bounds
+			b.Y = baseline - items[i].Item.(Baseliner).Baseline(b.Size())
+
+			overflow = maxi(overflow, b.Y+b.Height-bottom)
+			minTop = mini(minTop, b.Y)
+		}
+
+		// Move the items up again as far as needed to keep them in their row.
+		if shift := mini(overflow, minTop-top); shift > 0 {
+			for _, i := range indexes {
+				items[i].Bounds.Y -= shift
+			}
+		}
+	}
+}
+
 // sectionSizesForSpace returns section sizes. Input and outpus is measured in native pixels.
 func (li *gridLayoutItem) sectionSizesForSpace(orientation Orientation, space int, widths []int) []int {
 	var stretchFactors []int
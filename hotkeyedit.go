@@ -0,0 +1,166 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"github.com/tailscale/win"
+)
+
+// HotkeyEdit is a widget that records a key combination typed by the user,
+// using the msctls_hotkey32 common control. The combination is displayed
+// using the key names of the current keyboard layout.
+type HotkeyEdit struct {
+	WidgetBase
+	shortcutChangedPublisher EventPublisher
+}
+
+// NewHotkeyEdit returns a new HotkeyEdit widget as child of parent.
+func NewHotkeyEdit(parent Container) (*HotkeyEdit, error) {
+	he := new(HotkeyEdit)
+
+	if err := InitWidget(
+		he,
+		parent,
+		"msctls_hotkey32",
+		win.WS_TABSTOP|win.WS_VISIBLE,
+		win.WS_EX_CLIENTEDGE); err != nil {
+		return nil, err
+	}
+
+	he.GraphicsEffects().Add(InteractionEffect)
+	he.GraphicsEffects().Add(FocusEffect)
+
+	he.MustRegisterProperty("Shortcut", NewProperty(
+		func() interface{} {
+			return he.Shortcut()
+		},
+		func(v interface{}) error {
+			shortcut, _ := v.(Shortcut)
+			he.SetShortcut(shortcut)
+			return nil
+		},
+		he.shortcutChangedPublisher.Event()))
+
+	return he, nil
+}
+
+// Shortcut returns the key combination currently recorded. A zero Key means
+// that no key has been entered yet.
+func (he *HotkeyEdit) Shortcut() Shortcut {
+	ret := he.SendMessage(_HKM_GETHOTKEY, 0, 0)
+
+	return Shortcut{
+		Modifiers: Modifiers(win.HIBYTE(uint16(ret)) & (_HOTKEYF_SHIFT | _HOTKEYF_CONTROL | _HOTKEYF_ALT)),
+		Key:       Key(win.LOBYTE(uint16(ret))),
+	}
+}
+
+// SetShortcut sets the key combination displayed by the control.
+func (he *HotkeyEdit) SetShortcut(shortcut Shortcut) {
+	if shortcut == he.Shortcut() {
+		return
+	}
+
+	// Modifiers uses the same bit values as HOTKEYF_*.
+	flags := byte(shortcut.Modifiers)
+	if isExtendedKey(shortcut.Key) {
+		flags |= _HOTKEYF_EXT
+	}
+
+	he.SendMessage(_HKM_SETHOTKEY, uintptr(flags)<<8|uintptr(byte(shortcut.Key)), 0)
+
+	he.shortcutChangedPublisher.Publish()
+}
+
+// SetInvalidModifiers prevents the user from entering keys with any of the
+// specified modifier combinations. When the user does, fallback is used
+// instead.
+//
+// To require at least one modifier for example, pass []Modifiers{0, ModShift}
+// as invalid and ModControl as fallback.
+func (he *HotkeyEdit) SetInvalidModifiers(invalid []Modifiers, fallback Modifiers) {
+	var rules uintptr
+	for _, m := range invalid {
+		rules |= hotkeyModifiers2Comb[m&(ModShift|ModControl|ModAlt)]
+	}
+
+	he.SendMessage(_HKM_SETRULES, rules, uintptr(fallback))
+}
+
+var hotkeyModifiers2Comb = map[Modifiers]uintptr{
+	0:                              _HKCOMB_NONE,
+	ModShift:                       _HKCOMB_S,
+	ModControl:                     _HKCOMB_C,
+	ModAlt:                         _HKCOMB_A,
+	ModShift | ModControl:          _HKCOMB_SC,
+	ModShift | ModAlt:              _HKCOMB_SA,
+	ModControl | ModAlt:            _HKCOMB_CA,
+	ModShift | ModControl | ModAlt: _HKCOMB_SCA,
+}
+
+// isExtendedKey returns whether k is reported with the extended-key flag, so
+// it is not displayed as its numeric keypad counterpart.
+func isExtendedKey(k Key) bool {
+	switch k {
+	case KeyPrior, KeyNext, KeyEnd, KeyHome, KeyLeft, KeyUp, KeyRight, KeyDown,
+		KeyInsert, KeyDelete, KeyDivide, KeyNumlock, KeyRControl, KeyRMenu, KeySnapshot:
+		return true
+	}
+
+	return false
+}
+
+// ShortcutChanged returns the event that is published when the recorded key
+// combination changes.
+func (he *HotkeyEdit) ShortcutChanged() *Event {
+	return he.shortcutChangedPublisher.Event()
+}
+
+func (*HotkeyEdit) NeedsWmSize() bool {
+	return true
+}
+
+func (he *HotkeyEdit) WndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) uintptr {
+	switch msg {
+	case win.WM_COMMAND:
+		switch win.HIWORD(uint32(wParam)) {
+		case win.EN_CHANGE:
+			he.shortcutChangedPublisher.Publish()
+		}
+	}
+
+	return he.WidgetBase.WndProc(hwnd, msg, wParam, lParam)
+}
+
+func (he *HotkeyEdit) CreateLayoutItem(ctx *LayoutContext) LayoutItem {
+	size := he.dialogBaseUnitsToPixels(Size{0, 12})
+	size.Width = he.calculateTextSizeImpl("Ctrl + Shift + Alt + Delete").Width + he.IntFrom96DPI(8)
+
+	return &hotkeyEditLayoutItem{
+		idealSize: size,
+		minSize:   Size{size.Width / 2, size.Height},
+	}
+}
+
+type hotkeyEditLayoutItem struct {
+	LayoutItemBase
+	idealSize Size // in native pixels
+	minSize   Size // in native pixels
+}
+
+func (*hotkeyEditLayoutItem) LayoutFlags() LayoutFlags {
+	return ShrinkableHorz | GrowableHorz
+}
+
+func (li *hotkeyEditLayoutItem) IdealSize() Size {
+	return li.idealSize
+}
+
+func (li *hotkeyEditLayoutItem) MinSize() Size {
+	return li.minSize
+}
@@ -6,6 +6,11 @@
 
 package walk
 
+import (
+	"container/list"
+	"fmt"
+)
+
 var iconCache *IconCache
 
 func init() {
@@ -14,9 +19,30 @@ func init() {
 	})
 }
 
+// DefaultIconCache returns the IconCache that walk uses internally to hold
+// DPI-specific variants of images assigned to widgets, menus, forms and
+// notification icons.
+func DefaultIconCache() *IconCache {
+	return iconCache
+}
+
+// IconCache holds Bitmap and Icon variants of images, keyed by image and DPI.
+//
+// By default the cache grows without bound. Use SetMaxBytes to impose a memory
+// budget; once exceeded, the least recently used entries are disposed. Entries
+// whose handles walk has passed to windows, e.g. as the image of a button or
+// the icon of a form, are never evicted while in use. Images that change
+// frequently (e.g. animated notification icons) should be passed to Release
+// once they are no longer referenced.
 type IconCache struct {
-	imageAndDPI2Bitmap map[imageAndDPI]*Bitmap
-	imageAndDPI2Icon   map[imageAndDPI]*Icon
+	imageAndDPI2Bitmap map[imageAndDPI]*list.Element
+	imageAndDPI2Icon   map[imageAndDPI]*list.Element
+	lru                list.List // of *iconCacheEntry, most recently used first
+	maxBytes           int64
+	bytes              int64
+	hits               uint64
+	misses             uint64
+	evictions          uint64
 }
 
 type imageAndDPI struct {
@@ -24,21 +50,108 @@ type imageAndDPI struct {
 	dpi   int
 }
 
+type iconCacheEntry struct {
+	key      imageAndDPI
+	bmp      *Bitmap
+	ico      *Icon
+	bytes    int64
+	owned    bool // false if the entry refers to the caller's own Icon
+	pins     int  // number of iconCachePins holding the entry
+	released bool // true if the entry is to be removed once no longer pinned
+}
+
+// iconCachePins holds IconCache entries whose handles are in use, e.g. by a
+// window through BM_SETIMAGE or WM_SETICON, so they are not disposed before
+// the window stops using them.
+type iconCachePins []*list.Element
+
+// IconCacheStats contains counters describing the state of an IconCache.
+//
+// IconCacheStats implements the expvar.Var interface, so it can be published
+// directly via expvar.Func.
+type IconCacheStats struct {
+	Entries   int
+	Bytes     int64
+	MaxBytes  int64
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+}
+
+// HitRate returns the fraction of lookups that were served from the cache.
+func (s IconCacheStats) HitRate() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+
+	return float64(s.Hits) / float64(total)
+}
+
+// String returns s formatted as a JSON object.
+func (s IconCacheStats) String() string {
+	return fmt.Sprintf(
+		`{"entries": %d, "bytes": %d, "maxBytes": %d, "hits": %d, "misses": %d, "evictions": %d, "hitRate": %g}`,
+		s.Entries, s.Bytes, s.MaxBytes, s.Hits, s.Misses, s.Evictions, s.HitRate())
+}
+
 func NewIconCache() *IconCache {
 	return &IconCache{
-		imageAndDPI2Bitmap: make(map[imageAndDPI]*Bitmap),
-		imageAndDPI2Icon:   make(map[imageAndDPI]*Icon),
+		imageAndDPI2Bitmap: make(map[imageAndDPI]*list.Element),
+		imageAndDPI2Icon:   make(map[imageAndDPI]*list.Element),
 	}
 }
 
-func (ic *IconCache) Clear() {
-	for key, bmp := range ic.imageAndDPI2Bitmap {
-		bmp.Dispose()
-		delete(ic.imageAndDPI2Bitmap, key)
+// MaxBytes returns the memory budget of the cache in bytes. Zero means
+// unlimited.
+func (ic *IconCache) MaxBytes() int64 {
+	return ic.maxBytes
+}
+
+// SetMaxBytes sets the memory budget of the cache in bytes and evicts least
+// recently used entries until the cache fits. Zero means unlimited.
+func (ic *IconCache) SetMaxBytes(maxBytes int64) {
+	if maxBytes < 0 {
+		maxBytes = 0
+	}
+
+	ic.maxBytes = maxBytes
+
+	ic.evict(nil)
+}
+
+// Stats returns a snapshot of the cache counters.
+func (ic *IconCache) Stats() IconCacheStats {
+	return IconCacheStats{
+		Entries:   ic.lru.Len(),
+		Bytes:     ic.bytes,
+		MaxBytes:  ic.maxBytes,
+		Hits:      ic.hits,
+		Misses:    ic.misses,
+		Evictions: ic.evictions,
 	}
-	for key, ico := range ic.imageAndDPI2Icon {
-		ico.Dispose()
-		delete(ic.imageAndDPI2Icon, key)
+}
+
+// Release disposes and removes all DPI variants cached for image. Variants
+// that walk still uses are disposed once they are no longer used.
+//
+// Callers must ensure that none of the variants they obtained from Bitmap or
+// Icon are still in use.
+func (ic *IconCache) Release(image Image) {
+	for e := ic.lru.Front(); e != nil; {
+		next := e.Next()
+		if e.Value.(*iconCacheEntry).key.image == image {
+			ic.release(e)
+		}
+		e = next
+	}
+}
+
+func (ic *IconCache) Clear() {
+	for e := ic.lru.Front(); e != nil; {
+		next := e.Next()
+		ic.release(e)
+		e = next
 	}
 }
 
@@ -49,10 +162,14 @@ func (ic *IconCache) Dispose() {
 func (ic *IconCache) Bitmap(image Image, dpi int) (*Bitmap, error) {
 	key := imageAndDPI{image, dpi}
 
-	if bmp, ok := ic.imageAndDPI2Bitmap[key]; ok {
-		return bmp, nil
+	if e, ok := ic.imageAndDPI2Bitmap[key]; ok {
+		ic.hits++
+		ic.lru.MoveToFront(e)
+		return e.Value.(*iconCacheEntry).bmp, nil
 	}
 
+	ic.misses++
+
 	size := SizeFrom96DPI(image.Size(), dpi)
 
 	bmp, err := NewBitmapFromImageWithSize(image, size)
@@ -60,7 +177,12 @@ func (ic *IconCache) Bitmap(image Image, dpi int) (*Bitmap, error) {
 		return nil, err
 	}
 
-	ic.imageAndDPI2Bitmap[key] = bmp
+	ic.imageAndDPI2Bitmap[key] = ic.add(&iconCacheEntry{
+		key:   key,
+		bmp:   bmp,
+		bytes: bitmapBytes(bmp.size),
+		owned: true,
+	})
 
 	return bmp, nil
 }
@@ -68,13 +190,17 @@ func (ic *IconCache) Bitmap(image Image, dpi int) (*Bitmap, error) {
 func (ic *IconCache) Icon(image Image, dpi int) (*Icon, error) {
 	key := imageAndDPI{image, dpi}
 
-	if ico, ok := ic.imageAndDPI2Icon[key]; ok {
-		return ico, nil
+	if e, ok := ic.imageAndDPI2Icon[key]; ok {
+		ic.hits++
+		ic.lru.MoveToFront(e)
+		return e.Value.(*iconCacheEntry).ico, nil
 	}
 
+	ic.misses++
+
 	if ico, ok := image.(*Icon); ok {
 		if ico.handleForDPI(dpi) != 0 {
-			ic.imageAndDPI2Icon[key] = ico
+			ic.imageAndDPI2Icon[key] = ic.add(&iconCacheEntry{key: key, ico: ico})
 			return ico, nil
 		}
 	}
@@ -84,7 +210,124 @@ func (ic *IconCache) Icon(image Image, dpi int) (*Icon, error) {
 		return nil, err
 	}
 
-	ic.imageAndDPI2Icon[key] = ico
+	ic.imageAndDPI2Icon[key] = ic.add(&iconCacheEntry{
+		key: key,
+		ico: ico,
+		// Color and mask bitmaps.
+		bytes: 2 * bitmapBytes(SizeFrom96DPI(ico.size96dpi, dpi)),
+		owned: true,
+	})
 
 	return ico, nil
 }
+
+// pinnedBitmap is like Bitmap, but also adds the entry to pins, which keeps it
+// from being evicted until unpin is called.
+func (ic *IconCache) pinnedBitmap(pins *iconCachePins, image Image, dpi int) (*Bitmap, error) {
+	bmp, err := ic.Bitmap(image, dpi)
+	if err != nil {
+		return nil, err
+	}
+
+	ic.pin(pins, ic.imageAndDPI2Bitmap[imageAndDPI{image, dpi}])
+
+	return bmp, nil
+}
+
+// pinnedIcon is like Icon, but also adds the entry to pins, which keeps it
+// from being evicted until unpin is called.
+func (ic *IconCache) pinnedIcon(pins *iconCachePins, image Image, dpi int) (*Icon, error) {
+	ico, err := ic.Icon(image, dpi)
+	if err != nil {
+		return nil, err
+	}
+
+	ic.pin(pins, ic.imageAndDPI2Icon[imageAndDPI{image, dpi}])
+
+	return ico, nil
+}
+
+func (ic *IconCache) pin(pins *iconCachePins, e *list.Element) {
+	e.Value.(*iconCacheEntry).pins++
+
+	*pins = append(*pins, e)
+}
+
+// unpin removes all entries from pins, evicting or removing those that are no
+// longer pinned if necessary.
+func (ic *IconCache) unpin(pins *iconCachePins) {
+	if len(*pins) == 0 {
+		return
+	}
+
+	for _, e := range *pins {
+		entry := e.Value.(*iconCacheEntry)
+		entry.pins--
+
+		if entry.pins == 0 && entry.released {
+			ic.remove(e)
+		}
+	}
+
+	*pins = nil
+
+	ic.evict(nil)
+}
+
+func (ic *IconCache) add(entry *iconCacheEntry) *list.Element {
+	e := ic.lru.PushFront(entry)
+	ic.bytes += entry.bytes
+
+	ic.evict(e)
+
+	return e
+}
+
+// evict removes least recently used entries that are not pinned until the
+// cache fits its budget. The element keep, if not nil, is never evicted.
+func (ic *IconCache) evict(keep *list.Element) {
+	if ic.maxBytes == 0 {
+		return
+	}
+
+	for e := ic.lru.Back(); e != nil && ic.bytes > ic.maxBytes; {
+		prev := e.Prev()
+		if e != keep && e.Value.(*iconCacheEntry).pins == 0 {
+			ic.remove(e)
+			ic.evictions++
+		}
+		e = prev
+	}
+}
+
+// release removes the entry of e, or marks it to be removed once it is no
+// longer pinned.
+func (ic *IconCache) release(e *list.Element) {
+	if entry := e.Value.(*iconCacheEntry); entry.pins > 0 {
+		entry.released = true
+		return
+	}
+
+	ic.remove(e)
+}
+
+func (ic *IconCache) remove(e *list.Element) {
+	entry := ic.lru.Remove(e).(*iconCacheEntry)
+	ic.bytes -= entry.bytes
+
+	if entry.bmp != nil {
+		delete(ic.imageAndDPI2Bitmap, entry.key)
+		if entry.owned {
+			entry.bmp.Dispose()
+		}
+	} else {
+		delete(ic.imageAndDPI2Icon, entry.key)
+		if entry.owned {
+			entry.ico.Dispose()
+		}
+	}
+}
+
+func bitmapBytes(size Size) int64 {
+	return int64(size.Width) * int64(size.Height) * 4
+}
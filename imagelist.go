@@ -21,6 +21,7 @@ type ImageList struct {
 	colorMaskedBitmap2Index  map[*Bitmap]int
 	bitmapMaskedBitmap2Index map[bitmapMaskedBitmap]int
 	icon2Index               map[*Icon]int32
+	imagePins                iconCachePins // cached images added to the list
 }
 
 type bitmapMaskedBitmap struct {
@@ -132,22 +133,66 @@ func (il *ImageList) AddIcon(icon *Icon) (int32, error) {
 }
 
 func (il *ImageList) AddImage(image interface{}) (int32, error) {
-	switch image.(type) {
-	case ExtractableIcon, *Icon:
-		icon, err := IconFrom(image, il.dpi)
-		if err != nil {
-			return 0, err
-		}
+	img, err := ImageFrom(image)
+	if err != nil {
+		return 0, err
+	}
+
+	switch img.(type) {
+	case nil:
+		return il.AddMasked(nil)
 
-		return il.AddIcon(icon)
+	case *Icon:
+		return il.addCachedIcon(img)
 
 	default:
-		bmp, err := BitmapFrom(image, il.dpi)
-		if err != nil {
-			return 0, err
-		}
+		return il.addCachedBitmap(img)
+	}
+}
+
+// addCachedIcon adds the icon of image from the IconCache, which stays pinned
+// until the list is disposed.
+func (il *ImageList) addCachedIcon(image Image) (int32, error) {
+	var pins iconCachePins
+
+	icon, err := iconCache.pinnedIcon(&pins, image, il.dpi)
+	if err != nil {
+		return 0, err
+	}
+
+	_, added := il.icon2Index[icon]
+
+	index, err := il.AddIcon(icon)
+
+	il.keepPins(pins, err == nil && !added)
 
-		return il.AddMasked(bmp)
+	return index, err
+}
+
+// addCachedBitmap adds the bitmap of image from the IconCache, which stays
+// pinned until the list is disposed.
+func (il *ImageList) addCachedBitmap(image Image) (int32, error) {
+	var pins iconCachePins
+
+	bmp, err := iconCache.pinnedBitmap(&pins, image, il.dpi)
+	if err != nil {
+		return 0, err
+	}
+
+	_, added := il.colorMaskedBitmap2Index[bmp]
+
+	index, err := il.AddMasked(bmp)
+
+	il.keepPins(pins, err == nil && !added)
+
+	return index, err
+}
+
+func (il *ImageList) keepPins(pins iconCachePins, keep bool) {
+	if keep {
+		il.imagePins = append(il.imagePins, pins...)
+	} else {
+		iconCache.unpin(&pins)
 	}
 }
 
@@ -164,6 +209,8 @@ func (il *ImageList) Dispose() {
 		win.ImageList_Destroy(il.hIml)
 		il.hIml = 0
 	}
+
+	iconCache.unpin(&il.imagePins)
 }
 
 func (il *ImageList) MaskColor() Color {
@@ -7,6 +7,8 @@
 package walk
 
 import (
+	"image"
+	"image/color"
 	"math"
 
 	"github.com/tailscale/win"
@@ -21,19 +23,38 @@ const (
 	ImageViewModeShrink
 	ImageViewModeZoom
 	ImageViewModeStretch
+
+	// ImageViewModeViewer displays the image at Zoom, letting the user zoom
+	// using the mouse wheel and keyboard, and pan by dragging.
+	ImageViewModeViewer
+)
+
+const (
+	imageViewMinZoom               = 1.0 / 32
+	imageViewMaxZoom               = 32
+	imageViewZoomStep              = 1.25
+	imageViewPanStep96dpi          = 32
+	imageViewCheckerboardCell96dpi = 8
 )
 
 type ImageView struct {
 	*CustomWidget
-	image                  Image
-	imageChangedPublisher  EventPublisher
-	margin96dpi            int
-	marginChangedPublisher EventPublisher
-	mode                   ImageViewMode
+	image                    Image
+	imageChangedPublisher    EventPublisher
+	margin96dpi              int
+	marginChangedPublisher   EventPublisher
+	mode                     ImageViewMode
+	checkerboard             bool
+	zoom                     float64
+	fitToWindow              bool
+	scroll                   Point // in native pixels, of the view into the zoomed image
+	dragging                 bool
+	dragPoint                Point // in native pixels
+	viewportChangedPublisher EventPublisher
 }
 
 func NewImageView(parent Container) (*ImageView, error) {
-	iv := new(ImageView)
+	iv := &ImageView{zoom: 1, fitToWindow: true}
 
 	cw, err := NewCustomWidgetPixels(parent, 0, func(canvas *Canvas, updateBounds Rectangle) error {
 		return iv.drawImage(canvas, updateBounds)
@@ -90,6 +111,17 @@ func (iv *ImageView) SetMode(mode ImageViewMode) {
 
 	iv.mode = mode
 
+	iv.ensureStyleBits(win.WS_TABSTOP, mode == ImageViewModeViewer)
+	if mode == ImageViewModeViewer {
+		iv.SetCursor(CursorSizeAll())
+	} else {
+		iv.SetCursor(nil)
+	}
+
+	if mode == ImageViewModeViewer && iv.fitToWindow {
+		iv.ZoomToFit()
+	}
+
 	iv.Invalidate()
 
 	iv.RequestLayout()
@@ -122,6 +154,14 @@ func (iv *ImageView) SetImage(image Image) error {
 
 	iv.image = image
 
+	if iv.mode == ImageViewModeViewer {
+		if iv.fitToWindow {
+			iv.ZoomToFit()
+		} else {
+			iv.setScroll(iv.scroll)
+		}
+	}
+
 	_, isMetafile := image.(*Metafile)
 	iv.SetClearsBackground(isMetafile)
 
@@ -166,6 +206,324 @@ func (iv *ImageView) MarginChanged() *Event {
 	return iv.marginChangedPublisher.Event()
 }
 
+// Checkerboard returns whether a checkerboard is drawn behind the image, making
+// its transparent parts visible.
+func (iv *ImageView) Checkerboard() bool {
+	return iv.checkerboard
+}
+
+// SetCheckerboard sets whether a checkerboard is drawn behind the image.
+func (iv *ImageView) SetCheckerboard(checkerboard bool) {
+	if checkerboard == iv.checkerboard {
+		return
+	}
+
+	iv.checkerboard = checkerboard
+
+	iv.Invalidate()
+}
+
+// Zoom returns the scale the image is displayed at in ImageViewModeViewer,
+// 1 being its actual size.
+func (iv *ImageView) Zoom() float64 {
+	return iv.zoom
+}
+
+// SetZoom sets the scale the image is displayed at in ImageViewModeViewer,
+// keeping the center of the view in place.
+func (iv *ImageView) SetZoom(zoom float64) {
+	area := iv.viewArea()
+
+	iv.fitToWindow = false
+	iv.zoomAt(zoom, Point{area.X + area.Width/2, area.Y + area.Height/2})
+}
+
+// ZoomIn enlarges the image one step.
+func (iv *ImageView) ZoomIn() {
+	iv.SetZoom(iv.zoom * imageViewZoomStep)
+}
+
+// ZoomOut shrinks the image one step.
+func (iv *ImageView) ZoomOut() {
+	iv.SetZoom(iv.zoom / imageViewZoomStep)
+}
+
+// ZoomToActualSize displays the image at its actual size.
+func (iv *ImageView) ZoomToActualSize() {
+	iv.SetZoom(1)
+}
+
+// ZoomToFit scales the image to fit the view, also after the ImageView is
+// resized, until the zoom is changed otherwise.
+func (iv *ImageView) ZoomToFit() {
+	iv.fitToWindow = true
+
+	if iv.image == nil {
+		return
+	}
+
+	area := iv.viewArea()
+	s := SizeFrom96DPI(iv.image.Size(), iv.DPI())
+	if area.Width <= 0 || area.Height <= 0 || s.Width <= 0 || s.Height <= 0 {
+		return
+	}
+
+	zoom := math.Min(float64(area.Width)/float64(s.Width), float64(area.Height)/float64(s.Height))
+
+	iv.zoomAt(zoom, Point{area.X + area.Width/2, area.Y + area.Height/2})
+}
+
+// Viewport returns the part of the image displayed in ImageViewModeViewer,
+// in 1/96" units like the size of the image.
+func (iv *ImageView) Viewport() Rectangle {
+	if iv.image == nil {
+		return Rectangle{}
+	}
+
+	area := iv.viewArea()
+	b := iv.viewerImageBounds()
+
+	left, top := maxi(area.X, b.X), maxi(area.Y, b.Y)
+	right, bottom := mini(area.X+area.Width, b.X+b.Width), mini(area.Y+area.Height, b.Y+b.Height)
+	if right <= left || bottom <= top {
+		return Rectangle{}
+	}
+
+	visible := Rectangle{
+		X:      int(float64(left-b.X) / iv.zoom),
+		Y:      int(float64(top-b.Y) / iv.zoom),
+		Width:  int(float64(right-left) / iv.zoom),
+		Height: int(float64(bottom-top) / iv.zoom),
+	}
+
+	return RectangleTo96DPI(visible, iv.DPI())
+}
+
+// ViewportChanged returns the event that is published when the image was
+// zoomed or panned in ImageViewModeViewer.
+func (iv *ImageView) ViewportChanged() *Event {
+	return iv.viewportChangedPublisher.Event()
+}
+
+// viewArea returns the client area without the margin, in native pixels.
+func (iv *ImageView) viewArea() Rectangle {
+	cb := iv.ClientBoundsPixels()
+	margin := iv.IntFrom96DPI(iv.margin96dpi)
+
+	return Rectangle{cb.X + margin, cb.Y + margin, cb.Width - 2*margin, cb.Height - 2*margin}
+}
+
+// viewerImageBounds returns the bounds of the zoomed image in
+// ImageViewModeViewer, in native pixels. Dimensions smaller than the view are
+// centered, larger ones scrolled.
+func (iv *ImageView) viewerImageBounds() Rectangle {
+	area := iv.viewArea()
+
+	var b Rectangle
+	if iv.image != nil {
+		s := SizeFrom96DPI(iv.image.Size(), iv.DPI())
+		b.Width = int(math.Round(float64(s.Width) * iv.zoom))
+		b.Height = int(math.Round(float64(s.Height) * iv.zoom))
+	}
+
+	if b.Width <= area.Width {
+		b.X = area.X + (area.Width-b.Width)/2
+	} else {
+		b.X = area.X - iv.scroll.X
+	}
+
+	if b.Height <= area.Height {
+		b.Y = area.Y + (area.Height-b.Height)/2
+	} else {
+		b.Y = area.Y - iv.scroll.Y
+	}
+
+	return b
+}
+
+// zoomAt sets the zoom, keeping the part of the image at p, in native pixels,
+// in place.
+func (iv *ImageView) zoomAt(zoom float64, p Point) {
+	zoom = math.Max(imageViewMinZoom, math.Min(imageViewMaxZoom, zoom))
+
+	old := iv.viewerImageBounds()
+	var fx, fy float64
+	if old.Width > 0 && old.Height > 0 {
+		fx = float64(p.X-old.X) / float64(old.Width)
+		fy = float64(p.Y-old.Y) / float64(old.Height)
+	}
+
+	changed := zoom != iv.zoom
+	iv.zoom = zoom
+
+	b := iv.viewerImageBounds()
+	area := iv.viewArea()
+
+	if !iv.setScroll(Point{
+		X: area.X - p.X + int(fx*float64(b.Width)),
+		Y: area.Y - p.Y + int(fy*float64(b.Height)),
+	}) && changed {
+		iv.Invalidate()
+		iv.viewportChangedPublisher.Publish()
+	}
+}
+
+// setScroll scrolls the view into the zoomed image to scroll, as far as
+// possible, and returns whether it changed.
+func (iv *ImageView) setScroll(scroll Point) bool {
+	area := iv.viewArea()
+	b := iv.viewerImageBounds()
+
+	scroll.X = maxi(0, mini(scroll.X, b.Width-area.Width))
+	scroll.Y = maxi(0, mini(scroll.Y, b.Height-area.Height))
+
+	if scroll == iv.scroll {
+		return false
+	}
+
+	iv.scroll = scroll
+
+	iv.Invalidate()
+	iv.viewportChangedPublisher.Publish()
+
+	return true
+}
+
+func (iv *ImageView) pan(dx, dy int) {
+	iv.setScroll(Point{iv.scroll.X - dx, iv.scroll.Y - dy})
+}
+
+func (iv *ImageView) drawImageStretched(canvas *Canvas, bounds Rectangle) error {
+	if iv.checkerboard {
+		brush, err := newCheckerboardBrush(iv.IntFrom96DPI(imageViewCheckerboardCell96dpi))
+		if err != nil {
+			return err
+		}
+		defer brush.Dispose()
+
+		if err := canvas.FillRectanglePixels(brush, bounds); err != nil {
+			return err
+		}
+	}
+
+	return canvas.DrawImageStretchedPixels(iv.image, bounds)
+}
+
+// newCheckerboardBrush returns a brush painting a light checkerboard with
+// cells of size native pixels.
+func newCheckerboardBrush(size int) (*BitmapBrush, error) {
+	light := color.RGBA{0xff, 0xff, 0xff, 0xff}
+	dark := color.RGBA{0xcc, 0xcc, 0xcc, 0xff}
+
+	im := image.NewRGBA(image.Rect(0, 0, 2*size, 2*size))
+	for y := 0; y < 2*size; y++ {
+		for x := 0; x < 2*size; x++ {
+			if (x/size+y/size)%2 == 0 {
+				im.SetRGBA(x, y, light)
+			} else {
+				im.SetRGBA(x, y, dark)
+			}
+		}
+	}
+
+	bmp, err := NewBitmapFromImageForDPI(im, 96)
+	if err != nil {
+		return nil, err
+	}
+
+	brush, err := NewBitmapBrush(bmp)
+	if err != nil {
+		bmp.Dispose()
+		return nil, err
+	}
+
+	brush.ownsBitmap = true
+
+	return brush, nil
+}
+
+func (iv *ImageView) WndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) uintptr {
+	if iv.mode == ImageViewModeViewer {
+		switch msg {
+		case win.WM_MOUSEWHEEL:
+			delta := float64(int16(win.HIWORD(uint32(wParam)))) / _WHEEL_DELTA
+
+			// The position is in screen coordinates.
+			pt := win.POINT{X: int32(int16(win.LOWORD(uint32(lParam)))), Y: int32(int16(win.HIWORD(uint32(lParam))))}
+			win.ScreenToClient(hwnd, &pt)
+
+			iv.fitToWindow = false
+			iv.zoomAt(iv.zoom*math.Pow(imageViewZoomStep, delta), Point{int(pt.X), int(pt.Y)})
+
+			return 0
+
+		case win.WM_LBUTTONDOWN:
+			iv.SetFocus()
+
+			iv.dragging = true
+			iv.dragPoint = Point{int(win.GET_X_LPARAM(lParam)), int(win.GET_Y_LPARAM(lParam))}
+			win.SetCapture(hwnd)
+
+		case win.WM_MOUSEMOVE:
+			if iv.dragging {
+				p := Point{int(win.GET_X_LPARAM(lParam)), int(win.GET_Y_LPARAM(lParam))}
+				iv.pan(p.X-iv.dragPoint.X, p.Y-iv.dragPoint.Y)
+				iv.dragPoint = p
+			}
+
+		case win.WM_LBUTTONUP:
+			if iv.dragging {
+				iv.dragging = false
+				win.ReleaseCapture()
+			}
+
+		case win.WM_CAPTURECHANGED:
+			iv.dragging = false
+
+		case win.WM_GETDLGCODE:
+			return win.DLGC_WANTARROWS | win.DLGC_WANTCHARS
+
+		case win.WM_KEYDOWN:
+			step := iv.IntFrom96DPI(imageViewPanStep96dpi)
+
+			switch Key(wParam) {
+			case KeyAdd, KeyOEMPlus:
+				iv.ZoomIn()
+
+			case KeySubtract, KeyOEMMinus:
+				iv.ZoomOut()
+
+			case Key0, KeyNumpad0:
+				iv.ZoomToFit()
+
+			case Key1, KeyNumpad1:
+				iv.ZoomToActualSize()
+
+			case KeyLeft:
+				iv.pan(step, 0)
+
+			case KeyRight:
+				iv.pan(-step, 0)
+
+			case KeyUp:
+				iv.pan(0, step)
+
+			case KeyDown:
+				iv.pan(0, -step)
+			}
+
+		case win.WM_SIZE:
+			if iv.fitToWindow {
+				iv.ZoomToFit()
+			} else {
+				iv.setScroll(iv.scroll)
+			}
+		}
+	}
+
+	return iv.CustomWidget.WndProc(hwnd, msg, wParam, lParam)
+}
+
 func (iv *ImageView) drawImage(canvas *Canvas, _ Rectangle) error {
 	if iv.image == nil {
 		return nil
@@ -207,10 +565,15 @@ func (iv *ImageView) drawImage(canvas *Canvas, _ Rectangle) error {
 			bounds.Y = margin + (cb.Height-bounds.Height)/2
 		}
 
-		return canvas.DrawImageStretchedPixels(iv.image, bounds)
+		return iv.drawImageStretched(canvas, bounds)
 
 	case ImageViewModeCorner, ImageViewModeCenter:
 		win.IntersectClipRect(canvas.hdc, int32(margin), int32(margin), int32(cb.Width+margin), int32(cb.Height+margin))
+
+	case ImageViewModeViewer:
+		win.IntersectClipRect(canvas.hdc, int32(margin), int32(margin), int32(cb.Width+margin), int32(cb.Height+margin))
+
+		return iv.drawImageStretched(canvas, iv.viewerImageBounds())
 	}
 
 	var bounds Rectangle
@@ -227,7 +590,7 @@ func (iv *ImageView) drawImage(canvas *Canvas, _ Rectangle) error {
 	bounds.Width = s.Width
 	bounds.Height = s.Height
 
-	return canvas.DrawImageStretchedPixels(iv.image, bounds)
+	return iv.drawImageStretched(canvas, bounds)
 }
 
 func (iv *ImageView) CreateLayoutItem(ctx *LayoutContext) LayoutItem {
@@ -0,0 +1,866 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"math"
+	"reflect"
+	"time"
+	"unsafe"
+
+	"github.com/tailscale/win"
+)
+
+const (
+	infoBarWindowClass            = `\o/ Walk_InfoBar_Class \o/`
+	infoBarCloseButtonWindowClass = `\o/ Walk_InfoBarCloseButton_Class \o/`
+)
+
+func init() {
+	AppendToWalkInit(func() {
+		MustRegisterWindowClass(infoBarWindowClass)
+		MustRegisterWindowClass(infoBarCloseButtonWindowClass)
+	})
+}
+
+const (
+	infoBarTimerId              = 1
+	infoBarTimerElapse          = 16  // in milliseconds
+	infoBarAnimationDuration    = 150 // in milliseconds
+	infoBarPadding96dpi         = 8
+	infoBarIconSize96dpi        = 16
+	infoBarSpacing96dpi         = 8
+	infoBarCloseButtonSize96dpi = 20
+	infoBarCloseGlyphSize96dpi  = 8
+)
+
+// InfoBarSeverity determines the colors and the icon of an InfoBar.
+type InfoBarSeverity int
+
+const (
+	InfoBarInformation InfoBarSeverity = iota
+	InfoBarWarning
+	InfoBarError
+)
+
+func (s InfoBarSeverity) colors() (background, border Color) {
+	switch s {
+	case InfoBarWarning:
+		return RGB(0xFF, 0xF4, 0xCE), RGB(0xF0, 0xD4, 0x7A)
+
+	case InfoBarError:
+		return RGB(0xFD, 0xE7, 0xE9), RGB(0xEE, 0xA8, 0xAF)
+	}
+
+	return RGB(0xE5, 0xF1, 0xFB), RGB(0x99, 0xC9, 0xEF)
+}
+
+func (s InfoBarSeverity) icon() *Icon {
+	switch s {
+	case InfoBarWarning:
+		return IconWarning()
+
+	case InfoBarError:
+		return IconError()
+	}
+
+	return IconInformation()
+}
+
+// InfoBar is a strip displaying a non-blocking notification, usually at the
+// top of a container, as an alternative to a modal MsgBox. It shows an icon
+// and colors according to its severity, the message, optional actions and a
+// close button. Opening and closing slide the InfoBar in and out.
+//
+// An InfoBar is created closed, i.e. invisible.
+type InfoBar struct {
+	WidgetBase
+	composite                *Composite
+	messageLabel             *TextLabel
+	actions                  *Composite
+	closeButton              *infoBarCloseButton
+	severity                 InfoBarSeverity
+	backgroundBrush          *SolidColorBrush
+	open                     bool
+	animated                 bool
+	expansion                float64 // 0 when closed, 1 when open
+	animationFrom            float64
+	animationStart           time.Time
+	messageChangedPublisher  EventPublisher
+	severityChangedPublisher EventPublisher
+	openChangedPublisher     EventPublisher
+}
+
+// NewInfoBar creates a new closed InfoBar as child of parent.
+func NewInfoBar(parent Container) (*InfoBar, error) {
+	ib := &InfoBar{
+		animated: true,
+	}
+
+	if err := InitWidget(
+		ib,
+		parent,
+		infoBarWindowClass,
+		0,
+		win.WS_EX_CONTROLPARENT); err != nil {
+		return nil, err
+	}
+
+	succeeded := false
+	defer func() {
+		if !succeeded {
+			ib.Dispose()
+		}
+	}()
+
+	var err error
+
+	if ib.composite, err = NewComposite(ib); err != nil {
+		return nil, err
+	}
+	ib.composite.name = "composite"
+
+	// The layout of the parent takes the visibility of an InfoBar from its
+	// composite.
+	ib.composite.SetVisible(false)
+
+	layout := NewHBoxLayout()
+	layout.SetMargins(Margins{})
+	layout.SetSpacing(infoBarSpacing96dpi)
+	if err := ib.composite.SetLayout(layout); err != nil {
+		return nil, err
+	}
+
+	if ib.messageLabel, err = NewTextLabel(ib.composite); err != nil {
+		return nil, err
+	}
+	ib.messageLabel.SetAlignment(AlignHNearVCenter)
+
+	if ib.actions, err = NewComposite(ib.composite); err != nil {
+		return nil, err
+	}
+	ib.actions.name = "actions"
+	ib.actions.SetAlignment(AlignHFarVCenter)
+
+	actionsLayout := NewHBoxLayout()
+	actionsLayout.SetMargins(Margins{})
+	if err := ib.actions.SetLayout(actionsLayout); err != nil {
+		return nil, err
+	}
+
+	if ib.closeButton, err = newInfoBarCloseButton(ib); err != nil {
+		return nil, err
+	}
+	ib.closeButton.SetAlignment(AlignHFarVNear)
+
+	if err := ib.applySeverity(); err != nil {
+		return nil, err
+	}
+
+	ib.MustRegisterProperty("Message", NewProperty(
+		func() interface{} {
+			return ib.Message()
+		},
+		func(v interface{}) error {
+			return ib.SetMessage(assertStringOr(v, ""))
+		},
+		ib.messageChangedPublisher.Event()))
+
+	ib.MustRegisterProperty("Severity", NewProperty(
+		func() interface{} {
+			return ib.Severity()
+		},
+		func(v interface{}) error {
+			var severity InfoBarSeverity
+			if rv := reflect.ValueOf(v); rv.Kind() == reflect.Int {
+				severity = InfoBarSeverity(rv.Int())
+			}
+
+			return ib.SetSeverity(severity)
+		},
+		ib.severityChangedPublisher.Event()))
+
+	ib.MustRegisterProperty("Open", NewBoolProperty(
+		func() bool {
+			return ib.IsOpen()
+		},
+		func(v bool) error {
+			ib.SetOpen(v)
+			return nil
+		},
+		ib.openChangedPublisher.Event()))
+
+	succeeded = true
+
+	return ib, nil
+}
+
+func (ib *InfoBar) Dispose() {
+	ib.WidgetBase.Dispose()
+
+	if ib.backgroundBrush != nil {
+		ib.backgroundBrush.Dispose()
+		ib.backgroundBrush = nil
+	}
+}
+
+func (ib *InfoBar) AsContainerBase() *ContainerBase {
+	if ib.composite == nil {
+		return nil
+	}
+
+	return ib.composite.AsContainerBase()
+}
+
+func (ib *InfoBar) Children() *WidgetList {
+	if ib.composite == nil {
+		// Without this we would get into trouble in NewComposite.
+		return nil
+	}
+
+	return ib.composite.Children()
+}
+
+func (ib *InfoBar) Layout() Layout {
+	if ib.composite == nil {
+		return nil
+	}
+
+	return ib.composite.Layout()
+}
+
+func (ib *InfoBar) SetLayout(value Layout) error {
+	return newError("not supported")
+}
+
+func (ib *InfoBar) DataBinder() *DataBinder {
+	return ib.composite.dataBinder
+}
+
+func (ib *InfoBar) SetDataBinder(dataBinder *DataBinder) {
+	ib.composite.SetDataBinder(dataBinder)
+}
+
+// Actions returns the Composite between the message and the close button, for
+// buttons acting on the notification.
+func (ib *InfoBar) Actions() *Composite {
+	return ib.actions
+}
+
+// AddAction adds a PushButton with text to the actions, calling handler when
+// it is clicked.
+func (ib *InfoBar) AddAction(text string, handler func()) (*PushButton, error) {
+	pb, err := NewPushButton(ib.actions)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := pb.SetText(text); err != nil {
+		pb.Dispose()
+		return nil, err
+	}
+
+	if handler != nil {
+		pb.Clicked().Attach(handler)
+	}
+
+	return pb, nil
+}
+
+func (ib *InfoBar) applyEnabled(enabled bool) {
+	ib.WidgetBase.applyEnabled(enabled)
+
+	if ib.composite != nil {
+		ib.composite.applyEnabled(enabled)
+	}
+}
+
+func (ib *InfoBar) applyFont(font *Font) {
+	ib.WidgetBase.applyFont(font)
+
+	if ib.composite != nil {
+		ib.composite.applyFont(font)
+	}
+}
+
+func (ib *InfoBar) ApplyDPI(dpi int) {
+	ib.WidgetBase.ApplyDPI(dpi)
+
+	if ib.composite != nil {
+		ib.composite.ApplyDPI(dpi)
+	}
+}
+
+func (ib *InfoBar) SetSuspended(suspend bool) {
+	ib.composite.SetSuspended(suspend)
+	ib.WidgetBase.SetSuspended(suspend)
+	ib.Invalidate()
+}
+
+// Message returns the text of the notification.
+func (ib *InfoBar) Message() string {
+	return ib.messageLabel.Text()
+}
+
+// SetMessage sets the text of the notification.
+func (ib *InfoBar) SetMessage(message string) error {
+	if message == ib.Message() {
+		return nil
+	}
+
+	if err := ib.messageLabel.SetText(message); err != nil {
+		return err
+	}
+
+	ib.messageChangedPublisher.Publish()
+
+	return nil
+}
+
+// MessageChanged returns the event that is published when the message
+// changed.
+func (ib *InfoBar) MessageChanged() *Event {
+	return ib.messageChangedPublisher.Event()
+}
+
+// Severity returns the severity determining the colors and the icon.
+func (ib *InfoBar) Severity() InfoBarSeverity {
+	return ib.severity
+}
+
+// SetSeverity sets the severity determining the colors and the icon.
+func (ib *InfoBar) SetSeverity(severity InfoBarSeverity) error {
+	if severity == ib.severity {
+		return nil
+	}
+
+	ib.severity = severity
+
+	if err := ib.applySeverity(); err != nil {
+		return err
+	}
+
+	ib.severityChangedPublisher.Publish()
+
+	return nil
+}
+
+// SeverityChanged returns the event that is published when the severity
+// changed.
+func (ib *InfoBar) SeverityChanged() *Event {
+	return ib.severityChangedPublisher.Event()
+}
+
+func (ib *InfoBar) applySeverity() error {
+	background, _ := ib.severity.colors()
+
+	brush, err := NewSolidColorBrush(background)
+	if err != nil {
+		return err
+	}
+
+	ib.composite.SetBackground(brush)
+
+	if ib.backgroundBrush != nil {
+		ib.backgroundBrush.Dispose()
+	}
+	ib.backgroundBrush = brush
+
+	ib.Invalidate()
+	ib.composite.Invalidate()
+	ib.messageLabel.Invalidate()
+	ib.closeButton.Invalidate()
+
+	return nil
+}
+
+// CloseButtonVisible returns whether the close button is displayed.
+func (ib *InfoBar) CloseButtonVisible() bool {
+	return ib.closeButton.Visible()
+}
+
+// SetCloseButtonVisible sets whether the close button is displayed, e.g. to
+// hide it for notifications closed by the application only.
+func (ib *InfoBar) SetCloseButtonVisible(visible bool) {
+	ib.closeButton.SetVisible(visible)
+}
+
+// IsOpen returns whether the InfoBar is displayed.
+func (ib *InfoBar) IsOpen() bool {
+	return ib.open
+}
+
+// SetOpen displays or hides the InfoBar, animating the change if Animated.
+func (ib *InfoBar) SetOpen(open bool) {
+	if open == ib.open {
+		return
+	}
+
+	ib.open = open
+
+	if !open && win.IsChild(ib.hWnd, win.GetFocus()) {
+		win.SetFocus(ib.parent.Handle())
+	}
+
+	parentVisible := ib.parent != nil && win.IsWindowVisible(ib.parent.Handle())
+
+	if ib.animated && parentVisible {
+		ib.animationFrom = ib.expansion
+		ib.animationStart = time.Now()
+
+		ib.composite.SetVisible(true)
+		ib.SetVisible(true)
+
+		win.SetTimer(ib.hWnd, infoBarTimerId, infoBarTimerElapse, 0)
+	} else {
+		win.KillTimer(ib.hWnd, infoBarTimerId)
+
+		ib.setExpansion(ib.target())
+	}
+
+	ib.openChangedPublisher.Publish()
+}
+
+// OpenChanged returns the event that is published when the InfoBar was opened
+// or closed, including by the close button.
+func (ib *InfoBar) OpenChanged() *Event {
+	return ib.openChangedPublisher.Event()
+}
+
+// Show sets the severity and the message and opens the InfoBar.
+func (ib *InfoBar) Show(severity InfoBarSeverity, message string) error {
+	if err := ib.SetSeverity(severity); err != nil {
+		return err
+	}
+
+	if err := ib.SetMessage(message); err != nil {
+		return err
+	}
+
+	ib.SetOpen(true)
+
+	return nil
+}
+
+// Close closes the InfoBar.
+func (ib *InfoBar) Close() {
+	ib.SetOpen(false)
+}
+
+// Animated returns whether opening and closing is animated.
+func (ib *InfoBar) Animated() bool {
+	return ib.animated
+}
+
+// SetAnimated sets whether opening and closing is animated.
+func (ib *InfoBar) SetAnimated(animated bool) {
+	ib.animated = animated
+}
+
+func (ib *InfoBar) target() float64 {
+	if ib.open {
+		return 1
+	}
+
+	return 0
+}
+
+func (ib *InfoBar) setExpansion(expansion float64) {
+	ib.expansion = expansion
+
+	ib.composite.SetVisible(expansion > 0)
+	ib.SetVisible(expansion > 0)
+
+	ib.RequestLayout()
+}
+
+func (ib *InfoBar) animate() {
+	progress := float64(time.Since(ib.animationStart).Milliseconds()) / infoBarAnimationDuration
+	if progress >= 1 {
+		progress = 1
+
+		win.KillTimer(ib.hWnd, infoBarTimerId)
+	}
+
+	// Ease out, slowing down towards the end.
+	progress = 1 - (1-progress)*(1-progress)
+
+	ib.setExpansion(ib.animationFrom + (ib.target()-ib.animationFrom)*progress)
+}
+
+func (ib *InfoBar) paint(canvas *Canvas, bounds Rectangle) error {
+	bp, err := BeginBufferedPaint(canvas, bounds, win.BPBF_COMPATIBLEBITMAP)
+	if err != nil {
+		return err
+	}
+	defer bp.End()
+
+	buffered, err := bp.Canvas()
+	if err != nil {
+		return err
+	}
+	defer buffered.Dispose()
+
+	background, border := ib.severity.colors()
+
+	borderBrush, err := NewSolidColorBrush(border)
+	if err != nil {
+		return err
+	}
+	defer borderBrush.Dispose()
+
+	backgroundBrush, err := NewSolidColorBrush(background)
+	if err != nil {
+		return err
+	}
+	defer backgroundBrush.Dispose()
+
+	cb := ib.ClientBoundsPixels()
+	if err := buffered.FillRectanglePixels(borderBrush, cb); err != nil {
+		return err
+	}
+
+	one := ib.IntFrom96DPI(1)
+	if err := buffered.FillRectanglePixels(backgroundBrush, Rectangle{cb.X + one, cb.Y + one, cb.Width - 2*one, cb.Height - 2*one}); err != nil {
+		return err
+	}
+
+	// The icon is aligned with the first line of the message, like the close
+	// button.
+	padding := ib.IntFrom96DPI(infoBarPadding96dpi)
+	iconSize := ib.IntFrom96DPI(infoBarIconSize96dpi)
+	y := cb.Y + padding + (ib.IntFrom96DPI(infoBarCloseButtonSize96dpi)-iconSize)/2
+	if ib.composite != nil {
+		y = ib.composite.BoundsPixels().Y + (ib.IntFrom96DPI(infoBarCloseButtonSize96dpi)-iconSize)/2
+	}
+
+	return buffered.DrawImageStretchedPixels(ib.severity.icon(), Rectangle{cb.X + padding, y, iconSize, iconSize})
+}
+
+func (ib *InfoBar) WndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) uintptr {
+	switch msg {
+	case win.WM_PAINT:
+		var ps win.PAINTSTRUCT
+
+		hdc := win.BeginPaint(hwnd, &ps)
+		defer win.EndPaint(hwnd, &ps)
+
+		canvas, err := newCanvasFromHDC(hdc)
+		if err != nil {
+			return 0
+		}
+		defer canvas.Dispose()
+
+		ib.paint(canvas, ib.ClientBoundsPixels())
+
+		return 0
+
+	case win.WM_ERASEBKGND:
+		// Everything is drawn in WM_PAINT.
+		return 1
+
+	case win.WM_SIZE:
+		ib.Invalidate()
+
+	case win.WM_TIMER:
+		if wParam == infoBarTimerId {
+			ib.animate()
+			return 0
+		}
+	}
+
+	return ib.WidgetBase.WndProc(hwnd, msg, wParam, lParam)
+}
+
+func (ib *InfoBar) CreateLayoutItem(ctx *LayoutContext) LayoutItem {
+	li := &infoBarLayoutItem{
+		expansion: ib.expansion,
+		padding:   IntFrom96DPI(infoBarPadding96dpi, ctx.dpi),
+		indent:    IntFrom96DPI(infoBarPadding96dpi+infoBarIconSize96dpi+infoBarSpacing96dpi, ctx.dpi),
+	}
+
+	contentItem := CreateLayoutItemsForContainerWithContext(ib.composite, ctx)
+	contentItem.AsLayoutItemBase().parent = li
+	li.children = append(li.children, contentItem)
+
+	return li
+}
+
+type infoBarLayoutItem struct {
+	ContainerLayoutItemBase
+	expansion float64 // 0 when closed, 1 when open
+	padding   int     // in native pixels
+	indent    int     // left of the content, in native pixels
+}
+
+func (li *infoBarLayoutItem) content() LayoutItem {
+	return li.children[0]
+}
+
+// height returns the part of the height of the InfoBar that is displayed.
+func (li *infoBarLayoutItem) height(contentHeight int) int {
+	return int(math.Round(float64(contentHeight+2*li.padding) * li.expansion))
+}
+
+func (li *infoBarLayoutItem) LayoutFlags() LayoutFlags {
+	return GrowableHorz | ShrinkableHorz
+}
+
+func (li *infoBarLayoutItem) MinSize() Size {
+	content := li.content().(MinSizer).MinSize()
+
+	return Size{
+		Width:  li.indent + content.Width + li.padding,
+		Height: li.height(content.Height),
+	}
+}
+
+func (li *infoBarLayoutItem) MinSizeForSize(size Size) Size {
+	return li.MinSize()
+}
+
+func (li *infoBarLayoutItem) IdealSize() Size {
+	content := li.content().(IdealSizer).IdealSize()
+
+	return Size{
+		Width:  li.indent + content.Width + li.padding,
+		Height: li.height(content.Height),
+	}
+}
+
+func (li *infoBarLayoutItem) HasHeightForWidth() bool {
+	hfw, ok := li.content().(HeightForWidther)
+	return ok && hfw.HasHeightForWidth()
+}
+
+func (li *infoBarLayoutItem) contentHeightForWidth(width int) int {
+	if li.HasHeightForWidth() {
+		return li.content().(HeightForWidther).HeightForWidth(width - li.indent - li.padding)
+	}
+
+	return li.content().(IdealSizer).IdealSize().Height
+}
+
+func (li *infoBarLayoutItem) HeightForWidth(width int) int {
+	return li.height(li.contentHeightForWidth(width))
+}
+
+func (li *infoBarLayoutItem) PerformLayout() []LayoutResultItem {
+	size := li.geometry.ClientSize
+
+	// While animating, the content keeps its full height and slides in from
+	// the top.
+	contentHeight := li.contentHeightForWidth(size.Width)
+	y := size.Height - contentHeight - li.padding
+
+	return []LayoutResultItem{
+		{
+			Item:   li.content(),
+			Bounds: Rectangle{X: li.indent, Y: y, Width: size.Width - li.indent - li.padding, Height: contentHeight},
+		},
+	}
+}
+
+// infoBarCloseButton is the button closing an InfoBar.
+type infoBarCloseButton struct {
+	WidgetBase
+	infoBar *InfoBar
+	hot     bool
+	pressed bool
+}
+
+func newInfoBarCloseButton(infoBar *InfoBar) (*infoBarCloseButton, error) {
+	b := &infoBarCloseButton{infoBar: infoBar}
+
+	if err := InitWidget(
+		b,
+		infoBar.composite,
+		infoBarCloseButtonWindowClass,
+		win.WS_TABSTOP|win.WS_VISIBLE,
+		0); err != nil {
+		return nil, err
+	}
+
+	b.SetBackground(NullBrush())
+
+	if err := b.SetToolTipText(tr("Close", "walk")); err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+func (b *infoBarCloseButton) setHot(hot bool) {
+	if hot == b.hot {
+		return
+	}
+
+	b.hot = hot
+	b.Invalidate()
+
+	if hot {
+		var tme win.TRACKMOUSEEVENT
+		tme.CbSize = uint32(unsafe.Sizeof(tme))
+		tme.DwFlags = win.TME_LEAVE
+		tme.HwndTrack = b.hWnd
+
+		win.TrackMouseEvent(&tme)
+	}
+}
+
+func (b *infoBarCloseButton) paint(canvas *Canvas, bounds Rectangle) error {
+	bp, err := BeginBufferedPaint(canvas, bounds, win.BPBF_COMPATIBLEBITMAP)
+	if err != nil {
+		return err
+	}
+	defer bp.End()
+
+	buffered, err := bp.Canvas()
+	if err != nil {
+		return err
+	}
+	defer buffered.Dispose()
+
+	background, border := b.infoBar.severity.colors()
+	if b.hot || b.pressed {
+		background = border
+	}
+
+	backgroundBrush, err := NewSolidColorBrush(background)
+	if err != nil {
+		return err
+	}
+	defer backgroundBrush.Dispose()
+
+	cb := b.ClientBoundsPixels()
+	if err := buffered.FillRectanglePixels(backgroundBrush, cb); err != nil {
+		return err
+	}
+
+	color := Color(win.GetSysColor(win.COLOR_BTNTEXT))
+	if !b.Enabled() {
+		color = Color(win.GetSysColor(win.COLOR_GRAYTEXT))
+	}
+
+	brush, err := NewSolidColorBrush(color)
+	if err != nil {
+		return err
+	}
+	defer brush.Dispose()
+
+	pen, err := NewGeometricPen(PenSolid|PenCapRound, b.IntFrom96DPI(1), brush)
+	if err != nil {
+		return err
+	}
+	defer pen.Dispose()
+
+	half := b.IntFrom96DPI(infoBarCloseGlyphSize96dpi) / 2
+	cx, cy := cb.X+cb.Width/2, cb.Y+cb.Height/2
+	if err := buffered.DrawLinePixels(pen, Point{cx - half, cy - half}, Point{cx + half, cy + half}); err != nil {
+		return err
+	}
+	if err := buffered.DrawLinePixels(pen, Point{cx + half, cy - half}, Point{cx - half, cy + half}); err != nil {
+		return err
+	}
+
+	if b.Focused() && b.FocusCuesVisible() {
+		rc := cb.toRECT()
+		win.DrawFocusRect(buffered.HDC(), &rc)
+	}
+
+	return nil
+}
+
+func (b *infoBarCloseButton) WndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) uintptr {
+	switch msg {
+	case win.WM_PAINT:
+		var ps win.PAINTSTRUCT
+
+		hdc := win.BeginPaint(hwnd, &ps)
+		defer win.EndPaint(hwnd, &ps)
+
+		canvas, err := newCanvasFromHDC(hdc)
+		if err != nil {
+			return 0
+		}
+		defer canvas.Dispose()
+
+		b.paint(canvas, b.ClientBoundsPixels())
+
+		return 0
+
+	case win.WM_ERASEBKGND:
+		// Everything is drawn in WM_PAINT.
+		return 1
+
+	case win.WM_MOUSEMOVE:
+		b.setHot(true)
+
+	case win.WM_MOUSELEAVE:
+		b.setHot(false)
+
+	case win.WM_LBUTTONDOWN:
+		b.pressed = true
+		win.SetCapture(hwnd)
+		b.Invalidate()
+
+	case win.WM_LBUTTONUP:
+		if b.pressed {
+			b.pressed = false
+			win.ReleaseCapture()
+			b.Invalidate()
+
+			p := Point{int(win.GET_X_LPARAM(lParam)), int(win.GET_Y_LPARAM(lParam))}
+			cb := b.ClientBoundsPixels()
+			if p.X >= 0 && p.Y >= 0 && p.X < cb.Width && p.Y < cb.Height {
+				b.infoBar.Close()
+			}
+		}
+
+	case win.WM_CAPTURECHANGED:
+		if b.pressed {
+			b.pressed = false
+			b.Invalidate()
+		}
+
+	case win.WM_KEYDOWN:
+		if Key(wParam) == KeySpace {
+			b.infoBar.Close()
+		}
+
+	case win.WM_SETFOCUS, win.WM_KILLFOCUS, win.WM_SIZE:
+		b.Invalidate()
+	}
+
+	return b.WidgetBase.WndProc(hwnd, msg, wParam, lParam)
+}
+
+func (b *infoBarCloseButton) CreateLayoutItem(ctx *LayoutContext) LayoutItem {
+	size := IntFrom96DPI(infoBarCloseButtonSize96dpi, ctx.dpi)
+
+	return &infoBarCloseButtonLayoutItem{
+		size: Size{size, size},
+	}
+}
+
+type infoBarCloseButtonLayoutItem struct {
+	LayoutItemBase
+	size Size // in native pixels
+}
+
+func (*infoBarCloseButtonLayoutItem) LayoutFlags() LayoutFlags {
+	return 0
+}
+
+func (li *infoBarCloseButtonLayoutItem) IdealSize() Size {
+	return li.size
+}
+
+func (li *infoBarCloseButtonLayoutItem) MinSize() Size {
+	return li.size
+}
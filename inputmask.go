@@ -0,0 +1,312 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"errors"
+	"unicode"
+)
+
+// InputMaskChar defines a mask character of an InputMask, which stands for a
+// position the user fills in.
+type InputMaskChar struct {
+	// Accept reports whether r may be entered at the position.
+	Accept func(r rune) bool
+
+	// Optional makes the text complete without the position being filled.
+	Optional bool
+}
+
+func isAnyRune(rune) bool {
+	return true
+}
+
+func isLetterOrDigit(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// inputMaskChars are the mask characters every InputMask understands.
+var inputMaskChars = map[rune]InputMaskChar{
+	'0': {unicode.IsDigit, false},
+	'_': {unicode.IsDigit, false},
+	'9': {unicode.IsDigit, true},
+	'L': {unicode.IsLetter, false},
+	'?': {unicode.IsLetter, true},
+	'A': {isLetterOrDigit, false},
+	'a': {isLetterOrDigit, true},
+	'&': {isAnyRune, false},
+	'C': {isAnyRune, true},
+}
+
+// inputMaskSlot is a position of an InputMask, either a literal or one the
+// user fills in.
+type inputMaskSlot struct {
+	literal rune
+	char    *InputMaskChar
+}
+
+// InputMask restricts the text of a LineEdit to a fixed pattern, like
+// "000.000.000.000" or "(___) ___-____".
+//
+// The following mask characters stand for the positions the user fills in:
+//
+//	0, _  a digit
+//	9     a digit, optional
+//	L     a letter
+//	?     a letter, optional
+//	A     a letter or digit
+//	a     a letter or digit, optional
+//	&     any character
+//	C     any character, optional
+//
+// Any other character of the pattern is a literal, which is displayed as is
+// and skipped while typing. A backslash makes the following character a
+// literal. Unfilled positions are displayed as the placeholder, '_' by
+// default.
+//
+// InputMask implements Validator, rejecting incomplete texts.
+type InputMask struct {
+	pattern     string
+	placeholder rune
+	slots       []inputMaskSlot
+}
+
+// NewInputMask returns a new InputMask for pattern. chars defines additional
+// mask characters, which may also replace the predefined ones.
+func NewInputMask(pattern string, chars map[rune]InputMaskChar) (*InputMask, error) {
+	m := &InputMask{pattern: pattern, placeholder: '_'}
+
+	runes := []rune(pattern)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+
+		if r == '\\' {
+			i++
+			if i == len(runes) {
+				return nil, newError("pattern ends with an escape")
+			}
+
+			m.slots = append(m.slots, inputMaskSlot{literal: runes[i]})
+			continue
+		}
+
+		char, ok := chars[r]
+		if !ok {
+			char, ok = inputMaskChars[r]
+		}
+		if !ok {
+			m.slots = append(m.slots, inputMaskSlot{literal: r})
+			continue
+		}
+		if char.Accept == nil {
+			return nil, newError("mask character without Accept")
+		}
+
+		m.slots = append(m.slots, inputMaskSlot{char: &char})
+	}
+
+	if len(m.slots) == 0 {
+		return nil, newError("empty pattern")
+	}
+
+	return m, nil
+}
+
+// Pattern returns the pattern of the mask.
+func (m *InputMask) Pattern() string {
+	return m.pattern
+}
+
+// Placeholder returns the character unfilled positions are displayed as.
+func (m *InputMask) Placeholder() rune {
+	return m.placeholder
+}
+
+// SetPlaceholder sets the character unfilled positions are displayed as. It
+// must be set before the mask is assigned to a LineEdit.
+func (m *InputMask) SetPlaceholder(placeholder rune) {
+	m.placeholder = placeholder
+}
+
+// Format returns text with the literals of the mask, and the characters of
+// value in the positions the user fills in. value may be formatted already,
+// or contain only the characters to fill in. Characters the positions do not
+// accept are dropped.
+func (m *InputMask) Format(value string) string {
+	text := m.empty()
+	m.insert(text, 0, []rune(value))
+
+	return string(text)
+}
+
+// Raw returns the characters filled in text, which is formatted with the
+// mask, without the literals and placeholders.
+func (m *InputMask) Raw(text string) string {
+	runes := []rune(text)
+
+	var raw []rune
+	for i, slot := range m.slots {
+		if slot.char != nil && i < len(runes) && runes[i] != m.placeholder {
+			raw = append(raw, runes[i])
+		}
+	}
+
+	return string(raw)
+}
+
+// Complete reports whether all positions of text that are not optional are
+// filled.
+func (m *InputMask) Complete(text string) bool {
+	runes := []rune(text)
+	if len(runes) != len(m.slots) {
+		return false
+	}
+
+	for i, slot := range m.slots {
+		switch {
+		case slot.char == nil:
+			if runes[i] != slot.literal {
+				return false
+			}
+
+		case runes[i] == m.placeholder:
+			if !slot.char.Optional {
+				return false
+			}
+
+		case !slot.char.Accept(runes[i]):
+			return false
+		}
+	}
+
+	return true
+}
+
+func (m *InputMask) Validate(v interface{}) error {
+	if s, ok := v.(string); ok && m.Complete(s) {
+		return nil
+	}
+
+	return errors.New(tr("The text is incomplete.", "walk"))
+}
+
+// empty returns the text with no position filled.
+func (m *InputMask) empty() []rune {
+	text := make([]rune, len(m.slots))
+	for i, slot := range m.slots {
+		if slot.char == nil {
+			text[i] = slot.literal
+		} else {
+			text[i] = m.placeholder
+		}
+	}
+
+	return text
+}
+
+// normalize returns text, which may be out of shape after an edit the mask
+// did not handle, formatted.
+func (m *InputMask) normalize(text []rune) []rune {
+	if len(text) == len(m.slots) {
+		return text
+	}
+
+	return []rune(m.Format(string(text)))
+}
+
+// clear empties the positions of text from start to end.
+func (m *InputMask) clear(text []rune, start, end int) {
+	for i := start; i < end && i < len(m.slots); i++ {
+		if m.slots[i].char != nil {
+			text[i] = m.placeholder
+		}
+	}
+}
+
+// insert fills in input at the positions of text from pos onwards and returns
+// the position after it. Literals in input that match those of the mask are
+// skipped over.
+func (m *InputMask) insert(text []rune, pos int, input []rune) int {
+	for _, r := range input {
+		next := pos
+		for next < len(m.slots) && m.slots[next].char == nil && m.slots[next].literal != r {
+			next++
+		}
+		if next == len(m.slots) {
+			continue
+		}
+
+		if m.slots[next].char == nil {
+			pos = next + 1
+			continue
+		}
+
+		if r == m.placeholder || m.slots[next].char.Accept(r) {
+			text[next] = r
+			pos = next + 1
+		}
+	}
+
+	return m.skipLiterals(pos)
+}
+
+// skipLiterals returns the first position at or after pos that the user fills
+// in, or the end of the text.
+func (m *InputMask) skipLiterals(pos int) int {
+	for pos < len(m.slots) && m.slots[pos].char == nil {
+		pos++
+	}
+
+	return pos
+}
+
+// runeIndex converts offset, which is in UTF-16 code units like the positions
+// of edit controls, to an index into text.
+func runeIndex(text []rune, offset int) int {
+	var u int
+	for i, r := range text {
+		if u >= offset {
+			return i
+		}
+
+		if r >= 0x10000 {
+			u += 2
+		} else {
+			u++
+		}
+	}
+
+	return len(text)
+}
+
+// utf16Offset converts index, which is an index into text, to an offset in
+// UTF-16 code units.
+func utf16Offset(text []rune, index int) int {
+	var u int
+	for _, r := range text[:min(index, len(text))] {
+		if r >= 0x10000 {
+			u += 2
+		} else {
+			u++
+		}
+	}
+
+	return u
+}
+
+// previousSlot returns the last position before pos that the user fills in,
+// or -1.
+func (m *InputMask) previousSlot(pos int) int {
+	for pos--; pos >= 0; pos-- {
+		if m.slots[pos].char != nil {
+			return pos
+		}
+	}
+
+	return -1
+}
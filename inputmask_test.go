@@ -0,0 +1,178 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"testing"
+)
+
+func TestNewInputMask(t *testing.T) {
+	testCases := []struct {
+		pattern string
+		chars   map[rune]InputMaskChar
+		wantErr bool
+		want    string // empty text of the mask
+	}{
+		{pattern: "000.000", want: "___.___"},
+		{pattern: `\0-0`, want: "0-_"},
+		{pattern: "XX", chars: map[rune]InputMaskChar{'X': {Accept: isAnyRune}}, want: "__"},
+		{pattern: "", wantErr: true},
+		{pattern: `00\`, wantErr: true},
+		{pattern: "X", chars: map[rune]InputMaskChar{'X': {}}, wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		m, err := NewInputMask(tc.pattern, tc.chars)
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("NewInputMask(%q): got no error", tc.pattern)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("NewInputMask(%q): %v", tc.pattern, err)
+			continue
+		}
+
+		if got := string(m.empty()); got != tc.want {
+			t.Errorf("NewInputMask(%q): got empty text %q, want %q", tc.pattern, got, tc.want)
+		}
+	}
+}
+
+func TestInputMaskFormat(t *testing.T) {
+	testCases := []struct {
+		pattern string
+		value   string
+		want    string
+	}{
+		{"(___) ___-____", "5551234567", "(555) 123-4567"},
+		{"(___) ___-____", "(555) 123-4567", "(555) 123-4567"},
+		{"(___) ___-____", "55", "(55_) ___-____"},
+		{"(___) ___-____", "abc123", "(123) ___-____"},
+		{"LL-99", "ab-1", "ab-1_"},
+		{"LL-99", "", "__-__"},
+	}
+
+	for _, tc := range testCases {
+		m, err := NewInputMask(tc.pattern, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := m.Format(tc.value); got != tc.want {
+			t.Errorf("%q: Format(%q) = %q, want %q", tc.pattern, tc.value, got, tc.want)
+		}
+	}
+}
+
+func TestInputMaskRaw(t *testing.T) {
+	testCases := []struct {
+		pattern string
+		text    string
+		want    string
+	}{
+		{"(___) ___-____", "(555) 123-4567", "5551234567"},
+		{"(___) ___-____", "(555) 12_-____", "55512"},
+		{"(___) ___-____", "(55", "55"},
+		{"LL-99", "__-__", ""},
+	}
+
+	for _, tc := range testCases {
+		m, err := NewInputMask(tc.pattern, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := m.Raw(tc.text); got != tc.want {
+			t.Errorf("%q: Raw(%q) = %q, want %q", tc.pattern, tc.text, got, tc.want)
+		}
+	}
+}
+
+func TestInputMaskComplete(t *testing.T) {
+	testCases := []struct {
+		pattern string
+		text    string
+		want    bool
+	}{
+		{"(___) ___-____", "(555) 123-4567", true},
+		{"(___) ___-____", "(555) 123-456_", false},
+		{"LL-99", "ab-1_", true},
+		{"LL-99", "ab-__", true},
+		{"LL-99", "a_-12", false},
+		{"LL-99", "ab-1", false},
+		{"LL-99", "a1-12", false},
+		{"LL-99", "ab+12", false},
+	}
+
+	for _, tc := range testCases {
+		m, err := NewInputMask(tc.pattern, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := m.Complete(tc.text); got != tc.want {
+			t.Errorf("%q: Complete(%q) = %t, want %t", tc.pattern, tc.text, got, tc.want)
+		}
+	}
+}
+
+func TestInputMaskInsert(t *testing.T) {
+	testCases := []struct {
+		text     string
+		pos      int
+		input    string
+		wantText string
+		wantPos  int
+	}{
+		{"__:__", 0, "12", "12:__", 3},
+		{"__:__", 3, "3", "__:3_", 4},
+		{"__:__", 1, "1:", "_1:__", 3},
+		{"__:__", 4, "56", "__:_5", 5},
+		{"12:34", 1, "x9", "19:34", 3},
+	}
+
+	m, err := NewInputMask("00:00", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range testCases {
+		text := []rune(tc.text)
+
+		pos := m.insert(text, tc.pos, []rune(tc.input))
+
+		if string(text) != tc.wantText || pos != tc.wantPos {
+			t.Errorf("insert(%q, %d, %q) = %q, %d, want %q, %d", tc.text, tc.pos, tc.input, string(text), pos, tc.wantText, tc.wantPos)
+		}
+	}
+}
+
+func TestInputMaskOffsets(t *testing.T) {
+	text := []rune("a\U0001F600b")
+
+	testCases := []struct {
+		offset int // in UTF-16 code units
+		index  int
+	}{
+		{0, 0},
+		{1, 1},
+		{3, 2},
+		{4, 3},
+	}
+
+	for _, tc := range testCases {
+		if got := runeIndex(text, tc.offset); got != tc.index {
+			t.Errorf("runeIndex(%d) = %d, want %d", tc.offset, got, tc.index)
+		}
+		if got := utf16Offset(text, tc.index); got != tc.offset {
+			t.Errorf("utf16Offset(%d) = %d, want %d", tc.index, got, tc.offset)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"net/netip"
+	"unsafe"
+
+	"github.com/tailscale/win"
+)
+
+// IPAddressEdit is a widget for entering an IPv4 address, using the
+// SysIPAddress32 common control. For IPv6 addresses, prefixes and ports, see
+// NetworkAddressEdit.
+type IPAddressEdit struct {
+	WidgetBase
+	addressChangedPublisher EventPublisher
+}
+
+// NewIPAddressEdit returns a new IPAddressEdit widget as child of parent.
+func NewIPAddressEdit(parent Container) (*IPAddressEdit, error) {
+	ipe := new(IPAddressEdit)
+
+	if err := InitWidget(
+		ipe,
+		parent,
+		"SysIPAddress32",
+		win.WS_TABSTOP|win.WS_VISIBLE,
+		0); err != nil {
+		return nil, err
+	}
+
+	ipe.GraphicsEffects().Add(InteractionEffect)
+	ipe.GraphicsEffects().Add(FocusEffect)
+
+	ipe.MustRegisterProperty("Address", NewProperty(
+		func() interface{} {
+			return ipe.Address()
+		},
+		func(v interface{}) error {
+			addr, _ := v.(netip.Addr)
+			return ipe.SetAddress(addr)
+		},
+		ipe.addressChangedPublisher.Event()))
+
+	return ipe, nil
+}
+
+// Address returns the address entered by the user. If any of the four fields
+// is blank, the zero Addr is returned.
+func (ipe *IPAddressEdit) Address() netip.Addr {
+	var packed uint32
+	if 4 != ipe.SendMessage(_IPM_GETADDRESS, 0, uintptr(unsafe.Pointer(&packed))) {
+		return netip.Addr{}
+	}
+
+	return netip.AddrFrom4([4]byte{byte(packed >> 24), byte(packed >> 16), byte(packed >> 8), byte(packed)})
+}
+
+// SetAddress sets the address displayed by the control. The zero Addr clears
+// all fields. IPv4-mapped IPv6 addresses are accepted, other IPv6 addresses
+// are not.
+func (ipe *IPAddressEdit) SetAddress(addr netip.Addr) error {
+	if !addr.IsValid() {
+		ipe.Clear()
+		return nil
+	}
+
+	addr = addr.Unmap()
+	if !addr.Is4() {
+		return newError("IPAddressEdit supports IPv4 addresses only")
+	}
+
+	if addr == ipe.Address() {
+		return nil
+	}
+
+	b := addr.As4()
+	packed := uint32(b[0])<<24 | uint32(b[1])<<16 | uint32(b[2])<<8 | uint32(b[3])
+	ipe.SendMessage(_IPM_SETADDRESS, 0, uintptr(packed))
+
+	ipe.addressChangedPublisher.Publish()
+
+	return nil
+}
+
+// Clear clears all fields of the control.
+func (ipe *IPAddressEdit) Clear() {
+	ipe.SendMessage(_IPM_CLEARADDRESS, 0, 0)
+
+	ipe.addressChangedPublisher.Publish()
+}
+
+// IsBlank returns whether all fields of the control are blank.
+func (ipe *IPAddressEdit) IsBlank() bool {
+	return ipe.SendMessage(_IPM_ISBLANK, 0, 0) != 0
+}
+
+// SetFieldRange restricts the values the user can enter into the field with
+// index field (0-3) to the range from min to max.
+func (ipe *IPAddressEdit) SetFieldRange(field int, min, max byte) error {
+	if field < 0 || field > 3 {
+		return newError("field must be in the range 0-3")
+	}
+	if min > max {
+		return newError("min must not be greater than max")
+	}
+
+	if 0 == ipe.SendMessage(_IPM_SETRANGE, uintptr(field), uintptr(max)<<8|uintptr(min)) {
+		return newError("SendMessage(IPM_SETRANGE)")
+	}
+
+	return nil
+}
+
+// FocusField moves the keyboard focus to the field with index field (0-3) and
+// selects its text.
+func (ipe *IPAddressEdit) FocusField(field int) {
+	ipe.SendMessage(_IPM_SETFOCUS, uintptr(field), 0)
+}
+
+// AddressChanged returns the event that is published when the address changes.
+func (ipe *IPAddressEdit) AddressChanged() *Event {
+	return ipe.addressChangedPublisher.Event()
+}
+
+func (*IPAddressEdit) NeedsWmSize() bool {
+	return true
+}
+
+func (ipe *IPAddressEdit) WndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) uintptr {
+	switch msg {
+	case win.WM_COMMAND:
+		switch win.HIWORD(uint32(wParam)) {
+		case win.EN_CHANGE:
+			ipe.addressChangedPublisher.Publish()
+		}
+	}
+
+	return ipe.WidgetBase.WndProc(hwnd, msg, wParam, lParam)
+}
+
+func (ipe *IPAddressEdit) CreateLayoutItem(ctx *LayoutContext) LayoutItem {
+	size := ipe.dialogBaseUnitsToPixels(Size{0, 12})
+	size.Width = ipe.calculateTextSizeImpl("000.000.000.000").Width + ipe.IntFrom96DPI(12)
+
+	return &ipAddressEditLayoutItem{
+		idealSize: size,
+	}
+}
+
+type ipAddressEditLayoutItem struct {
+	LayoutItemBase
+	idealSize Size // in native pixels
+}
+
+func (*ipAddressEditLayoutItem) LayoutFlags() LayoutFlags {
+	return GrowableHorz
+}
+
+func (li *ipAddressEditLayoutItem) IdealSize() Size {
+	return li.idealSize
+}
+
+func (li *ipAddressEditLayoutItem) MinSize() Size {
+	return li.idealSize
+}
@@ -0,0 +1,189 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"sort"
+	"unicode"
+)
+
+// FilterMatch is an item matching the text typed into a FilterComboBox.
+type FilterMatch struct {
+	// Index is the index of the item in the items of the FilterComboBox.
+	Index int
+
+	// Highlights are the ranges of the item text that matched, as pairs of
+	// start and end byte offsets.
+	Highlights [][2]int
+}
+
+// ItemFilter selects the items of a FilterComboBox matching the text typed
+// into it.
+type ItemFilter interface {
+	// SetItems is called with the items of the FilterComboBox whenever they
+	// change, so they can be indexed ahead of filtering.
+	SetItems(items []string)
+
+	// Filter returns the matches for text, which is not empty, in the order
+	// they are to be listed.
+	Filter(text string) []FilterMatch
+}
+
+// foldedItem is an item converted to lower case rune by rune, along with the
+// byte offsets of the runes in the item.
+type foldedItem struct {
+	runes   []rune
+	offsets []int // one more than runes, ending with the length of the item
+}
+
+func foldItem(item string) foldedItem {
+	var fi foldedItem
+	for offset, r := range item {
+		fi.runes = append(fi.runes, unicode.ToLower(r))
+		fi.offsets = append(fi.offsets, offset)
+	}
+	fi.offsets = append(fi.offsets, len(item))
+
+	return fi
+}
+
+func foldText(text string) []rune {
+	runes := []rune(text)
+	for i, r := range runes {
+		runes[i] = unicode.ToLower(r)
+	}
+
+	return runes
+}
+
+type prefixItemFilter struct {
+	items []foldedItem
+}
+
+// NewPrefixItemFilter returns an ItemFilter matching the items starting with
+// the text, ignoring case.
+func NewPrefixItemFilter() ItemFilter {
+	return new(prefixItemFilter)
+}
+
+func (f *prefixItemFilter) SetItems(items []string) {
+	f.items = make([]foldedItem, len(items))
+	for i, item := range items {
+		f.items[i] = foldItem(item)
+	}
+}
+
+func (f *prefixItemFilter) Filter(text string) []FilterMatch {
+	query := foldText(text)
+
+	var matches []FilterMatch
+	for i, item := range f.items {
+		if len(item.runes) < len(query) || string(item.runes[:len(query)]) != string(query) {
+			continue
+		}
+
+		matches = append(matches, FilterMatch{
+			Index:      i,
+			Highlights: [][2]int{{0, item.offsets[len(query)]}},
+		})
+	}
+
+	return matches
+}
+
+type fuzzyItemFilter struct {
+	items []foldedItem
+}
+
+// NewFuzzyItemFilter returns an ItemFilter matching the items containing the
+// characters of the text in order, ignoring case. The items are listed by
+// how well they match, favoring consecutive characters and those starting
+// words.
+func NewFuzzyItemFilter() ItemFilter {
+	return new(fuzzyItemFilter)
+}
+
+func (f *fuzzyItemFilter) SetItems(items []string) {
+	f.items = make([]foldedItem, len(items))
+	for i, item := range items {
+		f.items[i] = foldItem(item)
+	}
+}
+
+func (f *fuzzyItemFilter) Filter(text string) []FilterMatch {
+	query := foldText(text)
+
+	type scoredMatch struct {
+		FilterMatch
+		score int
+	}
+
+	var scored []scoredMatch
+	for i, item := range f.items {
+		score, positions, ok := fuzzyMatch(item.runes, query)
+		if !ok {
+			continue
+		}
+
+		var highlights [][2]int
+		for j, pos := range positions {
+			start, end := item.offsets[pos], item.offsets[pos+1]
+			if j > 0 && positions[j-1] == pos-1 {
+				highlights[len(highlights)-1][1] = end
+			} else {
+				highlights = append(highlights, [2]int{start, end})
+			}
+		}
+
+		scored = append(scored, scoredMatch{FilterMatch{i, highlights}, score})
+	}
+
+	sort.SliceStable(scored, func(i, j int) bool {
+		return scored[i].score > scored[j].score
+	})
+
+	matches := make([]FilterMatch, len(scored))
+	for i, sm := range scored {
+		matches[i] = sm.FilterMatch
+	}
+
+	return matches
+}
+
+// fuzzyMatch finds the runes of query in item in order and returns the
+// positions they were found at, along with a score of the match.
+func fuzzyMatch(item, query []rune) (score int, positions []int, ok bool) {
+	positions = make([]int, 0, len(query))
+
+	pos := 0
+	for _, q := range query {
+		for pos < len(item) && item[pos] != q {
+			pos++
+		}
+		if pos == len(item) {
+			return 0, nil, false
+		}
+
+		score++
+		switch {
+		case len(positions) > 0 && positions[len(positions)-1] == pos-1:
+			score += 3
+
+		case pos == 0 || !unicode.IsLetter(item[pos-1]) && !unicode.IsDigit(item[pos-1]):
+			score += 2
+		}
+
+		positions = append(positions, pos)
+		pos++
+	}
+
+	// Matches spread over less of the item rank higher.
+	score -= (positions[len(positions)-1] - positions[0]) / 4
+
+	return score, positions, true
+}
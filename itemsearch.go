@@ -0,0 +1,89 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+// itemSearchTimeout is how long after the last key press typing starts a new
+// search instead of extending the current one.
+const itemSearchTimeout = time.Second
+
+// itemSearch implements incremental search by typing, as shared by the item
+// widgets.
+type itemSearch struct {
+	text      string // typed so far
+	match     string // prefix of the text of the last match
+	lastTime  time.Time
+	publisher ItemSearchEventPublisher
+}
+
+// reset makes the next key press start a new search.
+func (s *itemSearch) reset() {
+	s.text = ""
+	s.match = ""
+}
+
+// active returns whether a search is in progress.
+func (s *itemSearch) active() bool {
+	return s.text != "" && time.Since(s.lastTime) < itemSearchTimeout
+}
+
+// handleChar extends the search by ch and returns the index of the first item
+// at or after current whose text starts with the search text, ignoring case,
+// or -1. handled reports whether ch was used for searching.
+//
+// Typing the same character repeatedly cycles through the items starting
+// with it.
+func (s *itemSearch) handleChar(ch rune, current, count int, itemText func(index int) string) (index int, handled bool) {
+	if !s.active() {
+		s.reset()
+	}
+
+	// Space only searches once a search is in progress, so it can still be
+	// used to toggle check boxes.
+	if ch < ' ' || ch == ' ' && s.text == "" || utf16.IsSurrogate(ch) {
+		s.reset()
+		return -1, false
+	}
+
+	s.text += string(ch)
+	s.lastTime = time.Now()
+
+	text := s.text
+	start := current
+	if strings.Trim(text, string(ch)) == "" {
+		// All the same character, so move on to the next item with it.
+		text = string(ch)
+		start = current + 1
+	}
+	if start < 0 || start >= count {
+		start = 0
+	}
+
+	index = -1
+	s.publisher.Publish(text, start, &index)
+
+	lower := strings.ToLower(text)
+	for i := 0; i < count && index == -1; i++ {
+		idx := (start + i) % count
+
+		if strings.HasPrefix(strings.ToLower(itemText(idx)), lower) {
+			index = idx
+		}
+	}
+
+	if index > -1 {
+		s.match = text
+	}
+
+	return index, true
+}
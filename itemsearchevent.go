@@ -0,0 +1,63 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+type itemSearchEventHandlerInfo struct {
+	handler ItemSearchEventHandler
+	once    bool
+}
+
+type ItemSearchEventHandler func(text string, start int, index *int)
+
+type ItemSearchEvent struct {
+	handlers []itemSearchEventHandlerInfo
+}
+
+func (e *ItemSearchEvent) Attach(handler ItemSearchEventHandler) int {
+	handlerInfo := itemSearchEventHandlerInfo{handler, false}
+
+	for i, h := range e.handlers {
+		if h.handler == nil {
+			e.handlers[i] = handlerInfo
+			return i
+		}
+	}
+
+	e.handlers = append(e.handlers, handlerInfo)
+
+	return len(e.handlers) - 1
+}
+
+func (e *ItemSearchEvent) Detach(handle int) {
+	e.handlers[handle].handler = nil
+}
+
+func (e *ItemSearchEvent) Once(handler ItemSearchEventHandler) {
+	i := e.Attach(handler)
+	e.handlers[i].once = true
+}
+
+type ItemSearchEventPublisher struct {
+	event ItemSearchEvent
+}
+
+func (p *ItemSearchEventPublisher) Event() *ItemSearchEvent {
+	return &p.event
+}
+
+func (p *ItemSearchEventPublisher) Publish(text string, start int, index *int) {
+	for i, h := range p.event.handlers {
+		if h.handler != nil {
+			h.handler(text, start, index)
+
+			if h.once {
+				p.event.Detach(i)
+			}
+		}
+	}
+}
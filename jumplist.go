@@ -0,0 +1,506 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"os"
+	"strings"
+	"syscall"
+	"unsafe"
+
+	"github.com/tailscale/win"
+)
+
+// JumpListItem is a shortcut in a JumpList. It starts Path, or the executable
+// of the application if Path is empty, with Arguments.
+type JumpListItem struct {
+	Title            string
+	Description      string // shown as tool tip
+	Path             string
+	Arguments        string
+	WorkingDirectory string
+	IconPath         string
+	IconIndex        int
+
+	// Separator makes the item a separator line, ignoring all other fields.
+	// Separators are only supported in the tasks of a JumpList.
+	Separator bool
+}
+
+// JumpListCategory is a titled group of items in a JumpList.
+type JumpListCategory struct {
+	Title string
+	Items []JumpListItem
+}
+
+// JumpList is the menu shown when right-clicking the taskbar button of the
+// application or its entry in the start menu.
+//
+// It consists of custom categories, the Recent and Frequent categories
+// maintained by the shell and the tasks of the application. Changes are only
+// shown after calling Commit.
+//
+// Users can remove items of custom categories from the list. These are
+// reported by ItemsRemoved during the next Commit and are left out from then
+// on, as the shell refuses to show them again.
+type JumpList struct {
+	appID                 string
+	showRecent            bool
+	showFrequent          bool
+	categories            []JumpListCategory
+	tasks                 []JumpListItem
+	removed               []JumpListItem
+	itemsRemovedPublisher GenericEventPublisher[[]JumpListItem]
+}
+
+func NewJumpList() *JumpList {
+	return new(JumpList)
+}
+
+// AppID returns the application user model ID the JumpList belongs to, or an
+// empty string for the one of the process.
+func (jl *JumpList) AppID() string {
+	return jl.appID
+}
+
+// SetAppID sets the application user model ID the JumpList belongs to. It
+// only needs to be set if the taskbar buttons of the application use an
+// explicit ID other than the one of the process.
+func (jl *JumpList) SetAppID(appID string) {
+	jl.appID = appID
+}
+
+// ShowRecent returns whether the Recent category is shown.
+func (jl *JumpList) ShowRecent() bool {
+	return jl.showRecent
+}
+
+// SetShowRecent sets whether the Recent category is shown. It lists documents
+// added using AddRecentDocument or opened using the common file dialogs.
+//
+// The category requires the application to be registered as a handler for the
+// file types of the documents.
+func (jl *JumpList) SetShowRecent(show bool) {
+	jl.showRecent = show
+}
+
+// ShowFrequent returns whether the Frequent category is shown.
+func (jl *JumpList) ShowFrequent() bool {
+	return jl.showFrequent
+}
+
+// SetShowFrequent sets whether the Frequent category is shown. It lists the
+// documents that were opened most often, with the same requirements as the
+// Recent category.
+func (jl *JumpList) SetShowFrequent(show bool) {
+	jl.showFrequent = show
+}
+
+// Categories returns the custom categories of the JumpList.
+func (jl *JumpList) Categories() []JumpListCategory {
+	return jl.categories
+}
+
+// SetCategories sets the custom categories of the JumpList, which are shown
+// above the Recent and Frequent categories.
+func (jl *JumpList) SetCategories(categories []JumpListCategory) {
+	jl.categories = categories
+}
+
+// Tasks returns the tasks of the JumpList.
+func (jl *JumpList) Tasks() []JumpListItem {
+	return jl.tasks
+}
+
+// SetTasks sets the tasks of the JumpList, which are shown at its bottom and
+// cannot be removed by the user.
+func (jl *JumpList) SetTasks(tasks []JumpListItem) {
+	jl.tasks = tasks
+}
+
+// ItemsRemoved returns the event that is published during Commit with the
+// items of custom categories that the user removed from the JumpList since the
+// previous Commit.
+func (jl *JumpList) ItemsRemoved() *GenericEvent[[]JumpListItem] {
+	return jl.itemsRemovedPublisher.Event()
+}
+
+// Commit replaces the JumpList shown by the shell.
+func (jl *JumpList) Commit() error {
+	var list *iCustomDestinationList
+	if hr := win.CoCreateInstance(
+		&clsid_DestinationList,
+		nil,
+		win.CLSCTX_INPROC_SERVER,
+		&iid_ICustomDestinationList,
+		(*unsafe.Pointer)(unsafe.Pointer(&list))); win.FAILED(hr) {
+
+		return errorFromHRESULT("CoCreateInstance(CLSID_DestinationList)", hr)
+	}
+	defer list.Release()
+
+	if jl.appID != "" {
+		appID, err := syscall.UTF16PtrFromString(jl.appID)
+		if err != nil {
+			return wrapError(err)
+		}
+
+		if hr := list.SetAppID(appID); win.FAILED(hr) {
+			return errorFromHRESULT("ICustomDestinationList.SetAppID", hr)
+		}
+	}
+
+	var minSlots uint32
+	var removedArray *iObjectArray
+	if hr := list.BeginList(&minSlots, &removedArray); win.FAILED(hr) {
+		return errorFromHRESULT("ICustomDestinationList.BeginList", hr)
+	}
+
+	committed := false
+	defer func() {
+		if !committed {
+			list.AbortList()
+		}
+	}()
+
+	removed, err := jumpListItemsFromObjectArray(removedArray)
+	removedArray.Release()
+	if err != nil {
+		return err
+	}
+	jl.removed = append(jl.removed, removed...)
+
+	// The removed items are reported as they were passed to SetCategories.
+	var removedItems []JumpListItem
+
+	for _, category := range jl.categories {
+		var items []JumpListItem
+		for _, item := range category.Items {
+			if !containsJumpListItem(jl.removed, item) {
+				items = append(items, item)
+			} else if containsJumpListItem(removed, item) {
+				removedItems = append(removedItems, item)
+			}
+		}
+		if len(items) == 0 {
+			continue
+		}
+
+		title, err := syscall.UTF16PtrFromString(category.Title)
+		if err != nil {
+			return wrapError(err)
+		}
+
+		collection, err := newJumpListObjectCollection(items)
+		if err != nil {
+			return err
+		}
+
+		hr := list.AppendCategory(title, collection)
+		collection.Release()
+		if win.FAILED(hr) {
+			return errorFromHRESULT("ICustomDestinationList.AppendCategory", hr)
+		}
+	}
+
+	if jl.showFrequent {
+		if hr := list.AppendKnownCategory(_KDC_FREQUENT); win.FAILED(hr) {
+			return errorFromHRESULT("ICustomDestinationList.AppendKnownCategory", hr)
+		}
+	}
+	if jl.showRecent {
+		if hr := list.AppendKnownCategory(_KDC_RECENT); win.FAILED(hr) {
+			return errorFromHRESULT("ICustomDestinationList.AppendKnownCategory", hr)
+		}
+	}
+
+	if len(jl.tasks) > 0 {
+		collection, err := newJumpListObjectCollection(jl.tasks)
+		if err != nil {
+			return err
+		}
+
+		hr := list.AddUserTasks(collection)
+		collection.Release()
+		if win.FAILED(hr) {
+			return errorFromHRESULT("ICustomDestinationList.AddUserTasks", hr)
+		}
+	}
+
+	if hr := list.CommitList(); win.FAILED(hr) {
+		return errorFromHRESULT("ICustomDestinationList.CommitList", hr)
+	}
+
+	committed = true
+
+	if len(removedItems) > 0 {
+		jl.itemsRemovedPublisher.Publish(removedItems)
+	}
+
+	return nil
+}
+
+// Delete removes the custom categories and tasks shown by the shell. The
+// Recent and Frequent categories are kept, see ClearRecentDocuments.
+func (jl *JumpList) Delete() error {
+	var list *iCustomDestinationList
+	if hr := win.CoCreateInstance(
+		&clsid_DestinationList,
+		nil,
+		win.CLSCTX_INPROC_SERVER,
+		&iid_ICustomDestinationList,
+		(*unsafe.Pointer)(unsafe.Pointer(&list))); win.FAILED(hr) {
+
+		return errorFromHRESULT("CoCreateInstance(CLSID_DestinationList)", hr)
+	}
+	defer list.Release()
+
+	var appID *uint16
+	if jl.appID != "" {
+		var err error
+		if appID, err = syscall.UTF16PtrFromString(jl.appID); err != nil {
+			return wrapError(err)
+		}
+	}
+
+	if hr := list.DeleteList(appID); win.FAILED(hr) {
+		return errorFromHRESULT("ICustomDestinationList.DeleteList", hr)
+	}
+
+	return nil
+}
+
+// AddRecentDocument adds the file at path to the Recent and Frequent
+// categories of the application.
+func (jl *JumpList) AddRecentDocument(path string) error {
+	path16, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		return wrapError(err)
+	}
+
+	shAddToRecentDocs(_SHARD_PATHW, unsafe.Pointer(path16))
+
+	return nil
+}
+
+// ClearRecentDocuments empties the Recent and Frequent categories of the
+// application.
+func (jl *JumpList) ClearRecentDocuments() error {
+	var destinations *iApplicationDestinations
+	if hr := win.CoCreateInstance(
+		&clsid_ApplicationDestinations,
+		nil,
+		win.CLSCTX_INPROC_SERVER,
+		&iid_IApplicationDestinations,
+		(*unsafe.Pointer)(unsafe.Pointer(&destinations))); win.FAILED(hr) {
+
+		return errorFromHRESULT("CoCreateInstance(CLSID_ApplicationDestinations)", hr)
+	}
+	defer destinations.Release()
+
+	if jl.appID != "" {
+		appID, err := syscall.UTF16PtrFromString(jl.appID)
+		if err != nil {
+			return wrapError(err)
+		}
+
+		if hr := destinations.SetAppID(appID); win.FAILED(hr) {
+			return errorFromHRESULT("IApplicationDestinations.SetAppID", hr)
+		}
+	}
+
+	if hr := destinations.RemoveAllDestinations(); win.FAILED(hr) {
+		return errorFromHRESULT("IApplicationDestinations.RemoveAllDestinations", hr)
+	}
+
+	return nil
+}
+
+func containsJumpListItem(items []JumpListItem, item JumpListItem) bool {
+	for _, it := range items {
+		if sameJumpListItem(it, item) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// sameJumpListItem returns whether a and b start the same program with the
+// same arguments, which is how the shell identifies removed items.
+func sameJumpListItem(a, b JumpListItem) bool {
+	return strings.EqualFold(jumpListItemPath(a), jumpListItemPath(b)) && a.Arguments == b.Arguments
+}
+
+func jumpListItemPath(item JumpListItem) string {
+	if item.Path != "" {
+		return item.Path
+	}
+
+	path, _ := os.Executable()
+
+	return path
+}
+
+func newJumpListObjectCollection(items []JumpListItem) (*iObjectCollection, error) {
+	var collection *iObjectCollection
+	if hr := win.CoCreateInstance(
+		&clsid_EnumerableObjectCollection,
+		nil,
+		win.CLSCTX_INPROC_SERVER,
+		&iid_IObjectCollection,
+		(*unsafe.Pointer)(unsafe.Pointer(&collection))); win.FAILED(hr) {
+
+		return nil, errorFromHRESULT("CoCreateInstance(CLSID_EnumerableObjectCollection)", hr)
+	}
+
+	for _, item := range items {
+		link, err := newJumpListShellLink(item)
+		if err != nil {
+			collection.Release()
+			return nil, err
+		}
+
+		hr := collection.AddObject(unsafe.Pointer(link))
+		link.Release()
+		if win.FAILED(hr) {
+			collection.Release()
+			return nil, errorFromHRESULT("IObjectCollection.AddObject", hr)
+		}
+	}
+
+	return collection, nil
+}
+
+func newJumpListShellLink(item JumpListItem) (*iShellLinkW, error) {
+	var link *iShellLinkW
+	if hr := win.CoCreateInstance(
+		&clsid_ShellLink,
+		nil,
+		win.CLSCTX_INPROC_SERVER,
+		&iid_IShellLinkW,
+		(*unsafe.Pointer)(unsafe.Pointer(&link))); win.FAILED(hr) {
+
+		return nil, errorFromHRESULT("CoCreateInstance(CLSID_ShellLink)", hr)
+	}
+
+	succeeded := false
+	defer func() {
+		if !succeeded {
+			link.Release()
+		}
+	}()
+
+	if !item.Separator {
+		if err := setJumpListShellLinkStrings(link, item); err != nil {
+			return nil, err
+		}
+	}
+
+	var props *iPropertyStore
+	if hr := link.QueryInterface(&iid_IPropertyStore, (*unsafe.Pointer)(unsafe.Pointer(&props))); win.FAILED(hr) {
+		return nil, errorFromHRESULT("IShellLinkW.QueryInterface(IID_IPropertyStore)", hr)
+	}
+	defer props.Release()
+
+	var pv propVariant
+	key := &_PKEY_Title
+	if item.Separator {
+		pv.setBool(true)
+		key = &_PKEY_AppUserModel_IsDestListSeparator
+	} else if err := pv.setString(item.Title); err != nil {
+		return nil, wrapError(err)
+	}
+
+	hr := props.SetValue(key, &pv)
+	if pv.vt == win.VT_LPWSTR {
+		win.CoTaskMemFree(pv.val)
+	}
+	if win.FAILED(hr) {
+		return nil, errorFromHRESULT("IPropertyStore.SetValue", hr)
+	}
+
+	if hr := props.Commit(); win.FAILED(hr) {
+		return nil, errorFromHRESULT("IPropertyStore.Commit", hr)
+	}
+
+	succeeded = true
+
+	return link, nil
+}
+
+func setJumpListShellLinkStrings(link *iShellLinkW, item JumpListItem) error {
+	for _, s := range []struct {
+		value  string
+		method string
+		set    func(*uint16) win.HRESULT
+	}{
+		{jumpListItemPath(item), "SetPath", link.SetPath},
+		{item.Arguments, "SetArguments", link.SetArguments},
+		{item.Description, "SetDescription", link.SetDescription},
+		{item.WorkingDirectory, "SetWorkingDirectory", link.SetWorkingDirectory},
+	} {
+		if s.value == "" {
+			continue
+		}
+
+		value, err := syscall.UTF16PtrFromString(s.value)
+		if err != nil {
+			return wrapError(err)
+		}
+
+		if hr := s.set(value); win.FAILED(hr) {
+			return errorFromHRESULT("IShellLinkW."+s.method, hr)
+		}
+	}
+
+	if item.IconPath != "" {
+		iconPath, err := syscall.UTF16PtrFromString(item.IconPath)
+		if err != nil {
+			return wrapError(err)
+		}
+
+		if hr := link.SetIconLocation(iconPath, int32(item.IconIndex)); win.FAILED(hr) {
+			return errorFromHRESULT("IShellLinkW.SetIconLocation", hr)
+		}
+	}
+
+	return nil
+}
+
+// jumpListItemsFromObjectArray returns the shell links in array as items that
+// only carry what is needed to identify them.
+func jumpListItemsFromObjectArray(array *iObjectArray) ([]JumpListItem, error) {
+	var count uint32
+	if hr := array.GetCount(&count); win.FAILED(hr) {
+		return nil, errorFromHRESULT("IObjectArray.GetCount", hr)
+	}
+
+	var items []JumpListItem
+	for i := uint32(0); i < count; i++ {
+		var link *iShellLinkW
+		if hr := array.GetAt(i, &iid_IShellLinkW, (*unsafe.Pointer)(unsafe.Pointer(&link))); win.FAILED(hr) {
+			// Removed destinations may also be shell items, which this
+			// JumpList never adds.
+			continue
+		}
+
+		var path [win.MAX_PATH]uint16
+		var args [1024]uint16
+		link.GetPath(path[:])
+		link.GetArguments(args[:])
+		link.Release()
+
+		items = append(items, JumpListItem{
+			Path:      syscall.UTF16ToString(path[:]),
+			Arguments: syscall.UTF16ToString(args[:]),
+		})
+	}
+
+	return items, nil
+}
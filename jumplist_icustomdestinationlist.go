@@ -0,0 +1,363 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"syscall"
+	"unsafe"
+
+	"github.com/tailscale/win"
+)
+
+var (
+	clsid_ApplicationDestinations    = win.CLSID{0x86C14003, 0x4D6B, 0x4EF3, [8]byte{0xA7, 0xB4, 0x05, 0x06, 0x66, 0x3B, 0x2E, 0x68}}
+	clsid_DestinationList            = win.CLSID{0x77F10CF0, 0x3DB5, 0x4966, [8]byte{0xB5, 0x20, 0xB7, 0xC5, 0x4F, 0xD3, 0x5E, 0xD6}}
+	clsid_EnumerableObjectCollection = win.CLSID{0x2D3468C1, 0x36A7, 0x43B6, [8]byte{0xAC, 0x24, 0xD3, 0xF0, 0x2F, 0xD9, 0x60, 0x7A}}
+	clsid_ShellLink                  = win.CLSID{0x00021401, 0x0000, 0x0000, [8]byte{0xC0, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x46}}
+
+	iid_IApplicationDestinations = win.IID{0x12337D35, 0x94C6, 0x48A0, [8]byte{0xBC, 0xE7, 0x6A, 0x9C, 0x69, 0xD4, 0xD6, 0x00}}
+	iid_ICustomDestinationList   = win.IID{0x6332DEBF, 0x87B5, 0x4670, [8]byte{0x90, 0xC0, 0x5E, 0x57, 0xB4, 0x08, 0xA4, 0x9E}}
+	iid_IObjectArray             = win.IID{0x92CA9DCD, 0x5622, 0x4BBA, [8]byte{0xA8, 0x05, 0x5E, 0x9F, 0x54, 0x1B, 0xD8, 0xC9}}
+	iid_IObjectCollection        = win.IID{0x5632B1A4, 0xE38A, 0x400A, [8]byte{0x92, 0x8A, 0xD4, 0xCD, 0x63, 0x23, 0x02, 0x95}}
+	iid_IPropertyStore           = win.IID{0x886D8EEB, 0x8CF2, 0x4446, [8]byte{0x8D, 0x02, 0xCD, 0xBA, 0x1D, 0xBD, 0xCF, 0x99}}
+	iid_IShellLinkW              = win.IID{0x000214F9, 0x0000, 0x0000, [8]byte{0xC0, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x46}}
+)
+
+var (
+	_PKEY_Title                            = uiPropertyKey{syscall.GUID{0xF29F85E0, 0x4FF9, 0x1068, [8]byte{0xAB, 0x91, 0x08, 0x00, 0x2B, 0x27, 0xB3, 0xD9}}, 2}
+	_PKEY_AppUserModel_IsDestListSeparator = uiPropertyKey{syscall.GUID{0x9F4C2855, 0x9F79, 0x4B39, [8]byte{0xA8, 0xD0, 0xE1, 0xD4, 0x2D, 0xE1, 0xD5, 0xF3}}, 6}
+)
+
+const (
+	_KDC_FREQUENT = 1
+	_KDC_RECENT   = 2
+
+	_SHARD_PATHW = 0x00000003
+)
+
+type iCustomDestinationListVtbl struct {
+	win.IUnknownVtbl
+	SetAppID               uintptr
+	BeginList              uintptr
+	AppendCategory         uintptr
+	AppendKnownCategory    uintptr
+	AddUserTasks           uintptr
+	CommitList             uintptr
+	GetRemovedDestinations uintptr
+	DeleteList             uintptr
+	AbortList              uintptr
+}
+
+type iCustomDestinationList struct {
+	LpVtbl *iCustomDestinationListVtbl
+}
+
+func (obj *iCustomDestinationList) Release() uint32 {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.Release,
+		uintptr(unsafe.Pointer(obj)))
+
+	return uint32(ret)
+}
+
+func (obj *iCustomDestinationList) SetAppID(appID *uint16) win.HRESULT {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.SetAppID,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(unsafe.Pointer(appID)))
+
+	return win.HRESULT(ret)
+}
+
+func (obj *iCustomDestinationList) BeginList(minSlots *uint32, removed **iObjectArray) win.HRESULT {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.BeginList,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(unsafe.Pointer(minSlots)),
+		uintptr(unsafe.Pointer(&iid_IObjectArray)),
+		uintptr(unsafe.Pointer(removed)))
+
+	return win.HRESULT(ret)
+}
+
+func (obj *iCustomDestinationList) AppendCategory(category *uint16, items *iObjectCollection) win.HRESULT {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.AppendCategory,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(unsafe.Pointer(category)),
+		uintptr(unsafe.Pointer(items)))
+
+	return win.HRESULT(ret)
+}
+
+func (obj *iCustomDestinationList) AppendKnownCategory(category int32) win.HRESULT {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.AppendKnownCategory,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(category))
+
+	return win.HRESULT(ret)
+}
+
+func (obj *iCustomDestinationList) AddUserTasks(tasks *iObjectCollection) win.HRESULT {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.AddUserTasks,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(unsafe.Pointer(tasks)))
+
+	return win.HRESULT(ret)
+}
+
+func (obj *iCustomDestinationList) CommitList() win.HRESULT {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.CommitList,
+		uintptr(unsafe.Pointer(obj)))
+
+	return win.HRESULT(ret)
+}
+
+func (obj *iCustomDestinationList) DeleteList(appID *uint16) win.HRESULT {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.DeleteList,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(unsafe.Pointer(appID)))
+
+	return win.HRESULT(ret)
+}
+
+func (obj *iCustomDestinationList) AbortList() win.HRESULT {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.AbortList,
+		uintptr(unsafe.Pointer(obj)))
+
+	return win.HRESULT(ret)
+}
+
+type iApplicationDestinationsVtbl struct {
+	win.IUnknownVtbl
+	SetAppID              uintptr
+	RemoveDestination     uintptr
+	RemoveAllDestinations uintptr
+}
+
+type iApplicationDestinations struct {
+	LpVtbl *iApplicationDestinationsVtbl
+}
+
+func (obj *iApplicationDestinations) Release() uint32 {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.Release,
+		uintptr(unsafe.Pointer(obj)))
+
+	return uint32(ret)
+}
+
+func (obj *iApplicationDestinations) SetAppID(appID *uint16) win.HRESULT {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.SetAppID,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(unsafe.Pointer(appID)))
+
+	return win.HRESULT(ret)
+}
+
+func (obj *iApplicationDestinations) RemoveAllDestinations() win.HRESULT {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.RemoveAllDestinations,
+		uintptr(unsafe.Pointer(obj)))
+
+	return win.HRESULT(ret)
+}
+
+// iObjectCollectionVtbl is the vtable of IObjectCollection, whose first
+// entries are those of IObjectArray.
+type iObjectCollectionVtbl struct {
+	win.IUnknownVtbl
+	GetCount       uintptr
+	GetAt          uintptr
+	AddObject      uintptr
+	AddFromArray   uintptr
+	RemoveObjectAt uintptr
+	Clear          uintptr
+}
+
+// iObjectArray must only be used for the methods of IObjectArray.
+type iObjectArray struct {
+	LpVtbl *iObjectCollectionVtbl
+}
+
+func (obj *iObjectArray) Release() uint32 {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.Release,
+		uintptr(unsafe.Pointer(obj)))
+
+	return uint32(ret)
+}
+
+func (obj *iObjectArray) GetCount(count *uint32) win.HRESULT {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.GetCount,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(unsafe.Pointer(count)))
+
+	return win.HRESULT(ret)
+}
+
+func (obj *iObjectArray) GetAt(index uint32, riid *win.IID, ppv *unsafe.Pointer) win.HRESULT {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.GetAt,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(index),
+		uintptr(unsafe.Pointer(riid)),
+		uintptr(unsafe.Pointer(ppv)))
+
+	return win.HRESULT(ret)
+}
+
+type iObjectCollection struct {
+	LpVtbl *iObjectCollectionVtbl
+}
+
+func (obj *iObjectCollection) Release() uint32 {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.Release,
+		uintptr(unsafe.Pointer(obj)))
+
+	return uint32(ret)
+}
+
+func (obj *iObjectCollection) AddObject(unk unsafe.Pointer) win.HRESULT {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.AddObject,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(unk))
+
+	return win.HRESULT(ret)
+}
+
+type iShellLinkWVtbl struct {
+	win.IUnknownVtbl
+	GetPath             uintptr
+	GetIDList           uintptr
+	SetIDList           uintptr
+	GetDescription      uintptr
+	SetDescription      uintptr
+	GetWorkingDirectory uintptr
+	SetWorkingDirectory uintptr
+	GetArguments        uintptr
+	SetArguments        uintptr
+	GetHotkey           uintptr
+	SetHotkey           uintptr
+	GetShowCmd          uintptr
+	SetShowCmd          uintptr
+	GetIconLocation     uintptr
+	SetIconLocation     uintptr
+	SetRelativePath     uintptr
+	Resolve             uintptr
+	SetPath             uintptr
+}
+
+type iShellLinkW struct {
+	LpVtbl *iShellLinkWVtbl
+}
+
+func (obj *iShellLinkW) QueryInterface(riid *win.IID, ppv *unsafe.Pointer) win.HRESULT {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.QueryInterface,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(unsafe.Pointer(riid)),
+		uintptr(unsafe.Pointer(ppv)))
+
+	return win.HRESULT(ret)
+}
+
+func (obj *iShellLinkW) Release() uint32 {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.Release,
+		uintptr(unsafe.Pointer(obj)))
+
+	return uint32(ret)
+}
+
+func (obj *iShellLinkW) GetPath(buf []uint16) win.HRESULT {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.GetPath,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(unsafe.Pointer(&buf[0])),
+		uintptr(len(buf)),
+		0,
+		0)
+
+	return win.HRESULT(ret)
+}
+
+func (obj *iShellLinkW) SetDescription(description *uint16) win.HRESULT {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.SetDescription,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(unsafe.Pointer(description)))
+
+	return win.HRESULT(ret)
+}
+
+func (obj *iShellLinkW) SetWorkingDirectory(dir *uint16) win.HRESULT {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.SetWorkingDirectory,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(unsafe.Pointer(dir)))
+
+	return win.HRESULT(ret)
+}
+
+func (obj *iShellLinkW) GetArguments(buf []uint16) win.HRESULT {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.GetArguments,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(unsafe.Pointer(&buf[0])),
+		uintptr(len(buf)))
+
+	return win.HRESULT(ret)
+}
+
+func (obj *iShellLinkW) SetArguments(args *uint16) win.HRESULT {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.SetArguments,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(unsafe.Pointer(args)))
+
+	return win.HRESULT(ret)
+}
+
+func (obj *iShellLinkW) SetIconLocation(path *uint16, index int32) win.HRESULT {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.SetIconLocation,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(unsafe.Pointer(path)),
+		uintptr(index))
+
+	return win.HRESULT(ret)
+}
+
+func (obj *iShellLinkW) SetPath(path *uint16) win.HRESULT {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.SetPath,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(unsafe.Pointer(path)))
+
+	return win.HRESULT(ret)
+}
+
+type iPropertyStoreVtbl struct {
+	win.IUnknownVtbl
+	GetCount uintptr
+	GetAt    uintptr
+	GetValue uintptr
+	SetValue uintptr
+	Commit   uintptr
+}
+
+type iPropertyStore struct {
+	LpVtbl *iPropertyStoreVtbl
+}
+
+func (obj *iPropertyStore) Release() uint32 {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.Release,
+		uintptr(unsafe.Pointer(obj)))
+
+	return uint32(ret)
+}
+
+func (obj *iPropertyStore) SetValue(key *uiPropertyKey, value *propVariant) win.HRESULT {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.SetValue,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(unsafe.Pointer(key)),
+		uintptr(unsafe.Pointer(value)))
+
+	return win.HRESULT(ret)
+}
+
+func (obj *iPropertyStore) Commit() win.HRESULT {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.Commit,
+		uintptr(unsafe.Pointer(obj)))
+
+	return win.HRESULT(ret)
+}
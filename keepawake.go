@@ -0,0 +1,123 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"os"
+	"path/filepath"
+
+	"golang.org/x/sys/windows"
+)
+
+// KeepAwakeRequest keeps the system, and optionally the display, from going
+// to sleep until it is released.
+type KeepAwakeRequest struct {
+	handle          windows.Handle
+	display         bool
+	form            Form
+	disposingHandle int
+}
+
+// KeepAwake keeps the system from going to sleep because of user inactivity,
+// e.g. during a file transfer, and if display is true, also keeps the display
+// on, e.g. during media playback. The request lasts until it is released.
+// Requests are independent of each other, so operations can each hold their
+// own one:
+//
+//	req, err := walk.KeepAwake(false)
+//	if err != nil {
+//		return err
+//	}
+//	defer req.Release()
+//
+// Windows lists the request with the product name of the application, see
+// powercfg /requests.
+func KeepAwake(display bool) (*KeepAwakeRequest, error) {
+	reason := App().ProductName()
+	if reason == "" {
+		reason = filepath.Base(os.Args[0])
+	}
+
+	reason16, err := windows.UTF16PtrFromString(reason)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	context := reasonContext{
+		version:            _POWER_REQUEST_CONTEXT_VERSION,
+		flags:              _POWER_REQUEST_CONTEXT_SIMPLE_STRING,
+		simpleReasonString: reason16,
+	}
+
+	handle := powerCreateRequest(&context)
+	if handle == windows.InvalidHandle {
+		return nil, lastError("PowerCreateRequest")
+	}
+
+	req := &KeepAwakeRequest{handle: handle, display: display}
+
+	if !powerSetRequest(handle, _POWER_REQUEST_SYSTEM_REQUIRED) {
+		err := lastError("PowerSetRequest")
+		windows.CloseHandle(handle)
+		return nil, err
+	}
+
+	if display && !powerSetRequest(handle, _POWER_REQUEST_DISPLAY_REQUIRED) {
+		err := lastError("PowerSetRequest")
+		powerClearRequest(handle, _POWER_REQUEST_SYSTEM_REQUIRED)
+		windows.CloseHandle(handle)
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// KeepAwake is like the KeepAwake function, but the request is also released
+// when the *FormBase is disposed, e.g. for a media player window.
+func (fb *FormBase) KeepAwake(display bool) (*KeepAwakeRequest, error) {
+	req, err := KeepAwake(display)
+	if err != nil {
+		return nil, err
+	}
+
+	req.form = fb.window.(Form)
+	req.disposingHandle = fb.Disposing().Attach(req.Release)
+
+	return req, nil
+}
+
+// Display returns whether the KeepAwakeRequest also keeps the display on.
+func (req *KeepAwakeRequest) Display() bool {
+	return req.display
+}
+
+// Released returns whether the KeepAwakeRequest was released.
+func (req *KeepAwakeRequest) Released() bool {
+	return req.handle == 0
+}
+
+// Release allows the system to go to sleep again, unless other requests keep
+// it awake. Releasing a request more than once has no effect.
+func (req *KeepAwakeRequest) Release() {
+	if req.handle == 0 {
+		return
+	}
+
+	if req.display {
+		powerClearRequest(req.handle, _POWER_REQUEST_DISPLAY_REQUIRED)
+	}
+	powerClearRequest(req.handle, _POWER_REQUEST_SYSTEM_REQUIRED)
+
+	windows.CloseHandle(req.handle)
+	req.handle = 0
+
+	if req.form != nil {
+		req.form.Disposing().Detach(req.disposingHandle)
+		req.form = nil
+	}
+}
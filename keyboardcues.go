@@ -0,0 +1,83 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"github.com/tailscale/win"
+)
+
+// Windows hides keyboard cues, i.e. focus rectangles and the underlines of
+// mnemonics, until the user starts navigating with the keyboard, e.g. by
+// pressing Tab or Alt. The state is kept per top-level window and its
+// descendants are notified of changes with WM_UPDATEUISTATE.
+//
+// Widgets that draw focus rectangles or mnemonics themselves should only do so
+// if FocusCuesVisible or AcceleratorCuesVisible return true. Windows of the
+// classes registered by walk are repainted when the cues change.
+
+// FocusCuesVisible returns whether the *WindowBase shows focus rectangles.
+func (wb *WindowBase) FocusCuesVisible() bool {
+	return keyboardCuesVisible(wb.hWnd, win.UISF_HIDEFOCUS)
+}
+
+// AcceleratorCuesVisible returns whether the *WindowBase underlines the
+// mnemonics of its text, i.e. the characters following an ampersand.
+func (wb *WindowBase) AcceleratorCuesVisible() bool {
+	return keyboardCuesVisible(wb.hWnd, win.UISF_HIDEACCEL)
+}
+
+// ShowKeyboardCues shows focus rectangles and mnemonic underlines in the form
+// of the *WindowBase, e.g. after moving the focus programmatically in reaction
+// to a keyboard shortcut.
+func (wb *WindowBase) ShowKeyboardCues() {
+	wb.SendMessage(win.WM_CHANGEUISTATE, uintptr(win.MAKELONG(win.UIS_CLEAR, win.UISF_HIDEFOCUS|win.UISF_HIDEACCEL)), 0)
+}
+
+// KeyboardCuesChanged returns the event that is published when the
+// *WindowBase starts or stops showing focus rectangles or mnemonic underlines.
+func (wb *WindowBase) KeyboardCuesChanged() *Event {
+	return wb.keyboardCuesPublisher.Event()
+}
+
+// initKeyboardCues hides the keyboard cues in the form of the *WindowBase
+// unless the last input came from the keyboard or the user chose to always
+// show them. The dialog manager does this for dialogs, but not for windows
+// created otherwise.
+func (wb *WindowBase) initKeyboardCues() {
+	wb.SendMessage(win.WM_CHANGEUISTATE, uintptr(win.MAKELONG(win.UIS_INITIALIZE, 0)), 0)
+}
+
+// handleUpdateUIState lets the window procedure of the *WindowBase update the
+// keyboard cues, which also notifies the children, and publishes
+// KeyboardCuesChanged if they changed.
+func (wb *WindowBase) handleUpdateUIState(hwnd win.HWND, msg uint32, wParam, lParam uintptr) uintptr {
+	before := win.SendMessage(hwnd, win.WM_QUERYUISTATE, 0, 0)
+
+	var result uintptr
+	if wb.origWndProcPtr != 0 {
+		result = win.CallWindowProc(wb.origWndProcPtr, hwnd, msg, wParam, lParam)
+	} else {
+		result = win.DefWindowProc(hwnd, msg, wParam, lParam)
+	}
+
+	const mask = win.UISF_HIDEFOCUS | win.UISF_HIDEACCEL
+	if after := win.SendMessage(hwnd, win.WM_QUERYUISTATE, 0, 0); after&mask != before&mask {
+		if wb.origWndProcPtr == 0 {
+			// Windows of system classes repaint themselves.
+			win.InvalidateRect(hwnd, nil, false)
+		}
+
+		wb.keyboardCuesPublisher.Publish()
+	}
+
+	return result
+}
+
+func keyboardCuesVisible(hwnd win.HWND, flag uint32) bool {
+	return uint32(win.SendMessage(hwnd, win.WM_QUERYUISTATE, 0, 0))&flag == 0
+}
@@ -0,0 +1,195 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"image"
+	"unsafe"
+
+	"github.com/tailscale/win"
+)
+
+// updateLayeredWindowWithImage sets the content of the WS_EX_LAYERED window
+// hwnd to im, which must contain premultiplied alpha, and moves the window to
+// location in native pixels. opacity is applied on top of the per-pixel alpha.
+func updateLayeredWindowWithImage(hwnd win.HWND, location Point, im *image.RGBA, opacity byte) error {
+	hBmp, err := hBitmapFromImage(im, 96)
+	if err != nil {
+		return err
+	}
+	defer win.DeleteObject(win.HGDIOBJ(hBmp))
+
+	hdcScreen := win.GetDC(0)
+	if hdcScreen == 0 {
+		return newError("GetDC failed")
+	}
+	defer win.ReleaseDC(0, hdcScreen)
+
+	hdcMem := win.CreateCompatibleDC(hdcScreen)
+	if hdcMem == 0 {
+		return newError("CreateCompatibleDC failed")
+	}
+	defer win.DeleteDC(hdcMem)
+
+	hBmpOld := win.SelectObject(hdcMem, win.HGDIOBJ(hBmp))
+	defer win.SelectObject(hdcMem, hBmpOld)
+
+	ptDst := win.POINT{X: int32(location.X), Y: int32(location.Y)}
+	size := win.SIZE{CX: int32(im.Rect.Dx()), CY: int32(im.Rect.Dy())}
+	var ptSrc win.POINT
+	blend := win.BLENDFUNCTION{
+		BlendOp:             _AC_SRC_OVER,
+		SourceConstantAlpha: opacity,
+		AlphaFormat:         win.AC_SRC_ALPHA,
+	}
+
+	if !updateLayeredWindow(hwnd, hdcScreen, &ptDst, &size, hdcMem, &ptSrc, 0, &blend, _ULW_ALPHA) {
+		return lastError("UpdateLayeredWindow")
+	}
+
+	return nil
+}
+
+const layeredWindowClass = `\o/ Walk_LayeredWindow_Class \o/`
+
+func init() {
+	AppendToWalkInit(func() {
+		MustRegisterWindowClass(layeredWindowClass)
+	})
+}
+
+// LayeredPaintFunc paints the content of a LayeredWindow into buffer, whose
+// bounds match the size of the window in native pixels. As with any
+// image.RGBA, the color channels must be premultiplied by alpha.
+type LayeredPaintFunc func(buffer *image.RGBA) error
+
+// LayeredWindow is a top-level window with per-pixel alpha transparency.
+//
+// Its content is not painted by child widgets, but supplied as a whole by a
+// LayeredPaintFunc, which makes it suitable for splash screens, non-rectangular
+// overlays and OSD-style popups.
+type LayeredWindow struct {
+	WindowBase
+	owner        Form
+	paint        LayeredPaintFunc
+	opacity      byte
+	clickThrough bool
+}
+
+// NewLayeredWindow creates a new, initially hidden LayeredWindow whose content
+// is painted by paint. owner may be nil.
+func NewLayeredWindow(owner Form, paint LayeredPaintFunc) (*LayeredWindow, error) {
+	if paint == nil {
+		return nil, newError("paint cannot be nil")
+	}
+
+	lw := &LayeredWindow{owner: owner, paint: paint, opacity: 0xff}
+
+	var parent Window
+	if owner != nil {
+		parent = owner
+	}
+
+	if err := InitWindow(
+		lw,
+		parent,
+		layeredWindowClass,
+		win.WS_POPUP,
+		win.WS_EX_LAYERED|win.WS_EX_TOOLWINDOW); err != nil {
+		return nil, err
+	}
+
+	return lw, nil
+}
+
+// Owner returns the Form that owns the LayeredWindow, if any.
+func (lw *LayeredWindow) Owner() Form {
+	return lw.owner
+}
+
+// Opacity returns the opacity that is applied on top of the per-pixel alpha.
+func (lw *LayeredWindow) Opacity() byte {
+	return lw.opacity
+}
+
+// SetOpacity sets the opacity that is applied on top of the per-pixel alpha
+// and repaints the window.
+func (lw *LayeredWindow) SetOpacity(opacity byte) error {
+	lw.opacity = opacity
+
+	return lw.Update()
+}
+
+// ClickThrough returns whether mouse input passes through the window to the
+// windows beneath it.
+func (lw *LayeredWindow) ClickThrough() bool {
+	return lw.clickThrough
+}
+
+// SetClickThrough sets whether mouse input passes through the window to the
+// windows beneath it.
+func (lw *LayeredWindow) SetClickThrough(clickThrough bool) error {
+	if err := lw.ensureExtendedStyleBits(win.WS_EX_TRANSPARENT, clickThrough); err != nil {
+		return err
+	}
+
+	lw.clickThrough = clickThrough
+
+	return nil
+}
+
+// SetBounds moves and resizes the window and repaints it. bounds is specified
+// in 1/96" units.
+func (lw *LayeredWindow) SetBounds(bounds Rectangle) error {
+	return lw.SetBoundsPixels(lw.RectangleFrom96DPI(bounds))
+}
+
+// SetBoundsPixels moves and resizes the window and repaints it. bounds is
+// specified in native pixels.
+func (lw *LayeredWindow) SetBoundsPixels(bounds Rectangle) error {
+	return lw.updateWithBounds(bounds)
+}
+
+// Update calls the paint function and replaces the window content with the
+// result.
+func (lw *LayeredWindow) Update() error {
+	return lw.updateWithBounds(lw.BoundsPixels())
+}
+
+func (lw *LayeredWindow) updateWithBounds(bounds Rectangle) error {
+	if bounds.Width <= 0 || bounds.Height <= 0 {
+		return nil
+	}
+
+	buffer := image.NewRGBA(image.Rect(0, 0, bounds.Width, bounds.Height))
+	if err := lw.paint(buffer); err != nil {
+		return err
+	}
+
+	if err := updateLayeredWindowWithImage(lw.hWnd, bounds.Location(), buffer, lw.opacity); err != nil {
+		return err
+	}
+
+	lw.boundsChangedPublisher.Publish()
+
+	return nil
+}
+
+func (lw *LayeredWindow) WndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) uintptr {
+	switch msg {
+	case win.WM_MOUSEACTIVATE:
+		return _MA_NOACTIVATE
+
+	case win.WM_DPICHANGED:
+		rc := (*win.RECT)(unsafe.Pointer(lParam))
+		lw.SetBoundsPixels(rectangleFromRECT(*rc))
+		return 0
+	}
+
+	return lw.WindowBase.WndProc(hwnd, msg, wParam, lParam)
+}
@@ -9,6 +9,7 @@ package walk
 
 import (
 	"sync"
+	"sync/atomic"
 
 	"github.com/tailscale/win"
 )
@@ -225,6 +226,7 @@ func layoutTree(root ContainerLayoutItem, size Size, cancel chan struct{}, done
 				clib.geometry.ClientSize = size
 
 				items := container.PerformLayout()
+				constrainLayoutResults(items, clib.alignment)
 
 				select {
 				case <-cancel:
@@ -286,6 +288,115 @@ func layoutTree(root ContainerLayoutItem, size Size, cancel chan struct{}, done
 	}
 }
 
+// constrainLayoutResults enforces the max size of each item on the bounds
+// computed for it by a layout, for layouts that do not honor it themselves.
+// An item that got more space than its max size is aligned in that space
+// according to its alignment, or else the alignment of the layout.
+//
+// Min sizes are not enforced here, as growing an item past the space the
+// layout gave it would make it overlap its neighbors. Layouts honor them when
+// distributing space, via MinSizeEffectiveForChild.
+func constrainLayoutResults(items []LayoutResultItem, layoutAlignment Alignment2D) {
+	for i := range items {
+		geometry := items[i].Item.Geometry()
+		bounds := &items[i].Bounds
+
+		alignment := geometry.Alignment
+		if alignment == AlignHVDefault {
+			alignment = layoutAlignment
+		}
+
+		var hAlign, vAlign Alignment1D
+		switch alignment {
+		case AlignHNearVNear, AlignHNearVCenter, AlignHNearVFar:
+			hAlign = AlignNear
+		case AlignHFarVNear, AlignHFarVCenter, AlignHFarVFar:
+			hAlign = AlignFar
+		}
+		switch alignment {
+		case AlignHNearVNear, AlignHCenterVNear, AlignHFarVNear:
+			vAlign = AlignNear
+		case AlignHNearVFar, AlignHCenterVFar, AlignHFarVFar:
+			vAlign = AlignFar
+		}
+
+		bounds.X, bounds.Width = constrainSpan(bounds.X, bounds.Width, geometry.MaxSize.Width, hAlign)
+		bounds.Y, bounds.Height = constrainSpan(bounds.Y, bounds.Height, geometry.MaxSize.Height, vAlign)
+	}
+}
+
+// constrainSpan limits length to max, where 0 means no limit, and aligns the
+// result in the original span if it got shorter.
+func constrainSpan(pos, length, max int, align Alignment1D) (int, int) {
+	if max > 0 && length > max {
+		switch align {
+		case AlignNear:
+			// The item stays at the start of its span.
+
+		case AlignFar:
+			pos += length - max
+
+		default:
+			pos += (length - max) / 2
+		}
+
+		return pos, max
+	}
+
+	return pos, length
+}
+
+// LayoutStats holds counters describing the work done by the layout engine
+// since the start of the process or the last call to ResetLayoutStats.
+type LayoutStats struct {
+	// Requests is the number of layouts requested via RequestLayout.
+	Requests uint64
+
+	// Coalesced is the number of requests that were merged into a layout
+	// already scheduled for the same form.
+	Coalesced uint64
+
+	// Passes is the number of layout passes started.
+	Passes uint64
+
+	// Repositioned is the number of widgets moved or resized when applying
+	// layout results.
+	Repositioned uint64
+
+	// Unchanged is the number of widgets whose bounds did not change when
+	// applying layout results, so no SetWindowPos was needed for them.
+	Unchanged uint64
+}
+
+var layoutStats struct {
+	requests     atomic.Uint64
+	coalesced    atomic.Uint64
+	passes       atomic.Uint64
+	repositioned atomic.Uint64
+	unchanged    atomic.Uint64
+}
+
+// CurrentLayoutStats returns the current layout engine counters, e.g. for
+// profiling.
+func CurrentLayoutStats() LayoutStats {
+	return LayoutStats{
+		Requests:     layoutStats.requests.Load(),
+		Coalesced:    layoutStats.coalesced.Load(),
+		Passes:       layoutStats.passes.Load(),
+		Repositioned: layoutStats.repositioned.Load(),
+		Unchanged:    layoutStats.unchanged.Load(),
+	}
+}
+
+// ResetLayoutStats resets all layout engine counters to zero.
+func ResetLayoutStats() {
+	layoutStats.requests.Store(0)
+	layoutStats.coalesced.Store(0)
+	layoutStats.passes.Store(0)
+	layoutStats.repositioned.Store(0)
+	layoutStats.unchanged.Store(0)
+}
+
 func applyLayoutResults(results []LayoutResult, stopwatch *stopwatch) error {
 	if stopwatch != nil {
 		const subject = "applyLayoutResults"
@@ -300,10 +411,9 @@ func applyLayoutResults(results []LayoutResult, stopwatch *stopwatch) error {
 			continue
 		}
 
-		hdwp := win.BeginDeferWindowPos(int32(len(result.items)))
-		if hdwp == 0 {
-			return lastError("BeginDeferWindowPos")
-		}
+		// The batch is only started once the first item actually needs to be
+		// repositioned.
+		var hdwp win.HDWP
 
 		var maybeInvalidate bool
 		if wnd := windowFromHandle(result.container.Handle()); wnd != nil {
@@ -329,7 +439,7 @@ func applyLayoutResults(results []LayoutResult, stopwatch *stopwatch) error {
 							activeForm, _ := windowFromHandle(hwndForm).(Form)
 
 							if hwndFocused == 0 || form.Handle() == hwndFocused || activeForm != window.Form() {
-								form.AsFormBase().clientComposite.focusFirstCandidateDescendant()
+								form.AsFormBase().focusInitially()
 							}
 						}()
 					}
@@ -340,19 +450,27 @@ func applyLayoutResults(results []LayoutResult, stopwatch *stopwatch) error {
 				oldBounds := widget.BoundsPixels()
 
 				if ri.Bounds == oldBounds {
+					layoutStats.unchanged.Add(1)
 					continue
 				}
 
 				if ri.Bounds.X == oldBounds.X && ri.Bounds.Y == oldBounds.Y && ri.Bounds.Width == oldBounds.Width {
 					if _, ok := widget.(*ComboBox); ok {
 						if ri.Bounds.Height == oldBounds.Height+1 {
+							layoutStats.unchanged.Add(1)
 							continue
 						}
-					} else if ri.Bounds.Height == oldBounds.Height {
-						continue
 					}
 				}
 
+				if hdwp == 0 {
+					if hdwp = win.BeginDeferWindowPos(int32(len(result.items))); hdwp == 0 {
+						return lastError("BeginDeferWindowPos")
+					}
+				}
+
+				layoutStats.repositioned.Add(1)
+
 				if maybeInvalidate {
 					if ri.Bounds.Width == oldBounds.Width && ri.Bounds.Height == oldBounds.Height && (ri.Bounds.X != oldBounds.X || ri.Bounds.Y != oldBounds.Y) {
 						widget.Invalidate()
@@ -380,7 +498,7 @@ func applyLayoutResults(results []LayoutResult, stopwatch *stopwatch) error {
 			}
 		}
 
-		if !win.EndDeferWindowPos(hdwp) {
+		if hdwp != 0 && !win.EndDeferWindowPos(hdwp) {
 			return lastError("EndDeferWindowPos")
 		}
 	}
@@ -554,6 +672,15 @@ type HeightForWidther interface {
 	HeightForWidth(width int) int
 }
 
+// Baseliner is implemented by layout items that display text and can tell
+// where the baseline of its first line is.
+type Baseliner interface {
+	// Baseline returns the distance from the top of the item to the baseline
+	// of its first line of text when laid out at size. Input and output are
+	// in native pixels.
+	Baseline(size Size) int
+}
+
 type LayoutContext struct {
 	layoutItem2MinSizeEffective map[LayoutItem]Size // in native pixels
 	dpi                         int
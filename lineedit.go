@@ -7,7 +7,10 @@
 package walk
 
 import (
+	"strings"
 	"syscall"
+	"unicode"
+	"unicode/utf16"
 	"unsafe"
 )
 
@@ -36,6 +39,9 @@ type LineEdit struct {
 	charWidthFont            *Font
 	charWidth                int // in native pixels
 	textColor                Color
+	inputMask                *InputMask
+	autocomplete             *Autocomplete
+	highSurrogate            rune // first half of a surrogate pair from WM_CHAR
 }
 
 func newLineEdit(parent Window) (*LineEdit, error) {
@@ -50,6 +56,14 @@ func newLineEdit(parent Window) (*LineEdit, error) {
 		return nil, err
 	}
 
+	le.initLineEdit()
+
+	return le, nil
+}
+
+// initLineEdit adds the graphics effects and registers the properties of le.
+// It must be called after the window has been created.
+func (le *LineEdit) initLineEdit() {
 	le.GraphicsEffects().Add(InteractionEffect)
 	le.GraphicsEffects().Add(FocusEffect)
 
@@ -70,8 +84,6 @@ func newLineEdit(parent Window) (*LineEdit, error) {
 			return le.SetText(assertStringOr(v, ""))
 		},
 		le.textChangedPublisher.Event()))
-
-	return le, nil
 }
 
 func NewLineEdit(parent Container) (*LineEdit, error) {
@@ -132,9 +144,109 @@ func (le *LineEdit) Text() string {
 }
 
 func (le *LineEdit) SetText(value string) error {
+	if le.inputMask != nil {
+		value = le.inputMask.Format(value)
+	}
+
 	return le.setText(value)
 }
 
+// InputMask returns the mask the text is restricted to, if any.
+func (le *LineEdit) InputMask() *InputMask {
+	return le.inputMask
+}
+
+// SetInputMask restricts the text to mask, reformatting the current text, or
+// lifts the restriction if mask is nil.
+func (le *LineEdit) SetInputMask(mask *InputMask) error {
+	le.inputMask = mask
+
+	if mask == nil {
+		return nil
+	}
+
+	return le.setText(mask.Format(le.Text()))
+}
+
+// RawText returns the characters filled in the positions of the input mask,
+// without its literals and placeholders, or the text if there is no mask.
+func (le *LineEdit) RawText() string {
+	if le.inputMask == nil {
+		return le.Text()
+	}
+
+	return le.inputMask.Raw(le.Text())
+}
+
+// InputMaskComplete reports whether all positions of the input mask that are
+// not optional are filled. It is true if there is no mask.
+func (le *LineEdit) InputMaskComplete() bool {
+	if le.inputMask == nil {
+		return true
+	}
+
+	return le.inputMask.Complete(le.Text())
+}
+
+// Autocomplete returns the Autocomplete of the LineEdit, if any.
+func (le *LineEdit) Autocomplete() *Autocomplete {
+	return le.autocomplete
+}
+
+// SetAutocomplete makes the LineEdit offer the suggestions of source as
+// specified by options. A nil source disables the suggestions.
+func (le *LineEdit) SetAutocomplete(source AutocompleteSource, options AutocompleteOptions) error {
+	return setAutocomplete(&le.autocomplete, le.hWnd, source, options)
+}
+
+// editMasked replaces the selection with input, deleting the position before
+// or after the caret instead if input is empty and the selection is, and
+// updates the text and caret.
+func (le *LineEdit) editMasked(input string, deleteBackward bool) {
+	mask := le.inputMask
+	current := []rune(le.Text())
+	start, end := le.TextSelection()
+	start, end = runeIndex(current, start), runeIndex(current, end)
+	text := mask.normalize(current)
+
+	switch le.CaseMode() {
+	case CaseModeUpper:
+		input = strings.ToUpper(input)
+
+	case CaseModeLower:
+		input = strings.ToLower(input)
+	}
+
+	var caret int
+	switch {
+	case start < end:
+		mask.clear(text, start, end)
+		caret = mask.insert(text, start, []rune(input))
+		if input == "" {
+			caret = start
+		}
+
+	case input != "":
+		caret = mask.insert(text, start, []rune(input))
+
+	case deleteBackward:
+		caret = mask.previousSlot(start)
+		if caret < 0 {
+			return
+		}
+		mask.clear(text, caret, caret+1)
+
+	default:
+		caret = mask.skipLiterals(start)
+		mask.clear(text, caret, caret+1)
+		caret = start
+	}
+
+	le.setText(string(text))
+	caret = utf16Offset(text, caret)
+	le.SetTextSelection(caret, caret)
+}
+
 func (le *LineEdit) TextSelection() (start, end int) {
 	le.SendMessage(win.EM_GETSEL, uintptr(unsafe.Pointer(&start)), uintptr(unsafe.Pointer(&end)))
 	return
@@ -308,6 +420,9 @@ func (le *LineEdit) WndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) u
 	case win.WM_COMMAND:
 		switch win.HIWORD(uint32(wParam)) {
 		case win.EN_CHANGE:
+			if le.autocomplete != nil {
+				le.autocomplete.textChanged()
+			}
 			le.textChangedPublisher.Publish()
 		}
 
@@ -335,6 +450,24 @@ func (le *LineEdit) WndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) u
 
 		case KeyReturn:
 			le.editingFinishedPublisher.Publish()
+
+		case KeyDelete:
+			if le.inputMask != nil && !le.ReadOnly() {
+				le.editMasked("", false)
+				return 0
+			}
+		}
+
+	case win.WM_CHAR, win.WM_PASTE, win.WM_CUT, win.WM_CLEAR:
+		if le.inputMask != nil && !le.ReadOnly() {
+			if le.handleMaskedEdit(msg, wParam) {
+				return 0
+			}
+		}
+
+	case win.WM_DESTROY:
+		if le.autocomplete != nil {
+			le.autocomplete.release()
 		}
 
 	case win.WM_KILLFOCUS:
@@ -346,16 +479,79 @@ func (le *LineEdit) WndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) u
 	return le.WidgetBase.WndProc(hwnd, msg, wParam, lParam)
 }
 
+// handleMaskedEdit performs the edit requested by msg on the masked text and
+// reports whether it was handled.
+func (le *LineEdit) handleMaskedEdit(msg uint32, wParam uintptr) bool {
+	switch msg {
+	case win.WM_CHAR:
+		r := rune(wParam)
+
+		// Characters outside the BMP arrive as two WM_CHAR messages, one per
+		// half of their surrogate pair.
+		if utf16.IsSurrogate(r) && r < 0xDC00 {
+			le.highSurrogate = r
+			return true
+		}
+		if le.highSurrogate != 0 {
+			if pair := utf16.DecodeRune(le.highSurrogate, r); pair != unicode.ReplacementChar {
+				r = pair
+			}
+			le.highSurrogate = 0
+		}
+
+		switch {
+		case r == win.VK_BACK:
+			le.editMasked("", true)
+
+		case r < ' ':
+			// Control characters like Ctrl+V come back as messages like
+			// WM_PASTE.
+			return false
+
+		case r == 0x7F, utf16.IsSurrogate(r):
+			// Ctrl+Backspace, which the edit control would insert as a
+			// character, or a lone half of a surrogate pair.
+
+		default:
+			le.editMasked(string(r), false)
+		}
+
+	case win.WM_PASTE:
+		text, err := Clipboard().Text()
+		if err != nil {
+			return true
+		}
+		le.editMasked(text, false)
+
+	case win.WM_CUT:
+		le.SendMessage(win.WM_COPY, 0, 0)
+		le.editMasked("", false)
+
+	case win.WM_CLEAR:
+		le.editMasked("", false)
+	}
+
+	return true
+}
+
 func (le *LineEdit) CreateLayoutItem(ctx *LayoutContext) LayoutItem {
 	lf := ShrinkableHorz | GrowableHorz
 	if le.MaxLength() > lineEditGreedyLimit {
 		lf |= GreedyHorz
 	}
 
+	// The text of a single-line edit control starts at the top of its
+	// formatting rectangle, independent of the height of the control.
+	var rc win.RECT
+	le.SendMessage(win.EM_GETRECT, 0, uintptr(unsafe.Pointer(&rc)))
+
+	border := (le.SizePixels().Height - le.ClientBoundsPixels().Height) / 2
+
 	return &lineEditLayoutItem{
 		layoutFlags: lf,
 		idealSize:   le.sizeHintForLimit(lineEditGreedyLimit),
 		minSize:     le.sizeHintForLimit(lineEditMinChars),
+		baseline:    border + int(rc.Top) + le.fontAscent(),
 	}
 }
 
@@ -364,6 +560,11 @@ type lineEditLayoutItem struct {
 	layoutFlags LayoutFlags
 	idealSize   Size // in native pixels
 	minSize     Size // in native pixels
+	baseline    int  // in native pixels
+}
+
+func (li *lineEditLayoutItem) Baseline(size Size) int {
+	return li.baseline
 }
 
 func (li *lineEditLayoutItem) LayoutFlags() LayoutFlags {
@@ -7,16 +7,24 @@
 package walk
 
 import (
+	"strings"
+	"sync"
 	"syscall"
 	"unsafe"
 
 	"github.com/tailscale/win"
 )
 
+// LinkLabel displays text containing hyperlinks, using the SysLink control.
+//
+// Links are marked up inline with <a> tags, optionally carrying id and href
+// attributes, e.g. `Read the <a id="tos" href="https://example.com">terms</a>.`
+// Activating a link publishes the LinkActivated event.
 type LinkLabel struct {
 	WidgetBase
 	textChangedPublisher   EventPublisher
 	linkActivatedPublisher LinkLabelLinkEventPublisher
+	wordWrap               bool
 }
 
 func NewLinkLabel(parent Container) (*LinkLabel, error) {
@@ -26,7 +34,7 @@ func NewLinkLabel(parent Container) (*LinkLabel, error) {
 		ll,
 		parent,
 		"SysLink",
-		win.WS_TABSTOP|win.WS_VISIBLE,
+		win.WS_TABSTOP|win.WS_VISIBLE|win.LWS_TRANSPARENT|win.LWS_USEVISUALSTYLE,
 		0); err != nil {
 		return nil, err
 	}
@@ -63,6 +71,62 @@ func (ll *LinkLabel) SetText(value string) error {
 	return nil
 }
 
+// WordWrap returns whether the text is wrapped to the width assigned by the
+// layout.
+func (ll *LinkLabel) WordWrap() bool {
+	return ll.wordWrap
+}
+
+// SetWordWrap sets whether the text is wrapped to the width assigned by the
+// layout. If disabled, the LinkLabel keeps its ideal size, which only wraps
+// at MaxSize.
+func (ll *LinkLabel) SetWordWrap(wordWrap bool) {
+	if wordWrap == ll.wordWrap {
+		return
+	}
+
+	ll.wordWrap = wordWrap
+
+	ll.RequestLayout()
+}
+
+// Links returns all links currently contained in the text.
+func (ll *LinkLabel) Links() []*LinkLabelLink {
+	var links []*LinkLabelLink
+
+	for i := 0; ; i++ {
+		li := win.LITEM{
+			Mask:  win.LIF_ITEMINDEX | win.LIF_ITEMID | win.LIF_URL,
+			ILink: int32(i),
+		}
+
+		if win.TRUE != ll.SendMessage(win.LM_GETITEM, 0, uintptr(unsafe.Pointer(&li))) {
+			break
+		}
+
+		links = append(links, &LinkLabelLink{
+			ll:    ll,
+			index: i,
+			id:    syscall.UTF16ToString(li.SzID[:]),
+			url:   syscall.UTF16ToString(li.SzUrl[:]),
+		})
+	}
+
+	return links
+}
+
+// LinkById returns the link with the specified id attribute, or nil if there
+// is none.
+func (ll *LinkLabel) LinkById(id string) *LinkLabelLink {
+	for _, link := range ll.Links() {
+		if link.id == id {
+			return link
+		}
+	}
+
+	return nil
+}
+
 func (ll *LinkLabel) LinkActivated() *LinkLabelLinkEvent {
 	return ll.linkActivatedPublisher.Event()
 }
@@ -222,17 +286,71 @@ func (ll *LinkLabel) CreateLayoutItem(ctx *LayoutContext) LayoutItem {
 	var s win.SIZE
 	ll.SendMessage(win.LM_GETIDEALSIZE, uintptr(ll.IntFrom96DPI(ll.maxSize96dpi.Width)), uintptr(unsafe.Pointer(&s)))
 
-	return &linkLabelLayoutItem{
+	li := &linkLabelLayoutItem{
 		idealSize: sizeFromSIZE(s),
+		wordWrap:  ll.wordWrap,
+	}
+
+	if ll.wordWrap {
+		li.width2Height = make(map[int]int)
+		li.text = linkLabelPlainText(ll.Text())
+		li.font = ll.Font()
+		li.minWidth = ll.calculateTextSizeImpl("W").Width
+	}
+
+	return li
+}
+
+// linkLabelPlainText returns text with the SysLink <a> markup removed.
+func linkLabelPlainText(text string) string {
+	var sb strings.Builder
+
+	for {
+		i := strings.IndexByte(text, '<')
+		if i < 0 {
+			break
+		}
+
+		sb.WriteString(text[:i])
+		text = text[i:]
+
+		lower := strings.ToLower(text)
+		if strings.HasPrefix(lower, "</a>") {
+			text = text[len("</a>"):]
+			continue
+		}
+		if strings.HasPrefix(lower, "<a>") || strings.HasPrefix(lower, "<a ") {
+			if j := strings.IndexByte(text, '>'); j >= 0 {
+				text = text[j+1:]
+				continue
+			}
+		}
+
+		sb.WriteByte('<')
+		text = text[1:]
 	}
+
+	sb.WriteString(text)
+
+	return sb.String()
 }
 
 type linkLabelLayoutItem struct {
 	LayoutItemBase
-	idealSize Size // in native pixels
+	idealSize    Size // in native pixels
+	wordWrap     bool
+	mutex        sync.Mutex
+	width2Height map[int]int // in native pixels
+	text         string
+	font         *Font
+	minWidth     int // in native pixels
 }
 
-func (*linkLabelLayoutItem) LayoutFlags() LayoutFlags {
+func (li *linkLabelLayoutItem) LayoutFlags() LayoutFlags {
+	if li.wordWrap {
+		return ShrinkableHorz | GrowableHorz | GrowableVert
+	}
+
 	return 0
 }
 
@@ -241,5 +359,28 @@ func (li *linkLabelLayoutItem) IdealSize() Size {
 }
 
 func (li *linkLabelLayoutItem) MinSize() Size {
+	if li.wordWrap {
+		return Size{li.minWidth, li.HeightForWidth(li.minWidth)}
+	}
+
 	return li.idealSize
 }
+
+func (li *linkLabelLayoutItem) HasHeightForWidth() bool {
+	return li.wordWrap
+}
+
+func (li *linkLabelLayoutItem) HeightForWidth(width int) int {
+	li.mutex.Lock()
+	defer li.mutex.Unlock()
+
+	if height, ok := li.width2Height[width]; ok {
+		return height
+	}
+
+	size := calculateTextSize(li.text, li.font, li.ctx.dpi, width, li.handle)
+
+	li.width2Height[width] = size.Height
+
+	return size.Height
+}
@@ -40,6 +40,7 @@ type ListBox struct {
 	currentIndexChangedPublisher    EventPublisher
 	selectedIndexesChangedPublisher EventPublisher
 	itemActivatedPublisher          EventPublisher
+	search                          itemSearch
 	themeNormalBGColor              Color
 	themeNormalTextColor            Color
 	themeSelectedBGColor            Color
@@ -639,6 +640,40 @@ func (lb *ListBox) ItemActivated() *Event {
 	return lb.itemActivatedPublisher.Event()
 }
 
+// ItemSearching returns the event that is published when the user typed to
+// search for an item. The text is to be searched for starting at the item at
+// index start, wrapping around. Handlers of models that can find items faster
+// than by comparing the text of each item, e.g. using an index, set index to
+// the item found.
+func (lb *ListBox) ItemSearching() *ItemSearchEvent {
+	return lb.search.publisher.Event()
+}
+
+// handleSearchChar selects the first item matching the text typed so far and
+// returns whether ch was used for searching.
+func (lb *ListBox) handleSearchChar(ch rune) bool {
+	if lb.model == nil {
+		return false
+	}
+
+	index, handled := lb.search.handleChar(ch, lb.CurrentIndex(), lb.model.ItemCount(), lb.itemString)
+	if !handled {
+		return false
+	}
+
+	if index > -1 {
+		if lb.hasStyleBits(win.LBS_EXTENDEDSEL) || lb.hasStyleBits(win.LBS_MULTIPLESEL) {
+			lb.SetSelectedIndexes([]int{index})
+			lb.SendMessage(win.LB_SETCARETINDEX, uintptr(index), 0)
+		} else {
+			lb.SetCurrentIndex(index)
+			lb.selectedIndexesChangedPublisher.Publish()
+		}
+	}
+
+	return true
+}
+
 func (lb *ListBox) WndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) uintptr {
 	switch msg {
 	case win.WM_MEASUREITEM:
@@ -837,6 +872,11 @@ func (lb *ListBox) WndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) ui
 		if uint32(lParam)>>30 == 0 && Key(wParam) == KeyReturn && lb.CurrentIndex() > -1 {
 			lb.itemActivatedPublisher.Publish()
 		}
+
+	case win.WM_CHAR:
+		if lb.handleSearchChar(rune(wParam)) {
+			return 0
+		}
 	}
 
 	return lb.WidgetBase.WndProc(hwnd, msg, wParam, lParam)
@@ -31,6 +31,7 @@ type MainWindow struct {
 	windowPlacement     *win.WINDOWPLACEMENT
 	menu                *Menu
 	toolBar             *ToolBar
+	ribbon              *Ribbon
 	statusBar           *StatusBar
 	exitCode            int
 	exitOnCloseDisabled bool
@@ -136,6 +137,12 @@ func (mw *MainWindow) SetToolBar(tb *ToolBar) {
 	mw.toolBar = tb
 }
 
+// Ribbon returns the Ribbon created for the MainWindow using NewRibbon, if
+// any.
+func (mw *MainWindow) Ribbon() *Ribbon {
+	return mw.ribbon
+}
+
 func (mw *MainWindow) StatusBar() *StatusBar {
 	return mw.statusBar
 }
@@ -143,6 +150,11 @@ func (mw *MainWindow) StatusBar() *StatusBar {
 func (mw *MainWindow) ClientBoundsPixels() Rectangle {
 	bounds := mw.FormBase.ClientBoundsPixels()
 
+	if mw.ribbon != nil {
+		bounds.Y += mw.ribbon.HeightPixels()
+		bounds.Height -= mw.ribbon.HeightPixels()
+	}
+
 	if mw.toolBar != nil && mw.toolBar.Actions().Len() > 0 {
 		tlbBounds := mw.toolBar.BoundsPixels()
 
@@ -248,7 +260,12 @@ func (mw *MainWindow) WndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr)
 		cb := mw.ClientBoundsPixels()
 
 		if mw.toolBar != nil {
-			bounds := Rectangle{0, 0, cb.Width, mw.toolBar.HeightPixels()}
+			var y int
+			if mw.ribbon != nil {
+				y = mw.ribbon.HeightPixels()
+			}
+
+			bounds := Rectangle{0, y, cb.Width, mw.toolBar.HeightPixels()}
 			if mw.toolBar.BoundsPixels() != bounds {
 				mw.toolBar.SetBoundsPixels(bounds)
 			}
@@ -258,16 +275,24 @@ func (mw *MainWindow) WndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr)
 		if mw.statusBar.BoundsPixels() != bounds {
 			mw.statusBar.SetBoundsPixels(bounds)
 		}
+	case win.WM_DPICHANGED:
+		result := mw.FormBase.WndProc(hwnd, msg, wParam, lParam)
+
+		// Owner-drawn menu items refer to theme metrics and bitmaps for the
+		// previous DPI.
+		mw.menu.updateItemsForWindow(mw)
+		win.DrawMenuBar(mw.hWnd)
+
+		return result
+
 	case win.WM_CLOSE:
 		// Ensure that all Closing event handlers have executed *before* we set
-		// the exit code.
-		if mw.FormBase.WndProc(hwnd, msg, wParam, lParam) == 0 {
-			if !mw.exitOnCloseDisabled {
-				mw.Dispose()
-				App().Exit(mw.exitCode)
-			}
-			return 0
+		// the exit code. FormBase returns non-zero if one of them canceled.
+		if mw.FormBase.WndProc(hwnd, msg, wParam, lParam) == 0 && !mw.exitOnCloseDisabled {
+			mw.Dispose()
+			App().Exit(mw.exitCode)
 		}
+		return 0
 	}
 
 	return mw.FormBase.WndProc(hwnd, msg, wParam, lParam)
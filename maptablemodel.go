@@ -16,6 +16,8 @@ type mapTableModel struct {
 	dataMembers []string
 	dataSource  interface{}
 	items       []map[string]interface{}
+	filter      func(value func(col int) interface{}) bool
+	rows        []int // indexes of the items the filter accepts
 }
 
 func newMapTableModel(dataSource interface{}) (TableModel, error) {
@@ -32,23 +34,95 @@ func (m *mapTableModel) setDataMembers(dataMembers []string) {
 }
 
 func (m *mapTableModel) RowCount() int {
+	if m.filter != nil {
+		return len(m.rows)
+	}
+
 	return len(m.items)
 }
 
+// itemIndex returns the index of the item displayed in row.
+func (m *mapTableModel) itemIndex(row int) int {
+	if m.filter != nil {
+		return m.rows[row]
+	}
+
+	return row
+}
+
 func (m *mapTableModel) Value(row, col int) interface{} {
-	if m.items[row] == nil {
+	return m.itemValue(m.itemIndex(row), col)
+}
+
+func (m *mapTableModel) itemValue(index, col int) interface{} {
+	if m.items[index] == nil {
 		if populator, ok := m.dataSource.(Populator); ok {
-			if err := populator.Populate(row); err != nil {
+			if err := populator.Populate(index); err != nil {
 				return err
 			}
 		}
 
-		if m.items[row] == nil {
+		if m.items[index] == nil {
 			return nil
 		}
 	}
 
-	return m.items[row][m.dataMembers[col]]
+	return m.items[index][m.dataMembers[col]]
+}
+
+func (m *mapTableModel) SetValue(row, col int, value interface{}) error {
+	index := m.itemIndex(row)
+
+	if setter, ok := m.dataSource.(CellValueSetter); ok {
+		return setter.SetValue(index, col, value)
+	}
+
+	if m.items[index] == nil {
+		return newError("item not populated")
+	}
+
+	m.items[index][m.dataMembers[col]] = value
+
+	return nil
+}
+
+func (m *mapTableModel) ColumnFilterable(col int) bool {
+	return true
+}
+
+func (m *mapTableModel) ColumnValues(col int) []interface{} {
+	values := make([]interface{}, len(m.items))
+	for i := range values {
+		values[i] = m.itemValue(i, col)
+	}
+
+	return values
+}
+
+func (m *mapTableModel) Filter(accept func(value func(col int) interface{}) bool) error {
+	m.filter = accept
+
+	m.updateRows()
+
+	m.PublishRowsReset()
+
+	return nil
+}
+
+// updateRows collects the indexes of the items the filter accepts, leaving
+// the items themselves in place.
+func (m *mapTableModel) updateRows() {
+	m.rows = nil
+	if m.filter == nil {
+		return
+	}
+
+	m.rows = make([]int, 0, len(m.items))
+	for i := range m.items {
+		if m.filter(func(col int) interface{} { return m.itemValue(i, col) }) {
+			m.rows = append(m.rows, i)
+		}
+	}
 }
 
 func (m *mapTableModel) Sort(col int, order SortOrder) error {
@@ -56,19 +130,21 @@ func (m *mapTableModel) Sort(col int, order SortOrder) error {
 
 	sort.Stable(m)
 
+	m.updateRows()
+
 	m.changedPublisher.Publish()
 
 	return nil
 }
 
 func (m *mapTableModel) Len() int {
-	return m.RowCount()
+	return len(m.items)
 }
 
 func (m *mapTableModel) Less(i, j int) bool {
 	col := m.SortedColumn()
 
-	return less(m.Value(i, col), m.Value(j, col), m.SortOrder())
+	return less(m.itemValue(i, col), m.itemValue(j, col), m.SortOrder())
 }
 
 func (m *mapTableModel) Swap(i, j int) {
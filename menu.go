@@ -24,6 +24,7 @@ type Menu struct {
 	sharedMetrics              *menuSharedMetrics  // shared theme metrics across all menus associated with the current window
 	perMenuMetrics             menuSpecificMetrics // per-menu metrics
 	allowOwnerDrawInvalidation bool
+	action2ImagePins           map[*Action]iconCachePins // cached images in use by menu items
 }
 
 func newMenuBar(window Window) (menu *Menu, _ error) {
@@ -118,6 +119,10 @@ func (m *Menu) InitPopup() *Event {
 func (m *Menu) Dispose() {
 	m.actions.Clear()
 
+	for action := range m.action2ImagePins {
+		m.setImagePins(action, nil)
+	}
+
 	if m.hMenu != 0 {
 		win.DestroyMenu(m.hMenu)
 		m.hMenu = 0
@@ -212,7 +217,9 @@ func (m *Menu) resolveDPI() int {
 	}
 }
 
-func (m *Menu) initMenuItemInfoFromAction(mii *win.MENUITEMINFO, action *Action) {
+// initMenuItemInfoFromAction initializes mii from action. If the bitmap of the
+// menu item comes from the IconCache, it is added to pins.
+func (m *Menu) initMenuItemInfoFromAction(mii *win.MENUITEMINFO, action *Action, pins *iconCachePins) {
 	mii.CbSize = uint32(unsafe.Sizeof(*mii))
 	mii.FMask = win.MIIM_FTYPE | win.MIIM_ID | win.MIIM_STATE | win.MIIM_DATA
 	mii.FType = 0
@@ -236,7 +243,7 @@ func (m *Menu) initMenuItemInfoFromAction(mii *win.MENUITEMINFO, action *Action)
 	case action.image != nil:
 		mii.FMask |= win.MIIM_BITMAP
 		dpi := m.resolveDPI()
-		if bmp, err := iconCache.Bitmap(action.image, dpi); err == nil {
+		if bmp, err := iconCache.pinnedBitmap(pins, action.image, dpi); err == nil {
 			mii.HbmpItem = bmp.hBmp
 		}
 	case action.IsSeparator():
@@ -278,6 +285,23 @@ func (m *Menu) initMenuItemInfoFromAction(mii *win.MENUITEMINFO, action *Action)
 	}
 }
 
+// setImagePins replaces the IconCache entries pinned for the menu item of
+// action with pins.
+func (m *Menu) setImagePins(action *Action, pins iconCachePins) {
+	old := m.action2ImagePins[action]
+	iconCache.unpin(&old)
+
+	if len(pins) == 0 {
+		delete(m.action2ImagePins, action)
+		return
+	}
+
+	if m.action2ImagePins == nil {
+		m.action2ImagePins = make(map[*Action]iconCachePins)
+	}
+	m.action2ImagePins[action] = pins
+}
+
 func (m *Menu) handleDefaultState(action *Action) {
 	if action.Default() {
 		// Unset other default actions before we set this one. Otherwise insertion fails.
@@ -300,13 +324,17 @@ func (m *Menu) onActionChanged(action *Action) error {
 	}
 
 	var mii win.MENUITEMINFO
+	var pins iconCachePins
 
-	m.initMenuItemInfoFromAction(&mii, action)
+	m.initMenuItemInfoFromAction(&mii, action, &pins)
 
 	if !win.SetMenuItemInfo(m.hMenu, uint32(m.actions.indexInObserver(action)), true, &mii) {
+		iconCache.unpin(&pins)
 		return newError("SetMenuItemInfo failed")
 	}
 
+	m.setImagePins(action, pins)
+
 	if action.Default() {
 		win.SetMenuDefaultItem(m.hMenu, uint32(m.actions.indexInObserver(action)), true)
 	}
@@ -360,13 +388,17 @@ func (m *Menu) insertAction(action *Action, visibleChanged bool) (err error) {
 	index := m.actions.indexInObserver(action)
 
 	var mii win.MENUITEMINFO
+	var pins iconCachePins
 
-	m.initMenuItemInfoFromAction(&mii, action)
+	m.initMenuItemInfoFromAction(&mii, action, &pins)
 
 	if !win.InsertMenuItem(m.hMenu, uint32(index), true, &mii) {
+		iconCache.unpin(&pins)
 		return newError("InsertMenuItem failed")
 	}
 
+	m.setImagePins(action, pins)
+
 	if action.Default() {
 		win.SetMenuDefaultItem(m.hMenu, uint32(m.actions.indexInObserver(action)), true)
 	}
@@ -388,6 +420,8 @@ func (m *Menu) removeAction(action *Action, visibleChanged bool) error {
 		return lastError("RemoveMenu")
 	}
 
+	m.setImagePins(action, nil)
+
 	if !visibleChanged {
 		action.removeChangedHandler(m)
 	}
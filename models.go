@@ -526,6 +526,86 @@ type ItemChecker interface {
 	SetChecked(index int, checked bool) error
 }
 
+// CellValueSetter is the interface that a model must implement to support
+// editing cells in a widget like TableView.
+type CellValueSetter interface {
+	// SetValue sets the value of the cell specified by row and col.
+	SetValue(row, col int, value interface{}) error
+}
+
+// RowMover is the interface that a TableModel must implement to support
+// reordering items by drag and drop in a TableView.
+type RowMover interface {
+	// CanMoveRows returns whether the items at rows, in ascending order, may
+	// be moved to index.
+	//
+	// index refers to the items before the move, so the items are to be
+	// inserted before the item currently at index, or appended if index
+	// equals RowCount.
+	CanMoveRows(rows []int, index int) bool
+
+	// MoveRows moves the items at rows to index, as described for
+	// CanMoveRows, keeping their order.
+	//
+	// MoveRows must publish the events returned from RowsRemoved and
+	// RowsInserted, or RowsReset, after moving.
+	MoveRows(rows []int, index int) error
+}
+
+// Filterer is the interface that a model must implement to support filtering
+// in a widget like TableView.
+//
+// The reflect based models TableView creates for slices implement Filterer.
+type Filterer interface {
+	// ColumnFilterable returns whether items can be filtered by the values
+	// of col.
+	ColumnFilterable(col int) bool
+
+	// ColumnValues returns the values of col of all items, including hidden
+	// ones.
+	ColumnValues(col int) []interface{}
+
+	// Filter hides all items for which accept returns false, or shows all
+	// items if accept is nil. accept is passed a function that returns the
+	// value of a column of the item to be tested.
+	//
+	// Implementations must keep applying accept as items change and publish
+	// RowsReset after filtering.
+	Filter(accept func(value func(col int) interface{}) bool) error
+}
+
+// ItemGroup describes how a group of items is presented in a widget like
+// TableView.
+type ItemGroup struct {
+	// Title is displayed in the header of the group.
+	Title string
+
+	// Subtitle is displayed below the title.
+	Subtitle string
+
+	// TaskLink is displayed as a link at the end of the header.
+	TaskLink string
+
+	// Collapsible specifies whether the user can collapse the group.
+	Collapsible bool
+
+	// Collapsed specifies whether the group is initially collapsed.
+	Collapsed bool
+}
+
+// Grouper is the interface that a model must implement to organize its items
+// into groups in a widget like TableView.
+type Grouper interface {
+	// GroupBy returns the key of the group that the item at index belongs to.
+	//
+	// Groups are displayed in the order in which their first item appears in
+	// the model.
+	GroupBy(index int) string
+
+	// Group returns how the group with the specified key is presented.
+	Group(key string) ItemGroup
+}
+
 // SortOrder specifies the order by which items are sorted.
 type SortOrder int
 
@@ -660,6 +740,32 @@ type TreeModel interface {
 	ItemRemoved() *TreeItemEvent
 }
 
+// TreeTableModel provides widgets like TreeTableView with a hierarchy of
+// items that have values in multiple columns.
+type TreeTableModel interface {
+	TreeModel
+
+	// Value returns the value to display for item in the column at index col.
+	Value(item TreeItem, col int) interface{}
+}
+
+// TreeItemMover is the interface that a TreeModel must implement to support
+// reordering items by drag and drop in a TreeView.
+type TreeItemMover interface {
+	// CanMoveItem returns whether item may be moved to newParent, or to the
+	// roots if newParent is nil, at index.
+	//
+	// index refers to the children of newParent before the move, so item is
+	// to be inserted before the child currently at index.
+	CanMoveItem(item, newParent TreeItem, index int) bool
+
+	// MoveItem moves item to newParent at index, as described for CanMoveItem.
+	//
+	// MoveItem must publish the events returned from ItemRemoved and
+	// ItemInserted, or ItemsReset, after moving.
+	MoveItem(item, newParent TreeItem, index int) error
+}
+
 // TreeModelBase partially implements the TreeModel interface.
 //
 // You still need to provide your own implementation of at least the
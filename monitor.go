@@ -0,0 +1,218 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"syscall"
+	"unsafe"
+
+	"github.com/tailscale/win"
+)
+
+const (
+	displayChangedTimerId     = 0x44495350 // "DISP"
+	displayChangedTimerElapse = 250        // in milliseconds
+)
+
+// Monitor is a display monitor. It holds the state the monitor had when the
+// Monitor was obtained, so it should be obtained again once the
+// Application.DisplayChanged event is published.
+//
+// Coordinates are in native pixels, relative to the top left corner of the
+// primary monitor.
+type Monitor struct {
+	handle      win.HMONITOR
+	deviceName  string
+	name        string
+	bounds      Rectangle
+	workArea    Rectangle
+	dpi         int
+	primary     bool
+	refreshRate int
+}
+
+var monitorsCallbackPtr uintptr
+
+func init() {
+	AppendToWalkInit(func() {
+		monitorsCallbackPtr = syscall.NewCallback(monitorsCallback)
+	})
+}
+
+func monitorsCallback(hMonitor win.HMONITOR, hdc win.HDC, rc *win.RECT, lParam uintptr) uintptr {
+	monitors := (*[]*Monitor)(unsafe.Pointer(lParam))
+
+	if m := newMonitor(hMonitor); m != nil {
+		*monitors = append(*monitors, m)
+	}
+
+	return 1
+}
+
+// Monitors returns the monitors that are part of the desktop.
+func Monitors() []*Monitor {
+	var monitors []*Monitor
+	enumDisplayMonitors(monitorsCallbackPtr, uintptr(unsafe.Pointer(&monitors)))
+
+	return monitors
+}
+
+// PrimaryMonitor returns the primary monitor, which holds the taskbar and
+// whose top left corner is the origin of the desktop.
+func PrimaryMonitor() *Monitor {
+	return MonitorFromPoint(Point{})
+}
+
+// MonitorFromPoint returns the monitor that contains pt, or the one nearest
+// to it.
+func MonitorFromPoint(pt Point) *Monitor {
+	return newMonitor(monitorFromPoint(pt.toPOINT(), win.MONITOR_DEFAULTTONEAREST))
+}
+
+// MonitorFromRectangle returns the monitor that has the largest intersection
+// with r, or the one nearest to it.
+func MonitorFromRectangle(r Rectangle) *Monitor {
+	rc := r.toRECT()
+
+	return newMonitor(monitorFromRect(&rc, win.MONITOR_DEFAULTTONEAREST))
+}
+
+// MonitorFromWindow returns the monitor that has the largest intersection with
+// window, or the one nearest to it.
+func MonitorFromWindow(window Window) *Monitor {
+	return newMonitor(win.MonitorFromWindow(window.Handle(), win.MONITOR_DEFAULTTONEAREST))
+}
+
+func newMonitor(hMonitor win.HMONITOR) *Monitor {
+	var mi monitorInfoEx
+	mi.CbSize = uint32(unsafe.Sizeof(mi))
+
+	if !getMonitorInfoEx(hMonitor, &mi) {
+		return nil
+	}
+
+	m := &Monitor{
+		handle:     hMonitor,
+		deviceName: win.UTF16PtrToString(&mi.szDevice[0]),
+		bounds:     rectangleFromRECT(mi.RcMonitor),
+		workArea:   rectangleFromRECT(mi.RcWork),
+		dpi:        getDpiForMonitor(hMonitor),
+		primary:    mi.DwFlags&win.MONITORINFOF_PRIMARY != 0,
+	}
+
+	if m.dpi == 0 {
+		m.dpi = screenDPI()
+	}
+
+	// The first device of the display adapter is the monitor attached to it.
+	dd := displayDevice{cb: uint32(unsafe.Sizeof(displayDevice{}))}
+	if enumDisplayDevices(&mi.szDevice[0], 0, &dd, 0) {
+		m.name = win.UTF16PtrToString(&dd.deviceString[0])
+	}
+	if m.name == "" {
+		m.name = m.deviceName
+	}
+
+	dm := win.DEVMODE{DmSize: uint16(unsafe.Sizeof(win.DEVMODE{}))}
+	if enumDisplaySettings(&mi.szDevice[0], _ENUM_CURRENT_SETTINGS, &dm) && dm.DmDisplayFrequency > 1 {
+		// 0 and 1 mean the hardware default.
+		m.refreshRate = int(dm.DmDisplayFrequency)
+	}
+
+	return m
+}
+
+// Handle returns the handle of the Monitor.
+func (m *Monitor) Handle() win.HMONITOR {
+	return m.handle
+}
+
+// DeviceName returns the name of the display device of the Monitor, e.g.
+// \\.\DISPLAY1.
+func (m *Monitor) DeviceName() string {
+	return m.deviceName
+}
+
+// Name returns the name of the Monitor for display to the user, as reported
+// by its driver, or its device name if the driver reports none.
+func (m *Monitor) Name() string {
+	return m.name
+}
+
+// Bounds returns the bounds of the Monitor, in native pixels.
+func (m *Monitor) Bounds() Rectangle {
+	return m.bounds
+}
+
+// WorkArea returns the part of the bounds of the Monitor that is not covered
+// by the taskbar and docked toolbars, in native pixels.
+func (m *Monitor) WorkArea() Rectangle {
+	return m.workArea
+}
+
+// DPI returns the effective DPI of the Monitor, which includes the scale
+// factor chosen by the user.
+func (m *Monitor) DPI() int {
+	return m.dpi
+}
+
+// Primary returns whether the Monitor is the primary monitor.
+func (m *Monitor) Primary() bool {
+	return m.primary
+}
+
+// RefreshRate returns the refresh rate of the Monitor in Hz, or 0 if it is
+// unknown.
+func (m *Monitor) RefreshRate() int {
+	return m.refreshRate
+}
+
+// DisplayChanged returns the event that is published when monitors were added
+// or removed, or when their arrangement, resolution, scale factor or work area
+// changed. Bursts of changes, as when a laptop is docked, are published once.
+// It must be called from the main goroutine.
+func (app *Application) DisplayChanged() *Event {
+	app.ensureSessionWindow()
+
+	if !app.watchingDisplay {
+		app.watchingDisplay = true
+		app.monitors = Monitors()
+	}
+
+	return app.displayChangedPublisher.Event()
+}
+
+// scheduleDisplayChanged checks for display changes once no further
+// notifications arrived for a moment.
+func (app *Application) scheduleDisplayChanged() {
+	if !app.watchingDisplay {
+		return
+	}
+
+	win.SetTimer(app.sessionWindow, displayChangedTimerId, displayChangedTimerElapse, 0)
+}
+
+// checkDisplayChanged publishes DisplayChanged if the monitors differ from
+// the ones seen last. Notifications like WM_DEVICECHANGE are also sent for
+// devices other than monitors.
+func (app *Application) checkDisplayChanged() {
+	win.KillTimer(app.sessionWindow, displayChangedTimerId)
+
+	monitors := Monitors()
+
+	changed := len(monitors) != len(app.monitors)
+	for i := 0; !changed && i < len(monitors); i++ {
+		changed = *monitors[i] != *app.monitors[i]
+	}
+
+	app.monitors = monitors
+
+	if changed {
+		app.displayChangedPublisher.Publish()
+	}
+}
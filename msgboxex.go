@@ -0,0 +1,148 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"unsafe"
+
+	"github.com/tailscale/win"
+	"golang.org/x/sys/windows"
+)
+
+// MsgBoxExButton describes a button of a message box shown using MsgBoxEx.
+type MsgBoxExButton struct {
+	Text    string // The caption of the button.
+	Default bool   // true to make this button the one that is clicked by Enter.
+	Cancel  bool   // true to report this button if the message box is dismissed using Escape, Alt+F4 or the close button.
+}
+
+// MsgBoxExOpts contains the options used by MsgBoxEx.
+type MsgBoxExOpts struct {
+	Owner            Form                 // Optional owner for the message box.
+	Title            string               // Title bar text.
+	Instruction      string               // Optional heading shown above Message.
+	Message          string               // Main text. It may contain hyperlinks of the form <a href="url">text</a>.
+	Buttons          []MsgBoxExButton     // The buttons, from left to right. A single OK button is shown if empty.
+	Icon             Image                // Optional custom Image to use as icon.
+	IconSystem       TaskDialogSystemIcon // Predefined icon to use. Ignored if Icon is non-nil.
+	DontAskAgainText string               // When non-empty, a "don't ask again" checkbox labelled with this text is shown.
+	LinkClicked      func(url string)     // Called when a hyperlink in Message is clicked. If nil, the url is opened using its default handler.
+}
+
+// MsgBoxExResult describes how a message box shown using MsgBoxEx was closed.
+type MsgBoxExResult struct {
+	// Button is the index of the clicked button in MsgBoxExOpts.Buttons. If
+	// the message box was dismissed, it is the index of the button designated
+	// as Cancel, or -1 if there is none.
+	Button int
+
+	// DontAskAgain is the state of the "don't ask again" checkbox.
+	DontAskAgain bool
+}
+
+// MsgBoxEx shows a message box with custom buttons and waits for it to be
+// closed. Unlike MsgBox, it supports custom button captions, arbitrary icons,
+// a "don't ask again" checkbox and hyperlinks in the message. Its title bar
+// follows the dark mode setting of the system.
+//
+// It must be called from the UI goroutine.
+func MsgBoxEx(opts MsgBoxExOpts) (MsgBoxExResult, error) {
+	result := MsgBoxExResult{Button: -1}
+
+	buttons := opts.Buttons
+	if len(buttons) == 0 {
+		buttons = []MsgBoxExButton{{Text: tr("OK", "walk"), Default: true, Cancel: true}}
+	}
+
+	tdOpts := TaskDialogOpts{
+		Owner:            opts.Owner,
+		Title:            opts.Title,
+		IconImage:        opts.Icon,
+		IconSystem:       opts.IconSystem,
+		Instruction:      opts.Instruction,
+		Content:          opts.Message,
+		CustomButtons:    make([]TaskDialogCustomButton, len(buttons)),
+		DefaultButton:    TaskDialogDefaultButtonCustom,
+		VerificationText: opts.DontAskAgainText,
+		AllowHyperlinks:  true,
+	}
+
+	clicked := -1
+	for i, btn := range buttons {
+		tdb := &tdOpts.CustomButtons[i]
+		tdb.MainText = btn.Text
+		tdb.Default = btn.Default
+
+		i := i
+		tdb.Clicked().Attach(func() bool {
+			clicked = i
+			return false
+		})
+	}
+
+	td := NewTaskDialog()
+
+	td.Created().Attach(func() {
+		if hwnd := td.(*taskDialog).hwnd; hwnd != 0 && systemUsesDarkMode() {
+			useDarkMode := win.BOOL(1)
+			windows.DwmSetWindowAttribute(windows.HWND(hwnd), windows.DWMWA_USE_IMMERSIVE_DARK_MODE, unsafe.Pointer(&useDarkMode), uint32(unsafe.Sizeof(useDarkMode)))
+		}
+	})
+
+	td.HyperlinkClicked().Attach(func(url string) bool {
+		if opts.LinkClicked != nil {
+			opts.LinkClicked(url)
+		} else {
+			openURL(url)
+		}
+		return true
+	})
+
+	tdResult, err := td.Show(tdOpts)
+	if err != nil {
+		return result, err
+	}
+
+	if tdResult.Checked != nil {
+		result.DontAskAgain = *tdResult.Checked
+	}
+
+	if clicked >= 0 && !tdResult.Canceled {
+		result.Button = clicked
+		return result, nil
+	}
+
+	for i, btn := range buttons {
+		if btn.Cancel {
+			result.Button = i
+			break
+		}
+	}
+
+	return result, nil
+}
+
+// systemUsesDarkMode returns whether the user chose the dark mode for
+// applications in the Windows settings.
+func systemUsesDarkMode() bool {
+	value, err := RegistryKeyUint32(CurrentUserKey(), `Software\Microsoft\Windows\CurrentVersion\Themes\Personalize`, "AppsUseLightTheme")
+
+	return err == nil && value == 0
+}
+
+// openURL opens url using the handler registered for its scheme.
+func openURL(url string) {
+	url16, err := windows.UTF16PtrFromString(url)
+	if err != nil {
+		return
+	}
+
+	verb16, _ := windows.UTF16PtrFromString("open")
+
+	win.ShellExecute(0, verb16, url16, nil, nil, win.SW_SHOWNORMAL)
+}
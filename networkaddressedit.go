@@ -0,0 +1,169 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"net/netip"
+	"strings"
+
+	"github.com/tailscale/win"
+)
+
+// NetworkAddressKind specifies what a NetworkAddressEdit accepts.
+type NetworkAddressKind int
+
+const (
+	// NetworkAddressAddr accepts an IPv4 or IPv6 address, e.g. "192.0.2.1"
+	// or "2001:db8::1".
+	NetworkAddressAddr NetworkAddressKind = iota
+
+	// NetworkAddressPrefix accepts an address with prefix length in CIDR
+	// notation, e.g. "192.0.2.0/24" or "2001:db8::/32".
+	NetworkAddressPrefix
+
+	// NetworkAddressAddrPort accepts an address and port, e.g.
+	// "192.0.2.1:443" or "[2001:db8::1]:443".
+	NetworkAddressAddrPort
+)
+
+func (kind NetworkAddressKind) example() string {
+	switch kind {
+	case NetworkAddressPrefix:
+		return "192.0.2.0/24"
+
+	case NetworkAddressAddrPort:
+		return "192.0.2.1:443"
+	}
+
+	return "192.0.2.1"
+}
+
+// parseNetworkAddress parses text according to kind and returns the address
+// part of the result.
+func parseNetworkAddress(text string, kind NetworkAddressKind) (netip.Addr, error) {
+	text = strings.TrimSpace(text)
+
+	switch kind {
+	case NetworkAddressPrefix:
+		prefix, err := netip.ParsePrefix(text)
+		return prefix.Addr(), err
+
+	case NetworkAddressAddrPort:
+		addrPort, err := netip.ParseAddrPort(text)
+		return addrPort.Addr(), err
+	}
+
+	return netip.ParseAddr(text)
+}
+
+// NetworkAddressEdit is a LineEdit that accepts IPv4 and IPv6 addresses,
+// optionally with prefix length or port, and parses them to net/netip types.
+//
+// The Text property carries a NetworkAddressValidator, so data binding
+// reports malformed input.
+type NetworkAddressEdit struct {
+	LineEdit
+	kind NetworkAddressKind
+}
+
+// NewNetworkAddressEdit returns a new NetworkAddressEdit widget accepting
+// addresses of the specified kind as child of parent.
+func NewNetworkAddressEdit(parent Container, kind NetworkAddressKind) (*NetworkAddressEdit, error) {
+	nae := &NetworkAddressEdit{kind: kind}
+
+	if err := InitWidget(
+		nae,
+		parent,
+		"EDIT",
+		win.WS_TABSTOP|win.WS_VISIBLE|win.ES_AUTOHSCROLL,
+		win.WS_EX_CLIENTEDGE); err != nil {
+		return nil, err
+	}
+
+	nae.initLineEdit()
+
+	if err := nae.Property("Text").SetValidator(NewNetworkAddressValidator(kind)); err != nil {
+		nae.Dispose()
+		return nil, err
+	}
+
+	nae.SetCueBanner(kind.example())
+
+	return nae, nil
+}
+
+// Kind returns the kind of address nae accepts.
+func (nae *NetworkAddressEdit) Kind() NetworkAddressKind {
+	return nae.kind
+}
+
+// Valid returns whether the current text is a well-formed address of the kind
+// nae accepts.
+func (nae *NetworkAddressEdit) Valid() bool {
+	_, err := parseNetworkAddress(nae.Text(), nae.kind)
+	return err == nil
+}
+
+// Addr returns the address part of the current text.
+func (nae *NetworkAddressEdit) Addr() (netip.Addr, error) {
+	return parseNetworkAddress(nae.Text(), nae.kind)
+}
+
+// Prefix returns the current text parsed as prefix. If nae accepts plain
+// addresses, a single-address prefix is returned.
+func (nae *NetworkAddressEdit) Prefix() (netip.Prefix, error) {
+	switch nae.kind {
+	case NetworkAddressPrefix:
+		return netip.ParsePrefix(strings.TrimSpace(nae.Text()))
+
+	case NetworkAddressAddr:
+		addr, err := nae.Addr()
+		if err != nil {
+			return netip.Prefix{}, err
+		}
+		return netip.PrefixFrom(addr, addr.BitLen()), nil
+	}
+
+	return netip.Prefix{}, newError("NetworkAddressEdit does not accept prefixes")
+}
+
+// AddrPort returns the current text parsed as address and port.
+func (nae *NetworkAddressEdit) AddrPort() (netip.AddrPort, error) {
+	if nae.kind != NetworkAddressAddrPort {
+		return netip.AddrPort{}, newError("NetworkAddressEdit does not accept ports")
+	}
+
+	return netip.ParseAddrPort(strings.TrimSpace(nae.Text()))
+}
+
+// SetAddr sets the text to addr.
+func (nae *NetworkAddressEdit) SetAddr(addr netip.Addr) error {
+	if !addr.IsValid() {
+		return nae.SetText("")
+	}
+
+	return nae.SetText(addr.String())
+}
+
+// SetPrefix sets the text to prefix.
+func (nae *NetworkAddressEdit) SetPrefix(prefix netip.Prefix) error {
+	if !prefix.IsValid() {
+		return nae.SetText("")
+	}
+
+	return nae.SetText(prefix.String())
+}
+
+// SetAddrPort sets the text to addrPort.
+func (nae *NetworkAddressEdit) SetAddrPort(addrPort netip.AddrPort) error {
+	if !addrPort.IsValid() {
+		return nae.SetText("")
+	}
+
+	return nae.SetText(addrPort.String())
+}
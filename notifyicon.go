@@ -97,7 +97,7 @@ func (ni *NotifyIcon) wndProc(hwnd win.HWND, msg uint16, wParam uintptr) {
 		ni.mouseDownPublisher.Publish(int(win.GET_X_LPARAM(wParam)), int(win.GET_Y_LPARAM(wParam)), LeftButton)
 
 	case win.WM_LBUTTONUP:
-		if len(ni.mouseDownPublisher.event.handlers) == 0 && len(ni.mouseUpPublisher.event.handlers) == 0 {
+		if len(ni.mouseDownPublisher.event.handlers) == 0 && len(ni.mouseUpPublisher.event.handlers) == 0 && len(ni.activatedPublisher.event.handlers) == 0 {
 			// If there are no mouse event handlers, then treat WM_LBUTTONUP as
 			// a "show context menu" event; this is consistent with Windows 7
 			// UX guidelines for notification icons.
@@ -124,6 +124,9 @@ func (ni *NotifyIcon) wndProc(hwnd win.HWND, msg uint16, wParam uintptr) {
 	case win.WM_CONTEXTMENU:
 		ni.doContextMenu(hwnd, win.GET_X_LPARAM(wParam), win.GET_Y_LPARAM(wParam))
 
+	case win.NIN_SELECT, win.NIN_KEYSELECT:
+		ni.activatedPublisher.Publish()
+
 	case win.NIN_BALLOONUSERCLICK:
 		ni.reEnableToolTip()
 		ni.messageClickedPublisher.Publish()
@@ -472,9 +475,11 @@ type NotifyIcon struct {
 	mouseDownPublisher          MouseEventPublisher
 	mouseUpPublisher            MouseEventPublisher
 	messageClickedPublisher     EventPublisher
+	activatedPublisher          EventPublisher
 	showingContextMenuPublisher ProceedEventPublisher
 	disableShowContextMenu      bool
 	visible                     bool
+	iconPins                    iconCachePins // cached icons in use by the shell
 }
 
 // NewNotifyIcon creates and returns a new NotifyIcon.
@@ -537,18 +542,25 @@ func (ni *NotifyIcon) reAddToTaskbar() {
 	// track this once the add command successfully executes.
 	prevID := ni.shellIcon.id
 
+	var pins iconCachePins
+
 	cmd := ni.shellIcon.newCmd(win.NIM_ADD)
 	cmd.setCallbackMessage(notifyIconMessageID)
 	cmd.setVisible(ni.visible)
-	cmd.setIcon(ni.getHICON(ni.icon))
+	cmd.setIcon(ni.getHICON(&pins, ni.icon))
 	if err := cmd.setToolTip(ni.toolTip); err != nil {
+		iconCache.unpin(&pins)
 		return
 	}
 
 	if err := cmd.execute(); err != nil {
+		iconCache.unpin(&pins)
 		return
 	}
 
+	iconCache.unpin(&ni.iconPins)
+	ni.iconPins = pins
+
 	newID := ni.shellIcon.id
 	if prevID != nil && (newID == nil || *prevID != *newID) {
 		// The ID has changed. Remove defunct prevID from notifyIconIDs.
@@ -593,6 +605,8 @@ func (ni *NotifyIcon) Dispose() error {
 	}
 	ni.shellIcon = nil
 
+	iconCache.unpin(&ni.iconPins)
+
 	delete(notifyIcons, ni)
 	if nid != nil {
 		delete(notifyIconIDs, uint16(*nid))
@@ -605,13 +619,15 @@ func (ni *NotifyIcon) Dispose() error {
 	return nil
 }
 
-func (ni *NotifyIcon) getHICON(icon Image) win.HICON {
+// getHICON returns the handle of icon at the DPI of ni, adding its IconCache
+// entry to pins.
+func (ni *NotifyIcon) getHICON(pins *iconCachePins, icon Image) win.HICON {
 	if icon == nil {
 		return 0
 	}
 
 	dpi := ni.DPI()
-	ic, err := iconCache.Icon(icon, dpi)
+	ic, err := iconCache.pinnedIcon(pins, icon, dpi)
 	if err != nil {
 		return 0
 	}
@@ -625,13 +641,18 @@ func (ni *NotifyIcon) showMessage(title, info string, iconType uint32, icon Imag
 		return nil
 	}
 
+	// The shell copies the balloon icon, so it only needs to stay alive until
+	// the command has been executed.
+	var pins iconCachePins
+	defer iconCache.unpin(&pins)
+
 	switch iconType {
 	case win.NIIF_NONE, win.NIIF_INFO, win.NIIF_WARNING, win.NIIF_ERROR:
 		if err := cmd.setBalloonInfo(title, info, iconType); err != nil {
 			return err
 		}
 	case win.NIIF_USER:
-		if err := cmd.setBalloonInfo(title, info, ni.getHICON(icon)); err != nil {
+		if err := cmd.setBalloonInfo(title, info, ni.getHICON(&pins, icon)); err != nil {
 			return err
 		}
 	default:
@@ -703,10 +724,15 @@ func (ni *NotifyIcon) forciblySetIcon(icon Image) error {
 	}
 
 	if cmd := ni.shellIcon.newCmd(win.NIM_MODIFY); cmd != nil {
-		cmd.setIcon(ni.getHICON(icon))
+		var pins iconCachePins
+		cmd.setIcon(ni.getHICON(&pins, icon))
 		if err := cmd.execute(); err != nil {
+			iconCache.unpin(&pins)
 			return err
 		}
+
+		iconCache.unpin(&ni.iconPins)
+		ni.iconPins = pins
 	}
 
 	ni.icon = icon
@@ -780,6 +806,14 @@ func (ni *NotifyIcon) MessageClicked() *Event {
 	return ni.messageClickedPublisher.Event()
 }
 
+// Activated returns the event that is published when the user activates the
+// NotifyIcon, by clicking it or by selecting it with the keyboard and pressing
+// Space or Enter. Attaching a handler keeps left clicks from showing the
+// context menu.
+func (ni *NotifyIcon) Activated() *Event {
+	return ni.activatedPublisher.Event()
+}
+
 // ShowingContextMenu returns the event that is published when ni's context menu
 // is going to be shown. Its handlers may return false to prevent the
 // context menu from being shown.
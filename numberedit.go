@@ -31,6 +31,7 @@ type NumberEdit struct {
 	WidgetBase
 	edit                     *numberLineEdit
 	hWndUpDown               win.HWND
+	accelerations            []UpDownAcceleration
 	maxValueChangedPublisher EventPublisher
 	minValueChangedPublisher EventPublisher
 	prefixChangedPublisher   EventPublisher
@@ -383,6 +384,10 @@ func (ne *NumberEdit) SetSpinButtonsVisible(visible bool) error {
 		}
 
 		win.SendMessage(ne.hWndUpDown, win.UDM_SETBUDDY, uintptr(ne.edit.hWnd), 0)
+
+		if err := ne.applyAccelerations(); err != nil {
+			return err
+		}
 	} else {
 		if !win.DestroyWindow(ne.hWndUpDown) {
 			return lastError("DestroyWindow")
@@ -394,6 +399,63 @@ func (ne *NumberEdit) SetSpinButtonsVisible(visible bool) error {
 	return nil
 }
 
+// Accelerations returns how the step size of the spin buttons grows while one
+// is held down. Each Increment is a multiple of the Increment of the
+// NumberEdit.
+func (ne *NumberEdit) Accelerations() []UpDownAcceleration {
+	return ne.accelerations
+}
+
+// SetAccelerations sets how the step size of the spin buttons grows while one
+// is held down. Each Increment is a multiple of the Increment of the
+// NumberEdit. Passing nil restores the default behavior.
+func (ne *NumberEdit) SetAccelerations(accelerations []UpDownAcceleration) error {
+	for i, a := range accelerations {
+		if a.Seconds < 0 || a.Increment <= 0 || i > 0 && a.Seconds < accelerations[i-1].Seconds {
+			return newError("invalid acceleration")
+		}
+	}
+
+	ne.accelerations = append([]UpDownAcceleration(nil), accelerations...)
+
+	return ne.applyAccelerations()
+}
+
+func (ne *NumberEdit) applyAccelerations() error {
+	if ne.hWndUpDown == 0 {
+		return nil
+	}
+
+	accelerations := ne.accelerations
+	if len(accelerations) == 0 {
+		// The default of the control
+		accelerations = []UpDownAcceleration{{0, 1}, {2, 5}, {5, 20}}
+	}
+
+	accels := make([]win.UDACCEL, len(accelerations))
+	for i, a := range accelerations {
+		accels[i] = win.UDACCEL{NSec: uint32(a.Seconds), NInc: uint32(a.Increment)}
+	}
+
+	if 0 == win.SendMessage(ne.hWndUpDown, win.UDM_SETACCEL, uintptr(len(accels)), uintptr(unsafe.Pointer(&accels[0]))) {
+		return newError("UDM_SETACCEL failed")
+	}
+
+	return nil
+}
+
+// Wrap returns whether stepping beyond the range wraps around to the other
+// end.
+func (ne *NumberEdit) Wrap() bool {
+	return ne.edit.wrap
+}
+
+// SetWrap sets whether stepping beyond the range wraps around to the other
+// end. This has no effect without a range.
+func (ne *NumberEdit) SetWrap(wrap bool) {
+	ne.edit.wrap = wrap
+}
+
 // Background returns the background Brush of the NumberEdit.
 //
 // By default this is nil.
@@ -499,6 +561,7 @@ type numberLineEdit struct {
 	decimals              int
 	valueChangedPublisher EventPublisher
 	inEditMode            bool
+	wrap                  bool
 }
 
 func newNumberLineEdit(parent Widget) (*numberLineEdit, error) {
@@ -684,9 +747,17 @@ func (nle *numberLineEdit) incrementValue(delta float64) {
 
 	if nle.minValue != nle.maxValue {
 		if value < nle.minValue {
-			value = nle.minValue
+			if nle.wrap && nle.value == nle.minValue {
+				value = nle.maxValue
+			} else {
+				value = nle.minValue
+			}
 		} else if value > nle.maxValue {
-			value = nle.maxValue
+			if nle.wrap && nle.value == nle.maxValue {
+				value = nle.minValue
+			} else {
+				value = nle.maxValue
+			}
 		}
 	}
 
@@ -0,0 +1,637 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"unsafe"
+
+	"github.com/tailscale/win"
+)
+
+const pagerWindowClass = `\o/ Walk_Pager_Class \o/`
+
+func init() {
+	AppendToWalkInit(func() {
+		MustRegisterWindowClass(pagerWindowClass)
+	})
+}
+
+const (
+	pagerArrowExtent96dpi = 12
+	pagerScrollStep96dpi  = 20
+	pagerTimerId          = 1
+	pagerTimerDelay       = 300 // in milliseconds
+	pagerTimerInterval    = 50  // in milliseconds
+)
+
+type pagerArrow int
+
+const (
+	pagerArrowNone pagerArrow = iota
+	pagerArrowBack
+	pagerArrowForward
+)
+
+// Pager is a container that lays out its children along one axis at their
+// ideal extent. If that does not fit, it shows scroll arrows at both ends, like
+// the Windows pager control. It is useful for ToolBars and tab strips in
+// narrow windows.
+type Pager struct {
+	WidgetBase
+	composite   *Composite
+	orientation Orientation
+	position    int // in native pixels
+	scrolling   bool
+	hotArrow    pagerArrow
+	pressed     pagerArrow
+}
+
+// NewPager returns a new horizontal Pager as child of parent.
+func NewPager(parent Container) (*Pager, error) {
+	return NewPagerWithOrientation(parent, Horizontal)
+}
+
+// NewPagerWithOrientation returns a new Pager with the specified orientation
+// as child of parent.
+func NewPagerWithOrientation(parent Container, orientation Orientation) (*Pager, error) {
+	if orientation != Vertical {
+		orientation = Horizontal
+	}
+
+	p := &Pager{orientation: orientation}
+
+	if err := InitWidget(
+		p,
+		parent,
+		pagerWindowClass,
+		win.WS_CHILD|win.WS_VISIBLE|win.WS_CLIPCHILDREN,
+		win.WS_EX_CONTROLPARENT); err != nil {
+		return nil, err
+	}
+
+	succeeded := false
+	defer func() {
+		if !succeeded {
+			p.Dispose()
+		}
+	}()
+
+	var err error
+	if p.composite, err = NewComposite(p); err != nil {
+		return nil, err
+	}
+
+	if orientation == Horizontal {
+		p.composite.SetLayout(NewHBoxLayout())
+	} else {
+		p.composite.SetLayout(NewVBoxLayout())
+	}
+	p.composite.Layout().SetMargins(Margins{})
+
+	p.composite.BoundsChanged().Attach(p.updateScrolling)
+
+	succeeded = true
+
+	return p, nil
+}
+
+func (p *Pager) AsContainerBase() *ContainerBase {
+	if p.composite == nil {
+		return nil
+	}
+
+	return p.composite.AsContainerBase()
+}
+
+func (p *Pager) ApplyDPI(dpi int) {
+	p.WidgetBase.ApplyDPI(dpi)
+	p.composite.ApplyDPI(dpi)
+}
+
+// Orientation returns the axis along which p scrolls.
+func (p *Pager) Orientation() Orientation {
+	return p.orientation
+}
+
+// ScrollPosition returns how far the content is scrolled, in native pixels.
+func (p *Pager) ScrollPosition() int {
+	return p.position
+}
+
+// SetScrollPosition scrolls the content to position, in native pixels. The
+// position is clamped to the scrollable range.
+func (p *Pager) SetScrollPosition(position int) {
+	position = p.clampPosition(position)
+	if position == p.position {
+		return
+	}
+
+	p.position = position
+
+	if !p.scrolling {
+		return
+	}
+
+	if p.orientation == Horizontal {
+		p.composite.SetXPixels(p.arrowExtent() - position)
+	} else {
+		p.composite.SetYPixels(p.arrowExtent() - position)
+	}
+}
+
+// EnsureVisible scrolls the content so that widget, which must be a
+// descendant of p, is fully visible.
+func (p *Pager) EnsureVisible(widget Widget) {
+	b := widget.BoundsPixels()
+	for w := widget; ; {
+		parent, ok := w.Parent().(Widget)
+		if !ok || parent == Widget(p.composite) {
+			break
+		}
+
+		pb := parent.BoundsPixels()
+		b.X += pb.X
+		b.Y += pb.Y
+		w = parent
+	}
+
+	start, extent := b.X, b.Width
+	if p.orientation == Vertical {
+		start, extent = b.Y, b.Height
+	}
+
+	viewport := p.viewportExtent()
+	if start < p.position {
+		p.SetScrollPosition(start)
+	} else if start+extent > p.position+viewport {
+		p.SetScrollPosition(start + extent - viewport)
+	}
+}
+
+func (p *Pager) arrowExtent() int {
+	return p.IntFrom96DPI(pagerArrowExtent96dpi)
+}
+
+func (p *Pager) mainAxis(size Size) int {
+	if p.orientation == Horizontal {
+		return size.Width
+	}
+
+	return size.Height
+}
+
+func (p *Pager) viewportExtent() int {
+	extent := p.mainAxis(p.ClientBoundsPixels().Size())
+	if p.scrolling {
+		extent -= 2 * p.arrowExtent()
+	}
+
+	return extent
+}
+
+func (p *Pager) clampPosition(position int) int {
+	max := p.mainAxis(p.composite.SizePixels()) - p.viewportExtent()
+	if position > max {
+		position = max
+	}
+	if position < 0 {
+		position = 0
+	}
+
+	return position
+}
+
+func (p *Pager) updateScrolling() {
+	scrolling := p.mainAxis(p.composite.SizePixels()) > p.mainAxis(p.ClientBoundsPixels().Size())
+
+	if scrolling != p.scrolling {
+		p.scrolling = scrolling
+		if !scrolling {
+			p.position = 0
+			p.stopAutoScroll()
+		}
+	}
+	p.position = p.clampPosition(p.position)
+
+	// Clip the composite to the viewport, so it does not cover the arrows.
+	var hRgn win.HRGN
+	if scrolling {
+		cb := p.composite.BoundsPixels()
+		vp := p.viewportBounds()
+		hRgn = win.CreateRectRgn(
+			int32(vp.X-cb.X),
+			int32(vp.Y-cb.Y),
+			int32(vp.X-cb.X+vp.Width),
+			int32(vp.Y-cb.Y+vp.Height))
+	}
+	setWindowRgn(p.composite.hWnd, hRgn, true)
+
+	p.Invalidate()
+}
+
+func (p *Pager) viewportBounds() Rectangle {
+	bounds := p.ClientBoundsPixels()
+	arrow := p.arrowExtent()
+
+	if p.orientation == Horizontal {
+		bounds.X += arrow
+		bounds.Width -= 2 * arrow
+	} else {
+		bounds.Y += arrow
+		bounds.Height -= 2 * arrow
+	}
+
+	return bounds
+}
+
+func (p *Pager) arrowBounds(arrow pagerArrow) Rectangle {
+	bounds := p.ClientBoundsPixels()
+	extent := p.arrowExtent()
+
+	if p.orientation == Horizontal {
+		if arrow == pagerArrowForward {
+			bounds.X += bounds.Width - extent
+		}
+		bounds.Width = extent
+	} else {
+		if arrow == pagerArrowForward {
+			bounds.Y += bounds.Height - extent
+		}
+		bounds.Height = extent
+	}
+
+	return bounds
+}
+
+func (p *Pager) arrowAt(pt Point) pagerArrow {
+	if !p.scrolling {
+		return pagerArrowNone
+	}
+
+	for _, arrow := range [...]pagerArrow{pagerArrowBack, pagerArrowForward} {
+		b := p.arrowBounds(arrow)
+		if pt.X >= b.X && pt.X < b.X+b.Width && pt.Y >= b.Y && pt.Y < b.Y+b.Height {
+			return arrow
+		}
+	}
+
+	return pagerArrowNone
+}
+
+func (p *Pager) arrowEnabled(arrow pagerArrow) bool {
+	if arrow == pagerArrowBack {
+		return p.position > 0
+	}
+
+	return p.position < p.mainAxis(p.composite.SizePixels())-p.viewportExtent()
+}
+
+func (p *Pager) step(arrow pagerArrow) {
+	delta := p.IntFrom96DPI(pagerScrollStep96dpi)
+	if arrow == pagerArrowBack {
+		delta = -delta
+	}
+
+	p.SetScrollPosition(p.position + delta)
+}
+
+func (p *Pager) stopAutoScroll() {
+	if p.pressed == pagerArrowNone {
+		return
+	}
+
+	p.pressed = pagerArrowNone
+	win.KillTimer(p.hWnd, pagerTimerId)
+	win.ReleaseCapture()
+}
+
+func (p *Pager) paintArrows(canvas *Canvas) error {
+	if !p.scrolling {
+		return nil
+	}
+
+	for _, arrow := range [...]pagerArrow{pagerArrowBack, pagerArrowForward} {
+		b := p.arrowBounds(arrow)
+
+		enabled := p.arrowEnabled(arrow)
+
+		if enabled && (arrow == p.hotArrow || arrow == p.pressed) {
+			brush, err := NewSystemColorBrush(SysColorBtnShadow)
+			if err != nil {
+				return err
+			}
+			err = canvas.FillRectanglePixels(brush, b)
+			brush.Dispose()
+			if err != nil {
+				return err
+			}
+		}
+
+		color := Color(win.GetSysColor(win.COLOR_BTNTEXT))
+		if !enabled {
+			color = Color(win.GetSysColor(win.COLOR_GRAYTEXT))
+		}
+
+		brush, err := NewSolidColorBrush(color)
+		if err != nil {
+			return err
+		}
+		pen, err := NewGeometricPen(PenSolid|PenCapFlat|PenJoinMiter, 2, brush)
+		if err != nil {
+			brush.Dispose()
+			return err
+		}
+
+		err = canvas.DrawPolylinePixels(pen, p.chevron(arrow, b))
+		pen.Dispose()
+		brush.Dispose()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// chevron returns the points of the arrow glyph drawn within bounds.
+func (p *Pager) chevron(arrow pagerArrow, bounds Rectangle) []Point {
+	half := p.IntFrom96DPI(3)
+	cx := bounds.X + bounds.Width/2
+	cy := bounds.Y + bounds.Height/2
+
+	dir := 1
+	if arrow == pagerArrowBack {
+		dir = -1
+	}
+
+	if p.orientation == Horizontal {
+		return []Point{{cx - dir*half/2, cy - half}, {cx + dir*half/2, cy}, {cx - dir*half/2, cy + half}}
+	}
+
+	return []Point{{cx - half, cy - dir*half/2}, {cx, cy + dir*half/2}, {cx + half, cy - dir*half/2}}
+}
+
+func (p *Pager) SetSuspended(suspend bool) {
+	p.composite.SetSuspended(suspend)
+	p.WidgetBase.SetSuspended(suspend)
+	p.Invalidate()
+}
+
+func (p *Pager) DataBinder() *DataBinder {
+	return p.composite.dataBinder
+}
+
+func (p *Pager) SetDataBinder(dataBinder *DataBinder) {
+	p.composite.SetDataBinder(dataBinder)
+}
+
+func (p *Pager) Children() *WidgetList {
+	if p.composite == nil {
+		// Without this we would get into trouble in NewComposite.
+		return nil
+	}
+
+	return p.composite.Children()
+}
+
+func (p *Pager) Layout() Layout {
+	if p.composite == nil {
+		return nil
+	}
+
+	return p.composite.Layout()
+}
+
+func (p *Pager) SetLayout(value Layout) error {
+	return p.composite.SetLayout(value)
+}
+
+func (p *Pager) Name() string {
+	if p.composite == nil {
+		return ""
+	}
+
+	return p.composite.Name()
+}
+
+func (p *Pager) SetName(name string) {
+	p.composite.SetName(name)
+}
+
+func (p *Pager) Persistent() bool {
+	return p.composite.Persistent()
+}
+
+func (p *Pager) SetPersistent(value bool) {
+	p.composite.SetPersistent(value)
+}
+
+func (p *Pager) SaveState() error {
+	return p.composite.SaveState()
+}
+
+func (p *Pager) RestoreState() error {
+	return p.composite.RestoreState()
+}
+
+func (p *Pager) WndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) uintptr {
+	if p.composite != nil {
+		switch msg {
+		case win.WM_PAINT:
+			var ps win.PAINTSTRUCT
+
+			hdc := win.BeginPaint(hwnd, &ps)
+			defer win.EndPaint(hwnd, &ps)
+
+			canvas, err := newCanvasFromHDC(hdc)
+			if err != nil {
+				return 0
+			}
+			defer canvas.Dispose()
+
+			p.paintArrows(canvas)
+
+			return 0
+
+		case win.WM_LBUTTONDOWN:
+			arrow := p.arrowAt(Point{int(win.GET_X_LPARAM(lParam)), int(win.GET_Y_LPARAM(lParam))})
+			if arrow == pagerArrowNone || !p.arrowEnabled(arrow) {
+				break
+			}
+
+			p.pressed = arrow
+			win.SetCapture(hwnd)
+			win.SetTimer(hwnd, pagerTimerId, pagerTimerDelay, 0)
+			p.step(arrow)
+			p.Invalidate()
+
+		case win.WM_LBUTTONUP, win.WM_CAPTURECHANGED:
+			if p.pressed != pagerArrowNone {
+				p.stopAutoScroll()
+				p.Invalidate()
+			}
+
+		case win.WM_TIMER:
+			if wParam != pagerTimerId || p.pressed == pagerArrowNone {
+				break
+			}
+
+			win.SetTimer(hwnd, pagerTimerId, pagerTimerInterval, 0)
+			if p.hotArrow == p.pressed {
+				p.step(p.pressed)
+			}
+			if !p.arrowEnabled(p.pressed) {
+				p.stopAutoScroll()
+			}
+			p.Invalidate()
+
+			return 0
+
+		case win.WM_MOUSEMOVE:
+			hot := p.arrowAt(Point{int(win.GET_X_LPARAM(lParam)), int(win.GET_Y_LPARAM(lParam))})
+			if hot != p.hotArrow {
+				p.hotArrow = hot
+				p.Invalidate()
+
+				if hot != pagerArrowNone {
+					var tme win.TRACKMOUSEEVENT
+					tme.CbSize = uint32(unsafe.Sizeof(tme))
+					tme.DwFlags = win.TME_LEAVE
+					tme.HwndTrack = hwnd
+
+					win.TrackMouseEvent(&tme)
+				}
+			}
+
+		case win.WM_MOUSELEAVE:
+			if p.hotArrow != pagerArrowNone {
+				p.hotArrow = pagerArrowNone
+				p.Invalidate()
+			}
+
+		case win.WM_MOUSEWHEEL, _WM_MOUSEHWHEEL:
+			if !p.scrolling {
+				break
+			}
+
+			arrow := pagerArrowForward
+			delta := int16(win.HIWORD(uint32(wParam)))
+			if (msg == win.WM_MOUSEWHEEL) == (delta > 0) {
+				arrow = pagerArrowBack
+			}
+			p.step(arrow)
+
+			return 0
+
+		case win.WM_COMMAND, win.WM_NOTIFY:
+			p.composite.WndProc(hwnd, msg, wParam, lParam)
+
+		case win.WM_WINDOWPOSCHANGED:
+			p.updateScrolling()
+		}
+	}
+
+	return p.WidgetBase.WndProc(hwnd, msg, wParam, lParam)
+}
+
+func (p *Pager) CreateLayoutItem(ctx *LayoutContext) LayoutItem {
+	li := &pagerLayoutItem{
+		orientation: p.orientation,
+		arrowExtent: IntFrom96DPI(pagerArrowExtent96dpi, ctx.dpi),
+		position:    p.position,
+	}
+	li.ctx = ctx
+
+	cli := CreateLayoutItemsForContainerWithContext(p.composite, ctx)
+	cli.AsLayoutItemBase().parent = li
+	li.children = append(li.children, cli)
+
+	return li
+}
+
+type pagerLayoutItem struct {
+	ContainerLayoutItemBase
+	orientation Orientation
+	arrowExtent int // in native pixels
+	position    int // in native pixels
+}
+
+func (li *pagerLayoutItem) child() LayoutItem {
+	return li.children[0]
+}
+
+func (li *pagerLayoutItem) LayoutFlags() LayoutFlags {
+	flags := li.child().LayoutFlags()
+
+	if li.orientation == Horizontal {
+		return flags&^(GreedyHorz) | ShrinkableHorz | GrowableHorz
+	}
+
+	return flags&^(GreedyVert) | ShrinkableVert | GrowableVert
+}
+
+func (li *pagerLayoutItem) IdealSize() Size {
+	return li.child().(IdealSizer).IdealSize()
+}
+
+func (li *pagerLayoutItem) MinSize() Size {
+	size := li.child().(MinSizer).MinSize()
+
+	if li.orientation == Horizontal {
+		size.Width = 3 * li.arrowExtent
+	} else {
+		size.Height = 3 * li.arrowExtent
+	}
+
+	return size
+}
+
+func (li *pagerLayoutItem) MinSizeForSize(size Size) Size {
+	return li.MinSize()
+}
+
+func (li *pagerLayoutItem) HasHeightForWidth() bool {
+	return false
+}
+
+func (li *pagerLayoutItem) HeightForWidth(width int) int {
+	return 0
+}
+
+func (li *pagerLayoutItem) PerformLayout() []LayoutResultItem {
+	size := li.geometry.ClientSize
+	ideal := li.child().(IdealSizer).IdealSize()
+	min := li.child().(MinSizer).MinSize()
+
+	bounds := Rectangle{Width: size.Width, Height: size.Height}
+
+	if li.orientation == Horizontal {
+		extent := maxi(ideal.Width, min.Width)
+		if extent > size.Width {
+			viewport := size.Width - 2*li.arrowExtent
+			position := mini(li.position, extent-viewport)
+			bounds.X = li.arrowExtent - maxi(position, 0)
+			bounds.Width = extent
+		}
+	} else {
+		extent := maxi(ideal.Height, min.Height)
+		if extent > size.Height {
+			viewport := size.Height - 2*li.arrowExtent
+			position := mini(li.position, extent-viewport)
+			bounds.Y = li.arrowExtent - maxi(position, 0)
+			bounds.Height = extent
+		}
+	}
+
+	return []LayoutResultItem{
+		{
+			Item:   li.child(),
+			Bounds: bounds,
+		},
+	}
+}
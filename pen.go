@@ -139,10 +139,11 @@ func (p *CosmeticPen) Width() int {
 }
 
 type GeometricPen struct {
-	dpi2hPen   map[int]win.HPEN
-	style      PenStyle
-	brush      Brush
-	width96dpi int
+	dpi2hPen    map[int]win.HPEN
+	style       PenStyle
+	brush       Brush
+	width96dpi  int
+	dashes96dpi []int
 }
 
 // NewGeometricPen prepares new geometric pen. width parameter is specified in 1/96" units.
@@ -160,6 +161,34 @@ func NewGeometricPen(style PenStyle, width int, brush Brush) (*GeometricPen, err
 	}, nil
 }
 
+// NewGeometricPenWithDashes prepares new geometric pen that strokes lines
+// using a custom dash pattern. dashes holds alternating dash and gap lengths,
+// starting with a dash. width and dashes are specified in 1/96" units.
+//
+// Any line style bits in style are replaced with PenUserStyle, while cap and
+// join styles are preserved.
+func NewGeometricPenWithDashes(style PenStyle, width int, brush Brush, dashes []int) (*GeometricPen, error) {
+	if len(dashes) == 0 || len(dashes) > 16 {
+		return nil, newError("dashes must contain between 1 and 16 elements")
+	}
+	for _, d := range dashes {
+		if d <= 0 {
+			return nil, newError("dash lengths must be positive")
+		}
+	}
+
+	style = style&^win.PS_STYLE_MASK | PenUserStyle
+
+	p, err := NewGeometricPen(style, width, brush)
+	if err != nil {
+		return nil, err
+	}
+
+	p.dashes96dpi = append([]int(nil), dashes...)
+
+	return p, nil
+}
+
 func (p *GeometricPen) Dispose() {
 	if len(p.dpi2hPen) == 0 {
 		return
@@ -183,10 +212,19 @@ func (p *GeometricPen) handleForDPIWithError(dpi int) (win.HPEN, error) {
 		return handle, nil
 	}
 
+	var dashes []uint32
+	for _, d := range p.dashes96dpi {
+		dashes = append(dashes, uint32(IntFrom96DPI(d, dpi)))
+	}
+	var pDashes *uint32
+	if len(dashes) > 0 {
+		pDashes = &dashes[0]
+	}
+
 	hPen := win.ExtCreatePen(
 		uint32(p.style),
 		uint32(IntFrom96DPI(p.width96dpi, dpi)),
-		p.brush.logbrush(), 0, nil)
+		p.brush.logbrush(), uint32(len(dashes)), pDashes)
 	if hPen == 0 {
 		return 0, newError("ExtCreatePen failed")
 	}
@@ -208,3 +246,19 @@ func (p *GeometricPen) Width() int {
 func (p *GeometricPen) Brush() Brush {
 	return p.brush
 }
+
+// Cap returns the end cap style of the pen.
+func (p *GeometricPen) Cap() PenStyle {
+	return p.style & win.PS_ENDCAP_MASK
+}
+
+// Join returns the join style of the pen.
+func (p *GeometricPen) Join() PenStyle {
+	return p.style & win.PS_JOIN_MASK
+}
+
+// Dashes returns the custom dash pattern of the pen in 1/96" units, or nil if
+// the pen does not use one.
+func (p *GeometricPen) Dashes() []int {
+	return append([]int(nil), p.dashes96dpi...)
+}
@@ -10,8 +10,19 @@ import (
 	"github.com/tailscale/win"
 )
 
+// ProgressBarState specifies the state, and with visual styles enabled the
+// color, of a ProgressBar.
+type ProgressBarState int
+
+const (
+	ProgressBarNormal ProgressBarState = win.PBST_NORMAL // green
+	ProgressBarError  ProgressBarState = win.PBST_ERROR  // red
+	ProgressBarPaused ProgressBarState = win.PBST_PAUSED // yellow
+)
+
 type ProgressBar struct {
 	WidgetBase
+	marqueeInterval int // in milliseconds, 0 means default
 }
 
 func NewProgressBar(parent Container) (*ProgressBar, error) {
@@ -49,6 +60,41 @@ func (pb *ProgressBar) SetValue(value int) {
 	pb.SendMessage(win.PBM_SETPOS, uintptr(value), 0)
 }
 
+// SetValueImmediately sets the value without the animation visual styles apply
+// when the value increases, so the bar does not lag behind fast progress or
+// appear to move backwards slowly after a reset.
+func (pb *ProgressBar) SetValueImmediately(value int) {
+	// Moving backwards is never animated, so we step past value and back.
+	if max := pb.MaxValue(); value >= max {
+		min := pb.MinValue()
+
+		pb.SendMessage(win.PBM_SETRANGE32, uintptr(min), uintptr(max+1))
+		pb.SendMessage(win.PBM_SETPOS, uintptr(max+1), 0)
+		pb.SendMessage(win.PBM_SETRANGE32, uintptr(min), uintptr(max))
+	} else {
+		pb.SendMessage(win.PBM_SETPOS, uintptr(value+1), 0)
+	}
+
+	pb.SendMessage(win.PBM_SETPOS, uintptr(value), 0)
+}
+
+// State returns the state of the ProgressBar.
+func (pb *ProgressBar) State() ProgressBarState {
+	return ProgressBarState(pb.SendMessage(_PBM_GETSTATE, 0, 0))
+}
+
+// SetState sets the state of the ProgressBar. With visual styles enabled, an
+// error state is displayed in red and a paused state in yellow.
+func (pb *ProgressBar) SetState(state ProgressBarState) error {
+	if state < ProgressBarNormal || state > ProgressBarPaused {
+		return newError("invalid state")
+	}
+
+	pb.SendMessage(_PBM_SETSTATE, uintptr(state), 0)
+
+	return nil
+}
+
 func (pb *ProgressBar) MarqueeMode() bool {
 	return pb.hasStyleBits(win.PBS_MARQUEE)
 }
@@ -58,7 +104,30 @@ func (pb *ProgressBar) SetMarqueeMode(marqueeMode bool) error {
 		return err
 	}
 
-	pb.SendMessage(win.PBM_SETMARQUEE, uintptr(win.BoolToBOOL(marqueeMode)), 0)
+	pb.SendMessage(win.PBM_SETMARQUEE, uintptr(win.BoolToBOOL(marqueeMode)), uintptr(pb.marqueeInterval))
+
+	return nil
+}
+
+// MarqueeInterval returns the time in milliseconds between updates of the
+// marquee animation. Zero means the default of 30 milliseconds.
+func (pb *ProgressBar) MarqueeInterval() int {
+	return pb.marqueeInterval
+}
+
+// SetMarqueeInterval sets the time in milliseconds between updates of the
+// marquee animation, where lower values result in faster movement. Zero means
+// the default of 30 milliseconds.
+func (pb *ProgressBar) SetMarqueeInterval(interval int) error {
+	if interval < 0 {
+		return newError("interval must not be negative")
+	}
+
+	pb.marqueeInterval = interval
+
+	if pb.MarqueeMode() {
+		pb.SendMessage(win.PBM_SETMARQUEE, 1, uintptr(interval))
+	}
 
 	return nil
 }
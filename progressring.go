@@ -0,0 +1,301 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"math"
+	"time"
+
+	"github.com/tailscale/win"
+)
+
+const progressRingWindowClass = `\o/ Walk_ProgressRing_Class \o/`
+
+func init() {
+	AppendToWalkInit(func() {
+		MustRegisterWindowClass(progressRingWindowClass)
+	})
+}
+
+const (
+	progressRingSize96dpi    = 32
+	progressRingTimerId      = 1
+	progressRingTimerElapse  = 16   // in milliseconds
+	progressRingRotation     = 1500 // period in milliseconds
+	progressRingArcPeriod    = 2200 // period in milliseconds
+	progressRingMinSweep     = 20   // in degrees
+	progressRingMaxSweep     = 270  // in degrees
+	progressRingDegreesPerPt = 6
+)
+
+// ProgressRing is a circular indicator for operations of unknown duration,
+// for places where a marquee ProgressBar looks out of place. It displays a
+// rotating arc while running and nothing otherwise.
+type ProgressRing struct {
+	WidgetBase
+	color                   Color
+	running                 bool
+	startTime               time.Time
+	runningChangedPublisher EventPublisher
+}
+
+// NewProgressRing returns a new ProgressRing as child of parent. The ring is
+// initially stopped.
+func NewProgressRing(parent Container) (*ProgressRing, error) {
+	pr := new(ProgressRing)
+
+	if err := InitWidget(
+		pr,
+		parent,
+		progressRingWindowClass,
+		win.WS_VISIBLE,
+		0); err != nil {
+		return nil, err
+	}
+
+	pr.MustRegisterProperty("Running", NewBoolProperty(
+		func() bool {
+			return pr.Running()
+		},
+		func(b bool) error {
+			pr.SetRunning(b)
+			return nil
+		},
+		pr.runningChangedPublisher.Event()))
+
+	return pr, nil
+}
+
+// Running returns whether the ring is animating.
+func (pr *ProgressRing) Running() bool {
+	return pr.running
+}
+
+// SetRunning starts or stops the animation.
+func (pr *ProgressRing) SetRunning(running bool) {
+	if running {
+		pr.Start()
+	} else {
+		pr.Stop()
+	}
+}
+
+// Start starts the animation.
+func (pr *ProgressRing) Start() {
+	if pr.running {
+		return
+	}
+
+	pr.running = true
+	pr.startTime = time.Now()
+
+	win.SetTimer(pr.hWnd, progressRingTimerId, progressRingTimerElapse, 0)
+
+	pr.Invalidate()
+
+	pr.runningChangedPublisher.Publish()
+}
+
+// Stop stops the animation and clears the ring.
+func (pr *ProgressRing) Stop() {
+	if !pr.running {
+		return
+	}
+
+	pr.running = false
+
+	win.KillTimer(pr.hWnd, progressRingTimerId)
+
+	pr.Invalidate()
+
+	pr.runningChangedPublisher.Publish()
+}
+
+// RunningChanged returns the event that is published when the ring is started
+// or stopped.
+func (pr *ProgressRing) RunningChanged() *Event {
+	return pr.runningChangedPublisher.Event()
+}
+
+// Color returns the color of the arc. Zero means the system highlight color.
+func (pr *ProgressRing) Color() Color {
+	return pr.color
+}
+
+// SetColor sets the color of the arc. Zero means the system highlight color.
+func (pr *ProgressRing) SetColor(color Color) {
+	if color == pr.color {
+		return
+	}
+
+	pr.color = color
+
+	pr.Invalidate()
+}
+
+// arcPoints returns the points of the arc to be drawn elapsed into the
+// animation, inside bounds.
+func (pr *ProgressRing) arcPoints(bounds Rectangle, elapsed time.Duration) []Point {
+	ms := float64(elapsed.Milliseconds())
+
+	// The arc grows and shrinks while the whole ring rotates, like the
+	// indeterminate ProgressRing of WinUI.
+	phase := math.Mod(ms, progressRingArcPeriod) / progressRingArcPeriod
+	sweep := progressRingMinSweep + (progressRingMaxSweep-progressRingMinSweep)*(1-math.Cos(2*math.Pi*phase))/2
+	start := 360*math.Mod(ms, progressRingRotation)/progressRingRotation + 180*phase
+
+	cx := float64(bounds.X) + float64(bounds.Width)/2
+	cy := float64(bounds.Y) + float64(bounds.Height)/2
+	r := float64(bounds.Width) / 2
+
+	n := int(sweep/progressRingDegreesPerPt) + 2
+	points := make([]Point, n)
+	for i := range points {
+		a := (start + sweep*float64(i)/float64(n-1)) * math.Pi / 180
+		points[i] = Point{int(math.Round(cx + r*math.Cos(a))), int(math.Round(cy + r*math.Sin(a)))}
+	}
+
+	return points
+}
+
+func (pr *ProgressRing) paint(canvas *Canvas, bounds Rectangle) error {
+	bp, err := BeginBufferedPaint(canvas, bounds, win.BPBF_COMPATIBLEBITMAP)
+	if err != nil {
+		return err
+	}
+	defer bp.End()
+
+	buffered, err := bp.Canvas()
+	if err != nil {
+		return err
+	}
+	defer buffered.Dispose()
+
+	if bg, wnd := pr.backgroundEffective(); bg != nil {
+		pr.prepareDCForBackground(buffered.HDC(), pr.hWnd, wnd)
+
+		if err := buffered.FillRectanglePixels(bg, bounds); err != nil {
+			return err
+		}
+	} else {
+		brush, err := NewSystemColorBrush(SysColorBtnFace)
+		if err != nil {
+			return err
+		}
+		err = buffered.FillRectanglePixels(brush, bounds)
+		brush.Dispose()
+		if err != nil {
+			return err
+		}
+	}
+
+	if !pr.running {
+		return nil
+	}
+
+	cb := pr.ClientBoundsPixels()
+	diameter := cb.Width
+	if cb.Height < diameter {
+		diameter = cb.Height
+	}
+
+	// The stroke width scales with the ring, but is specified in 1/96" like
+	// all pen widths, so it also follows the DPI.
+	strokeWidth := IntTo96DPI(diameter, pr.DPI()) / 10
+	if strokeWidth < 2 {
+		strokeWidth = 2
+	}
+	inset := IntFrom96DPI(strokeWidth, pr.DPI())/2 + 1
+
+	ring := Rectangle{
+		X:      cb.X + (cb.Width-diameter)/2 + inset,
+		Y:      cb.Y + (cb.Height-diameter)/2 + inset,
+		Width:  diameter - 2*inset,
+		Height: diameter - 2*inset,
+	}
+	if ring.Width <= 0 {
+		return nil
+	}
+
+	color := pr.color
+	if color == 0 {
+		color = Color(win.GetSysColor(win.COLOR_HIGHLIGHT))
+	}
+
+	brush, err := NewSolidColorBrush(color)
+	if err != nil {
+		return err
+	}
+	defer brush.Dispose()
+
+	pen, err := NewGeometricPen(PenSolid|PenCapRound|PenJoinRound, strokeWidth, brush)
+	if err != nil {
+		return err
+	}
+	defer pen.Dispose()
+
+	return buffered.DrawPolylinePixels(pen, pr.arcPoints(ring, time.Since(pr.startTime)))
+}
+
+func (pr *ProgressRing) WndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) uintptr {
+	switch msg {
+	case win.WM_PAINT:
+		var ps win.PAINTSTRUCT
+
+		hdc := win.BeginPaint(hwnd, &ps)
+		defer win.EndPaint(hwnd, &ps)
+
+		canvas, err := newCanvasFromHDC(hdc)
+		if err != nil {
+			return 0
+		}
+		defer canvas.Dispose()
+
+		pr.paint(canvas, pr.ClientBoundsPixels())
+
+		return 0
+
+	case win.WM_ERASEBKGND:
+		// Everything is drawn in WM_PAINT.
+		return 1
+
+	case win.WM_TIMER:
+		if wParam == progressRingTimerId {
+			pr.Invalidate()
+			return 0
+		}
+
+	case win.WM_SIZE:
+		pr.Invalidate()
+	}
+
+	return pr.WidgetBase.WndProc(hwnd, msg, wParam, lParam)
+}
+
+func (pr *ProgressRing) CreateLayoutItem(ctx *LayoutContext) LayoutItem {
+	return &progressRingLayoutItem{
+		idealSize: SizeFrom96DPI(Size{progressRingSize96dpi, progressRingSize96dpi}, ctx.dpi),
+	}
+}
+
+type progressRingLayoutItem struct {
+	LayoutItemBase
+	idealSize Size // in native pixels
+}
+
+func (*progressRingLayoutItem) LayoutFlags() LayoutFlags {
+	return 0
+}
+
+func (li *progressRingLayoutItem) IdealSize() Size {
+	return li.idealSize
+}
+
+func (li *progressRingLayoutItem) MinSize() Size {
+	return li.idealSize
+}
@@ -0,0 +1,695 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/tailscale/win"
+)
+
+const rangeSliderWindowClass = `\o/ Walk_RangeSlider_Class \o/`
+
+func init() {
+	AppendToWalkInit(func() {
+		MustRegisterWindowClass(rangeSliderWindowClass)
+	})
+}
+
+const (
+	rangeSliderThumbWidth96dpi  = 11
+	rangeSliderThumbHeight96dpi = 20
+	rangeSliderTrackHeight96dpi = 4
+	rangeSliderTickLength96dpi  = 4
+)
+
+// RangeSlider is a horizontal slider with two thumbs, selecting a range of
+// values like the minimum and maximum of a filter.
+//
+// The arrow, Page Up, Page Down, Home and End keys move the thumb that was
+// dragged last, the space key switches to the other thumb.
+type RangeSlider struct {
+	WidgetBase
+	min                    int
+	max                    int
+	lower                  int
+	upper                  int
+	lineSize               int
+	pageSize               int
+	tickFrequency          int
+	tickLabelsVisible      bool
+	snapToTicks            bool
+	tracking               bool
+	persistent             bool
+	valueFormatter         func(value int) string
+	valueToolTip           sliderValueToolTip
+	valueToolTipHidden     bool
+	activeThumb            int // 0 for the lower, 1 for the upper thumb
+	dragging               bool
+	hotThumb               int // -1 if none
+	lowerChangedPublisher  EventPublisher
+	upperChangedPublisher  EventPublisher
+	valuesChangedPublisher EventPublisher
+}
+
+// NewRangeSlider creates a new RangeSlider as child of parent, with a range of
+// 0 to 100 that is selected completely.
+func NewRangeSlider(parent Container) (*RangeSlider, error) {
+	rs := &RangeSlider{
+		max:      100,
+		upper:    100,
+		lineSize: 1,
+		pageSize: 10,
+		hotThumb: -1,
+	}
+
+	if err := InitWidget(
+		rs,
+		parent,
+		rangeSliderWindowClass,
+		win.WS_TABSTOP|win.WS_VISIBLE,
+		0); err != nil {
+		return nil, err
+	}
+
+	rs.SetBackground(NullBrush())
+
+	rs.GraphicsEffects().Add(InteractionEffect)
+	rs.GraphicsEffects().Add(FocusEffect)
+
+	rs.MustRegisterProperty("LowerValue", NewProperty(
+		func() interface{} {
+			return rs.LowerValue()
+		},
+		func(v interface{}) error {
+			return rs.SetValues(assertIntOr(v, rs.min), rs.upper)
+		},
+		rs.lowerChangedPublisher.Event()))
+
+	rs.MustRegisterProperty("UpperValue", NewProperty(
+		func() interface{} {
+			return rs.UpperValue()
+		},
+		func(v interface{}) error {
+			return rs.SetValues(rs.lower, assertIntOr(v, rs.max))
+		},
+		rs.upperChangedPublisher.Event()))
+
+	return rs, nil
+}
+
+func (rs *RangeSlider) MinValue() int {
+	return rs.min
+}
+
+func (rs *RangeSlider) MaxValue() int {
+	return rs.max
+}
+
+// SetRange sets the values that can be selected, clamping the selected range.
+func (rs *RangeSlider) SetRange(min, max int) error {
+	if max < min {
+		return newError("max must not be less than min")
+	}
+
+	rs.min, rs.max = min, max
+
+	rs.setValues(rs.lower, rs.upper, true)
+
+	rs.Invalidate()
+	if rs.tickLabelsVisible {
+		rs.RequestLayout()
+	}
+
+	return nil
+}
+
+// LowerValue returns the start of the selected range.
+func (rs *RangeSlider) LowerValue() int {
+	return rs.lower
+}
+
+// UpperValue returns the end of the selected range.
+func (rs *RangeSlider) UpperValue() int {
+	return rs.upper
+}
+
+// SetValues selects the range from lower to upper, clamped to the range of
+// the RangeSlider.
+func (rs *RangeSlider) SetValues(lower, upper int) error {
+	if upper < lower {
+		return newError("upper must not be less than lower")
+	}
+
+	rs.setValues(lower, upper, true)
+
+	return nil
+}
+
+// setValues clamps and sets the values, publishing the events if publish is
+// true.
+func (rs *RangeSlider) setValues(lower, upper int, publish bool) bool {
+	lower = maxi(rs.min, mini(lower, rs.max))
+	upper = maxi(lower, mini(upper, rs.max))
+
+	if lower == rs.lower && upper == rs.upper {
+		return false
+	}
+
+	lowerChanged, upperChanged := lower != rs.lower, upper != rs.upper
+	rs.lower, rs.upper = lower, upper
+
+	rs.Invalidate()
+
+	if publish {
+		rs.publishValuesChanged(lowerChanged, upperChanged)
+	}
+
+	return true
+}
+
+func (rs *RangeSlider) publishValuesChanged(lowerChanged, upperChanged bool) {
+	if lowerChanged {
+		rs.lowerChangedPublisher.Publish()
+	}
+	if upperChanged {
+		rs.upperChangedPublisher.Publish()
+	}
+	rs.valuesChangedPublisher.Publish()
+}
+
+// ValuesChanged returns the event that is published when the selected range
+// changed.
+func (rs *RangeSlider) ValuesChanged() *Event {
+	return rs.valuesChangedPublisher.Event()
+}
+
+func (rs *RangeSlider) LineSize() int {
+	return rs.lineSize
+}
+
+func (rs *RangeSlider) SetLineSize(lineSize int) {
+	rs.lineSize = lineSize
+}
+
+func (rs *RangeSlider) PageSize() int {
+	return rs.pageSize
+}
+
+func (rs *RangeSlider) SetPageSize(pageSize int) {
+	rs.pageSize = pageSize
+}
+
+// Tracking returns whether ValuesChanged is published while a thumb is
+// dragged, instead of once it is released.
+func (rs *RangeSlider) Tracking() bool {
+	return rs.tracking
+}
+
+func (rs *RangeSlider) SetTracking(tracking bool) {
+	rs.tracking = tracking
+}
+
+// TickFrequency returns the distance of the values tick marks are displayed
+// at, or 0 if there are tick marks at the ends only.
+func (rs *RangeSlider) TickFrequency() int {
+	return rs.tickFrequency
+}
+
+// SetTickFrequency sets the distance of the values tick marks are displayed
+// at, starting at MinValue. 0 displays tick marks at the ends only.
+func (rs *RangeSlider) SetTickFrequency(frequency int) error {
+	if frequency < 0 {
+		return newError("frequency must not be negative")
+	}
+
+	rs.tickFrequency = frequency
+
+	rs.Invalidate()
+	if rs.tickLabelsVisible {
+		rs.RequestLayout()
+	}
+
+	return nil
+}
+
+// TickLabelsVisible returns whether the tick marks are labeled with their
+// values.
+func (rs *RangeSlider) TickLabelsVisible() bool {
+	return rs.tickLabelsVisible
+}
+
+// SetTickLabelsVisible sets whether the tick marks are labeled with their
+// values, as formatted by the ValueFormatter.
+func (rs *RangeSlider) SetTickLabelsVisible(visible bool) {
+	if visible == rs.tickLabelsVisible {
+		return
+	}
+
+	rs.tickLabelsVisible = visible
+
+	rs.Invalidate()
+	rs.RequestLayout()
+}
+
+// SnapToTicks returns whether the values snap to the closest tick mark.
+func (rs *RangeSlider) SnapToTicks() bool {
+	return rs.snapToTicks
+}
+
+// SetSnapToTicks sets whether the values snap to the closest tick mark when
+// the user moves a thumb.
+func (rs *RangeSlider) SetSnapToTicks(snap bool) {
+	rs.snapToTicks = snap
+}
+
+// ValueFormatter returns the function formatting the values of the tick labels
+// and the value tooltip, or nil if they are formatted as decimal numbers.
+func (rs *RangeSlider) ValueFormatter() func(value int) string {
+	return rs.valueFormatter
+}
+
+// SetValueFormatter sets the function formatting the values of the tick labels
+// and the value tooltip. nil formats them as decimal numbers.
+func (rs *RangeSlider) SetValueFormatter(formatter func(value int) string) {
+	rs.valueFormatter = formatter
+
+	if rs.tickLabelsVisible {
+		rs.Invalidate()
+		rs.RequestLayout()
+	}
+}
+
+// ToolTipsHidden returns whether the tooltip displaying the value while a
+// thumb is dragged is hidden.
+func (rs *RangeSlider) ToolTipsHidden() bool {
+	return rs.valueToolTipHidden
+}
+
+func (rs *RangeSlider) SetToolTipsHidden(hidden bool) {
+	rs.valueToolTipHidden = hidden
+}
+
+func (rs *RangeSlider) formatValue(value int) string {
+	if rs.valueFormatter != nil {
+		return rs.valueFormatter(value)
+	}
+
+	return fmt.Sprint(value)
+}
+
+func (rs *RangeSlider) Persistent() bool {
+	return rs.persistent
+}
+
+func (rs *RangeSlider) SetPersistent(value bool) {
+	rs.persistent = value
+}
+
+func (rs *RangeSlider) SaveState() error {
+	return rs.WriteState(fmt.Sprintf("%d %d", rs.lower, rs.upper))
+}
+
+func (rs *RangeSlider) RestoreState() error {
+	s, err := rs.ReadState()
+	if err != nil {
+		return err
+	}
+
+	var lower, upper int
+	if _, err := fmt.Sscan(s, &lower, &upper); err != nil {
+		return err
+	}
+
+	return rs.SetValues(lower, upper)
+}
+
+func (rs *RangeSlider) Dispose() {
+	rs.valueToolTip.dispose()
+
+	rs.WidgetBase.Dispose()
+}
+
+// track returns the part of the client area the centers of the thumbs move
+// along, in native pixels.
+func (rs *RangeSlider) track() (left, right int) {
+	cb := rs.ClientBoundsPixels()
+	half := rs.IntFrom96DPI(rangeSliderThumbWidth96dpi)/2 + 1
+
+	return cb.X + half, cb.X + cb.Width - half
+}
+
+func (rs *RangeSlider) valueToPixel(value int) int {
+	left, right := rs.track()
+	if rs.max <= rs.min {
+		return left
+	}
+
+	return left + int(int64(right-left)*int64(value-rs.min)/int64(rs.max-rs.min))
+}
+
+func (rs *RangeSlider) pixelToValue(x int) int {
+	left, right := rs.track()
+	if right <= left {
+		return rs.min
+	}
+
+	value := rs.min + int(math.Round(float64(x-left)*float64(rs.max-rs.min)/float64(right-left)))
+	if rs.snapToTicks {
+		value = snapToTick(value, rs.min, rs.max, rs.tickFrequency)
+	}
+
+	return maxi(rs.min, mini(value, rs.max))
+}
+
+// thumbBounds returns the bounds of the thumb, 0 for the lower and 1 for the
+// upper one, in native pixels.
+func (rs *RangeSlider) thumbBounds(thumb int) Rectangle {
+	value := rs.lower
+	if thumb == 1 {
+		value = rs.upper
+	}
+
+	width := rs.IntFrom96DPI(rangeSliderThumbWidth96dpi)
+	height := rs.IntFrom96DPI(rangeSliderThumbHeight96dpi)
+
+	return Rectangle{rs.valueToPixel(value) - width/2, 0, width, height}
+}
+
+// thumbAt returns the thumb to drag when clicking at x.
+func (rs *RangeSlider) thumbAt(x int) int {
+	lowerX, upperX := rs.valueToPixel(rs.lower), rs.valueToPixel(rs.upper)
+
+	switch {
+	case lowerX == upperX:
+		// Dragging towards the minimum only works with the lower thumb.
+		if x < lowerX || rs.upper == rs.max {
+			return 0
+		}
+		return 1
+
+	case absDiff(x, lowerX) <= absDiff(x, upperX):
+		return 0
+	}
+
+	return 1
+}
+
+func absDiff(a, b int) int {
+	if a < b {
+		return b - a
+	}
+
+	return a - b
+}
+
+// moveThumb moves thumb to value, keeping the thumbs in order.
+func (rs *RangeSlider) moveThumb(thumb, value int, publish bool) bool {
+	if thumb == 0 {
+		return rs.setValues(mini(value, rs.upper), rs.upper, publish)
+	}
+
+	return rs.setValues(rs.lower, maxi(value, rs.lower), publish)
+}
+
+func (rs *RangeSlider) showValueToolTip() {
+	if rs.valueToolTipHidden {
+		return
+	}
+
+	value := rs.lower
+	if rs.activeThumb == 1 {
+		value = rs.upper
+	}
+
+	rs.valueToolTip.show(rs, rs.formatValue(value), rs.thumbBounds(rs.activeThumb).toRECT(), false)
+}
+
+func (rs *RangeSlider) paint(canvas *Canvas, bounds Rectangle) error {
+	bp, err := BeginBufferedPaint(canvas, bounds, win.BPBF_COMPATIBLEBITMAP)
+	if err != nil {
+		return err
+	}
+	defer bp.End()
+
+	buffered, err := bp.Canvas()
+	if err != nil {
+		return err
+	}
+	defer buffered.Dispose()
+
+	if bg, wnd := rs.backgroundEffective(); bg != nil {
+		rs.prepareDCForBackground(buffered.HDC(), rs.hWnd, wnd)
+
+		if err := buffered.FillRectanglePixels(bg, bounds); err != nil {
+			return err
+		}
+	} else if err := rs.fillSysColor(buffered, SysColorBtnFace, bounds); err != nil {
+		return err
+	}
+
+	theme, _ := rs.ThemeForClass(win.VSCLASS_TRACKBAR)
+
+	thumbHeight := rs.IntFrom96DPI(rangeSliderThumbHeight96dpi)
+	trackHeight := rs.IntFrom96DPI(rangeSliderTrackHeight96dpi)
+	left, right := rs.track()
+
+	track := Rectangle{left, (thumbHeight - trackHeight) / 2, right - left, trackHeight}
+	if theme != nil {
+		theme.DrawBackground(buffered, _TKP_TRACK, _TRS_NORMAL, track)
+	} else if err := rs.fillSysColor(buffered, SysColorBtnShadow, track); err != nil {
+		return err
+	}
+
+	selectionColor := Color(win.GetSysColor(win.COLOR_HIGHLIGHT))
+	textColor := Color(win.GetSysColor(win.COLOR_BTNTEXT))
+	if !rs.Enabled() {
+		selectionColor = Color(win.GetSysColor(win.COLOR_GRAYTEXT))
+		textColor = selectionColor
+	}
+
+	selectionBrush, err := NewSolidColorBrush(selectionColor)
+	if err != nil {
+		return err
+	}
+	defer selectionBrush.Dispose()
+
+	lowerX, upperX := rs.valueToPixel(rs.lower), rs.valueToPixel(rs.upper)
+	if err := buffered.FillRectanglePixels(selectionBrush, Rectangle{lowerX, track.Y + 1, upperX - lowerX, track.Height - 2}); err != nil {
+		return err
+	}
+
+	tickBrush, err := NewSolidColorBrush(textColor)
+	if err != nil {
+		return err
+	}
+	defer tickBrush.Dispose()
+
+	ticks := sliderTicks(rs.min, rs.max, rs.tickFrequency)
+	tickY := thumbHeight + rs.IntFrom96DPI(1)
+	tickLength := rs.IntFrom96DPI(rangeSliderTickLength96dpi)
+	for _, value := range ticks {
+		if err := buffered.FillRectanglePixels(tickBrush, Rectangle{rs.valueToPixel(value), tickY, rs.IntFrom96DPI(1), tickLength}); err != nil {
+			return err
+		}
+	}
+
+	if rs.tickLabelsVisible {
+		labelY := tickY + tickLength + rs.IntFrom96DPI(sliderTickLabelGap96dpi)
+		cb := rs.ClientBoundsPixels()
+
+		// Labels overlapping the previous one are left out.
+		last := math.MinInt32
+		for _, value := range ticks {
+			text := rs.formatValue(value)
+			size := rs.calculateTextSizeImpl(text)
+
+			x := maxi(cb.X, mini(rs.valueToPixel(value)-size.Width/2, cb.X+cb.Width-size.Width))
+			if x < last {
+				continue
+			}
+			last = x + size.Width + rs.IntFrom96DPI(sliderTickLabelGap96dpi)
+
+			buffered.DrawTextPixels(text, rs.Font(), textColor, Rectangle{x, labelY, size.Width, size.Height}, TextLeft|TextSingleLine|TextNoPrefix)
+		}
+	}
+
+	// The active thumb is drawn last, on top of the other one.
+	for _, thumb := range []int{1 - rs.activeThumb, rs.activeThumb} {
+		tb := rs.thumbBounds(thumb)
+
+		state := int32(_TUBS_NORMAL)
+		switch {
+		case !rs.Enabled():
+			state = _TUBS_DISABLED
+
+		case rs.dragging && thumb == rs.activeThumb:
+			state = _TUBS_PRESSED
+
+		case thumb == rs.hotThumb:
+			state = _TUBS_HOT
+
+		case rs.Focused() && thumb == rs.activeThumb:
+			state = _TUBS_FOCUSED
+		}
+
+		if theme != nil {
+			theme.DrawBackground(buffered, _TKP_THUMBBOTTOM, state, tb)
+		} else if err := rs.fillSysColor(buffered, SysColorBtnShadow, tb); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (rs *RangeSlider) fillSysColor(canvas *Canvas, color SystemColor, bounds Rectangle) error {
+	brush, err := NewSystemColorBrush(color)
+	if err != nil {
+		return err
+	}
+	defer brush.Dispose()
+
+	return canvas.FillRectanglePixels(brush, bounds)
+}
+
+func (rs *RangeSlider) WndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) uintptr {
+	switch msg {
+	case win.WM_PAINT:
+		var ps win.PAINTSTRUCT
+
+		hdc := win.BeginPaint(hwnd, &ps)
+		defer win.EndPaint(hwnd, &ps)
+
+		canvas, err := newCanvasFromHDC(hdc)
+		if err != nil {
+			return 0
+		}
+		defer canvas.Dispose()
+
+		rs.paint(canvas, rs.ClientBoundsPixels())
+
+		return 0
+
+	case win.WM_ERASEBKGND:
+		// Everything is drawn in WM_PAINT.
+		return 1
+
+	case win.WM_GETDLGCODE:
+		return win.DLGC_WANTARROWS
+
+	case win.WM_SETFOCUS, win.WM_KILLFOCUS, win.WM_SIZE, win.WM_ENABLE:
+		rs.Invalidate()
+
+	case win.WM_LBUTTONDOWN:
+		rs.SetFocus()
+
+		x := int(win.GET_X_LPARAM(lParam))
+
+		rs.activeThumb = rs.thumbAt(x)
+		rs.dragging = true
+
+		// Clicking next to a thumb moves it there.
+		rs.moveThumb(rs.activeThumb, rs.pixelToValue(x), rs.tracking)
+		rs.showValueToolTip()
+		rs.Invalidate()
+
+	case win.WM_MOUSEMOVE:
+		x := int(win.GET_X_LPARAM(lParam))
+
+		if rs.dragging {
+			if rs.moveThumb(rs.activeThumb, rs.pixelToValue(x), rs.tracking) {
+				rs.showValueToolTip()
+			}
+			break
+		}
+
+		hot := -1
+		for thumb := 0; thumb < 2; thumb++ {
+			if tb := rs.thumbBounds(thumb); x >= tb.X && x < tb.X+tb.Width {
+				hot = thumb
+			}
+		}
+		if hot != rs.hotThumb {
+			rs.hotThumb = hot
+			rs.Invalidate()
+		}
+
+	case win.WM_LBUTTONUP, win.WM_CAPTURECHANGED:
+		if rs.dragging {
+			rs.dragging = false
+			rs.valueToolTip.hide(rs)
+			rs.Invalidate()
+
+			if !rs.tracking {
+				rs.publishValuesChanged(true, true)
+			}
+		}
+
+	case win.WM_KEYDOWN:
+		value := rs.lower
+		if rs.activeThumb == 1 {
+			value = rs.upper
+		}
+
+		step := rs.lineSize
+		if rs.snapToTicks && rs.tickFrequency > 0 {
+			step = rs.tickFrequency
+		}
+
+		switch Key(wParam) {
+		case KeyLeft, KeyDown:
+			value -= step
+
+		case KeyRight, KeyUp:
+			value += step
+
+		case KeyPrior:
+			value -= rs.pageSize
+
+		case KeyNext:
+			value += rs.pageSize
+
+		case KeyHome:
+			value = rs.min
+
+		case KeyEnd:
+			value = rs.max
+
+		case KeySpace:
+			rs.activeThumb = 1 - rs.activeThumb
+			rs.Invalidate()
+			return rs.WidgetBase.WndProc(hwnd, msg, wParam, lParam)
+
+		default:
+			return rs.WidgetBase.WndProc(hwnd, msg, wParam, lParam)
+		}
+
+		rs.moveThumb(rs.activeThumb, value, true)
+	}
+
+	return rs.WidgetBase.WndProc(hwnd, msg, wParam, lParam)
+}
+
+func (rs *RangeSlider) CreateLayoutItem(ctx *LayoutContext) LayoutItem {
+	height := IntFrom96DPI(rangeSliderThumbHeight96dpi+1+rangeSliderTickLength96dpi, ctx.dpi)
+	if rs.tickLabelsVisible {
+		var labelHeight int
+		for _, value := range sliderTicks(rs.min, rs.max, rs.tickFrequency) {
+			labelHeight = maxi(labelHeight, rs.calculateTextSizeImpl(rs.formatValue(value)).Height)
+		}
+
+		height += IntFrom96DPI(sliderTickLabelGap96dpi, ctx.dpi) + labelHeight
+	}
+
+	return &sliderLayoutItem{
+		layoutFlags: ShrinkableHorz | GrowableHorz,
+		idealSize:   Size{rs.dialogBaseUnitsToPixels(Size{30, 0}).Width, height},
+	}
+}
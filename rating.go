@@ -0,0 +1,556 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"math"
+	"strconv"
+	"unsafe"
+
+	"github.com/tailscale/win"
+)
+
+const ratingWindowClass = `\o/ Walk_Rating_Class \o/`
+
+func init() {
+	AppendToWalkInit(func() {
+		MustRegisterWindowClass(ratingWindowClass)
+	})
+}
+
+const (
+	ratingGlyphSize96dpi = 20
+	ratingSpacing96dpi   = 4
+	ratingPadding96dpi   = 2
+	ratingDefaultMax     = 5
+)
+
+// Rating is a widget that displays a value as a row of stars and lets the
+// user pick one by clicking a star, or using the arrow, Home and End or digit
+// keys. While the mouse hovers over the stars, the value that a click would
+// set is previewed.
+//
+// Clicking the star of the current value clears the rating.
+type Rating struct {
+	WidgetBase
+	maxValue              int
+	value                 float64
+	hoverValue            float64
+	halfSteps             bool
+	readOnly              bool
+	color                 Color
+	filledGlyph           Image
+	emptyGlyph            Image
+	valueChangedPublisher EventPublisher
+}
+
+// NewRating returns a new Rating with five stars as child of parent.
+func NewRating(parent Container) (*Rating, error) {
+	r := &Rating{
+		maxValue:   ratingDefaultMax,
+		hoverValue: -1,
+	}
+
+	if err := InitWidget(
+		r,
+		parent,
+		ratingWindowClass,
+		win.WS_VISIBLE|win.WS_TABSTOP,
+		0); err != nil {
+		return nil, err
+	}
+
+	r.Accessibility().SetRole(AccRoleSlider)
+	r.updateAccessibleValue()
+
+	r.MustRegisterProperty("Value", NewProperty(
+		func() interface{} {
+			return r.Value()
+		},
+		func(v interface{}) error {
+			return r.SetValue(assertFloat64Or(v, 0))
+		},
+		r.valueChangedPublisher.Event()))
+
+	r.MustRegisterProperty("ReadOnly", NewBoolProperty(
+		func() bool {
+			return r.ReadOnly()
+		},
+		func(b bool) error {
+			r.SetReadOnly(b)
+			return nil
+		},
+		nil))
+
+	return r, nil
+}
+
+// MaxValue returns the number of stars.
+func (r *Rating) MaxValue() int {
+	return r.maxValue
+}
+
+// SetMaxValue sets the number of stars. The value is reduced if needed.
+func (r *Rating) SetMaxValue(maxValue int) error {
+	if maxValue < 1 {
+		return newError("maxValue must be >= 1")
+	}
+	if maxValue == r.maxValue {
+		return nil
+	}
+
+	r.maxValue = maxValue
+
+	if r.value > float64(maxValue) {
+		r.setValue(float64(maxValue))
+	}
+
+	r.Invalidate()
+	r.RequestLayout()
+
+	return nil
+}
+
+// Value returns the rating, from 0 to MaxValue.
+func (r *Rating) Value() float64 {
+	return r.value
+}
+
+// SetValue sets the rating, from 0 to MaxValue. It is rounded to a whole or
+// half star, depending on HalfSteps.
+func (r *Rating) SetValue(value float64) error {
+	if value < 0 || value > float64(r.maxValue) {
+		return newError("value out of range")
+	}
+
+	r.setValue(r.roundValue(value))
+
+	return nil
+}
+
+// ValueChanged returns the event that is published when the rating changes.
+func (r *Rating) ValueChanged() *Event {
+	return r.valueChangedPublisher.Event()
+}
+
+// HalfSteps returns whether half stars can be picked.
+func (r *Rating) HalfSteps() bool {
+	return r.halfSteps
+}
+
+// SetHalfSteps sets whether half stars can be picked, by clicking the left
+// half of a star.
+func (r *Rating) SetHalfSteps(halfSteps bool) {
+	r.halfSteps = halfSteps
+
+	r.setValue(r.roundValue(r.value))
+}
+
+// ReadOnly returns whether the user cannot change the rating.
+func (r *Rating) ReadOnly() bool {
+	return r.readOnly
+}
+
+// SetReadOnly sets whether the user cannot change the rating.
+func (r *Rating) SetReadOnly(readOnly bool) {
+	if readOnly == r.readOnly {
+		return
+	}
+
+	r.readOnly = readOnly
+	r.hoverValue = -1
+
+	r.ensureStyleBits(win.WS_TABSTOP, !readOnly)
+
+	r.Invalidate()
+}
+
+// Color returns the color of the stars. Zero means the default gold.
+func (r *Rating) Color() Color {
+	return r.color
+}
+
+// SetColor sets the color of the stars. Zero means the default gold.
+func (r *Rating) SetColor(color Color) {
+	if color == r.color {
+		return
+	}
+
+	r.color = color
+
+	r.Invalidate()
+}
+
+// Glyphs returns the images drawn instead of the stars, if any.
+func (r *Rating) Glyphs() (filled, empty Image) {
+	return r.filledGlyph, r.emptyGlyph
+}
+
+// SetGlyphs sets images to draw instead of the stars. Both are stretched to
+// 20x20 1/96" units, and half values are drawn by combining the left half of
+// filled with the right half of empty. Passing nil restores the stars.
+func (r *Rating) SetGlyphs(filled, empty Image) error {
+	if (filled == nil) != (empty == nil) {
+		return newError("both glyphs or none must be set")
+	}
+
+	r.filledGlyph, r.emptyGlyph = filled, empty
+
+	r.Invalidate()
+
+	return nil
+}
+
+func (r *Rating) step() float64 {
+	if r.halfSteps {
+		return 0.5
+	}
+
+	return 1
+}
+
+func (r *Rating) roundValue(value float64) float64 {
+	step := r.step()
+	value = math.Round(value/step) * step
+
+	return math.Max(0, math.Min(value, float64(r.maxValue)))
+}
+
+func (r *Rating) setValue(value float64) {
+	if value == r.value {
+		return
+	}
+
+	r.value = value
+
+	r.updateAccessibleValue()
+	r.Invalidate()
+
+	r.valueChangedPublisher.Publish()
+}
+
+func (r *Rating) updateAccessibleValue() {
+	text := strconv.FormatFloat(r.value, 'f', -1, 64) + " / " + strconv.Itoa(r.maxValue)
+
+	r.Accessibility().accSetPropertyStr(r.hWnd, &win.PROPID_ACC_VALUE, win.EVENT_OBJECT_VALUECHANGE, text)
+}
+
+// glyphBounds returns the bounds of star index in native pixels.
+func (r *Rating) glyphBounds(index int) Rectangle {
+	size := r.IntFrom96DPI(ratingGlyphSize96dpi)
+	padding := r.IntFrom96DPI(ratingPadding96dpi)
+	cb := r.ClientBoundsPixels()
+
+	return Rectangle{
+		X:      padding + index*(size+r.IntFrom96DPI(ratingSpacing96dpi)),
+		Y:      (cb.Height - size) / 2,
+		Width:  size,
+		Height: size,
+	}
+}
+
+// valueAt returns the value that clicking at x would set, or -1 if x is not
+// on a star.
+func (r *Rating) valueAt(x int) float64 {
+	for i := 0; i < r.maxValue; i++ {
+		b := r.glyphBounds(i)
+		if x < b.X || x >= b.X+b.Width+r.IntFrom96DPI(ratingSpacing96dpi) {
+			continue
+		}
+
+		if r.halfSteps && x < b.X+b.Width/2 {
+			return float64(i) + 0.5
+		}
+
+		return float64(i + 1)
+	}
+
+	return -1
+}
+
+func (r *Rating) setHoverValue(value float64) {
+	if value == r.hoverValue {
+		return
+	}
+
+	if r.hoverValue < 0 {
+		var tme win.TRACKMOUSEEVENT
+		tme.CbSize = uint32(unsafe.Sizeof(tme))
+		tme.DwFlags = win.TME_LEAVE
+		tme.HwndTrack = r.hWnd
+
+		win.TrackMouseEvent(&tme)
+	}
+
+	r.hoverValue = value
+
+	r.Invalidate()
+}
+
+// starPoints returns the outline of a five-pointed star inside bounds.
+func starPoints(bounds Rectangle) []Point {
+	cx := float64(bounds.X) + float64(bounds.Width)/2
+	cy := float64(bounds.Y) + float64(bounds.Height)/2
+	outer := float64(bounds.Width) / 2
+	inner := outer * 0.4
+
+	points := make([]Point, 10)
+	for i := range points {
+		radius := outer
+		if i%2 == 1 {
+			radius = inner
+		}
+
+		a := -math.Pi/2 + float64(i)*math.Pi/5
+		points[i] = Point{int(math.Round(cx + radius*math.Cos(a))), int(math.Round(cy + radius*math.Sin(a)))}
+	}
+
+	return points
+}
+
+// paintGlyph paints star or custom glyph filled, clipped to clip.
+func (r *Rating) paintGlyph(canvas *Canvas, bounds, clip Rectangle, filled bool, color Color) error {
+	if clip.Width <= 0 {
+		return nil
+	}
+
+	hdc := canvas.HDC()
+	saved := win.SaveDC(hdc)
+	defer win.RestoreDC(hdc, saved)
+
+	win.IntersectClipRect(hdc, int32(clip.X), int32(clip.Y), int32(clip.X+clip.Width), int32(clip.Y+clip.Height))
+
+	if r.filledGlyph != nil {
+		glyph := r.emptyGlyph
+		if filled {
+			glyph = r.filledGlyph
+		}
+
+		return canvas.DrawImageStretchedPixels(glyph, bounds)
+	}
+
+	brush, err := NewSolidColorBrush(color)
+	if err != nil {
+		return err
+	}
+	defer brush.Dispose()
+
+	points := starPoints(bounds)
+
+	if filled {
+		return canvas.FillPolygonPixels(brush, points)
+	}
+
+	pen, err := NewGeometricPen(PenSolid|PenJoinRound, 1, brush)
+	if err != nil {
+		return err
+	}
+	defer pen.Dispose()
+
+	return canvas.DrawPolylinePixels(pen, append(points, points[0]))
+}
+
+func (r *Rating) paint(canvas *Canvas, bounds Rectangle) error {
+	bp, err := BeginBufferedPaint(canvas, bounds, win.BPBF_COMPATIBLEBITMAP)
+	if err != nil {
+		return err
+	}
+	defer bp.End()
+
+	buffered, err := bp.Canvas()
+	if err != nil {
+		return err
+	}
+	defer buffered.Dispose()
+
+	if bg, wnd := r.backgroundEffective(); bg != nil {
+		r.prepareDCForBackground(buffered.HDC(), r.hWnd, wnd)
+
+		if err := buffered.FillRectanglePixels(bg, bounds); err != nil {
+			return err
+		}
+	} else {
+		brush, err := NewSystemColorBrush(SysColorBtnFace)
+		if err != nil {
+			return err
+		}
+		err = buffered.FillRectanglePixels(brush, bounds)
+		brush.Dispose()
+		if err != nil {
+			return err
+		}
+	}
+
+	value := r.value
+	if r.hoverValue >= 0 {
+		value = r.hoverValue
+	}
+
+	filledColor := r.color
+	if filledColor == 0 {
+		filledColor = RGB(0xFF, 0xB9, 0x00)
+	}
+	emptyColor := Color(win.GetSysColor(win.COLOR_GRAYTEXT))
+	if !r.Enabled() {
+		filledColor = emptyColor
+	}
+
+	for i := 0; i < r.maxValue; i++ {
+		b := r.glyphBounds(i)
+
+		fill := math.Max(0, math.Min(value-float64(i), 1))
+		split := b.X + int(math.Round(fill*float64(b.Width)))
+
+		if err := r.paintGlyph(buffered, b, Rectangle{split, b.Y, b.X + b.Width - split, b.Height}, false, emptyColor); err != nil {
+			return err
+		}
+		if err := r.paintGlyph(buffered, b, Rectangle{b.X, b.Y, split - b.X, b.Height}, true, filledColor); err != nil {
+			return err
+		}
+	}
+
+	if win.GetFocus() == r.hWnd && r.maxValue > 0 && r.FocusCuesVisible() {
+		first, last := r.glyphBounds(0), r.glyphBounds(r.maxValue-1)
+		padding := int32(r.IntFrom96DPI(ratingPadding96dpi))
+
+		rc := win.RECT{
+			Left:   int32(first.X) - padding,
+			Top:    int32(first.Y) - padding,
+			Right:  int32(last.X+last.Width) + padding,
+			Bottom: int32(last.Y+last.Height) + padding,
+		}
+		win.DrawFocusRect(buffered.HDC(), &rc)
+	}
+
+	return nil
+}
+
+func (r *Rating) WndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) uintptr {
+	switch msg {
+	case win.WM_PAINT:
+		var ps win.PAINTSTRUCT
+
+		hdc := win.BeginPaint(hwnd, &ps)
+		defer win.EndPaint(hwnd, &ps)
+
+		canvas, err := newCanvasFromHDC(hdc)
+		if err != nil {
+			return 0
+		}
+		defer canvas.Dispose()
+
+		r.paint(canvas, r.ClientBoundsPixels())
+
+		return 0
+
+	case win.WM_ERASEBKGND:
+		// Everything is drawn in WM_PAINT.
+		return 1
+
+	case win.WM_GETDLGCODE:
+		if !r.readOnly {
+			return win.DLGC_WANTARROWS | win.DLGC_WANTCHARS
+		}
+
+	case win.WM_SETFOCUS, win.WM_KILLFOCUS:
+		r.Invalidate()
+
+	case win.WM_MOUSEMOVE:
+		if !r.readOnly {
+			r.setHoverValue(r.valueAt(int(win.GET_X_LPARAM(lParam))))
+		}
+
+	case win.WM_MOUSELEAVE:
+		r.setHoverValue(-1)
+
+	case win.WM_LBUTTONDOWN:
+		if r.readOnly {
+			break
+		}
+
+		win.SetFocus(r.hWnd)
+
+		if value := r.valueAt(int(win.GET_X_LPARAM(lParam))); value >= 0 {
+			if value == r.value {
+				value = 0
+			}
+
+			r.setValue(value)
+		}
+
+	case win.WM_KEYDOWN:
+		if r.readOnly {
+			break
+		}
+
+		switch Key(wParam) {
+		case KeyLeft, KeyDown:
+			r.setValue(r.roundValue(r.value - r.step()))
+			return 0
+
+		case KeyRight, KeyUp:
+			r.setValue(r.roundValue(r.value + r.step()))
+			return 0
+
+		case KeyHome:
+			r.setValue(0)
+			return 0
+
+		case KeyEnd:
+			r.setValue(float64(r.maxValue))
+			return 0
+		}
+
+	case win.WM_CHAR:
+		if r.readOnly {
+			break
+		}
+
+		if wParam >= '0' && wParam <= '9' {
+			if value := int(wParam - '0'); value <= r.maxValue {
+				r.setValue(float64(value))
+			}
+			return 0
+		}
+
+	case win.WM_SIZE:
+		r.Invalidate()
+	}
+
+	return r.WidgetBase.WndProc(hwnd, msg, wParam, lParam)
+}
+
+func (r *Rating) CreateLayoutItem(ctx *LayoutContext) LayoutItem {
+	size := IntFrom96DPI(ratingGlyphSize96dpi, ctx.dpi)
+	spacing := IntFrom96DPI(ratingSpacing96dpi, ctx.dpi)
+	padding := IntFrom96DPI(ratingPadding96dpi, ctx.dpi)
+
+	return &ratingLayoutItem{
+		idealSize: Size{
+			Width:  r.maxValue*size + (r.maxValue-1)*spacing + 2*padding,
+			Height: size + 2*padding,
+		},
+	}
+}
+
+type ratingLayoutItem struct {
+	LayoutItemBase
+	idealSize Size // in native pixels
+}
+
+func (*ratingLayoutItem) LayoutFlags() LayoutFlags {
+	return 0
+}
+
+func (li *ratingLayoutItem) IdealSize() Size {
+	return li.idealSize
+}
+
+func (li *ratingLayoutItem) MinSize() Size {
+	return li.idealSize
+}
@@ -0,0 +1,488 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"syscall"
+	"unsafe"
+
+	"github.com/tailscale/win"
+)
+
+// Rebar is a container that hosts each of its children, typically ToolBars,
+// in a band of the ReBarWindow32 common control. The user can move bands
+// between rows by dragging their grippers and collapse or expand them by
+// double-clicking. The arrangement is saved and restored via SaveState and
+// RestoreState if the Rebar is persistent.
+type Rebar struct {
+	ContainerBase
+	hwnd2Band              map[win.HWND]*rebarBand
+	nextBandID             uint32
+	locked                 bool
+	persistent             bool
+	layoutChangedPublisher EventPublisher
+	barHeight              int // in native pixels
+	lastLayoutBandSizes    map[win.HWND]Size
+}
+
+type rebarBand struct {
+	id                   uint32
+	visibleChangedHandle int
+}
+
+// NewRebar returns a new Rebar as child of parent.
+func NewRebar(parent Container) (*Rebar, error) {
+	rb := &Rebar{
+		hwnd2Band:           make(map[win.HWND]*rebarBand),
+		nextBandID:          1,
+		lastLayoutBandSizes: make(map[win.HWND]Size),
+	}
+	rb.children = newWidgetList(rb)
+
+	// The layout is only used for margins; bands are positioned by the
+	// control itself.
+	rb.layout = NewHBoxLayout()
+	rb.layout.SetMargins(Margins{})
+	rb.layout.SetContainer(rb)
+
+	if err := InitWidget(
+		rb,
+		parent,
+		"ReBarWindow32",
+		win.WS_VISIBLE|win.WS_CLIPSIBLINGS|win.WS_CLIPCHILDREN|
+			win.CCS_NODIVIDER|win.CCS_NORESIZE|win.CCS_NOPARENTALIGN|
+			_RBS_VARHEIGHT|_RBS_BANDBORDERS|_RBS_DBLCLKTOGGLE,
+		win.WS_EX_CONTROLPARENT); err != nil {
+		return nil, err
+	}
+
+	return rb, nil
+}
+
+func (rb *Rebar) SetLayout(value Layout) error {
+	return newError("not supported")
+}
+
+// Locked returns whether the user is prevented from moving bands.
+func (rb *Rebar) Locked() bool {
+	return rb.locked
+}
+
+// SetLocked sets whether the user is prevented from moving bands. Locked
+// bands have no gripper.
+func (rb *Rebar) SetLocked(locked bool) error {
+	if locked == rb.locked {
+		return nil
+	}
+
+	rb.locked = locked
+
+	for _, wb := range rb.children.items {
+		if err := rb.setBandStyle(wb.window.(Widget), _RBBS_NOGRIPPER, locked); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RowCount returns the number of rows the bands are currently arranged in.
+func (rb *Rebar) RowCount() int {
+	return int(rb.SendMessage(_RB_GETROWCOUNT, 0, 0))
+}
+
+// BandText returns the title displayed in front of the band hosting widget.
+func (rb *Rebar) BandText(widget Widget) string {
+	buf := make([]uint16, 256)
+
+	rbbi := rebarBandInfo{
+		fMask:  _RBBIM_TEXT,
+		lpText: &buf[0],
+		cch:    uint32(len(buf)),
+	}
+	if err := rb.bandInfo(widget, &rbbi); err != nil {
+		return ""
+	}
+
+	return syscall.UTF16ToString(buf)
+}
+
+// SetBandText sets the title displayed in front of the band hosting widget.
+func (rb *Rebar) SetBandText(widget Widget, text string) error {
+	return rb.setBandInfo(widget, &rebarBandInfo{
+		fMask:  _RBBIM_TEXT,
+		lpText: syscall.StringToUTF16Ptr(text),
+	})
+}
+
+// BandBreak returns whether the band hosting widget starts a new row.
+func (rb *Rebar) BandBreak(widget Widget) bool {
+	return rb.bandStyle(widget)&_RBBS_BREAK != 0
+}
+
+// SetBandBreak sets whether the band hosting widget starts a new row.
+func (rb *Rebar) SetBandBreak(widget Widget, lineBreak bool) error {
+	return rb.setBandStyle(widget, _RBBS_BREAK, lineBreak)
+}
+
+// MinimizeBand collapses the band hosting widget to its smallest size.
+func (rb *Rebar) MinimizeBand(widget Widget) error {
+	index, err := rb.bandIndex(widget)
+	if err != nil {
+		return err
+	}
+
+	rb.SendMessage(_RB_MINIMIZEBAND, uintptr(index), 0)
+
+	return nil
+}
+
+// MaximizeBand expands the band hosting widget to the largest size its row
+// allows.
+func (rb *Rebar) MaximizeBand(widget Widget) error {
+	index, err := rb.bandIndex(widget)
+	if err != nil {
+		return err
+	}
+
+	rb.SendMessage(_RB_MAXIMIZEBAND, uintptr(index), 0)
+
+	return nil
+}
+
+// LayoutChanged returns the event that is published when the user moves or
+// resizes a band.
+func (rb *Rebar) LayoutChanged() *Event {
+	return rb.layoutChangedPublisher.Event()
+}
+
+func (rb *Rebar) bandIndex(widget Widget) (int, error) {
+	band, ok := rb.hwnd2Band[widget.Handle()]
+	if !ok {
+		return -1, newError("widget is not hosted by this Rebar")
+	}
+
+	return int(rb.SendMessage(_RB_IDTOINDEX, uintptr(band.id), 0)), nil
+}
+
+func (rb *Rebar) bandInfo(widget Widget, rbbi *rebarBandInfo) error {
+	index, err := rb.bandIndex(widget)
+	if err != nil {
+		return err
+	}
+
+	rbbi.cbSize = uint32(unsafe.Sizeof(*rbbi))
+	if 0 == rb.SendMessage(_RB_GETBANDINFO, uintptr(index), uintptr(unsafe.Pointer(rbbi))) {
+		return newError("RB_GETBANDINFO failed")
+	}
+
+	return nil
+}
+
+func (rb *Rebar) setBandInfo(widget Widget, rbbi *rebarBandInfo) error {
+	index, err := rb.bandIndex(widget)
+	if err != nil {
+		return err
+	}
+
+	rbbi.cbSize = uint32(unsafe.Sizeof(*rbbi))
+	if 0 == rb.SendMessage(_RB_SETBANDINFO, uintptr(index), uintptr(unsafe.Pointer(rbbi))) {
+		return newError("RB_SETBANDINFO failed")
+	}
+
+	return nil
+}
+
+func (rb *Rebar) bandStyle(widget Widget) uint32 {
+	rbbi := rebarBandInfo{fMask: _RBBIM_STYLE}
+	if err := rb.bandInfo(widget, &rbbi); err != nil {
+		return 0
+	}
+
+	return rbbi.fStyle
+}
+
+func (rb *Rebar) setBandStyle(widget Widget, bits uint32, set bool) error {
+	style := rb.bandStyle(widget)
+	if set {
+		style |= bits
+	} else {
+		style &^= bits
+	}
+
+	return rb.setBandInfo(widget, &rebarBandInfo{
+		fMask:  _RBBIM_STYLE,
+		fStyle: style,
+	})
+}
+
+// updateBandSizes updates the child size of each band from the current layout
+// of its widget.
+func (rb *Rebar) updateBandSizes() {
+	for _, wb := range rb.children.items {
+		widget := wb.window.(Widget)
+
+		item := createLayoutItemForWidget(widget)
+		if item == nil {
+			continue
+		}
+
+		size := minSizeEffective(item)
+		ideal := size
+		if is, ok := item.(IdealSizer); ok {
+			ideal = maxSize(ideal, is.IdealSize())
+		}
+
+		if rb.lastLayoutBandSizes[widget.Handle()] == ideal {
+			continue
+		}
+		rb.lastLayoutBandSizes[widget.Handle()] = ideal
+
+		rb.setBandInfo(widget, &rebarBandInfo{
+			fMask:      _RBBIM_CHILDSIZE | _RBBIM_IDEALSIZE,
+			cxMinChild: uint32(size.Width),
+			cyMinChild: uint32(ideal.Height),
+			cyChild:    uint32(ideal.Height),
+			cxIdeal:    uint32(ideal.Width),
+		})
+	}
+}
+
+func (rb *Rebar) onInsertedWidget(index int, widget Widget) (err error) {
+	if err = rb.ContainerBase.onInsertedWidget(index, widget); err != nil {
+		return
+	}
+
+	if tb, ok := widget.(*ToolBar); ok {
+		// Let the band, not the ToolBar, determine its position.
+		tb.ensureStyleBits(win.CCS_NORESIZE|win.CCS_NOPARENTALIGN|win.CCS_NODIVIDER, true)
+	}
+
+	band := &rebarBand{id: rb.nextBandID}
+	rb.nextBandID++
+
+	style := uint32(_RBBS_CHILDEDGE)
+	if rb.locked {
+		style |= _RBBS_NOGRIPPER
+	}
+	if !widget.Visible() {
+		style |= _RBBS_HIDDEN
+	}
+
+	rbbi := rebarBandInfo{
+		fMask:     _RBBIM_STYLE | _RBBIM_CHILD | _RBBIM_ID,
+		fStyle:    style,
+		hwndChild: widget.Handle(),
+		wID:       band.id,
+	}
+	rbbi.cbSize = uint32(unsafe.Sizeof(rbbi))
+
+	if 0 == rb.SendMessage(_RB_INSERTBAND, uintptr(index), uintptr(unsafe.Pointer(&rbbi))) {
+		return newError("RB_INSERTBAND failed")
+	}
+
+	rb.hwnd2Band[widget.Handle()] = band
+
+	band.visibleChangedHandle = widget.VisibleChanged().Attach(func() {
+		if index, err := rb.bandIndex(widget); err == nil {
+			rb.SendMessage(_RB_SHOWBAND, uintptr(index), uintptr(win.BoolToBOOL(widget.Visible())))
+		}
+	})
+
+	rb.updateBandSizes()
+
+	return nil
+}
+
+func (rb *Rebar) onRemovingWidget(index int, widget Widget) (err error) {
+	if band, ok := rb.hwnd2Band[widget.Handle()]; ok {
+		widget.VisibleChanged().Detach(band.visibleChangedHandle)
+
+		if index, err := rb.bandIndex(widget); err == nil {
+			rb.SendMessage(_RB_DELETEBAND, uintptr(index), 0)
+		}
+
+		delete(rb.hwnd2Band, widget.Handle())
+		delete(rb.lastLayoutBandSizes, widget.Handle())
+	}
+
+	return rb.ContainerBase.onRemovingWidget(index, widget)
+}
+
+func (rb *Rebar) onClearingWidgets() (err error) {
+	for i := rb.children.Len() - 1; i >= 0; i-- {
+		if err = rb.onRemovingWidget(i, rb.children.At(i)); err != nil {
+			return
+		}
+	}
+
+	return rb.ContainerBase.onClearingWidgets()
+}
+
+func (rb *Rebar) Persistent() bool {
+	return rb.persistent
+}
+
+func (rb *Rebar) SetPersistent(value bool) {
+	rb.persistent = value
+}
+
+// SaveState writes the order, row breaks and widths of the bands.
+func (rb *Rebar) SaveState() error {
+	buf := bytes.NewBuffer(nil)
+
+	id2Child := make(map[uint32]int)
+	for i, wb := range rb.children.items {
+		if band, ok := rb.hwnd2Band[wb.hWnd]; ok {
+			id2Child[band.id] = i
+		}
+	}
+
+	count := int(rb.SendMessage(_RB_GETBANDCOUNT, 0, 0))
+	for i := 0; i < count; i++ {
+		rbbi := rebarBandInfo{fMask: _RBBIM_ID | _RBBIM_SIZE | _RBBIM_STYLE}
+		rbbi.cbSize = uint32(unsafe.Sizeof(rbbi))
+		rb.SendMessage(_RB_GETBANDINFO, uintptr(i), uintptr(unsafe.Pointer(&rbbi)))
+
+		child, ok := id2Child[rbbi.wID]
+		if !ok {
+			continue
+		}
+
+		var lineBreak int
+		if rbbi.fStyle&_RBBS_BREAK != 0 {
+			lineBreak = 1
+		}
+
+		if buf.Len() > 0 {
+			buf.WriteString(" ")
+		}
+		fmt.Fprintf(buf, "%d:%d:%d", child, rbbi.cx, lineBreak)
+	}
+
+	if err := rb.WriteState(buf.String()); err != nil {
+		return err
+	}
+
+	return rb.ContainerBase.SaveState()
+}
+
+// RestoreState restores the order, row breaks and widths of the bands.
+func (rb *Rebar) RestoreState() error {
+	state, err := rb.ReadState()
+	if err != nil {
+		return err
+	}
+
+	if state != "" {
+		for position, s := range strings.Split(state, " ") {
+			var child, cx, lineBreak int
+			if _, err := fmt.Sscanf(s, "%d:%d:%d", &child, &cx, &lineBreak); err != nil {
+				return err
+			}
+			if child < 0 || child >= rb.children.Len() {
+				continue
+			}
+
+			widget := rb.children.At(child)
+
+			index, err := rb.bandIndex(widget)
+			if err != nil {
+				continue
+			}
+			rb.SendMessage(_RB_MOVEBAND, uintptr(index), uintptr(position))
+
+			if err := rb.setBandStyle(widget, _RBBS_BREAK, lineBreak != 0); err != nil {
+				return err
+			}
+			if err := rb.setBandInfo(widget, &rebarBandInfo{fMask: _RBBIM_SIZE, cx: uint32(cx)}); err != nil {
+				return err
+			}
+		}
+	}
+
+	return rb.ContainerBase.RestoreState()
+}
+
+func (rb *Rebar) WndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) uintptr {
+	switch msg {
+	case win.WM_NOTIFY:
+		nmh := (*win.NMHDR)(unsafe.Pointer(lParam))
+		if nmh.HwndFrom != rb.hWnd {
+			break
+		}
+
+		// Our own notifications, forwarded by the parent.
+		switch nmh.Code {
+		case _RBN_HEIGHTCHANGE:
+			if int(rb.SendMessage(_RB_GETBARHEIGHT, 0, 0)) != rb.barHeight {
+				rb.RequestLayout()
+			}
+
+		case _RBN_LAYOUTCHANGE:
+			rb.layoutChangedPublisher.Publish()
+		}
+
+		return 0
+	}
+
+	return rb.ContainerBase.WndProc(hwnd, msg, wParam, lParam)
+}
+
+func (rb *Rebar) CreateLayoutItem(ctx *LayoutContext) LayoutItem {
+	rb.updateBandSizes()
+
+	rb.barHeight = int(rb.SendMessage(_RB_GETBARHEIGHT, 0, 0))
+
+	var idealWidth int
+	for _, size := range rb.lastLayoutBandSizes {
+		idealWidth += size.Width
+	}
+
+	return &rebarLayoutItem{
+		idealSize: Size{idealWidth, rb.barHeight},
+	}
+}
+
+type rebarLayoutItem struct {
+	ContainerLayoutItemBase
+	idealSize Size // in native pixels
+}
+
+func (*rebarLayoutItem) LayoutFlags() LayoutFlags {
+	return ShrinkableHorz | GrowableHorz | GreedyHorz
+}
+
+func (li *rebarLayoutItem) IdealSize() Size {
+	return li.idealSize
+}
+
+func (li *rebarLayoutItem) MinSize() Size {
+	return Size{Height: li.idealSize.Height}
+}
+
+func (li *rebarLayoutItem) MinSizeForSize(size Size) Size {
+	return li.MinSize()
+}
+
+func (*rebarLayoutItem) HasHeightForWidth() bool {
+	return false
+}
+
+func (*rebarLayoutItem) HeightForWidth(width int) int {
+	return 0
+}
+
+// PerformLayout returns no results, as the control positions the band
+// children itself.
+func (*rebarLayoutItem) PerformLayout() []LayoutResultItem {
+	return nil
+}
@@ -81,3 +81,7 @@ func (r Rectangle) toRECT() win.RECT {
 		int32(r.Y + r.Height),
 	}
 }
+
+func (r Rectangle) contains(p Point) bool {
+	return p.X >= r.X && p.X < r.X+r.Width && p.Y >= r.Y && p.Y < r.Y+r.Height
+}
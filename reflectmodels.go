@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"reflect"
 	"sort"
+	"strings"
 )
 
 type reflectModel interface {
@@ -104,6 +105,12 @@ type dataMembersSetter interface {
 	setDataMembers(dataMembers []string)
 }
 
+// itemIndexer is implemented by models that may display the items of their
+// data source in other rows, e.g. because they are filtered.
+type itemIndexer interface {
+	itemIndex(row int) int
+}
+
 type reflectTableModel struct {
 	TableModelBase
 	sorterBase  *SorterBase
@@ -112,6 +119,8 @@ type reflectTableModel struct {
 	dataSource  interface{}
 	items       interface{}
 	value       reflect.Value
+	filter      func(value func(col int) interface{}) bool
+	rows        []int // indexes of the items the filter accepts
 }
 
 func newReflectTableModel(dataSource interface{}) (TableModel, error) {
@@ -127,11 +136,16 @@ func newReflectTableModel(dataSource interface{}) (TableModel, error) {
 	}
 
 	if rtm, ok := dataSource.(ReflectTableModel); ok {
-		rtm.setValueFunc(func(row, col int) interface{} {
-			return m.Value(row, col)
+		rtm.setValueFunc(func(index, col int) interface{} {
+			return m.itemValue(index, col)
 		})
 
 		rtm.RowChanged().Attach(func(index int) {
+			if m.filter != nil {
+				m.refilter()
+				return
+			}
+
 			m.PublishRowChanged(index)
 		})
 
@@ -139,7 +153,11 @@ func newReflectTableModel(dataSource interface{}) (TableModel, error) {
 			m.items = rtm.Items()
 			m.value = reflect.ValueOf(m.items)
 
-			m.PublishRowsReset()
+			if m.filter != nil {
+				m.refilter()
+			} else {
+				m.PublishRowsReset()
+			}
 
 			if is, ok := dataSource.(interceptedSorter); ok {
 				sb := is.sorterBase()
@@ -148,6 +166,11 @@ func newReflectTableModel(dataSource interface{}) (TableModel, error) {
 		})
 
 		rtm.RowsChanged().Attach(func(from, to int) {
+			if m.filter != nil {
+				m.refilter()
+				return
+			}
+
 			m.PublishRowsChanged(from, to)
 		})
 
@@ -155,6 +178,11 @@ func newReflectTableModel(dataSource interface{}) (TableModel, error) {
 			m.items = rtm.Items()
 			m.value = reflect.ValueOf(m.items)
 
+			if m.filter != nil {
+				m.refilter()
+				return
+			}
+
 			m.PublishRowsInserted(from, to)
 		})
 
@@ -162,6 +190,11 @@ func newReflectTableModel(dataSource interface{}) (TableModel, error) {
 			m.items = rtm.Items()
 			m.value = reflect.ValueOf(m.items)
 
+			if m.filter != nil {
+				m.refilter()
+				return
+			}
+
 			m.PublishRowsRemoved(from, to)
 		})
 	} else {
@@ -202,14 +235,119 @@ func (m *reflectTableModel) setDataMembers(dataMembers []string) {
 }
 
 func (m *reflectTableModel) RowCount() int {
+	if m.filter != nil {
+		return len(m.rows)
+	}
+
 	return m.value.Len()
 }
 
+// itemIndex returns the index of the item displayed in row, which is what
+// the data source knows it by.
+func (m *reflectTableModel) itemIndex(row int) int {
+	if m.filter != nil {
+		return m.rows[row]
+	}
+
+	return row
+}
+
 func (m *reflectTableModel) Value(row, col int) interface{} {
-	return valueFromSlice(m.dataSource, m.value, m.dataMembers[col], row)
+	return m.itemValue(m.itemIndex(row), col)
+}
+
+func (m *reflectTableModel) itemValue(index, col int) interface{} {
+	return valueFromSlice(m.dataSource, m.value, m.dataMembers[col], index)
+}
+
+func (m *reflectTableModel) SetValue(row, col int, value interface{}) error {
+	row = m.itemIndex(row)
+
+	if setter, ok := m.dataSource.(CellValueSetter); ok {
+		return setter.SetValue(row, col, value)
+	}
+
+	member := m.dataMembers[col]
+	if member == "" {
+		return newError("column has no data member")
+	}
+
+	v := m.value.Index(row)
+	if v.Kind() == reflect.Ptr && v.IsNil() {
+		return newError("item not populated")
+	}
+
+	parent, vv, err := reflectValueFromPath(v, member)
+	if err != nil {
+		return err
+	}
+
+	field := &reflectField{parent: parent, value: vv, key: member[strings.LastIndexByte(member, '.')+1:]}
+	if !field.CanSet() {
+		return newError(fmt.Sprintf("Field '%s' cannot be set.", member))
+	}
+
+	if parent.Kind() != reflect.Map {
+		if value == nil {
+			value = field.Zero()
+		}
+
+		if _, ok := value.(float64); !ok && !reflect.TypeOf(value).AssignableTo(vv.Type()) {
+			return newError(fmt.Sprintf("Field '%s': Can't assign %T.", member, value))
+		}
+	}
+
+	return field.Set(value)
+}
+
+func (m *reflectTableModel) ColumnFilterable(col int) bool {
+	return col < len(m.dataMembers) && m.dataMembers[col] != ""
+}
+
+func (m *reflectTableModel) ColumnValues(col int) []interface{} {
+	values := make([]interface{}, m.value.Len())
+	for i := range values {
+		values[i] = m.itemValue(i, col)
+	}
+
+	return values
+}
+
+func (m *reflectTableModel) Filter(accept func(value func(col int) interface{}) bool) error {
+	m.filter = accept
+
+	m.refilter()
+
+	return nil
+}
+
+// refilter applies the filter to all items and resets the rows.
+func (m *reflectTableModel) refilter() {
+	m.updateRows()
+
+	m.PublishRowsReset()
+}
+
+// updateRows collects the indexes of the items the filter accepts, leaving
+// the items of the data source in place.
+func (m *reflectTableModel) updateRows() {
+	m.rows = nil
+	if m.filter == nil {
+		return
+	}
+
+	count := m.value.Len()
+	m.rows = make([]int, 0, count)
+	for i := 0; i < count; i++ {
+		if m.filter(func(col int) interface{} { return m.itemValue(i, col) }) {
+			m.rows = append(m.rows, i)
+		}
+	}
 }
 
 func (m *reflectTableModel) Checked(row int) bool {
+	row = m.itemIndex(row)
+
 	if m.value.Index(row).IsNil() {
 		return false
 	}
@@ -222,6 +360,8 @@ func (m *reflectTableModel) Checked(row int) bool {
 }
 
 func (m *reflectTableModel) SetChecked(row int, checked bool) error {
+	row = m.itemIndex(row)
+
 	if m.value.Index(row).IsNil() {
 		return nil
 	}
@@ -283,6 +423,8 @@ func (m *reflectTableModel) sort(col int, order SortOrder) error {
 
 		sort.Stable(m)
 
+		m.updateRows()
+
 		sb.changedPublisher.Publish()
 
 		return nil
@@ -296,7 +438,7 @@ func (m *reflectTableModel) sort(col int, order SortOrder) error {
 }
 
 func (m *reflectTableModel) Len() int {
-	return m.RowCount()
+	return m.value.Len()
 }
 
 func (m *reflectTableModel) Less(i, j int) bool {
@@ -312,7 +454,7 @@ func (m *reflectTableModel) Less(i, j int) bool {
 		}
 	}
 
-	return less(m.Value(i, col), m.Value(j, col), m.SortOrder())
+	return less(m.itemValue(i, col), m.itemValue(j, col), m.SortOrder())
 }
 
 func (m *reflectTableModel) Swap(i, j int) {
@@ -331,6 +473,8 @@ type imageReflectTableModel struct {
 }
 
 func (m *imageReflectTableModel) Image(index int) interface{} {
+	index = m.itemIndex(index)
+
 	if m.value.Index(index).IsNil() {
 		return nil
 	}
@@ -355,6 +499,8 @@ func (m *sortedImageReflectTableModel) Sort(col int, order SortOrder) error {
 }
 
 func (m *sortedImageReflectTableModel) Image(index int) interface{} {
+	index = m.itemIndex(index)
+
 	if m.value.Index(index).IsNil() {
 		return nil
 	}
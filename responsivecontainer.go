@@ -0,0 +1,199 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"sort"
+	"unsafe"
+
+	"github.com/tailscale/win"
+)
+
+const responsiveContainerWindowClass = `\o/ Walk_ResponsiveContainer_Class \o/`
+
+func init() {
+	AppendToWalkInit(func() {
+		MustRegisterWindowClass(responsiveContainerWindowClass)
+	})
+}
+
+// ResponsiveBreakpoint makes a ResponsiveContainer use Layout while it is at
+// least MinWidth wide.
+type ResponsiveBreakpoint struct {
+	MinWidth int // in 1/96" units
+	Layout   Layout
+}
+
+// ResponsiveContainer is a Container that switches between alternate layouts
+// of its children depending on its width, e.g. to stack them when narrow and
+// arrange them in columns when wide.
+//
+// The Layout set via SetLayout is used below the smallest breakpoint. Each
+// layout keeps its own per-widget settings, like stretch factors or grid
+// ranges, so they must be configured on every layout that needs them.
+type ResponsiveContainer struct {
+	ContainerBase
+	baseLayout                 Layout
+	breakpoints                []ResponsiveBreakpoint
+	mode                       int
+	layoutModeChangedPublisher EventPublisher
+}
+
+// NewResponsiveContainer creates a new ResponsiveContainer as child of parent.
+func NewResponsiveContainer(parent Container) (*ResponsiveContainer, error) {
+	rc := new(ResponsiveContainer)
+	rc.children = newWidgetList(rc)
+	rc.SetPersistent(true)
+
+	if err := InitWidget(
+		rc,
+		parent,
+		responsiveContainerWindowClass,
+		win.WS_CHILD|win.WS_VISIBLE,
+		win.WS_EX_CONTROLPARENT); err != nil {
+		return nil, err
+	}
+
+	rc.SetBackground(NullBrush())
+
+	return rc, nil
+}
+
+// SetLayout sets the layout used while the ResponsiveContainer is narrower
+// than its smallest breakpoint.
+func (rc *ResponsiveContainer) SetLayout(value Layout) error {
+	if value == rc.baseLayout {
+		return nil
+	}
+
+	if rc.baseLayout != nil {
+		rc.detach(rc.baseLayout)
+	}
+
+	rc.baseLayout = value
+
+	if value != nil {
+		rc.attach(value)
+	}
+
+	rc.updateLayoutMode()
+
+	return nil
+}
+
+// Breakpoints returns the breakpoints of the ResponsiveContainer, ordered by
+// their MinWidth.
+func (rc *ResponsiveContainer) Breakpoints() []ResponsiveBreakpoint {
+	return append([]ResponsiveBreakpoint(nil), rc.breakpoints...)
+}
+
+// SetBreakpoints sets the breakpoints of the ResponsiveContainer. The layout
+// of the breakpoint with the largest MinWidth not exceeding the current width
+// is used.
+func (rc *ResponsiveContainer) SetBreakpoints(breakpoints []ResponsiveBreakpoint) error {
+	sorted := append([]ResponsiveBreakpoint(nil), breakpoints...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].MinWidth < sorted[j].MinWidth
+	})
+
+	for i, bp := range sorted {
+		if bp.Layout == nil {
+			return newError("breakpoint layout required")
+		}
+		if bp.MinWidth <= 0 {
+			return newError("breakpoint width must be positive")
+		}
+		if i > 0 && bp.MinWidth == sorted[i-1].MinWidth {
+			return newError("duplicate breakpoint width")
+		}
+	}
+
+	for _, bp := range rc.breakpoints {
+		rc.detach(bp.Layout)
+	}
+
+	rc.breakpoints = sorted
+
+	for _, bp := range rc.breakpoints {
+		rc.attach(bp.Layout)
+	}
+
+	rc.updateLayoutMode()
+
+	return nil
+}
+
+// LayoutMode returns which layout is currently used: 0 for the one set via
+// SetLayout, i+1 for the layout of breakpoint i.
+func (rc *ResponsiveContainer) LayoutMode() int {
+	return rc.mode
+}
+
+// LayoutModeChanged returns the event that is published when the
+// ResponsiveContainer switched to another layout.
+func (rc *ResponsiveContainer) LayoutModeChanged() *Event {
+	return rc.layoutModeChangedPublisher.Event()
+}
+
+// attach associates layout with rc without making it the active layout.
+func (rc *ResponsiveContainer) attach(layout Layout) {
+	active := rc.layout
+	rc.layout = layout
+	layout.SetContainer(&rc.ContainerBase)
+	rc.layout = active
+}
+
+// detach dissociates layout from rc.
+func (rc *ResponsiveContainer) detach(layout Layout) {
+	active := rc.layout
+	rc.layout = nil
+	layout.SetContainer(nil)
+	if active != layout {
+		rc.layout = active
+	}
+}
+
+// updateLayoutMode activates the layout matching the current width.
+func (rc *ResponsiveContainer) updateLayoutMode() {
+	width := rc.IntTo96DPI(rc.ClientBoundsPixels().Width)
+
+	mode := 0
+	for i, bp := range rc.breakpoints {
+		if width >= bp.MinWidth {
+			mode = i + 1
+		}
+	}
+
+	layout := rc.baseLayout
+	if mode > 0 {
+		layout = rc.breakpoints[mode-1].Layout
+	}
+
+	if layout != rc.layout {
+		rc.layout = layout
+		rc.RequestLayout()
+	}
+
+	if mode != rc.mode {
+		rc.mode = mode
+		rc.layoutModeChangedPublisher.Publish()
+	}
+}
+
+func (rc *ResponsiveContainer) WndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) uintptr {
+	switch msg {
+	case win.WM_WINDOWPOSCHANGED:
+		wp := (*win.WINDOWPOS)(unsafe.Pointer(lParam))
+
+		if wp.Flags&win.SWP_NOSIZE == 0 {
+			rc.updateLayoutMode()
+		}
+	}
+
+	return rc.ContainerBase.WndProc(hwnd, msg, wParam, lParam)
+}
@@ -0,0 +1,166 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tailscale/win"
+	"golang.org/x/sys/windows"
+)
+
+// RestartArg is passed as first command line argument to an application that
+// is restarted after being registered using Application.RegisterRestart.
+const RestartArg = "/restart"
+
+// RestartFlags specify in which cases an application registered using
+// Application.RegisterRestart is not restarted.
+type RestartFlags uint32
+
+const (
+	RestartNoCrash  RestartFlags = 0x1 // not after crashing
+	RestartNoHang   RestartFlags = 0x2 // not after hanging
+	RestartNoPatch  RestartFlags = 0x4 // not after being updated
+	RestartNoReboot RestartFlags = 0x8 // not after the system restarted for an update
+)
+
+// restartMaxCmdLine is RESTART_MAX_CMD_LINE, the maximum length of the
+// command line passed to RegisterApplicationRestart.
+const restartMaxCmdLine = 1024
+
+// RecoveryContext is passed to the callback registered using
+// Application.RegisterRecovery.
+type RecoveryContext struct{}
+
+// Cancelled reports to Windows Error Reporting that recovery is still in
+// progress and returns whether the user cancelled it. The callback must call
+// it at least once per ping interval, or the process is terminated.
+func (rc *RecoveryContext) Cancelled() bool {
+	var cancelled win.BOOL
+	applicationRecoveryInProgress(&cancelled)
+
+	return cancelled != 0
+}
+
+var (
+	recoveryCallbackMutex sync.Mutex
+	recoveryCallback      func(rc *RecoveryContext) bool
+	recoveryCallbackPtr   uintptr
+)
+
+// WasRestarted returns whether the application was started by Windows after
+// being registered using RegisterRestart, e.g. after it crashed, hung or was
+// closed to be updated. Applications may want to restore their previous
+// session in that case.
+func (app *Application) WasRestarted() bool {
+	return len(os.Args) > 1 && os.Args[1] == RestartArg
+}
+
+// RegisterRestart makes Windows restart the application with RestartArg
+// followed by args if it crashes, hangs or is closed to be updated, except in
+// the cases excluded by flags. Windows only restarts applications that have
+// been running for at least 60 seconds.
+//
+// Applications closed for an update receive the usual close requests of their
+// windows, so their state can be saved as usual.
+func (app *Application) RegisterRestart(args []string, flags RestartFlags) error {
+	parts := []string{RestartArg}
+	for _, arg := range args {
+		parts = append(parts, windows.EscapeArg(arg))
+	}
+
+	commandLine := strings.Join(parts, " ")
+	if len(commandLine) >= restartMaxCmdLine {
+		return newError("command line too long")
+	}
+
+	commandLine16, err := windows.UTF16PtrFromString(commandLine)
+	if err != nil {
+		return wrapError(err)
+	}
+
+	if hr := registerApplicationRestart(commandLine16, uint32(flags)); win.FAILED(hr) {
+		return errorFromHRESULT("RegisterApplicationRestart", hr)
+	}
+
+	return nil
+}
+
+// UnregisterRestart reverts RegisterRestart.
+func (app *Application) UnregisterRestart() error {
+	if hr := unregisterApplicationRestart(); win.FAILED(hr) {
+		return errorFromHRESULT("UnregisterApplicationRestart", hr)
+	}
+
+	return nil
+}
+
+// RegisterRecovery makes Windows Error Reporting call callback before the
+// application is terminated because it crashed or hung, so it can persist its
+// state, e.g. to be restored after a restart registered using RegisterRestart.
+//
+// The callback runs on a thread of its own while the UI thread is blocked or
+// broken, so it must not access any windows. It has to call Cancelled at least
+// every pingInterval and returns whether it saved the state successfully.
+func (app *Application) RegisterRecovery(callback func(rc *RecoveryContext) bool, pingInterval time.Duration) error {
+	if callback == nil {
+		return newError("callback cannot be nil")
+	}
+
+	if pingInterval <= 0 {
+		pingInterval = 5 * time.Second
+	}
+
+	recoveryCallbackMutex.Lock()
+	defer recoveryCallbackMutex.Unlock()
+
+	// Callbacks cannot be freed, so the same one is used every time.
+	if recoveryCallbackPtr == 0 {
+		recoveryCallbackPtr = windows.NewCallback(applicationRecoveryCallback)
+	}
+
+	if hr := registerApplicationRecoveryCallback(recoveryCallbackPtr, 0, uint32(pingInterval.Milliseconds()), 0); win.FAILED(hr) {
+		return errorFromHRESULT("RegisterApplicationRecoveryCallback", hr)
+	}
+
+	recoveryCallback = callback
+
+	return nil
+}
+
+// UnregisterRecovery reverts RegisterRecovery.
+func (app *Application) UnregisterRecovery() error {
+	recoveryCallbackMutex.Lock()
+	defer recoveryCallbackMutex.Unlock()
+
+	if hr := unregisterApplicationRecoveryCallback(); win.FAILED(hr) {
+		return errorFromHRESULT("UnregisterApplicationRecoveryCallback", hr)
+	}
+
+	recoveryCallback = nil
+
+	return nil
+}
+
+func applicationRecoveryCallback(param uintptr) uintptr {
+	recoveryCallbackMutex.Lock()
+	callback := recoveryCallback
+	recoveryCallbackMutex.Unlock()
+
+	success := false
+	if callback != nil {
+		success = callback(new(RecoveryContext))
+	}
+
+	applicationRecoveryFinished(success)
+
+	return 0
+}
@@ -0,0 +1,388 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"syscall"
+	"unsafe"
+
+	"github.com/tailscale/win"
+)
+
+// RibbonContextAvailability specifies whether a contextual tab group of a
+// Ribbon is displayed.
+type RibbonContextAvailability uint32
+
+const (
+	RibbonContextNotAvailable RibbonContextAvailability = _UI_CONTEXTAVAILABILITY_NOTAVAILABLE
+	RibbonContextAvailable    RibbonContextAvailability = _UI_CONTEXTAVAILABILITY_AVAILABLE
+	RibbonContextActive       RibbonContextAvailability = _UI_CONTEXTAVAILABILITY_ACTIVE
+)
+
+// Ribbon hosts the Windows Ribbon Framework at the top of a MainWindow, whose
+// client area shrinks by the height of the ribbon.
+//
+// The tabs, groups and controls of the ribbon, including its application
+// menu, are defined in Ribbon markup, which the uicc tool compiles into a
+// resource that must be linked into the executable. The commands of the
+// markup are connected to Actions by their ids using SetAction.
+type Ribbon struct {
+	mw                     *MainWindow
+	framework              *iUIFramework
+	view                   *iUIRibbon
+	application            *ribbonIUIApplication
+	commandHandler         *ribbonIUICommandHandler
+	actions                map[uint32]*Action
+	galleries              map[uint32]*RibbonGallery
+	contexts               map[uint32]RibbonContextAvailability
+	heightPixels           int
+	heightChangedPublisher EventPublisher
+	disposingHandle        int
+}
+
+// NewRibbon creates a Ribbon for mw from the ribbon resource named
+// resourceName, which is APPLICATION_RIBBON unless specified otherwise when
+// compiling the markup.
+func NewRibbon(mw *MainWindow, resourceName string) (*Ribbon, error) {
+	if mw.ribbon != nil {
+		return nil, newError("the MainWindow already has a Ribbon")
+	}
+
+	r := &Ribbon{
+		mw:        mw,
+		actions:   make(map[uint32]*Action),
+		galleries: make(map[uint32]*RibbonGallery),
+		contexts:  make(map[uint32]RibbonContextAvailability),
+	}
+	r.application = newRibbonIUIApplication(r)
+	r.commandHandler = newRibbonIUICommandHandler(r)
+
+	if hr := win.CoCreateInstance(
+		&clsid_UIRibbonFramework,
+		nil,
+		win.CLSCTX_INPROC_SERVER,
+		&iid_IUIFramework,
+		(*unsafe.Pointer)(unsafe.Pointer(&r.framework))); win.FAILED(hr) {
+
+		return nil, errorFromHRESULT("CoCreateInstance(CLSID_UIRibbonFramework)", hr)
+	}
+
+	succeeded := false
+	defer func() {
+		if !succeeded {
+			r.Dispose()
+		}
+	}()
+
+	// The view is created while loading the markup, which already affects the
+	// layout of mw.
+	mw.ribbon = r
+
+	if hr := r.framework.Initialize(mw.hWnd, unsafe.Pointer(r.application)); win.FAILED(hr) {
+		return nil, errorFromHRESULT("IUIFramework.Initialize", hr)
+	}
+
+	name, err := syscall.UTF16PtrFromString(resourceName)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	if hr := r.framework.LoadUI(win.GetModuleHandle(nil), name); win.FAILED(hr) {
+		return nil, errorFromHRESULT("IUIFramework.LoadUI", hr)
+	}
+
+	r.disposingHandle = mw.Disposing().Attach(r.Dispose)
+
+	succeeded = true
+
+	return r, nil
+}
+
+// Dispose removes the Ribbon from its MainWindow. This happens automatically
+// when the MainWindow is disposed.
+func (r *Ribbon) Dispose() {
+	if r.framework == nil {
+		return
+	}
+
+	r.mw.Disposing().Detach(r.disposingHandle)
+
+	r.framework.Destroy()
+	if r.view != nil {
+		r.view.Release()
+		r.view = nil
+	}
+	r.framework.Release()
+	r.framework = nil
+
+	for _, action := range r.actions {
+		action.removeChangedHandler(r)
+		action.release()
+	}
+	r.actions = nil
+
+	r.mw.ribbon = nil
+	r.heightPixels = 0
+
+	if !r.mw.IsDisposed() {
+		r.mw.SetBoundsPixels(r.mw.BoundsPixels())
+	}
+}
+
+// HeightPixels returns the height of the Ribbon in native pixels, which is 0
+// while it is hidden because the MainWindow is too small.
+func (r *Ribbon) HeightPixels() int {
+	return r.heightPixels
+}
+
+// HeightChanged returns the event that is published when the Ribbon is
+// minimized, restored or shown or hidden due to the size of the MainWindow.
+func (r *Ribbon) HeightChanged() *Event {
+	return r.heightChangedPublisher.Event()
+}
+
+// Action returns the Action connected to the command commandId.
+func (r *Ribbon) Action(commandId uint32) *Action {
+	return r.actions[commandId]
+}
+
+// SetAction connects the command commandId, which may be in the application
+// menu, to action. Executing the command triggers action, and the command
+// follows the text, tool tip, enabled and checked state of action. Passing
+// nil disconnects the command.
+//
+// The visibility of commands is determined by the markup and the modes set
+// using SetModes instead.
+func (r *Ribbon) SetAction(commandId uint32, action *Action) error {
+	if old := r.actions[commandId]; old != nil {
+		old.removeChangedHandler(r)
+		old.release()
+		delete(r.actions, commandId)
+	}
+
+	if action != nil {
+		action.addRef()
+		action.addChangedHandler(r)
+		r.actions[commandId] = action
+	}
+
+	return r.invalidate(commandId)
+}
+
+// Gallery returns the RibbonGallery for the gallery or combo box command
+// commandId.
+func (r *Ribbon) Gallery(commandId uint32) *RibbonGallery {
+	if g := r.galleries[commandId]; g != nil {
+		return g
+	}
+
+	g := &RibbonGallery{ribbon: r, commandId: commandId, currentIndex: -1}
+	r.galleries[commandId] = g
+
+	return g
+}
+
+// ContextAvailability returns whether the contextual tab group commandId is
+// displayed.
+func (r *Ribbon) ContextAvailability(commandId uint32) RibbonContextAvailability {
+	return r.contexts[commandId]
+}
+
+// SetContextAvailability sets whether the contextual tab group commandId is
+// displayed, and whether its first tab is activated along with it.
+func (r *Ribbon) SetContextAvailability(commandId uint32, availability RibbonContextAvailability) error {
+	r.contexts[commandId] = availability
+
+	return r.invalidateProperty(commandId, &_UI_PKEY_ContextAvailable)
+}
+
+// SetModes sets the application modes whose tabs, groups and application menu
+// items are displayed. Mode 0 is active initially.
+func (r *Ribbon) SetModes(modes ...int) error {
+	var mask int32
+	for _, mode := range modes {
+		if mode < 0 || mode > 31 {
+			return newError("mode out of range")
+		}
+
+		mask |= 1 << uint(mode)
+	}
+
+	if hr := r.framework.SetModes(mask); win.FAILED(hr) {
+		return errorFromHRESULT("IUIFramework.SetModes", hr)
+	}
+
+	return nil
+}
+
+func (r *Ribbon) onActionChanged(action *Action) error {
+	for commandId, a := range r.actions {
+		if a == action {
+			if err := r.invalidate(commandId); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (r *Ribbon) onActionVisibleChanged(action *Action) error {
+	return nil
+}
+
+// invalidate makes the Ribbon Framework query all properties of commandId
+// again.
+func (r *Ribbon) invalidate(commandId uint32) error {
+	if r.framework == nil {
+		return nil
+	}
+
+	if hr := r.framework.InvalidateUICommand(
+		commandId,
+		_UI_INVALIDATIONS_STATE|_UI_INVALIDATIONS_VALUE|_UI_INVALIDATIONS_ALLPROPERTIES,
+		nil); win.FAILED(hr) {
+
+		return errorFromHRESULT("IUIFramework.InvalidateUICommand", hr)
+	}
+
+	return nil
+}
+
+// invalidateProperty makes the Ribbon Framework query property key of
+// commandId again.
+func (r *Ribbon) invalidateProperty(commandId uint32, key *uiPropertyKey) error {
+	if r.framework == nil {
+		return nil
+	}
+
+	if hr := r.framework.InvalidateUICommand(commandId, _UI_INVALIDATIONS_PROPERTY, key); win.FAILED(hr) {
+		return errorFromHRESULT("IUIFramework.InvalidateUICommand", hr)
+	}
+
+	return nil
+}
+
+func (r *Ribbon) execute(commandId uint32, key *uiPropertyKey, currentValue *propVariant) uintptr {
+	if g := r.galleries[commandId]; g != nil && key != nil && *key == _UI_PKEY_SelectedItem && currentValue != nil {
+		g.setCurrentIndex(int(int32(currentValue.uint32())))
+	}
+
+	action := r.actions[commandId]
+	if action == nil || !action.Enabled() {
+		return win.S_OK
+	}
+
+	action.raiseTriggered()
+
+	// Toggle buttons switch their state by themselves, which must follow the
+	// action instead.
+	if action.Checkable() {
+		r.invalidate(commandId)
+	}
+
+	return win.S_OK
+}
+
+func (r *Ribbon) updateProperty(commandId uint32, key *uiPropertyKey, currentValue, newValue *propVariant) uintptr {
+	if *key == _UI_PKEY_ContextAvailable {
+		availability, ok := r.contexts[commandId]
+		if !ok {
+			return win.E_NOTIMPL
+		}
+
+		newValue.setUInt32(uint32(availability))
+		return win.S_OK
+	}
+
+	if g := r.galleries[commandId]; g != nil {
+		if hr := g.updateProperty(key, currentValue, newValue); hr != win.E_NOTIMPL {
+			return hr
+		}
+	}
+
+	action := r.actions[commandId]
+	if action == nil {
+		return win.E_NOTIMPL
+	}
+
+	var text string
+	switch *key {
+	case _UI_PKEY_Enabled:
+		newValue.setBool(action.Enabled())
+		return win.S_OK
+
+	case _UI_PKEY_BooleanValue:
+		newValue.setBool(action.Checked())
+		return win.S_OK
+
+	case _UI_PKEY_Label, _UI_PKEY_TooltipTitle:
+		text = action.Text()
+
+	case _UI_PKEY_TooltipDescription:
+		text = action.ToolTip()
+	}
+
+	// Without a text of the action, the one of the markup is used.
+	if text == "" {
+		return win.E_NOTIMPL
+	}
+
+	if err := newValue.setString(text); err != nil {
+		return win.E_OUTOFMEMORY
+	}
+
+	return win.S_OK
+}
+
+func (r *Ribbon) onViewChanged(view *win.IUnknown, verb uint32) {
+	switch verb {
+	case _UI_VIEWVERB_CREATE:
+		p, hr := queryInterface(view, &iid_IUIRibbon)
+		if win.FAILED(hr) {
+			return
+		}
+		r.view = (*iUIRibbon)(p)
+
+		r.updateHeight()
+
+	case _UI_VIEWVERB_SIZE:
+		r.updateHeight()
+
+	case _UI_VIEWVERB_DESTROY:
+		if r.view != nil {
+			r.view.Release()
+			r.view = nil
+		}
+
+		r.setHeightPixels(0)
+	}
+}
+
+func (r *Ribbon) updateHeight() {
+	var height uint32
+	if r.view == nil || win.FAILED(r.view.GetHeight(&height)) {
+		return
+	}
+
+	r.setHeightPixels(int(height))
+}
+
+func (r *Ribbon) setHeightPixels(height int) {
+	if height == r.heightPixels {
+		return
+	}
+
+	r.heightPixels = height
+
+	if r.mw.ribbon == r && !r.mw.IsDisposed() {
+		r.mw.SetBoundsPixels(r.mw.BoundsPixels())
+	}
+
+	r.heightChangedPublisher.Publish()
+}
@@ -0,0 +1,87 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"syscall"
+	"unsafe"
+
+	"github.com/tailscale/win"
+)
+
+type iUIApplicationVtbl struct {
+	QueryInterface     uintptr
+	AddRef             uintptr
+	Release            uintptr
+	OnViewChanged      uintptr
+	OnCreateUICommand  uintptr
+	OnDestroyUICommand uintptr
+}
+
+var ribbonIUIApplicationVtbl *iUIApplicationVtbl
+
+func init() {
+	AppendToWalkInit(func() {
+		ribbonIUIApplicationVtbl = &iUIApplicationVtbl{
+			syscall.NewCallback(ribbon_IUIApplication_QueryInterface),
+			syscall.NewCallback(ribbon_IUIApplication_AddRef),
+			syscall.NewCallback(ribbon_IUIApplication_Release),
+			syscall.NewCallback(ribbon_IUIApplication_OnViewChanged),
+			syscall.NewCallback(ribbon_IUIApplication_OnCreateUICommand),
+			syscall.NewCallback(ribbon_IUIApplication_OnDestroyUICommand),
+		}
+	})
+}
+
+// ribbonIUIApplication is how the Ribbon Framework notifies a Ribbon of
+// changes to its view and asks for the handlers of its commands.
+type ribbonIUIApplication struct {
+	lpVtbl *iUIApplicationVtbl
+	ribbon *Ribbon
+}
+
+func newRibbonIUIApplication(ribbon *Ribbon) *ribbonIUIApplication {
+	return &ribbonIUIApplication{ribbonIUIApplicationVtbl, ribbon}
+}
+
+func ribbon_IUIApplication_QueryInterface(app *ribbonIUIApplication, riid win.REFIID, ppvObject *unsafe.Pointer) uintptr {
+	if win.EqualREFIID(riid, &win.IID_IUnknown) || win.EqualREFIID(riid, &iid_IUIApplication) {
+		*ppvObject = unsafe.Pointer(app)
+		return win.S_OK
+	}
+
+	*ppvObject = nil
+	return win.E_NOINTERFACE
+}
+
+func ribbon_IUIApplication_AddRef(app *ribbonIUIApplication) uintptr {
+	return 1
+}
+
+func ribbon_IUIApplication_Release(app *ribbonIUIApplication) uintptr {
+	return 1
+}
+
+func ribbon_IUIApplication_OnViewChanged(app *ribbonIUIApplication, viewId, typeId uintptr, view *win.IUnknown, verb, reasonCode uintptr) uintptr {
+	if typeId == _UI_VIEWTYPE_RIBBON {
+		app.ribbon.onViewChanged(view, uint32(verb))
+	}
+
+	return win.S_OK
+}
+
+func ribbon_IUIApplication_OnCreateUICommand(app *ribbonIUIApplication, commandId, typeId uintptr, commandHandler *unsafe.Pointer) uintptr {
+	// A single handler serves all commands.
+	*commandHandler = unsafe.Pointer(app.ribbon.commandHandler)
+
+	return win.S_OK
+}
+
+func ribbon_IUIApplication_OnDestroyUICommand(app *ribbonIUIApplication, commandId, typeId uintptr, commandHandler unsafe.Pointer) uintptr {
+	return win.S_OK
+}
@@ -0,0 +1,78 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"syscall"
+	"unsafe"
+
+	"github.com/tailscale/win"
+)
+
+type iUICommandHandlerVtbl struct {
+	QueryInterface uintptr
+	AddRef         uintptr
+	Release        uintptr
+	Execute        uintptr
+	UpdateProperty uintptr
+}
+
+var ribbonIUICommandHandlerVtbl *iUICommandHandlerVtbl
+
+func init() {
+	AppendToWalkInit(func() {
+		ribbonIUICommandHandlerVtbl = &iUICommandHandlerVtbl{
+			syscall.NewCallback(ribbon_IUICommandHandler_QueryInterface),
+			syscall.NewCallback(ribbon_IUICommandHandler_AddRef),
+			syscall.NewCallback(ribbon_IUICommandHandler_Release),
+			syscall.NewCallback(ribbon_IUICommandHandler_Execute),
+			syscall.NewCallback(ribbon_IUICommandHandler_UpdateProperty),
+		}
+	})
+}
+
+// ribbonIUICommandHandler executes the commands of a Ribbon and provides
+// their current properties.
+type ribbonIUICommandHandler struct {
+	lpVtbl *iUICommandHandlerVtbl
+	ribbon *Ribbon
+}
+
+func newRibbonIUICommandHandler(ribbon *Ribbon) *ribbonIUICommandHandler {
+	return &ribbonIUICommandHandler{ribbonIUICommandHandlerVtbl, ribbon}
+}
+
+func ribbon_IUICommandHandler_QueryInterface(h *ribbonIUICommandHandler, riid win.REFIID, ppvObject *unsafe.Pointer) uintptr {
+	if win.EqualREFIID(riid, &win.IID_IUnknown) || win.EqualREFIID(riid, &iid_IUICommandHandler) {
+		*ppvObject = unsafe.Pointer(h)
+		return win.S_OK
+	}
+
+	*ppvObject = nil
+	return win.E_NOINTERFACE
+}
+
+func ribbon_IUICommandHandler_AddRef(h *ribbonIUICommandHandler) uintptr {
+	return 1
+}
+
+func ribbon_IUICommandHandler_Release(h *ribbonIUICommandHandler) uintptr {
+	return 1
+}
+
+func ribbon_IUICommandHandler_Execute(h *ribbonIUICommandHandler, commandId, verb uintptr, key *uiPropertyKey, currentValue *propVariant, commandExecutionProperties unsafe.Pointer) uintptr {
+	if verb != _UI_EXECUTIONVERB_EXECUTE {
+		return win.S_OK
+	}
+
+	return h.ribbon.execute(uint32(commandId), key, currentValue)
+}
+
+func ribbon_IUICommandHandler_UpdateProperty(h *ribbonIUICommandHandler, commandId uintptr, key *uiPropertyKey, currentValue, newValue *propVariant) uintptr {
+	return h.ribbon.updateProperty(uint32(commandId), key, currentValue, newValue)
+}
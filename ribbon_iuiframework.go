@@ -0,0 +1,273 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"syscall"
+	"unsafe"
+
+	"github.com/tailscale/win"
+)
+
+var (
+	clsid_UIRibbonFramework = win.CLSID{0x926749FA, 0x2615, 0x4987, [8]byte{0x88, 0x45, 0xC3, 0x3E, 0x65, 0xF2, 0xB9, 0x57}}
+
+	iid_IUIApplication       = win.IID{0xD428903C, 0x729A, 0x491D, [8]byte{0x91, 0x0D, 0x68, 0x2A, 0x08, 0xFF, 0x25, 0x22}}
+	iid_IUICollection        = win.IID{0xDF4F45BF, 0x6F9D, 0x4DD7, [8]byte{0x9D, 0x68, 0xD8, 0xF9, 0xCD, 0x18, 0xC4, 0xDB}}
+	iid_IUICommandHandler    = win.IID{0x75AE0A2D, 0xDC03, 0x4C9F, [8]byte{0x88, 0x83, 0x06, 0x96, 0x60, 0xD0, 0xBE, 0xB6}}
+	iid_IUIFramework         = win.IID{0xF4F0385D, 0x6872, 0x43A8, [8]byte{0xAD, 0x09, 0x4C, 0x33, 0x9C, 0xB3, 0xF5, 0xC5}}
+	iid_IUIRibbon            = win.IID{0x803982AB, 0x370A, 0x4F7E, [8]byte{0xA9, 0xE7, 0x87, 0x84, 0x03, 0x6A, 0x6E, 0x26}}
+	iid_IUISimplePropertySet = win.IID{0xC205BB48, 0x5B1C, 0x4219, [8]byte{0xA1, 0x06, 0x15, 0xBD, 0x0A, 0x5F, 0x24, 0xE2}}
+)
+
+const (
+	_UI_COLLECTION_INVALIDINDEX = 0xFFFFFFFF
+
+	_UI_CONTEXTAVAILABILITY_NOTAVAILABLE = 0
+	_UI_CONTEXTAVAILABILITY_AVAILABLE    = 1
+	_UI_CONTEXTAVAILABILITY_ACTIVE       = 2
+
+	_UI_EXECUTIONVERB_EXECUTE = 0
+
+	_UI_INVALIDATIONS_STATE         = 0x1
+	_UI_INVALIDATIONS_VALUE         = 0x2
+	_UI_INVALIDATIONS_PROPERTY      = 0x4
+	_UI_INVALIDATIONS_ALLPROPERTIES = 0x8
+
+	_UI_VIEWTYPE_RIBBON = 1
+
+	_UI_VIEWVERB_CREATE  = 0
+	_UI_VIEWVERB_DESTROY = 1
+	_UI_VIEWVERB_SIZE    = 2
+)
+
+// uiPropertyKey mirrors PROPERTYKEY.
+type uiPropertyKey struct {
+	fmtid syscall.GUID
+	pid   uint32
+}
+
+// newUIPropertyKey returns the key of a property of the Ribbon Framework, as
+// defined by DEFINE_UIPROPERTYKEY in UIRibbonKeydef.h.
+func newUIPropertyKey(index uint32, vt win.VARTYPE) uiPropertyKey {
+	return uiPropertyKey{
+		fmtid: syscall.GUID{index, 0x7363, 0x696E, [8]byte{0x84, 0x41, 0x79, 0x8A, 0xCF, 0x5A, 0xEB, 0xB7}},
+		pid:   uint32(vt),
+	}
+}
+
+var (
+	_UI_PKEY_Enabled            = newUIPropertyKey(1, win.VT_BOOL)
+	_UI_PKEY_Label              = newUIPropertyKey(4, win.VT_LPWSTR)
+	_UI_PKEY_TooltipDescription = newUIPropertyKey(5, win.VT_LPWSTR)
+	_UI_PKEY_TooltipTitle       = newUIPropertyKey(6, win.VT_LPWSTR)
+	_UI_PKEY_ItemsSource        = newUIPropertyKey(101, win.VT_UNKNOWN)
+	_UI_PKEY_CategoryId         = newUIPropertyKey(103, win.VT_UI4)
+	_UI_PKEY_SelectedItem       = newUIPropertyKey(104, win.VT_UI4)
+	_UI_PKEY_BooleanValue       = newUIPropertyKey(200, win.VT_BOOL)
+	_UI_PKEY_ContextAvailable   = newUIPropertyKey(1100, win.VT_UI4)
+)
+
+// propVariant mirrors the parts of PROPVARIANT used by the Ribbon Framework.
+type propVariant struct {
+	vt       win.VARTYPE
+	reserved [3]uint16
+	val      uintptr
+	val2     uintptr
+}
+
+func (pv *propVariant) setBool(value bool) {
+	pv.vt = win.VT_BOOL
+	pv.val = 0
+	if value {
+		pv.val = uintptr(uint16(0xFFFF)) // VARIANT_TRUE
+	}
+}
+
+func (pv *propVariant) setUInt32(value uint32) {
+	pv.vt = win.VT_UI4
+	pv.val = uintptr(value)
+}
+
+// setString stores a copy of value, which the Ribbon Framework frees using
+// PropVariantClear.
+func (pv *propVariant) setString(value string) error {
+	buf, err := syscall.UTF16FromString(value)
+	if err != nil {
+		return err
+	}
+
+	p := coTaskMemAlloc(uintptr(len(buf)) * 2)
+	if p == nil {
+		return newError("CoTaskMemAlloc failed")
+	}
+
+	copy(unsafe.Slice((*uint16)(p), len(buf)), buf)
+
+	pv.vt = win.VT_LPWSTR
+	pv.val = uintptr(p)
+
+	return nil
+}
+
+func (pv *propVariant) bool() bool {
+	return pv.vt == win.VT_BOOL && int16(pv.val) != 0
+}
+
+func (pv *propVariant) uint32() uint32 {
+	return uint32(pv.val)
+}
+
+func (pv *propVariant) unknown() *win.IUnknown {
+	if pv.vt != win.VT_UNKNOWN {
+		return nil
+	}
+
+	return *(**win.IUnknown)(unsafe.Pointer(&pv.val))
+}
+
+type iUIFrameworkVtbl struct {
+	win.IUnknownVtbl
+	Initialize                uintptr
+	Destroy                   uintptr
+	LoadUI                    uintptr
+	GetView                   uintptr
+	GetUICommandProperty      uintptr
+	SetUICommandProperty      uintptr
+	InvalidateUICommand       uintptr
+	FlushPendingInvalidations uintptr
+	SetModes                  uintptr
+}
+
+type iUIFramework struct {
+	LpVtbl *iUIFrameworkVtbl
+}
+
+func (obj *iUIFramework) Release() uint32 {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.Release,
+		uintptr(unsafe.Pointer(obj)))
+
+	return uint32(ret)
+}
+
+func (obj *iUIFramework) Initialize(frameWnd win.HWND, application unsafe.Pointer) win.HRESULT {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.Initialize,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(frameWnd),
+		uintptr(application))
+
+	return win.HRESULT(ret)
+}
+
+func (obj *iUIFramework) Destroy() win.HRESULT {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.Destroy,
+		uintptr(unsafe.Pointer(obj)))
+
+	return win.HRESULT(ret)
+}
+
+func (obj *iUIFramework) LoadUI(instance win.HINSTANCE, resourceName *uint16) win.HRESULT {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.LoadUI,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(instance),
+		uintptr(unsafe.Pointer(resourceName)))
+
+	return win.HRESULT(ret)
+}
+
+func (obj *iUIFramework) InvalidateUICommand(commandId uint32, flags uint32, key *uiPropertyKey) win.HRESULT {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.InvalidateUICommand,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(commandId),
+		uintptr(flags),
+		uintptr(unsafe.Pointer(key)))
+
+	return win.HRESULT(ret)
+}
+
+func (obj *iUIFramework) SetModes(modes int32) win.HRESULT {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.SetModes,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(modes))
+
+	return win.HRESULT(ret)
+}
+
+type iUIRibbonVtbl struct {
+	win.IUnknownVtbl
+	GetHeight              uintptr
+	LoadSettingsFromStream uintptr
+	SaveSettingsToStream   uintptr
+}
+
+type iUIRibbon struct {
+	LpVtbl *iUIRibbonVtbl
+}
+
+func (obj *iUIRibbon) Release() uint32 {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.Release,
+		uintptr(unsafe.Pointer(obj)))
+
+	return uint32(ret)
+}
+
+func (obj *iUIRibbon) GetHeight(height *uint32) win.HRESULT {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.GetHeight,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(unsafe.Pointer(height)))
+
+	return win.HRESULT(ret)
+}
+
+type iUICollectionVtbl struct {
+	win.IUnknownVtbl
+	GetCount uintptr
+	GetItem  uintptr
+	Add      uintptr
+	Insert   uintptr
+	RemoveAt uintptr
+	Replace  uintptr
+	Clear    uintptr
+}
+
+type iUICollection struct {
+	LpVtbl *iUICollectionVtbl
+}
+
+func (obj *iUICollection) Release() uint32 {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.Release,
+		uintptr(unsafe.Pointer(obj)))
+
+	return uint32(ret)
+}
+
+func (obj *iUICollection) Add(item unsafe.Pointer) win.HRESULT {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.Add,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(item))
+
+	return win.HRESULT(ret)
+}
+
+func (obj *iUICollection) Clear() win.HRESULT {
+	ret, _, _ := syscall.SyscallN(obj.LpVtbl.Clear,
+		uintptr(unsafe.Pointer(obj)))
+
+	return win.HRESULT(ret)
+}
+
+// queryInterface obtains the interface riid of the COM object unknown.
+func queryInterface(unknown *win.IUnknown, riid *win.IID) (unsafe.Pointer, win.HRESULT) {
+	var obj unsafe.Pointer
+	ret, _, _ := syscall.SyscallN(unknown.LpVtbl.QueryInterface,
+		uintptr(unsafe.Pointer(unknown)),
+		uintptr(unsafe.Pointer(riid)),
+		uintptr(unsafe.Pointer(&obj)))
+
+	return obj, win.HRESULT(ret)
+}
@@ -0,0 +1,81 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"syscall"
+	"unsafe"
+
+	"github.com/tailscale/win"
+)
+
+type iUISimplePropertySetVtbl struct {
+	QueryInterface uintptr
+	AddRef         uintptr
+	Release        uintptr
+	GetValue       uintptr
+}
+
+var ribbonGalleryItemVtbl *iUISimplePropertySetVtbl
+
+func init() {
+	AppendToWalkInit(func() {
+		ribbonGalleryItemVtbl = &iUISimplePropertySetVtbl{
+			syscall.NewCallback(ribbonGalleryItem_QueryInterface),
+			syscall.NewCallback(ribbonGalleryItem_AddRef),
+			syscall.NewCallback(ribbonGalleryItem_Release),
+			syscall.NewCallback(ribbonGalleryItem_GetValue),
+		}
+	})
+}
+
+// ribbonGalleryItem provides the properties of an item of a RibbonGallery
+// through IUISimplePropertySet.
+type ribbonGalleryItem struct {
+	lpVtbl *iUISimplePropertySetVtbl
+	label  string
+}
+
+func newRibbonGalleryItem(label string) *ribbonGalleryItem {
+	return &ribbonGalleryItem{ribbonGalleryItemVtbl, label}
+}
+
+func ribbonGalleryItem_QueryInterface(item *ribbonGalleryItem, riid win.REFIID, ppvObject *unsafe.Pointer) uintptr {
+	if win.EqualREFIID(riid, &win.IID_IUnknown) || win.EqualREFIID(riid, &iid_IUISimplePropertySet) {
+		*ppvObject = unsafe.Pointer(item)
+		return win.S_OK
+	}
+
+	*ppvObject = nil
+	return win.E_NOINTERFACE
+}
+
+func ribbonGalleryItem_AddRef(item *ribbonGalleryItem) uintptr {
+	return 1
+}
+
+func ribbonGalleryItem_Release(item *ribbonGalleryItem) uintptr {
+	return 1
+}
+
+func ribbonGalleryItem_GetValue(item *ribbonGalleryItem, key *uiPropertyKey, value *propVariant) uintptr {
+	switch *key {
+	case _UI_PKEY_Label:
+		if err := value.setString(item.label); err != nil {
+			return win.E_OUTOFMEMORY
+		}
+
+	case _UI_PKEY_CategoryId:
+		value.setUInt32(_UI_COLLECTION_INVALIDINDEX)
+
+	default:
+		return win.E_NOTIMPL
+	}
+
+	return win.S_OK
+}
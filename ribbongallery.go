@@ -0,0 +1,130 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"unsafe"
+
+	"github.com/tailscale/win"
+)
+
+// RibbonGallery provides the items of a gallery or combo box of a Ribbon,
+// such as a DropDownGallery, InRibbonGallery or SplitButtonGallery, and
+// tracks the selected one.
+type RibbonGallery struct {
+	ribbon                       *Ribbon
+	commandId                    uint32
+	items                        []*ribbonGalleryItem
+	staleItems                   []*ribbonGalleryItem
+	currentIndex                 int
+	currentIndexChangedPublisher EventPublisher
+}
+
+// Items returns the labels of the items of the RibbonGallery.
+func (g *RibbonGallery) Items() []string {
+	labels := make([]string, len(g.items))
+	for i, item := range g.items {
+		labels[i] = item.label
+	}
+
+	return labels
+}
+
+// SetItems sets the labels of the items of the RibbonGallery.
+func (g *RibbonGallery) SetItems(labels []string) error {
+	items := make([]*ribbonGalleryItem, len(labels))
+	for i, label := range labels {
+		items[i] = newRibbonGalleryItem(label)
+	}
+
+	// The Ribbon Framework may still refer to the previous items until it
+	// queries the new ones.
+	g.staleItems = append(g.staleItems, g.items...)
+	g.items = items
+
+	if err := g.ribbon.invalidateProperty(g.commandId, &_UI_PKEY_ItemsSource); err != nil {
+		return err
+	}
+
+	if g.currentIndex >= len(items) {
+		return g.SetCurrentIndex(-1)
+	}
+
+	return nil
+}
+
+// CurrentIndex returns the index of the selected item, or -1 if there is
+// none.
+func (g *RibbonGallery) CurrentIndex() int {
+	return g.currentIndex
+}
+
+// SetCurrentIndex selects the item at index. Pass -1 to select none.
+func (g *RibbonGallery) SetCurrentIndex(index int) error {
+	if index < -1 || index >= len(g.items) {
+		return newError("index out of range")
+	}
+
+	g.setCurrentIndex(index)
+
+	return g.ribbon.invalidateProperty(g.commandId, &_UI_PKEY_SelectedItem)
+}
+
+// CurrentIndexChanged returns the event that is published when the selected
+// item changes.
+func (g *RibbonGallery) CurrentIndexChanged() *Event {
+	return g.currentIndexChangedPublisher.Event()
+}
+
+func (g *RibbonGallery) setCurrentIndex(index int) {
+	if index < -1 || index >= len(g.items) {
+		index = -1
+	}
+
+	if index == g.currentIndex {
+		return
+	}
+
+	g.currentIndex = index
+
+	g.currentIndexChangedPublisher.Publish()
+}
+
+func (g *RibbonGallery) updateProperty(key *uiPropertyKey, currentValue, newValue *propVariant) uintptr {
+	switch *key {
+	case _UI_PKEY_ItemsSource:
+		unknown := currentValue.unknown()
+		if unknown == nil {
+			return win.E_FAIL
+		}
+
+		p, hr := queryInterface(unknown, &iid_IUICollection)
+		if win.FAILED(hr) {
+			return uintptr(hr)
+		}
+		collection := (*iUICollection)(p)
+		defer collection.Release()
+
+		collection.Clear()
+		g.staleItems = nil
+
+		for _, item := range g.items {
+			if hr := collection.Add(unsafe.Pointer(item)); win.FAILED(hr) {
+				return uintptr(hr)
+			}
+		}
+
+		return win.S_OK
+
+	case _UI_PKEY_SelectedItem:
+		newValue.setUInt32(uint32(int32(g.currentIndex)))
+		return win.S_OK
+	}
+
+	return win.E_NOTIMPL
+}
@@ -0,0 +1,763 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"syscall"
+	"unsafe"
+
+	"github.com/dblohm7/wingoes/com"
+	"github.com/tailscale/win"
+)
+
+// RichTextFormat specifies the data format used by RichTextEdit.StreamIn and
+// RichTextEdit.StreamOut.
+type RichTextFormat int
+
+const (
+	// RichTextFormatPlain is UTF-16 plain text.
+	RichTextFormatPlain RichTextFormat = iota
+
+	// RichTextFormatRTF is Rich Text Format.
+	RichTextFormatRTF
+)
+
+// RichTextCharFormatFields selects the members of RichTextCharFormat that are
+// valid, or that are to be applied.
+type RichTextCharFormatFields uint32
+
+const (
+	RichTextCharFamily RichTextCharFormatFields = 1 << iota
+	RichTextCharPointSize
+	RichTextCharStyle
+	RichTextCharTextColor
+	RichTextCharBackgroundColor
+)
+
+// RichTextCharFormat describes character formatting of a RichTextEdit.
+type RichTextCharFormat struct {
+	Fields          RichTextCharFormatFields
+	Family          string
+	PointSize       int
+	Style           FontStyle
+	TextColor       Color
+	BackgroundColor Color
+}
+
+// RichTextParaFormatFields selects the members of RichTextParaFormat that are
+// valid, or that are to be applied.
+type RichTextParaFormatFields uint32
+
+const (
+	RichTextParaAlignment RichTextParaFormatFields = 1 << iota
+	RichTextParaIndents
+	RichTextParaBullet
+)
+
+// RichTextParaFormat describes paragraph formatting of a RichTextEdit.
+//
+// Indents are specified in 1/96".
+type RichTextParaFormat struct {
+	Fields          RichTextParaFormatFields
+	Alignment       Alignment1D
+	StartIndent     int
+	RightIndent     int
+	FirstLineOffset int
+	Bullet          bool
+}
+
+// RichTextFindFlags controls the behavior of RichTextEdit.Find.
+type RichTextFindFlags uint32
+
+const (
+	RichTextFindMatchCase RichTextFindFlags = 1 << iota
+	RichTextFindWholeWord
+	RichTextFindBackward
+)
+
+// RichTextEdit is a multi-line edit control supporting character and
+// paragraph formatting, RTF, embedded images and hyperlinks. It is backed by
+// the Rich Edit 4.1+ control hosted in msftedit.dll.
+type RichTextEdit struct {
+	WidgetBase
+	readOnlyChangedPublisher  EventPublisher
+	textChangedPublisher      EventPublisher
+	selectionChangedPublisher EventPublisher
+	linkClickedPublisher      StringEventPublisher
+	findHighlights            findHighlights
+}
+
+func NewRichTextEdit(parent Container) (*RichTextEdit, error) {
+	return NewRichTextEditWithStyle(parent, 0)
+}
+
+func NewRichTextEditWithStyle(parent Container, style uint32) (*RichTextEdit, error) {
+	if err := libmsftedit.Load(); err != nil {
+		return nil, wrapError(err)
+	}
+
+	rte := new(RichTextEdit)
+
+	if err := InitWidget(
+		rte,
+		parent,
+		win.MSFTEDIT_CLASS,
+		win.WS_TABSTOP|win.WS_VISIBLE|win.WS_VSCROLL|win.ES_MULTILINE|win.ES_AUTOVSCROLL|win.ES_WANTRETURN|style,
+		win.WS_EX_CLIENTEDGE); err != nil {
+		return nil, err
+	}
+
+	rte.SendMessage(win.EM_SETEVENTMASK, 0, win.ENM_CHANGE|win.ENM_SELCHANGE|win.ENM_LINK)
+
+	rte.GraphicsEffects().Add(InteractionEffect)
+	rte.GraphicsEffects().Add(FocusEffect)
+
+	rte.MustRegisterProperty("ReadOnly", NewProperty(
+		func() interface{} {
+			return rte.ReadOnly()
+		},
+		func(v interface{}) error {
+			return rte.SetReadOnly(v.(bool))
+		},
+		rte.readOnlyChangedPublisher.Event()))
+
+	rte.MustRegisterProperty("Text", NewProperty(
+		func() interface{} {
+			return rte.Text()
+		},
+		func(v interface{}) error {
+			return rte.SetText(assertStringOr(v, ""))
+		},
+		rte.textChangedPublisher.Event()))
+
+	return rte, nil
+}
+
+// Text returns the plain text content of the control.
+func (rte *RichTextEdit) Text() string {
+	return rte.text()
+}
+
+// SetText replaces the content of the control with plain text.
+func (rte *RichTextEdit) SetText(text string) error {
+	if text == rte.Text() {
+		return nil
+	}
+
+	return rte.StreamIn(strings.NewReader(text), RichTextFormatPlain, false)
+}
+
+func (rte *RichTextEdit) TextLength() int {
+	gtl := win.GETTEXTLENGTHEX{
+		Flags:    win.GTL_DEFAULT | win.GTL_NUMCHARS,
+		Codepage: 1200,
+	}
+
+	return int(rte.SendMessage(win.EM_GETTEXTLENGTHEX, uintptr(unsafe.Pointer(&gtl)), 0))
+}
+
+// RTF returns the content of the control in Rich Text Format.
+func (rte *RichTextEdit) RTF() (string, error) {
+	var buf bytes.Buffer
+
+	if err := rte.StreamOut(&buf, RichTextFormatRTF, false); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+// SetRTF replaces the content of the control with the Rich Text Format
+// document rtf.
+func (rte *RichTextEdit) SetRTF(rtf string) error {
+	return rte.StreamIn(strings.NewReader(rtf), RichTextFormatRTF, false)
+}
+
+// StreamIn reads content in the specified format from r. If selectionOnly is
+// true, the current selection is replaced, otherwise the whole content.
+func (rte *RichTextEdit) StreamIn(r io.Reader, format RichTextFormat, selectionOnly bool) error {
+	if format == RichTextFormatPlain {
+		// The control expects UTF-16, so transcode on the fly.
+		r = &utf16Reader{r: r}
+	}
+
+	s := &richTextEditStream{r: r}
+
+	if err := rte.stream(win.EM_STREAMIN, s, format, selectionOnly); err != nil {
+		return err
+	}
+
+	rte.textChangedPublisher.Publish()
+
+	return nil
+}
+
+// StreamOut writes content in the specified format to w. If selectionOnly is
+// true, only the current selection is written.
+func (rte *RichTextEdit) StreamOut(w io.Writer, format RichTextFormat, selectionOnly bool) error {
+	var u *utf16Writer
+	if format == RichTextFormatPlain {
+		u = &utf16Writer{w: w}
+		w = u
+	}
+
+	if err := rte.stream(win.EM_STREAMOUT, &richTextEditStream{w: w}, format, selectionOnly); err != nil {
+		return err
+	}
+
+	if u != nil {
+		return u.Flush()
+	}
+
+	return nil
+}
+
+func (rte *RichTextEdit) stream(msg uint32, s *richTextEditStream, format RichTextFormat, selectionOnly bool) error {
+	var flags uintptr
+	if format == RichTextFormatRTF {
+		flags = win.SF_RTF
+	} else {
+		flags = win.SF_TEXT | win.SF_UNICODE
+	}
+	if selectionOnly {
+		flags |= win.SFF_SELECTION
+	}
+
+	richTextEditStreamCookie++
+	cookie := richTextEditStreamCookie
+	richTextEditStreams[cookie] = s
+	defer delete(richTextEditStreams, cookie)
+
+	es := win.EDITSTREAM{
+		DwCookie:    cookie,
+		PfnCallback: richTextEditStreamCallbackPtr,
+	}
+
+	rte.SendMessage(msg, flags, uintptr(unsafe.Pointer(&es)))
+
+	if s.err != nil {
+		return s.err
+	}
+	if es.DwError != 0 {
+		return newError("EDITSTREAM failed")
+	}
+
+	return nil
+}
+
+type richTextEditStream struct {
+	r   io.Reader
+	w   io.Writer
+	err error
+}
+
+var (
+	richTextEditStreamCallbackPtr uintptr
+	richTextEditStreamCookie      uintptr
+	richTextEditStreams           = make(map[uintptr]*richTextEditStream)
+)
+
+func init() {
+	AppendToWalkInit(func() {
+		richTextEditStreamCallbackPtr = syscall.NewCallback(richTextEditStreamCallback)
+	})
+}
+
+func richTextEditStreamCallback(cookie uintptr, buf *byte, cb int32, pcb *int32) uintptr {
+	s, ok := richTextEditStreams[cookie]
+	if !ok {
+		return 1
+	}
+
+	p := unsafe.Slice(buf, cb)
+
+	var n int
+	if s.r != nil {
+		n, s.err = io.ReadFull(s.r, p)
+		if s.err == io.EOF || s.err == io.ErrUnexpectedEOF {
+			s.err = nil
+		}
+	} else {
+		n, s.err = s.w.Write(p)
+	}
+
+	*pcb = int32(n)
+
+	if s.err != nil {
+		return 1
+	}
+
+	return 0
+}
+
+// utf16Reader converts UTF-8 read from r to UTF-16LE.
+type utf16Reader struct {
+	r   io.Reader
+	buf []byte
+	eof bool
+}
+
+func (u *utf16Reader) Read(p []byte) (int, error) {
+	if len(u.buf) == 0 {
+		if u.eof {
+			return 0, io.EOF
+		}
+
+		data, err := io.ReadAll(u.r)
+		if err != nil {
+			return 0, err
+		}
+		u.eof = true
+
+		units := syscall.StringToUTF16(string(data))
+		units = units[:len(units)-1]
+		u.buf = unsafe.Slice((*byte)(unsafe.Pointer(unsafe.SliceData(units))), len(units)*2)
+		if len(u.buf) == 0 {
+			return 0, io.EOF
+		}
+	}
+
+	n := copy(p, u.buf)
+	u.buf = u.buf[n:]
+
+	return n, nil
+}
+
+// utf16Writer buffers UTF-16LE and writes it to w as UTF-8 on Flush.
+type utf16Writer struct {
+	w   io.Writer
+	buf []byte
+}
+
+func (u *utf16Writer) Write(p []byte) (int, error) {
+	u.buf = append(u.buf, p...)
+	return len(p), nil
+}
+
+func (u *utf16Writer) Flush() error {
+	units := make([]uint16, len(u.buf)/2)
+	for i := range units {
+		units[i] = uint16(u.buf[2*i]) | uint16(u.buf[2*i+1])<<8
+	}
+
+	_, err := io.WriteString(u.w, syscall.UTF16ToString(units))
+	return err
+}
+
+func (rte *RichTextEdit) TextSelection() (start, end int) {
+	var cr win.CHARRANGE
+	rte.SendMessage(win.EM_EXGETSEL, 0, uintptr(unsafe.Pointer(&cr)))
+	return int(cr.CpMin), int(cr.CpMax)
+}
+
+// SetTextSelection selects the characters from start to end. An end of -1
+// extends the selection to the end of the text.
+func (rte *RichTextEdit) SetTextSelection(start, end int) {
+	cr := win.CHARRANGE{CpMin: int32(start), CpMax: int32(end)}
+	rte.SendMessage(win.EM_EXSETSEL, 0, uintptr(unsafe.Pointer(&cr)))
+}
+
+func (rte *RichTextEdit) ReplaceSelectedText(text string, canUndo bool) {
+	rte.SendMessage(win.EM_REPLACESEL,
+		uintptr(win.BoolToBOOL(canUndo)),
+		uintptr(unsafe.Pointer(syscall.StringToUTF16Ptr(text))))
+}
+
+func (rte *RichTextEdit) AppendText(value string) {
+	s, e := rte.TextSelection()
+	l := rte.TextLength()
+	rte.SetTextSelection(l, l)
+	rte.ReplaceSelectedText(value, false)
+	rte.SetTextSelection(s, e)
+}
+
+// TextRange returns the plain text of the characters from start to end.
+func (rte *RichTextEdit) TextRange(start, end int) string {
+	if end < 0 {
+		end = rte.TextLength()
+	}
+	if end <= start {
+		return ""
+	}
+
+	buf := make([]uint16, end-start+1)
+	tr := win.TEXTRANGE{
+		Chrg:      win.CHARRANGE{CpMin: int32(start), CpMax: int32(end)},
+		LpstrText: &buf[0],
+	}
+	n := int(rte.SendMessage(win.EM_GETTEXTRANGE, 0, uintptr(unsafe.Pointer(&tr))))
+
+	return syscall.UTF16ToString(buf[:n])
+}
+
+// Find searches for text starting at character position start and returns
+// the range of the first match.
+func (rte *RichTextEdit) Find(text string, start int, flags RichTextFindFlags) (matchStart, matchEnd int, found bool) {
+	ft := findTextEx{
+		chrg:      win.CHARRANGE{CpMin: int32(start), CpMax: -1},
+		lpstrText: syscall.StringToUTF16Ptr(text),
+	}
+
+	var fr uintptr
+	if flags&RichTextFindBackward != 0 {
+		ft.chrg.CpMax = 0
+	} else {
+		fr |= _FR_DOWN
+	}
+	if flags&RichTextFindMatchCase != 0 {
+		fr |= _FR_MATCHCASE
+	}
+	if flags&RichTextFindWholeWord != 0 {
+		fr |= _FR_WHOLEWORD
+	}
+
+	if int32(rte.SendMessage(win.EM_FINDTEXTEXW, fr, uintptr(unsafe.Pointer(&ft)))) < 0 {
+		return 0, 0, false
+	}
+
+	return int(ft.chrgText.CpMin), int(ft.chrgText.CpMax), true
+}
+
+func (rte *RichTextEdit) searchText() string {
+	// Unlike Text, TextRange matches character positions, as paragraphs end
+	// with a single carriage return.
+	return rte.TextRange(0, -1)
+}
+
+func (rte *RichTextEdit) setFindHighlights(highlights findHighlights) {
+	rte.findHighlights = highlights
+
+	rte.Invalidate()
+}
+
+// posFromChar returns the client position of the character at index.
+func (rte *RichTextEdit) posFromChar(index int) (Point, bool) {
+	var pt win.POINT
+	rte.SendMessage(win.EM_POSFROMCHAR, uintptr(unsafe.Pointer(&pt)), uintptr(index))
+
+	return Point{int(pt.X), int(pt.Y)}, true
+}
+
+// SelectionCharFormat returns the character formatting of the current
+// selection. Only members that are uniform across the selection are included
+// in the Fields of the result.
+func (rte *RichTextEdit) SelectionCharFormat() RichTextCharFormat {
+	var cf win.CHARFORMAT2
+	cf.CbSize = uint32(unsafe.Sizeof(cf))
+	cf.DwMask = win.CFM_FACE | win.CFM_SIZE | win.CFM_BOLD | win.CFM_ITALIC | win.CFM_UNDERLINE |
+		win.CFM_STRIKEOUT | win.CFM_COLOR | win.CFM_BACKCOLOR
+
+	rte.SendMessage(win.EM_GETCHARFORMAT, win.SCF_SELECTION, uintptr(unsafe.Pointer(&cf)))
+
+	var format RichTextCharFormat
+
+	if cf.DwMask&win.CFM_FACE != 0 {
+		format.Fields |= RichTextCharFamily
+		format.Family = syscall.UTF16ToString(cf.SzFaceName[:])
+	}
+	if cf.DwMask&win.CFM_SIZE != 0 {
+		format.Fields |= RichTextCharPointSize
+		format.PointSize = int(cf.YHeight / 20)
+	}
+	const styleMask = win.CFM_BOLD | win.CFM_ITALIC | win.CFM_UNDERLINE | win.CFM_STRIKEOUT
+	if cf.DwMask&styleMask == styleMask {
+		format.Fields |= RichTextCharStyle
+		format.Style = FontStyle(cf.DwEffects & styleMask)
+	}
+	if cf.DwMask&win.CFM_COLOR != 0 && cf.DwEffects&win.CFE_AUTOCOLOR == 0 {
+		format.Fields |= RichTextCharTextColor
+		format.TextColor = Color(cf.CrTextColor)
+	}
+	if cf.DwMask&win.CFM_BACKCOLOR != 0 && cf.DwEffects&win.CFE_AUTOBACKCOLOR == 0 {
+		format.Fields |= RichTextCharBackgroundColor
+		format.BackgroundColor = Color(cf.CrBackColor)
+	}
+
+	return format
+}
+
+// SetSelectionCharFormat applies the members of format selected by its Fields
+// to the current selection, or to the insertion point if nothing is selected.
+func (rte *RichTextEdit) SetSelectionCharFormat(format RichTextCharFormat) error {
+	var cf win.CHARFORMAT2
+	cf.CbSize = uint32(unsafe.Sizeof(cf))
+
+	if format.Fields&RichTextCharFamily != 0 {
+		face := syscall.StringToUTF16(format.Family)
+		if len(face) > len(cf.SzFaceName) {
+			return newError("family name too long")
+		}
+		cf.DwMask |= win.CFM_FACE
+		copy(cf.SzFaceName[:], face)
+	}
+	if format.Fields&RichTextCharPointSize != 0 {
+		cf.DwMask |= win.CFM_SIZE
+		cf.YHeight = int32(format.PointSize * 20)
+	}
+	if format.Fields&RichTextCharStyle != 0 {
+		cf.DwMask |= win.CFM_BOLD | win.CFM_ITALIC | win.CFM_UNDERLINE | win.CFM_STRIKEOUT
+		cf.DwEffects |= uint32(format.Style)
+	}
+	if format.Fields&RichTextCharTextColor != 0 {
+		cf.DwMask |= win.CFM_COLOR
+		cf.CrTextColor = win.COLORREF(format.TextColor)
+	}
+	if format.Fields&RichTextCharBackgroundColor != 0 {
+		cf.DwMask |= win.CFM_BACKCOLOR
+		cf.CrBackColor = win.COLORREF(format.BackgroundColor)
+	}
+
+	if 0 == rte.SendMessage(win.EM_SETCHARFORMAT, win.SCF_SELECTION, uintptr(unsafe.Pointer(&cf))) {
+		return newError("SendMessage(EM_SETCHARFORMAT)")
+	}
+
+	return nil
+}
+
+// SelectionParaFormat returns the paragraph formatting of the paragraphs in
+// the current selection. Only members that are uniform across the selection
+// are included in the Fields of the result.
+func (rte *RichTextEdit) SelectionParaFormat() RichTextParaFormat {
+	var pf win.PARAFORMAT2
+	pf.CbSize = uint32(unsafe.Sizeof(pf))
+	pf.DwMask = win.PFM_ALIGNMENT | win.PFM_STARTINDENT | win.PFM_RIGHTINDENT | win.PFM_OFFSET | win.PFM_NUMBERING
+
+	rte.SendMessage(win.EM_GETPARAFORMAT, 0, uintptr(unsafe.Pointer(&pf)))
+
+	var format RichTextParaFormat
+
+	if pf.DwMask&win.PFM_ALIGNMENT != 0 {
+		format.Fields |= RichTextParaAlignment
+		switch pf.WAlignment {
+		case win.PFA_CENTER:
+			format.Alignment = AlignCenter
+
+		case win.PFA_RIGHT:
+			format.Alignment = AlignFar
+
+		default:
+			format.Alignment = AlignNear
+		}
+	}
+	const indentMask = win.PFM_STARTINDENT | win.PFM_RIGHTINDENT | win.PFM_OFFSET
+	if pf.DwMask&indentMask == indentMask {
+		format.Fields |= RichTextParaIndents
+		// The control reports the start indent of the first line; we expose
+		// the indent of the paragraph body, like PFM_OFFSETINDENT does.
+		format.StartIndent = twipsTo96DPI(pf.DxStartIndent + pf.DxOffset)
+		format.RightIndent = twipsTo96DPI(pf.DxRightIndent)
+		format.FirstLineOffset = twipsTo96DPI(-pf.DxOffset)
+	}
+	if pf.DwMask&win.PFM_NUMBERING != 0 {
+		format.Fields |= RichTextParaBullet
+		format.Bullet = pf.WNumbering == win.PFN_BULLET
+	}
+
+	return format
+}
+
+// SetSelectionParaFormat applies the members of format selected by its Fields
+// to the paragraphs in the current selection.
+func (rte *RichTextEdit) SetSelectionParaFormat(format RichTextParaFormat) error {
+	var pf win.PARAFORMAT2
+	pf.CbSize = uint32(unsafe.Sizeof(pf))
+
+	if format.Fields&RichTextParaAlignment != 0 {
+		pf.DwMask |= win.PFM_ALIGNMENT
+		switch format.Alignment {
+		case AlignCenter:
+			pf.WAlignment = win.PFA_CENTER
+
+		case AlignFar:
+			pf.WAlignment = win.PFA_RIGHT
+
+		default:
+			pf.WAlignment = win.PFA_LEFT
+		}
+	}
+	if format.Fields&RichTextParaIndents != 0 {
+		pf.DwMask |= win.PFM_STARTINDENT | win.PFM_RIGHTINDENT | win.PFM_OFFSET
+		pf.DxStartIndent = twipsFrom96DPI(format.StartIndent + format.FirstLineOffset)
+		pf.DxRightIndent = twipsFrom96DPI(format.RightIndent)
+		pf.DxOffset = twipsFrom96DPI(-format.FirstLineOffset)
+	}
+	if format.Fields&RichTextParaBullet != 0 {
+		pf.DwMask |= win.PFM_NUMBERING
+		if format.Bullet {
+			pf.WNumbering = win.PFN_BULLET
+		}
+	}
+
+	if 0 == rte.SendMessage(win.EM_SETPARAFORMAT, 0, uintptr(unsafe.Pointer(&pf))) {
+		return newError("SendMessage(EM_SETPARAFORMAT)")
+	}
+
+	return nil
+}
+
+func twipsFrom96DPI(value int) int32 {
+	return int32(value * 15)
+}
+
+func twipsTo96DPI(value int32) int {
+	return int(value / 15)
+}
+
+// InsertImage inserts an image at the insertion point, replacing the current
+// selection. data holds an encoded image in any format supported by WIC, e.g.
+// PNG or JPEG. size is the display size in 1/96".
+//
+// InsertImage requires Windows 8 or later.
+func (rte *RichTextEdit) InsertImage(data []byte, size Size, altText string) error {
+	stream, err := com.NewMemoryStream(data)
+	if err != nil {
+		return wrapError(err)
+	}
+
+	const himetricPer96DPI = 2540.0 / 96.0
+
+	params := win.RICHEDIT_IMAGE_PARAMETERS{
+		XWidth:   int32(float64(size.Width) * himetricPer96DPI),
+		YHeight:  int32(float64(size.Height) * himetricPer96DPI),
+		Type:     _TA_BASELINE,
+		PIStream: uintptr(unsafe.Pointer(stream.UnsafeUnwrap())),
+	}
+	if altText != "" {
+		params.PwszAlternateText = syscall.StringToUTF16Ptr(altText)
+	}
+
+	if hr := win.HRESULT(rte.SendMessage(win.EM_INSERTIMAGE, 0, uintptr(unsafe.Pointer(&params)))); win.FAILED(hr) {
+		return errorFromHRESULT("SendMessage(EM_INSERTIMAGE)", hr)
+	}
+
+	return nil
+}
+
+// AutoURLDetect returns whether URLs in the text are automatically detected
+// and formatted as links.
+func (rte *RichTextEdit) AutoURLDetect() bool {
+	return rte.SendMessage(win.EM_GETAUTOURLDETECT, 0, 0) != 0
+}
+
+// SetAutoURLDetect sets whether URLs in the text are automatically detected
+// and formatted as links. Clicking a link publishes the LinkClicked event.
+func (rte *RichTextEdit) SetAutoURLDetect(enabled bool) error {
+	var flags uintptr
+	if enabled {
+		flags = win.AURL_ENABLEURL
+	}
+
+	if 0 != rte.SendMessage(win.EM_AUTOURLDETECT, flags, 0) {
+		return newError("SendMessage(EM_AUTOURLDETECT)")
+	}
+
+	return nil
+}
+
+func (rte *RichTextEdit) ReadOnly() bool {
+	return rte.hasStyleBits(win.ES_READONLY)
+}
+
+func (rte *RichTextEdit) SetReadOnly(readOnly bool) error {
+	if 0 == rte.SendMessage(win.EM_SETREADONLY, uintptr(win.BoolToBOOL(readOnly)), 0) {
+		return newError("SendMessage(EM_SETREADONLY)")
+	}
+
+	rte.readOnlyChangedPublisher.Publish()
+
+	return nil
+}
+
+func (rte *RichTextEdit) TextChanged() *Event {
+	return rte.textChangedPublisher.Event()
+}
+
+func (rte *RichTextEdit) SelectionChanged() *Event {
+	return rte.selectionChangedPublisher.Event()
+}
+
+// LinkClicked returns the event that is published with the link text when the
+// user clicks a link.
+func (rte *RichTextEdit) LinkClicked() *StringEvent {
+	return rte.linkClickedPublisher.Event()
+}
+
+func (*RichTextEdit) NeedsWmSize() bool {
+	return true
+}
+
+func (rte *RichTextEdit) WndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) uintptr {
+	switch msg {
+	case win.WM_COMMAND:
+		switch win.HIWORD(uint32(wParam)) {
+		case win.EN_CHANGE:
+			rte.textChangedPublisher.Publish()
+		}
+
+	case win.WM_NOTIFY:
+		switch ((*win.NMHDR)(unsafe.Pointer(lParam))).Code {
+		case win.EN_SELCHANGE:
+			rte.selectionChangedPublisher.Publish()
+
+		case win.EN_LINK:
+			enl := (*win.ENLINK)(unsafe.Pointer(lParam))
+			if enl.Msg == win.WM_LBUTTONUP {
+				rte.linkClickedPublisher.Publish(rte.TextRange(int(enl.Chrg.CpMin), int(enl.Chrg.CpMax)))
+			}
+		}
+
+	case win.WM_GETDLGCODE:
+		if wParam == win.VK_RETURN {
+			return win.DLGC_WANTALLKEYS
+		}
+
+		return win.DLGC_HASSETSEL | win.DLGC_WANTARROWS | win.DLGC_WANTCHARS
+
+	case win.WM_KEYDOWN:
+		if Key(wParam) == KeyA && ControlDown() {
+			rte.SetTextSelection(0, -1)
+		}
+
+	case win.WM_PAINT:
+		if len(rte.findHighlights.matches) > 0 {
+			result := rte.WidgetBase.WndProc(hwnd, msg, wParam, lParam)
+
+			rte.findHighlights.paint(&rte.WindowBase, rte.posFromChar)
+
+			return result
+		}
+	}
+
+	return rte.WidgetBase.WndProc(hwnd, msg, wParam, lParam)
+}
+
+func (rte *RichTextEdit) CreateLayoutItem(ctx *LayoutContext) LayoutItem {
+	return &richTextEditLayoutItem{
+		minSize: rte.dialogBaseUnitsToPixels(Size{20, 12}),
+	}
+}
+
+type richTextEditLayoutItem struct {
+	LayoutItemBase
+	minSize Size // in native pixels
+}
+
+func (*richTextEditLayoutItem) LayoutFlags() LayoutFlags {
+	return ShrinkableHorz | GrowableHorz | GreedyHorz | ShrinkableVert | GrowableVert | GreedyVert
+}
+
+func (li *richTextEditLayoutItem) IdealSize() Size {
+	return SizeFrom96DPI(Size{100, 100}, li.ctx.dpi)
+}
+
+func (li *richTextEditLayoutItem) MinSize() Size {
+	return li.minSize
+}
@@ -0,0 +1,186 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"github.com/tailscale/win"
+)
+
+// ScrollComposite is a Container that behaves like a Composite as long as it
+// gets at least the ideal size of its children, and like a ScrollView when it
+// gets less.
+//
+// Unlike a ScrollView, it reports the ideal size of its children to the
+// layout of its parent and only grows if its children do, so it can be
+// sized to its content, e.g. in a Form using SizeToContent. Scrollbars are
+// only shown when the space allotted to it is smaller than the minimum size
+// of its children.
+type ScrollComposite struct {
+	ScrollView
+}
+
+func NewScrollComposite(parent Container) (*ScrollComposite, error) {
+	sc := &ScrollComposite{ScrollView: ScrollView{horizontal: true, vertical: true}}
+
+	if err := sc.init(sc, parent); err != nil {
+		return nil, err
+	}
+
+	return sc, nil
+}
+
+func (sc *ScrollComposite) CreateLayoutItem(ctx *LayoutContext) LayoutItem {
+	li := &scrollCompositeLayoutItem{
+		horizontal: sc.horizontal,
+		vertical:   sc.vertical,
+		scrollPos:  sc.composite.BoundsPixels().Location(),
+	}
+	li.ctx = ctx
+
+	cli := CreateLayoutItemsForContainerWithContext(sc.composite, ctx)
+	cli.AsLayoutItemBase().parent = li
+	li.children = append(li.children, cli)
+
+	return li
+}
+
+type scrollCompositeLayoutItem struct {
+	ContainerLayoutItemBase
+	horizontal bool
+	vertical   bool
+	scrollPos  Point // current location of the composite in native pixels
+}
+
+// scrollbarSize returns the width of a vertical and the height of a
+// horizontal scrollbar in native pixels.
+func (li *scrollCompositeLayoutItem) scrollbarSize() Size {
+	dpi := uint32(li.ctx.dpi)
+
+	return Size{
+		Width:  int(win.GetSystemMetricsForDpi(win.SM_CXVSCROLL, dpi)),
+		Height: int(win.GetSystemMetricsForDpi(win.SM_CYHSCROLL, dpi)),
+	}
+}
+
+func (li *scrollCompositeLayoutItem) LayoutFlags() LayoutFlags {
+	flags := li.children[0].LayoutFlags()
+
+	if li.horizontal {
+		flags |= ShrinkableHorz
+	}
+	if li.vertical {
+		flags |= ShrinkableVert
+	}
+
+	return flags
+}
+
+func (li *scrollCompositeLayoutItem) IdealSize() Size {
+	composite := li.children[0]
+
+	size := composite.(MinSizer).MinSize()
+	if is, ok := composite.(IdealSizer); ok {
+		size = maxSize(size, is.IdealSize())
+	}
+
+	return size
+}
+
+func (li *scrollCompositeLayoutItem) MinSize() Size {
+	return li.MinSizeForSize(li.geometry.ClientSize)
+}
+
+func (li *scrollCompositeLayoutItem) MinSizeForSize(size Size) Size {
+	// Scrolling directions need no minimum size, the others need the minimum
+	// size of the children plus room for the scrollbar.
+	var min Size
+
+	if li.horizontal && li.vertical {
+		return min
+	}
+
+	childMin := li.children[0].(MinSizeForSizer).MinSizeForSize(size)
+	sbSize := li.scrollbarSize()
+
+	if !li.horizontal {
+		min.Width = childMin.Width
+		if li.vertical {
+			min.Width += sbSize.Width
+		}
+	}
+	if !li.vertical {
+		min.Height = childMin.Height
+		if li.horizontal {
+			min.Height += sbSize.Height
+		}
+	}
+
+	return min
+}
+
+func (li *scrollCompositeLayoutItem) HasHeightForWidth() bool {
+	return false
+}
+
+func (li *scrollCompositeLayoutItem) HeightForWidth(width int) int {
+	return 0
+}
+
+func (li *scrollCompositeLayoutItem) PerformLayout() []LayoutResultItem {
+	composite := li.children[0]
+	minSizeForSize := composite.(MinSizeForSizer).MinSizeForSize
+
+	sbSize := li.scrollbarSize()
+
+	clientSize := li.geometry.Size
+	minSize := minSizeForSize(clientSize)
+
+	// A scrollbar reduces the space available in the other direction, which
+	// may then require the other scrollbar as well.
+	needH := li.horizontal && minSize.Width > clientSize.Width
+	needV := li.vertical && minSize.Height > clientSize.Height
+
+	if needV {
+		clientSize.Width -= sbSize.Width
+		minSize = minSizeForSize(clientSize)
+		needH = li.horizontal && minSize.Width > clientSize.Width
+	}
+	if needH {
+		clientSize.Height -= sbSize.Height
+		if !needV {
+			minSize = minSizeForSize(clientSize)
+			if needV = li.vertical && minSize.Height > clientSize.Height; needV {
+				clientSize.Width -= sbSize.Width
+				minSize = minSizeForSize(clientSize)
+			}
+		}
+	}
+
+	s := maxSize(minSize, clientSize)
+	if !li.horizontal {
+		s.Width = clientSize.Width
+	}
+	if !li.vertical {
+		s.Height = clientSize.Height
+	}
+
+	// Keep the current scroll position, as far as it is still valid.
+	bounds := Rectangle{
+		X:      mini(0, maxi(li.scrollPos.X, clientSize.Width-s.Width)),
+		Y:      mini(0, maxi(li.scrollPos.Y, clientSize.Height-s.Height)),
+		Width:  s.Width,
+		Height: s.Height,
+	}
+
+	return []LayoutResultItem{
+		{
+			Item:   composite,
+			Bounds: bounds,
+		},
+	}
+}
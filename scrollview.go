@@ -22,33 +22,46 @@ func init() {
 
 type ScrollView struct {
 	WidgetBase
-	composite  *Composite
-	horizontal bool
-	vertical   bool
+	composite        *Composite
+	horizontal       bool
+	vertical         bool
+	smoothScrolling  bool
+	wheelScrollLines int
+	scrollAnimating  bool
+	scrollTarget     Point // in native pixels
 }
 
 func NewScrollView(parent Container) (*ScrollView, error) {
 	sv := &ScrollView{horizontal: true, vertical: true}
 
+	if err := sv.init(sv, parent); err != nil {
+		return nil, err
+	}
+
+	return sv, nil
+}
+
+// init initializes sv as the ScrollView part of widget, which embeds it.
+func (sv *ScrollView) init(widget Widget, parent Container) error {
 	if err := InitWidget(
-		sv,
+		widget,
 		parent,
 		scrollViewWindowClass,
 		win.WS_CHILD|win.WS_HSCROLL|win.WS_VISIBLE|win.WS_VSCROLL,
 		win.WS_EX_CONTROLPARENT); err != nil {
-		return nil, err
+		return err
 	}
 
 	succeeded := false
 	defer func() {
 		if !succeeded {
-			sv.Dispose()
+			widget.Dispose()
 		}
 	}()
 
 	var err error
-	if sv.composite, err = NewComposite(sv); err != nil {
-		return nil, err
+	if sv.composite, err = NewComposite(widget.(Container)); err != nil {
+		return err
 	}
 
 	sv.composite.SizeChanged().Attach(func() {
@@ -59,7 +72,7 @@ func NewScrollView(parent Container) (*ScrollView, error) {
 
 	succeeded = true
 
-	return sv, nil
+	return nil
 }
 
 func (sv *ScrollView) AsContainerBase() *ContainerBase {
@@ -175,34 +188,49 @@ func (sv *ScrollView) WndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr)
 
 		switch msg {
 		case win.WM_HSCROLL:
+			sv.stopScrollAnimation()
 			sv.composite.SetXPixels(sv.scroll(win.SB_HORZ, win.LOWORD(uint32(wParam))))
 			if wParam == win.SB_ENDSCROLL {
 				avoidBGArtifacts()
 			}
 
 		case win.WM_VSCROLL:
+			sv.stopScrollAnimation()
 			sv.composite.SetYPixels(sv.scroll(win.SB_VERT, win.LOWORD(uint32(wParam))))
 			if wParam == win.SB_ENDSCROLL {
 				avoidBGArtifacts()
 			}
 
-		case win.WM_MOUSEWHEEL:
-			if win.GetWindowLong(sv.hWnd, win.GWL_STYLE)&win.WS_VSCROLL == 0 {
-				break
+		case win.WM_MOUSEWHEEL, _WM_MOUSEHWHEEL:
+			delta := int(int16(win.HIWORD(uint32(wParam))))
+
+			sb := int32(win.SB_VERT)
+			if msg == _WM_MOUSEHWHEEL || win.LOWORD(uint32(wParam))&win.MK_SHIFT != 0 {
+				sb = win.SB_HORZ
+			}
+			if msg == win.WM_MOUSEWHEEL {
+				// Rotating the wheel forward scrolls up or left.
+				delta = -delta
 			}
 
-			var cmd uint16
-			if delta := int16(win.HIWORD(uint32(wParam))); delta < 0 {
-				cmd = win.SB_LINEDOWN
-			} else {
-				cmd = win.SB_LINEUP
+			style := win.GetWindowLong(sv.hWnd, win.GWL_STYLE)
+			if sb == win.SB_VERT && style&win.WS_VSCROLL == 0 || sb == win.SB_HORZ && style&win.WS_HSCROLL == 0 {
+				break
 			}
 
-			sv.composite.SetYPixels(sv.scroll(win.SB_VERT, cmd))
-			avoidBGArtifacts()
+			sv.scrollTo(sb, sv.scrollTargetPos(sb)+sv.wheelScrollDistance(sb, delta))
+			if !sv.scrollAnimating {
+				avoidBGArtifacts()
+			}
 
 			return 0
 
+		case win.WM_TIMER:
+			if wParam == scrollViewSmoothScrollTimerId {
+				sv.onSmoothScrollTimer()
+				return 0
+			}
+
 		case win.WM_COMMAND, win.WM_NOTIFY:
 			sv.composite.WndProc(hwnd, msg, wParam, lParam)
 
@@ -272,10 +300,10 @@ func (sv *ScrollView) scroll(sb int32, cmd uint16) int {
 
 	switch cmd {
 	case win.SB_LINELEFT: // == win.SB_LINEUP
-		pos -= int32(sv.IntFrom96DPI(20))
+		pos -= int32(sv.IntFrom96DPI(scrollViewLineSize96dpi))
 
 	case win.SB_LINERIGHT: // == win.SB_LINEDOWN
-		pos += int32(sv.IntFrom96DPI(20))
+		pos += int32(sv.IntFrom96DPI(scrollViewLineSize96dpi))
 
 	case win.SB_PAGELEFT: // == win.SB_PAGEUP
 		pos -= int32(si.NPage)
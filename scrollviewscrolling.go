@@ -0,0 +1,265 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"unsafe"
+
+	"github.com/tailscale/win"
+)
+
+// WheelScrollPage can be passed to ScrollView.SetWheelScrollLines to make
+// each notch of the mouse wheel scroll by a page.
+const WheelScrollPage = -1
+
+const (
+	scrollViewLineSize96dpi           = 20
+	scrollViewSmoothScrollTimerId     = 1
+	scrollViewSmoothScrollTimerElapse = 10 // in milliseconds
+)
+
+// SmoothScrolling returns whether scrolling using the mouse wheel or
+// ScrollToWidget is animated.
+func (sv *ScrollView) SmoothScrolling() bool {
+	return sv.smoothScrolling
+}
+
+// SetSmoothScrolling sets whether scrolling using the mouse wheel or
+// ScrollToWidget is animated.
+func (sv *ScrollView) SetSmoothScrolling(enabled bool) {
+	if !enabled && sv.scrollAnimating {
+		sv.setScrollPos(win.SB_HORZ, sv.scrollTarget.X)
+		sv.setScrollPos(win.SB_VERT, sv.scrollTarget.Y)
+		sv.stopScrollAnimation()
+	}
+
+	sv.smoothScrolling = enabled
+}
+
+// WheelScrollLines returns the number of lines a notch of the mouse wheel
+// scrolls by, WheelScrollPage, or 0 if the system setting is used.
+func (sv *ScrollView) WheelScrollLines() int {
+	return sv.wheelScrollLines
+}
+
+// SetWheelScrollLines sets the number of lines a notch of the mouse wheel
+// scrolls by. Pass WheelScrollPage to scroll by a page, or 0 to use the system
+// setting, which is the default.
+//
+// Rotating the wheel with the shift key down or tilting it scrolls
+// horizontally.
+func (sv *ScrollView) SetWheelScrollLines(lines int) {
+	sv.wheelScrollLines = lines
+}
+
+// ScrollToWidget scrolls by the least distance needed to bring widget, which
+// may be nested in other containers of the ScrollView, into view. If widget
+// is larger than the visible area, its top left corner is brought into view.
+func (sv *ScrollView) ScrollToWidget(widget Widget) error {
+	if widget == nil || !win.IsChild(sv.composite.hWnd, widget.Handle()) {
+		return newError("widget is not a descendant of the ScrollView")
+	}
+
+	var rc win.RECT
+	win.GetWindowRect(widget.Handle(), &rc)
+
+	// The origin of the composite moves with the scroll position, so this
+	// yields positions within the scrolled contents.
+	var origin win.POINT
+	win.ClientToScreen(sv.composite.hWnd, &origin)
+
+	sv.scrollIntoView(win.SB_HORZ, int(rc.Left-origin.X), int(rc.Right-origin.X))
+	sv.scrollIntoView(win.SB_VERT, int(rc.Top-origin.Y), int(rc.Bottom-origin.Y))
+
+	if !sv.scrollAnimating && sv.hasComplexBackground() {
+		sv.composite.Invalidate()
+	}
+
+	return nil
+}
+
+// scrollIntoView scrolls the range from start to end, in native pixels, into
+// view along scroll bar sb.
+func (sv *ScrollView) scrollIntoView(sb int32, start, end int) {
+	page := int(sv.scrollInfo(sb).NPage)
+	pos := sv.scrollTargetPos(sb)
+
+	switch {
+	case start < pos || end-start > page:
+		pos = start
+
+	case end > pos+page:
+		pos = end - page
+
+	default:
+		return
+	}
+
+	sv.scrollTo(sb, pos)
+}
+
+// wheelScrollDistance returns the distance in native pixels to scroll by for
+// delta, in multiples or fractions of WHEEL_DELTA.
+func (sv *ScrollView) wheelScrollDistance(sb int32, delta int) int {
+	lines := sv.wheelScrollLines
+	if lines == 0 {
+		action := uint32(_SPI_GETWHEELSCROLLLINES)
+		if sb == win.SB_HORZ {
+			action = _SPI_GETWHEELSCROLLCHARS
+		}
+
+		n := uint32(3)
+		win.SystemParametersInfo(action, 0, unsafe.Pointer(&n), 0)
+
+		if n == _WHEEL_PAGESCROLL {
+			lines = WheelScrollPage
+		} else {
+			lines = int(n)
+		}
+	}
+
+	var notch int
+	if lines == WheelScrollPage {
+		notch = int(sv.scrollInfo(sb).NPage)
+	} else {
+		notch = lines * sv.IntFrom96DPI(scrollViewLineSize96dpi)
+	}
+
+	// High resolution wheels report fractions of WHEEL_DELTA, which scroll by
+	// fractions of a line.
+	return delta * notch / _WHEEL_DELTA
+}
+
+// scrollTo scrolls to pos along scroll bar sb, animated if smooth scrolling
+// is enabled.
+func (sv *ScrollView) scrollTo(sb int32, pos int) {
+	pos = sv.clampScrollPos(sb, pos)
+
+	if !sv.smoothScrolling || !sv.Visible() {
+		sv.setScrollPos(sb, pos)
+		return
+	}
+
+	if !sv.scrollAnimating {
+		sv.scrollTarget = Point{int(sv.scrollInfo(win.SB_HORZ).NPos), int(sv.scrollInfo(win.SB_VERT).NPos)}
+		sv.scrollAnimating = true
+
+		win.SetTimer(sv.hWnd, scrollViewSmoothScrollTimerId, scrollViewSmoothScrollTimerElapse, 0)
+	}
+
+	if sb == win.SB_HORZ {
+		sv.scrollTarget.X = pos
+	} else {
+		sv.scrollTarget.Y = pos
+	}
+}
+
+// scrollTargetPos returns the position scrolled to along scroll bar sb,
+// including any pending animation.
+func (sv *ScrollView) scrollTargetPos(sb int32) int {
+	if sv.scrollAnimating {
+		if sb == win.SB_HORZ {
+			return sv.scrollTarget.X
+		}
+		return sv.scrollTarget.Y
+	}
+
+	return int(sv.scrollInfo(sb).NPos)
+}
+
+func (sv *ScrollView) onSmoothScrollTimer() {
+	done := true
+
+	for _, sb := range [...]int32{win.SB_HORZ, win.SB_VERT} {
+		target := sv.scrollTarget.Y
+		if sb == win.SB_HORZ {
+			target = sv.scrollTarget.X
+		}
+
+		pos := int(sv.scrollInfo(sb).NPos)
+		if pos == target {
+			continue
+		}
+
+		// Covering a third of the remaining distance per step decelerates
+		// towards the target.
+		step := (target - pos) / 3
+		if step == 0 {
+			if target > pos {
+				step = 1
+			} else {
+				step = -1
+			}
+		}
+
+		// The range may have shrunk meanwhile, so stop where we get stuck.
+		if newPos := sv.setScrollPos(sb, pos+step); newPos != target && newPos != pos {
+			done = false
+		}
+	}
+
+	if done {
+		sv.stopScrollAnimation()
+	}
+}
+
+func (sv *ScrollView) stopScrollAnimation() {
+	if !sv.scrollAnimating {
+		return
+	}
+
+	sv.scrollAnimating = false
+	win.KillTimer(sv.hWnd, scrollViewSmoothScrollTimerId)
+
+	if sv.hasComplexBackground() {
+		sv.composite.Invalidate()
+	}
+}
+
+// setScrollPos moves the contents to pos along scroll bar sb and returns the
+// position actually scrolled to, in native pixels.
+func (sv *ScrollView) setScrollPos(sb int32, pos int) int {
+	pos = sv.clampScrollPos(sb, pos)
+
+	var si win.SCROLLINFO
+	si.CbSize = uint32(unsafe.Sizeof(si))
+	si.FMask = win.SIF_POS
+	si.NPos = int32(pos)
+	win.SetScrollInfo(sv.hWnd, sb, &si, true)
+
+	if sb == win.SB_HORZ {
+		sv.composite.SetXPixels(-pos)
+	} else {
+		sv.composite.SetYPixels(-pos)
+	}
+
+	return pos
+}
+
+func (sv *ScrollView) clampScrollPos(sb int32, pos int) int {
+	si := sv.scrollInfo(sb)
+
+	if max := int(si.NMax) + 1 - int(si.NPage); pos > max {
+		pos = max
+	}
+	if pos < 0 {
+		pos = 0
+	}
+
+	return pos
+}
+
+func (sv *ScrollView) scrollInfo(sb int32) win.SCROLLINFO {
+	var si win.SCROLLINFO
+	si.CbSize = uint32(unsafe.Sizeof(si))
+	si.FMask = win.SIF_PAGE | win.SIF_POS | win.SIF_RANGE
+
+	win.GetScrollInfo(sv.hWnd, sb, &si)
+
+	return si
+}
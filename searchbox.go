@@ -0,0 +1,729 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"syscall"
+	"time"
+	"unsafe"
+
+	"github.com/tailscale/win"
+)
+
+const searchBoxWindowClass = `\o/ Walk_SearchBox_Class \o/`
+
+func init() {
+	AppendToWalkInit(func() {
+		MustRegisterWindowClass(searchBoxWindowClass)
+	})
+}
+
+const (
+	searchBoxGlyphSize96dpi    = 16
+	searchBoxPadding96dpi      = 2
+	searchBoxDebounceTimerId   = 1
+	searchBoxDefaultDebounce   = 300 * time.Millisecond
+	searchBoxMaxVisibleResults = 8
+)
+
+// SearchBox is a widget for entering search terms. It displays a cue banner
+// and a search glyph while empty, and a clear glyph once text has been
+// entered. Pressing Escape clears the text as well.
+//
+// TextChanged is debounced, so a search is not started for every keystroke.
+// Optionally, results can be offered in a dropdown below the box using
+// SetResults.
+type SearchBox struct {
+	WidgetBase
+	edit                     *searchLineEdit
+	results                  *searchResultsPopup
+	debounceInterval         time.Duration
+	debouncing               bool
+	clearHot                 bool
+	textChangedPublisher     EventPublisher
+	resultActivatedPublisher IntEventPublisher
+}
+
+// NewSearchBox returns a new SearchBox widget as child of parent.
+func NewSearchBox(parent Container) (*SearchBox, error) {
+	sb := &SearchBox{debounceInterval: searchBoxDefaultDebounce}
+
+	if err := InitWidget(
+		sb,
+		parent,
+		searchBoxWindowClass,
+		win.WS_VISIBLE|win.WS_CLIPCHILDREN,
+		win.WS_EX_CLIENTEDGE|win.WS_EX_CONTROLPARENT); err != nil {
+		return nil, err
+	}
+
+	var succeeded bool
+	defer func() {
+		if !succeeded {
+			sb.Dispose()
+		}
+	}()
+
+	var err error
+	if sb.edit, err = newSearchLineEdit(sb); err != nil {
+		return nil, err
+	}
+
+	sb.edit.applyFont(sb.Font())
+
+	if err := sb.edit.SetCueBanner(tr("Search…", "walk")); err != nil {
+		return nil, err
+	}
+
+	sb.edit.TextChanged().Attach(sb.onEditTextChanged)
+
+	sb.GraphicsEffects().Add(InteractionEffect)
+	sb.GraphicsEffects().Add(FocusEffect)
+
+	sb.MustRegisterProperty("Text", NewProperty(
+		func() interface{} {
+			return sb.Text()
+		},
+		func(v interface{}) error {
+			return sb.SetText(assertStringOr(v, ""))
+		},
+		sb.edit.textChangedPublisher.Event()))
+
+	succeeded = true
+
+	return sb, nil
+}
+
+func (sb *SearchBox) applyEnabled(enabled bool) {
+	sb.WidgetBase.applyEnabled(enabled)
+
+	if sb.edit == nil {
+		return
+	}
+
+	sb.edit.applyEnabled(enabled)
+}
+
+func (sb *SearchBox) applyFont(font *Font) {
+	sb.WidgetBase.applyFont(font)
+
+	if sb.edit == nil {
+		return
+	}
+
+	sb.edit.applyFont(font)
+	sb.layoutEdit()
+}
+
+// SetFocus sets the keyboard input focus to the SearchBox.
+func (sb *SearchBox) SetFocus() error {
+	if win.SetFocus(sb.edit.hWnd) == 0 {
+		return lastError("SetFocus")
+	}
+
+	return nil
+}
+
+// Text returns the search text.
+func (sb *SearchBox) Text() string {
+	return sb.edit.Text()
+}
+
+// SetText sets the search text.
+func (sb *SearchBox) SetText(text string) error {
+	return sb.edit.SetText(text)
+}
+
+// Clear clears the search text and hides the results.
+func (sb *SearchBox) Clear() {
+	sb.hideResults()
+
+	if sb.Text() != "" {
+		sb.SetText("")
+	}
+}
+
+// CueBanner returns the text displayed while the SearchBox is empty.
+func (sb *SearchBox) CueBanner() string {
+	return sb.edit.CueBanner()
+}
+
+// SetCueBanner sets the text displayed while the SearchBox is empty. The
+// default is "Search…", passed through the function set with
+// SetTranslationFunc.
+func (sb *SearchBox) SetCueBanner(cueBanner string) error {
+	return sb.edit.SetCueBanner(cueBanner)
+}
+
+// DebounceInterval returns the time the text must remain unchanged before
+// TextChanged is published.
+func (sb *SearchBox) DebounceInterval() time.Duration {
+	return sb.debounceInterval
+}
+
+// SetDebounceInterval sets the time the text must remain unchanged before
+// TextChanged is published. Zero publishes it on every change. The default is
+// 300 milliseconds.
+func (sb *SearchBox) SetDebounceInterval(interval time.Duration) {
+	if interval < 0 {
+		interval = 0
+	}
+
+	sb.debounceInterval = interval
+}
+
+// TextChanged returns the event that is published when the search text has
+// changed and then remained unchanged for DebounceInterval, or immediately
+// when the user presses Enter or clears the text.
+func (sb *SearchBox) TextChanged() *Event {
+	return sb.textChangedPublisher.Event()
+}
+
+// Results returns the results currently offered in the dropdown.
+func (sb *SearchBox) Results() []string {
+	if sb.results == nil {
+		return nil
+	}
+
+	return sb.results.items
+}
+
+// SetResults sets the results offered in a dropdown below the SearchBox. The
+// dropdown is displayed while the SearchBox has the keyboard focus and there
+// are results. The user can select a result with the arrow keys or the mouse,
+// which publishes ResultActivated.
+func (sb *SearchBox) SetResults(results []string) error {
+	if len(results) == 0 && sb.results == nil {
+		return nil
+	}
+
+	if sb.results == nil {
+		var err error
+		if sb.results, err = newSearchResultsPopup(sb); err != nil {
+			return err
+		}
+	}
+
+	if err := sb.results.setItems(results); err != nil {
+		return err
+	}
+
+	if len(results) > 0 && win.GetFocus() == sb.edit.hWnd {
+		sb.showResults()
+	} else {
+		sb.hideResults()
+	}
+
+	return nil
+}
+
+// ResultActivated returns the event that is published with the index of the
+// result the user has chosen from the dropdown.
+func (sb *SearchBox) ResultActivated() *IntEvent {
+	return sb.resultActivatedPublisher.Event()
+}
+
+func (sb *SearchBox) resultsVisible() bool {
+	return sb.results != nil && win.IsWindowVisible(sb.results.hWnd)
+}
+
+func (sb *SearchBox) showResults() {
+	if sb.results == nil || len(sb.results.items) == 0 {
+		return
+	}
+
+	sb.results.show()
+}
+
+func (sb *SearchBox) hideResults() {
+	if sb.resultsVisible() {
+		win.ShowWindow(sb.results.hWnd, win.SW_HIDE)
+	}
+}
+
+func (sb *SearchBox) activateResult(index int) {
+	if sb.results == nil || index < 0 || index >= len(sb.results.items) {
+		return
+	}
+
+	sb.hideResults()
+
+	sb.resultActivatedPublisher.Publish(index)
+}
+
+func (sb *SearchBox) onEditTextChanged() {
+	sb.Invalidate()
+
+	win.KillTimer(sb.hWnd, searchBoxDebounceTimerId)
+
+	if sb.debounceInterval == 0 || sb.Text() == "" {
+		sb.debouncing = false
+		sb.textChangedPublisher.Publish()
+		return
+	}
+
+	sb.debouncing = true
+	win.SetTimer(sb.hWnd, searchBoxDebounceTimerId, uint32(sb.debounceInterval.Milliseconds()), 0)
+}
+
+// flushTextChanged publishes a pending debounced TextChanged immediately.
+func (sb *SearchBox) flushTextChanged() {
+	if !sb.debouncing {
+		return
+	}
+
+	sb.debouncing = false
+	win.KillTimer(sb.hWnd, searchBoxDebounceTimerId)
+
+	sb.textChangedPublisher.Publish()
+}
+
+// glyphBounds returns the bounds of the search or clear glyph in native
+// pixels.
+func (sb *SearchBox) glyphBounds() Rectangle {
+	cb := sb.ClientBoundsPixels()
+	size := sb.IntFrom96DPI(searchBoxGlyphSize96dpi)
+
+	return Rectangle{
+		X:      cb.Width - size - sb.IntFrom96DPI(searchBoxPadding96dpi),
+		Y:      (cb.Height - size) / 2,
+		Width:  size,
+		Height: size,
+	}
+}
+
+// clearGlyphAt returns whether the clear glyph is displayed at pt.
+func (sb *SearchBox) clearGlyphAt(pt Point) bool {
+	if sb.Text() == "" {
+		return false
+	}
+
+	b := sb.glyphBounds()
+
+	return pt.X >= b.X && pt.X < b.X+b.Width && pt.Y >= b.Y && pt.Y < b.Y+b.Height
+}
+
+func (sb *SearchBox) layoutEdit() {
+	cb := sb.ClientBoundsPixels()
+	padding := sb.IntFrom96DPI(searchBoxPadding96dpi)
+
+	height := sb.calculateTextSizeImpl("gM").Height + 2
+	if height > cb.Height {
+		height = cb.Height
+	}
+
+	sb.edit.SetBoundsPixels(Rectangle{
+		X:      padding,
+		Y:      (cb.Height - height) / 2,
+		Width:  sb.glyphBounds().X - 2*padding,
+		Height: height,
+	})
+}
+
+func (sb *SearchBox) paintGlyph(canvas *Canvas) error {
+	bg, err := NewSystemColorBrush(SysColorWindow)
+	if err != nil {
+		return err
+	}
+	defer bg.Dispose()
+
+	if err := canvas.FillRectanglePixels(bg, sb.ClientBoundsPixels()); err != nil {
+		return err
+	}
+
+	b := sb.glyphBounds()
+	empty := sb.Text() == ""
+
+	color := Color(win.GetSysColor(win.COLOR_GRAYTEXT))
+	if !empty && sb.clearHot {
+		color = Color(win.GetSysColor(win.COLOR_WINDOWTEXT))
+	}
+
+	brush, err := NewSolidColorBrush(color)
+	if err != nil {
+		return err
+	}
+	defer brush.Dispose()
+
+	pen, err := NewGeometricPen(PenSolid|PenCapRound, 1, brush)
+	if err != nil {
+		return err
+	}
+	defer pen.Dispose()
+
+	unit := b.Width / 16
+	if unit < 1 {
+		unit = 1
+	}
+
+	if empty {
+		// Magnifying glass
+		lens := Rectangle{b.X + 2*unit, b.Y + 2*unit, 9 * unit, 9 * unit}
+		if err := canvas.DrawEllipsePixels(pen, lens); err != nil {
+			return err
+		}
+
+		return canvas.DrawLinePixels(pen,
+			Point{lens.X + lens.Width - unit, lens.Y + lens.Height - unit},
+			Point{b.X + 14*unit, b.Y + 14*unit})
+	}
+
+	// Cross
+	if err := canvas.DrawLinePixels(pen, Point{b.X + 4*unit, b.Y + 4*unit}, Point{b.X + 12*unit, b.Y + 12*unit}); err != nil {
+		return err
+	}
+
+	return canvas.DrawLinePixels(pen, Point{b.X + 12*unit, b.Y + 4*unit}, Point{b.X + 4*unit, b.Y + 12*unit})
+}
+
+func (sb *SearchBox) setClearHot(hot bool) {
+	if hot == sb.clearHot {
+		return
+	}
+
+	sb.clearHot = hot
+	sb.Invalidate()
+
+	if hot {
+		var tme win.TRACKMOUSEEVENT
+		tme.CbSize = uint32(unsafe.Sizeof(tme))
+		tme.DwFlags = win.TME_LEAVE
+		tme.HwndTrack = sb.hWnd
+
+		win.TrackMouseEvent(&tme)
+	}
+}
+
+func (*SearchBox) NeedsWmSize() bool {
+	return true
+}
+
+// WndProc is the window procedure of the SearchBox.
+//
+// When implementing your own WndProc to add or modify behavior, call the
+// WndProc of the embedded SearchBox for messages you don't handle yourself.
+func (sb *SearchBox) WndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) uintptr {
+	switch msg {
+	case win.WM_PAINT:
+		var ps win.PAINTSTRUCT
+
+		hdc := win.BeginPaint(hwnd, &ps)
+		defer win.EndPaint(hwnd, &ps)
+
+		canvas, err := newCanvasFromHDC(hdc)
+		if err != nil {
+			return 0
+		}
+		defer canvas.Dispose()
+
+		sb.paintGlyph(canvas)
+
+		return 0
+
+	case win.WM_ERASEBKGND:
+		return 1
+
+	case win.WM_CTLCOLOREDIT:
+		win.SetBkColor(win.HDC(wParam), win.COLORREF(win.GetSysColor(win.COLOR_WINDOW)))
+
+		return uintptr(win.GetSysColorBrush(win.COLOR_WINDOW))
+
+	case win.WM_MOUSEMOVE:
+		sb.setClearHot(sb.clearGlyphAt(Point{int(win.GET_X_LPARAM(lParam)), int(win.GET_Y_LPARAM(lParam))}))
+
+	case win.WM_MOUSELEAVE:
+		sb.setClearHot(false)
+
+	case win.WM_LBUTTONDOWN:
+		if sb.clearGlyphAt(Point{int(win.GET_X_LPARAM(lParam)), int(win.GET_Y_LPARAM(lParam))}) {
+			sb.Clear()
+		}
+		sb.SetFocus()
+
+	case win.WM_TIMER:
+		if wParam == searchBoxDebounceTimerId {
+			sb.flushTextChanged()
+			return 0
+		}
+
+	case win.WM_WINDOWPOSCHANGED:
+		wp := (*win.WINDOWPOS)(unsafe.Pointer(lParam))
+
+		if wp.Flags&win.SWP_NOSIZE != 0 || sb.edit == nil {
+			break
+		}
+
+		sb.layoutEdit()
+		sb.Invalidate()
+	}
+
+	return sb.WidgetBase.WndProc(hwnd, msg, wParam, lParam)
+}
+
+func (sb *SearchBox) CreateLayoutItem(ctx *LayoutContext) LayoutItem {
+	return &searchBoxLayoutItem{
+		idealSize: sb.dialogBaseUnitsToPixels(Size{100, 12}),
+		minSize:   sb.dialogBaseUnitsToPixels(Size{30, 12}),
+	}
+}
+
+type searchBoxLayoutItem struct {
+	LayoutItemBase
+	idealSize Size // in native pixels
+	minSize   Size // in native pixels
+}
+
+func (*searchBoxLayoutItem) LayoutFlags() LayoutFlags {
+	return ShrinkableHorz | GrowableHorz
+}
+
+func (li *searchBoxLayoutItem) IdealSize() Size {
+	return li.idealSize
+}
+
+func (li *searchBoxLayoutItem) MinSize() Size {
+	return li.minSize
+}
+
+type searchLineEdit struct {
+	*LineEdit
+	sb *SearchBox
+}
+
+func newSearchLineEdit(sb *SearchBox) (*searchLineEdit, error) {
+	sle := &searchLineEdit{sb: sb}
+
+	var err error
+	if sle.LineEdit, err = newLineEdit(sb); err != nil {
+		return nil, err
+	}
+
+	succeeded := false
+	defer func() {
+		if !succeeded {
+			sle.Dispose()
+		}
+	}()
+
+	if err := sle.ensureExtendedStyleBits(win.WS_EX_CLIENTEDGE, false); err != nil {
+		return nil, err
+	}
+
+	if err := InitWrapperWindow(sle); err != nil {
+		return nil, err
+	}
+
+	succeeded = true
+
+	return sle, nil
+}
+
+func (sle *searchLineEdit) onFocusChanged() {
+	if wnd := windowFromHandle(win.GetParent(sle.sb.hWnd)); wnd != nil {
+		if _, ok := wnd.(Container); ok {
+			sle.sb.invalidateBorderInParent()
+		}
+	}
+}
+
+func (sle *searchLineEdit) WndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) uintptr {
+	sb := sle.sb
+
+	switch msg {
+	case win.WM_GETDLGCODE:
+		if wParam == win.VK_ESCAPE && (sb.Text() != "" || sb.resultsVisible()) {
+			// Don't let a Dialog close before the SearchBox is cleared.
+			return win.DLGC_WANTALLKEYS
+		}
+
+	case win.WM_KEYDOWN:
+		switch Key(wParam) {
+		case KeyEscape:
+			if sb.resultsVisible() {
+				sb.hideResults()
+				return 0
+			}
+			if sb.Text() != "" {
+				sb.Clear()
+				return 0
+			}
+
+		case KeyDown, KeyUp:
+			if !sb.resultsVisible() {
+				if Key(wParam) == KeyDown && sb.results != nil && len(sb.results.items) > 0 {
+					sb.showResults()
+					return 0
+				}
+				break
+			}
+
+			if Key(wParam) == KeyDown {
+				sb.results.moveSelection(1)
+			} else {
+				sb.results.moveSelection(-1)
+			}
+			return 0
+
+		case KeyReturn:
+			if sb.resultsVisible() {
+				if index := sb.results.selection(); index > -1 {
+					sb.activateResult(index)
+					return 0
+				}
+			}
+
+			sb.flushTextChanged()
+		}
+
+	case win.WM_CHAR:
+		if wParam == win.VK_ESCAPE {
+			// Prevent the beep.
+			return 0
+		}
+
+	case win.WM_SETFOCUS:
+		sle.onFocusChanged()
+
+	case win.WM_KILLFOCUS:
+		sle.onFocusChanged()
+
+		if sb.results == nil || win.HWND(wParam) != sb.results.hWnd {
+			sb.hideResults()
+		}
+	}
+
+	return sle.LineEdit.WndProc(hwnd, msg, wParam, lParam)
+}
+
+// searchResultsOwner is a widget that offers results in a
+// searchResultsPopup.
+type searchResultsOwner interface {
+	Widget
+	activateResult(index int)
+}
+
+// searchResultsPopup is the dropdown of a SearchBox or another
+// searchResultsOwner. It never takes the keyboard focus, which stays in the
+// owner.
+type searchResultsPopup struct {
+	WindowBase
+	owner searchResultsOwner
+	items []string
+}
+
+func newSearchResultsPopup(owner searchResultsOwner) (*searchResultsPopup, error) {
+	srp := &searchResultsPopup{owner: owner}
+
+	if err := InitWindow(
+		srp,
+		owner,
+		"LISTBOX",
+		win.WS_POPUP|win.WS_BORDER|win.WS_VSCROLL|win.LBS_NOINTEGRALHEIGHT,
+		win.WS_EX_TOOLWINDOW|win.WS_EX_NOACTIVATE|win.WS_EX_TOPMOST); err != nil {
+		return nil, err
+	}
+
+	srp.SetFont(owner.Font())
+
+	owner.AsWindowBase().AddDisposable(srp)
+
+	return srp, nil
+}
+
+func (srp *searchResultsPopup) setItems(items []string) error {
+	srp.items = append([]string(nil), items...)
+
+	srp.SendMessage(win.LB_RESETCONTENT, 0, 0)
+
+	for _, item := range srp.items {
+		utf16, err := syscall.UTF16PtrFromString(item)
+		if err != nil {
+			return err
+		}
+
+		if int(int32(srp.SendMessage(win.LB_ADDSTRING, 0, uintptr(unsafe.Pointer(utf16))))) < 0 {
+			return newError("LB_ADDSTRING failed")
+		}
+	}
+
+	return nil
+}
+
+// show displays the popup below its owner, tall enough for up to
+// searchBoxMaxVisibleResults items.
+func (srp *searchResultsPopup) show() {
+	var r win.RECT
+	win.GetWindowRect(srp.owner.Handle(), &r)
+
+	n := len(srp.items)
+	if n > searchBoxMaxVisibleResults {
+		n = searchBoxMaxVisibleResults
+	}
+	itemHeight := int32(srp.SendMessage(win.LB_GETITEMHEIGHT, 0, 0))
+	height := int32(n)*itemHeight + 2*int32(win.GetSystemMetricsForDpi(win.SM_CYBORDER, uint32(srp.owner.DPI())))
+
+	win.SetWindowPos(
+		srp.hWnd,
+		win.HWND_TOPMOST,
+		r.Left,
+		r.Bottom,
+		r.Right-r.Left,
+		height,
+		win.SWP_NOACTIVATE|win.SWP_SHOWWINDOW)
+}
+
+func (srp *searchResultsPopup) selection() int {
+	return int(int32(srp.SendMessage(win.LB_GETCURSEL, 0, 0)))
+}
+
+func (srp *searchResultsPopup) moveSelection(delta int) {
+	index := srp.selection() + delta
+	if index < 0 {
+		index = 0
+	}
+	if index >= len(srp.items) {
+		index = len(srp.items) - 1
+	}
+
+	srp.SendMessage(win.LB_SETCURSEL, uintptr(index), 0)
+}
+
+func (srp *searchResultsPopup) itemFromPoint(lParam uintptr) int {
+	ret := uint32(srp.SendMessage(win.LB_ITEMFROMPOINT, 0, lParam))
+	if win.HIWORD(ret) != 0 {
+		// Outside of the client area
+		return -1
+	}
+
+	return int(win.LOWORD(ret))
+}
+
+func (srp *searchResultsPopup) WndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) uintptr {
+	switch msg {
+	case win.WM_MOUSEACTIVATE:
+		return _MA_NOACTIVATE
+
+	case win.WM_MOUSEMOVE:
+		if index := srp.itemFromPoint(lParam); index > -1 && index != srp.selection() {
+			srp.SendMessage(win.LB_SETCURSEL, uintptr(index), 0)
+		}
+		return 0
+
+	case win.WM_LBUTTONDOWN, win.WM_LBUTTONDBLCLK:
+		// The default processing would move the focus to the list.
+		return 0
+
+	case win.WM_LBUTTONUP:
+		if index := srp.itemFromPoint(lParam); index > -1 {
+			srp.owner.activateResult(index)
+		}
+		return 0
+	}
+
+	return srp.WindowBase.WndProc(hwnd, msg, wParam, lParam)
+}
@@ -0,0 +1,278 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"syscall"
+	"unsafe"
+
+	"github.com/tailscale/win"
+	"golang.org/x/sys/windows"
+)
+
+const sessionWindowClassName = "Walk Session Window"
+
+var (
+	guid_ACDC_POWER_SOURCE            = syscall.GUID{0x5D3E9A59, 0xE9D5, 0x4B00, [8]byte{0xA6, 0xBD, 0xFF, 0x34, 0xFF, 0x51, 0x65, 0x48}}
+	guid_BATTERY_PERCENTAGE_REMAINING = syscall.GUID{0xA7AD8041, 0xB45A, 0x4CAE, [8]byte{0x87, 0xA3, 0xEE, 0xCB, 0xB4, 0x68, 0xA9, 0xE1}}
+)
+
+// SessionEndReason specifies why the user session ends. It is a combination
+// of flags; 0 means the system shuts down or restarts.
+type SessionEndReason uint32
+
+const (
+	SessionEndCloseApp SessionEndReason = win.ENDSESSION_CLOSEAPP // the application is closed to be updated
+	SessionEndCritical SessionEndReason = win.ENDSESSION_CRITICAL // the application is closed forcibly
+	SessionEndLogoff   SessionEndReason = win.ENDSESSION_LOGOFF   // the user logs off
+)
+
+// SessionEndQuery is published by Application.SessionEnding.
+type SessionEndQuery struct {
+	Reason SessionEndReason
+
+	// Block can be set by handlers to ask for the session not to end yet.
+	// Windows only honors it while a reason has been set using
+	// Application.BlockShutdown, and never for critical shutdowns.
+	Block bool
+}
+
+// PowerSource is the source the system is powered by.
+type PowerSource uint32
+
+const (
+	PowerSourceAC      PowerSource = 0
+	PowerSourceBattery PowerSource = 1
+	PowerSourceUPS     PowerSource = 2 // short-term power source, like a UPS
+)
+
+// SessionEnding returns the event that is published when the user session is
+// about to end, e.g. because the user logs off or the system shuts down. It
+// must be called from the main goroutine.
+func (app *Application) SessionEnding() *GenericEvent[*SessionEndQuery] {
+	app.ensureSessionWindow()
+	return app.sessionEndingPublisher.Event()
+}
+
+// SessionEnded returns the event that is published when the user session
+// ends. The process may be terminated any time after the handlers returned,
+// so they should save any state right away. It must be called from the main
+// goroutine.
+func (app *Application) SessionEnded() *GenericEvent[SessionEndReason] {
+	app.ensureSessionWindow()
+	return app.sessionEndedPublisher.Event()
+}
+
+// Suspending returns the event that is published when the system is about to
+// suspend. Handlers have about two seconds to pause their work. It must be
+// called from the main goroutine.
+func (app *Application) Suspending() *Event {
+	app.ensureSessionWindow()
+	return app.suspendingPublisher.Event()
+}
+
+// Resumed returns the event that is published when the system resumed from
+// suspension. It must be called from the main goroutine.
+func (app *Application) Resumed() *Event {
+	app.ensureSessionWindow()
+	return app.resumedPublisher.Event()
+}
+
+// PowerSource returns the source the system is currently powered by. It must
+// be called from the main goroutine.
+func (app *Application) PowerSource() PowerSource {
+	app.ensureSessionWindow()
+	return app.powerSource
+}
+
+// PowerSourceChanged returns the event that is published when the system
+// switches between AC and battery power. It must be called from the main
+// goroutine.
+func (app *Application) PowerSourceChanged() *GenericEvent[PowerSource] {
+	app.ensureSessionWindow()
+	return app.powerSourceChangedPublisher.Event()
+}
+
+// BatteryPercentage returns the remaining capacity of the battery in percent,
+// or -1 if the system has no battery or its capacity is unknown. It must be
+// called from the main goroutine.
+func (app *Application) BatteryPercentage() int {
+	app.ensureSessionWindow()
+	return app.batteryPercentage
+}
+
+// BatteryPercentageChanged returns the event that is published when the
+// remaining capacity of the battery changes. It must be called from the main
+// goroutine.
+func (app *Application) BatteryPercentageChanged() *GenericEvent[int] {
+	app.ensureSessionWindow()
+	return app.batteryPercentageChangedPublisher.Event()
+}
+
+// BlockShutdown asks Windows to keep the user session from ending, showing
+// reason to the user, e.g. while unsaved work exists. The session is only
+// kept if a SessionEnding handler sets Block. It must be called from the main
+// goroutine.
+func (app *Application) BlockShutdown(reason string) error {
+	app.ensureSessionWindow()
+
+	reason16, err := syscall.UTF16PtrFromString(reason)
+	if err != nil {
+		return wrapError(err)
+	}
+
+	if !shutdownBlockReasonCreate(app.sessionWindow, reason16) {
+		return lastError("ShutdownBlockReasonCreate")
+	}
+
+	return nil
+}
+
+// UnblockShutdown reverts BlockShutdown. It must be called from the main
+// goroutine.
+func (app *Application) UnblockShutdown() error {
+	app.AssertUIThread()
+
+	if app.sessionWindow == 0 {
+		return nil
+	}
+
+	if !shutdownBlockReasonDestroy(app.sessionWindow) {
+		return lastError("ShutdownBlockReasonDestroy")
+	}
+
+	return nil
+}
+
+// ensureSessionWindow creates the window that receives session, power and
+// display notifications. Unlike the message window of the application, it is a
+// hidden top-level window, as the notifications are only broadcast to those.
+func (app *Application) ensureSessionWindow() {
+	app.AssertUIThread()
+
+	if app.sessionWindow != 0 {
+		return
+	}
+
+	MustRegisterWindowClassWithWndProcPtr(sessionWindowClassName, windows.NewCallback(sessionWndProc))
+
+	className16, err := windows.UTF16PtrFromString(sessionWindowClassName)
+	if err != nil {
+		panic(err)
+	}
+
+	app.sessionWindow = win.CreateWindowEx(
+		0,
+		className16,
+		nil,
+		win.WS_POPUP,
+		0,
+		0,
+		0,
+		0,
+		0,
+		0,
+		0,
+		nil)
+	if app.sessionWindow == 0 {
+		panic(lastError("CreateWindowEx"))
+	}
+
+	app.powerSource = PowerSourceAC
+	app.batteryPercentage = -1
+
+	var sps systemPowerStatus
+	if getSystemPowerStatus(&sps) {
+		if sps.acLineStatus == 0 {
+			app.powerSource = PowerSourceBattery
+		}
+		if sps.batteryLifePercent <= 100 {
+			app.batteryPercentage = int(sps.batteryLifePercent)
+		}
+	}
+
+	// Both notifications are also sent with the current value right away,
+	// which is only published if it differs from the one above.
+	registerPowerSettingNotification(app.sessionWindow, &guid_ACDC_POWER_SOURCE)
+	registerPowerSettingNotification(app.sessionWindow, &guid_BATTERY_PERCENTAGE_REMAINING)
+}
+
+func sessionWndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) uintptr {
+	defer appSingleton.maybePublishPanic()
+
+	app := &appSingleton
+
+	switch msg {
+	case win.WM_QUERYENDSESSION:
+		query := &SessionEndQuery{Reason: SessionEndReason(lParam)}
+		app.sessionEndingPublisher.Publish(query)
+
+		if query.Block {
+			return 0
+		}
+		return 1
+
+	case win.WM_ENDSESSION:
+		if wParam != 0 {
+			app.sessionEndedPublisher.Publish(SessionEndReason(lParam))
+		}
+		return 0
+
+	case win.WM_POWERBROADCAST:
+		switch wParam {
+		case _PBT_APMSUSPEND:
+			app.suspendingPublisher.Publish()
+
+		case _PBT_APMRESUMEAUTOMATIC:
+			app.resumedPublisher.Publish()
+
+		case _PBT_POWERSETTINGCHANGE:
+			pbs := (*powerBroadcastSetting)(unsafe.Pointer(lParam))
+			if pbs.dataLength < 4 {
+				break
+			}
+			value := *(*uint32)(unsafe.Pointer(&pbs.data[0]))
+
+			switch pbs.powerSetting {
+			case guid_ACDC_POWER_SOURCE:
+				if source := PowerSource(value); source != app.powerSource {
+					app.powerSource = source
+					app.powerSourceChangedPublisher.Publish(source)
+				}
+
+			case guid_BATTERY_PERCENTAGE_REMAINING:
+				if percentage := int(value); percentage != app.batteryPercentage {
+					app.batteryPercentage = percentage
+					app.batteryPercentageChangedPublisher.Publish(percentage)
+				}
+			}
+		}
+		return 1
+
+	case win.WM_DISPLAYCHANGE:
+		app.scheduleDisplayChanged()
+
+	case win.WM_SETTINGCHANGE:
+		if wParam == _SPI_SETWORKAREA {
+			app.scheduleDisplayChanged()
+		}
+
+	case win.WM_DEVICECHANGE:
+		if wParam == _DBT_DEVNODES_CHANGED {
+			app.scheduleDisplayChanged()
+		}
+
+	case win.WM_TIMER:
+		if wParam == displayChangedTimerId {
+			app.checkDisplayChanged()
+			return 0
+		}
+	}
+
+	return win.DefWindowProc(hwnd, msg, wParam, lParam)
+}
@@ -0,0 +1,206 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"image"
+	"math"
+	"unsafe"
+
+	"github.com/tailscale/win"
+	"golang.org/x/sys/windows"
+)
+
+const popupShadowWindowClass = `\o/ Walk_PopupShadow_Class \o/`
+
+func init() {
+	AppendToWalkInit(func() {
+		MustRegisterWindowClass(popupShadowWindowClass)
+	})
+}
+
+// EnableDWMShadow asks the desktop window manager to draw its standard drop
+// shadow around window, which is typically a borderless popup. This is the
+// cheapest way to get a native looking shadow, but its shape and extent can
+// not be customized. Use PopupShadow for that.
+func EnableDWMShadow(window Window) error {
+	hwnd := window.Handle()
+
+	policy := uint32(_DWMNCRP_ENABLED)
+	if err := windows.DwmSetWindowAttribute(windows.HWND(hwnd), windows.DWMWA_NCRENDERING_POLICY, unsafe.Pointer(&policy), uint32(unsafe.Sizeof(policy))); err != nil {
+		return wrapError(err)
+	}
+
+	// DWM only draws the shadow if the frame extends into the client area.
+	margins := win.MARGINS{LeftWidth: 1, RightWidth: 1, TopHeight: 1, BottomHeight: 1}
+	if hr := dwmExtendFrameIntoClientArea(hwnd, &margins); win.FAILED(hr) {
+		return errorFromHRESULT("DwmExtendFrameIntoClientArea", hr)
+	}
+
+	return nil
+}
+
+// PopupShadowOptions describes the appearance of a PopupShadow. All distances
+// are specified in 1/96" units.
+type PopupShadowOptions struct {
+	// CornerRadius is the corner radius of the shape casting the shadow.
+	CornerRadius int
+
+	// BlurRadius is the distance over which the shadow fades out. Defaults
+	// to 8.
+	BlurRadius int
+
+	// Offset moves the shadow relative to the target window.
+	Offset Point
+
+	// Color is the color of the shadow. Defaults to black.
+	Color Color
+
+	// Opacity is the opacity of the shadow's darkest part. Defaults to 0x50.
+	Opacity byte
+}
+
+// PopupShadow is a click-through layered window that renders a soft drop
+// shadow behind a top-level target window, e.g. a custom tooltip, flyout or
+// hover card. It follows the bounds and visibility of its target and is
+// disposed together with it.
+type PopupShadow struct {
+	WindowBase
+	target   Window
+	opts     PopupShadowOptions
+	rendered Size // target size in native pixels the shadow was rendered for
+}
+
+// NewPopupShadow creates a PopupShadow for target.
+func NewPopupShadow(target Window, opts PopupShadowOptions) (*PopupShadow, error) {
+	if target == nil {
+		return nil, newError("target cannot be nil")
+	}
+
+	if opts.BlurRadius == 0 {
+		opts.BlurRadius = 8
+	}
+	if opts.Opacity == 0 {
+		opts.Opacity = 0x50
+	}
+
+	ps := &PopupShadow{target: target, opts: opts}
+
+	if err := InitWindow(
+		ps,
+		nil,
+		popupShadowWindowClass,
+		win.WS_POPUP,
+		win.WS_EX_LAYERED|win.WS_EX_TRANSPARENT|win.WS_EX_NOACTIVATE|win.WS_EX_TOOLWINDOW); err != nil {
+		return nil, err
+	}
+
+	tb := target.AsWindowBase()
+	tb.BoundsChanged().Attach(ps.update)
+	tb.VisibleChanged().Attach(ps.update)
+	tb.AddDisposable(ps)
+
+	ps.update()
+
+	return ps, nil
+}
+
+// Options returns the options the shadow is rendered with.
+func (ps *PopupShadow) Options() PopupShadowOptions {
+	return ps.opts
+}
+
+// SetOptions changes the options the shadow is rendered with.
+func (ps *PopupShadow) SetOptions(opts PopupShadowOptions) {
+	ps.opts = opts
+	ps.rendered = Size{}
+
+	ps.update()
+}
+
+func (ps *PopupShadow) update() {
+	if ps.hWnd == 0 {
+		return
+	}
+
+	if !ps.target.Visible() || ps.target.IsDisposed() {
+		win.ShowWindow(ps.hWnd, win.SW_HIDE)
+		return
+	}
+
+	dpi := ps.target.DPI()
+	bounds := ps.target.BoundsPixels()
+	blur := IntFrom96DPI(ps.opts.BlurRadius, dpi)
+	offset := PointFrom96DPI(ps.opts.Offset, dpi)
+
+	location := Point{bounds.X - blur + offset.X, bounds.Y - blur + offset.Y}
+
+	if size := bounds.Size(); size != ps.rendered {
+		im := renderShadow(size, IntFrom96DPI(ps.opts.CornerRadius, dpi), blur, ps.opts.Color, ps.opts.Opacity)
+		if err := updateLayeredWindowWithImage(ps.hWnd, location, im, 0xff); err != nil {
+			return
+		}
+		ps.rendered = size
+	}
+
+	// Keep the shadow directly beneath its target.
+	win.SetWindowPos(
+		ps.hWnd,
+		ps.target.Handle(),
+		int32(location.X),
+		int32(location.Y),
+		0,
+		0,
+		win.SWP_NOSIZE|win.SWP_NOACTIVATE|win.SWP_SHOWWINDOW)
+}
+
+func (ps *PopupShadow) WndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) uintptr {
+	switch msg {
+	case win.WM_NCHITTEST:
+		hit := int32(win.HTTRANSPARENT)
+		return uintptr(hit)
+
+	case win.WM_MOUSEACTIVATE:
+		return _MA_NOACTIVATE
+	}
+
+	return ps.WindowBase.WndProc(hwnd, msg, wParam, lParam)
+}
+
+// renderShadow renders the shadow of a rounded rectangle of the given size
+// and corner radius, blurred over blur pixels. The shadow is centered in the
+// resulting image, which is larger than size by blur on each side.
+func renderShadow(size Size, radius, blur int, color Color, opacity byte) *image.RGBA {
+	w, h := size.Width+2*blur, size.Height+2*blur
+	im := image.NewRGBA(image.Rect(0, 0, w, h))
+
+	halfW, halfH := float64(size.Width)/2, float64(size.Height)/2
+	r := math.Min(float64(radius), math.Min(halfW, halfH))
+	sigma := math.Max(float64(blur)/2, 0.5)
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			// Signed distance from the pixel center to the rounded rectangle.
+			px := math.Abs(float64(x)+0.5-float64(w)/2) - (halfW - r)
+			py := math.Abs(float64(y)+0.5-float64(h)/2) - (halfH - r)
+			d := math.Hypot(math.Max(px, 0), math.Max(py, 0)) + math.Min(math.Max(px, py), 0) - r
+
+			// Approximate a gaussian blur of the shape's edge.
+			coverage := 0.5 * math.Erfc(d/(sigma*math.Sqrt2))
+
+			a := coverage * float64(opacity) / 255
+			i := im.PixOffset(x, y)
+			im.Pix[i+0] = byte(float64(color.R())*a + 0.5)
+			im.Pix[i+1] = byte(float64(color.G())*a + 0.5)
+			im.Pix[i+2] = byte(float64(color.B())*a + 0.5)
+			im.Pix[i+3] = byte(255*a + 0.5)
+		}
+	}
+
+	return im
+}
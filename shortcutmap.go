@@ -0,0 +1,223 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"fmt"
+
+	"github.com/tailscale/win"
+)
+
+// ShortcutBinding binds a Shortcut to a handler in a ShortcutMap.
+type ShortcutBinding struct {
+	shortcut         Shortcut
+	description      string
+	widget           Widget
+	handler          func()
+	enabledCondition Condition
+}
+
+// Shortcut returns the Shortcut of the ShortcutBinding.
+func (sb *ShortcutBinding) Shortcut() Shortcut {
+	return sb.shortcut
+}
+
+// Description returns the text describing what the ShortcutBinding does, e.g.
+// to be listed in a help window.
+func (sb *ShortcutBinding) Description() string {
+	return sb.description
+}
+
+// Widget returns the Widget the ShortcutBinding is scoped to, or nil if it
+// applies to the whole Form.
+func (sb *ShortcutBinding) Widget() Widget {
+	return sb.widget
+}
+
+// EnabledCondition returns the Condition that decides whether the
+// ShortcutBinding is enabled, or nil if it is always enabled.
+func (sb *ShortcutBinding) EnabledCondition() Condition {
+	return sb.enabledCondition
+}
+
+// SetEnabledCondition sets the Condition that decides whether the
+// ShortcutBinding is enabled. A nil Condition means always enabled.
+func (sb *ShortcutBinding) SetEnabledCondition(c Condition) {
+	sb.enabledCondition = c
+}
+
+// Enabled returns whether pressing the Shortcut calls the handler of the
+// ShortcutBinding.
+func (sb *ShortcutBinding) Enabled() bool {
+	if sb.enabledCondition == nil {
+		return true
+	}
+
+	return sb.enabledCondition.Satisfied()
+}
+
+// ShortcutMap maps the Shortcuts of a Form to handlers.
+//
+// Bindings are either scoped to the whole Form or to a Widget, in which case
+// they only apply while the Widget or one of its descendants has the keyboard
+// focus. Widget bindings take precedence over Form bindings, which in turn take
+// precedence over the shortcut actions of the focused windows.
+type ShortcutMap struct {
+	form     *FormBase
+	bindings []*ShortcutBinding
+}
+
+// Shortcuts returns the ShortcutMap of the *FormBase.
+func (fb *FormBase) Shortcuts() *ShortcutMap {
+	if fb.shortcuts == nil {
+		fb.shortcuts = &ShortcutMap{form: fb}
+	}
+
+	return fb.shortcuts
+}
+
+// Add binds shortcut to handler for the whole Form. It returns an error if
+// shortcut is bound already for the whole Form, also by a shortcut action of
+// the Form.
+func (sm *ShortcutMap) Add(shortcut Shortcut, description string, handler func()) (*ShortcutBinding, error) {
+	return sm.add(nil, shortcut, description, handler)
+}
+
+// AddForWidget binds shortcut to handler while widget or one of its
+// descendants has the keyboard focus. It returns an error if shortcut is bound
+// already for widget.
+func (sm *ShortcutMap) AddForWidget(widget Widget, shortcut Shortcut, description string, handler func()) (*ShortcutBinding, error) {
+	if widget == nil {
+		return nil, newError("widget cannot be nil")
+	}
+
+	return sm.add(widget, shortcut, description, handler)
+}
+
+func (sm *ShortcutMap) add(widget Widget, shortcut Shortcut, description string, handler func()) (*ShortcutBinding, error) {
+	if shortcut.Key == 0 {
+		return nil, newError("shortcut has no key")
+	}
+
+	if handler == nil {
+		return nil, newError("handler cannot be nil")
+	}
+
+	if conflict := sm.Conflict(widget, shortcut); conflict != "" {
+		return nil, newError(fmt.Sprintf("shortcut %s conflicts with %s", shortcut, conflict))
+	}
+
+	sb := &ShortcutBinding{
+		shortcut:    shortcut,
+		description: description,
+		widget:      widget,
+		handler:     handler,
+	}
+
+	sm.bindings = append(sm.bindings, sb)
+
+	return sb, nil
+}
+
+// Conflict returns a description of what shortcut is bound to already in the
+// scope of widget, which is nil for the whole Form, or an empty string if it
+// is free.
+func (sm *ShortcutMap) Conflict(widget Widget, shortcut Shortcut) string {
+	for _, sb := range sm.bindings {
+		if sb.widget == widget && sb.shortcut == shortcut {
+			if sb.description != "" {
+				return fmt.Sprintf("%q", sb.description)
+			}
+			return "another binding"
+		}
+	}
+
+	var actions *ActionList
+	if widget == nil {
+		actions = sm.form.shortcutActions
+	} else {
+		actions = widget.AsWindowBase().shortcutActions
+	}
+
+	if actions != nil {
+		for _, action := range actions.actions {
+			if action.shortcut == shortcut {
+				return fmt.Sprintf("action %q", action.Text())
+			}
+		}
+	}
+
+	return ""
+}
+
+// Remove removes sb from the ShortcutMap.
+func (sm *ShortcutMap) Remove(sb *ShortcutBinding) {
+	for i, b := range sm.bindings {
+		if b == sb {
+			sm.bindings = append(sm.bindings[:i], sm.bindings[i+1:]...)
+			return
+		}
+	}
+}
+
+// Clear removes all bindings from the ShortcutMap.
+func (sm *ShortcutMap) Clear() {
+	sm.bindings = nil
+}
+
+// Bindings returns the bindings of the ShortcutMap in the order they were
+// added, e.g. to list the available shortcuts to the user.
+func (sm *ShortcutMap) Bindings() []*ShortcutBinding {
+	return append([]*ShortcutBinding(nil), sm.bindings...)
+}
+
+// handleShortcut calls the handler of the enabled binding for shortcut that
+// is closest to hwnd, the window with the keyboard focus, and returns whether
+// there was one.
+func (sm *ShortcutMap) handleShortcut(hwnd win.HWND, shortcut Shortcut) bool {
+	if len(sm.bindings) == 0 {
+		return false
+	}
+
+	// The focus may be on a native child of a widget, like the edit control
+	// of a ComboBox.
+	var window Window
+	for ; hwnd != 0 && window == nil; hwnd = win.GetParent(hwnd) {
+		window = windowFromHandle(hwnd)
+	}
+
+	for window != nil {
+		widget, ok := window.(Widget)
+		if !ok {
+			break
+		}
+
+		if sm.trigger(widget, shortcut) {
+			return true
+		}
+
+		if parent := widget.Parent(); parent != nil {
+			window = parent
+		} else {
+			break
+		}
+	}
+
+	return sm.trigger(nil, shortcut)
+}
+
+func (sm *ShortcutMap) trigger(widget Widget, shortcut Shortcut) bool {
+	for _, sb := range sm.bindings {
+		if sb.widget == widget && sb.shortcut == shortcut && sb.Enabled() {
+			sb.handler()
+			return true
+		}
+	}
+
+	return false
+}
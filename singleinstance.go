@@ -0,0 +1,237 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"time"
+	"unsafe"
+
+	"github.com/tailscale/win"
+	"golang.org/x/sys/windows"
+)
+
+const (
+	singleInstanceWindowClassName = "Walk Single Instance Window"
+
+	// singleInstanceCopyDataID identifies the WM_COPYDATA messages that carry
+	// the command line arguments of another instance.
+	singleInstanceCopyDataID = 0x574C4B49 // "WLKI"
+)
+
+// ErrAlreadyRunning is returned by Application.SetSingleInstance if another
+// instance of the application is running already.
+var ErrAlreadyRunning = errors.New("another instance of the application is already running")
+
+var singleInstanceWindowClassRegistered bool
+
+// SetSingleInstance makes sure only one instance of the application with the
+// given id runs per user session. The id should be unique to the application,
+// e.g. its product name prefixed by the organization name.
+//
+// If no other instance is running, the calling process becomes the instance
+// that later starts are handed off to. Otherwise the command line arguments of
+// the calling process are forwarded to the running instance, which activates
+// the Form that was active most recently and publishes InstanceStarted, and
+// ErrAlreadyRunning is returned, upon which the application should exit.
+//
+// SetSingleInstance should be called right after InitApp, before creating any
+// windows. It must be called from the main goroutine.
+func (app *Application) SetSingleInstance(id string) error {
+	app.AssertUIThread()
+
+	if app.singleInstanceMutex != 0 {
+		return newError("single instance mode is enabled already")
+	}
+
+	if id == "" {
+		return newError("id cannot be empty")
+	}
+
+	// Backslashes are not allowed in the names of kernel objects.
+	name := "Walk Single Instance " + strings.ReplaceAll(id, `\`, "/")
+
+	mutexName16, err := windows.UTF16PtrFromString(`Local\` + name)
+	if err != nil {
+		return wrapError(err)
+	}
+
+	mutex, err := windows.CreateMutex(nil, false, mutexName16)
+	if err == windows.ERROR_ALREADY_EXISTS {
+		windows.CloseHandle(mutex)
+
+		forwardToRunningInstance(name)
+
+		return ErrAlreadyRunning
+	}
+	if err != nil {
+		return wrapError(err)
+	}
+
+	if !singleInstanceWindowClassRegistered {
+		MustRegisterWindowClassWithWndProcPtr(singleInstanceWindowClassName, windows.NewCallback(singleInstanceWndProc))
+		singleInstanceWindowClassRegistered = true
+	}
+
+	className16, err := windows.UTF16PtrFromString(singleInstanceWindowClassName)
+	if err != nil {
+		windows.CloseHandle(mutex)
+		return wrapError(err)
+	}
+
+	windowName16, err := windows.UTF16PtrFromString(name)
+	if err != nil {
+		windows.CloseHandle(mutex)
+		return wrapError(err)
+	}
+
+	hwnd := win.CreateWindowEx(
+		0,
+		className16,
+		windowName16,
+		0,
+		win.CW_USEDEFAULT,
+		win.CW_USEDEFAULT,
+		win.CW_USEDEFAULT,
+		win.CW_USEDEFAULT,
+		win.HWND_MESSAGE,
+		0,
+		0,
+		nil)
+	if hwnd == 0 {
+		windows.CloseHandle(mutex)
+		return lastError("CreateWindowEx")
+	}
+
+	app.singleInstanceMutex = mutex
+	app.singleInstanceWindow = hwnd
+
+	return nil
+}
+
+// InstanceStarted returns the event that is published with the command line
+// arguments, without the program name, of another instance that was started
+// while single instance mode is enabled. It must be called from the main
+// goroutine.
+func (app *Application) InstanceStarted() *GenericEvent[[]string] {
+	app.AssertUIThread()
+	return app.instanceStartedPublisher.Event()
+}
+
+// forwardToRunningInstance hands the command line arguments over to the
+// instance that owns the window named name.
+func forwardToRunningInstance(name string) {
+	className16, err := windows.UTF16PtrFromString(singleInstanceWindowClassName)
+	if err != nil {
+		return
+	}
+
+	windowName16, err := windows.UTF16PtrFromString(name)
+	if err != nil {
+		return
+	}
+
+	// The running instance creates its window right after the mutex, so it
+	// may not exist yet if both were started at about the same time.
+	var hwnd win.HWND
+	for i := 0; i < 50; i++ {
+		if hwnd = findWindowEx(win.HWND_MESSAGE, 0, className16, windowName16); hwnd != 0 {
+			break
+		}
+
+		time.Sleep(100 * time.Millisecond)
+	}
+	if hwnd == 0 {
+		return
+	}
+
+	// Only the foreground process may pass on the right to activate windows.
+	var processID uint32
+	win.GetWindowThreadProcessId(hwnd, &processID)
+	allowSetForegroundWindow(processID)
+
+	var data []uint16
+	for _, arg := range os.Args[1:] {
+		arg16, err := windows.UTF16FromString(arg)
+		if err != nil {
+			continue
+		}
+
+		data = append(data, arg16...)
+	}
+
+	cds := copyDataStruct{
+		dwData: singleInstanceCopyDataID,
+		cbData: uint32(len(data) * 2),
+	}
+	if len(data) > 0 {
+		cds.lpData = unsafe.Pointer(&data[0])
+	}
+
+	var result uintptr
+	sendMessageTimeout(hwnd, win.WM_COPYDATA, 0, uintptr(unsafe.Pointer(&cds)), _SMTO_ABORTIFHUNG, 5000, &result)
+}
+
+func singleInstanceWndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) uintptr {
+	defer appSingleton.maybePublishPanic()
+
+	if msg != win.WM_COPYDATA {
+		return win.DefWindowProc(hwnd, msg, wParam, lParam)
+	}
+
+	cds := (*copyDataStruct)(unsafe.Pointer(lParam))
+	if cds.dwData != singleInstanceCopyDataID {
+		return 0
+	}
+
+	// The data is only valid while the message is handled. Each argument is
+	// terminated by a null character.
+	args := []string{}
+	if cds.cbData > 0 {
+		data := unsafe.Slice((*uint16)(cds.lpData), cds.cbData/2)
+		for len(data) > 0 {
+			n := 0
+			for n < len(data) && data[n] != 0 {
+				n++
+			}
+
+			args = append(args, windows.UTF16ToString(data[:n]))
+
+			if n == len(data) {
+				break
+			}
+			data = data[n+1:]
+		}
+	}
+
+	// Let the other instance exit before doing anything that might take long.
+	appSingleton.Synchronize(func() {
+		if fb := lastActiveForm; fb != nil {
+			activateForeground(fb)
+		}
+
+		appSingleton.instanceStartedPublisher.Publish(args)
+	})
+
+	return 1
+}
+
+// activateForeground shows fb if needed and brings it to the foreground.
+func activateForeground(fb *FormBase) {
+	if !fb.Visible() {
+		fb.Show()
+	}
+
+	if win.IsIconic(fb.hWnd) {
+		win.ShowWindow(fb.hWnd, win.SW_RESTORE)
+	}
+
+	win.SetForegroundWindow(fb.hWnd)
+}
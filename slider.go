@@ -7,21 +7,38 @@
 package walk
 
 import (
+	"math"
 	"strconv"
+	"unsafe"
 
 	"github.com/tailscale/win"
 )
 
+const (
+	sliderThumbLength96dpi  = 20
+	sliderTickLabelGap96dpi = 2
+	sliderToolTipGap96dpi   = 4
+)
+
 type Slider struct {
 	WidgetBase
 	valueChangedPublisher EventPublisher
 	layoutFlags           LayoutFlags
 	tracking              bool
 	persistent            bool
+	tickFrequency         int
+	tickLabelsVisible     bool
+	snapToTicks           bool
+	valueFormatter        func(value int) string
+	valueToolTip          sliderValueToolTip
+	valueToolTipHidden    bool
 }
 
 type SliderCfg struct {
-	Orientation    Orientation
+	Orientation Orientation
+
+	// ToolTipsHidden hides the tooltip displaying the value while the thumb
+	// is dragged.
 	ToolTipsHidden bool
 }
 
@@ -34,7 +51,7 @@ func NewSliderWithOrientation(parent Container, orientation Orientation) (*Slide
 }
 
 func NewSliderWithCfg(parent Container, cfg *SliderCfg) (*Slider, error) {
-	sl := new(Slider)
+	sl := &Slider{valueToolTipHidden: cfg.ToolTipsHidden}
 
 	var style uint32 = win.WS_TABSTOP | win.WS_VISIBLE
 	if cfg.Orientation == Vertical {
@@ -43,9 +60,6 @@ func NewSliderWithCfg(parent Container, cfg *SliderCfg) (*Slider, error) {
 	} else {
 		sl.layoutFlags = ShrinkableHorz | GrowableHorz
 	}
-	if !cfg.ToolTipsHidden {
-		style |= win.TBS_TOOLTIPS
-	}
 
 	if err := InitWidget(
 		sl,
@@ -85,6 +99,10 @@ func (sl *Slider) MaxValue() int {
 func (sl *Slider) SetRange(min, max int) {
 	sl.SendMessage(win.TBM_SETRANGEMIN, 0, uintptr(min))
 	sl.SendMessage(win.TBM_SETRANGEMAX, 1, uintptr(max))
+
+	if sl.tickLabelsVisible {
+		sl.RequestLayout()
+	}
 }
 
 func (sl *Slider) Value() int {
@@ -145,6 +163,219 @@ func (sl *Slider) SetPageSize(pageSize int) {
 	sl.SendMessage(win.TBM_SETPAGESIZE, 0, uintptr(pageSize))
 }
 
+// TickFrequency returns the distance of the values tick marks are displayed
+// at, or 0 if there are tick marks at the ends only.
+func (sl *Slider) TickFrequency() int {
+	return sl.tickFrequency
+}
+
+// SetTickFrequency sets the distance of the values tick marks are displayed
+// at, starting at MinValue. 0 displays tick marks at the ends only.
+func (sl *Slider) SetTickFrequency(frequency int) error {
+	if frequency < 0 {
+		return newError("frequency must not be negative")
+	}
+
+	if err := sl.ensureStyleBits(_TBS_AUTOTICKS, frequency > 0); err != nil {
+		return err
+	}
+
+	sl.tickFrequency = frequency
+
+	if frequency > 0 {
+		sl.SendMessage(_TBM_SETTICFREQ, uintptr(frequency), 0)
+	} else {
+		sl.SendMessage(_TBM_CLEARTICS, 1, 0)
+	}
+
+	if sl.tickLabelsVisible {
+		sl.RequestLayout()
+	}
+
+	return nil
+}
+
+// TickLabelsVisible returns whether the tick marks are labeled with their
+// values.
+func (sl *Slider) TickLabelsVisible() bool {
+	return sl.tickLabelsVisible
+}
+
+// SetTickLabelsVisible sets whether the tick marks are labeled with their
+// values, as formatted by the ValueFormatter.
+func (sl *Slider) SetTickLabelsVisible(visible bool) error {
+	if visible == sl.tickLabelsVisible {
+		return nil
+	}
+
+	// The thumb would grow into the labels otherwise.
+	if err := sl.ensureStyleBits(_TBS_FIXEDLENGTH, visible); err != nil {
+		return err
+	}
+
+	sl.tickLabelsVisible = visible
+
+	sl.updateThumbLength()
+
+	sl.Invalidate()
+	sl.RequestLayout()
+
+	return nil
+}
+
+func (sl *Slider) updateThumbLength() {
+	if sl.tickLabelsVisible {
+		sl.SendMessage(_TBM_SETTHUMBLENGTH, uintptr(sl.IntFrom96DPI(sliderThumbLength96dpi)), 0)
+	}
+}
+
+// SnapToTicks returns whether the value snaps to the closest tick mark.
+func (sl *Slider) SnapToTicks() bool {
+	return sl.snapToTicks
+}
+
+// SetSnapToTicks sets whether the value snaps to the closest tick mark when
+// the user moves the thumb.
+func (sl *Slider) SetSnapToTicks(snap bool) {
+	sl.snapToTicks = snap
+}
+
+// ValueFormatter returns the function formatting the values of the tick labels
+// and the value tooltip, or nil if they are formatted as decimal numbers.
+func (sl *Slider) ValueFormatter() func(value int) string {
+	return sl.valueFormatter
+}
+
+// SetValueFormatter sets the function formatting the values of the tick labels
+// and the value tooltip. nil formats them as decimal numbers.
+func (sl *Slider) SetValueFormatter(formatter func(value int) string) {
+	sl.valueFormatter = formatter
+
+	if sl.tickLabelsVisible {
+		sl.Invalidate()
+		sl.RequestLayout()
+	}
+}
+
+func (sl *Slider) formatValue(value int) string {
+	if sl.valueFormatter != nil {
+		return sl.valueFormatter(value)
+	}
+
+	return strconv.Itoa(value)
+}
+
+func (sl *Slider) vertical() bool {
+	return sl.hasStyleBits(win.TBS_VERT)
+}
+
+// valueToPixel returns the position of the center of the thumb at value, along
+// the channel, in native pixels.
+func (sl *Slider) valueToPixel(value int) int {
+	var channel, thumb win.RECT
+	sl.SendMessage(_TBM_GETCHANNELRECT, 0, uintptr(unsafe.Pointer(&channel)))
+	sl.SendMessage(_TBM_GETTHUMBRECT, 0, uintptr(unsafe.Pointer(&thumb)))
+
+	// The channel rectangle is horizontal also for vertical sliders.
+	start, length, thumbLength := channel.Left, channel.Right-channel.Left, thumb.Right-thumb.Left
+	if sl.vertical() {
+		thumbLength = thumb.Bottom - thumb.Top
+	}
+
+	min, max := sl.MinValue(), sl.MaxValue()
+	if max <= min {
+		return int(start + thumbLength/2)
+	}
+
+	return int(start+thumbLength/2) + int(int64(length-thumbLength)*int64(value-min)/int64(max-min))
+}
+
+func (sl *Slider) drawTickLabels(hdc win.HDC) {
+	canvas, err := newCanvasFromHDC(hdc)
+	if err != nil {
+		return
+	}
+	defer canvas.Dispose()
+
+	var thumb win.RECT
+	sl.SendMessage(_TBM_GETTHUMBRECT, 0, uintptr(unsafe.Pointer(&thumb)))
+
+	color := Color(win.GetSysColor(win.COLOR_BTNTEXT))
+	if !sl.Enabled() {
+		color = Color(win.GetSysColor(win.COLOR_GRAYTEXT))
+	}
+
+	cb := sl.ClientBoundsPixels()
+	gap := sl.IntFrom96DPI(sliderTickLabelGap96dpi)
+	vertical := sl.vertical()
+
+	// Labels overlapping the previous one are left out.
+	last := math.MinInt32
+
+	for _, value := range sliderTicks(sl.MinValue(), sl.MaxValue(), sl.tickFrequency) {
+		text := sl.formatValue(value)
+		size := sl.calculateTextSizeImpl(text)
+		pos := sl.valueToPixel(value)
+
+		var bounds Rectangle
+		if vertical {
+			bounds = Rectangle{int(thumb.Right) + gap, pos - size.Height/2, size.Width, size.Height}
+			if bounds.Y < last {
+				continue
+			}
+			last = bounds.Y + size.Height
+		} else {
+			bounds = Rectangle{pos - size.Width/2, int(thumb.Bottom) + gap, size.Width, size.Height}
+			bounds.X = maxi(cb.X, mini(bounds.X, cb.X+cb.Width-size.Width))
+			if bounds.X < last {
+				continue
+			}
+			last = bounds.X + size.Width + gap
+		}
+
+		canvas.DrawTextPixels(text, sl.Font(), color, bounds, TextLeft|TextSingleLine|TextNoPrefix)
+	}
+}
+
+// tickLabelsSize returns the size the tick labels need next to the channel.
+func (sl *Slider) tickLabelsSize() Size {
+	var size Size
+	for _, value := range sliderTicks(sl.MinValue(), sl.MaxValue(), sl.tickFrequency) {
+		s := sl.calculateTextSizeImpl(sl.formatValue(value))
+		size.Width = maxi(size.Width, s.Width)
+		size.Height = maxi(size.Height, s.Height)
+	}
+
+	gap := sl.IntFrom96DPI(sliderTickLabelGap96dpi)
+	size.Width += gap
+	size.Height += gap
+
+	return size
+}
+
+func (sl *Slider) showValueToolTip() {
+	if sl.valueToolTipHidden {
+		return
+	}
+
+	var thumb win.RECT
+	sl.SendMessage(_TBM_GETTHUMBRECT, 0, uintptr(unsafe.Pointer(&thumb)))
+
+	sl.valueToolTip.show(sl, sl.formatValue(sl.Value()), thumb, sl.vertical())
+}
+
+func (sl *Slider) Dispose() {
+	sl.valueToolTip.dispose()
+
+	sl.WidgetBase.Dispose()
+}
+
+func (sl *Slider) ApplyDPI(dpi int) {
+	sl.WidgetBase.ApplyDPI(dpi)
+
+	sl.updateThumbLength()
+}
+
 func (sl *Slider) Tracking() bool {
 	return sl.tracking
 }
@@ -156,16 +387,43 @@ func (sl *Slider) SetTracking(tracking bool) {
 func (sl *Slider) WndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) uintptr {
 	switch msg {
 	case win.WM_HSCROLL, win.WM_VSCROLL:
-		switch win.LOWORD(uint32(wParam)) {
+		code := win.LOWORD(uint32(wParam))
+
+		if sl.snapToTicks && code != win.TB_ENDTRACK {
+			if value := sl.Value(); value != snapToTick(value, sl.MinValue(), sl.MaxValue(), sl.tickFrequency) {
+				sl.SendMessage(win.TBM_SETPOS, 1, uintptr(snapToTick(value, sl.MinValue(), sl.MaxValue(), sl.tickFrequency)))
+			}
+		}
+
+		switch code {
 		case win.TB_THUMBPOSITION, win.TB_ENDTRACK:
+			sl.valueToolTip.hide(sl)
 			sl.valueChangedPublisher.Publish()
 
 		case win.TB_THUMBTRACK:
+			sl.showValueToolTip()
+
 			if sl.tracking {
 				sl.valueChangedPublisher.Publish()
 			}
 		}
 		return 0
+
+	case win.WM_NOTIFY:
+		nmcd := (*win.NMCUSTOMDRAW)(unsafe.Pointer(lParam))
+		if nmcd.Hdr.HwndFrom != sl.hWnd || nmcd.Hdr.Code != win.NM_CUSTOMDRAW || !sl.tickLabelsVisible {
+			break
+		}
+
+		switch nmcd.DwDrawStage {
+		case win.CDDS_PREPAINT:
+			return win.CDRF_NOTIFYPOSTPAINT
+
+		case win.CDDS_POSTPAINT:
+			sl.drawTickLabels(nmcd.Hdc)
+		}
+
+		return win.CDRF_DODEFAULT
 	}
 	return sl.WidgetBase.WndProc(hwnd, msg, wParam, lParam)
 }
@@ -175,9 +433,19 @@ func (*Slider) NeedsWmSize() bool {
 }
 
 func (sl *Slider) CreateLayoutItem(ctx *LayoutContext) LayoutItem {
+	idealSize := sl.dialogBaseUnitsToPixels(Size{15, 15})
+	if sl.tickLabelsVisible {
+		labels := sl.tickLabelsSize()
+		if sl.vertical() {
+			idealSize.Width += labels.Width
+		} else {
+			idealSize.Height += labels.Height
+		}
+	}
+
 	return &sliderLayoutItem{
 		layoutFlags: sl.layoutFlags,
-		idealSize:   sl.dialogBaseUnitsToPixels(Size{15, 15}),
+		idealSize:   idealSize,
 	}
 }
 
@@ -198,3 +466,103 @@ func (li *sliderLayoutItem) IdealSize() Size {
 func (li *sliderLayoutItem) MinSize() Size {
 	return li.idealSize
 }
+
+// sliderTicks returns the values of the tick marks of a slider from min to
+// max, with a tick mark every frequency values, or at the ends only if
+// frequency is 0.
+func sliderTicks(min, max, frequency int) []int {
+	if max <= min {
+		return []int{min}
+	}
+
+	ticks := []int{min}
+	if frequency > 0 {
+		for value := min + frequency; value < max; value += frequency {
+			ticks = append(ticks, value)
+		}
+	}
+
+	return append(ticks, max)
+}
+
+// snapToTick returns the value of the tick mark closest to value.
+func snapToTick(value, min, max, frequency int) int {
+	if frequency <= 0 || max <= min {
+		return value
+	}
+
+	snapped := min + int(math.Round(float64(value-min)/float64(frequency)))*frequency
+	if snapped > max || max-value < value-snapped {
+		snapped = max
+	}
+
+	return snapped
+}
+
+// sliderValueToolTip displays the value of a slider next to its thumb while
+// the thumb is dragged.
+type sliderValueToolTip struct {
+	toolTip *ToolTip
+}
+
+// show displays text next to thumb, in client coordinates of slider.
+func (vt *sliderValueToolTip) show(slider Widget, text string, thumb win.RECT, vertical bool) {
+	hwnd := slider.Handle()
+
+	if vt.toolTip == nil {
+		tt, err := NewToolTip()
+		if err != nil {
+			return
+		}
+
+		if err := tt.addTrackedTool(slider); err != nil {
+			tt.Dispose()
+			return
+		}
+
+		// The tooltip is positioned exactly, instead of close to the mouse.
+		if ti := tt.toolInfo(hwnd); ti != nil {
+			ti.UFlags |= win.TTF_ABSOLUTE
+			tt.SendMessage(win.TTM_SETTOOLINFO, 0, uintptr(unsafe.Pointer(ti)))
+		}
+
+		vt.toolTip = tt
+	}
+
+	if err := vt.toolTip.setText(hwnd, text); err != nil {
+		return
+	}
+
+	ti := vt.toolTip.toolInfo(hwnd)
+	if ti == nil {
+		return
+	}
+
+	size := uint32(vt.toolTip.SendMessage(win.TTM_GETBUBBLESIZE, 0, uintptr(unsafe.Pointer(ti))))
+	width, height := int32(win.LOWORD(size)), int32(win.HIWORD(size))
+	gap := int32(slider.AsWindowBase().IntFrom96DPI(sliderToolTipGap96dpi))
+
+	var p win.POINT
+	if vertical {
+		p = win.POINT{X: thumb.Right + gap, Y: (thumb.Top+thumb.Bottom)/2 - height/2}
+	} else {
+		p = win.POINT{X: (thumb.Left+thumb.Right)/2 - width/2, Y: thumb.Top - height - gap}
+	}
+	win.ClientToScreen(hwnd, &p)
+
+	vt.toolTip.SendMessage(win.TTM_TRACKPOSITION, 0, uintptr(win.MAKELONG(uint16(p.X), uint16(p.Y))))
+	vt.toolTip.SendMessage(win.TTM_TRACKACTIVATE, 1, uintptr(unsafe.Pointer(ti)))
+}
+
+func (vt *sliderValueToolTip) hide(slider Widget) {
+	if vt.toolTip != nil {
+		vt.toolTip.untrack(slider)
+	}
+}
+
+func (vt *sliderValueToolTip) dispose() {
+	if vt.toolTip != nil {
+		vt.toolTip.Dispose()
+		vt.toolTip = nil
+	}
+}
@@ -14,6 +14,11 @@ func init() {
 	})
 }
 
+// Spacer is an invisible widget that takes up space in a layout.
+//
+// A stretchy Spacer takes up the space left over by the other items of a box
+// layout, e.g. to push the items after it to the far end. A fixed Spacer
+// keeps a constant distance between the items around it.
 type Spacer struct {
 	WidgetBase
 	sizeHint96dpi     Size
@@ -50,18 +55,26 @@ func newSpacer(parent Container, layoutFlags LayoutFlags, sizeHint96dpi Size, gr
 	return s, nil
 }
 
+// NewHSpacer creates a stretchy Spacer that grows horizontally. Greedy items
+// that are not spacers take precedence when distributing space. Set its
+// stretch factor in the layout to weigh it against other stretchy spacers.
 func NewHSpacer(parent Container) (*Spacer, error) {
 	return newSpacer(parent, ShrinkableHorz|ShrinkableVert|GrowableHorz|GreedyHorz, Size{}, false)
 }
 
+// NewHSpacerFixed creates a Spacer with a fixed width in 1/96" units.
 func NewHSpacerFixed(parent Container, width int) (*Spacer, error) {
 	return newSpacer(parent, 0, Size{width, 0}, false)
 }
 
+// NewVSpacer creates a stretchy Spacer that grows vertically. Greedy items
+// that are not spacers take precedence when distributing space. Set its
+// stretch factor in the layout to weigh it against other stretchy spacers.
 func NewVSpacer(parent Container) (*Spacer, error) {
 	return newSpacer(parent, ShrinkableHorz|ShrinkableVert|GrowableVert|GreedyVert, Size{}, false)
 }
 
+// NewVSpacerFixed creates a Spacer with a fixed height in 1/96" units.
 func NewVSpacerFixed(parent Container, height int) (*Spacer, error) {
 	return newSpacer(parent, 0, Size{0, height}, false)
 }
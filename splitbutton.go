@@ -14,9 +14,13 @@ import (
 	"github.com/tailscale/win"
 )
 
+// SplitButton is a push button with an attached drop-down arrow. Clicking the
+// button itself publishes Clicked and triggers the DefaultAction, if any, while
+// clicking the arrow, or pressing Alt+Down or F4, pops up the Menu.
 type SplitButton struct {
 	Button
-	menu *Menu
+	menu          *Menu
+	defaultAction *Action
 }
 
 func NewSplitButton(parent Container) (*SplitButton, error) {
@@ -48,6 +52,12 @@ func NewSplitButton(parent Container) (*SplitButton, error) {
 	sb.GraphicsEffects().Add(InteractionEffect)
 	sb.GraphicsEffects().Add(FocusEffect)
 
+	sb.Clicked().Attach(func() {
+		if sb.defaultAction != nil && sb.defaultAction.Enabled() {
+			sb.defaultAction.raiseTriggered()
+		}
+	})
+
 	disposables.Spare()
 
 	return sb, nil
@@ -57,6 +67,8 @@ func (sb *SplitButton) Dispose() {
 	sb.Button.Dispose()
 
 	sb.menu.Dispose()
+
+	sb.SetDefaultAction(nil)
 }
 
 func (sb *SplitButton) ImageAboveText() bool {
@@ -77,6 +89,82 @@ func (sb *SplitButton) Menu() *Menu {
 	return sb.menu
 }
 
+// DefaultAction returns the Action that is triggered when the primary part of
+// the button is clicked.
+func (sb *SplitButton) DefaultAction() *Action {
+	return sb.defaultAction
+}
+
+// SetDefaultAction sets the Action that is triggered when the primary part of
+// the button is clicked. The text, image and enabled state of the button
+// follow the action.
+func (sb *SplitButton) SetDefaultAction(action *Action) error {
+	if action == sb.defaultAction {
+		return nil
+	}
+
+	if old := sb.defaultAction; old != nil {
+		old.removeChangedHandler(sb)
+		old.release()
+	}
+
+	sb.defaultAction = action
+
+	if action == nil {
+		return nil
+	}
+
+	action.addRef()
+	action.addChangedHandler(sb)
+
+	return sb.onActionChanged(action)
+}
+
+func (sb *SplitButton) onActionChanged(action *Action) error {
+	if err := sb.SetText(action.Text()); err != nil {
+		return err
+	}
+
+	if err := sb.SetImage(action.Image()); err != nil {
+		return err
+	}
+
+	sb.SetEnabled(action.Enabled())
+
+	return nil
+}
+
+func (sb *SplitButton) onActionVisibleChanged(action *Action) error {
+	sb.SetVisible(action.Visible())
+
+	return nil
+}
+
+// ShowMenu pops up the drop-down menu below the button.
+func (sb *SplitButton) ShowMenu() {
+	var rc win.RECT
+	win.GetClientRect(sb.hWnd, &rc)
+
+	sb.showMenuAt(rc)
+}
+
+func (sb *SplitButton) showMenuAt(rc win.RECT) {
+	p := win.POINT{rc.Left, rc.Bottom}
+
+	win.ClientToScreen(sb.hWnd, &p)
+
+	sb.SendMessage(win.BCM_SETDROPDOWNSTATE, win.TRUE, 0)
+	defer sb.SendMessage(win.BCM_SETDROPDOWNSTATE, win.FALSE, 0)
+
+	win.TrackPopupMenuEx(
+		sb.menu.hMenu,
+		win.TPM_NOANIMATION,
+		p.X,
+		p.Y,
+		sb.hWnd,
+		nil)
+}
+
 func (sb *SplitButton) WndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) uintptr {
 	switch msg {
 	case win.WM_NOTIFY:
@@ -84,17 +172,14 @@ func (sb *SplitButton) WndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr
 		case win.BCN_DROPDOWN:
 			dd := (*win.NMBCDROPDOWN)(unsafe.Pointer(lParam))
 
-			p := win.POINT{dd.RcButton.Left, dd.RcButton.Bottom}
-
-			win.ClientToScreen(sb.hWnd, &p)
+			sb.showMenuAt(dd.RcButton)
+			return 0
+		}
 
-			win.TrackPopupMenuEx(
-				sb.menu.hMenu,
-				win.TPM_NOANIMATION,
-				p.X,
-				p.Y,
-				sb.hWnd,
-				nil)
+	case win.WM_KEYDOWN, win.WM_SYSKEYDOWN:
+		key := Key(wParam)
+		if key == KeyF4 && msg == win.WM_KEYDOWN || key == KeyDown && AltDown() {
+			sb.ShowMenu()
 			return 0
 		}
 	}
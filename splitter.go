@@ -18,6 +18,8 @@ import (
 
 const splitterWindowClass = `\o/ Walk_Splitter_Class \o/`
 
+const splitterSnapDistance96dpi = 8
+
 var splitterHandleDraggingBrush *SolidColorBrush
 
 func init() {
@@ -36,6 +38,8 @@ type Splitter struct {
 	draggedHandle *splitterHandle
 	persistent    bool
 	removing      bool
+	proportional  bool
+	snapPoints    []float64
 }
 
 func newSplitter(parent Container, orientation Orientation) (*Splitter, error) {
@@ -203,6 +207,10 @@ func (s *Splitter) SaveState() error {
 		if size == 0 {
 			size = item.size
 		}
+		if item.collapsed {
+			// Negative sizes mark collapsed widgets.
+			size = -maxi(item.expandedSize, 1)
+		}
 		buf.WriteString(strconv.FormatInt(int64(size), 10))
 	}
 
@@ -278,6 +286,10 @@ func (s *Splitter) RestoreState() error {
 			}
 
 			item := layout.hwnd2Item[widget.Handle()]
+			if item.collapsed = size < 0; item.collapsed {
+				item.expandedSize = -size
+				size = 0
+			}
 			item.size = size
 			item.oldExplicitSize = size
 		}
@@ -315,6 +327,184 @@ func (s *Splitter) SetFixed(widget Widget, fixed bool) error {
 	return nil
 }
 
+// Collapsible returns whether the user can collapse and expand widget by
+// clicking the button on the adjacent handle.
+func (s *Splitter) Collapsible(widget Widget) bool {
+	item := s.layout.(*splitterLayout).hwnd2Item[widget.Handle()]
+	return item != nil && item.collapsible
+}
+
+// SetCollapsible sets whether the user can collapse and expand widget by
+// clicking the button on the adjacent handle. Dragging the handle close to the
+// edge also collapses a collapsible widget.
+func (s *Splitter) SetCollapsible(widget Widget, collapsible bool) error {
+	item := s.layout.(*splitterLayout).hwnd2Item[widget.Handle()]
+	if item == nil {
+		return newError("unknown widget")
+	}
+
+	item.collapsible = collapsible
+
+	s.invalidateHandles()
+
+	return nil
+}
+
+// Collapsed returns whether widget is collapsed to zero size.
+func (s *Splitter) Collapsed(widget Widget) bool {
+	item := s.layout.(*splitterLayout).hwnd2Item[widget.Handle()]
+	return item != nil && item.collapsed
+}
+
+// SetCollapsed collapses widget to zero size or expands it to the size it had
+// before. The space is given to or taken from the adjacent widget.
+func (s *Splitter) SetCollapsed(widget Widget, collapsed bool) error {
+	layout := s.layout.(*splitterLayout)
+
+	item := layout.hwnd2Item[widget.Handle()]
+	if item == nil {
+		return newError("unknown widget")
+	}
+	if collapsed == item.collapsed {
+		return nil
+	}
+
+	neighbor := s.neighborItem(s.children.Index(widget))
+
+	if collapsed {
+		item.expandedSize = item.size
+		if neighbor != nil {
+			neighbor.size += item.size
+			neighbor.oldExplicitSize = neighbor.size
+		}
+
+		item.size = 0
+	} else {
+		item.size = item.expandedSize
+		if neighbor != nil {
+			neighbor.size = maxi(neighbor.size-item.size, 0)
+			neighbor.oldExplicitSize = neighbor.size
+		}
+	}
+
+	item.collapsed = collapsed
+	item.oldExplicitSize = item.size
+
+	s.invalidateHandles()
+	s.RequestLayout()
+
+	return nil
+}
+
+// Proportional returns whether the widgets keep their sizes relative to each
+// other when the Splitter is resized.
+func (s *Splitter) Proportional() bool {
+	return s.proportional
+}
+
+// SetProportional sets whether the widgets keep their sizes relative to each
+// other when the Splitter is resized. Otherwise the size difference is
+// distributed according to the stretch factors.
+func (s *Splitter) SetProportional(proportional bool) {
+	s.proportional = proportional
+}
+
+// SnapPoints returns the positions, as fractions of the Splitter size, that
+// handles snap to while being dragged.
+func (s *Splitter) SnapPoints() []float64 {
+	return s.snapPoints
+}
+
+// SetSnapPoints sets the positions, as fractions of the Splitter size, that
+// handles snap to while being dragged, e.g. 0.5 for the middle.
+func (s *Splitter) SetSnapPoints(points []float64) {
+	s.snapPoints = points
+}
+
+// constrainHandlePos returns the position pos in native pixels a handle
+// between prev and next is dragged to, adjusted for snap points, collapsing
+// and the minimum and maximum sizes of prev and next.
+func (s *Splitter) constrainHandlePos(pos int, prev, next Widget) int {
+	bp, bn := prev.BoundsPixels(), next.BoundsPixels()
+	minPrev := minSizeEffective(createLayoutItemForWidget(prev))
+	minNext := minSizeEffective(createLayoutItemForWidget(next))
+	maxPrev, maxNext := prev.MaxSizePixels(), next.MaxSizePixels()
+	extent := s.ClientBoundsPixels().Size()
+
+	start, end := bp.X, bn.X+bn.Width
+	if s.Orientation() == Vertical {
+		start, end = bp.Y, bn.Y+bn.Height
+		minPrev.Width, minNext.Width = minPrev.Height, minNext.Height
+		maxPrev.Width, maxNext.Width = maxPrev.Height, maxNext.Height
+		extent.Width = extent.Height
+	}
+
+	handleWidth := s.IntFrom96DPI(s.handleWidth)
+
+	snapDistance := s.IntFrom96DPI(splitterSnapDistance96dpi)
+	for _, point := range s.snapPoints {
+		p := int(point*float64(extent.Width)) - handleWidth/2
+
+		if pos >= p-snapDistance && pos <= p+snapDistance {
+			pos = p
+			break
+		}
+	}
+
+	low := start + minPrev.Width
+	high := end - minNext.Width - handleWidth
+	if maxPrev.Width > 0 {
+		high = mini(high, start+maxPrev.Width)
+	}
+	if maxNext.Width > 0 {
+		low = maxi(low, end-maxNext.Width-handleWidth)
+	}
+
+	switch {
+	case pos < low:
+		if s.Collapsible(prev) && pos < start+minPrev.Width/2 {
+			return start
+		}
+
+		return low
+
+	case pos > high:
+		if s.Collapsible(next) && pos > end-handleWidth-minNext.Width/2 {
+			return end - handleWidth
+		}
+
+		return high
+	}
+
+	return pos
+}
+
+// neighborItem returns the layout item of the visible, expanded widget
+// closest to the one at index, preferring following ones.
+func (s *Splitter) neighborItem(index int) *splitterLayoutItem {
+	layout := s.layout.(*splitterLayout)
+
+	for _, direction := range []int{2, -2} {
+		for i := index + direction; i >= 0 && i < s.children.Len(); i += direction {
+			wb := s.children.items[i]
+
+			if item := layout.hwnd2Item[wb.hWnd]; item != nil && wb.visible && !item.collapsed {
+				return item
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *Splitter) invalidateHandles() {
+	for _, wb := range s.children.items {
+		if handle, ok := wb.window.(*splitterHandle); ok {
+			handle.Invalidate()
+		}
+	}
+}
+
 func (s *Splitter) WndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) uintptr {
 	switch msg {
 	case win.WM_WINDOWPOSCHANGED:
@@ -406,6 +596,10 @@ func (s *Splitter) onInsertedWidget(index int, widget Widget) (err error) {
 							return
 						}
 
+						if handle.toggleCollapseAt(Point{x, y}) {
+							return
+						}
+
 						s.draggedHandle = handle
 						s.mouseDownPos = Point{x, y}
 						handle.SetBackground(splitterHandleDraggingBrush)
@@ -421,24 +615,14 @@ func (s *Splitter) onInsertedWidget(index int, widget Widget) (err error) {
 
 						prev := closestVisibleWidget(handleIndex, -1)
 						bp := prev.BoundsPixels()
-						msep := minSizeEffective(createLayoutItemForWidget(prev))
 
 						next := closestVisibleWidget(handleIndex, 1)
 						bn := next.BoundsPixels()
-						msen := minSizeEffective(createLayoutItemForWidget(next))
-
-						dpi := s.draggedHandle.DPI()
-						handleWidth := IntFrom96DPI(s.handleWidth, dpi)
 
 						if s.Orientation() == Horizontal {
 							xh := s.draggedHandle.XPixels()
 
-							xnew := xh + x - s.mouseDownPos.X
-							if xnew < bp.X+msep.Width {
-								xnew = bp.X + msep.Width
-							} else if xnew >= bn.X+bn.Width-msen.Width-handleWidth {
-								xnew = bn.X + bn.Width - msen.Width - handleWidth
-							}
+							xnew := s.constrainHandlePos(xh+x-s.mouseDownPos.X, prev, next)
 
 							if e := s.draggedHandle.SetXPixels(xnew); e != nil {
 								return
@@ -446,12 +630,7 @@ func (s *Splitter) onInsertedWidget(index int, widget Widget) (err error) {
 						} else {
 							yh := s.draggedHandle.YPixels()
 
-							ynew := yh + y - s.mouseDownPos.Y
-							if ynew < bp.Y+msep.Height {
-								ynew = bp.Y + msep.Height
-							} else if ynew >= bn.Y+bn.Height-msen.Height-handleWidth {
-								ynew = bn.Y + bn.Height - msen.Height - handleWidth
-							}
+							ynew := s.constrainHandlePos(yh+y-s.mouseDownPos.Y, prev, next)
 
 							if e := s.draggedHandle.SetYPixels(ynew); e != nil {
 								return
@@ -530,12 +709,12 @@ func (s *Splitter) onInsertedWidget(index int, widget Widget) (err error) {
 						layout := s.Layout().(*splitterLayout)
 
 						prevItem := layout.hwnd2Item[prev.Handle()]
-						prevItem.size = sizePrev
-						prevItem.oldExplicitSize = sizePrev
+						prevItem.setDraggedSize(sizePrev)
 
 						nextItem := layout.hwnd2Item[next.Handle()]
-						nextItem.size = sizeNext
-						nextItem.oldExplicitSize = sizeNext
+						nextItem.setDraggedSize(sizeNext)
+
+						s.invalidateHandles()
 					})
 				}
 			}()
@@ -12,6 +12,8 @@ import (
 
 const splitterHandleWindowClass = `\o/ Walk_SplitterHandle_Class \o/`
 
+const splitterCollapseButtonLength96dpi = 24
+
 func init() {
 	AppendToWalkInit(func() {
 		MustRegisterWindowClass(splitterHandleWindowClass)
@@ -59,11 +61,29 @@ func (sh *splitterHandle) WndProc(hwnd win.HWND, msg uint32, wParam, lParam uint
 		if sh.Background() == nullBrushSingleton {
 			var ps win.PAINTSTRUCT
 
-			win.BeginPaint(hwnd, &ps)
+			hdc := win.BeginPaint(hwnd, &ps)
 			defer win.EndPaint(hwnd, &ps)
 
+			if sh.collapseTarget() != nil {
+				if canvas, err := newCanvasFromHDC(hdc); err == nil {
+					defer canvas.Dispose()
+
+					sh.paintCollapseButton(canvas)
+				}
+			}
+
 			return 0
 		}
+
+	case win.WM_SETCURSOR:
+		var pt win.POINT
+		win.GetCursorPos(&pt)
+		win.ScreenToClient(hwnd, &pt)
+
+		if sh.collapseButtonAt(Point{int(pt.X), int(pt.Y)}) {
+			win.SetCursor(CursorArrow().handle())
+			return 1
+		}
 	}
 
 	return sh.WidgetBase.WndProc(hwnd, msg, wParam, lParam)
@@ -114,3 +134,122 @@ func (li *splitterHandleLayoutItem) IdealSize() Size {
 func (li *splitterHandleLayoutItem) MinSize() Size {
 	return li.IdealSize()
 }
+
+func (sh *splitterHandle) splitter() *Splitter {
+	s, _ := sh.Parent().(*Splitter)
+	return s
+}
+
+// collapseTarget returns the widget the collapse button of the handle
+// collapses or expands, or nil if it has none.
+func (sh *splitterHandle) collapseTarget() Widget {
+	s := sh.splitter()
+	if s == nil {
+		return nil
+	}
+
+	index := s.children.Index(sh)
+
+	for _, i := range []int{index - 1, index + 1} {
+		if i < 0 || i >= s.children.Len() {
+			continue
+		}
+
+		if widget := s.children.At(i); s.Collapsible(widget) {
+			return widget
+		}
+	}
+
+	return nil
+}
+
+// collapseButtonBounds returns the bounds of the collapse button in native
+// pixels, centered on the handle.
+func (sh *splitterHandle) collapseButtonBounds() Rectangle {
+	cb := sh.ClientBoundsPixels()
+	length := sh.IntFrom96DPI(splitterCollapseButtonLength96dpi)
+
+	if s := sh.splitter(); s != nil && s.Orientation() == Vertical {
+		return Rectangle{(cb.Width - length) / 2, 0, length, cb.Height}
+	}
+
+	return Rectangle{0, (cb.Height - length) / 2, cb.Width, length}
+}
+
+func (sh *splitterHandle) collapseButtonAt(p Point) bool {
+	if sh.collapseTarget() == nil {
+		return false
+	}
+
+	b := sh.collapseButtonBounds()
+
+	return p.X >= b.X && p.X < b.X+b.Width && p.Y >= b.Y && p.Y < b.Y+b.Height
+}
+
+// toggleCollapseAt collapses or expands the collapse target, if p is on the
+// collapse button, and returns whether it was.
+func (sh *splitterHandle) toggleCollapseAt(p Point) bool {
+	if !sh.collapseButtonAt(p) {
+		return false
+	}
+
+	s := sh.splitter()
+	target := sh.collapseTarget()
+
+	s.SetCollapsed(target, !s.Collapsed(target))
+
+	return true
+}
+
+// paintCollapseButton draws an arrow pointing in the direction clicking the
+// collapse button moves the handle.
+func (sh *splitterHandle) paintCollapseButton(canvas *Canvas) error {
+	s := sh.splitter()
+	target := sh.collapseTarget()
+
+	if bg, wnd := sh.backgroundEffective(); bg != nil {
+		sh.prepareDCForBackground(canvas.hdc, sh.hWnd, wnd)
+
+		if err := canvas.FillRectanglePixels(bg, sh.ClientBoundsPixels()); err != nil {
+			return err
+		}
+	}
+
+	brush, err := NewSystemColorBrush(SysColorBtnShadow)
+	if err != nil {
+		return err
+	}
+	defer brush.Dispose()
+
+	// Towards the target when collapsing, away from it when expanding.
+	towardsPrev := s.children.Index(target) < s.children.Index(sh)
+	if s.Collapsed(target) {
+		towardsPrev = !towardsPrev
+	}
+
+	b := sh.collapseButtonBounds()
+	var points []Point
+	if s.Orientation() == Horizontal {
+		half := mini(b.Width, b.Height/2)
+		top, bottom := b.Y+(b.Height-2*half)/2, b.Y+(b.Height+2*half)/2
+		left, right := b.X+(b.Width-half)/2, b.X+(b.Width+half)/2
+
+		if towardsPrev {
+			points = []Point{{right, top}, {left, top + half}, {right, bottom}}
+		} else {
+			points = []Point{{left, top}, {right, top + half}, {left, bottom}}
+		}
+	} else {
+		half := mini(b.Height, b.Width/2)
+		left, right := b.X+(b.Width-2*half)/2, b.X+(b.Width+2*half)/2
+		top, bottom := b.Y+(b.Height-half)/2, b.Y+(b.Height+half)/2
+
+		if towardsPrev {
+			points = []Point{{left, bottom}, {left + half, top}, {right, bottom}}
+		} else {
+			points = []Point{{left, top}, {left + half, bottom}, {right, top}}
+		}
+	}
+
+	return canvas.FillPolygonPixels(brush, points)
+}
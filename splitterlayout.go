@@ -30,6 +30,25 @@ type splitterLayoutItem struct {
 	fixed                bool
 	keepSize             bool
 	wasVisible           bool
+	collapsible          bool
+	collapsed            bool
+	expandedSize         int // in native pixels, to restore when expanding
+}
+
+// setDraggedSize sets the size the user dragged the widget to. A collapsible
+// widget dragged to zero size becomes collapsed.
+func (sli *splitterLayoutItem) setDraggedSize(size int) {
+	if size == 0 && sli.collapsible {
+		if !sli.collapsed {
+			sli.expandedSize = sli.size
+			sli.collapsed = true
+		}
+	} else {
+		sli.collapsed = false
+	}
+
+	sli.size = size
+	sli.oldExplicitSize = size
 }
 
 func newSplitterLayout(orientation Orientation) *splitterLayout {
@@ -182,6 +201,7 @@ func (l *splitterLayout) CreateLayoutItem(ctx *LayoutContext) ContainerLayoutIte
 		handleWidth96dpi:               splitter.HandleWidth(),
 		anyNonFixed:                    l.anyNonFixed(),
 		resetNeeded:                    l.resetNeeded,
+		proportional:                   splitter.proportional,
 	}
 
 	li.margins96dpi = l.margins96dpi
@@ -197,6 +217,7 @@ type splitterContainerLayoutItem struct {
 	handleWidth96dpi               int
 	anyNonFixed                    bool
 	resetNeeded                    bool
+	proportional                   bool
 }
 
 func (li *splitterContainerLayoutItem) StretchFactor(item LayoutItem) int {
@@ -232,7 +253,9 @@ func (li *splitterContainerLayoutItem) MinSizeForSize(size Size) Size {
 
 		var cur Size
 
-		if sli, ok := li.hwnd2Item[item.Handle()]; ok && li.anyNonFixed && sli.fixed {
+		if sli, ok := li.hwnd2Item[item.Handle()]; ok && sli.collapsed {
+			// Takes no space.
+		} else if ok && li.anyNonFixed && sli.fixed {
 			cur = item.Geometry().Size
 
 			if li.orientation == Horizontal {
@@ -300,6 +323,11 @@ func (li *splitterContainerLayoutItem) PerformLayout() []LayoutResultItem {
 		if i%2 == 0 {
 			slItem := li.hwnd2Item[item.Handle()]
 
+			if slItem.collapsed {
+				slItem.size = 0
+				continue
+			}
+
 			var wi *WidgetItem
 
 			if !anyNonFixed || !slItem.fixed {
@@ -380,6 +408,35 @@ func (li *splitterContainerLayoutItem) PerformLayout() []LayoutResultItem {
 
 	var resultItems []LayoutResultItem
 
+	if li.proportional && totalRegularSize > 0 && totalRegularSize != space1 {
+		// Scale the resizable items, so they keep their sizes relative to
+		// each other.
+		var scalable int
+		for _, wi := range wis {
+			if !wi.item.keepSize {
+				scalable += sizes[wi.index]
+			}
+		}
+
+		if available := space1 - (totalRegularSize - scalable); scalable > 0 && available > 0 {
+			for _, wi := range wis {
+				if wi.item.keepSize {
+					continue
+				}
+
+				size := int(float64(sizes[wi.index]) * float64(available) / float64(scalable))
+				size = maxi(size, wi.min)
+				if wi.max > 0 {
+					size = mini(size, wi.max)
+				}
+
+				totalRegularSize += size - sizes[wi.index]
+				sizes[wi.index] = size
+				wi.item.size = size
+			}
+		}
+	}
+
 	diff := space1 - totalRegularSize
 
 	if diff != 0 && len(sizes) > 1 {
@@ -497,6 +554,9 @@ func (li *splitterContainerLayoutItem) reset() {
 		if i%2 == 1 || !anyVisibleItemInHierarchy(item) {
 			continue
 		}
+		if sli := li.hwnd2Item[item.Handle()]; sli != nil && sli.collapsed {
+			continue
+		}
 
 		min := li.MinSizeEffectiveForChild(item)
 		if li.orientation == Horizontal {
@@ -523,6 +583,8 @@ func (li *splitterContainerLayoutItem) reset() {
 
 		if sli := li.hwnd2Item[item.Handle()]; sli == nil {
 			li.hwnd2Item[item.Handle()] = &splitterLayoutItem{stretchFactor: 1}
+		} else if sli.collapsed {
+			continue
 		}
 
 		stretchTotal += li.StretchFactor(item)
@@ -536,6 +598,10 @@ func (li *splitterContainerLayoutItem) reset() {
 		sli := li.hwnd2Item[item.Handle()]
 		sli.growth = 0
 		sli.keepSize = false
+		if sli.collapsed {
+			sli.size = 0
+			continue
+		}
 		if sli.oldExplicitSize > 0 {
 			sli.size = sli.oldExplicitSize
 		} else {
@@ -59,7 +59,7 @@ func (s *static) init(widget Widget, parent Container, style uint32) error {
 		return newError("creating static failed")
 	}
 
-	if err := App().toolTip().AddTool(s); err != nil {
+	if err := App().ToolTip().AddTool(s); err != nil {
 		return err
 	}
 
@@ -115,6 +115,20 @@ func (s *static) textAlignment1D() Alignment1D {
 	}
 }
 
+// textVAlignment returns the vertical alignment of the text.
+func (s *static) textVAlignment() Alignment1D {
+	switch s.textAlignment {
+	case AlignHNearVCenter, AlignHCenterVCenter, AlignHFarVCenter:
+		return AlignCenter
+
+	case AlignHNearVFar, AlignHCenterVFar, AlignHFarVFar:
+		return AlignFar
+
+	default:
+		return AlignNear
+	}
+}
+
 func (s *static) setTextAlignment1D(alignment Alignment1D) error {
 	var align Alignment2D
 
@@ -286,6 +300,11 @@ func staticWndProc(hwnd win.HWND, msg uint32, wp, lp uintptr) uintptr {
 
 	switch msg {
 	case win.WM_NCHITTEST:
+		if s.passesHitTestToForm(Point{int(win.GET_X_LPARAM(lp)), int(win.GET_Y_LPARAM(lp))}) {
+			hit := int32(win.HTTRANSPARENT)
+			return uintptr(hit)
+		}
+
 		return win.HTCLIENT
 
 	case win.WM_MOUSEMOVE, win.WM_LBUTTONDOWN, win.WM_LBUTTONUP, win.WM_MBUTTONDOWN, win.WM_MBUTTONUP, win.WM_RBUTTONDOWN, win.WM_RBUTTONUP:
@@ -297,7 +316,7 @@ func staticWndProc(hwnd win.HWND, msg uint32, wp, lp uintptr) uintptr {
 			Pt:      win.POINT{int32(win.GET_X_LPARAM(lp)), int32(win.GET_Y_LPARAM(lp))},
 		}
 
-		return App().toolTip().SendMessage(win.TTM_RELAYEVENT, 0, uintptr(unsafe.Pointer(&m)))
+		return App().ToolTip().SendMessage(win.TTM_RELAYEVENT, 0, uintptr(unsafe.Pointer(&m)))
 	}
 
 	return win.CallWindowProc(s.origStaticWndProcPtr, hwnd, msg, wp, lp)
@@ -312,6 +331,7 @@ func (s *static) CreateLayoutItem(ctx *LayoutContext) LayoutItem {
 	}
 
 	idealSize := s.calculateTextSize()
+	textHeight := idealSize.Height
 	if s.hasStyleBits(win.WS_BORDER) {
 		border := s.IntFrom96DPI(1) * 2
 		idealSize.Width += border
@@ -321,6 +341,12 @@ func (s *static) CreateLayoutItem(ctx *LayoutContext) LayoutItem {
 	return &staticLayoutItem{
 		layoutFlags: layoutFlags,
 		idealSize:   idealSize,
+		baseline: staticBaseline{
+			textVAlignment: s.textVAlignment(),
+			textAscent:     s.fontAscent(),
+			borderHeight:   s.SizePixels().Height - s.ClientBoundsPixels().Height,
+		},
+		textHeight: textHeight,
 	}
 }
 
@@ -328,6 +354,35 @@ type staticLayoutItem struct {
 	LayoutItemBase
 	layoutFlags LayoutFlags
 	idealSize   Size // in native pixels
+	baseline    staticBaseline
+	textHeight  int // in native pixels
+}
+
+func (li *staticLayoutItem) Baseline(size Size) int {
+	return li.baseline.forHeight(size.Height, li.textHeight)
+}
+
+// staticBaseline calculates the baseline of the text of a static.
+type staticBaseline struct {
+	textVAlignment Alignment1D
+	textAscent     int // in native pixels
+	borderHeight   int // top and bottom, in native pixels
+}
+
+// forHeight returns the baseline of the first line of a text of textHeight
+// for a static of height. Input and output are in native pixels.
+func (b staticBaseline) forHeight(height, textHeight int) int {
+	y := b.borderHeight / 2
+
+	switch b.textVAlignment {
+	case AlignCenter:
+		y += (height - b.borderHeight - textHeight) / 2
+
+	case AlignFar:
+		y += height - b.borderHeight - textHeight
+	}
+
+	return y + b.textAscent
 }
 
 func (li *staticLayoutItem) LayoutFlags() LayoutFlags {
@@ -44,6 +44,30 @@ func (sb *StatusBar) Items() *StatusBarItemList {
 	return sb.items
 }
 
+// SizeGrip returns whether the StatusBar displays a sizing grip at its right
+// end.
+func (sb *StatusBar) SizeGrip() bool {
+	return sb.hasStyleBits(win.SBARS_SIZEGRIP)
+}
+
+// SetSizeGrip sets whether the StatusBar displays a sizing grip at its right
+// end.
+func (sb *StatusBar) SetSizeGrip(sizeGrip bool) error {
+	if sizeGrip == sb.SizeGrip() {
+		return nil
+	}
+
+	if err := sb.ensureStyleBits(win.SBARS_SIZEGRIP, sizeGrip); err != nil {
+		return err
+	}
+
+	if err := sb.updateParts(); err != nil {
+		return err
+	}
+
+	return sb.Invalidate()
+}
+
 // SetVisible sets whether the StatusBar is visible.
 func (sb *StatusBar) SetVisible(visible bool) {
 	sb.WidgetBase.SetVisible(visible)
@@ -76,12 +100,30 @@ func (sb *StatusBar) update() error {
 func (sb *StatusBar) updateParts() error {
 	items := sb.items.items
 
-	dpi := sb.DPI()
+	widths := make([]int32, len(items))
+	firstRightAligned := -1
+	var total int32
+	for i, item := range items {
+		widths[i] = int32(item.widthPixels())
+		total += widths[i]
+
+		if item.rightAligned && firstRightAligned == -1 {
+			firstRightAligned = i
+		}
+	}
+
+	// The item preceding the first right-aligned one absorbs the remaining
+	// space, which pushes all following items to the right edge.
+	if firstRightAligned > 0 {
+		if gap := int32(sb.ClientBoundsPixels().Width) - total; gap > 0 {
+			widths[firstRightAligned-1] += gap
+		}
+	}
 
 	rightEdges := make([]int32, len(items))
 	var right int32
-	for i, item := range items {
-		right += int32(IntFrom96DPI(item.width, dpi))
+	for i := range items {
+		right += widths[i]
 		rightEdges[i] = right
 	}
 	var rep *int32
@@ -89,8 +131,8 @@ func (sb *StatusBar) updateParts() error {
 		rep = &rightEdges[0]
 	}
 
-	if len(rightEdges) == 1 {
-		rightEdges[0] = -1
+	if len(rightEdges) == 1 || firstRightAligned > -1 {
+		rightEdges[len(rightEdges)-1] = -1
 	}
 
 	if 0 == sb.SendMessage(
@@ -101,14 +143,86 @@ func (sb *StatusBar) updateParts() error {
 		return newError("SB_SETPARTS")
 	}
 
+	return sb.updateWidgets()
+}
+
+// updateWidgets moves the widgets of the items into their parts.
+func (sb *StatusBar) updateWidgets() error {
+	inset := sb.IntFrom96DPI(2)
+
+	for i, item := range sb.items.items {
+		if item.widget == nil {
+			continue
+		}
+
+		wb := item.widget.AsWidgetBase()
+
+		if win.GetParent(wb.hWnd) != sb.hWnd {
+			// Take the widget out of the layout of its parent, like
+			// MainWindow does with its StatusBar.
+			if parent := wb.parent; parent != nil {
+				wb.parent = nil
+				parent.Children().Remove(item.widget)
+			}
+			wb.parent = sb.parent
+			win.SetParent(wb.hWnd, sb.hWnd)
+
+			item.widget.(applyFonter).applyFont(sb.Font())
+		}
+
+		var r win.RECT
+		if 0 == sb.SendMessage(win.SB_GETRECT, uintptr(i), uintptr(unsafe.Pointer(&r))) {
+			return newError("SB_GETRECT")
+		}
+
+		bounds := rectangleFromRECT(r)
+		bounds.X += inset
+		bounds.Y += inset
+		bounds.Width -= 2 * inset
+		bounds.Height -= 2 * inset
+
+		if err := item.widget.SetBoundsPixels(bounds); err != nil {
+			return err
+		}
+
+		item.widget.SetVisible(true)
+	}
+
 	return nil
 }
 
 func (sb *StatusBar) WndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) uintptr {
 	switch msg {
+	case win.WM_COMMAND:
+		if window := windowFromHandle(win.HWND(lParam)); lParam != 0 && window != nil {
+			// Notification from an embedded widget
+			return window.WndProc(hwnd, msg, wParam, lParam)
+		}
+
+	case win.WM_DRAWITEM:
+		dis := (*win.DRAWITEMSTRUCT)(unsafe.Pointer(lParam))
+		if n := int(dis.ItemID); dis.HwndItem == sb.hWnd && n >= 0 && n < sb.items.Len() {
+			if sb.items.At(n).paint != nil {
+				sb.items.At(n).draw(dis)
+				return 1
+			}
+		}
+
+	case win.WM_SIZE:
+		if sb.items != nil {
+			sb.updateParts()
+		}
+
 	case win.WM_NOTIFY:
 		nmhdr := (*win.NMHDR)(unsafe.Pointer(lParam))
 
+		if nmhdr.HwndFrom != sb.hWnd {
+			if window := windowFromHandle(nmhdr.HwndFrom); window != nil {
+				// Notification from an embedded widget
+				return window.WndProc(hwnd, msg, wParam, lParam)
+			}
+		}
+
 		switch nmhdr.Code {
 		case win.NM_CLICK:
 			lpnm := (*win.NMMOUSE)(unsafe.Pointer(lParam))
@@ -138,13 +252,18 @@ func (*statusBarLayoutItem) IdealSize() Size {
 }
 
 // StatusBarItem represents a section of a StatusBar that can have its own icon,
-// text, tool tip text and width.
+// text, tool tip text and width. Instead of text, an item can display a small
+// embedded widget or be drawn by a PaintFunc.
 type StatusBarItem struct {
 	sb               *StatusBar
 	icon             *Icon
 	text             string
 	toolTipText      string
 	width            int
+	autoSize         bool
+	rightAligned     bool
+	paint            PaintFunc
+	widget           Widget
 	clickedPublisher EventPublisher
 }
 
@@ -167,7 +286,7 @@ func (sbi *StatusBarItem) SetIcon(icon *Icon) error {
 	old := sbi.icon
 	sbi.icon = icon
 
-	return sbi.maybeTry(sbi.updateIcon, func() { sbi.icon = old })
+	return sbi.maybeTry(sbi.updateIconAndSize, func() { sbi.icon = old })
 }
 
 // Text returns the text of the StatusBarItem.
@@ -184,7 +303,7 @@ func (sbi *StatusBarItem) SetText(text string) error {
 	old := sbi.text
 	sbi.text = text
 
-	return sbi.maybeTry(sbi.updateText, func() { sbi.text = old })
+	return sbi.maybeTry(sbi.updateTextAndSize, func() { sbi.text = old })
 }
 
 // ToolTipText returns the tool tip text of the StatusBarItem.
@@ -236,6 +355,86 @@ func (sbi *StatusBarItem) SetWidth(width int) error {
 	return nil
 }
 
+// AutoSize returns whether the width of the StatusBarItem follows its content
+// instead of Width.
+func (sbi *StatusBarItem) AutoSize() bool {
+	return sbi.autoSize
+}
+
+// SetAutoSize sets whether the width of the StatusBarItem follows its content,
+// i.e. its icon and text or the ideal width of its widget, instead of Width.
+func (sbi *StatusBarItem) SetAutoSize(autoSize bool) error {
+	if autoSize == sbi.autoSize {
+		return nil
+	}
+
+	sbi.autoSize = autoSize
+
+	return sbi.updateParts()
+}
+
+// RightAligned returns whether the StatusBarItem is aligned to the right edge
+// of the StatusBar.
+func (sbi *StatusBarItem) RightAligned() bool {
+	return sbi.rightAligned
+}
+
+// SetRightAligned sets whether the StatusBarItem is aligned to the right edge
+// of the StatusBar. The first right-aligned item and all items following it
+// are packed against the right edge, while the item preceding them is
+// stretched to fill the remaining space.
+func (sbi *StatusBarItem) SetRightAligned(rightAligned bool) error {
+	if rightAligned == sbi.rightAligned {
+		return nil
+	}
+
+	sbi.rightAligned = rightAligned
+
+	return sbi.updateParts()
+}
+
+// PaintFunc returns the function that draws the StatusBarItem, if it is
+// owner-drawn.
+func (sbi *StatusBarItem) PaintFunc() PaintFunc {
+	return sbi.paint
+}
+
+// SetPaintFunc makes the StatusBarItem owner-drawn, with paint being called
+// with the bounds of the item in native pixels whenever it needs to be drawn. Passing nil
+// restores the display of its text.
+func (sbi *StatusBarItem) SetPaintFunc(paint PaintFunc) error {
+	old := sbi.paint
+	sbi.paint = paint
+
+	return sbi.maybeTry(sbi.updateText, func() { sbi.paint = old })
+}
+
+// Widget returns the widget embedded in the StatusBarItem, if any.
+func (sbi *StatusBarItem) Widget() Widget {
+	return sbi.widget
+}
+
+// SetWidget embeds widget in the StatusBarItem, covering its text. Small
+// widgets like a ProgressBar or a LinkLabel work best.
+//
+// The widget should be created with the parent of the StatusBar as parent. It
+// is moved into the StatusBar and no longer takes part in the layout of that
+// parent. The StatusBarItem owns the widget, so a previously embedded widget
+// is disposed.
+func (sbi *StatusBarItem) SetWidget(widget Widget) error {
+	if widget == sbi.widget {
+		return nil
+	}
+
+	if sbi.widget != nil {
+		sbi.widget.Dispose()
+	}
+
+	sbi.widget = widget
+
+	return sbi.updateParts()
+}
+
 func (sbi *StatusBarItem) Clicked() *Event {
 	return sbi.clickedPublisher.Event()
 }
@@ -244,6 +443,77 @@ func (sbi *StatusBarItem) raiseClicked() {
 	sbi.clickedPublisher.Publish()
 }
 
+// widthPixels returns the width of the part of the StatusBarItem in native
+// pixels.
+func (sbi *StatusBarItem) widthPixels() int {
+	dpi := sbi.sb.DPI()
+
+	if !sbi.autoSize {
+		return IntFrom96DPI(sbi.width, dpi)
+	}
+
+	var width int
+	if sbi.widget != nil {
+		if item := createLayoutItemForWidget(sbi.widget); item != nil {
+			if is, ok := item.(IdealSizer); ok {
+				width = is.IdealSize().Width
+			} else {
+				width = minSizeEffective(item).Width
+			}
+		}
+	} else {
+		width = sbi.sb.calculateTextSizeImpl(sbi.text).Width
+		if sbi.icon != nil {
+			width += IntFrom96DPI(16+4, dpi)
+		}
+	}
+
+	// Part borders and padding
+	return width + IntFrom96DPI(12, dpi)
+}
+
+func (sbi *StatusBarItem) updateParts() error {
+	if sbi.sb == nil {
+		return nil
+	}
+
+	return sbi.sb.updateParts()
+}
+
+func (sbi *StatusBarItem) updateIconAndSize(index int) error {
+	if err := sbi.updateIcon(index); err != nil {
+		return err
+	}
+
+	if sbi.autoSize {
+		return sbi.sb.updateParts()
+	}
+
+	return nil
+}
+
+func (sbi *StatusBarItem) updateTextAndSize(index int) error {
+	if err := sbi.updateText(index); err != nil {
+		return err
+	}
+
+	if sbi.autoSize {
+		return sbi.sb.updateParts()
+	}
+
+	return nil
+}
+
+func (sbi *StatusBarItem) draw(dis *win.DRAWITEMSTRUCT) {
+	canvas, err := newCanvasFromHDC(dis.HDC)
+	if err != nil {
+		return
+	}
+	defer canvas.Dispose()
+
+	sbi.paint(canvas, rectangleFromRECT(dis.RcItem))
+}
+
 func (sbi *StatusBarItem) maybeTry(f func(index int) error, rollback func()) error {
 	if sbi.sb != nil {
 		succeeded := false
@@ -295,6 +565,18 @@ func (sbi *StatusBarItem) updateIcon(index int) error {
 }
 
 func (sbi *StatusBarItem) updateText(index int) error {
+	if sbi.paint != nil {
+		if 0 == sbi.sb.SendMessage(
+			win.SB_SETTEXT,
+			uintptr(win.MAKEWORD(byte(index), win.SBT_OWNERDRAW>>8)),
+			0) {
+
+			return newError("SB_SETTEXT")
+		}
+
+		return nil
+	}
+
 	utf16, err := syscall.UTF16PtrFromString(sbi.text)
 	if err != nil {
 		return err
@@ -343,6 +625,12 @@ func (l *StatusBarItemList) At(index int) *StatusBarItem {
 }
 
 func (l *StatusBarItemList) Clear() error {
+	for _, item := range l.items {
+		if item.widget != nil {
+			item.widget.SetVisible(false)
+		}
+	}
+
 	old := l.items
 	l.items = l.items[:0]
 
@@ -425,6 +713,10 @@ func (l *StatusBarItemList) RemoveAt(index int) error {
 	item := l.items[index]
 	item.sb = nil
 
+	if item.widget != nil {
+		item.widget.SetVisible(false)
+	}
+
 	l.items = append(l.items[:index], l.items[index+1:]...)
 
 	succeeded := false
@@ -9,8 +9,11 @@ package walk
 import (
 	"encoding/json"
 	"fmt"
+	"log"
 	"math/big"
 	"reflect"
+	"sort"
+	"strings"
 	"syscall"
 	"time"
 	"unsafe"
@@ -40,6 +43,8 @@ func init() {
 const (
 	tableViewCurrentIndexChangedTimerId = 1 + iota
 	tableViewSelectedIndexesChangedTimerId
+	tableViewItemSearchTimerId
+	tableViewDragTimerId
 )
 
 type TableViewCfg struct {
@@ -67,6 +72,30 @@ type TableView struct {
 	model                              TableModel
 	providedModel                      interface{}
 	itemChecker                        ItemChecker
+	grouper                            Grouper
+	groupKeys                          []string
+	groupRows                          [][]int32
+	rowGroups                          []int32
+	ownerDataCallback                  *tableViewIOwnerDataCallback
+	editor                             Widget
+	editCellEditor                     CellEditor
+	editRow                            int
+	editCol                            int
+	editValue                          interface{}
+	filters                            map[int]ColumnFilter
+	search                             itemSearch
+	searchColumn                       int
+	searchFormatter                    func(value interface{}) string
+	searchRow                          int
+	copyShortcutDisabled               bool
+	reorderable                        bool
+	tree                               *TreeTableView
+	dragRows                           []int
+	dragHWnd                           win.HWND
+	hDragIml                           win.HIMAGELIST
+	dragOffset                         Point // of the drag list view, in TableView window coordinates
+	dropIndex                          int
+	dropValid                          bool
 	imageProvider                      ImageProvider
 	styler                             CellStyler
 	style                              CellStyle
@@ -93,6 +122,12 @@ type TableView struct {
 	columnsOrderableChangedPublisher   EventPublisher
 	columnsSizableChangedPublisher     EventPublisher
 	itemCountChangedPublisher          EventPublisher
+	groupTaskLinkClickedPublisher      StringEventPublisher
+	cellEditBeginningPublisher         CellEditingEventPublisher
+	cellEditValidatingPublisher        CellEditingEventPublisher
+	cellEditCommittedPublisher         CellEditEventPublisher
+	cellEditCanceledPublisher          CellEditEventPublisher
+	filterChangedPublisher             EventPublisher
 	publishNextSelClear                bool
 	inSetSelectedIndexes               bool
 	lastColumnStretched                bool
@@ -152,6 +187,8 @@ func NewTableViewWithCfg(parent Container, cfg *TableViewCfg) (*TableView, error
 		customRowHeight:             cfg.CustomRowHeight,
 		scrollbarOrientation:        Horizontal | Vertical,
 		restoringCurrentItemOnReset: true,
+		searchColumn:                -1,
+		searchRow:                   -1,
 	}
 
 	tv.columns = newTableViewColumnList(tv)
@@ -250,8 +287,8 @@ func NewTableViewWithCfg(parent Container, cfg *TableViewCfg) (*TableView, error
 	win.SendMessage(tv.hwndFrozenLV, win.WM_CHANGEUISTATE, uintptr(win.MAKELONG(win.UIS_SET, win.UISF_HIDEFOCUS)), 0)
 	win.SendMessage(tv.hwndNormalLV, win.WM_CHANGEUISTATE, uintptr(win.MAKELONG(win.UIS_SET, win.UISF_HIDEFOCUS)), 0)
 
-	App().toolTip().addTool(tv.hwndFrozenHdr, false)
-	App().toolTip().addTool(tv.hwndNormalHdr, false)
+	App().ToolTip().addTool(tv.hwndFrozenHdr, false)
+	App().ToolTip().addTool(tv.hwndNormalHdr, false)
 
 	tv.applyFont(parent.Font())
 
@@ -295,7 +332,7 @@ func NewTableViewWithCfg(parent Container, cfg *TableViewCfg) (*TableView, error
 		func() interface{} {
 			if i := tv.CurrentIndex(); i > -1 {
 				if rm, ok := tv.providedModel.(reflectModel); ok {
-					return reflect.ValueOf(rm.Items()).Index(i).Interface()
+					return reflect.ValueOf(rm.Items()).Index(tv.itemIndex(i)).Interface()
 				}
 			}
 
@@ -336,6 +373,10 @@ func (tv *TableView) asTableView() *TableView {
 // Dispose releases the operating system resources, associated with the
 // *TableView.
 func (tv *TableView) Dispose() {
+	if tv.editor != nil {
+		tv.closeEditor()
+	}
+
 	tv.columns.unsetColumnsTV()
 
 	tv.disposeImageListAndCaches()
@@ -350,13 +391,13 @@ func (tv *TableView) Dispose() {
 	}
 
 	if tv.hwndFrozenLV != 0 {
-		App().toolTip().removeTool(tv.hwndFrozenHdr)
+		App().ToolTip().removeTool(tv.hwndFrozenHdr)
 		win.DestroyWindow(tv.hwndFrozenLV)
 		tv.hwndFrozenLV = 0
 	}
 
 	if tv.hwndNormalLV != 0 {
-		App().toolTip().removeTool(tv.hwndNormalHdr)
+		App().ToolTip().removeTool(tv.hwndNormalHdr)
 		win.DestroyWindow(tv.hwndNormalLV)
 		tv.hwndNormalLV = 0
 	}
@@ -622,8 +663,40 @@ func (tv *TableView) Columns() *TableViewColumnList {
 	return tv.columns
 }
 
+// FrozenColumnCount returns the number of leading columns that are frozen,
+// i.e. that stay visible while the other columns are scrolled horizontally.
+func (tv *TableView) FrozenColumnCount() int {
+	for i, tvc := range tv.columns.items {
+		if !tvc.frozen {
+			return i
+		}
+	}
+
+	return len(tv.columns.items)
+}
+
+// SetFrozenColumnCount freezes the first count columns and unfreezes all
+// others, so that the leading columns, like a key column, stay visible while
+// the other columns are scrolled horizontally.
+func (tv *TableView) SetFrozenColumnCount(count int) error {
+	if count < 0 || count > len(tv.columns.items) {
+		return newError("count out of range")
+	}
+
+	tv.SetSuspended(true)
+	defer tv.SetSuspended(false)
+
+	for i, tvc := range tv.columns.items {
+		if err := tvc.SetFrozen(i < count); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // VisibleColumnsInDisplayOrder returns a slice of visible columns in display
-// order.
+// order, or nil if the order cannot be retrieved.
 func (tv *TableView) VisibleColumnsInDisplayOrder() []*TableViewColumn {
 	visibleCols := tv.visibleColumns()
 	indices := make([]int32, len(visibleCols))
@@ -712,7 +785,7 @@ func (tv *TableView) attachModel() {
 
 		count := tv.model.RowCount()
 		for i := 0; i < count; i++ {
-			if ip.ID(i) == tv.currentItemID {
+			if ip.ID(tv.itemIndex(i)) == tv.currentItemID {
 				tv.SetCurrentIndex(i)
 				return
 			}
@@ -722,6 +795,8 @@ func (tv *TableView) attachModel() {
 	}
 
 	tv.rowsResetHandlerHandle = tv.model.RowsReset().Attach(func() {
+		tv.CancelEdit()
+
 		tv.setItemCount()
 
 		if ip, ok := tv.providedModel.(IDProvider); ok && tv.restoringCurrentItemOnReset {
@@ -736,6 +811,10 @@ func (tv *TableView) attachModel() {
 	})
 
 	tv.rowChangedHandlerHandle = tv.model.RowChanged().Attach(func(row int) {
+		if err := tv.regroupRow(row); err != nil {
+			log.Printf("*TableView - failed to regroup row %d: %s", row, err)
+		}
+
 		tv.UpdateItem(row)
 	})
 
@@ -743,6 +822,10 @@ func (tv *TableView) attachModel() {
 		if s, ok := tv.model.(Sorter); ok {
 			s.Sort(s.SortedColumn(), s.SortOrder())
 		} else {
+			if err := tv.updateGroups(); err != nil {
+				log.Printf("*TableView - failed to update groups: %s", err)
+			}
+
 			first, last := uintptr(from), uintptr(to)
 			win.SendMessage(tv.hwndFrozenLV, win.LVM_REDRAWITEMS, first, last)
 			win.SendMessage(tv.hwndNormalLV, win.LVM_REDRAWITEMS, first, last)
@@ -750,6 +833,8 @@ func (tv *TableView) attachModel() {
 	})
 
 	tv.rowsInsertedHandlerHandle = tv.model.RowsInserted().Attach(func(from, to int) {
+		tv.CancelEdit()
+
 		i := tv.currentIndex
 
 		tv.setItemCount()
@@ -764,6 +849,8 @@ func (tv *TableView) attachModel() {
 	})
 
 	tv.rowsRemovedHandlerHandle = tv.model.RowsRemoved().Attach(func(from, to int) {
+		tv.CancelEdit()
+
 		i := tv.currentIndex
 
 		tv.setItemCount()
@@ -792,6 +879,10 @@ func (tv *TableView) attachModel() {
 			col := sorter.SortedColumn()
 			tv.setSortIcon(col, sorter.SortOrder())
 
+			if err := tv.updateGroups(); err != nil {
+				log.Printf("*TableView - failed to update groups: %s", err)
+			}
+
 			tv.redrawItems()
 		})
 	}
@@ -818,129 +909,1073 @@ func (tv *TableView) Model() interface{} {
 	return tv.providedModel
 }
 
-// SetModel sets the model of the TableView.
-//
-// It is required that mdl either implements walk.TableModel,
-// walk.ReflectTableModel or be a slice of pointers to struct or a
-// []map[string]interface{}. A walk.TableModel implementation must also
-// implement walk.Sorter to support sorting, all other options get sorting for
-// free. To support item check boxes and icons, mdl must implement
-// walk.ItemChecker and walk.ImageProvider, respectively. On-demand model
-// population for a walk.ReflectTableModel or slice requires mdl to implement
-// walk.Populator.
-func (tv *TableView) SetModel(mdl interface{}) error {
-	model, ok := mdl.(TableModel)
-	if !ok && mdl != nil {
-		var err error
-		if model, err = newReflectTableModel(mdl); err != nil {
-			if model, err = newMapTableModel(mdl); err != nil {
-				return err
+// SetModel sets the model of the TableView.
+//
+// It is required that mdl either implements walk.TableModel,
+// walk.ReflectTableModel or be a slice of pointers to struct or a
+// []map[string]interface{}. A walk.TableModel implementation must also
+// implement walk.Sorter to support sorting, all other options get sorting for
+// free. To support item check boxes and icons, mdl must implement
+// walk.ItemChecker and walk.ImageProvider, respectively. To display its items
+// in groups, mdl must implement walk.Grouper. On-demand model
+// population for a walk.ReflectTableModel or slice requires mdl to implement
+// walk.Populator.
+func (tv *TableView) SetModel(mdl interface{}) error {
+	model, ok := mdl.(TableModel)
+	if !ok && mdl != nil {
+		var err error
+		if model, err = newReflectTableModel(mdl); err != nil {
+			if model, err = newMapTableModel(mdl); err != nil {
+				return err
+			}
+		}
+	}
+
+	tv.CancelEdit()
+
+	tv.SetSuspended(true)
+	defer tv.SetSuspended(false)
+
+	if tv.model != nil {
+		tv.detachModel()
+
+		tv.disposeImageListAndCaches()
+	}
+
+	oldProvidedModelStyler, _ := tv.providedModel.(CellStyler)
+	if styler, ok := mdl.(CellStyler); ok || tv.styler == oldProvidedModelStyler {
+		tv.styler = styler
+	}
+
+	tv.providedModel = mdl
+	tv.model = model
+
+	tv.itemChecker, _ = model.(ItemChecker)
+	tv.imageProvider, _ = model.(ImageProvider)
+	if tv.grouper, ok = model.(Grouper); !ok {
+		// Slices are wrapped in reflect models, which do not implement
+		// Grouper, but their data source may. It knows the items by their
+		// index, which differs from the row while filtered.
+		if grouper, ok := mdl.(Grouper); ok {
+			if indexer, ok := model.(itemIndexer); ok {
+				tv.grouper = itemGrouper{grouper, indexer}
+			} else {
+				tv.grouper = grouper
+			}
+		}
+	}
+
+	for _, tvc := range tv.columns.items {
+		// Columns get a filter drop-down button if the model supports it.
+		tvc.update()
+	}
+
+	if model != nil {
+		tv.attachModel()
+
+		if dms, ok := model.(dataMembersSetter); ok {
+			// FIXME: This depends on columns to be initialized before
+			// calling this method.
+			dataMembers := make([]string, len(tv.columns.items))
+
+			for i, col := range tv.columns.items {
+				dataMembers[i] = col.DataMemberEffective()
+			}
+
+			dms.setDataMembers(dataMembers)
+		}
+
+		if lfs, ok := model.(lessFuncsSetter); ok {
+			lessFuncs := make([]func(i, j int) bool, tv.columns.Len())
+			for i, c := range tv.columns.items {
+				lessFuncs[i] = c.lessFunc
+			}
+			lfs.setLessFuncs(lessFuncs)
+		}
+
+		if sorter, ok := tv.model.(Sorter); ok {
+			if tv.sortedColumnIndex >= tv.visibleColumnCount() {
+				tv.sortedColumnIndex = maxi(-1, mini(0, tv.visibleColumnCount()-1))
+				tv.sortOrder = SortAscending
+			}
+
+			sorter.Sort(tv.sortedColumnIndex, tv.sortOrder)
+		}
+
+		if len(tv.filters) > 0 {
+			if err := tv.applyFilters(); err != nil {
+				return err
+			}
+		}
+	}
+
+	tv.SetCurrentIndex(-1)
+
+	tv.setItemCount()
+
+	tv.itemCountChangedPublisher.Publish()
+
+	return nil
+}
+
+// TableModel returns the TableModel of the TableView.
+func (tv *TableView) TableModel() TableModel {
+	return tv.model
+}
+
+// ItemChecker returns the ItemChecker of the TableView.
+func (tv *TableView) ItemChecker() ItemChecker {
+	return tv.itemChecker
+}
+
+// SetItemChecker sets the ItemChecker of the TableView.
+func (tv *TableView) SetItemChecker(itemChecker ItemChecker) {
+	tv.itemChecker = itemChecker
+}
+
+// CellStyler returns the CellStyler of the TableView.
+func (tv *TableView) CellStyler() CellStyler {
+	return tv.styler
+}
+
+// SetCellStyler sets the CellStyler of the TableView.
+func (tv *TableView) SetCellStyler(styler CellStyler) {
+	tv.styler = styler
+}
+
+// SearchColumn returns the index of the column that is searched when the user
+// types while the TableView has the focus. -1 means the column by which the
+// items are sorted, or else the first visible column.
+func (tv *TableView) SearchColumn() int {
+	return tv.searchColumn
+}
+
+// SetSearchColumn sets the index of the column that is searched when the user
+// types while the TableView has the focus. -1 means the column by which the
+// items are sorted, or else the first visible column.
+func (tv *TableView) SetSearchColumn(col int) error {
+	if col < -1 || col >= len(tv.columns.items) {
+		return newError("col out of range")
+	}
+
+	tv.searchColumn = col
+
+	return nil
+}
+
+// SearchFormatter returns the function that converts values of the search
+// column to the text that is searched. If nil, the displayed text is searched.
+func (tv *TableView) SearchFormatter() func(value interface{}) string {
+	return tv.searchFormatter
+}
+
+// SetSearchFormatter sets the function that converts values of the search
+// column to the text that is searched. If nil, the displayed text is searched.
+func (tv *TableView) SetSearchFormatter(formatter func(value interface{}) string) {
+	tv.searchFormatter = formatter
+}
+
+// ItemSearching returns the event that is published when the user typed to
+// search for an item. The text is to be searched for starting at the item at
+// index start, wrapping around. Handlers of models that can find items faster
+// than by comparing the text of each item, e.g. using an index, set index to
+// the item found.
+func (tv *TableView) ItemSearching() *ItemSearchEvent {
+	return tv.search.publisher.Event()
+}
+
+func (tv *TableView) searchColumnEffective() int {
+	if tv.searchColumn > -1 {
+		return tv.searchColumn
+	}
+
+	if sorter, ok := tv.model.(Sorter); ok {
+		if col := sorter.SortedColumn(); col > -1 && col < len(tv.columns.items) && tv.columns.items[col].visible {
+			return col
+		}
+	}
+
+	if cols := tv.visibleColumns(); len(cols) > 0 {
+		return tv.columns.Index(cols[0])
+	}
+
+	return -1
+}
+
+func (tv *TableView) searchText(row, col int) string {
+	value := tv.model.Value(row, col)
+
+	if tv.searchFormatter != nil {
+		return tv.searchFormatter(value)
+	}
+
+	return tv.formatCellValue(col, value)
+}
+
+// handleSearchChar makes the first item matching the text typed so far
+// current and returns whether ch was used for searching.
+func (tv *TableView) handleSearchChar(ch rune) bool {
+	if tv.model == nil {
+		return false
+	}
+
+	col := tv.searchColumnEffective()
+	if col == -1 {
+		return false
+	}
+
+	index, handled := tv.search.handleChar(ch, tv.currentIndex, tv.model.RowCount(), func(row int) string {
+		return tv.searchText(row, col)
+	})
+	if !handled {
+		return false
+	}
+
+	prevRow := tv.searchRow
+	tv.searchRow = index
+
+	if index > -1 {
+		tv.SetCurrentIndex(index)
+	}
+
+	tv.redrawRow(prevRow)
+	tv.redrawRow(index)
+
+	// The match stays highlighted until the search times out.
+	if 0 == win.SetTimer(tv.hWnd, tableViewItemSearchTimerId, uint32(itemSearchTimeout/time.Millisecond), 0) {
+		lastError("SetTimer")
+	}
+
+	return true
+}
+
+func (tv *TableView) redrawRow(row int) {
+	if row < 0 {
+		return
+	}
+
+	win.SendMessage(tv.hwndFrozenLV, win.LVM_REDRAWITEMS, uintptr(row), uintptr(row))
+	win.SendMessage(tv.hwndNormalLV, win.LVM_REDRAWITEMS, uintptr(row), uintptr(row))
+}
+
+// drawSearchMatch underlines the part of the text of a cell that matches the
+// search text.
+func (tv *TableView) drawSearchMatch(hwnd win.HWND, nmlvcd *win.NMLVCUSTOMDRAW, row, col int) {
+	text := tv.formatCellValue(col, tv.model.Value(row, col))
+
+	match := []rune(tv.search.match)
+	if len(match) == 0 || len(match) > len([]rune(text)) || !strings.EqualFold(string([]rune(text)[:len(match)]), string(match)) {
+		// A SearchFormatter may produce texts that differ from the displayed
+		// ones.
+		return
+	}
+
+	hdc := nmlvcd.Nmcd.Hdc
+
+	textUTF16 := syscall.StringToUTF16(text)
+	prefixUTF16 := syscall.StringToUTF16(string([]rune(text)[:len(match)]))
+
+	var textSize, prefixSize win.SIZE
+	if !win.GetTextExtentPoint32(hdc, &textUTF16[0], int32(len(textUTF16)-1), &textSize) ||
+		!win.GetTextExtentPoint32(hdc, &prefixUTF16[0], int32(len(prefixUTF16)-1), &prefixSize) {
+		return
+	}
+
+	rc := win.RECT{Top: nmlvcd.ISubItem, Left: win.LVIR_LABEL}
+	if 0 == win.SendMessage(hwnd, win.LVM_GETSUBITEMRECT, uintptr(row), uintptr(unsafe.Pointer(&rc))) {
+		return
+	}
+
+	dpi := tv.DPI()
+	padding := int32(IntFrom96DPI(6, dpi))
+
+	var x int32
+	switch tv.columns.items[col].Alignment() {
+	case AlignCenter:
+		x = rc.Left + (rc.Right-rc.Left-textSize.CX)/2
+	case AlignFar:
+		x = rc.Right - padding - textSize.CX
+	default:
+		x = rc.Left + padding
+	}
+
+	thickness := IntFrom96DPI(2, dpi)
+	y := int(rc.Top+(rc.Bottom-rc.Top+textSize.CY)/2) - thickness
+
+	canvas, err := newCanvasFromHDC(hdc)
+	if err != nil {
+		return
+	}
+	defer canvas.Dispose()
+
+	brush, err := NewSystemColorBrush(SysColorHighlight)
+	if err != nil {
+		return
+	}
+	defer brush.Dispose()
+
+	canvas.FillRectanglePixels(brush, Rectangle{int(x), y, int(prefixSize.CX), thickness})
+}
+
+// ColumnFilter returns the filter of the column at index col. If the column
+// is not filtered, the filter is not Active.
+func (tv *TableView) ColumnFilter(col int) ColumnFilter {
+	return tv.filters[col]
+}
+
+// SetColumnFilter sets the filter of the column at index col. An inactive
+// filter removes the filter of the column.
+//
+// Filtering requires the model to implement Filterer. Users can edit the
+// filters through drop-down buttons in the headers of filterable columns.
+func (tv *TableView) SetColumnFilter(col int, filter ColumnFilter) error {
+	filters := tv.Filters()
+
+	if filter.Active() {
+		filters[col] = filter
+	} else {
+		delete(filters, col)
+	}
+
+	return tv.SetFilters(filters)
+}
+
+// Filters returns the active filters by column index.
+func (tv *TableView) Filters() map[int]ColumnFilter {
+	filters := make(map[int]ColumnFilter, len(tv.filters))
+	for col, filter := range tv.filters {
+		filters[col] = filter
+	}
+
+	return filters
+}
+
+// SetFilters replaces all filters, e.g. to restore filters returned by
+// Filters earlier.
+func (tv *TableView) SetFilters(filters map[int]ColumnFilter) error {
+	active := make(map[int]ColumnFilter, len(filters))
+	for col, filter := range filters {
+		if col < 0 || col >= len(tv.columns.items) {
+			return newError("col out of range")
+		}
+
+		if filter.Active() {
+			active[col] = filter
+		}
+	}
+
+	if tv.model != nil {
+		if _, ok := tv.model.(Filterer); !ok {
+			return newError("model does not implement Filterer")
+		}
+	}
+
+	tv.CancelEdit()
+
+	tv.filters = active
+
+	if err := tv.applyFilters(); err != nil {
+		return err
+	}
+
+	tv.filterChangedPublisher.Publish()
+
+	return nil
+}
+
+// FilterChanged returns the event that is published when the filters
+// changed.
+func (tv *TableView) FilterChanged() *Event {
+	return tv.filterChangedPublisher.Event()
+}
+
+func (tv *TableView) columnFilterable(col int) bool {
+	filterer, ok := tv.model.(Filterer)
+
+	return ok && col >= 0 && filterer.ColumnFilterable(col)
+}
+
+func (tv *TableView) applyFilters() error {
+	filterer, ok := tv.model.(Filterer)
+	if !ok {
+		return nil
+	}
+
+	if len(tv.filters) == 0 {
+		return filterer.Filter(nil)
+	}
+
+	matchers := make([]*columnFilterMatcher, 0, len(tv.filters))
+	for col, filter := range tv.filters {
+		matchers = append(matchers, newColumnFilterMatcher(col, filter))
+	}
+
+	return filterer.Filter(func(value func(col int) interface{}) bool {
+		for _, m := range matchers {
+			if !m.matches(tv.formatCellValue(m.col, value(m.col))) {
+				return false
+			}
+		}
+
+		return true
+	})
+}
+
+// BeginEdit starts editing the cell at row and col, using the CellEditor of
+// the column. An edit in progress is committed first.
+//
+// The model must implement CellValueSetter.
+func (tv *TableView) BeginEdit(row, col int) error {
+	if tv.model == nil || row < 0 || row >= tv.model.RowCount() {
+		return newError("row out of range")
+	}
+	if !tv.cellEditable(col) {
+		return newError("cell not editable")
+	}
+
+	if err := tv.CommitEdit(); err != nil {
+		return err
+	}
+
+	value := tv.model.Value(row, col)
+
+	var canceled bool
+	tv.cellEditBeginningPublisher.Publish(row, col, value, &canceled)
+	if canceled {
+		return nil
+	}
+
+	tvc := tv.columns.items[col]
+
+	hwndLV := tv.hwndNormalLV
+	if tvc.frozen {
+		hwndLV = tv.hwndFrozenLV
+	}
+
+	win.SendMessage(tv.hwndFrozenLV, win.LVM_ENSUREVISIBLE, uintptr(row), 0)
+	win.SendMessage(tv.hwndNormalLV, win.LVM_ENSUREVISIBLE, uintptr(row), 0)
+
+	rc := tv.cellRect(hwndLV, row, tvc.indexInListView())
+
+	if hwndLV == tv.hwndNormalLV {
+		var rcClient win.RECT
+		win.GetClientRect(hwndLV, &rcClient)
+
+		var dx int32
+		if rc.Right > rcClient.Right {
+			dx = rc.Right - rcClient.Right
+		}
+		if rc.Left-dx < 0 {
+			dx = rc.Left
+		}
+
+		if dx != 0 {
+			win.SendMessage(hwndLV, win.LVM_SCROLL, uintptr(dx), 0)
+			rc = tv.cellRect(hwndLV, row, tvc.indexInListView())
+		}
+	}
+
+	pt := win.POINT{rc.Left, rc.Top}
+	win.ClientToScreen(hwndLV, &pt)
+	win.ScreenToClient(tv.hWnd, &pt)
+
+	editor, err := tvc.editor.CreateEditor(tv.Parent(), value)
+	if err != nil {
+		return err
+	}
+
+	// Take the editor out of the layout of its parent and let it float above
+	// the list views, like StatusBar does with its widgets.
+	wb := editor.AsWidgetBase()
+	if parent := wb.parent; parent != nil {
+		wb.parent = nil
+		parent.Children().Remove(editor)
+	}
+	wb.parent = tv.parent
+	win.SetParent(wb.hWnd, tv.hWnd)
+
+	editor.(applyFonter).applyFont(tv.Font())
+
+	height := rc.Bottom - rc.Top
+	if _, ok := editor.(*ComboBox); ok {
+		// The height of a combo box includes its drop-down list.
+		height += int32(tv.IntFrom96DPI(200))
+	}
+
+	win.SetWindowPos(wb.hWnd, win.HWND_TOP, pt.X, pt.Y, rc.Right-rc.Left, height, 0)
+
+	tv.editor = editor
+	tv.editCellEditor = tvc.editor
+	tv.editRow, tv.editCol = row, col
+	tv.editValue = value
+
+	editor.FocusedChanged().Attach(func() {
+		tv.Synchronize(func() {
+			if tv.editor == editor && !tv.editorHasFocus() {
+				tv.endEdit()
+			}
+		})
+	})
+
+	editor.SetFocus()
+
+	return nil
+}
+
+// CommitEdit ends the edit in progress, if any, and sets the entered value
+// in the model. If a CellEditValidating handler cancels, or the model returns
+// an error, the edit continues.
+func (tv *TableView) CommitEdit() error {
+	if tv.editor == nil {
+		return nil
+	}
+
+	value, err := tv.editCellEditor.EditorValue(tv.editor)
+	if err != nil {
+		return err
+	}
+
+	row, col := tv.editRow, tv.editCol
+
+	var canceled bool
+	tv.cellEditValidatingPublisher.Publish(row, col, value, &canceled)
+	if canceled {
+		return errValidationFailed
+	}
+
+	if err := tv.model.(CellValueSetter).SetValue(row, col, value); err != nil {
+		return err
+	}
+
+	tv.closeEditor()
+
+	tv.UpdateItem(row)
+
+	tv.cellEditCommittedPublisher.Publish(row, col, value)
+
+	return nil
+}
+
+// CancelEdit ends the edit in progress, if any, without changing the model.
+func (tv *TableView) CancelEdit() {
+	if tv.editor == nil {
+		return
+	}
+
+	row, col, value := tv.editRow, tv.editCol, tv.editValue
+
+	tv.closeEditor()
+
+	tv.cellEditCanceledPublisher.Publish(row, col, value)
+}
+
+// Editing returns whether a cell is being edited.
+func (tv *TableView) Editing() bool {
+	return tv.editor != nil
+}
+
+// CellEditBeginning returns the event that is published before a cell is
+// edited, with the current value. Handlers can cancel editing.
+func (tv *TableView) CellEditBeginning() *CellEditingEvent {
+	return tv.cellEditBeginningPublisher.Event()
+}
+
+// CellEditValidating returns the event that is published before an entered
+// value is set in the model. Handlers can reject the value by canceling, which
+// keeps the editor open.
+func (tv *TableView) CellEditValidating() *CellEditingEvent {
+	return tv.cellEditValidatingPublisher.Event()
+}
+
+// CellEditCommitted returns the event that is published after an entered value
+// has been set in the model.
+func (tv *TableView) CellEditCommitted() *CellEditEvent {
+	return tv.cellEditCommittedPublisher.Event()
+}
+
+// CellEditCanceled returns the event that is published when an edit has been
+// canceled, with the unchanged value.
+func (tv *TableView) CellEditCanceled() *CellEditEvent {
+	return tv.cellEditCanceledPublisher.Event()
+}
+
+func (tv *TableView) cellEditable(col int) bool {
+	if _, ok := tv.model.(CellValueSetter); !ok || col < 0 || col >= len(tv.columns.items) {
+		return false
+	}
+
+	tvc := tv.columns.items[col]
+
+	return tvc.visible && tvc.editor != nil
+}
+
+// cellRect returns the bounds of a cell in client coordinates of hwndLV.
+func (tv *TableView) cellRect(hwndLV win.HWND, row int, subItem int32) win.RECT {
+	rc := win.RECT{Left: win.LVIR_BOUNDS, Top: subItem}
+	if subItem == 0 {
+		// The bounds of the first subitem span the whole row.
+		rc.Left = win.LVIR_LABEL
+	}
+
+	win.SendMessage(hwndLV, win.LVM_GETSUBITEMRECT, uintptr(row), uintptr(unsafe.Pointer(&rc)))
+
+	return rc
+}
+
+// editableColumns returns the indexes of the editable columns in display
+// order.
+func (tv *TableView) editableColumns() []int {
+	var cols []int
+
+	for _, tvc := range tv.VisibleColumnsInDisplayOrder() {
+		if col := tv.columns.Index(tvc); tv.cellEditable(col) {
+			cols = append(cols, col)
+		}
+	}
+
+	return cols
+}
+
+// beginEditAt starts editing the cell at the position in lp of hwndLV and
+// returns whether it did.
+func (tv *TableView) beginEditAt(hwndLV win.HWND, lp uintptr) bool {
+	var hti win.LVHITTESTINFO
+	hti.Pt = win.POINT{win.GET_X_LPARAM(lp), win.GET_Y_LPARAM(lp)}
+	win.SendMessage(hwndLV, win.LVM_SUBITEMHITTEST, 0, uintptr(unsafe.Pointer(&hti)))
+
+	if hti.IItem == -1 || hti.Flags&win.LVHT_ONITEMSTATEICON != 0 {
+		return false
+	}
+
+	col := tv.fromLVColIdx(hwndLV == tv.hwndFrozenLV, hti.ISubItem)
+	if !tv.cellEditable(col) {
+		return false
+	}
+
+	tv.SetCurrentIndex(int(hti.IItem))
+
+	return tv.BeginEdit(int(hti.IItem), col) == nil
+}
+
+// editNextCell commits the edit in progress and starts editing the next
+// editable cell, continuing in the next row after the last one.
+func (tv *TableView) editNextCell(backwards bool) {
+	row, col := tv.editRow, tv.editCol
+
+	if err := tv.CommitEdit(); err != nil {
+		return
+	}
+
+	cols := tv.editableColumns()
+
+	i := len(cols)
+	for j, c := range cols {
+		if c == col {
+			i = j
+			break
+		}
+	}
+
+	if backwards {
+		i--
+		if i < 0 {
+			i = len(cols) - 1
+			row--
+		}
+	} else {
+		i++
+		if i >= len(cols) {
+			i = 0
+			row++
+		}
+	}
+
+	if row < 0 || row >= tv.model.RowCount() || i < 0 {
+		return
+	}
+
+	tv.SetCurrentIndex(row)
+	tv.BeginEdit(row, cols[i])
+}
+
+// endEdit commits the edit in progress, or cancels it if that fails, e.g. when
+// the list views scroll away from the editor.
+func (tv *TableView) endEdit() {
+	if err := tv.CommitEdit(); err != nil {
+		tv.CancelEdit()
+	}
+}
+
+func (tv *TableView) closeEditor() {
+	editor := tv.editor
+	hadFocus := tv.editorHasFocus()
+
+	tv.editor = nil
+	tv.editCellEditor = nil
+	tv.editValue = nil
+
+	if hadFocus {
+		win.SetFocus(tv.hwndNormalLV)
+	}
+
+	editor.AsWidgetBase().parent = nil
+	editor.Dispose()
+}
+
+// editorHasFocus returns whether the editor or one of its pop-ups, like the
+// drop-down calendar of a DateEdit, has the focus.
+func (tv *TableView) editorHasFocus() bool {
+	focus := win.GetFocus()
+	if focus == 0 {
+		return true
+	}
+
+	hwnd := tv.editor.Handle()
+	if focus == hwnd || win.IsChild(hwnd, focus) {
+		return true
+	}
+
+	return win.GetAncestor(focus, win.GA_ROOT) != win.GetAncestor(hwnd, win.GA_ROOT)
+}
+
+// handleEditorKeyDown handles the keys that end an edit, before dialog
+// navigation gets them. It returns whether key was handled.
+func (tv *TableView) handleEditorKeyDown(key Key, mods Modifiers) bool {
+	if tv.editor == nil {
+		return false
+	}
+
+	if cb, ok := tv.editor.(*ComboBox); ok && cb.SendMessage(win.CB_GETDROPPEDSTATE, 0, 0) != 0 {
+		// Let the combo box close its drop-down list first.
+		return false
+	}
+
+	switch key {
+	case KeyReturn:
+		tv.CommitEdit()
+		return true
+
+	case KeyEscape:
+		tv.CancelEdit()
+		return true
+
+	case KeyTab:
+		if mods&ModControl == 0 {
+			tv.editNextCell(mods&ModShift != 0)
+			return true
+		}
+	}
+
+	return false
+}
+
+func (tv *TableView) setItemCount() error {
+	var count int
+
+	if tv.model != nil {
+		count = tv.model.RowCount()
+	}
+
+	if 0 == win.SendMessage(tv.hwndFrozenLV, win.LVM_SETITEMCOUNT, uintptr(count), win.LVSICF_NOINVALIDATEALL|win.LVSICF_NOSCROLL) {
+		return newError("SendMessage(LVM_SETITEMCOUNT)")
+	}
+	if 0 == win.SendMessage(tv.hwndNormalLV, win.LVM_SETITEMCOUNT, uintptr(count), win.LVSICF_NOINVALIDATEALL|win.LVSICF_NOSCROLL) {
+		return newError("SendMessage(LVM_SETITEMCOUNT)")
+	}
+
+	return tv.updateGroups()
+}
+
+// GroupKeys returns the keys of the groups the items of the TableView are
+// displayed in, in display order. It returns nil if the model does not
+// implement Grouper.
+func (tv *TableView) GroupKeys() []string {
+	return tv.groupKeys
+}
+
+// GroupCollapsed returns whether the group with the specified key is
+// collapsed.
+func (tv *TableView) GroupCollapsed(key string) bool {
+	g := tv.groupIndex(key)
+	if g == -1 {
+		return false
+	}
+
+	return win.SendMessage(tv.groupHeaderLV(), win.LVM_GETGROUPSTATE, uintptr(g), _LVGS_COLLAPSED) != 0
+}
+
+// SetGroupCollapsed collapses or expands the group with the specified key.
+func (tv *TableView) SetGroupCollapsed(key string, collapsed bool) error {
+	g := tv.groupIndex(key)
+	if g == -1 {
+		return newError("unknown group")
+	}
+
+	for _, hwnd := range [2]win.HWND{tv.hwndFrozenLV, tv.hwndNormalLV} {
+		setLVGroupCollapsed(hwnd, g, collapsed)
+	}
+
+	return nil
+}
+
+// GroupTaskLinkClicked returns the event that is published when the user
+// clicks the task link of a group. The event carries the key of the group.
+func (tv *TableView) GroupTaskLinkClicked() *StringEvent {
+	return tv.groupTaskLinkClickedPublisher.Event()
+}
+
+func (tv *TableView) groupIndex(key string) int {
+	for i, k := range tv.groupKeys {
+		if k == key {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// groupHeaderLV returns the list view that displays the texts of the group
+// headers, which is the one displaying the first column.
+func (tv *TableView) groupHeaderLV() win.HWND {
+	if tv.hasFrozenColumn {
+		return tv.hwndFrozenLV
+	}
+
+	return tv.hwndNormalLV
+}
+
+// updateGroups rebuilds the groups of both list views from the Grouper of the
+// model, keeping the collapsed state of groups that still exist.
+func (tv *TableView) updateGroups() error {
+	if tv.grouper == nil {
+		if tv.groupKeys != nil {
+			for _, hwnd := range [2]win.HWND{tv.hwndFrozenLV, tv.hwndNormalLV} {
+				win.SendMessage(hwnd, win.LVM_REMOVEALLGROUPS, 0, 0)
+				win.SendMessage(hwnd, win.LVM_ENABLEGROUPVIEW, 0, 0)
 			}
+
+			tv.groupKeys = nil
+			tv.groupRows = nil
+			tv.rowGroups = nil
 		}
+
+		return nil
 	}
 
-	tv.SetSuspended(true)
-	defer tv.SetSuspended(false)
+	collapsed := make(map[string]bool, len(tv.groupKeys))
+	for _, key := range tv.groupKeys {
+		collapsed[key] = tv.GroupCollapsed(key)
+	}
 
-	if tv.model != nil {
-		tv.detachModel()
+	count := tv.model.RowCount()
 
-		tv.disposeImageListAndCaches()
+	groupKeys := []string{}
+	var groupRows [][]int32
+	rowGroups := make([]int32, count)
+	key2Index := make(map[string]int32)
+
+	for row := 0; row < count; row++ {
+		key := tv.grouper.GroupBy(row)
+
+		g, ok := key2Index[key]
+		if !ok {
+			g = int32(len(groupKeys))
+			key2Index[key] = g
+			groupKeys = append(groupKeys, key)
+			groupRows = append(groupRows, nil)
+		}
+
+		groupRows[g] = append(groupRows[g], int32(row))
+		rowGroups[row] = g
 	}
 
-	oldProvidedModelStyler, _ := tv.providedModel.(CellStyler)
-	if styler, ok := mdl.(CellStyler); ok || tv.styler == oldProvidedModelStyler {
-		tv.styler = styler
+	tv.groupKeys = groupKeys
+	tv.groupRows = groupRows
+	tv.rowGroups = rowGroups
+
+	if tv.ownerDataCallback == nil {
+		// Owner data list views only support groups through this
+		// undocumented callback, which must be set before enabling them.
+		tv.ownerDataCallback = newTableViewIOwnerDataCallback(tv)
+
+		for _, hwnd := range [2]win.HWND{tv.hwndFrozenLV, tv.hwndNormalLV} {
+			win.SendMessage(hwnd, _LVM_SETOWNERDATACALLBACK, uintptr(unsafe.Pointer(tv.ownerDataCallback)), 0)
+		}
 	}
 
-	tv.providedModel = mdl
-	tv.model = model
+	hwndHeader := tv.groupHeaderLV()
 
-	tv.itemChecker, _ = model.(ItemChecker)
-	tv.imageProvider, _ = model.(ImageProvider)
+	for _, hwnd := range [2]win.HWND{tv.hwndFrozenLV, tv.hwndNormalLV} {
+		if -1 == int(win.SendMessage(hwnd, win.LVM_ENABLEGROUPVIEW, 1, 0)) {
+			return newError("LVM_ENABLEGROUPVIEW failed")
+		}
 
-	if model != nil {
-		tv.attachModel()
+		win.SendMessage(hwnd, win.LVM_REMOVEALLGROUPS, 0, 0)
 
-		if dms, ok := model.(dataMembersSetter); ok {
-			// FIXME: This depends on columns to be initialized before
-			// calling this method.
-			dataMembers := make([]string, len(tv.columns.items))
+		for g, key := range groupKeys {
+			group := tv.grouper.Group(key)
 
-			for i, col := range tv.columns.items {
-				dataMembers[i] = col.DataMemberEffective()
+			if c, ok := collapsed[key]; ok {
+				group.Collapsed = c
 			}
 
-			dms.setDataMembers(dataMembers)
-		}
-
-		if lfs, ok := model.(lessFuncsSetter); ok {
-			lessFuncs := make([]func(i, j int) bool, tv.columns.Len())
-			for i, c := range tv.columns.items {
-				lessFuncs[i] = c.lessFunc
+			if hwnd != hwndHeader {
+				// The other list view only draws matching blank headers, so
+				// rows stay aligned.
+				group.Title = " "
+				if group.Subtitle != "" {
+					group.Subtitle = " "
+				}
+				group.TaskLink = ""
 			}
-			lfs.setLessFuncs(lessFuncs)
-		}
 
-		if sorter, ok := tv.model.(Sorter); ok {
-			if tv.sortedColumnIndex >= tv.visibleColumnCount() {
-				tv.sortedColumnIndex = maxi(-1, mini(0, tv.visibleColumnCount()-1))
-				tv.sortOrder = SortAscending
+			lvg := lvGroup{
+				mask:     _LVGF_HEADER | _LVGF_STATE | _LVGF_ALIGN | _LVGF_GROUPID | _LVGF_ITEMS,
+				uAlign:   _LVGA_HEADER_LEFT,
+				iGroupId: int32(g),
+				cItems:   uint32(len(groupRows[g])),
 			}
+			lvg.cbSize = uint32(unsafe.Sizeof(lvg))
 
-			sorter.Sort(tv.sortedColumnIndex, tv.sortOrder)
-		}
-	}
+			lvg.pszHeader = syscall.StringToUTF16Ptr(group.Title)
+			if group.Subtitle != "" {
+				lvg.mask |= _LVGF_SUBTITLE
+				lvg.pszSubtitle = syscall.StringToUTF16Ptr(group.Subtitle)
+			}
+			if group.TaskLink != "" {
+				lvg.mask |= _LVGF_TASK
+				lvg.pszTask = syscall.StringToUTF16Ptr(group.TaskLink)
+			}
 
-	tv.SetCurrentIndex(-1)
+			lvg.stateMask = _LVGS_COLLAPSIBLE | _LVGS_COLLAPSED
+			if group.Collapsible {
+				lvg.state |= _LVGS_COLLAPSIBLE
 
-	tv.setItemCount()
+				if group.Collapsed {
+					lvg.state |= _LVGS_COLLAPSED
+				}
+			}
 
-	tv.itemCountChangedPublisher.Publish()
+			if -1 == int(win.SendMessage(hwnd, win.LVM_INSERTGROUP, ^uintptr(0), uintptr(unsafe.Pointer(&lvg)))) {
+				return newError("LVM_INSERTGROUP failed")
+			}
+		}
+	}
 
 	return nil
 }
 
-// TableModel returns the TableModel of the TableView.
-func (tv *TableView) TableModel() TableModel {
-	return tv.model
-}
+// itemIndex returns the index by which the data source knows the item
+// displayed in row.
+func (tv *TableView) itemIndex(row int) int {
+	if indexer, ok := tv.model.(itemIndexer); ok && row >= 0 {
+		return indexer.itemIndex(row)
+	}
 
-// ItemChecker returns the ItemChecker of the TableView.
-func (tv *TableView) ItemChecker() ItemChecker {
-	return tv.itemChecker
+	return row
 }
 
-// SetItemChecker sets the ItemChecker of the TableView.
-func (tv *TableView) SetItemChecker(itemChecker ItemChecker) {
-	tv.itemChecker = itemChecker
+// itemGrouper passes the Grouper of a data source the indexes of the items a
+// model displays in the rows.
+type itemGrouper struct {
+	Grouper
+	indexer itemIndexer
 }
 
-// CellStyler returns the CellStyler of the TableView.
-func (tv *TableView) CellStyler() CellStyler {
-	return tv.styler
+func (g itemGrouper) GroupBy(row int) string {
+	return g.Grouper.GroupBy(g.indexer.itemIndex(row))
 }
 
-// SetCellStyler sets the CellStyler of the TableView.
-func (tv *TableView) SetCellStyler(styler CellStyler) {
-	tv.styler = styler
+// regroupRow moves row into the group named by its current key, after its item
+// changed. It only rebuilds all groups if that adds, removes or reorders
+// groups.
+func (tv *TableView) regroupRow(row int) error {
+	if tv.grouper == nil || row < 0 || row >= len(tv.rowGroups) {
+		return nil
+	}
+
+	from := tv.rowGroups[row]
+	key := tv.grouper.GroupBy(row)
+	if tv.groupKeys[from] == key {
+		return nil
+	}
+
+	// Groups are ordered by their first item.
+	to := int32(tv.groupIndex(key))
+	fromRows := tv.groupRows[from]
+	if to == -1 || fromRows[0] == int32(row) || tv.groupRows[to][0] > int32(row) {
+		return tv.updateGroups()
+	}
+
+	i := sort.Search(len(fromRows), func(i int) bool { return fromRows[i] >= int32(row) })
+	tv.groupRows[from] = append(fromRows[:i], fromRows[i+1:]...)
+
+	toRows := tv.groupRows[to]
+	j := sort.Search(len(toRows), func(j int) bool { return toRows[j] > int32(row) })
+	toRows = append(toRows, 0)
+	copy(toRows[j+1:], toRows[j:])
+	toRows[j] = int32(row)
+	tv.groupRows[to] = toRows
+
+	tv.rowGroups[row] = to
+
+	count := uintptr(len(tv.rowGroups))
+
+	for _, hwnd := range [2]win.HWND{tv.hwndFrozenLV, tv.hwndNormalLV} {
+		for _, g := range [2]int32{from, to} {
+			lvg := lvGroup{
+				mask:   _LVGF_ITEMS,
+				cItems: uint32(len(tv.groupRows[g])),
+			}
+			lvg.cbSize = uint32(unsafe.Sizeof(lvg))
+
+			if -1 == int(win.SendMessage(hwnd, win.LVM_SETGROUPINFO, uintptr(g), uintptr(unsafe.Pointer(&lvg)))) {
+				return newError("LVM_SETGROUPINFO failed")
+			}
+		}
+
+		// Make the list view query the items of the groups again.
+		win.SendMessage(hwnd, win.LVM_SETITEMCOUNT, count, win.LVSICF_NOSCROLL)
+	}
+
+	return nil
 }
 
-func (tv *TableView) setItemCount() error {
-	var count int
+// syncGroupStates copies the collapsed state of all groups from hwnd to
+// hwndOther, after the user toggled one in hwnd.
+func (tv *TableView) syncGroupStates(hwnd, hwndOther win.HWND) {
+	for g := range tv.groupKeys {
+		collapsed := win.SendMessage(hwnd, win.LVM_GETGROUPSTATE, uintptr(g), _LVGS_COLLAPSED) != 0
 
-	if tv.model != nil {
-		count = tv.model.RowCount()
+		if collapsed != (win.SendMessage(hwndOther, win.LVM_GETGROUPSTATE, uintptr(g), _LVGS_COLLAPSED) != 0) {
+			setLVGroupCollapsed(hwndOther, g, collapsed)
+		}
 	}
+}
 
-	if 0 == win.SendMessage(tv.hwndFrozenLV, win.LVM_SETITEMCOUNT, uintptr(count), win.LVSICF_NOINVALIDATEALL|win.LVSICF_NOSCROLL) {
-		return newError("SendMessage(LVM_SETITEMCOUNT)")
+// hitTestGroupHeader returns whether pt lies on a group header of hwnd.
+func (tv *TableView) hitTestGroupHeader(hwnd win.HWND, pt win.POINT) bool {
+	if tv.groupKeys == nil {
+		return false
 	}
-	if 0 == win.SendMessage(tv.hwndNormalLV, win.LVM_SETITEMCOUNT, uintptr(count), win.LVSICF_NOINVALIDATEALL|win.LVSICF_NOSCROLL) {
-		return newError("SendMessage(LVM_SETITEMCOUNT)")
+
+	hti := win.LVHITTESTINFO{Pt: pt}
+	win.SendMessage(hwnd, win.LVM_HITTEST, ^uintptr(0), uintptr(unsafe.Pointer(&hti)))
+
+	return hti.Flags&(_LVHT_EX_GROUP_HEADER|_LVHT_EX_GROUP_FOOTER|_LVHT_EX_GROUP_COLLAPSE) != 0
+}
+
+func setLVGroupCollapsed(hwnd win.HWND, groupId int, collapsed bool) {
+	lvg := lvGroup{
+		mask:      _LVGF_STATE,
+		stateMask: _LVGS_COLLAPSED,
+	}
+	lvg.cbSize = uint32(unsafe.Sizeof(lvg))
+	if collapsed {
+		lvg.state = _LVGS_COLLAPSED
 	}
 
-	return nil
+	win.SendMessage(hwnd, win.LVM_SETGROUPINFO, uintptr(groupId), uintptr(unsafe.Pointer(&lvg)))
 }
 
 // CheckBoxes returns if the *TableView has check boxes.
@@ -1214,7 +2249,7 @@ func (tv *TableView) SetCurrentIndex(index int) error {
 		}
 
 		if ip, ok := tv.providedModel.(IDProvider); ok && tv.restoringCurrentItemOnReset {
-			if id := ip.ID(index); id != tv.currentItemID {
+			if id := ip.ID(tv.itemIndex(index)); id != tv.currentItemID {
 				tv.currentItemID = id
 				if tv.itemStateChangedEventDelay == 0 {
 					defer tv.currentItemChangedPublisher.Publish()
@@ -1613,6 +2648,7 @@ type tableViewColumnState struct {
 	Visible      bool
 	Frozen       bool
 	LastSeenDate string
+	Filter       *ColumnFilter
 }
 
 // SaveState writes the UI state of the *TableView to the settings.
@@ -1654,34 +2690,21 @@ func (tv *TableView) SaveState() error {
 		tvcs.Visible = tvc.Visible()
 		tvcs.Frozen = tvc.Frozen()
 		tvcs.LastSeenDate = time.Now().Format("2006-01-02")
-	}
-
-	visibleCols := tv.visibleColumns()
-	frozenCount := tv.visibleFrozenColumnCount()
-	normalCount := len(visibleCols) - frozenCount
-	indices := make([]int32, len(visibleCols))
-	var lp uintptr
-	if frozenCount > 0 {
-		lp = uintptr(unsafe.Pointer(&indices[0]))
 
-		if 0 == win.SendMessage(tv.hwndFrozenLV, win.LVM_GETCOLUMNORDERARRAY, uintptr(frozenCount), lp) {
-			return newError("LVM_GETCOLUMNORDERARRAY")
+		tvcs.Filter = nil
+		if filter, ok := tv.filters[tv.columns.Index(tvc)]; ok {
+			tvcs.Filter = &filter
 		}
 	}
-	if normalCount > 0 {
-		lp = uintptr(unsafe.Pointer(&indices[frozenCount]))
 
-		if 0 == win.SendMessage(tv.hwndNormalLV, win.LVM_GETCOLUMNORDERARRAY, uintptr(normalCount), lp) {
-			return newError("LVM_GETCOLUMNORDERARRAY")
-		}
+	visibleCols := tv.VisibleColumnsInDisplayOrder()
+	if visibleCols == nil {
+		return newError("LVM_GETCOLUMNORDERARRAY")
 	}
 
 	tvs.ColumnDisplayOrder = make([]string, len(visibleCols))
-	for i, j := range indices {
-		if i >= frozenCount {
-			j += int32(frozenCount)
-		}
-		tvs.ColumnDisplayOrder[i] = visibleCols[j].name
+	for i, tvc := range visibleCols {
+		tvs.ColumnDisplayOrder[i] = tvc.name
 	}
 
 	state, err := json.Marshal(tvs)
@@ -1831,6 +2854,20 @@ func (tv *TableView) RestoreState() error {
 		sorter.Sort(tv.sortedColumnIndex, tvs.SortOrder)
 	}
 
+	filters := make(map[int]ColumnFilter)
+	for i, tvc := range tv.columns.items {
+		if tvcs := name2tvcs[tvc.name]; tvcs != nil && tvcs.Filter != nil {
+			filters[i] = *tvcs.Filter
+		}
+	}
+
+	if _, ok := tv.model.(Filterer); ok {
+		return tv.SetFilters(filters)
+	}
+
+	// Applied once a model that supports filtering is set.
+	tv.filters = filters
+
 	return nil
 }
 
@@ -1865,6 +2902,21 @@ func (tv *TableView) applyImageList() {
 	win.SendMessage(tv.hwndNormalLV, win.LVM_SETIMAGELIST, win.LVSIL_SMALL, uintptr(tv.hIml))
 }
 
+// ensureImageList makes sure the list views have an image list, which they
+// also need to indent items.
+func (tv *TableView) ensureImageList() {
+	if tv.hIml != 0 {
+		return
+	}
+
+	dpi := tv.DPI()
+
+	if bmp, err := NewBitmapForDPI(SizeFrom96DPI(Size{16, 16}, dpi), dpi); err == nil {
+		tv.applyImageListForImage(bmp)
+		bmp.Dispose()
+	}
+}
+
 func (tv *TableView) disposeImageListAndCaches() {
 	if tv.hIml != 0 && !tv.usingSysIml {
 		win.SendMessage(tv.hwndFrozenLV, win.LVM_SETIMAGELIST, win.LVSIL_SMALL, 0)
@@ -1947,6 +2999,50 @@ func tableViewNormalLVWndProc(hwnd win.HWND, msg uint32, wp, lp uintptr) uintptr
 	return result
 }
 
+// formatCellValue returns the text displayed for value in col.
+func (tv *TableView) formatCellValue(col int, value interface{}) string {
+	tvc := tv.columns.items[col]
+
+	if format := tvc.formatFunc; format != nil {
+		return format(value)
+	}
+
+	prec := tvc.precision
+	if prec == 0 {
+		prec = 2
+	}
+
+	switch val := value.(type) {
+	case string:
+		return val
+
+	case float32:
+		return FormatFloatGrouped(float64(val), prec)
+
+	case float64:
+		return FormatFloatGrouped(val, prec)
+
+	case time.Time:
+		if val.Year() > 1601 {
+			return val.Format(tvc.format)
+		}
+
+		return ""
+
+	case bool:
+		if val {
+			return checkmark
+		}
+
+		return ""
+
+	case *big.Rat:
+		return formatBigRatGrouped(val, prec)
+	}
+
+	return fmt.Sprintf(tvc.format, value)
+}
+
 func (tv *TableView) lvWndProc(origWndProcPtr uintptr, hwnd win.HWND, msg uint32, wp, lp uintptr) uintptr {
 	var hwndOther win.HWND
 	if hwnd == tv.hwndFrozenLV {
@@ -1957,6 +3053,24 @@ func (tv *TableView) lvWndProc(origWndProcPtr uintptr, hwnd win.HWND, msg uint32
 
 	var maybeStretchLastColumn bool
 
+	if tv.dragRows != nil {
+		switch msg {
+		case win.WM_MOUSEMOVE:
+			tv.dragTo(Point{int(win.GET_X_LPARAM(lp)), int(win.GET_Y_LPARAM(lp))})
+			return 0
+
+		case win.WM_LBUTTONUP:
+			tv.endDrag(true)
+			return 0
+
+		case win.WM_KEYDOWN:
+			if wp == win.VK_ESCAPE {
+				tv.endDrag(false)
+				return 0
+			}
+		}
+	}
+
 	switch msg {
 	case win.WM_ERASEBKGND:
 		maybeStretchLastColumn = true
@@ -1982,7 +3096,17 @@ func (tv *TableView) lvWndProc(origWndProcPtr uintptr, hwnd win.HWND, msg uint32
 
 		tv.itemIndexOfLastMouseButtonDown = int(hti.IItem)
 
-		if hti.Flags == win.LVHT_NOWHERE {
+		if (msg == win.WM_LBUTTONDOWN || msg == win.WM_LBUTTONDBLCLK) &&
+			tv.tree != nil && tv.tree.handleGlyphClick(hwnd, int(hti.IItem), hti.Pt) {
+
+			return 0
+		}
+
+		if msg == win.WM_LBUTTONDBLCLK && tv.beginEditAt(hwnd, lp) {
+			return 0
+		}
+
+		if hti.Flags == win.LVHT_NOWHERE && !tv.hitTestGroupHeader(hwnd, hti.Pt) {
 			if tv.MultiSelection() {
 				tv.publishNextSelClear = true
 			} else {
@@ -2022,6 +3146,9 @@ func (tv *TableView) lvWndProc(origWndProcPtr uintptr, hwnd win.HWND, msg uint32
 	case win.WM_LBUTTONUP, win.WM_RBUTTONUP:
 		tv.itemIndexOfLastMouseButtonDown = -1
 
+	case win.WM_CAPTURECHANGED:
+		tv.endDrag(false)
+
 	case win.WM_MOUSEMOVE, win.WM_MOUSELEAVE:
 		if tv.inMouseEvent {
 			break
@@ -2039,7 +3166,24 @@ func (tv *TableView) lvWndProc(origWndProcPtr uintptr, hwnd win.HWND, msg uint32
 		win.SendMessage(hwndOther, msg, wp, lp)
 
 	case win.WM_KEYDOWN:
+		if wp == win.VK_F2 && tv.currentIndex > -1 {
+			if cols := tv.editableColumns(); len(cols) > 0 {
+				tv.BeginEdit(tv.currentIndex, cols[0])
+				return 0
+			}
+		}
+
+		if tv.tree != nil && tv.tree.handleKeyDown(wp) {
+			return 0
+		}
+
+		if wp == 'C' && ControlDown() && !ShiftDown() && !AltDown() && tv.CopyShortcutEnabled() {
+			tv.CopySelectionToClipboard()
+			return 0
+		}
+
 		if wp == win.VK_SPACE &&
+			!tv.search.active() &&
 			tv.currentIndex > -1 &&
 			tv.itemChecker != nil &&
 			tv.CheckBoxes() {
@@ -2052,6 +3196,11 @@ func (tv *TableView) lvWndProc(origWndProcPtr uintptr, hwnd win.HWND, msg uint32
 	case win.WM_KEYUP:
 		tv.handleKeyUp(wp, lp)
 
+	case win.WM_CHAR:
+		if tv.handleSearchChar(rune(wp)) {
+			return 0
+		}
+
 	case win.WM_NOTIFY:
 		nmh := ((*win.NMHDR)(unsafe.Pointer(lp)))
 		switch nmh.HwndFrom {
@@ -2072,50 +3221,7 @@ func (tv *TableView) lvWndProc(origWndProcPtr uintptr, hwnd win.HWND, msg uint32
 			}
 
 			if di.Item.Mask&win.LVIF_TEXT > 0 {
-				value := tv.model.Value(row, col)
-				var text string
-				if format := tv.columns.items[col].formatFunc; format != nil {
-					text = format(value)
-				} else {
-					switch val := value.(type) {
-					case string:
-						text = val
-
-					case float32:
-						prec := tv.columns.items[col].precision
-						if prec == 0 {
-							prec = 2
-						}
-						text = FormatFloatGrouped(float64(val), prec)
-
-					case float64:
-						prec := tv.columns.items[col].precision
-						if prec == 0 {
-							prec = 2
-						}
-						text = FormatFloatGrouped(val, prec)
-
-					case time.Time:
-						if val.Year() > 1601 {
-							text = val.Format(tv.columns.items[col].format)
-						}
-
-					case bool:
-						if val {
-							text = checkmark
-						}
-
-					case *big.Rat:
-						prec := tv.columns.items[col].precision
-						if prec == 0 {
-							prec = 2
-						}
-						text = formatBigRatGrouped(val, prec)
-
-					default:
-						text = fmt.Sprintf(tv.columns.items[col].format, val)
-					}
-				}
+				text := tv.formatCellValue(col, tv.model.Value(row, col))
 
 				utf16 := syscall.StringToUTF16(text)
 				buf := (*[264]uint16)(unsafe.Pointer(di.Item.PszText))
@@ -2124,6 +3230,11 @@ func (tv *TableView) lvWndProc(origWndProcPtr uintptr, hwnd win.HWND, msg uint32
 				(*buf)[max-1] = 0
 			}
 
+			if tv.tree != nil && col == 0 && di.Item.ISubItem == 0 && di.Item.Mask&win.LVIF_INDENT > 0 {
+				// One more level leaves room for the expand glyph.
+				di.Item.IIndent = int32(tv.tree.rows.levels[row] + 1)
+			}
+
 			if (tv.imageProvider != nil || tv.styler != nil) && di.Item.Mask&win.LVIF_IMAGE > 0 {
 				var image interface{}
 				if di.Item.ISubItem == 0 {
@@ -2132,7 +3243,7 @@ func (tv *TableView) lvWndProc(origWndProcPtr uintptr, hwnd win.HWND, msg uint32
 					}
 				}
 				if styler := tv.styler; styler != nil && image == nil {
-					tv.style.row = row
+					tv.style.row = tv.itemIndex(row)
 					tv.style.col = col
 					tv.style.bounds = Rectangle{}
 					tv.style.dpi = tv.DPI()
@@ -2183,7 +3294,7 @@ func (tv *TableView) lvWndProc(origWndProcPtr uintptr, hwnd win.HWND, msg uint32
 					if tv.styler != nil {
 						dpi := tv.DPI()
 
-						tv.style.row = row
+						tv.style.row = tv.itemIndex(row)
 						tv.style.col = col
 						tv.style.bounds = rectangleFromRECT(nmlvcd.Nmcd.Rc)
 						tv.style.dpi = dpi
@@ -2246,7 +3357,7 @@ func (tv *TableView) lvWndProc(origWndProcPtr uintptr, hwnd win.HWND, msg uint32
 					tv.style.TextColor = tv.itemTextColor
 
 					if tv.styler != nil {
-						tv.style.row = row
+						tv.style.row = tv.itemIndex(row)
 						tv.style.col = -1
 						tv.style.bounds = rectangleFromRECT(nmlvcd.Nmcd.Rc)
 						tv.style.dpi = tv.DPI()
@@ -2300,7 +3411,17 @@ func (tv *TableView) lvWndProc(origWndProcPtr uintptr, hwnd win.HWND, msg uint32
 					return win.CDRF_NEWFONT | win.CDRF_SKIPPOSTPAINT | win.CDRF_NOTIFYPOSTPAINT
 
 				case win.CDDS_ITEMPOSTPAINT | win.CDDS_SUBITEM:
-					if applyCellStyle() == win.CDRF_SKIPDEFAULT {
+					ret := applyCellStyle()
+
+					if row == tv.searchRow && col == tv.searchColumnEffective() {
+						tv.drawSearchMatch(hwnd, nmlvcd, row, col)
+					}
+
+					if tv.tree != nil && col == 0 {
+						tv.tree.drawGlyph(hwnd, nmlvcd.Nmcd.Hdc, row)
+					}
+
+					if ret == win.CDRF_SKIPDEFAULT {
 						return win.CDRF_SKIPDEFAULT
 					}
 
@@ -2313,6 +3434,8 @@ func (tv *TableView) lvWndProc(origWndProcPtr uintptr, hwnd win.HWND, msg uint32
 			return win.CDRF_SKIPPOSTPAINT
 
 		case win.LVN_BEGINSCROLL:
+			tv.endEdit()
+
 			if tv.scrolling {
 				break
 			}
@@ -2327,6 +3450,13 @@ func (tv *TableView) lvWndProc(origWndProcPtr uintptr, hwnd win.HWND, msg uint32
 			nmlvs := (*win.NMLVSCROLL)(unsafe.Pointer(lp))
 			win.SendMessage(hwndOther, win.LVM_SCROLL, 0, uintptr(nmlvs.Dy*(rc.Bottom-rc.Top)))
 
+		case win.LVN_BEGINDRAG:
+			if tv.reorderable {
+				nmlv := (*win.NMLISTVIEW)(unsafe.Pointer(lp))
+
+				tv.beginDrag(hwnd, int(nmlv.IItem), Point{int(nmlv.PtAction.X), int(nmlv.PtAction.Y)})
+			}
+
 		case win.LVN_COLUMNCLICK:
 			nmlv := (*win.NMLISTVIEW)(unsafe.Pointer(lp))
 
@@ -2421,7 +3551,38 @@ func (tv *TableView) lvWndProc(origWndProcPtr uintptr, hwnd win.HWND, msg uint32
 
 			tv.itemActivatedPublisher.Publish()
 
+		case _LVN_COLUMNDROPDOWN:
+			nmlv := (*win.NMLISTVIEW)(unsafe.Pointer(lp))
+
+			col := tv.fromLVColIdx(hwnd == tv.hwndFrozenLV, nmlv.ISubItem)
+
+			hwndHdr := tv.hwndNormalHdr
+			if hwnd == tv.hwndFrozenLV {
+				hwndHdr = tv.hwndFrozenHdr
+			}
+
+			var rc win.RECT
+			win.SendMessage(hwndHdr, win.HDM_GETITEMDROPDOWNRECT, uintptr(nmlv.ISubItem), uintptr(unsafe.Pointer(&rc)))
+
+			topLeft := win.POINT{rc.Left, rc.Top}
+			win.ClientToScreen(hwndHdr, &topLeft)
+			anchor := Rectangle{int(topLeft.X), int(topLeft.Y), int(rc.Right - rc.Left), int(rc.Bottom - rc.Top)}
+
+			// Run the pop-up after the header is done with the click.
+			tv.Synchronize(func() {
+				tv.showFilterPopup(col, anchor)
+			})
+
+		case _LVN_LINKCLICK:
+			nmlvl := (*nmLVLink)(unsafe.Pointer(lp))
+
+			// For group task links, iSubItem holds the group id.
+			if g := int(nmlvl.iSubItem); g >= 0 && g < len(tv.groupKeys) {
+				tv.groupTaskLinkClickedPublisher.Publish(tv.groupKeys[g])
+			}
+
 		case win.HDN_ITEMCHANGING:
+			tv.endEdit()
 			tv.updateLVSizes()
 		}
 
@@ -2483,6 +3644,18 @@ func (tv *TableView) lvWndProc(origWndProcPtr uintptr, hwnd win.HWND, msg uint32
 		}
 	}
 
+	if tv.groupKeys != nil {
+		switch msg {
+		case win.WM_LBUTTONDOWN, win.WM_LBUTTONDBLCLK, win.WM_KEYDOWN:
+			// The user may have collapsed or expanded a group.
+			result := win.CallWindowProc(origWndProcPtr, hwnd, msg, wp, lp)
+
+			tv.syncGroupStates(hwnd, hwndOther)
+
+			return result
+		}
+	}
+
 	return win.CallWindowProc(origWndProcPtr, hwnd, msg, wp, lp)
 }
 
@@ -2559,7 +3732,7 @@ func tableViewHdrWndProc(hwnd win.HWND, msg uint32, wp, lp uintptr) uintptr {
 		hti := win.HDHITTESTINFO{Pt: win.POINT{int32(win.GET_X_LPARAM(lp)), int32(win.GET_Y_LPARAM(lp))}}
 		win.SendMessage(hwnd, win.HDM_HITTEST, 0, uintptr(unsafe.Pointer(&hti)))
 		if hti.IItem == -1 {
-			App().toolTip().setText(hwnd, "")
+			App().ToolTip().setText(hwnd, "")
 			break
 		}
 
@@ -2568,21 +3741,21 @@ func tableViewHdrWndProc(hwnd win.HWND, msg uint32, wp, lp uintptr) uintptr {
 
 		var rc win.RECT
 		if 0 == win.SendMessage(hwnd, win.HDM_GETITEMRECT, uintptr(hti.IItem), uintptr(unsafe.Pointer(&rc))) {
-			App().toolTip().setText(hwnd, "")
+			App().ToolTip().setText(hwnd, "")
 			break
 		}
 
 		size := calculateTextSize(text, tv.Font(), tv.DPI(), 0, hwnd)
 		if size.Width <= rectangleFromRECT(rc).Width-int(win.SendMessage(hwnd, win.HDM_GETBITMAPMARGIN, 0, 0)) {
-			App().toolTip().setText(hwnd, "")
+			App().ToolTip().setText(hwnd, "")
 			break
 		}
 
-		if App().toolTip().text(hwnd) == text {
+		if App().ToolTip().text(hwnd) == text {
 			break
 		}
 
-		App().toolTip().setText(hwnd, text)
+		App().ToolTip().setText(hwnd, text)
 
 		m := win.MSG{
 			HWnd:    hwnd,
@@ -2592,7 +3765,7 @@ func tableViewHdrWndProc(hwnd win.HWND, msg uint32, wp, lp uintptr) uintptr {
 			Pt:      hti.Pt,
 		}
 
-		App().toolTip().SendMessage(win.TTM_RELAYEVENT, 0, uintptr(unsafe.Pointer(&m)))
+		App().ToolTip().SendMessage(win.TTM_RELAYEVENT, 0, uintptr(unsafe.Pointer(&m)))
 	}
 
 	return win.CallWindowProc(origWndProcPtr, hwnd, msg, wp, lp)
@@ -2600,6 +3773,12 @@ func tableViewHdrWndProc(hwnd win.HWND, msg uint32, wp, lp uintptr) uintptr {
 
 func (tv *TableView) WndProc(hwnd win.HWND, msg uint32, wp, lp uintptr) uintptr {
 	switch msg {
+	case win.WM_COMMAND:
+		if tv.editor != nil && win.HWND(lp) == tv.editor.Handle() {
+			// Notification from the in-cell editor
+			return tv.editor.WndProc(hwnd, msg, wp, lp)
+		}
+
 	case win.WM_NOTIFY:
 		nmh := (*win.NMHDR)(unsafe.Pointer(lp))
 		switch nmh.HwndFrom {
@@ -2610,6 +3789,11 @@ func (tv *TableView) WndProc(hwnd win.HWND, msg uint32, wp, lp uintptr) uintptr
 			return tableViewNormalLVWndProc(nmh.HwndFrom, msg, wp, lp)
 		}
 
+		if tv.editor != nil && nmh.HwndFrom == tv.editor.Handle() {
+			// Notification from the in-cell editor
+			return tv.editor.WndProc(hwnd, msg, wp, lp)
+		}
+
 	case win.WM_WINDOWPOSCHANGED:
 		wp := (*win.WINDOWPOS)(unsafe.Pointer(lp))
 
@@ -2617,6 +3801,8 @@ func (tv *TableView) WndProc(hwnd win.HWND, msg uint32, wp, lp uintptr) uintptr
 			break
 		}
 
+		tv.endEdit()
+
 		if tv.formActivatingHandle == -1 {
 			if form := tv.Form(); form != nil {
 				tv.formActivatingHandle = form.Activating().Attach(func() {
@@ -2647,6 +3833,12 @@ func (tv *TableView) WndProc(hwnd win.HWND, msg uint32, wp, lp uintptr) uintptr
 		tv.redrawItems()
 
 	case win.WM_TIMER:
+		if wp == tableViewDragTimerId {
+			// Fires repeatedly until the drag ends.
+			tv.onDragTimer()
+			return 0
+		}
+
 		if !win.KillTimer(tv.hWnd, wp) {
 			lastError("KillTimer")
 		}
@@ -2660,6 +3852,11 @@ func (tv *TableView) WndProc(hwnd win.HWND, msg uint32, wp, lp uintptr) uintptr
 
 		case tableViewSelectedIndexesChangedTimerId:
 			tv.selectedIndexesChangedPublisher.Publish()
+
+		case tableViewItemSearchTimerId:
+			row := tv.searchRow
+			tv.searchRow = -1
+			tv.redrawRow(row)
 		}
 
 	case win.WM_MEASUREITEM:
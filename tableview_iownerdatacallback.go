@@ -0,0 +1,139 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"syscall"
+	"unsafe"
+
+	"github.com/tailscale/win"
+)
+
+// IOwnerDataCallback is the undocumented interface through which a list view
+// with LVS_OWNERDATA asks which items belong to which group. Without it,
+// virtual list views do not support groups.
+var iid_IOwnerDataCallback = win.IID{0x44C09D56, 0x8D3B, 0x419D, [8]byte{0xA4, 0x62, 0x7B, 0x95, 0x6B, 0x10, 0x5B, 0x47}}
+
+type iOwnerDataCallbackVtbl struct {
+	QueryInterface    uintptr
+	AddRef            uintptr
+	Release           uintptr
+	GetItemPosition   uintptr
+	SetItemPosition   uintptr
+	GetItemInGroup    uintptr
+	GetItemGroup      uintptr
+	GetItemGroupCount uintptr
+	OnCacheHint       uintptr
+}
+
+var tableViewIOwnerDataCallbackVtbl *iOwnerDataCallbackVtbl
+
+func init() {
+	AppendToWalkInit(func() {
+		// SetItemPosition and OnCacheHint take structs by value, which are
+		// passed as two stack slots each on 386.
+		setItemPosition := syscall.NewCallback(tableView_IOwnerDataCallback_SetItemPosition)
+		onCacheHint := syscall.NewCallback(tableView_IOwnerDataCallback_OnCacheHint)
+		if unsafe.Sizeof(uintptr(0)) == 4 {
+			setItemPosition = syscall.NewCallback(tableView_IOwnerDataCallback_SetItemPosition32)
+			onCacheHint = syscall.NewCallback(tableView_IOwnerDataCallback_OnCacheHint32)
+		}
+
+		tableViewIOwnerDataCallbackVtbl = &iOwnerDataCallbackVtbl{
+			syscall.NewCallback(tableView_IOwnerDataCallback_QueryInterface),
+			syscall.NewCallback(tableView_IOwnerDataCallback_AddRef),
+			syscall.NewCallback(tableView_IOwnerDataCallback_Release),
+			syscall.NewCallback(tableView_IOwnerDataCallback_GetItemPosition),
+			setItemPosition,
+			syscall.NewCallback(tableView_IOwnerDataCallback_GetItemInGroup),
+			syscall.NewCallback(tableView_IOwnerDataCallback_GetItemGroup),
+			syscall.NewCallback(tableView_IOwnerDataCallback_GetItemGroupCount),
+			onCacheHint,
+		}
+	})
+}
+
+type tableViewIOwnerDataCallback struct {
+	lpVtbl *iOwnerDataCallbackVtbl
+	tv     *TableView
+}
+
+func newTableViewIOwnerDataCallback(tv *TableView) *tableViewIOwnerDataCallback {
+	return &tableViewIOwnerDataCallback{tableViewIOwnerDataCallbackVtbl, tv}
+}
+
+func tableView_IOwnerDataCallback_QueryInterface(cb *tableViewIOwnerDataCallback, riid win.REFIID, ppvObject *unsafe.Pointer) uintptr {
+	if win.EqualREFIID(riid, &win.IID_IUnknown) || win.EqualREFIID(riid, &iid_IOwnerDataCallback) {
+		*ppvObject = unsafe.Pointer(cb)
+		return win.S_OK
+	}
+
+	*ppvObject = nil
+	return win.E_NOINTERFACE
+}
+
+func tableView_IOwnerDataCallback_AddRef(cb *tableViewIOwnerDataCallback) uintptr {
+	return 1
+}
+
+func tableView_IOwnerDataCallback_Release(cb *tableViewIOwnerDataCallback) uintptr {
+	return 1
+}
+
+func tableView_IOwnerDataCallback_GetItemPosition(cb *tableViewIOwnerDataCallback, itemIndex uintptr, position *win.POINT) uintptr {
+	return win.E_NOTIMPL
+}
+
+func tableView_IOwnerDataCallback_SetItemPosition(cb *tableViewIOwnerDataCallback, itemIndex, position uintptr) uintptr {
+	return win.E_NOTIMPL
+}
+
+func tableView_IOwnerDataCallback_SetItemPosition32(cb *tableViewIOwnerDataCallback, itemIndex, x, y uintptr) uintptr {
+	return win.E_NOTIMPL
+}
+
+func tableView_IOwnerDataCallback_GetItemInGroup(cb *tableViewIOwnerDataCallback, groupIndex, groupWideItemIndex uintptr, totalItemIndex *int32) uintptr {
+	g, i := int(int32(groupIndex)), int(int32(groupWideItemIndex))
+
+	groupRows := cb.tv.groupRows
+	if g < 0 || g >= len(groupRows) || i < 0 || i >= len(groupRows[g]) {
+		return win.E_INVALIDARG
+	}
+
+	*totalItemIndex = groupRows[g][i]
+
+	return win.S_OK
+}
+
+func tableView_IOwnerDataCallback_GetItemGroup(cb *tableViewIOwnerDataCallback, itemIndex, occurrenceIndex uintptr, groupIndex *int32) uintptr {
+	row := int(int32(itemIndex))
+
+	rowGroups := cb.tv.rowGroups
+	if row < 0 || row >= len(rowGroups) {
+		return win.E_INVALIDARG
+	}
+
+	*groupIndex = rowGroups[row]
+
+	return win.S_OK
+}
+
+func tableView_IOwnerDataCallback_GetItemGroupCount(cb *tableViewIOwnerDataCallback, itemIndex uintptr, occurrenceCount *int32) uintptr {
+	// Each item belongs to exactly one group.
+	*occurrenceCount = 1
+
+	return win.S_OK
+}
+
+func tableView_IOwnerDataCallback_OnCacheHint(cb *tableViewIOwnerDataCallback, firstItem, lastItem uintptr) uintptr {
+	return win.S_OK
+}
+
+func tableView_IOwnerDataCallback_OnCacheHint32(cb *tableViewIOwnerDataCallback, firstItem0, firstItem1, lastItem0, lastItem1 uintptr) uintptr {
+	return win.S_OK
+}
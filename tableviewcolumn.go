@@ -26,6 +26,7 @@ type TableViewColumn struct {
 	width         int
 	lessFunc      func(i, j int) bool
 	formatFunc    func(value interface{}) string
+	editor        CellEditor
 	visible       bool
 	frozen        bool
 }
@@ -274,6 +275,7 @@ func (tvc *TableViewColumn) SetFrozen(frozen bool) (err error) {
 			tvc.tv.hasFrozenColumn = tvc.tv.visibleFrozenColumnCount() > 0
 			tvc.tv.SetCheckBoxes(checkBoxes)
 			tvc.tv.applyImageList()
+			tvc.tv.updateGroups()
 		}
 	}()
 
@@ -353,6 +355,19 @@ func (tvc *TableViewColumn) SetFormatFunc(formatFunc func(value interface{}) str
 	tvc.formatFunc = formatFunc
 }
 
+// Editor returns the CellEditor used to edit the cells of this
+// TableViewColumn, or nil if they are not editable.
+func (tvc *TableViewColumn) Editor() CellEditor {
+	return tvc.editor
+}
+
+// SetEditor sets the CellEditor used to edit the cells of this
+// TableViewColumn. The model of the TableView must implement CellValueSetter
+// for editing to work.
+func (tvc *TableViewColumn) SetEditor(editor CellEditor) {
+	tvc.editor = editor
+}
+
 func (tvc *TableViewColumn) indexInListView() int32 {
 	if tvc.tv == nil {
 		return -1
@@ -463,6 +478,10 @@ func (tvc *TableViewColumn) getLVCOLUMN() *win.LVCOLUMN {
 		lvc.Fmt = 1
 	}
 
+	if tvc.tv != nil && tvc.tv.columnFilterable(tvc.tv.columns.Index(tvc)) {
+		lvc.Fmt |= _LVCFMT_SPLITBUTTON
+	}
+
 	return &lvc
 }
 
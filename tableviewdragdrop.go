@@ -0,0 +1,278 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"sort"
+	"unsafe"
+
+	"github.com/tailscale/win"
+)
+
+const tableViewDragTimerElapse = 100 // in milliseconds
+
+// Reorderable returns whether the user can move items by drag and drop.
+func (tv *TableView) Reorderable() bool {
+	return tv.reorderable
+}
+
+// SetReorderable sets whether the user can move items by drag and drop. This
+// requires the model to implement RowMover.
+//
+// Dragging a selected item moves all selected items. While dragging, the
+// TableView scrolls when the cursor is near its top or bottom edge.
+func (tv *TableView) SetReorderable(reorderable bool) {
+	tv.reorderable = reorderable
+}
+
+func (tv *TableView) beginDrag(hwnd win.HWND, row int, p Point) {
+	if _, ok := tv.model.(RowMover); !ok {
+		return
+	}
+
+	tv.endEdit()
+
+	rows := tv.SelectedIndexes()
+	sort.Ints(rows)
+	if i := sort.SearchInts(rows, row); i == len(rows) || rows[i] != row {
+		rows = []int{row}
+	}
+
+	// The drag image is positioned relative to the TableView, which contains
+	// both list views.
+	var wr win.RECT
+	win.GetWindowRect(tv.hWnd, &wr)
+	var origin win.POINT
+	win.ClientToScreen(hwnd, &origin)
+	tv.dragOffset = Point{int(origin.X - wr.Left), int(origin.Y - wr.Top)}
+
+	var upperLeft win.POINT
+	tv.hDragIml = win.HIMAGELIST(win.SendMessage(hwnd, win.LVM_CREATEDRAGIMAGE, uintptr(row), uintptr(unsafe.Pointer(&upperLeft))))
+	if tv.hDragIml != 0 {
+		imageList_BeginDrag(tv.hDragIml, 0, int32(p.X)-upperLeft.X, int32(p.Y)-upperLeft.Y)
+		imageList_DragEnter(tv.hWnd, int32(p.X+tv.dragOffset.X), int32(p.Y+tv.dragOffset.Y))
+	}
+
+	tv.dragRows = rows
+	tv.dragHWnd = hwnd
+	tv.dropIndex = -1
+	tv.dropValid = false
+
+	win.SetCapture(hwnd)
+	win.SetTimer(tv.hWnd, tableViewDragTimerId, tableViewDragTimerElapse, 0)
+}
+
+func (tv *TableView) endDrag(drop bool) {
+	rows := tv.dragRows
+	if rows == nil {
+		return
+	}
+
+	// ReleaseCapture sends WM_CAPTURECHANGED, which must not end the drag
+	// again.
+	tv.dragRows = nil
+
+	win.KillTimer(tv.hWnd, tableViewDragTimerId)
+
+	if tv.hDragIml != 0 {
+		imageList_DragLeave(tv.hWnd)
+		imageList_EndDrag()
+		win.ImageList_Destroy(tv.hDragIml)
+		tv.hDragIml = 0
+	}
+
+	tv.setInsertMark(-1)
+
+	win.ReleaseCapture()
+
+	if !drop || !tv.dropValid {
+		return
+	}
+
+	mover, ok := tv.model.(RowMover)
+	if !ok {
+		return
+	}
+
+	index := tv.dropIndex
+
+	if err := mover.MoveRows(rows, index); err != nil {
+		return
+	}
+
+	// Keep the moved items selected.
+	first := index
+	for _, row := range rows {
+		if row < index {
+			first--
+		}
+	}
+
+	moved := make([]int, len(rows))
+	for i := range moved {
+		moved[i] = first + i
+	}
+
+	tv.SetSelectedIndexes(moved)
+	tv.EnsureItemVisible(first)
+}
+
+// dragTo updates the drag image and the drop index for the cursor at p, in
+// client coordinates of the list view the drag started in.
+func (tv *TableView) dragTo(p Point) {
+	if tv.hDragIml != 0 {
+		imageList_DragMove(int32(p.X+tv.dragOffset.X), int32(p.Y+tv.dragOffset.Y))
+	}
+
+	index := tv.dropIndexAt(p.Y)
+
+	valid := false
+	if index > -1 && !tv.dropIsNoOp(index) {
+		if mover, ok := tv.model.(RowMover); ok {
+			valid = mover.CanMoveRows(tv.dragRows, index)
+		}
+	}
+
+	if index != tv.dropIndex || valid != tv.dropValid {
+		tv.dropIndex, tv.dropValid = index, valid
+
+		tv.withDragImageHidden(func() {
+			if valid {
+				tv.setInsertMark(index)
+			} else {
+				tv.setInsertMark(-1)
+			}
+		})
+	}
+
+	if valid {
+		win.SetCursor(CursorArrow().handle())
+	} else {
+		win.SetCursor(CursorNo().handle())
+	}
+}
+
+// dropIndexAt returns the index dragged items would be moved to if dropped
+// at y, or -1.
+func (tv *TableView) dropIndexAt(y int) int {
+	count := tv.model.RowCount()
+
+	// With full row select, any x within the row hits the item.
+	hti := win.LVHITTESTINFO{Pt: win.POINT{1, int32(y)}}
+	row := int(win.SendMessage(tv.dragHWnd, win.LVM_HITTEST, 0, uintptr(unsafe.Pointer(&hti))))
+	if row < 0 || row >= count {
+		if count > 0 {
+			var rc win.RECT
+			rc.Left = win.LVIR_BOUNDS
+			if 0 != win.SendMessage(tv.dragHWnd, win.LVM_GETITEMRECT, uintptr(count-1), uintptr(unsafe.Pointer(&rc))) && int32(y) >= rc.Bottom {
+				// Below the last item.
+				return count
+			}
+		}
+
+		return -1
+	}
+
+	var rc win.RECT
+	rc.Left = win.LVIR_BOUNDS
+	if 0 == win.SendMessage(tv.dragHWnd, win.LVM_GETITEMRECT, uintptr(row), uintptr(unsafe.Pointer(&rc))) {
+		return -1
+	}
+
+	// The upper half of an item inserts before it, the lower half after it.
+	if int32(y) >= rc.Top+(rc.Bottom-rc.Top)/2 {
+		return row + 1
+	}
+
+	return row
+}
+
+// dropIsNoOp returns whether dropping at index would leave all items where
+// they are.
+func (tv *TableView) dropIsNoOp(index int) bool {
+	rows := tv.dragRows
+
+	if rows[len(rows)-1]-rows[0] != len(rows)-1 {
+		// Not contiguous, so they will be joined.
+		return false
+	}
+
+	return index >= rows[0] && index <= rows[len(rows)-1]+1
+}
+
+// setInsertMark displays the insertion marker before the item at index, or
+// after the last item if index equals the item count. -1 removes the marker.
+func (tv *TableView) setInsertMark(index int) {
+	mark := lvInsertMark{iItem: int32(index)}
+	mark.cbSize = uint32(unsafe.Sizeof(mark))
+
+	if count := tv.model.RowCount(); index > 0 && index == count {
+		mark.iItem = int32(count - 1)
+		mark.dwFlags = _LVIM_AFTER
+	}
+
+	win.SendMessage(tv.hwndFrozenLV, win.LVM_SETINSERTMARK, 0, uintptr(unsafe.Pointer(&mark)))
+	win.SendMessage(tv.hwndNormalLV, win.LVM_SETINSERTMARK, 0, uintptr(unsafe.Pointer(&mark)))
+}
+
+// withDragImageHidden calls f with the drag image hidden, so it does not
+// leave traces when f repaints.
+func (tv *TableView) withDragImageHidden(f func()) {
+	if tv.hDragIml != 0 {
+		imageList_DragShowNolock(false)
+		defer imageList_DragShowNolock(true)
+	}
+
+	f()
+}
+
+// onDragTimer scrolls while dragging near the top or bottom edge.
+func (tv *TableView) onDragTimer() {
+	var pt win.POINT
+	win.GetCursorPos(&pt)
+	win.ScreenToClient(tv.dragHWnd, &pt)
+
+	var rc win.RECT
+	rc.Left = win.LVIR_BOUNDS
+	top := win.SendMessage(tv.dragHWnd, win.LVM_GETTOPINDEX, 0, 0)
+	win.SendMessage(tv.dragHWnd, win.LVM_GETITEMRECT, top, uintptr(unsafe.Pointer(&rc)))
+	itemHeight := rc.Bottom - rc.Top
+	if itemHeight <= 0 {
+		return
+	}
+
+	var cr win.RECT
+	win.GetClientRect(tv.dragHWnd, &cr)
+
+	// The header covers the top of the client area.
+	hwndHdr := win.HWND(win.SendMessage(tv.dragHWnd, win.LVM_GETHEADER, 0, 0))
+	if win.IsWindowVisible(hwndHdr) {
+		var hr win.RECT
+		win.GetWindowRect(hwndHdr, &hr)
+		cr.Top += hr.Bottom - hr.Top
+	}
+
+	var dy int32
+	if pt.Y < cr.Top+itemHeight {
+		dy = -itemHeight
+	} else if pt.Y >= cr.Bottom-itemHeight {
+		dy = itemHeight
+	}
+
+	if dy == 0 {
+		return
+	}
+
+	tv.withDragImageHidden(func() {
+		win.SendMessage(tv.hwndFrozenLV, win.LVM_SCROLL, 0, uintptr(dy))
+		win.SendMessage(tv.hwndNormalLV, win.LVM_SCROLL, 0, uintptr(dy))
+		win.UpdateWindow(tv.hWnd)
+	})
+
+	tv.dragTo(Point{int(pt.X), int(pt.Y)})
+}
@@ -0,0 +1,138 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"bytes"
+	"encoding/csv"
+	"html"
+	"io"
+	"sort"
+	"strings"
+)
+
+// CopySelectionToClipboard copies the selected items to the clipboard, both
+// as tab separated text and as HTML table, so they can be pasted into
+// spreadsheets and documents. The first line holds the column titles.
+//
+// Only visible columns are copied, in the order they are displayed, and
+// values are formatted the way they are displayed.
+//
+// Unless disabled using SetCopyShortcutEnabled, the user can also copy the
+// selection by pressing Ctrl+C.
+func (tv *TableView) CopySelectionToClipboard() error {
+	rows := tv.SelectedIndexes()
+	if len(rows) == 0 && tv.currentIndex > -1 {
+		rows = []int{tv.currentIndex}
+	}
+	sort.Ints(rows)
+
+	records, err := tv.exportRecords(rows)
+	if err != nil {
+		return err
+	}
+
+	var text bytes.Buffer
+	cw := csv.NewWriter(&text)
+	cw.Comma = '\t'
+	cw.UseCRLF = true
+	if err := cw.WriteAll(records); err != nil {
+		return err
+	}
+
+	var table strings.Builder
+	table.WriteString("<table>")
+	for i, record := range records {
+		tag := "td"
+		if i == 0 {
+			tag = "th"
+		}
+
+		table.WriteString("<tr>")
+		for _, field := range record {
+			table.WriteString("<" + tag + ">" + html.EscapeString(field) + "</" + tag + ">")
+		}
+		table.WriteString("</tr>")
+	}
+	table.WriteString("</table>")
+
+	return Clipboard().setTextAndHTML(text.String(), table.String())
+}
+
+// ExportCSV writes all items to w as comma separated values, with the column
+// titles in the first line.
+//
+// Only visible columns are written, in the order they are displayed, and
+// values are formatted the way they are displayed. Items hidden by filters
+// are not written.
+func (tv *TableView) ExportCSV(w io.Writer) error {
+	var count int
+	if tv.model != nil {
+		count = tv.model.RowCount()
+	}
+
+	rows := make([]int, count)
+	for i := range rows {
+		rows[i] = i
+	}
+
+	records, err := tv.exportRecords(rows)
+	if err != nil {
+		return err
+	}
+
+	return csv.NewWriter(w).WriteAll(records)
+}
+
+// CopyShortcutEnabled returns whether pressing Ctrl+C copies the selected
+// items to the clipboard.
+func (tv *TableView) CopyShortcutEnabled() bool {
+	return !tv.copyShortcutDisabled
+}
+
+// SetCopyShortcutEnabled sets whether pressing Ctrl+C copies the selected
+// items to the clipboard.
+func (tv *TableView) SetCopyShortcutEnabled(enabled bool) {
+	tv.copyShortcutDisabled = !enabled
+}
+
+// exportRecords returns the column titles followed by the displayed texts of
+// rows, for the visible columns in display order.
+func (tv *TableView) exportRecords(rows []int) ([][]string, error) {
+	cols := tv.VisibleColumnsInDisplayOrder()
+	if cols == nil {
+		return nil, newError("LVM_GETCOLUMNORDERARRAY")
+	}
+
+	records := make([][]string, 0, len(rows)+1)
+
+	header := make([]string, len(cols))
+	for i, tvc := range cols {
+		header[i] = tvc.TitleEffective()
+	}
+	records = append(records, header)
+
+	if tv.model == nil {
+		return records, nil
+	}
+
+	indexes := make([]int, len(cols))
+	for i, tvc := range cols {
+		indexes[i] = tv.columns.Index(tvc)
+	}
+
+	for _, row := range rows {
+		record := make([]string, len(cols))
+		for i, col := range indexes {
+			record[i] = tv.formatCellValue(col, tv.model.Value(row, col))
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
@@ -0,0 +1,265 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/tailscale/win"
+)
+
+const (
+	tableViewFilterPopupWidth96dpi  = 240
+	tableViewFilterPopupHeight96dpi = 320
+)
+
+// ColumnFilter restricts the items a TableView displays to those whose value
+// in a column passes it. Values are compared by their text as displayed in the
+// column.
+type ColumnFilter struct {
+	// Values lists the texts of the values to show. If nil, all values pass.
+	Values []string
+
+	// Text, if not empty, must be contained in the text of a value, ignoring
+	// case.
+	Text string
+}
+
+// Active returns whether the filter restricts the values.
+func (f ColumnFilter) Active() bool {
+	return f.Values != nil || f.Text != ""
+}
+
+// columnFilterMatcher tests texts against a ColumnFilter.
+type columnFilterMatcher struct {
+	col    int
+	values map[string]bool
+	text   string
+}
+
+func newColumnFilterMatcher(col int, filter ColumnFilter) *columnFilterMatcher {
+	m := &columnFilterMatcher{col: col, text: strings.ToLower(filter.Text)}
+
+	if filter.Values != nil {
+		m.values = make(map[string]bool, len(filter.Values))
+		for _, v := range filter.Values {
+			m.values[v] = true
+		}
+	}
+
+	return m
+}
+
+func (m *columnFilterMatcher) matches(text string) bool {
+	if m.values != nil && !m.values[text] {
+		return false
+	}
+
+	return m.text == "" || strings.Contains(strings.ToLower(text), m.text)
+}
+
+// tableViewFilterModel is the model of the check list in the filter pop-up.
+type tableViewFilterModel struct {
+	TableModelBase
+	texts   []string
+	checked []bool
+}
+
+func (m *tableViewFilterModel) RowCount() int {
+	return len(m.texts)
+}
+
+func (m *tableViewFilterModel) Value(row, col int) interface{} {
+	return m.texts[row]
+}
+
+func (m *tableViewFilterModel) Checked(row int) bool {
+	return m.checked[row]
+}
+
+func (m *tableViewFilterModel) SetChecked(row int, checked bool) error {
+	m.checked[row] = checked
+
+	m.PublishRowChanged(row)
+
+	return nil
+}
+
+func (m *tableViewFilterModel) allChecked() bool {
+	for _, c := range m.checked {
+		if !c {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (m *tableViewFilterModel) setAllChecked(checked bool) {
+	for i := range m.checked {
+		m.checked[i] = checked
+	}
+
+	m.PublishRowsChanged(0, len(m.checked)-1)
+}
+
+// showFilterPopup lets the user edit the filter of col in a small dialog
+// below anchor, which is in screen coordinates.
+func (tv *TableView) showFilterPopup(col int, anchor Rectangle) error {
+	filterer, ok := tv.model.(Filterer)
+	if !ok {
+		return nil
+	}
+
+	// The check list offers the distinct texts of all values, in sort order.
+	values := filterer.ColumnValues(col)
+	sort.SliceStable(values, func(i, j int) bool {
+		return less(values[i], values[j], SortAscending)
+	})
+
+	filter := tv.filters[col]
+	var selected map[string]bool
+	if filter.Values != nil {
+		selected = make(map[string]bool, len(filter.Values))
+		for _, v := range filter.Values {
+			selected[v] = true
+		}
+	}
+
+	model := new(tableViewFilterModel)
+	seen := make(map[string]bool)
+	for _, v := range values {
+		text := tv.formatCellValue(col, v)
+		if seen[text] {
+			continue
+		}
+		seen[text] = true
+
+		model.texts = append(model.texts, text)
+		model.checked = append(model.checked, selected == nil || selected[text])
+	}
+
+	dlg, err := newDialogWithStyle(tv.Form(), win.WS_THICKFRAME)
+	if err != nil {
+		return err
+	}
+	dlg.centerInOwnerWhenRun = false
+
+	dlg.SetTitle(tv.columns.items[col].TitleEffective())
+	dlg.SetLayout(NewVBoxLayout())
+
+	textEdit, err := NewLineEdit(dlg)
+	if err != nil {
+		return err
+	}
+	textEdit.SetCueBanner(tr("Contains", "walk"))
+	textEdit.SetText(filter.Text)
+
+	selectAll, err := NewCheckBox(dlg)
+	if err != nil {
+		return err
+	}
+	selectAll.SetText(tr("(Select All)", "walk"))
+	selectAll.SetChecked(model.allChecked())
+
+	list, err := NewTableView(dlg)
+	if err != nil {
+		return err
+	}
+	list.SetHeaderHidden(true)
+	list.SetCheckBoxes(true)
+	if err := list.Columns().Add(NewTableViewColumn()); err != nil {
+		return err
+	}
+	if err := list.SetLastColumnStretched(true); err != nil {
+		return err
+	}
+	if err := list.SetModel(model); err != nil {
+		return err
+	}
+
+	var updatingSelectAll bool
+	selectAll.CheckedChanged().Attach(func() {
+		if !updatingSelectAll {
+			model.setAllChecked(selectAll.Checked())
+		}
+	})
+	model.RowChanged().Attach(func(row int) {
+		updatingSelectAll = true
+		selectAll.SetChecked(model.allChecked())
+		updatingSelectAll = false
+	})
+
+	buttons, err := NewComposite(dlg)
+	if err != nil {
+		return err
+	}
+	hbox := NewHBoxLayout()
+	hbox.SetMargins(Margins{})
+	buttons.SetLayout(hbox)
+
+	clearButton, err := NewPushButton(buttons)
+	if err != nil {
+		return err
+	}
+	clearButton.SetText(tr("Clear", "walk"))
+
+	if _, err := NewHSpacer(buttons); err != nil {
+		return err
+	}
+
+	okButton, err := NewPushButton(buttons)
+	if err != nil {
+		return err
+	}
+	okButton.SetText(tr("OK", "walk"))
+
+	cancelButton, err := NewPushButton(buttons)
+	if err != nil {
+		return err
+	}
+	cancelButton.SetText(tr("Cancel", "walk"))
+
+	dlg.SetDefaultButton(okButton)
+	dlg.SetCancelButton(cancelButton)
+
+	clearButton.Clicked().Attach(func() {
+		filter = ColumnFilter{}
+		dlg.Accept()
+	})
+	okButton.Clicked().Attach(func() {
+		filter = ColumnFilter{Text: textEdit.Text()}
+
+		if !model.allChecked() {
+			filter.Values = []string{}
+			for i, text := range model.texts {
+				if model.checked[i] {
+					filter.Values = append(filter.Values, text)
+				}
+			}
+		}
+
+		dlg.Accept()
+	})
+	cancelButton.Clicked().Attach(dlg.Cancel)
+
+	size := SizeFrom96DPI(Size{tableViewFilterPopupWidth96dpi, tableViewFilterPopupHeight96dpi}, tv.DPI())
+	dlg.SetBoundsPixels(fitRectToScreen(dlg.hWnd, Rectangle{
+		anchor.X + anchor.Width - size.Width,
+		anchor.Y + anchor.Height,
+		size.Width,
+		size.Height,
+	}))
+
+	if dlg.Run() != DlgCmdOK {
+		return nil
+	}
+
+	return tv.SetColumnFilter(col, filter)
+}
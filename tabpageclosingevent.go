@@ -0,0 +1,63 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+type tabPageClosingEventHandlerInfo struct {
+	handler TabPageClosingEventHandler
+	once    bool
+}
+
+type TabPageClosingEventHandler func(page *TabPage, canceled *bool)
+
+type TabPageClosingEvent struct {
+	handlers []tabPageClosingEventHandlerInfo
+}
+
+func (e *TabPageClosingEvent) Attach(handler TabPageClosingEventHandler) int {
+	handlerInfo := tabPageClosingEventHandlerInfo{handler, false}
+
+	for i, h := range e.handlers {
+		if h.handler == nil {
+			e.handlers[i] = handlerInfo
+			return i
+		}
+	}
+
+	e.handlers = append(e.handlers, handlerInfo)
+
+	return len(e.handlers) - 1
+}
+
+func (e *TabPageClosingEvent) Detach(handle int) {
+	e.handlers[handle].handler = nil
+}
+
+func (e *TabPageClosingEvent) Once(handler TabPageClosingEventHandler) {
+	i := e.Attach(handler)
+	e.handlers[i].once = true
+}
+
+type TabPageClosingEventPublisher struct {
+	event TabPageClosingEvent
+}
+
+func (p *TabPageClosingEventPublisher) Event() *TabPageClosingEvent {
+	return &p.event
+}
+
+func (p *TabPageClosingEventPublisher) Publish(page *TabPage, canceled *bool) {
+	for i, h := range p.event.handlers {
+		if h.handler != nil {
+			h.handler(page, canceled)
+
+			if h.once {
+				p.event.Detach(i)
+			}
+		}
+	}
+}
@@ -15,6 +15,7 @@ type tabPageListObserver interface {
 	onInsertedPage(index int, page *TabPage) error
 	onRemovingPage(index int, page *TabPage) error
 	onRemovedPage(index int, page *TabPage) error
+	onMovedPage(from, to int, page *TabPage) error
 	onClearingPages(pages []*TabPage) error
 	onClearedPages(pages []*TabPage) error
 }
@@ -113,6 +114,30 @@ func (l *TabPageList) Insert(index int, item *TabPage) error {
 	return nil
 }
 
+// Move moves the page at index from to index to.
+func (l *TabPageList) Move(from, to int) error {
+	if from < 0 || from >= len(l.items) || to < 0 || to >= len(l.items) {
+		return newError("invalid index")
+	}
+	if from == to {
+		return nil
+	}
+
+	item := l.items[from]
+	l.items = append(l.items[:from], l.items[from+1:]...)
+	l.insertIntoSlice(to, item)
+
+	if l.observer != nil {
+		if err := l.observer.onMovedPage(from, to, item); err != nil {
+			l.items = append(l.items[:to], l.items[to+1:]...)
+			l.insertIntoSlice(from, item)
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (l *TabPageList) Len() int {
 	return len(l.items)
 }
@@ -33,10 +33,28 @@ type TabWidget struct {
 	currentIndexChangedPublisher EventPublisher
 	nonClientSizePixels          Size
 	persistent                   bool
+	tabsClosable                 bool
+	tabsMovable                  bool
+	newTabButtonVisible          bool
+	hotHit                       tabWidgetHit
+	pressedHit                   tabWidgetHit
+	dragIndex                    int
+	dragStart                    Point
+	dragging                     bool
+	newTabRequestedPublisher     EventPublisher
+	pageClosingPublisher         TabPageClosingEventPublisher
+	pageMovedPublisher           IntRangeEventPublisher
+	tabPosition                  TabPosition
+	styler                       TabStyler
 }
 
 func NewTabWidget(parent Container) (*TabWidget, error) {
-	tw := &TabWidget{currentIndex: -1}
+	tw := &TabWidget{
+		currentIndex: -1,
+		hotHit:       tabWidgetHit{index: -1},
+		pressedHit:   tabWidgetHit{index: -1},
+		dragIndex:    -1,
+	}
 	tw.pages = newTabPageList(tw)
 
 	if err := InitWidget(
@@ -329,6 +347,12 @@ func (tw *TabWidget) WndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr)
 
 			tw.onResize(wp.Cx, wp.Cy)
 
+		case win.WM_DRAWITEM:
+			if dis := (*win.DRAWITEMSTRUCT)(unsafe.Pointer(lParam)); dis.HwndItem == tw.hWndTab {
+				tw.drawTab(dis)
+				return 1
+			}
+
 		case win.WM_NOTIFY:
 			nmhdr := (*win.NMHDR)(unsafe.Pointer(lParam))
 
@@ -349,8 +373,31 @@ func tabWidgetTabWndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) uint
 
 	switch msg {
 	case win.WM_MOUSEMOVE:
+		tw.onTabMouseMove(Point{int(win.GET_X_LPARAM(lParam)), int(win.GET_Y_LPARAM(lParam))})
+
 		win.InvalidateRect(hwnd, nil, true)
 
+	case win.WM_MOUSELEAVE:
+		tw.setHotHit(tabWidgetHit{index: -1})
+
+	case win.WM_LBUTTONDOWN:
+		if tw.onTabMouseDown(Point{int(win.GET_X_LPARAM(lParam)), int(win.GET_Y_LPARAM(lParam))}) {
+			return 0
+		}
+
+	case win.WM_LBUTTONUP:
+		if tw.onTabMouseUp(Point{int(win.GET_X_LPARAM(lParam)), int(win.GET_Y_LPARAM(lParam))}) {
+			return 0
+		}
+
+	case win.WM_MBUTTONUP:
+		tw.onTabMiddleClick(Point{int(win.GET_X_LPARAM(lParam)), int(win.GET_Y_LPARAM(lParam))})
+
+	case win.WM_CAPTURECHANGED:
+		if tw.dragIndex > -1 || tw.pressedHit.part != tabWidgetPartNone {
+			tw.endTabMouseAction()
+		}
+
 	case win.WM_ERASEBKGND:
 		return 1
 
@@ -421,7 +468,12 @@ func tabWidgetTabWndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) uint
 				win.DeleteObject(win.HGDIOBJ(hRgnTab))
 			}
 
-			hRgnRC := win.CreateRectRgn(0, 0, int32(cb.Width), rc.Bottom)
+			items := rc
+			if tw.tabPosition != TabPositionTop {
+				items, _ = tw.itemsRect()
+			}
+			strip := tw.tabStripRect(items)
+			hRgnRC := win.CreateRectRgn(strip.Left, strip.Top, strip.Right, strip.Bottom)
 			win.CombineRgn(hRgn, hRgnRC, hRgn, win.RGN_DIFF)
 			win.DeleteObject(win.HGDIOBJ(hRgnRC))
 
@@ -431,7 +483,7 @@ func tabWidgetTabWndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) uint
 		}
 
 		// Draw current tab item.
-		if tw.currentIndex != -1 {
+		if tw.currentIndex != -1 && tw.tabPosition == TabPositionTop && !tw.ownerDrawn() {
 			page := tw.pages.At(tw.CurrentIndex())
 
 			if bg, wnd := page.AsWindowBase().backgroundEffective(); bg != nil &&
@@ -496,6 +548,10 @@ func tabWidgetTabWndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) uint
 			}
 		}
 
+		if err := tw.paintButtons(canvas); err != nil {
+			break
+		}
+
 		if !win.BitBlt(hdc, 0, 0, int32(cb.Width), int32(cb.Height), canvas.hdc, 0, 0, win.SRCCOPY) {
 			break
 		}
@@ -514,6 +570,7 @@ func (tw *TabWidget) onPageChanged(page *TabPage) (err error) {
 		return newError("SendMessage(TCM_SETITEM) failed")
 	}
 
+	tw.updateItemSize()
 	tw.updateNonClientSize()
 
 	return nil
@@ -530,6 +587,8 @@ func (tw *TabWidget) onInsertedPage(index int, page *TabPage) (err error) {
 		return newError("SendMessage(TCM_INSERTITEM) failed")
 	}
 
+	tw.updateItemSize()
+
 	page.SetVisible(false)
 
 	style := uint32(win.GetWindowLong(page.hWnd, win.GWL_STYLE))
@@ -630,6 +689,43 @@ func (tw *TabWidget) onRemovedPage(index int, page *TabPage) (err error) {
 	return
 }
 
+func (tw *TabWidget) onMovedPage(from, to int, page *TabPage) (err error) {
+	win.SendMessage(tw.hWndTab, win.TCM_DELETEITEM, uintptr(from), 0)
+
+	item := tw.tcitemFromPage(page)
+
+	if idx := int(win.SendMessage(tw.hWndTab, win.TCM_INSERTITEM, uintptr(to), uintptr(unsafe.Pointer(item)))); idx == -1 {
+		return newError("SendMessage(TCM_INSERTITEM) failed")
+	}
+
+	current := tw.currentIndex
+	switch {
+	case current == from:
+		current = to
+
+	case from < current && current <= to:
+		current--
+
+	case to <= current && current < from:
+		current++
+	}
+
+	win.SendMessage(tw.hWndTab, win.TCM_SETCURSEL, uintptr(current), 0)
+
+	indexChanged := current != tw.currentIndex
+	tw.currentIndex = current
+
+	win.InvalidateRect(tw.hWndTab, nil, true)
+
+	if indexChanged {
+		tw.currentIndexChangedPublisher.Publish()
+	}
+
+	tw.pageMovedPublisher.Publish(from, to)
+
+	return
+}
+
 func (tw *TabWidget) onClearingPages(pages []*TabPage) (err error) {
 	return nil
 }
@@ -649,12 +745,10 @@ func (tw *TabWidget) onClearedPages(pages []*TabPage) (err error) {
 func (tw *TabWidget) tcitemFromPage(page *TabPage) *win.TCITEM {
 	var imageIndex int32 = -1
 	if page.image != nil {
-		if bmp, err := iconCache.Bitmap(page.image, tw.DPI()); err == nil {
-			imageIndex, _ = tw.imageIndex(bmp)
-		}
+		imageIndex, _ = tw.imageIndex(page.image)
 	}
 
-	text := syscall.StringToUTF16(page.title)
+	text := syscall.StringToUTF16(page.title + tw.closeButtonPadding())
 
 	item := &win.TCITEM{
 		Mask:       win.TCIF_IMAGE | win.TCIF_TEXT,
@@ -666,7 +760,7 @@ func (tw *TabWidget) tcitemFromPage(page *TabPage) *win.TCITEM {
 	return item
 }
 
-func (tw *TabWidget) imageIndex(image *Bitmap) (index int32, err error) {
+func (tw *TabWidget) imageIndex(image Image) (index int32, err error) {
 	index = -1
 	if image != nil {
 		if tw.imageList == nil {
@@ -678,7 +772,7 @@ func (tw *TabWidget) imageIndex(image *Bitmap) (index int32, err error) {
 			win.SendMessage(tw.hWndTab, win.TCM_SETIMAGELIST, 0, uintptr(tw.imageList.hIml))
 		}
 
-		if index, err = tw.imageList.AddMasked(image); err != nil {
+		if index, err = tw.imageList.addCachedBitmap(image); err != nil {
 			return
 		}
 	}
@@ -704,6 +798,12 @@ func (tw *TabWidget) CreateLayoutItem(ctx *LayoutContext) LayoutItem {
 		pagePos:             bounds.Location(),
 		currentIndex:        tw.CurrentIndex(),
 		nonClientSizePixels: tw.nonClientSizePixels,
+		tabPosition:         tw.tabPosition,
+	}
+
+	if tw.tabPosition != TabPositionTop {
+		cb := tw.ClientBoundsPixels()
+		li.pageMargin = Size{cb.Width - bounds.X - bounds.Width, cb.Height - bounds.Y - bounds.Height}
 	}
 
 	for i := tw.pages.Len() - 1; i >= 0; i-- {
@@ -729,7 +829,9 @@ type tabWidgetLayoutItem struct {
 	ContainerLayoutItemBase
 	nonClientSizePixels Size
 	pagePos             Point // in native pixels
+	pageMargin          Size  // right and bottom, in native pixels
 	currentIndex        int
+	tabPosition         TabPosition
 }
 
 func (li *tabWidgetLayoutItem) LayoutFlags() LayoutFlags {
@@ -814,6 +916,20 @@ func (li *tabWidgetLayoutItem) PerformLayout() []LayoutResultItem {
 	if li.currentIndex > -1 {
 		page := li.children[li.currentIndex]
 
+		if li.tabPosition != TabPositionTop {
+			return []LayoutResultItem{
+				{
+					Item: page,
+					Bounds: Rectangle{
+						X:      li.pagePos.X,
+						Y:      li.pagePos.Y,
+						Width:  li.geometry.Size.Width - li.pagePos.X - li.pageMargin.Width,
+						Height: li.geometry.Size.Height - li.pagePos.Y - li.pageMargin.Height,
+					},
+				},
+			}
+		}
+
 		adjustment := IntFrom96DPI(1, li.ctx.dpi)
 		return []LayoutResultItem{
 			{
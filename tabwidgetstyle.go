@@ -0,0 +1,411 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"syscall"
+	"unsafe"
+
+	"github.com/tailscale/win"
+)
+
+const tabWidgetTabPadding96dpi = 6
+
+// TabPosition specifies the side of a TabWidget its tabs are displayed at.
+type TabPosition int
+
+const (
+	TabPositionTop TabPosition = iota
+	TabPositionBottom
+	TabPositionLeft
+	TabPositionRight
+)
+
+// TabStyler is the interface that must be implemented to draw the tabs of a
+// TabWidget yourself.
+type TabStyler interface {
+	// StyleTab is called for each tab to draw its contents. The frame of the
+	// tab has already been drawn at that point.
+	//
+	// To draw the default contents with modified colors, set
+	// style.TextColor and call style.DrawContents. To add e.g. a badge or a
+	// colored indicator, draw it using style.Canvas() afterwards.
+	StyleTab(style *TabStyle)
+}
+
+// TabStyle carries information about the display style of a tab of a
+// TabWidget.
+type TabStyle struct {
+	BackgroundColor Color
+	TextColor       Color
+	Font            *Font
+	index           int
+	page            *TabPage
+	selected        bool
+	hot             bool
+	bounds          Rectangle // in native pixels
+	closable        bool
+	hideAccel       bool
+	hdc             win.HDC
+	dpi             int
+	canvas          *Canvas
+}
+
+// Index returns the index of the tab.
+func (ts *TabStyle) Index() int {
+	return ts.index
+}
+
+// Page returns the page of the tab.
+func (ts *TabStyle) Page() *TabPage {
+	return ts.page
+}
+
+// Selected returns whether the tab belongs to the current page.
+func (ts *TabStyle) Selected() bool {
+	return ts.selected
+}
+
+// Hot returns whether the mouse cursor is over the tab.
+func (ts *TabStyle) Hot() bool {
+	return ts.hot
+}
+
+// AcceleratorCuesVisible returns whether the mnemonic of the title should be
+// underlined. It is false until the user navigates with the keyboard.
+func (ts *TabStyle) AcceleratorCuesVisible() bool {
+	return !ts.hideAccel
+}
+
+// Bounds returns the bounds of the tab in 1/96" units.
+func (ts *TabStyle) Bounds() Rectangle {
+	return RectangleTo96DPI(ts.bounds, ts.dpi)
+}
+
+// BoundsPixels returns the bounds of the tab in native
+// pixels.
+func (ts *TabStyle) BoundsPixels() Rectangle {
+	return ts.bounds
+}
+
+// Canvas returns a Canvas to draw the tab on.
+func (ts *TabStyle) Canvas() *Canvas {
+	if ts.canvas != nil {
+		ts.canvas.dpi = ts.dpi
+		return ts.canvas
+	}
+
+	if ts.hdc != 0 {
+		ts.canvas, _ = newCanvasFromHDC(ts.hdc)
+		ts.canvas.dpi = ts.dpi
+	}
+
+	return ts.canvas
+}
+
+// DrawBackground fills the tab using BackgroundColor, replacing the themed
+// background, e.g. for a dark appearance.
+func (ts *TabStyle) DrawBackground() error {
+	canvas := ts.Canvas()
+	if canvas == nil {
+		return nil
+	}
+
+	brush, err := NewSolidColorBrush(ts.BackgroundColor)
+	if err != nil {
+		return err
+	}
+	defer brush.Dispose()
+
+	return canvas.FillRectanglePixels(brush, ts.bounds)
+}
+
+// DrawImage draws image stretched to bounds specified in native pixels.
+func (ts *TabStyle) DrawImage(image Image, bounds Rectangle) error {
+	canvas := ts.Canvas()
+	if canvas == nil || image == nil {
+		return nil
+	}
+
+	if bmp, err := iconCache.Bitmap(image, ts.dpi); err == nil {
+		image = bmp
+	}
+
+	return canvas.DrawImageStretchedPixels(image, bounds)
+}
+
+// DrawText draws text inside given bounds specified in native pixels.
+func (ts *TabStyle) DrawText(text string, bounds Rectangle, format DrawTextFormat) error {
+	canvas := ts.Canvas()
+	if canvas == nil {
+		return nil
+	}
+
+	return canvas.DrawTextPixels(text, ts.Font, ts.TextColor, bounds, format)
+}
+
+// DrawContents draws the image and the title of the page the default way,
+// using TextColor and Font.
+func (ts *TabStyle) DrawContents() error {
+	b := ts.ContentBoundsPixels()
+
+	if image := ts.page.image; image != nil {
+		size := IntFrom96DPI(16, ts.dpi)
+
+		if err := ts.DrawImage(image, Rectangle{b.X, b.Y + (b.Height-size)/2, size, size}); err != nil {
+			return err
+		}
+
+		b.X += size + IntFrom96DPI(tabWidgetTabPadding96dpi, ts.dpi)/2
+		b.Width -= size + IntFrom96DPI(tabWidgetTabPadding96dpi, ts.dpi)/2
+	}
+
+	format := TextLeft | TextVCenter | TextSingleLine | TextEndEllipsis
+	if ts.hideAccel {
+		format |= TextHidePrefix
+	}
+
+	return ts.DrawText(ts.page.title, b, format)
+}
+
+// ContentBoundsPixels returns the bounds of the tab in native pixels minus
+// padding and the space taken by the close button.
+func (ts *TabStyle) ContentBoundsPixels() Rectangle {
+	padding := IntFrom96DPI(tabWidgetTabPadding96dpi, ts.dpi)
+
+	b := ts.bounds
+	b.X += padding
+	b.Width -= 2 * padding
+
+	if ts.closable {
+		b.Width -= IntFrom96DPI(tabWidgetCloseButtonSize96dpi, ts.dpi)
+	}
+
+	return b
+}
+
+// TabPosition returns the side the tabs are displayed at.
+func (tw *TabWidget) TabPosition() TabPosition {
+	return tw.tabPosition
+}
+
+// SetTabPosition sets the side the tabs are displayed at.
+//
+// Tabs at the left or right side are stacked vertically, are all as wide as
+// the widest one and display their titles horizontally, as in sidebar style
+// navigation.
+func (tw *TabWidget) SetTabPosition(position TabPosition) error {
+	if position == tw.tabPosition {
+		return nil
+	}
+
+	tw.tabPosition = position
+
+	return tw.applyTabStyle()
+}
+
+// TabStyler returns the TabStyler that draws the tabs, if any.
+func (tw *TabWidget) TabStyler() TabStyler {
+	return tw.styler
+}
+
+// SetTabStyler sets the TabStyler that draws the tabs. Passing nil restores
+// the default drawing.
+func (tw *TabWidget) SetTabStyler(styler TabStyler) error {
+	tw.styler = styler
+
+	return tw.applyTabStyle()
+}
+
+func (tw *TabWidget) tabsVertical() bool {
+	return tw.tabPosition == TabPositionLeft || tw.tabPosition == TabPositionRight
+}
+
+// ownerDrawn returns whether the tabs are drawn by drawTab instead of the tab
+// control.
+func (tw *TabWidget) ownerDrawn() bool {
+	return tw.styler != nil || tw.tabsVertical()
+}
+
+// applyTabStyle updates the styles of the tab control to match the tab
+// position and styler.
+func (tw *TabWidget) applyTabStyle() error {
+	var set uint32
+	switch tw.tabPosition {
+	case TabPositionBottom:
+		set = win.TCS_BOTTOM
+
+	case TabPositionLeft:
+		set = win.TCS_VERTICAL | win.TCS_MULTILINE | win.TCS_FIXEDWIDTH
+
+	case TabPositionRight:
+		set = win.TCS_VERTICAL | win.TCS_RIGHT | win.TCS_MULTILINE | win.TCS_FIXEDWIDTH
+	}
+	if tw.ownerDrawn() {
+		set |= win.TCS_OWNERDRAWFIXED
+	}
+
+	const all = win.TCS_BOTTOM | win.TCS_VERTICAL | win.TCS_MULTILINE | win.TCS_FIXEDWIDTH | win.TCS_OWNERDRAWFIXED
+
+	if err := setAndClearWindowLongBits(tw.hWndTab, win.GWL_STYLE, set, all&^set); err != nil {
+		return err
+	}
+
+	// Themed tab controls draw vertical tabs like horizontal ones, so use the
+	// classic appearance for them.
+	if tw.tabsVertical() {
+		empty := syscall.StringToUTF16Ptr("")
+		win.SetWindowTheme(tw.hWndTab, empty, empty)
+	} else {
+		win.SetWindowTheme(tw.hWndTab, nil, nil)
+	}
+
+	tw.updateItemSize()
+
+	// Make the tab control recalculate the positions of its tabs.
+	var rc win.RECT
+	win.GetClientRect(tw.hWndTab, &rc)
+	win.SendMessage(tw.hWndTab, win.WM_SIZE, 0, uintptr(win.MAKELONG(uint16(rc.Right), uint16(rc.Bottom))))
+
+	tw.updateNonClientSize()
+	tw.resizePages()
+	tw.RequestLayout()
+
+	win.InvalidateRect(tw.hWndTab, nil, true)
+
+	return nil
+}
+
+// updateItemSize makes vertical tabs wide enough for the widest title.
+func (tw *TabWidget) updateItemSize() {
+	if !tw.tabsVertical() {
+		return
+	}
+
+	dpi := tw.DPI()
+	font := tw.Font()
+	padding := IntFrom96DPI(tabWidgetTabPadding96dpi, dpi)
+	imageSize := IntFrom96DPI(16, dpi)
+
+	var width int
+	for _, page := range tw.pages.items {
+		w := calculateTextSize(page.title, font, dpi, 0, tw.hWndTab).Width
+		if page.image != nil {
+			w += imageSize + padding/2
+		}
+
+		width = maxi(width, w)
+	}
+
+	width += 2 * padding
+	if tw.tabsClosable {
+		width += IntFrom96DPI(tabWidgetCloseButtonSize96dpi, dpi)
+	}
+
+	height := maxi(calculateTextSize("gM", font, dpi, 0, tw.hWndTab).Height, imageSize) + padding
+
+	// For vertical tabs, the width passed is the extent along the strip.
+	win.SendMessage(tw.hWndTab, win.TCM_SETITEMSIZE, 0, uintptr(win.MAKELONG(uint16(height), uint16(width))))
+}
+
+// drawTab draws the contents of an owner-drawn tab.
+func (tw *TabWidget) drawTab(dis *win.DRAWITEMSTRUCT) {
+	index := int(dis.ItemID)
+	if index < 0 || index >= tw.pages.Len() {
+		return
+	}
+
+	dpi := tw.DPI()
+
+	style := &TabStyle{
+		BackgroundColor: Color(win.GetSysColor(win.COLOR_BTNFACE)),
+		TextColor:       Color(win.GetSysColor(win.COLOR_BTNTEXT)),
+		Font:            tw.Font(),
+		index:           index,
+		page:            tw.pages.At(index),
+		selected:        dis.ItemState&win.ODS_SELECTED != 0,
+		hot:             tw.hotHit.index == index && (tw.hotHit.part == tabWidgetPartTab || tw.hotHit.part == tabWidgetPartCloseButton),
+		bounds:          rectangleFromRECT(dis.RcItem),
+		closable:        tw.tabsClosable,
+		hideAccel:       dis.ItemState&win.ODS_NOACCEL != 0 || !keyboardCuesVisible(tw.hWndTab, win.UISF_HIDEACCEL),
+		hdc:             dis.HDC,
+		dpi:             dpi,
+	}
+	if style.selected {
+		style.BackgroundColor = Color(win.GetSysColor(win.COLOR_WINDOW))
+	}
+
+	defer func() {
+		if style.canvas != nil {
+			style.canvas.Dispose()
+		}
+	}()
+
+	if tw.styler != nil {
+		tw.styler.StyleTab(style)
+	} else {
+		style.DrawContents()
+	}
+}
+
+// tabStripRect returns the part of the tab control taken by the tabs, given
+// the bounding rectangle of all tab items, in native pixels.
+func (tw *TabWidget) tabStripRect(items win.RECT) win.RECT {
+	var cr win.RECT
+	win.GetClientRect(tw.hWndTab, &cr)
+
+	switch tw.tabPosition {
+	case TabPositionBottom:
+		cr.Top = items.Top
+
+	case TabPositionLeft:
+		cr.Right = items.Right
+
+	case TabPositionRight:
+		cr.Left = items.Left
+
+	default:
+		cr.Bottom = items.Bottom
+	}
+
+	return cr
+}
+
+// itemsRect returns the bounding rectangle of all tab items in native pixels.
+func (tw *TabWidget) itemsRect() (win.RECT, bool) {
+	var bounds win.RECT
+	found := false
+
+	for i := range tw.pages.items {
+		var rc win.RECT
+		if 0 == win.SendMessage(tw.hWndTab, win.TCM_GETITEMRECT, uintptr(i), uintptr(unsafe.Pointer(&rc))) {
+			continue
+		}
+
+		if !found {
+			bounds = rc
+			found = true
+			continue
+		}
+
+		if rc.Left < bounds.Left {
+			bounds.Left = rc.Left
+		}
+		if rc.Top < bounds.Top {
+			bounds.Top = rc.Top
+		}
+		if rc.Right > bounds.Right {
+			bounds.Right = rc.Right
+		}
+		if rc.Bottom > bounds.Bottom {
+			bounds.Bottom = rc.Bottom
+		}
+	}
+
+	return bounds, found
+}
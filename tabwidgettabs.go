@@ -0,0 +1,663 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"syscall"
+	"unsafe"
+
+	"github.com/tailscale/win"
+)
+
+const (
+	tabWidgetCloseButtonSize96dpi = 14
+	tabWidgetButtonPadding96dpi   = 4
+)
+
+// tabWidgetPart identifies a part of the tab row.
+type tabWidgetPart int
+
+const (
+	tabWidgetPartNone tabWidgetPart = iota
+	tabWidgetPartTab
+	tabWidgetPartCloseButton
+	tabWidgetPartNewTabButton
+	tabWidgetPartOverflowButton
+)
+
+// tabWidgetHit describes what is at a point of the tab row.
+type tabWidgetHit struct {
+	part  tabWidgetPart
+	index int // of the page, for tabWidgetPartTab and tabWidgetPartCloseButton
+}
+
+// TabsClosable returns whether each tab displays a button that closes its
+// page.
+func (tw *TabWidget) TabsClosable() bool {
+	return tw.tabsClosable
+}
+
+// SetTabsClosable sets whether each tab displays a button that closes its
+// page.
+//
+// Clicking the button, or clicking the tab with the middle mouse button, calls
+// ClosePage.
+func (tw *TabWidget) SetTabsClosable(closable bool) error {
+	if closable == tw.tabsClosable {
+		return nil
+	}
+
+	tw.tabsClosable = closable
+
+	return tw.updateTabItems()
+}
+
+// TabsMovable returns whether the user can reorder the tabs by dragging them.
+func (tw *TabWidget) TabsMovable() bool {
+	return tw.tabsMovable
+}
+
+// SetTabsMovable sets whether the user can reorder the tabs by dragging them.
+func (tw *TabWidget) SetTabsMovable(movable bool) {
+	tw.tabsMovable = movable
+}
+
+// NewTabButtonVisible returns whether a "+" button is displayed next to the
+// tabs.
+func (tw *TabWidget) NewTabButtonVisible() bool {
+	return tw.newTabButtonVisible
+}
+
+// SetNewTabButtonVisible sets whether a "+" button is displayed next to the
+// tabs. Clicking it publishes the NewTabRequested event.
+func (tw *TabWidget) SetNewTabButtonVisible(visible bool) {
+	tw.newTabButtonVisible = visible
+
+	win.InvalidateRect(tw.hWndTab, nil, true)
+}
+
+// NewTabRequested returns the event that is published when the user clicks
+// the "+" button.
+func (tw *TabWidget) NewTabRequested() *Event {
+	return tw.newTabRequestedPublisher.Event()
+}
+
+// PageClosing returns the event that is published before ClosePage closes a
+// page. Handlers can set canceled to keep the page open.
+func (tw *TabWidget) PageClosing() *TabPageClosingEvent {
+	return tw.pageClosingPublisher.Event()
+}
+
+// PageMoved returns the event that is published after a page has been moved
+// from one index to another.
+func (tw *TabWidget) PageMoved() *IntRangeEvent {
+	return tw.pageMovedPublisher.Event()
+}
+
+// ClosePage publishes the PageClosing event for the page at index and, unless
+// a handler cancels, removes and disposes the page.
+//
+// If the page was the current one, its neighbor becomes current.
+func (tw *TabWidget) ClosePage(index int) error {
+	if index < 0 || index >= tw.pages.Len() {
+		return newError("invalid index")
+	}
+
+	page := tw.pages.At(index)
+
+	var canceled bool
+	tw.pageClosingPublisher.Publish(page, &canceled)
+	if canceled {
+		return nil
+	}
+
+	var current *TabPage
+	if tw.currentIndex > -1 && tw.currentIndex != index {
+		current = tw.pages.At(tw.currentIndex)
+	}
+
+	if err := tw.pages.RemoveAt(index); err != nil {
+		return err
+	}
+
+	page.Dispose()
+
+	if count := tw.pages.Len(); count > 0 {
+		next := index
+		if current != nil {
+			next = tw.pages.Index(current)
+		} else if next >= count {
+			next = count - 1
+		}
+
+		return tw.SetCurrentIndex(next)
+	}
+
+	return nil
+}
+
+// updateTabItems updates the tab items of all pages, e.g. after the space
+// reserved for close buttons changed.
+func (tw *TabWidget) updateTabItems() error {
+	for _, page := range tw.pages.items {
+		if err := tw.onPageChanged(page); err != nil {
+			return err
+		}
+	}
+
+	win.InvalidateRect(tw.hWndTab, nil, true)
+
+	return nil
+}
+
+// closeButtonPadding returns the text appended to tab titles to make room
+// for the close button.
+func (tw *TabWidget) closeButtonPadding() string {
+	if !tw.tabsClosable {
+		return ""
+	}
+
+	hdc := win.GetDC(tw.hWndTab)
+	if hdc == 0 {
+		return ""
+	}
+	defer win.ReleaseDC(tw.hWndTab, hdc)
+
+	hFontOld := win.SelectObject(hdc, win.HGDIOBJ(tw.Font().handleForDPI(tw.DPI())))
+	defer win.SelectObject(hdc, hFontOld)
+
+	space := syscall.StringToUTF16(" ")
+	var s win.SIZE
+	if !win.GetTextExtentPoint32(hdc, &space[0], 1, &s) || s.CX <= 0 {
+		return ""
+	}
+
+	width := int32(tw.IntFrom96DPI(tabWidgetCloseButtonSize96dpi + tabWidgetButtonPadding96dpi))
+
+	padding := make([]byte, (width+s.CX-1)/s.CX+1)
+	for i := range padding {
+		padding[i] = ' '
+	}
+
+	return string(padding)
+}
+
+// tabRowRect returns the bounds of the row, or for vertical tabs the column,
+// of tab items in native pixels.
+func (tw *TabWidget) tabRowRect() win.RECT {
+	var cr win.RECT
+	win.GetClientRect(tw.hWndTab, &cr)
+
+	var rc win.RECT
+	if tw.pages.Len() == 0 || 0 == win.SendMessage(tw.hWndTab, win.TCM_GETITEMRECT, 0, uintptr(unsafe.Pointer(&rc))) {
+		margin := int32(tw.IntFrom96DPI(2))
+		size := int32(tw.IntFrom96DPI(20))
+
+		switch tw.tabPosition {
+		case TabPositionBottom:
+			rc = win.RECT{Top: cr.Bottom - margin - size, Bottom: cr.Bottom - margin}
+
+		case TabPositionLeft:
+			rc = win.RECT{Left: margin, Right: margin + size}
+
+		case TabPositionRight:
+			rc = win.RECT{Left: cr.Right - margin - size, Right: cr.Right - margin}
+
+		default:
+			rc = win.RECT{Top: margin, Bottom: margin + size}
+		}
+	}
+
+	if tw.tabsVertical() {
+		return win.RECT{Left: rc.Left, Top: 0, Right: rc.Right, Bottom: cr.Bottom}
+	}
+
+	return win.RECT{Left: 0, Top: rc.Top, Right: cr.Right, Bottom: rc.Bottom}
+}
+
+// upDown returns the scroll arrows the tab control displays when the tabs
+// don't fit, or 0 if they are not displayed.
+func (tw *TabWidget) upDown() win.HWND {
+	hwnd := win.GetWindow(tw.hWndTab, win.GW_CHILD)
+	if hwnd == 0 || !win.IsWindowVisible(hwnd) {
+		return 0
+	}
+
+	return hwnd
+}
+
+// buttonRect returns the bounds of the "+" or overflow button in native
+// pixels and whether the button is displayed.
+func (tw *TabWidget) buttonRect(part tabWidgetPart) (win.RECT, bool) {
+	row := tw.tabRowRect()
+	size := row.Bottom - row.Top
+
+	if upDown := tw.upDown(); upDown != 0 {
+		// The tabs don't fit, so the buttons go left of the scroll arrows.
+		var rc win.RECT
+		win.GetWindowRect(upDown, &rc)
+		p := win.POINT{rc.Left, rc.Top}
+		win.ScreenToClient(tw.hWndTab, &p)
+
+		overflow := win.RECT{Left: p.X - size, Top: row.Top, Right: p.X, Bottom: row.Bottom}
+		if part == tabWidgetPartOverflowButton {
+			return overflow, true
+		}
+
+		return win.RECT{Left: overflow.Left - size, Top: row.Top, Right: overflow.Left, Bottom: row.Bottom}, part == tabWidgetPartNewTabButton && tw.newTabButtonVisible
+	}
+
+	if part != tabWidgetPartNewTabButton || !tw.newTabButtonVisible {
+		return win.RECT{}, false
+	}
+
+	var last win.RECT
+	if count := tw.pages.Len(); count > 0 {
+		win.SendMessage(tw.hWndTab, win.TCM_GETITEMRECT, uintptr(count-1), uintptr(unsafe.Pointer(&last)))
+	}
+
+	padding := int32(tw.IntFrom96DPI(tabWidgetButtonPadding96dpi))
+
+	if tw.tabsVertical() {
+		// Below the last tab, as high as a tab.
+		size = int32(tw.IntFrom96DPI(20))
+		if last.Bottom > last.Top {
+			size = last.Bottom - last.Top
+		}
+
+		left := row.Left + (row.Right-row.Left-size)/2
+		top := last.Bottom + padding
+
+		return win.RECT{Left: left, Top: top, Right: left + size, Bottom: top + size}, true
+	}
+
+	left := last.Right + padding
+
+	return win.RECT{Left: left, Top: row.Top, Right: left + size, Bottom: row.Bottom}, true
+}
+
+// closeButtonRect returns the bounds of the close button of the tab at index
+// in native pixels.
+func (tw *TabWidget) closeButtonRect(index int) (win.RECT, bool) {
+	if !tw.tabsClosable {
+		return win.RECT{}, false
+	}
+
+	var rc win.RECT
+	if 0 == win.SendMessage(tw.hWndTab, win.TCM_GETITEMRECT, uintptr(index), uintptr(unsafe.Pointer(&rc))) {
+		return win.RECT{}, false
+	}
+
+	size := int32(tw.IntFrom96DPI(tabWidgetCloseButtonSize96dpi))
+	right := rc.Right - int32(tw.IntFrom96DPI(tabWidgetButtonPadding96dpi))
+	top := rc.Top + (rc.Bottom-rc.Top-size)/2
+
+	return win.RECT{Left: right - size, Top: top, Right: right, Bottom: top + size}, true
+}
+
+func rectContains(rc win.RECT, p Point) bool {
+	return int32(p.X) >= rc.Left && int32(p.X) < rc.Right && int32(p.Y) >= rc.Top && int32(p.Y) < rc.Bottom
+}
+
+// hitTest returns what is at p, in client coordinates of the tab control.
+func (tw *TabWidget) hitTest(p Point) tabWidgetHit {
+	for _, part := range []tabWidgetPart{tabWidgetPartOverflowButton, tabWidgetPartNewTabButton} {
+		if rc, ok := tw.buttonRect(part); ok && rectContains(rc, p) {
+			return tabWidgetHit{part: part, index: -1}
+		}
+	}
+
+	hti := win.TCHITTESTINFO{Pt: win.POINT{int32(p.X), int32(p.Y)}}
+	index := int(int32(win.SendMessage(tw.hWndTab, win.TCM_HITTEST, 0, uintptr(unsafe.Pointer(&hti)))))
+	if index < 0 || index >= tw.pages.Len() {
+		return tabWidgetHit{index: -1}
+	}
+
+	if rc, ok := tw.closeButtonRect(index); ok && rectContains(rc, p) {
+		return tabWidgetHit{part: tabWidgetPartCloseButton, index: index}
+	}
+
+	return tabWidgetHit{part: tabWidgetPartTab, index: index}
+}
+
+func (tw *TabWidget) setHotHit(hit tabWidgetHit) {
+	if hit == tw.hotHit {
+		return
+	}
+
+	tw.hotHit = hit
+	win.InvalidateRect(tw.hWndTab, nil, true)
+
+	if hit.part != tabWidgetPartNone {
+		var tme win.TRACKMOUSEEVENT
+		tme.CbSize = uint32(unsafe.Sizeof(tme))
+		tme.DwFlags = win.TME_LEAVE
+		tme.HwndTrack = tw.hWndTab
+
+		win.TrackMouseEvent(&tme)
+	}
+}
+
+// onTabMouseDown handles a left button press in the tab control and returns
+// whether the tab control must not process it.
+func (tw *TabWidget) onTabMouseDown(p Point) bool {
+	hit := tw.hitTest(p)
+
+	switch hit.part {
+	case tabWidgetPartOverflowButton:
+		tw.showOverflowMenu()
+		return true
+
+	case tabWidgetPartCloseButton, tabWidgetPartNewTabButton:
+		tw.pressedHit = hit
+		win.SetCapture(tw.hWndTab)
+		return true
+
+	case tabWidgetPartTab:
+		if tw.tabsMovable {
+			tw.dragIndex = hit.index
+			tw.dragStart = p
+			tw.dragging = false
+		}
+	}
+
+	return false
+}
+
+func (tw *TabWidget) onTabMouseMove(p Point) {
+	tw.setHotHit(tw.hitTest(p))
+
+	if tw.dragIndex < 0 {
+		return
+	}
+
+	if !tw.dragging {
+		d, metric := p.X-tw.dragStart.X, int32(win.SM_CXDRAG)
+		if tw.tabsVertical() {
+			d, metric = p.Y-tw.dragStart.Y, win.SM_CYDRAG
+		}
+		if d < 0 {
+			d = -d
+		}
+		if d < int(win.GetSystemMetricsForDpi(metric, uint32(tw.DPI()))) {
+			return
+		}
+
+		tw.dragging = true
+		win.SetCapture(tw.hWndTab)
+	}
+
+	pt := win.POINT{int32(p.X), int32(p.Y)}
+	if !tw.tabsVertical() {
+		row := tw.tabRowRect()
+		pt.Y = row.Top + (row.Bottom-row.Top)/2
+	}
+
+	hti := win.TCHITTESTINFO{Pt: pt}
+	target := int(int32(win.SendMessage(tw.hWndTab, win.TCM_HITTEST, 0, uintptr(unsafe.Pointer(&hti)))))
+	if target < 0 || target >= tw.pages.Len() || target == tw.dragIndex {
+		return
+	}
+
+	// Only move once the cursor would be over the dragged tab afterwards, so
+	// tabs of different widths don't swap back and forth. Vertical tabs all
+	// have the same size.
+	if !tw.tabsVertical() {
+		var dragged, over win.RECT
+		win.SendMessage(tw.hWndTab, win.TCM_GETITEMRECT, uintptr(tw.dragIndex), uintptr(unsafe.Pointer(&dragged)))
+		win.SendMessage(tw.hWndTab, win.TCM_GETITEMRECT, uintptr(target), uintptr(unsafe.Pointer(&over)))
+		width := dragged.Right - dragged.Left
+		if target > tw.dragIndex && int32(p.X) < over.Right-width ||
+			target < tw.dragIndex && int32(p.X) >= over.Left+width {
+			return
+		}
+	}
+
+	if err := tw.pages.Move(tw.dragIndex, target); err != nil {
+		return
+	}
+
+	tw.dragIndex = target
+}
+
+// onTabMouseUp handles a left button release in the tab control and returns
+// whether the tab control must not process it.
+func (tw *TabWidget) onTabMouseUp(p Point) bool {
+	pressed := tw.pressedHit
+	dragging := tw.dragging
+
+	tw.endTabMouseAction()
+
+	if pressed.part != tabWidgetPartNone {
+		if hit := tw.hitTest(p); hit == pressed {
+			switch pressed.part {
+			case tabWidgetPartCloseButton:
+				tw.ClosePage(pressed.index)
+
+			case tabWidgetPartNewTabButton:
+				tw.newTabRequestedPublisher.Publish()
+			}
+		}
+
+		return true
+	}
+
+	return dragging
+}
+
+func (tw *TabWidget) onTabMiddleClick(p Point) {
+	if !tw.tabsClosable {
+		return
+	}
+
+	if hit := tw.hitTest(p); hit.part == tabWidgetPartTab || hit.part == tabWidgetPartCloseButton {
+		tw.ClosePage(hit.index)
+	}
+}
+
+// endTabMouseAction ends pressing a button or dragging a tab.
+func (tw *TabWidget) endTabMouseAction() {
+	captured := tw.pressedHit.part != tabWidgetPartNone || tw.dragging
+
+	tw.pressedHit = tabWidgetHit{index: -1}
+	tw.dragIndex = -1
+	tw.dragging = false
+
+	if captured {
+		win.ReleaseCapture()
+	}
+
+	win.InvalidateRect(tw.hWndTab, nil, true)
+}
+
+// showOverflowMenu pops up a menu below the overflow button that lists all
+// pages, to switch to one that is scrolled out of view.
+func (tw *TabWidget) showOverflowMenu() {
+	rc, ok := tw.buttonRect(tabWidgetPartOverflowButton)
+	if !ok {
+		return
+	}
+
+	menu, err := NewMenu()
+	if err != nil {
+		return
+	}
+	defer menu.Dispose()
+
+	for i, page := range tw.pages.items {
+		index := i
+
+		action := NewAction()
+		action.SetText(page.title)
+		action.SetImage(page.image)
+		action.SetChecked(i == tw.currentIndex)
+		action.Triggered().Attach(func() {
+			tw.SetCurrentIndex(index)
+		})
+
+		if err := menu.Actions().Add(action); err != nil {
+			return
+		}
+	}
+
+	p := win.POINT{rc.Right, rc.Bottom}
+	win.ClientToScreen(tw.hWndTab, &p)
+
+	actionId := uint16(win.TrackPopupMenuEx(
+		menu.hMenu,
+		win.TPM_NOANIMATION|win.TPM_RETURNCMD|win.TPM_RIGHTALIGN,
+		p.X,
+		p.Y,
+		tw.hWnd,
+		nil))
+
+	if actionId != 0 {
+		if action, ok := actionsById[actionId]; ok {
+			action.raiseTriggered()
+		}
+	}
+}
+
+// paintButtons draws the close, "+" and overflow buttons of the tab row.
+func (tw *TabWidget) paintButtons(canvas *Canvas) error {
+	hotBrush, err := NewSystemColorBrush(SysColor3DLight)
+	if err != nil {
+		return err
+	}
+	defer hotBrush.Dispose()
+
+	closeHotBrush, err := NewSolidColorBrush(RGB(0xC4, 0x2B, 0x1C))
+	if err != nil {
+		return err
+	}
+	defer closeHotBrush.Dispose()
+
+	textBrush, err := NewSolidColorBrush(Color(win.GetSysColor(win.COLOR_BTNTEXT)))
+	if err != nil {
+		return err
+	}
+	defer textBrush.Dispose()
+
+	whiteBrush, err := NewSolidColorBrush(RGB(0xFF, 0xFF, 0xFF))
+	if err != nil {
+		return err
+	}
+	defer whiteBrush.Dispose()
+
+	penWidth := tw.IntFrom96DPI(1)
+
+	drawGlyph := func(rc win.RECT, brush Brush, glyph func(pen Pen, b Rectangle) error) error {
+		pen, err := NewGeometricPen(PenSolid|PenCapRound, penWidth, brush)
+		if err != nil {
+			return err
+		}
+		defer pen.Dispose()
+
+		size := int(rc.Bottom - rc.Top)
+		if w := int(rc.Right - rc.Left); w < size {
+			size = w
+		}
+		size = size / 2
+
+		return glyph(pen, Rectangle{
+			X:      int(rc.Left+rc.Right)/2 - size/2,
+			Y:      int(rc.Top+rc.Bottom)/2 - size/2,
+			Width:  size,
+			Height: size,
+		})
+	}
+
+	cross := func(pen Pen, b Rectangle) error {
+		if err := canvas.DrawLinePixels(pen, Point{b.X, b.Y}, Point{b.X + b.Width, b.Y + b.Height}); err != nil {
+			return err
+		}
+
+		return canvas.DrawLinePixels(pen, Point{b.X + b.Width, b.Y}, Point{b.X, b.Y + b.Height})
+	}
+
+	plus := func(pen Pen, b Rectangle) error {
+		if err := canvas.DrawLinePixels(pen, Point{b.X, b.Y + b.Height/2}, Point{b.X + b.Width, b.Y + b.Height/2}); err != nil {
+			return err
+		}
+
+		return canvas.DrawLinePixels(pen, Point{b.X + b.Width/2, b.Y}, Point{b.X + b.Width/2, b.Y + b.Height})
+	}
+
+	chevron := func(pen Pen, b Rectangle) error {
+		return canvas.DrawPolylinePixels(pen, []Point{
+			{b.X, b.Y + b.Height/4},
+			{b.X + b.Width/2, b.Y + b.Height*3/4},
+			{b.X + b.Width, b.Y + b.Height/4},
+		})
+	}
+
+	toRectangle := func(rc win.RECT) Rectangle {
+		return Rectangle{int(rc.Left), int(rc.Top), int(rc.Right - rc.Left), int(rc.Bottom - rc.Top)}
+	}
+
+	if tw.tabsClosable {
+		for i := range tw.pages.items {
+			rc, ok := tw.closeButtonRect(i)
+			if !ok {
+				continue
+			}
+
+			brush := Brush(textBrush)
+			if hit := (tabWidgetHit{part: tabWidgetPartCloseButton, index: i}); tw.hotHit == hit || tw.pressedHit == hit {
+				if err := canvas.FillRectanglePixels(closeHotBrush, toRectangle(rc)); err != nil {
+					return err
+				}
+
+				brush = whiteBrush
+			}
+
+			if err := drawGlyph(rc, brush, cross); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, button := range []struct {
+		part  tabWidgetPart
+		glyph func(pen Pen, b Rectangle) error
+	}{
+		{tabWidgetPartNewTabButton, plus},
+		{tabWidgetPartOverflowButton, chevron},
+	} {
+		rc, ok := tw.buttonRect(button.part)
+		if !ok {
+			continue
+		}
+
+		// When the tabs don't fit, the buttons cover tab items.
+		var brush Brush
+		if tw.hotHit.part == button.part {
+			brush = hotBrush
+		} else if tw.upDown() != 0 {
+			brush = sysColorBtnFaceBrush
+			if parent := tw.Parent(); parent != nil {
+				if bg, wnd := parent.AsWindowBase().backgroundEffective(); bg != nil {
+					tw.prepareDCForBackground(canvas.hdc, tw.hWndTab, wnd)
+					brush = bg
+				}
+			}
+		}
+
+		if brush != nil {
+			if err := canvas.FillRectanglePixels(brush, toRectangle(rc)); err != nil {
+				return err
+			}
+		}
+
+		if err := drawGlyph(rc, textBrush, button.glyph); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
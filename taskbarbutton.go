@@ -0,0 +1,313 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"syscall"
+
+	"github.com/tailscale/win"
+)
+
+// maxThumbnailButtons is the number of buttons the taskbar supports in the
+// toolbar of a thumbnail.
+const maxThumbnailButtons = 7
+
+// TaskbarButton controls the taskbar button of a MainWindow. It shows the
+// progress of long running operations, an overlay icon and a toolbar in the
+// thumbnail preview, and can flash to draw attention to the window.
+//
+// The state is kept even while the taskbar button does not exist yet, and it
+// is applied again whenever the button is recreated, e.g. after Explorer
+// restarted.
+type TaskbarButton struct {
+	form                   *FormBase
+	progressState          PIState
+	completed              uint32
+	total                  uint32
+	overlayIcon            *Icon
+	overlayIconDescription string
+	thumbnailButtons       []*Action
+	thumbnailButtonsAdded  bool
+}
+
+// TaskbarButton returns the taskbar button of the MainWindow.
+func (mw *MainWindow) TaskbarButton() *TaskbarButton {
+	if mw.taskbarButton == nil {
+		mw.taskbarButton = &TaskbarButton{form: &mw.FormBase}
+	}
+
+	return mw.taskbarButton
+}
+
+// ProgressState returns the state of the progress shown on the button.
+func (tb *TaskbarButton) ProgressState() PIState {
+	return tb.progressState
+}
+
+// SetProgressState sets the state of the progress shown on the button.
+// PINoProgress hides the progress.
+func (tb *TaskbarButton) SetProgressState(state PIState) error {
+	tb.progressState = state
+
+	return tb.applyProgress()
+}
+
+// ProgressValue returns the completed and total amount of work shown on the
+// button.
+func (tb *TaskbarButton) ProgressValue() (completed, total uint32) {
+	return tb.completed, tb.total
+}
+
+// SetProgressValue sets the completed and total amount of work shown on the
+// button. If the progress state is PINoProgress or PIIndeterminate, it is
+// switched to PINormal, as the value would not be visible otherwise.
+func (tb *TaskbarButton) SetProgressValue(completed, total uint32) error {
+	if completed > total {
+		return newError("completed cannot exceed total")
+	}
+
+	tb.completed, tb.total = completed, total
+
+	if tb.progressState == PINoProgress || tb.progressState == PIIndeterminate {
+		tb.progressState = PINormal
+	}
+
+	return tb.applyProgress()
+}
+
+// OverlayIcon returns the icon drawn over the application icon on the
+// button, or nil if there is none.
+func (tb *TaskbarButton) OverlayIcon() *Icon {
+	return tb.overlayIcon
+}
+
+// OverlayIconDescription returns the accessibility text of the overlay icon.
+func (tb *TaskbarButton) OverlayIconDescription() string {
+	return tb.overlayIconDescription
+}
+
+// SetOverlayIcon sets the icon drawn over the application icon on the button,
+// along with a description that is read by screen readers. A nil icon removes
+// the overlay.
+func (tb *TaskbarButton) SetOverlayIcon(icon *Icon, description string) error {
+	if icon == nil {
+		description = ""
+	}
+
+	tb.overlayIcon, tb.overlayIconDescription = icon, description
+
+	if pi := tb.form.progressIndicator; pi != nil {
+		return pi.SetOverlayIcon(icon, description)
+	}
+
+	return nil
+}
+
+// Flash flashes the button count times. If count is 0, the button flashes
+// until the window is activated.
+func (tb *TaskbarButton) Flash(count int) error {
+	if count < 0 {
+		return newError("count cannot be negative")
+	}
+
+	fwi := flashWInfo{
+		hwnd:    tb.form.hWnd,
+		dwFlags: _FLASHW_TRAY,
+		uCount:  uint32(count),
+	}
+	if count == 0 {
+		fwi.dwFlags |= _FLASHW_TIMERNOFG
+	}
+
+	flashWindowEx(&fwi)
+
+	return nil
+}
+
+// StopFlashing stops the button from flashing and restores its normal look.
+func (tb *TaskbarButton) StopFlashing() {
+	fwi := flashWInfo{
+		hwnd:    tb.form.hWnd,
+		dwFlags: _FLASHW_STOP,
+	}
+
+	flashWindowEx(&fwi)
+}
+
+// ThumbnailButtons returns the Actions shown as buttons in the toolbar of the
+// thumbnail preview.
+func (tb *TaskbarButton) ThumbnailButtons() []*Action {
+	return tb.thumbnailButtons
+}
+
+// SetThumbnailButtons sets the Actions shown as buttons in the toolbar of the
+// thumbnail preview, e.g. media controls. At most seven buttons are supported.
+//
+// A button shows the image of its Action and its tool tip, or its text if it
+// has none, and follows its enabled and visible state. Separators leave an
+// empty space.
+func (tb *TaskbarButton) SetThumbnailButtons(actions ...*Action) error {
+	if len(actions) > maxThumbnailButtons {
+		return newError("too many thumbnail buttons")
+	}
+
+	for _, action := range actions {
+		if action == nil {
+			return newError("action cannot be nil")
+		}
+	}
+
+	tb.releaseThumbnailButtons()
+
+	tb.thumbnailButtons = append([]*Action(nil), actions...)
+
+	for _, action := range tb.thumbnailButtons {
+		action.addRef()
+		action.addChangedHandler(tb)
+	}
+
+	return tb.updateThumbnailButtons()
+}
+
+func (tb *TaskbarButton) releaseThumbnailButtons() {
+	for _, action := range tb.thumbnailButtons {
+		action.removeChangedHandler(tb)
+		action.release()
+	}
+
+	tb.thumbnailButtons = nil
+}
+
+func (tb *TaskbarButton) updateThumbnailButtons() error {
+	pi := tb.form.progressIndicator
+	if pi == nil || !tb.thumbnailButtonsAdded && len(tb.thumbnailButtons) == 0 {
+		return nil
+	}
+
+	dpi := tb.form.DPI()
+
+	// The taskbar does not allow adding or removing buttons once the toolbar
+	// exists, so all of them are added up front and the unused ones hidden.
+	var buttons [maxThumbnailButtons]thumbButton
+	for i := range buttons {
+		b := &buttons[i]
+		b.dwMask = _THB_FLAGS
+		b.iId = uint32(i)
+		b.dwFlags = _THBF_HIDDEN
+
+		if i >= len(tb.thumbnailButtons) {
+			continue
+		}
+
+		action := tb.thumbnailButtons[i]
+		switch {
+		case !action.Visible():
+
+		case action.IsSeparator():
+			b.dwFlags = _THBF_NOBACKGROUND | _THBF_NONINTERACTIVE
+
+		default:
+			b.dwFlags = 0
+			if !action.Enabled() {
+				b.dwFlags = _THBF_DISABLED
+			}
+
+			tip := action.ToolTip()
+			if tip == "" {
+				tip = action.Text()
+			}
+			if tip16, err := syscall.UTF16FromString(tip); err == nil {
+				b.dwMask |= _THB_TOOLTIP
+				copy(b.szTip[:len(b.szTip)-1], tip16)
+			}
+
+			icon, err := IconFrom(action.Image(), dpi)
+			if err != nil {
+				return err
+			}
+			if icon != nil {
+				b.dwMask |= _THB_ICON
+				b.hIcon = icon.handleForDPI(dpi)
+			}
+		}
+	}
+
+	if !tb.thumbnailButtonsAdded {
+		if hr := thumbBarAddButtons(pi.taskbarList3, tb.form.hWnd, buttons[:]); win.FAILED(hr) {
+			return errorFromHRESULT("ITaskbarList3.ThumbBarAddButtons", hr)
+		}
+
+		tb.thumbnailButtonsAdded = true
+
+		return nil
+	}
+
+	if hr := thumbBarUpdateButtons(pi.taskbarList3, tb.form.hWnd, buttons[:]); win.FAILED(hr) {
+		return errorFromHRESULT("ITaskbarList3.ThumbBarUpdateButtons", hr)
+	}
+
+	return nil
+}
+
+func (tb *TaskbarButton) onActionChanged(action *Action) error {
+	return tb.updateThumbnailButtons()
+}
+
+func (tb *TaskbarButton) onActionVisibleChanged(action *Action) error {
+	return tb.updateThumbnailButtons()
+}
+
+func (tb *TaskbarButton) handleThumbnailButtonClicked(index int) {
+	if index >= len(tb.thumbnailButtons) {
+		return
+	}
+
+	if action := tb.thumbnailButtons[index]; action.Enabled() {
+		action.raiseTriggered()
+	}
+}
+
+func (tb *TaskbarButton) applyProgress() error {
+	pi := tb.form.progressIndicator
+	if pi == nil {
+		return nil
+	}
+
+	if err := pi.SetState(tb.progressState); err != nil {
+		return err
+	}
+
+	if tb.progressState == PINoProgress || tb.progressState == PIIndeterminate {
+		return nil
+	}
+
+	// A total of 0 would hide the progress, so an empty one is shown instead.
+	total := tb.total
+	if total == 0 {
+		total = 1
+	}
+	pi.SetTotal(total)
+
+	return pi.SetCompleted(tb.completed)
+}
+
+// apply transfers the state to a newly created taskbar button.
+func (tb *TaskbarButton) apply() {
+	if tb.form.progressIndicator == nil {
+		return
+	}
+
+	tb.applyProgress()
+
+	if tb.overlayIcon != nil {
+		tb.form.progressIndicator.SetOverlayIcon(tb.overlayIcon, tb.overlayIconDescription)
+	}
+
+	tb.thumbnailButtonsAdded = false
+	tb.updateThumbnailButtons()
+}
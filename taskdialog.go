@@ -290,6 +290,7 @@ type taskDialog struct {
 	hyperlinkClicked    ProceedWithArgEventPublisher[string]
 	timerFired          GenericEventPublisher[time.Duration]
 	verificationClicked ProceedWithArgEventPublisher[bool]
+	iconPins            iconCachePins // cached icons in use by the dialog
 }
 
 // NewTaskDialog instantiates a new TaskDialog. It must only be called from the
@@ -308,6 +309,7 @@ func (td *taskDialog) Show(opts TaskDialogOpts) (result TaskDialogResult, err er
 	td.opts = &opts
 	defer func() {
 		td.opts = nil
+		iconCache.unpin(&td.iconPins)
 	}()
 
 	var rtl bool
@@ -887,7 +889,7 @@ func (td *taskDialog) radioIDToIndex(id int32) int {
 func (td *taskDialog) getIcon(img Image, sys TaskDialogSystemIcon) uintptr {
 	if img != nil {
 		dpi := td.getDPI()
-		ic, err := iconCache.Icon(img, dpi)
+		ic, err := iconCache.pinnedIcon(&td.iconPins, img, dpi)
 		if err != nil {
 			return 0
 		}
@@ -23,6 +23,7 @@ type TextEdit struct {
 	margins                  Size // in native pixels
 	lastHeight               int
 	origWordbreakProcPtr     uintptr
+	findHighlights           findHighlights
 }
 
 func NewTextEdit(parent Container) (*TextEdit, error) {
@@ -32,13 +33,22 @@ func NewTextEdit(parent Container) (*TextEdit, error) {
 func NewTextEditWithStyle(parent Container, style uint32) (*TextEdit, error) {
 	te := new(TextEdit)
 
+	if err := te.init(te, parent, style); err != nil {
+		return nil, err
+	}
+
+	return te, nil
+}
+
+// init creates the window of te, which is embedded in widget.
+func (te *TextEdit) init(widget Widget, parent Container, style uint32) error {
 	if err := InitWidget(
-		te,
+		widget,
 		parent,
 		"EDIT",
 		win.WS_TABSTOP|win.WS_VISIBLE|win.ES_MULTILINE|win.ES_WANTRETURN|style,
 		win.WS_EX_CLIENTEDGE); err != nil {
-		return nil, err
+		return err
 	}
 
 	te.origWordbreakProcPtr = te.SendMessage(win.EM_GETWORDBREAKPROC, 0, 0)
@@ -64,7 +74,7 @@ func NewTextEditWithStyle(parent Container, style uint32) (*TextEdit, error) {
 		},
 		te.textChangedPublisher.Event()))
 
-	return te, nil
+	return nil
 }
 
 func (te *TextEdit) applyFont(font *Font) {
@@ -245,6 +255,27 @@ func (te *TextEdit) AppendText(value string) {
 	te.SetTextSelection(s, e)
 }
 
+func (te *TextEdit) searchText() string {
+	return te.Text()
+}
+
+func (te *TextEdit) setFindHighlights(highlights findHighlights) {
+	te.findHighlights = highlights
+
+	te.Invalidate()
+}
+
+// posFromChar returns the client position of the character at index, which
+// is unavailable past the end of the text.
+func (te *TextEdit) posFromChar(index int) (Point, bool) {
+	ret := uint32(te.SendMessage(win.EM_POSFROMCHAR, uintptr(index), 0))
+	if int32(ret) == -1 {
+		return Point{}, false
+	}
+
+	return Point{int(int16(win.LOWORD(ret))), int(int16(win.HIWORD(ret)))}, true
+}
+
 func (te *TextEdit) ReadOnly() bool {
 	return te.hasStyleBits(win.ES_READONLY)
 }
@@ -314,6 +345,15 @@ func (te *TextEdit) WndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) u
 		if Key(wParam) == KeyA && ControlDown() {
 			te.SetTextSelection(0, -1)
 		}
+
+	case win.WM_PAINT:
+		if len(te.findHighlights.matches) > 0 {
+			result := te.WidgetBase.WndProc(hwnd, msg, wParam, lParam)
+
+			te.findHighlights.paint(&te.WindowBase, te.posFromChar)
+
+			return result
+		}
 	}
 
 	return te.WidgetBase.WndProc(hwnd, msg, wParam, lParam)
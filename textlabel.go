@@ -78,6 +78,11 @@ func (tl *TextLabel) CreateLayoutItem(ctx *LayoutContext) LayoutItem {
 		text:         tl.Text(),
 		font:         tl.Font(),
 		minWidth:     tl.MinSizePixels().Width,
+		baseline: staticBaseline{
+			textVAlignment: tl.textVAlignment(),
+			textAscent:     tl.fontAscent(),
+			borderHeight:   tl.SizePixels().Height - tl.ClientBoundsPixels().Height,
+		},
 	}
 }
 
@@ -88,6 +93,11 @@ type textLabelLayoutItem struct {
 	text         string
 	font         *Font
 	minWidth     int // in native pixels
+	baseline     staticBaseline
+}
+
+func (li *textLabelLayoutItem) Baseline(size Size) int {
+	return li.baseline.forHeight(size.Height, li.HeightForWidth(size.Width))
 }
 
 func (*textLabelLayoutItem) LayoutFlags() LayoutFlags {
@@ -0,0 +1,927 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"strings"
+	"unsafe"
+
+	"github.com/tailscale/win"
+)
+
+const tokenEditWindowClass = `\o/ Walk_TokenEdit_Class \o/`
+
+func init() {
+	AppendToWalkInit(func() {
+		MustRegisterWindowClass(tokenEditWindowClass)
+	})
+}
+
+const (
+	tokenEditPadding96dpi      = 2
+	tokenEditSpacing96dpi      = 3
+	tokenEditChipPadding96dpi  = 6
+	tokenEditCloseSize96dpi    = 8
+	tokenEditEditMinWidth96dpi = 40
+	tokenEditDefaultSeparators = ",;"
+)
+
+type tokenEditToken struct {
+	text  string
+	valid bool
+}
+
+// TokenEdit is a widget for entering a list of short entries, such as
+// recipients, tags or filters. Once typed, each entry becomes a token that is
+// displayed as a chip with a button to remove it.
+//
+// Typing a separator or pressing Enter turns the typed text into a token, and
+// pasted text is split into tokens at separators and line breaks. With the
+// caret at the start of the text, the Left and Right keys select tokens, and
+// Backspace or Delete removes the selected one.
+//
+// Tokens rejected by the Validator are kept, but marked as invalid. Suggestions
+// matching the typed text are offered in a dropdown below the TokenEdit.
+type TokenEdit struct {
+	WidgetBase
+	edit                   *tokenLineEdit
+	suggestionsPopup       *searchResultsPopup
+	tokens                 []tokenEditToken
+	tokenBounds            []Rectangle // in native pixels
+	selectedToken          int
+	hotCloseToken          int
+	separators             string
+	validator              Validator
+	suggestions            []string
+	tokensChangedPublisher EventPublisher
+}
+
+// NewTokenEdit returns a new TokenEdit widget as child of parent.
+func NewTokenEdit(parent Container) (*TokenEdit, error) {
+	te := &TokenEdit{
+		selectedToken: -1,
+		hotCloseToken: -1,
+		separators:    tokenEditDefaultSeparators,
+	}
+
+	if err := InitWidget(
+		te,
+		parent,
+		tokenEditWindowClass,
+		win.WS_VISIBLE|win.WS_CLIPCHILDREN,
+		win.WS_EX_CLIENTEDGE|win.WS_EX_CONTROLPARENT); err != nil {
+		return nil, err
+	}
+
+	var succeeded bool
+	defer func() {
+		if !succeeded {
+			te.Dispose()
+		}
+	}()
+
+	var err error
+	if te.edit, err = newTokenLineEdit(te); err != nil {
+		return nil, err
+	}
+
+	te.edit.applyFont(te.Font())
+
+	te.edit.TextChanged().Attach(te.updateSuggestions)
+
+	te.GraphicsEffects().Add(InteractionEffect)
+	te.GraphicsEffects().Add(FocusEffect)
+
+	te.MustRegisterProperty("Tokens", NewProperty(
+		func() interface{} {
+			return te.Tokens()
+		},
+		func(v interface{}) error {
+			tokens, _ := v.([]string)
+			return te.SetTokens(tokens)
+		},
+		te.tokensChangedPublisher.Event()))
+
+	succeeded = true
+
+	return te, nil
+}
+
+func (te *TokenEdit) applyEnabled(enabled bool) {
+	te.WidgetBase.applyEnabled(enabled)
+
+	if te.edit == nil {
+		return
+	}
+
+	te.edit.applyEnabled(enabled)
+}
+
+func (te *TokenEdit) applyFont(font *Font) {
+	te.WidgetBase.applyFont(font)
+
+	if te.edit == nil {
+		return
+	}
+
+	te.edit.applyFont(font)
+	te.layoutTokens()
+	te.RequestLayout()
+}
+
+// SetFocus sets the keyboard input focus to the TokenEdit.
+func (te *TokenEdit) SetFocus() error {
+	if win.SetFocus(te.edit.hWnd) == 0 {
+		return lastError("SetFocus")
+	}
+
+	return nil
+}
+
+// Tokens returns the texts of the tokens.
+func (te *TokenEdit) Tokens() []string {
+	texts := make([]string, len(te.tokens))
+	for i, t := range te.tokens {
+		texts[i] = t.text
+	}
+
+	return texts
+}
+
+// SetTokens replaces the tokens with the given texts.
+func (te *TokenEdit) SetTokens(texts []string) error {
+	te.tokens = nil
+	te.selectedToken = -1
+
+	te.addTokens(texts)
+
+	return nil
+}
+
+// AddToken adds a token with the given text after the existing ones.
+func (te *TokenEdit) AddToken(text string) error {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return newError("empty token")
+	}
+
+	te.addTokens([]string{text})
+
+	return nil
+}
+
+// RemoveToken removes the token at index.
+func (te *TokenEdit) RemoveToken(index int) error {
+	if index < 0 || index >= len(te.tokens) {
+		return newError("index out of range")
+	}
+
+	te.tokens = append(te.tokens[:index], te.tokens[index+1:]...)
+
+	if te.selectedToken >= len(te.tokens) {
+		te.selectedToken = -1
+	}
+
+	te.onTokensChanged()
+
+	return nil
+}
+
+// TokenValid returns whether the token at index was accepted by the
+// Validator.
+func (te *TokenEdit) TokenValid(index int) bool {
+	if index < 0 || index >= len(te.tokens) {
+		return false
+	}
+
+	return te.tokens[index].valid
+}
+
+// TokensChanged returns the event that is published when tokens are added or
+// removed.
+func (te *TokenEdit) TokensChanged() *Event {
+	return te.tokensChangedPublisher.Event()
+}
+
+// Text returns the text typed that has not become a token yet.
+func (te *TokenEdit) Text() string {
+	return te.edit.Text()
+}
+
+// SetText sets the text typed that has not become a token yet.
+func (te *TokenEdit) SetText(text string) error {
+	return te.edit.SetText(text)
+}
+
+// CueBanner returns the text displayed while the TokenEdit is empty.
+func (te *TokenEdit) CueBanner() string {
+	return te.edit.CueBanner()
+}
+
+// SetCueBanner sets the text displayed while the TokenEdit is empty.
+func (te *TokenEdit) SetCueBanner(cueBanner string) error {
+	return te.edit.SetCueBanner(cueBanner)
+}
+
+// Separators returns the characters that separate tokens.
+func (te *TokenEdit) Separators() string {
+	return te.separators
+}
+
+// SetSeparators sets the characters that separate tokens. Line breaks always
+// separate pasted tokens. The default is ",;".
+func (te *TokenEdit) SetSeparators(separators string) {
+	te.separators = separators
+}
+
+// Validator returns the Validator that tokens are checked with.
+func (te *TokenEdit) Validator() Validator {
+	return te.validator
+}
+
+// SetValidator sets the Validator that the texts of tokens are checked with,
+// including the existing ones. Tokens it rejects are marked as invalid.
+func (te *TokenEdit) SetValidator(validator Validator) {
+	te.validator = validator
+
+	for i := range te.tokens {
+		te.tokens[i].valid = te.validate(te.tokens[i].text)
+	}
+
+	te.Invalidate()
+}
+
+// Suggestions returns the texts offered while typing.
+func (te *TokenEdit) Suggestions() []string {
+	return te.suggestions
+}
+
+// SetSuggestions sets the texts offered in a dropdown while typing. Those
+// containing the typed text, ignoring case, and not already a token are
+// offered.
+func (te *TokenEdit) SetSuggestions(suggestions []string) {
+	te.suggestions = append([]string(nil), suggestions...)
+
+	te.updateSuggestions()
+}
+
+func (te *TokenEdit) validate(text string) bool {
+	return te.validator == nil || te.validator.Validate(text) == nil
+}
+
+func (te *TokenEdit) addTokens(texts []string) {
+	for _, text := range texts {
+		if text = strings.TrimSpace(text); text != "" {
+			te.tokens = append(te.tokens, tokenEditToken{text, te.validate(text)})
+		}
+	}
+
+	te.onTokensChanged()
+}
+
+func (te *TokenEdit) onTokensChanged() {
+	te.layoutTokens()
+	te.Invalidate()
+	te.RequestLayout()
+
+	te.tokensChangedPublisher.Publish()
+}
+
+func (te *TokenEdit) isSeparator(r rune) bool {
+	return r == '\r' || r == '\n' || strings.ContainsRune(te.separators, r)
+}
+
+// splitTokens splits text at separators and line breaks.
+func (te *TokenEdit) splitTokens(text string) []string {
+	return strings.FieldsFunc(text, te.isSeparator)
+}
+
+// commitText turns the typed text into tokens.
+func (te *TokenEdit) commitText() {
+	text := te.edit.Text()
+	if strings.TrimSpace(text) == "" {
+		return
+	}
+
+	te.edit.SetText("")
+	te.hideSuggestions()
+
+	te.addTokens(te.splitTokens(text))
+}
+
+// paste inserts text at the caret and turns all complete entries into
+// tokens.
+func (te *TokenEdit) paste(text string) {
+	current := []rune(te.edit.Text())
+	start, end := te.edit.TextSelection()
+	if start > len(current) {
+		start = len(current)
+	}
+	if end > len(current) {
+		end = len(current)
+	}
+
+	combined := []rune(string(current[:start]) + text + string(current[end:]))
+
+	parts := te.splitTokens(string(combined))
+
+	var rest string
+	if n := len(parts); n > 0 && !te.isSeparator(combined[len(combined)-1]) {
+		// The text after the last separator remains to be completed.
+		rest = strings.TrimSpace(parts[n-1])
+		parts = parts[:n-1]
+	}
+
+	te.edit.SetText(rest)
+	te.edit.SetTextSelection(len([]rune(rest)), len([]rune(rest)))
+
+	te.addTokens(parts)
+}
+
+func (te *TokenEdit) setSelectedToken(index int) {
+	if index < -1 || index >= len(te.tokens) {
+		index = -1
+	}
+	if index == te.selectedToken {
+		return
+	}
+
+	te.selectedToken = index
+	te.Invalidate()
+}
+
+func (te *TokenEdit) updateSuggestions() {
+	text := strings.ToLower(strings.TrimSpace(te.edit.Text()))
+	if text == "" || len(te.suggestions) == 0 {
+		te.hideSuggestions()
+		return
+	}
+
+	existing := make(map[string]bool, len(te.tokens))
+	for _, t := range te.tokens {
+		existing[strings.ToLower(t.text)] = true
+	}
+
+	var matches []string
+	for _, s := range te.suggestions {
+		lower := strings.ToLower(s)
+		if strings.Contains(lower, text) && !existing[lower] {
+			matches = append(matches, s)
+		}
+	}
+
+	if len(matches) == 0 {
+		te.hideSuggestions()
+		return
+	}
+
+	if te.suggestionsPopup == nil {
+		var err error
+		if te.suggestionsPopup, err = newSearchResultsPopup(te); err != nil {
+			return
+		}
+	}
+
+	if err := te.suggestionsPopup.setItems(matches); err != nil {
+		return
+	}
+
+	if win.GetFocus() == te.edit.hWnd {
+		te.suggestionsPopup.show()
+	}
+}
+
+func (te *TokenEdit) suggestionsVisible() bool {
+	return te.suggestionsPopup != nil && win.IsWindowVisible(te.suggestionsPopup.hWnd)
+}
+
+func (te *TokenEdit) hideSuggestions() {
+	if te.suggestionsVisible() {
+		win.ShowWindow(te.suggestionsPopup.hWnd, win.SW_HIDE)
+	}
+}
+
+// activateResult adds the suggestion at index as a token.
+func (te *TokenEdit) activateResult(index int) {
+	if te.suggestionsPopup == nil || index < 0 || index >= len(te.suggestionsPopup.items) {
+		return
+	}
+
+	text := te.suggestionsPopup.items[index]
+
+	te.hideSuggestions()
+	te.edit.SetText("")
+
+	te.addTokens([]string{text})
+}
+
+// tokenEditMetrics holds the dimensions of the parts of a TokenEdit in native
+// pixels.
+type tokenEditMetrics struct {
+	padding      int
+	spacing      int
+	chipPadding  int
+	closeSize    int
+	rowHeight    int
+	editMinWidth int
+}
+
+func (te *TokenEdit) metrics() tokenEditMetrics {
+	textHeight := te.calculateTextSizeImpl("gM").Height
+
+	return tokenEditMetrics{
+		padding:      te.IntFrom96DPI(tokenEditPadding96dpi),
+		spacing:      te.IntFrom96DPI(tokenEditSpacing96dpi),
+		chipPadding:  te.IntFrom96DPI(tokenEditChipPadding96dpi),
+		closeSize:    te.IntFrom96DPI(tokenEditCloseSize96dpi),
+		rowHeight:    textHeight + 2*te.IntFrom96DPI(tokenEditPadding96dpi),
+		editMinWidth: te.IntFrom96DPI(tokenEditEditMinWidth96dpi),
+	}
+}
+
+// itemWidths returns the widths of the chips followed by the minimum width of
+// the edit.
+func (te *TokenEdit) itemWidths(m tokenEditMetrics) []int {
+	widths := make([]int, len(te.tokens)+1)
+	for i, t := range te.tokens {
+		widths[i] = te.calculateTextSizeImpl(t.text).Width + 3*m.chipPadding + m.closeSize
+	}
+	widths[len(te.tokens)] = m.editMinWidth
+
+	return widths
+}
+
+// tokenEditFlow arranges items of the given widths in rows within width, all
+// in native pixels, and returns their bounds along with the total height.
+func tokenEditFlow(widths []int, width int, m tokenEditMetrics) ([]Rectangle, int) {
+	bounds := make([]Rectangle, len(widths))
+
+	maxWidth := width - 2*m.padding
+	x, y := m.padding, m.padding
+
+	for i, w := range widths {
+		if w > maxWidth {
+			w = maxWidth
+		}
+
+		if x > m.padding && x+w > width-m.padding {
+			x = m.padding
+			y += m.rowHeight + m.spacing
+		}
+
+		bounds[i] = Rectangle{x, y, w, m.rowHeight}
+		x += w + m.spacing
+	}
+
+	return bounds, y + m.rowHeight + m.padding
+}
+
+func (te *TokenEdit) layoutTokens() {
+	if te.edit == nil {
+		return
+	}
+
+	m := te.metrics()
+	cb := te.ClientBoundsPixels()
+
+	bounds, _ := tokenEditFlow(te.itemWidths(m), cb.Width, m)
+
+	te.tokenBounds = bounds[:len(te.tokens)]
+
+	// The edit takes the rest of the last row.
+	eb := bounds[len(te.tokens)]
+	eb.Width = cb.Width - m.padding - eb.X
+
+	height := te.calculateTextSizeImpl("gM").Height + 2
+	eb.Y += (eb.Height - height) / 2
+	eb.Height = height
+
+	te.edit.SetBoundsPixels(eb)
+}
+
+// closeBounds returns the bounds of the button removing the token at index.
+func (te *TokenEdit) closeBounds(index int) Rectangle {
+	m := te.metrics()
+	b := te.tokenBounds[index]
+
+	return Rectangle{
+		X:      b.X + b.Width - m.chipPadding - m.closeSize,
+		Y:      b.Y + (b.Height-m.closeSize)/2,
+		Width:  m.closeSize,
+		Height: m.closeSize,
+	}
+}
+
+// hitTest returns the index of the token at pt and whether pt is on its
+// button removing it.
+func (te *TokenEdit) hitTest(pt Point) (index int, onClose bool) {
+	for i, b := range te.tokenBounds {
+		if !rectangleContains(b, pt) {
+			continue
+		}
+
+		cb := te.closeBounds(i)
+		slop := te.IntFrom96DPI(2)
+		cb = Rectangle{cb.X - slop, cb.Y - slop, cb.Width + 2*slop, cb.Height + 2*slop}
+
+		return i, rectangleContains(cb, pt)
+	}
+
+	return -1, false
+}
+
+func rectangleContains(r Rectangle, pt Point) bool {
+	return pt.X >= r.X && pt.X < r.X+r.Width && pt.Y >= r.Y && pt.Y < r.Y+r.Height
+}
+
+func (te *TokenEdit) setHotCloseToken(index int) {
+	if index == te.hotCloseToken {
+		return
+	}
+
+	te.hotCloseToken = index
+	te.Invalidate()
+
+	if index > -1 {
+		var tme win.TRACKMOUSEEVENT
+		tme.CbSize = uint32(unsafe.Sizeof(tme))
+		tme.DwFlags = win.TME_LEAVE
+		tme.HwndTrack = te.hWnd
+
+		win.TrackMouseEvent(&tme)
+	}
+}
+
+func (te *TokenEdit) paint(canvas *Canvas) error {
+	bg, err := NewSystemColorBrush(SysColorWindow)
+	if err != nil {
+		return err
+	}
+	defer bg.Dispose()
+
+	if err := canvas.FillRectanglePixels(bg, te.ClientBoundsPixels()); err != nil {
+		return err
+	}
+
+	m := te.metrics()
+	radius := te.IntFrom96DPI(4)
+	font := te.Font()
+
+	for i, t := range te.tokens {
+		b := te.tokenBounds[i]
+
+		chipColor := Color(win.GetSysColor(win.COLOR_BTNFACE))
+		textColor := Color(win.GetSysColor(win.COLOR_BTNTEXT))
+		switch {
+		case i == te.selectedToken:
+			chipColor = Color(win.GetSysColor(win.COLOR_HIGHLIGHT))
+			textColor = Color(win.GetSysColor(win.COLOR_HIGHLIGHTTEXT))
+
+		case !t.valid:
+			chipColor = RGB(0xFF, 0xDD, 0xDD)
+			textColor = RGB(0xC0, 0x00, 0x00)
+		}
+
+		chipBrush, err := NewSolidColorBrush(chipColor)
+		if err != nil {
+			return err
+		}
+		err = canvas.FillRoundedRectanglePixels(chipBrush, b, Size{radius, radius})
+		chipBrush.Dispose()
+		if err != nil {
+			return err
+		}
+
+		textBounds := Rectangle{b.X + m.chipPadding, b.Y, b.Width - 3*m.chipPadding - m.closeSize, b.Height}
+		if err := canvas.DrawTextPixels(t.text, font, textColor, textBounds, TextLeft|TextVCenter|TextSingleLine|TextEndEllipsis|TextNoPrefix); err != nil {
+			return err
+		}
+
+		if err := te.paintCloseGlyph(canvas, te.closeBounds(i), textColor, i == te.hotCloseToken); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (te *TokenEdit) paintCloseGlyph(canvas *Canvas, b Rectangle, color Color, hot bool) error {
+	brush, err := NewSolidColorBrush(color)
+	if err != nil {
+		return err
+	}
+	defer brush.Dispose()
+
+	width := 1
+	if hot {
+		width = 2
+	}
+
+	pen, err := NewGeometricPen(PenSolid|PenCapRound, width, brush)
+	if err != nil {
+		return err
+	}
+	defer pen.Dispose()
+
+	if err := canvas.DrawLinePixels(pen, Point{b.X, b.Y}, Point{b.X + b.Width, b.Y + b.Height}); err != nil {
+		return err
+	}
+
+	return canvas.DrawLinePixels(pen, Point{b.X + b.Width, b.Y}, Point{b.X, b.Y + b.Height})
+}
+
+func (*TokenEdit) NeedsWmSize() bool {
+	return true
+}
+
+// WndProc is the window procedure of the TokenEdit.
+//
+// When implementing your own WndProc to add or modify behavior, call the
+// WndProc of the embedded TokenEdit for messages you don't handle yourself.
+func (te *TokenEdit) WndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) uintptr {
+	switch msg {
+	case win.WM_PAINT:
+		var ps win.PAINTSTRUCT
+
+		hdc := win.BeginPaint(hwnd, &ps)
+		defer win.EndPaint(hwnd, &ps)
+
+		canvas, err := newCanvasFromHDC(hdc)
+		if err != nil {
+			return 0
+		}
+		defer canvas.Dispose()
+
+		te.paint(canvas)
+
+		return 0
+
+	case win.WM_ERASEBKGND:
+		return 1
+
+	case win.WM_CTLCOLOREDIT:
+		win.SetBkColor(win.HDC(wParam), win.COLORREF(win.GetSysColor(win.COLOR_WINDOW)))
+
+		return uintptr(win.GetSysColorBrush(win.COLOR_WINDOW))
+
+	case win.WM_MOUSEMOVE:
+		index, onClose := te.hitTest(Point{int(win.GET_X_LPARAM(lParam)), int(win.GET_Y_LPARAM(lParam))})
+		if !onClose {
+			index = -1
+		}
+		te.setHotCloseToken(index)
+
+	case win.WM_MOUSELEAVE:
+		te.setHotCloseToken(-1)
+
+	case win.WM_LBUTTONDOWN:
+		index, onClose := te.hitTest(Point{int(win.GET_X_LPARAM(lParam)), int(win.GET_Y_LPARAM(lParam))})
+		if onClose {
+			te.hotCloseToken = -1
+			te.RemoveToken(index)
+		} else {
+			te.setSelectedToken(index)
+		}
+		te.SetFocus()
+
+	case win.WM_WINDOWPOSCHANGED:
+		wp := (*win.WINDOWPOS)(unsafe.Pointer(lParam))
+
+		if wp.Flags&win.SWP_NOSIZE != 0 || te.edit == nil {
+			break
+		}
+
+		te.layoutTokens()
+		te.Invalidate()
+	}
+
+	return te.WidgetBase.WndProc(hwnd, msg, wParam, lParam)
+}
+
+func (te *TokenEdit) CreateLayoutItem(ctx *LayoutContext) LayoutItem {
+	var wr, cr win.RECT
+	win.GetWindowRect(te.hWnd, &wr)
+	win.GetClientRect(te.hWnd, &cr)
+
+	m := te.metrics()
+
+	return &tokenEditLayoutItem{
+		idealWidth: te.dialogBaseUnitsToPixels(Size{100, 0}).Width,
+		minWidth:   te.dialogBaseUnitsToPixels(Size{30, 0}).Width,
+		nonClient:  Size{int(wr.Right-wr.Left) - int(cr.Right), int(wr.Bottom-wr.Top) - int(cr.Bottom)},
+		metrics:    m,
+		widths:     te.itemWidths(m),
+	}
+}
+
+type tokenEditLayoutItem struct {
+	LayoutItemBase
+	idealWidth int  // in native pixels
+	minWidth   int  // in native pixels
+	nonClient  Size // in native pixels
+	metrics    tokenEditMetrics
+	widths     []int // in native pixels
+}
+
+func (*tokenEditLayoutItem) LayoutFlags() LayoutFlags {
+	return ShrinkableHorz | GrowableHorz
+}
+
+func (li *tokenEditLayoutItem) IdealSize() Size {
+	return Size{li.idealWidth, li.HeightForWidth(li.idealWidth)}
+}
+
+func (li *tokenEditLayoutItem) MinSize() Size {
+	return Size{li.minWidth, li.HeightForWidth(li.minWidth)}
+}
+
+func (li *tokenEditLayoutItem) HasHeightForWidth() bool {
+	return true
+}
+
+// HeightForWidth returns the height needed to fit all tokens into rows of
+// the given width.
+func (li *tokenEditLayoutItem) HeightForWidth(width int) int {
+	_, height := tokenEditFlow(li.widths, width-li.nonClient.Width, li.metrics)
+
+	return height + li.nonClient.Height
+}
+
+type tokenLineEdit struct {
+	*LineEdit
+	te *TokenEdit
+}
+
+func newTokenLineEdit(te *TokenEdit) (*tokenLineEdit, error) {
+	tle := &tokenLineEdit{te: te}
+
+	var err error
+	if tle.LineEdit, err = newLineEdit(te); err != nil {
+		return nil, err
+	}
+
+	succeeded := false
+	defer func() {
+		if !succeeded {
+			tle.Dispose()
+		}
+	}()
+
+	if err := tle.ensureExtendedStyleBits(win.WS_EX_CLIENTEDGE, false); err != nil {
+		return nil, err
+	}
+
+	if err := InitWrapperWindow(tle); err != nil {
+		return nil, err
+	}
+
+	succeeded = true
+
+	return tle, nil
+}
+
+// caretAtStart returns whether the caret is at the start of the text, with
+// nothing selected.
+func (tle *tokenLineEdit) caretAtStart() bool {
+	start, end := tle.TextSelection()
+
+	return start == 0 && end == 0
+}
+
+func (tle *tokenLineEdit) onFocusChanged() {
+	if wnd := windowFromHandle(win.GetParent(tle.te.hWnd)); wnd != nil {
+		if _, ok := wnd.(Container); ok {
+			tle.te.invalidateBorderInParent()
+		}
+	}
+}
+
+func (tle *tokenLineEdit) WndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) uintptr {
+	te := tle.te
+
+	switch msg {
+	case win.WM_GETDLGCODE:
+		switch wParam {
+		case win.VK_RETURN:
+			if te.Text() != "" || te.suggestionsVisible() {
+				// Don't let a Dialog accept before the text becomes a token.
+				return win.DLGC_WANTALLKEYS
+			}
+
+		case win.VK_ESCAPE:
+			if te.suggestionsVisible() {
+				return win.DLGC_WANTALLKEYS
+			}
+		}
+
+	case win.WM_KEYDOWN:
+		switch Key(wParam) {
+		case KeyEscape:
+			if te.suggestionsVisible() {
+				te.hideSuggestions()
+				return 0
+			}
+
+		case KeyDown, KeyUp:
+			if te.suggestionsVisible() {
+				if Key(wParam) == KeyDown {
+					te.suggestionsPopup.moveSelection(1)
+				} else {
+					te.suggestionsPopup.moveSelection(-1)
+				}
+				return 0
+			}
+
+		case KeyReturn:
+			if te.suggestionsVisible() {
+				if index := te.suggestionsPopup.selection(); index > -1 {
+					te.activateResult(index)
+					return 0
+				}
+			}
+
+			te.commitText()
+			return 0
+
+		case KeyLeft:
+			if len(te.tokens) > 0 && tle.caretAtStart() {
+				switch {
+				case te.selectedToken == -1:
+					te.setSelectedToken(len(te.tokens) - 1)
+
+				case te.selectedToken > 0:
+					te.setSelectedToken(te.selectedToken - 1)
+				}
+				return 0
+			}
+
+		case KeyRight:
+			if te.selectedToken > -1 {
+				te.setSelectedToken(te.selectedToken + 1)
+				return 0
+			}
+
+		case KeyBack, KeyDelete:
+			if te.selectedToken > -1 {
+				index := te.selectedToken
+				te.RemoveToken(index)
+
+				// Backspace moves on to the previous token, like deleting
+				// characters does.
+				if Key(wParam) == KeyBack && index > 0 {
+					te.setSelectedToken(index - 1)
+				} else if index < len(te.tokens) {
+					te.setSelectedToken(index)
+				}
+				return 0
+			}
+
+			if Key(wParam) == KeyBack && len(te.tokens) > 0 && tle.caretAtStart() {
+				te.setSelectedToken(len(te.tokens) - 1)
+				return 0
+			}
+
+		default:
+			te.setSelectedToken(-1)
+		}
+
+	case win.WM_CHAR:
+		switch {
+		case wParam == win.VK_ESCAPE, wParam == win.VK_RETURN, wParam == win.VK_BACK && te.selectedToken > -1:
+			// Prevent the beep.
+			return 0
+
+		case te.isSeparator(rune(wParam)) && wParam != win.VK_RETURN:
+			te.commitText()
+			return 0
+		}
+
+		te.setSelectedToken(-1)
+
+	case win.WM_PASTE:
+		if text, err := Clipboard().Text(); err == nil && strings.IndexFunc(text, te.isSeparator) > -1 {
+			te.paste(text)
+			return 0
+		}
+
+	case win.WM_SETFOCUS:
+		tle.onFocusChanged()
+
+	case win.WM_KILLFOCUS:
+		tle.onFocusChanged()
+
+		if te.suggestionsPopup == nil || win.HWND(wParam) != te.suggestionsPopup.hWnd {
+			te.hideSuggestions()
+			te.commitText()
+		}
+
+		te.setSelectedToken(-1)
+	}
+
+	return tle.LineEdit.WndProc(hwnd, msg, wParam, lParam)
+}
@@ -31,6 +31,16 @@ type ToolBar struct {
 	defaultButtonWidth int
 	maxTextRows        int
 	buttonStyle        ToolBarButtonStyle
+	overflowEnabled    bool
+	overflowActions    []*Action
+	overflowIdealWidth int // in native pixels
+	chevronHot         bool
+	customizing        bool
+	customizeTexts     [][]uint16
+	removedActions     map[*Action]bool
+	addedSeparators    map[*Action]bool
+	defaultActions     []*Action
+	persistent         bool
 }
 
 func NewToolBarWithOrientationAndButtonStyle(parent Container, orientation Orientation, buttonStyle ToolBarButtonStyle) (*ToolBar, error) {
@@ -267,6 +277,21 @@ func (tb *ToolBar) WndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) ui
 	case win.WM_MOUSEMOVE, win.WM_MOUSELEAVE, win.WM_LBUTTONDOWN:
 		tb.Invalidate()
 
+		x, y := win.GET_X_LPARAM(lParam), win.GET_Y_LPARAM(lParam)
+		tb.setChevronHot(msg != win.WM_MOUSELEAVE && tb.chevronContains(x, y))
+
+		if msg == win.WM_LBUTTONDOWN && tb.chevronContains(x, y) {
+			tb.showOverflowMenu()
+			return 0
+		}
+
+	case win.WM_PAINT:
+		if len(tb.overflowActions) > 0 {
+			result := tb.WidgetBase.WndProc(hwnd, msg, wParam, lParam)
+			tb.paintChevron()
+			return result
+		}
+
 	case win.WM_COMMAND:
 		switch win.HIWORD(uint32(wParam)) {
 		case win.BN_CLICKED:
@@ -280,6 +305,12 @@ func (tb *ToolBar) WndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) ui
 	case win.WM_NOTIFY:
 		nmhdr := (*win.NMHDR)(unsafe.Pointer(lParam))
 
+		switch nmhdr.Code {
+		case _TBN_BEGINADJUST, _TBN_ENDADJUST, _TBN_INITCUSTOMIZE, _TBN_QUERYINSERT, _TBN_QUERYDELETE,
+			_TBN_RESET, _TBN_TOOLBARCHANGE, _TBN_GETBUTTONINFO:
+			return tb.onCustomizeNotify((*win.NMTOOLBAR)(unsafe.Pointer(lParam)))
+		}
+
 		switch int32(nmhdr.Code) {
 		case win.TBN_DROPDOWN:
 			nmtb := (*win.NMTOOLBAR)(unsafe.Pointer(lParam))
@@ -316,6 +347,7 @@ func (tb *ToolBar) WndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) ui
 		}
 
 		tb.SendMessage(win.TB_AUTOSIZE, 0, 0)
+		tb.updateOverflow()
 	}
 
 	return tb.WidgetBase.WndProc(hwnd, msg, wParam, lParam)
@@ -383,6 +415,10 @@ func (tb *ToolBar) initButtonForAction(action *Action, state, style *byte, image
 }
 
 func (tb *ToolBar) onActionChanged(action *Action) error {
+	if tb.removedActions[action] {
+		return nil
+	}
+
 	tbbi := win.TBBUTTONINFO{
 		DwMask: win.TBIF_IMAGE | win.TBIF_STATE | win.TBIF_STYLE | win.TBIF_TEXT,
 		IImage: win.I_IMAGENONE,
@@ -408,6 +444,9 @@ func (tb *ToolBar) onActionChanged(action *Action) error {
 		return newError("SendMessage(TB_SETBUTTONINFO) failed")
 	}
 
+	// Setting the state shows hidden buttons.
+	tb.updateOverflow()
+
 	tb.RequestLayout()
 
 	return nil
@@ -435,11 +474,11 @@ func (tb *ToolBar) insertAction(action *Action, visibleChanged bool) (err error)
 		}()
 	}
 
-	if !action.Visible() {
+	if !action.Visible() || tb.removedActions[action] {
 		return
 	}
 
-	index := tb.actions.indexInObserver(action)
+	index := tb.buttonIndex(action)
 
 	tbb := win.TBBUTTON{
 		IdCommand: int32(action.id),
@@ -469,22 +508,34 @@ func (tb *ToolBar) insertAction(action *Action, visibleChanged bool) (err error)
 
 	tb.SendMessage(win.TB_AUTOSIZE, 0, 0)
 
+	tb.updateOverflow()
+
 	tb.RequestLayout()
 
 	return
 }
 
 func (tb *ToolBar) removeAction(action *Action, visibleChanged bool) error {
-	index := tb.actions.indexInObserver(action)
+	index := tb.buttonIndex(action)
 
 	if !visibleChanged {
 		action.removeChangedHandler(tb)
 	}
 
+	if tb.removedActions[action] {
+		// The user removed its button already.
+		if !visibleChanged {
+			delete(tb.removedActions, action)
+		}
+		return nil
+	}
+
 	if 0 == tb.SendMessage(win.TB_DELETEBUTTON, uintptr(index), 0) {
 		return newError("SendMessage(TB_DELETEBUTTON) failed")
 	}
 
+	tb.updateOverflow()
+
 	tb.RequestLayout()
 
 	return nil
@@ -507,6 +558,10 @@ func (tb *ToolBar) onClearingActions() error {
 		}
 	}
 
+	tb.removedActions = nil
+	tb.addedSeparators = nil
+	tb.defaultActions = nil
+
 	return nil
 }
 
@@ -543,9 +598,21 @@ func (tb *ToolBar) CreateLayoutItem(ctx *LayoutContext) LayoutItem {
 		}
 	}
 
+	// Hidden buttons do not count for the ideal size.
+	if width < tb.overflowIdealWidth {
+		width = tb.overflowIdealWidth
+	}
+
+	minSize := Size{width, height}
+	if tb.overflowEnabled {
+		layoutFlags |= ShrinkableHorz
+		minSize.Width = IntFrom96DPI(toolBarChevronWidth96dpi, dpi)
+	}
+
 	return &toolBarLayoutItem{
 		layoutFlags: layoutFlags,
 		idealSize:   Size{width, height},
+		minSize:     minSize,
 	}
 }
 
@@ -553,6 +620,7 @@ type toolBarLayoutItem struct {
 	LayoutItemBase
 	layoutFlags LayoutFlags
 	idealSize   Size // in native pixels
+	minSize     Size // in native pixels
 }
 
 func (li *toolBarLayoutItem) LayoutFlags() LayoutFlags {
@@ -564,5 +632,5 @@ func (li *toolBarLayoutItem) IdealSize() Size {
 }
 
 func (li *toolBarLayoutItem) MinSize() Size {
-	return li.idealSize
+	return li.minSize
 }
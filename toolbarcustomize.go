@@ -0,0 +1,373 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"strconv"
+	"strings"
+	"syscall"
+	"unsafe"
+
+	"github.com/tailscale/win"
+)
+
+// Customizable returns whether the user can customize the buttons of the
+// ToolBar.
+func (tb *ToolBar) Customizable() bool {
+	return tb.hasStyleBits(win.CCS_ADJUSTABLE)
+}
+
+// SetCustomizable sets whether the user can customize the buttons of the
+// ToolBar, by double-clicking it to open the customization dialog or by
+// dragging buttons with the Alt key down.
+func (tb *ToolBar) SetCustomizable(customizable bool) error {
+	return tb.ensureStyleBits(win.CCS_ADJUSTABLE, customizable)
+}
+
+// Customize opens the standard dialog that lets the user add, remove and
+// rearrange the buttons of the ToolBar.
+func (tb *ToolBar) Customize() {
+	tb.SendMessage(win.TB_CUSTOMIZE, 0, 0)
+}
+
+// ResetButtonLayout restores the buttons of the ToolBar to the order of its
+// actions, undoing any customization by the user.
+func (tb *ToolBar) ResetButtonLayout() error {
+	return tb.applyButtonLayout(tb.defaultLayout(), nil)
+}
+
+func (tb *ToolBar) Persistent() bool {
+	return tb.persistent
+}
+
+func (tb *ToolBar) SetPersistent(value bool) {
+	tb.persistent = value
+}
+
+// SaveState saves the button layout as customized by the user.
+func (tb *ToolBar) SaveState() error {
+	defaults := tb.defaultLayout()
+
+	index := make(map[*Action]int, len(defaults))
+	for i, action := range defaults {
+		index[action] = i
+	}
+
+	var fields []string
+	for _, action := range tb.actions.actions {
+		if tb.removedActions[action] {
+			continue
+		}
+
+		if i, ok := index[action]; ok {
+			fields = append(fields, strconv.Itoa(i))
+		} else if action.IsSeparator() {
+			fields = append(fields, "-")
+		}
+	}
+
+	return tb.WriteState(strings.Join(fields, " "))
+}
+
+// RestoreState restores the button layout saved using SaveState.
+func (tb *ToolBar) RestoreState() error {
+	state, err := tb.ReadState()
+	if err != nil {
+		return err
+	}
+	if state == "" {
+		return nil
+	}
+
+	defaults := tb.defaultLayout()
+
+	var order []*Action
+	listed := make(map[*Action]bool)
+
+	for _, field := range strings.Fields(state) {
+		if field == "-" {
+			order = append(order, NewSeparatorAction())
+			continue
+		}
+
+		i, err := strconv.Atoi(field)
+		if err != nil {
+			return wrapError(err)
+		}
+
+		// The actions may have changed since the state was saved.
+		if i < 0 || i >= len(defaults) || listed[defaults[i]] {
+			continue
+		}
+
+		order = append(order, defaults[i])
+		listed[defaults[i]] = true
+	}
+
+	removed := make(map[*Action]bool)
+	for _, action := range defaults {
+		if !listed[action] {
+			removed[action] = true
+		}
+	}
+
+	return tb.applyButtonLayout(order, removed)
+}
+
+// buttonActions returns the actions that have a button, in button order.
+func (tb *ToolBar) buttonActions() []*Action {
+	var actions []*Action
+	for _, action := range tb.actions.actions {
+		if action.Visible() && !tb.removedActions[action] {
+			actions = append(actions, action)
+		}
+	}
+
+	return actions
+}
+
+// buttonIndex returns the index of the button of action.
+func (tb *ToolBar) buttonIndex(action *Action) int {
+	var index int
+	for _, a := range tb.actions.actions {
+		if a == action {
+			return index
+		}
+		if a.Visible() && !tb.removedActions[a] {
+			index++
+		}
+	}
+
+	return -1
+}
+
+// defaultLayout returns the actions in the order they were added to the
+// ToolBar, excluding separators added by the user.
+func (tb *ToolBar) defaultLayout() []*Action {
+	var layout []*Action
+	contained := make(map[*Action]bool)
+
+	// Actions may have been added or removed since customizing.
+	for _, action := range tb.defaultActions {
+		if tb.actions.Contains(action) {
+			layout = append(layout, action)
+			contained[action] = true
+		}
+	}
+	for _, action := range tb.actions.actions {
+		if !contained[action] && !tb.addedSeparators[action] {
+			layout = append(layout, action)
+		}
+	}
+
+	tb.defaultActions = layout
+
+	return layout
+}
+
+// applyButtonLayout rebuilds the buttons for actions in the given order,
+// without the ones in removed. Actions missing from order are kept after the
+// others.
+func (tb *ToolBar) applyButtonLayout(order []*Action, removed map[*Action]bool) error {
+	// Snapshot the order of the actions before it changes for the first time.
+	tb.defaultLayout()
+
+	for tb.SendMessage(win.TB_BUTTONCOUNT, 0, 0) > 0 {
+		if 0 == tb.SendMessage(win.TB_DELETEBUTTON, 0, 0) {
+			return newError("SendMessage(TB_DELETEBUTTON) failed")
+		}
+	}
+	tb.overflowActions = nil
+
+	inOrder := make(map[*Action]bool, len(order))
+	for _, action := range order {
+		inOrder[action] = true
+	}
+
+	actions := append([]*Action(nil), order...)
+	for _, action := range tb.actions.actions {
+		if inOrder[action] {
+			continue
+		}
+
+		if tb.addedSeparators[action] {
+			// Separators added by the user are gone when removed again.
+			delete(tb.addedSeparators, action)
+			action.removeChangedHandler(tb)
+			action.release()
+			continue
+		}
+
+		actions = append(actions, action)
+	}
+
+	var added []*Action
+	for _, action := range order {
+		if !tb.actions.Contains(action) {
+			added = append(added, action)
+		}
+	}
+
+	tb.actions.actions = actions
+	tb.removedActions = removed
+
+	for _, action := range added {
+		if tb.addedSeparators == nil {
+			tb.addedSeparators = make(map[*Action]bool)
+		}
+		tb.addedSeparators[action] = true
+
+		action.addRef()
+		action.addChangedHandler(tb)
+	}
+
+	for _, action := range actions {
+		if err := tb.insertAction(action, true); err != nil {
+			return err
+		}
+	}
+
+	tb.updateOverflow()
+	tb.RequestLayout()
+
+	return nil
+}
+
+// syncButtonLayout adopts the order of the buttons after the user customized
+// them.
+func (tb *ToolBar) syncButtonLayout() error {
+	var separators []*Action
+	for _, action := range tb.actions.actions {
+		if action.IsSeparator() {
+			separators = append(separators, action)
+		}
+	}
+
+	var order []*Action
+	inOrder := make(map[*Action]bool)
+
+	count := int(tb.SendMessage(win.TB_BUTTONCOUNT, 0, 0))
+	for i := 0; i < count; i++ {
+		var tbb win.TBBUTTON
+		if 0 == tb.SendMessage(win.TB_GETBUTTON, uintptr(i), uintptr(unsafe.Pointer(&tbb))) {
+			continue
+		}
+
+		if tbb.FsStyle&win.BTNS_SEP != 0 {
+			// Separators have no ids, so reuse existing ones in order.
+			if len(separators) > 0 {
+				order = append(order, separators[0])
+				separators = separators[1:]
+			} else {
+				order = append(order, NewSeparatorAction())
+			}
+			continue
+		}
+
+		if action, ok := actionsById[uint16(tbb.IdCommand)]; ok && tb.actions.Contains(action) && !inOrder[action] {
+			order = append(order, action)
+			inOrder[action] = true
+		}
+	}
+
+	removed := make(map[*Action]bool)
+	for _, action := range tb.actions.actions {
+		if action.Visible() && !action.IsSeparator() && !inOrder[action] {
+			removed[action] = true
+		}
+	}
+	// Unused separators are removed as well.
+	for _, action := range separators {
+		removed[action] = true
+	}
+
+	return tb.applyButtonLayout(order, removed)
+}
+
+// onCustomizeNotify handles the notifications of the customization dialog
+// and of dragging buttons.
+func (tb *ToolBar) onCustomizeNotify(nmtb *win.NMTOOLBAR) uintptr {
+	switch nmtb.Hdr.Code {
+	case _TBN_BEGINADJUST:
+		tb.customizing = true
+		tb.updateOverflow()
+
+	case _TBN_ENDADJUST:
+		tb.customizing = false
+		tb.customizeTexts = nil
+		tb.syncButtonLayout()
+
+	case _TBN_INITCUSTOMIZE:
+		return _TBNRF_HIDEHELP
+
+	case _TBN_QUERYINSERT, _TBN_QUERYDELETE:
+		return win.TRUE
+
+	case _TBN_RESET:
+		tb.ResetButtonLayout()
+
+	case _TBN_TOOLBARCHANGE:
+		if !tb.customizing {
+			tb.syncButtonLayout()
+		}
+
+	case _TBN_GETBUTTONINFO:
+		return tb.onGetButtonInfo(nmtb)
+	}
+
+	return 0
+}
+
+// onGetButtonInfo provides the buttons the customization dialog offers.
+func (tb *ToolBar) onGetButtonInfo(nmtb *win.NMTOOLBAR) uintptr {
+	var available []*Action
+	for _, action := range tb.defaultLayout() {
+		if action.Visible() && !action.IsSeparator() {
+			available = append(available, action)
+		}
+	}
+
+	index := int(nmtb.IItem)
+	if index < 0 || index >= len(available) {
+		return win.FALSE
+	}
+
+	action := available[index]
+
+	var text uintptr
+	if err := tb.initButtonForAction(
+		action,
+		&nmtb.TbButton.FsState,
+		&nmtb.TbButton.FsStyle,
+		&nmtb.TbButton.IBitmap,
+		&text); err != nil {
+
+		return win.FALSE
+	}
+
+	title := action.Text()
+	if title == "" {
+		title = action.ToolTip()
+	}
+
+	// The dialog may insert the button later, so the text must stay alive
+	// until it is closed.
+	buf := syscall.StringToUTF16(title)
+	tb.customizeTexts = append(tb.customizeTexts, buf)
+
+	nmtb.TbButton.IdCommand = int32(action.id)
+	nmtb.TbButton.IString = uintptr(unsafe.Pointer(&buf[0]))
+
+	if nmtb.PszText != nil && nmtb.CchText > 0 {
+		dst := (*[1 << 20]uint16)(unsafe.Pointer(nmtb.PszText))[:nmtb.CchText:nmtb.CchText]
+		n := copy(dst[:len(dst)-1], buf)
+		dst[n] = 0
+	}
+
+	return win.TRUE
+}
@@ -0,0 +1,265 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"unsafe"
+
+	"github.com/tailscale/win"
+)
+
+const toolBarChevronWidth96dpi = 16
+
+// OverflowEnabled returns whether buttons that do not fit are hidden behind a
+// chevron button instead of wrapping to another row.
+func (tb *ToolBar) OverflowEnabled() bool {
+	return tb.overflowEnabled
+}
+
+// SetOverflowEnabled sets whether buttons that do not fit are hidden behind a
+// chevron button instead of wrapping to another row. Clicking the chevron
+// button shows the hidden buttons in a menu.
+//
+// This allows the ToolBar to shrink below its ideal width. Only horizontal
+// tool bars support overflow.
+func (tb *ToolBar) SetOverflowEnabled(enabled bool) error {
+	if enabled == tb.overflowEnabled {
+		return nil
+	}
+
+	if tb.Orientation() == Vertical {
+		return newError("vertical tool bars do not support overflow")
+	}
+
+	if err := tb.ensureStyleBits(win.TBSTYLE_WRAPABLE, !enabled); err != nil {
+		return err
+	}
+
+	tb.overflowEnabled = enabled
+
+	tb.SendMessage(win.TB_AUTOSIZE, 0, 0)
+	tb.updateOverflow()
+	tb.RequestLayout()
+
+	return nil
+}
+
+// updateOverflow hides the buttons that do not fit next to the chevron button.
+func (tb *ToolBar) updateOverflow() {
+	count := int(tb.SendMessage(win.TB_BUTTONCOUNT, 0, 0))
+
+	// Show all buttons again to measure them.
+	if tb.overflowActions != nil {
+		for i := 0; i < count; i++ {
+			tb.setButtonHidden(i, false)
+		}
+	}
+	tb.overflowActions = nil
+	tb.overflowIdealWidth = 0
+
+	defer tb.Invalidate()
+
+	if !tb.overflowEnabled || tb.customizing || count == 0 {
+		return
+	}
+
+	var cr win.RECT
+	win.GetClientRect(tb.hWnd, &cr)
+
+	var rc win.RECT
+	tb.SendMessage(win.TB_GETITEMRECT, uintptr(count-1), uintptr(unsafe.Pointer(&rc)))
+	if rc.Right <= cr.Right || cr.Right <= 0 {
+		return
+	}
+
+	tb.overflowIdealWidth = int(rc.Right)
+
+	available := cr.Right - int32(tb.IntFrom96DPI(toolBarChevronWidth96dpi))
+
+	first := count
+	for i := 0; i < count; i++ {
+		tb.SendMessage(win.TB_GETITEMRECT, uintptr(i), uintptr(unsafe.Pointer(&rc)))
+		if rc.Right > available {
+			first = i
+			break
+		}
+	}
+
+	buttonActions := tb.buttonActions()
+	if len(buttonActions) != count {
+		return
+	}
+
+	// Do not leave a separator dangling next to the chevron button.
+	if first > 0 && buttonActions[first-1].IsSeparator() {
+		first--
+	}
+
+	tb.overflowActions = buttonActions[first:]
+
+	for i := first; i < count; i++ {
+		tb.setButtonHidden(i, true)
+	}
+}
+
+func (tb *ToolBar) setButtonHidden(index int, hidden bool) {
+	var tbb win.TBBUTTON
+	if 0 == tb.SendMessage(win.TB_GETBUTTON, uintptr(index), uintptr(unsafe.Pointer(&tbb))) {
+		return
+	}
+
+	state := tbb.FsState &^ win.TBSTATE_HIDDEN
+	if hidden {
+		state |= win.TBSTATE_HIDDEN
+	}
+	if state == tbb.FsState {
+		return
+	}
+
+	tbbi := win.TBBUTTONINFO{
+		DwMask:  win.TBIF_BYINDEX | win.TBIF_STATE,
+		FsState: state,
+	}
+	tbbi.CbSize = uint32(unsafe.Sizeof(tbbi))
+
+	tb.SendMessage(win.TB_SETBUTTONINFO, uintptr(index), uintptr(unsafe.Pointer(&tbbi)))
+}
+
+// chevronRect returns the bounds of the chevron button in native pixels, if
+// it is displayed.
+func (tb *ToolBar) chevronRect() (win.RECT, bool) {
+	if len(tb.overflowActions) == 0 {
+		return win.RECT{}, false
+	}
+
+	var rc win.RECT
+	win.GetClientRect(tb.hWnd, &rc)
+	rc.Left = rc.Right - int32(tb.IntFrom96DPI(toolBarChevronWidth96dpi))
+
+	return rc, true
+}
+
+func (tb *ToolBar) chevronContains(x, y int32) bool {
+	rc, ok := tb.chevronRect()
+
+	return ok && x >= rc.Left && x < rc.Right && y >= rc.Top && y < rc.Bottom
+}
+
+func (tb *ToolBar) setChevronHot(hot bool) {
+	if hot == tb.chevronHot {
+		return
+	}
+
+	tb.chevronHot = hot
+
+	if rc, ok := tb.chevronRect(); ok {
+		win.InvalidateRect(tb.hWnd, &rc, true)
+	}
+}
+
+// showOverflowMenu shows the hidden buttons in a menu below the chevron
+// button.
+func (tb *ToolBar) showOverflowMenu() {
+	rc, ok := tb.chevronRect()
+	if !ok {
+		return
+	}
+
+	menu, err := NewMenu()
+	if err != nil {
+		return
+	}
+	defer menu.Dispose()
+
+	for _, action := range tb.overflowActions {
+		if action.IsSeparator() && menu.Actions().Len() == 0 {
+			continue
+		}
+
+		if err := menu.Actions().Add(action); err != nil {
+			return
+		}
+	}
+
+	p := win.POINT{rc.Right, rc.Bottom}
+	win.ClientToScreen(tb.hWnd, &p)
+
+	actionId := uint16(win.TrackPopupMenuEx(
+		menu.hMenu,
+		win.TPM_NOANIMATION|win.TPM_RETURNCMD|win.TPM_RIGHTALIGN,
+		p.X,
+		p.Y,
+		tb.hWnd,
+		nil))
+
+	if actionId != 0 {
+		if action, ok := actionsById[actionId]; ok {
+			action.raiseTriggered()
+		}
+	}
+}
+
+// paintChevron draws the chevron button over the ToolBar.
+func (tb *ToolBar) paintChevron() error {
+	rc, ok := tb.chevronRect()
+	if !ok {
+		return nil
+	}
+
+	hdc := win.GetDC(tb.hWnd)
+	defer win.ReleaseDC(tb.hWnd, hdc)
+
+	canvas, err := newCanvasFromHDC(hdc)
+	if err != nil {
+		return err
+	}
+	defer canvas.Dispose()
+
+	bounds := Rectangle{int(rc.Left), int(rc.Top), int(rc.Right - rc.Left), int(rc.Bottom - rc.Top)}
+
+	if tb.chevronHot {
+		hotBrush, err := NewSystemColorBrush(SysColor3DLight)
+		if err != nil {
+			return err
+		}
+		defer hotBrush.Dispose()
+
+		if err := canvas.FillRectanglePixels(hotBrush, bounds); err != nil {
+			return err
+		}
+	}
+
+	textBrush, err := NewSolidColorBrush(Color(win.GetSysColor(win.COLOR_BTNTEXT)))
+	if err != nil {
+		return err
+	}
+	defer textBrush.Dispose()
+
+	pen, err := NewGeometricPen(PenSolid|PenCapRound, tb.IntFrom96DPI(1), textBrush)
+	if err != nil {
+		return err
+	}
+	defer pen.Dispose()
+
+	// Two small arrows pointing right, like the chevron of a rebar band.
+	size := tb.IntFrom96DPI(4)
+	x := bounds.X + bounds.Width/2 - size
+	y := bounds.Y + bounds.Height/2 - size
+
+	for i := 0; i < 2; i++ {
+		if err := canvas.DrawPolylinePixels(pen, []Point{
+			{x + i*size, y},
+			{x + i*size + size, y + size},
+			{x + i*size, y + 2*size},
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -20,6 +20,10 @@ const maxToolTipTextLen = 1024 // including NUL terminator
 
 type ToolTip struct {
 	WindowBase
+	tools          map[win.HWND]*toolTipTool
+	title          string
+	titleIcon      uintptr
+	toolTitleShown bool
 }
 
 func NewToolTip() (*ToolTip, error) {
@@ -90,6 +94,12 @@ func (tt *ToolTip) SetErrorTitle(title string) error {
 }
 
 func (tt *ToolTip) setTitle(title string, icon uintptr) error {
+	tt.title, tt.titleIcon = title, icon
+
+	return tt.applyTitle(title, icon)
+}
+
+func (tt *ToolTip) applyTitle(title string, icon uintptr) error {
 	if len(title) > 99 {
 		title = title[:99]
 	}
@@ -192,6 +202,8 @@ func (tt *ToolTip) RemoveTool(tool Widget) error {
 }
 
 func (tt *ToolTip) removeTool(hwnd win.HWND) error {
+	delete(tt.tools, hwnd)
+
 	var ti win.TOOLINFO
 	ti.CbSize = uint32(unsafe.Sizeof(ti))
 	ti.Hwnd = hwnd
@@ -207,6 +219,10 @@ func (tt *ToolTip) Text(tool Widget) string {
 }
 
 func (tt *ToolTip) text(hwnd win.HWND) string {
+	if t := tt.tools[hwnd]; t != nil {
+		return t.text
+	}
+
 	ti := tt.toolInfo(hwnd)
 	if ti == nil {
 		return ""
@@ -220,11 +236,26 @@ func (tt *ToolTip) SetText(tool Widget, text string) error {
 }
 
 func (tt *ToolTip) setText(hwnd win.HWND, text string) error {
+	if t := tt.tools[hwnd]; t != nil {
+		// The text is provided on demand, see onGetDispInfo.
+		t.text = text
+		return nil
+	}
+
 	ti := tt.toolInfo(hwnd)
 	if ti == nil {
 		return newError("unknown tool")
 	}
 
+	ti.LpszText = syscall.StringToUTF16Ptr(truncateToolTipText(text))
+
+	tt.SendMessage(win.TTM_SETTOOLINFO, 0, uintptr(unsafe.Pointer(ti)))
+
+	return nil
+}
+
+// truncateToolTipText truncates text so it fits maxToolTipTextLen.
+func truncateToolTipText(text string) string {
 	n := 0
 	for i, r := range text {
 		if r < 0x10000 {
@@ -233,16 +264,11 @@ func (tt *ToolTip) setText(hwnd win.HWND, text string) error {
 			n += 2 // surrogate pair
 		}
 		if n >= maxToolTipTextLen {
-			text = text[:i]
-			break
+			return text[:i]
 		}
 	}
 
-	ti.LpszText = syscall.StringToUTF16Ptr(text)
-
-	tt.SendMessage(win.TTM_SETTOOLINFO, 0, uintptr(unsafe.Pointer(ti)))
-
-	return nil
+	return text
 }
 
 func (tt *ToolTip) toolInfo(hwnd win.HWND) *win.TOOLINFO {
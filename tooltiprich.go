@@ -0,0 +1,227 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"syscall"
+	"time"
+	"unsafe"
+
+	"github.com/tailscale/win"
+)
+
+// toolTipTool holds the settings of a tool whose text is provided on demand,
+// which is needed to display a title of its own or dynamic text.
+type toolTipTool struct {
+	text     string
+	textFunc func() string
+	title    string
+	icon     *Icon
+	buf      []uint16
+}
+
+// Balloon returns whether the ToolTip is displayed as a balloon with a stem
+// pointing at the tool.
+func (tt *ToolTip) Balloon() bool {
+	return win.GetWindowLong(tt.hWnd, win.GWL_STYLE)&win.TTS_BALLOON != 0
+}
+
+// SetBalloon sets whether the ToolTip is displayed as a balloon with a stem
+// pointing at the tool.
+func (tt *ToolTip) SetBalloon(balloon bool) error {
+	return tt.ensureStyleBits(win.TTS_BALLOON, balloon)
+}
+
+// SetTitleWithIcon sets the title of the ToolTip, which is displayed in bold
+// above the text, along with a custom icon.
+func (tt *ToolTip) SetTitleWithIcon(title string, icon *Icon) error {
+	var hIcon uintptr
+	if icon != nil {
+		hIcon = uintptr(icon.handleForDPI(tt.DPI()))
+	}
+
+	return tt.setTitle(title, hIcon)
+}
+
+// MaxWidth returns the width in 1/96" units at which text is wrapped to
+// multiple lines.
+func (tt *ToolTip) MaxWidth() int {
+	return tt.IntTo96DPI(int(int32(tt.SendMessage(win.TTM_GETMAXTIPWIDTH, 0, 0))))
+}
+
+// SetMaxWidth sets the width in 1/96" units at which text is wrapped to
+// multiple lines. Line breaks in the text are always honored.
+func (tt *ToolTip) SetMaxWidth(width int) {
+	tt.SendMessage(win.TTM_SETMAXTIPWIDTH, 0, uintptr(tt.IntFrom96DPI(width)))
+}
+
+// InitialDelay returns how long the cursor must rest on a tool before the
+// ToolTip is displayed.
+func (tt *ToolTip) InitialDelay() time.Duration {
+	return tt.delay(_TTDT_INITIAL)
+}
+
+// SetInitialDelay sets how long the cursor must rest on a tool before the
+// ToolTip is displayed. A negative duration restores the default.
+func (tt *ToolTip) SetInitialDelay(delay time.Duration) {
+	tt.setDelay(_TTDT_INITIAL, delay)
+}
+
+// AutoPopDelay returns how long the ToolTip remains visible while the cursor
+// rests on a tool.
+func (tt *ToolTip) AutoPopDelay() time.Duration {
+	return tt.delay(_TTDT_AUTOPOP)
+}
+
+// SetAutoPopDelay sets how long the ToolTip remains visible while the cursor
+// rests on a tool. A negative duration restores the default.
+func (tt *ToolTip) SetAutoPopDelay(delay time.Duration) {
+	tt.setDelay(_TTDT_AUTOPOP, delay)
+}
+
+// ReshowDelay returns how long it takes for the ToolTip of another tool to
+// appear when the cursor moves there.
+func (tt *ToolTip) ReshowDelay() time.Duration {
+	return tt.delay(_TTDT_RESHOW)
+}
+
+// SetReshowDelay sets how long it takes for the ToolTip of another tool to
+// appear when the cursor moves there. A negative duration restores the
+// default.
+func (tt *ToolTip) SetReshowDelay(delay time.Duration) {
+	tt.setDelay(_TTDT_RESHOW, delay)
+}
+
+func (tt *ToolTip) delay(kind uintptr) time.Duration {
+	return time.Duration(tt.SendMessage(win.TTM_GETDELAYTIME, kind, 0)) * time.Millisecond
+}
+
+func (tt *ToolTip) setDelay(kind uintptr, delay time.Duration) {
+	ms := int32(-1)
+	if delay >= 0 {
+		ms = int32(mini(int(delay/time.Millisecond), 32767))
+	}
+
+	tt.SendMessage(win.TTM_SETDELAYTIME, kind, uintptr(ms))
+}
+
+// ToolTitle returns the title displayed above the text for tool, if it has
+// one of its own.
+func (tt *ToolTip) ToolTitle(tool Widget) string {
+	if t := tt.tools[tt.hwndForTool(tool)]; t != nil {
+		return t.title
+	}
+
+	return ""
+}
+
+// SetToolTitle sets a title and optional icon to display above the text for
+// tool instead of the title of the ToolTip.
+//
+// For the standard icons, pass IconInformation(), IconWarning() or
+// IconError().
+func (tt *ToolTip) SetToolTitle(tool Widget, title string, icon *Icon) error {
+	t, err := tt.onDemandTool(tt.hwndForTool(tool))
+	if err != nil {
+		return err
+	}
+
+	t.title, t.icon = title, icon
+
+	return nil
+}
+
+// SetTextFunc sets a function that is called right before the ToolTip is
+// displayed for tool, to provide its current text. Passing nil reverts to the
+// text set using SetText.
+func (tt *ToolTip) SetTextFunc(tool Widget, f func() string) error {
+	t, err := tt.onDemandTool(tt.hwndForTool(tool))
+	if err != nil {
+		return err
+	}
+
+	t.textFunc = f
+
+	return nil
+}
+
+// onDemandTool returns the settings of the tool identified by hwnd, making it
+// obtain its text via onGetDispInfo first, if needed.
+func (tt *ToolTip) onDemandTool(hwnd win.HWND) (*toolTipTool, error) {
+	if t := tt.tools[hwnd]; t != nil {
+		return t, nil
+	}
+
+	ti := tt.toolInfo(hwnd)
+	if ti == nil {
+		return nil, newError("unknown tool")
+	}
+
+	t := &toolTipTool{text: win.UTF16PtrToString(ti.LpszText)}
+
+	ti.LpszText = (*uint16)(unsafe.Pointer(win.LPSTR_TEXTCALLBACK))
+	tt.SendMessage(win.TTM_SETTOOLINFO, 0, uintptr(unsafe.Pointer(ti)))
+
+	if tt.tools == nil {
+		tt.tools = make(map[win.HWND]*toolTipTool)
+	}
+	tt.tools[hwnd] = t
+
+	return t, nil
+}
+
+func (tt *ToolTip) onGetDispInfo(di *nmTTDispInfo) {
+	t := tt.tools[win.HWND(di.hdr.IdFrom)]
+	if t == nil {
+		return
+	}
+
+	text := t.text
+	if t.textFunc != nil {
+		text = t.textFunc()
+	}
+
+	// The buffer must outlive the notification.
+	t.buf = syscall.StringToUTF16(truncateToolTipText(text))
+	di.lpszText = &t.buf[0]
+
+	if t.title != "" {
+		var hIcon uintptr
+		if t.icon != nil {
+			hIcon = uintptr(t.icon.handleForDPI(tt.DPI()))
+		}
+
+		tt.applyTitle(t.title, hIcon)
+		tt.toolTitleShown = true
+	}
+}
+
+func (tt *ToolTip) onPop() {
+	if tt.toolTitleShown {
+		tt.toolTitleShown = false
+		tt.applyTitle(tt.title, tt.titleIcon)
+	}
+}
+
+func (tt *ToolTip) WndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) uintptr {
+	if msg == win.WM_NOTIFY {
+		if nmh := (*win.NMHDR)(unsafe.Pointer(lParam)); nmh.HwndFrom == tt.hWnd {
+			switch nmh.Code {
+			case _TTN_GETDISPINFO:
+				tt.onGetDispInfo((*nmTTDispInfo)(unsafe.Pointer(lParam)))
+
+			case _TTN_POP:
+				tt.onPop()
+			}
+
+			return 0
+		}
+	}
+
+	return tt.WindowBase.WndProc(hwnd, msg, wParam, lParam)
+}
@@ -0,0 +1,152 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"github.com/tailscale/win"
+)
+
+// TrayBehavior makes a Form live in the taskbar notification area, as tray
+// applications do: minimizing or closing the Form hides it instead, and
+// activating the NotifyIcon shows it again.
+//
+// The first time the Form is hidden, a message tells the user that the
+// application is still running. It can be changed with SetMessage.
+//
+// Closing the Form programmatically, e.g. from an Exit action in the context
+// menu of the NotifyIcon, still closes it. Hiding a MainWindow on close does
+// not end the application, as TrayBehavior cancels the close; the
+// application still exits once the MainWindow is closed programmatically,
+// unless SetExitOnClose(false) has been called.
+type TrayBehavior struct {
+	form            Form
+	icon            *NotifyIcon
+	hideOnMinimize  bool
+	hideOnClose     bool
+	messageTitle    string
+	messageText     string
+	messageShown    bool
+	minimizedHandle int
+	closingHandle   int
+	activatedHandle int
+	disposingHandle int
+}
+
+// NewTrayBehavior links form and icon until the returned *TrayBehavior or
+// form is disposed. Both minimizing and closing form hide it.
+func NewTrayBehavior(form Form, icon *NotifyIcon) *TrayBehavior {
+	tb := &TrayBehavior{
+		form:           form,
+		icon:           icon,
+		hideOnMinimize: true,
+		hideOnClose:    true,
+		messageTitle:   App().ProductName(),
+		messageText:    tr("The application is still running. Click the icon to open it again.", "walk"),
+	}
+
+	fb := form.AsFormBase()
+
+	tb.minimizedHandle = fb.Minimized().Attach(func() {
+		if tb.hideOnMinimize {
+			tb.Hide()
+		}
+	})
+	tb.closingHandle = form.Closing().Attach(func(canceled *bool, reason CloseReason) {
+		if tb.hideOnClose && reason == CloseReasonUser {
+			*canceled = true
+			tb.Hide()
+		}
+	})
+	tb.activatedHandle = icon.Activated().Attach(tb.Show)
+	tb.disposingHandle = form.Disposing().Attach(tb.Dispose)
+
+	return tb
+}
+
+// Dispose unlinks the Form and the NotifyIcon. Neither is disposed.
+func (tb *TrayBehavior) Dispose() {
+	if tb.form == nil {
+		return
+	}
+
+	tb.form.AsFormBase().Minimized().Detach(tb.minimizedHandle)
+	tb.form.Closing().Detach(tb.closingHandle)
+	tb.form.Disposing().Detach(tb.disposingHandle)
+	tb.icon.Activated().Detach(tb.activatedHandle)
+
+	tb.form = nil
+	tb.icon = nil
+}
+
+// HideOnMinimize returns whether minimizing the Form hides it.
+func (tb *TrayBehavior) HideOnMinimize() bool {
+	return tb.hideOnMinimize
+}
+
+// SetHideOnMinimize sets whether minimizing the Form hides it.
+func (tb *TrayBehavior) SetHideOnMinimize(value bool) {
+	tb.hideOnMinimize = value
+}
+
+// HideOnClose returns whether closing the Form by the user, e.g. using the
+// close button, hides it.
+func (tb *TrayBehavior) HideOnClose() bool {
+	return tb.hideOnClose
+}
+
+// SetHideOnClose sets whether closing the Form by the user, e.g. using the
+// close button, hides it.
+func (tb *TrayBehavior) SetHideOnClose(value bool) {
+	tb.hideOnClose = value
+}
+
+// SetMessage sets the message shown the first time the Form is hidden. An
+// empty text disables the message.
+func (tb *TrayBehavior) SetMessage(title, text string) {
+	tb.messageTitle = title
+	tb.messageText = text
+}
+
+// Hide hides the Form and makes sure the NotifyIcon is visible, so the user
+// can show the Form again.
+func (tb *TrayBehavior) Hide() {
+	if tb.form == nil {
+		return
+	}
+
+	tb.form.Hide()
+
+	if !tb.icon.Visible() {
+		tb.icon.SetVisible(true)
+	}
+
+	if !tb.messageShown && tb.messageText != "" {
+		tb.messageShown = true
+		tb.icon.ShowInfo(tb.messageTitle, tb.messageText)
+	}
+}
+
+// Show shows the Form again, restores it if it is minimized and brings it to
+// the foreground.
+func (tb *TrayBehavior) Show() {
+	if tb.form == nil {
+		return
+	}
+
+	fb := tb.form.AsFormBase()
+
+	if !fb.Visible() {
+		fb.SetVisible(true)
+	}
+
+	if win.IsIconic(fb.hWnd) {
+		win.ShowWindow(fb.hWnd, win.SW_RESTORE)
+	}
+
+	win.SetForegroundWindow(fb.hWnd)
+}
@@ -0,0 +1,438 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"syscall"
+	"unsafe"
+
+	"github.com/tailscale/win"
+)
+
+// TreeTableView is a TableView that displays a hierarchy of items, like a
+// TreeView, with the values of the items in multiple columns. Items with
+// children can be expanded and collapsed in the first column.
+//
+// The items are provided by a TreeTableModel, so SetModel and Model take one
+// instead of the models supported by TableView.
+type TreeTableView struct {
+	*TableView
+	model                     TreeTableModel
+	rows                      *treeTableViewRows
+	expanded                  map[TreeItem]bool
+	itemsResetHandlerHandle   int
+	itemChangedHandlerHandle  int
+	itemInsertedHandlerHandle int
+	itemRemovedHandlerHandle  int
+	expandedChangedPublisher  TreeItemEventPublisher
+}
+
+// NewTreeTableView creates and returns a *TreeTableView as child of the
+// specified Container.
+func NewTreeTableView(parent Container) (*TreeTableView, error) {
+	tv, err := NewTableView(parent)
+	if err != nil {
+		return nil, err
+	}
+
+	ttv := &TreeTableView{
+		TableView: tv,
+		expanded:  make(map[TreeItem]bool),
+	}
+	ttv.rows = &treeTableViewRows{ttv: ttv}
+
+	if err := InitWrapperWindow(ttv); err != nil {
+		ttv.Dispose()
+		return nil, err
+	}
+
+	tv.tree = ttv
+
+	if err := tv.SetModel(ttv.rows); err != nil {
+		ttv.Dispose()
+		return nil, err
+	}
+
+	// The list views indent items by multiples of the image width.
+	tv.ensureImageList()
+
+	return ttv, nil
+}
+
+// Model returns the model of the TreeTableView.
+func (ttv *TreeTableView) Model() TreeTableModel {
+	return ttv.model
+}
+
+// SetModel sets the model of the TreeTableView. All items are initially
+// collapsed.
+func (ttv *TreeTableView) SetModel(model TreeTableModel) error {
+	if ttv.model != nil {
+		ttv.model.ItemsReset().Detach(ttv.itemsResetHandlerHandle)
+		ttv.model.ItemChanged().Detach(ttv.itemChangedHandlerHandle)
+		ttv.model.ItemInserted().Detach(ttv.itemInsertedHandlerHandle)
+		ttv.model.ItemRemoved().Detach(ttv.itemRemovedHandlerHandle)
+	}
+
+	ttv.model = model
+	ttv.expanded = make(map[TreeItem]bool)
+
+	if model != nil {
+		ttv.itemsResetHandlerHandle = model.ItemsReset().Attach(func(parent TreeItem) {
+			ttv.resetRows()
+		})
+		ttv.itemChangedHandlerHandle = model.ItemChanged().Attach(func(item TreeItem) {
+			if row := ttv.rows.indexOf(item); row > -1 {
+				ttv.rows.PublishRowChanged(row)
+			}
+		})
+		ttv.itemInsertedHandlerHandle = model.ItemInserted().Attach(func(item TreeItem) {
+			ttv.resetRows()
+		})
+		ttv.itemRemovedHandlerHandle = model.ItemRemoved().Attach(func(item TreeItem) {
+			delete(ttv.expanded, item)
+			ttv.resetRows()
+		})
+	}
+
+	ttv.resetRows()
+
+	return nil
+}
+
+// CurrentItem returns the item at the current index, or nil.
+func (ttv *TreeTableView) CurrentItem() TreeItem {
+	if index := ttv.CurrentIndex(); index > -1 && index < len(ttv.rows.items) {
+		return ttv.rows.items[index]
+	}
+
+	return nil
+}
+
+// SetCurrentItem makes item current, expanding its ancestors as necessary.
+// nil clears the current item.
+func (ttv *TreeTableView) SetCurrentItem(item TreeItem) error {
+	if item == nil {
+		return ttv.SetCurrentIndex(-1)
+	}
+
+	if err := ttv.expandAncestors(item); err != nil {
+		return err
+	}
+
+	index := ttv.rows.indexOf(item)
+	if index == -1 {
+		return newError("item not found")
+	}
+
+	return ttv.SetCurrentIndex(index)
+}
+
+// ItemAt returns the item displayed at index.
+func (ttv *TreeTableView) ItemAt(index int) TreeItem {
+	return ttv.rows.items[index]
+}
+
+// IndexOf returns the index at which item is displayed, or -1 if it is not
+// displayed because an ancestor is collapsed.
+func (ttv *TreeTableView) IndexOf(item TreeItem) int {
+	return ttv.rows.indexOf(item)
+}
+
+// Expanded returns whether the children of item are displayed.
+func (ttv *TreeTableView) Expanded(item TreeItem) bool {
+	return ttv.expanded[item]
+}
+
+// SetExpanded sets whether the children of item are displayed. If an
+// ancestor of item is collapsed, the state takes effect once it is expanded.
+func (ttv *TreeTableView) SetExpanded(item TreeItem, expanded bool) error {
+	if expanded == ttv.expanded[item] {
+		return nil
+	}
+
+	if expanded {
+		ttv.expanded[item] = true
+	} else {
+		delete(ttv.expanded, item)
+	}
+
+	if row := ttv.rows.indexOf(item); row > -1 {
+		level := ttv.rows.levels[row]
+
+		if expanded {
+			var items []TreeItem
+			var levels []int
+			ttv.appendChildren(&items, &levels, item, level+1)
+
+			if len(items) > 0 {
+				ttv.rows.insert(row+1, items, levels)
+				ttv.rows.PublishRowsInserted(row+1, row+len(items))
+			}
+		} else {
+			n := 0
+			for i := row + 1; i < len(ttv.rows.levels) && ttv.rows.levels[i] > level; i++ {
+				n++
+			}
+
+			if n > 0 {
+				ttv.rows.remove(row+1, n)
+				ttv.rows.PublishRowsRemoved(row+1, row+n)
+			}
+		}
+
+		// Update the glyph.
+		ttv.rows.PublishRowChanged(row)
+	}
+
+	ttv.expandedChangedPublisher.Publish(item)
+
+	return nil
+}
+
+// ExpandedChanged returns the event that is published when an item was
+// expanded or collapsed.
+func (ttv *TreeTableView) ExpandedChanged() *TreeItemEvent {
+	return ttv.expandedChangedPublisher.Event()
+}
+
+func (ttv *TreeTableView) expandAncestors(item TreeItem) error {
+	var ancestors []TreeItem
+	for parent := item.Parent(); parent != nil; parent = parent.Parent() {
+		ancestors = append(ancestors, parent)
+	}
+
+	for i := len(ancestors) - 1; i >= 0; i-- {
+		if err := ttv.SetExpanded(ancestors[i], true); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// resetRows rebuilds the displayed items from the model.
+func (ttv *TreeTableView) resetRows() {
+	var items []TreeItem
+	var levels []int
+
+	if ttv.model != nil {
+		for i, n := 0, ttv.model.RootCount(); i < n; i++ {
+			ttv.appendItem(&items, &levels, ttv.model.RootAt(i), 0)
+		}
+	}
+
+	ttv.rows.items = items
+	ttv.rows.levels = levels
+
+	ttv.rows.PublishRowsReset()
+}
+
+// appendItem appends item and its displayed descendants.
+func (ttv *TreeTableView) appendItem(items *[]TreeItem, levels *[]int, item TreeItem, level int) {
+	*items = append(*items, item)
+	*levels = append(*levels, level)
+
+	if ttv.expanded[item] {
+		ttv.appendChildren(items, levels, item, level+1)
+	}
+}
+
+func (ttv *TreeTableView) appendChildren(items *[]TreeItem, levels *[]int, parent TreeItem, level int) {
+	for i, n := 0, parent.ChildCount(); i < n; i++ {
+		ttv.appendItem(items, levels, parent.ChildAt(i), level)
+	}
+}
+
+func (ttv *TreeTableView) hasChildren(item TreeItem) bool {
+	if hc, ok := item.(HasChilder); ok {
+		return hc.HasChild()
+	}
+
+	return item.ChildCount() > 0
+}
+
+// glyphRect returns the bounds of the expand glyph of row in hwnd, if hwnd
+// displays the first column.
+func (ttv *TreeTableView) glyphRect(hwnd win.HWND, row int) (win.RECT, bool) {
+	if ttv.fromLVColIdx(hwnd == ttv.hwndFrozenLV, 0) != 0 {
+		return win.RECT{}, false
+	}
+
+	rc := win.RECT{Top: 0, Left: win.LVIR_ICON}
+	if 0 == win.SendMessage(hwnd, win.LVM_GETSUBITEMRECT, uintptr(row), uintptr(unsafe.Pointer(&rc))) {
+		return win.RECT{}, false
+	}
+
+	// The glyph occupies the last level of indentation.
+	width := int32(win.GetSystemMetricsForDpi(win.SM_CXSMICON, uint32(ttv.DPI())))
+
+	return win.RECT{rc.Left - width, rc.Top, rc.Left, rc.Bottom}, true
+}
+
+func (ttv *TreeTableView) drawGlyph(hwnd win.HWND, hdc win.HDC, row int) {
+	item := ttv.rows.items[row]
+	if !ttv.hasChildren(item) {
+		return
+	}
+
+	rc, ok := ttv.glyphRect(hwnd, row)
+	if !ok {
+		return
+	}
+
+	expanded := ttv.expanded[item]
+
+	if hTheme := win.OpenThemeData(hwnd, syscall.StringToUTF16Ptr("TreeView")); hTheme != 0 {
+		defer win.CloseThemeData(hTheme)
+
+		state := int32(_GLPS_CLOSED)
+		if expanded {
+			state = _GLPS_OPENED
+		}
+
+		var size win.SIZE
+		if win.SUCCEEDED(win.GetThemePartSize(hTheme, hdc, win.TVP_GLYPH, state, nil, win.TS_DRAW, &size)) {
+			rc.Left += (rc.Right - rc.Left - size.CX) / 2
+			rc.Top += (rc.Bottom - rc.Top - size.CY) / 2
+			rc.Right = rc.Left + size.CX
+			rc.Bottom = rc.Top + size.CY
+		}
+
+		win.DrawThemeBackground(hTheme, hdc, win.TVP_GLYPH, state, &rc, nil)
+		return
+	}
+
+	// Without visual styles, draw a classic plus or minus box.
+	canvas, err := newCanvasFromHDC(hdc)
+	if err != nil {
+		return
+	}
+	defer canvas.Dispose()
+
+	pen, err := NewCosmeticPen(PenSolid, Color(win.GetSysColor(win.COLOR_GRAYTEXT)))
+	if err != nil {
+		return
+	}
+	defer pen.Dispose()
+
+	size := IntFrom96DPI(9, ttv.DPI())
+	x := int(rc.Left) + (int(rc.Right-rc.Left)-size)/2
+	y := int(rc.Top) + (int(rc.Bottom-rc.Top)-size)/2
+	mid := size / 2
+
+	canvas.DrawRectanglePixels(pen, Rectangle{x, y, size, size})
+	canvas.DrawLinePixels(pen, Point{x + 2, y + mid}, Point{x + size - 2, y + mid})
+	if !expanded {
+		canvas.DrawLinePixels(pen, Point{x + mid, y + 2}, Point{x + mid, y + size - 2})
+	}
+}
+
+// handleGlyphClick toggles the item at row if p, in client coordinates of
+// hwnd, is on its glyph.
+func (ttv *TreeTableView) handleGlyphClick(hwnd win.HWND, row int, p win.POINT) bool {
+	if row < 0 || row >= len(ttv.rows.items) {
+		return false
+	}
+
+	item := ttv.rows.items[row]
+	if !ttv.hasChildren(item) {
+		return false
+	}
+
+	rc, ok := ttv.glyphRect(hwnd, row)
+	if !ok || p.X < rc.Left || p.X >= rc.Right || p.Y < rc.Top || p.Y >= rc.Bottom {
+		return false
+	}
+
+	ttv.SetExpanded(item, !ttv.expanded[item])
+
+	return true
+}
+
+// handleKeyDown expands and collapses items and navigates the hierarchy
+// using the arrow keys, like TreeView.
+func (ttv *TreeTableView) handleKeyDown(key uintptr) bool {
+	item := ttv.CurrentItem()
+	if item == nil {
+		return false
+	}
+
+	switch key {
+	case win.VK_LEFT, win.VK_SUBTRACT:
+		if ttv.expanded[item] {
+			ttv.SetExpanded(item, false)
+		} else if parent := item.Parent(); parent != nil && key == win.VK_LEFT {
+			ttv.SetCurrentItem(parent)
+		}
+		return true
+
+	case win.VK_RIGHT, win.VK_ADD:
+		if !ttv.hasChildren(item) {
+			return key == win.VK_RIGHT
+		}
+
+		if !ttv.expanded[item] {
+			ttv.SetExpanded(item, true)
+		} else if key == win.VK_RIGHT {
+			ttv.SetCurrentIndex(ttv.CurrentIndex() + 1)
+		}
+		return true
+	}
+
+	return false
+}
+
+// treeTableViewRows is the TableModel of the items a TreeTableView displays,
+// i.e. the items whose ancestors are all expanded.
+type treeTableViewRows struct {
+	TableModelBase
+	ttv    *TreeTableView
+	items  []TreeItem
+	levels []int
+}
+
+func (r *treeTableViewRows) RowCount() int {
+	return len(r.items)
+}
+
+func (r *treeTableViewRows) Value(row, col int) interface{} {
+	return r.ttv.model.Value(r.items[row], col)
+}
+
+func (r *treeTableViewRows) Image(row int) interface{} {
+	if imager, ok := r.items[row].(Imager); ok {
+		return imager.Image()
+	}
+
+	return nil
+}
+
+func (r *treeTableViewRows) ID(row int) interface{} {
+	return r.items[row]
+}
+
+func (r *treeTableViewRows) indexOf(item TreeItem) int {
+	for i, it := range r.items {
+		if it == item {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func (r *treeTableViewRows) insert(index int, items []TreeItem, levels []int) {
+	r.items = append(r.items[:index], append(items, r.items[index:]...)...)
+	r.levels = append(r.levels[:index], append(levels, r.levels[index:]...)...)
+}
+
+func (r *treeTableViewRows) remove(index, count int) {
+	r.items = append(r.items[:index], r.items[index+count:]...)
+	r.levels = append(r.levels[:index], r.levels[index+count:]...)
+}
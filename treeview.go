@@ -8,6 +8,7 @@ package walk
 
 import (
 	"syscall"
+	"time"
 	"unsafe"
 
 	"github.com/tailscale/win"
@@ -18,6 +19,22 @@ type treeViewItemInfo struct {
 	child2Handle map[TreeItem]win.HTREEITEM
 }
 
+// treeViewDropPosition specifies where a dragged item is dropped relative to
+// the item below the cursor.
+type treeViewDropPosition int
+
+const (
+	treeViewDropBefore treeViewDropPosition = iota
+	treeViewDropInto
+	treeViewDropAfter
+)
+
+const (
+	treeViewDragTimerId         = 0x7744
+	treeViewDragTimerElapse     = 100 // in milliseconds
+	treeViewDragAutoExpandDelay = 700 * time.Millisecond
+)
+
 type TreeView struct {
 	WidgetBase
 	model                          TreeModel
@@ -33,6 +50,17 @@ type TreeView struct {
 	usingSysIml                    bool
 	imageUintptr2Index             map[uintptr]int32
 	filePath2IconIndex             map[string]int32
+	checkStates                    map[TreeItem]CheckState
+	updatingCheckStates            bool
+	checkedChangedPublisher        TreeItemEventPublisher
+	reorderable                    bool
+	dragItem                       TreeItem
+	hDragIml                       win.HIMAGELIST
+	dragOffset                     Point // of the client area, in window coordinates
+	dropTarget                     TreeItem
+	dropPosition                   treeViewDropPosition
+	dropValid                      bool
+	dropHoverStart                 time.Time
 	expandedChangedPublisher       TreeItemEventPublisher
 	currentItemChangedPublisher    EventPublisher
 	itemActivatedPublisher         EventPublisher
@@ -138,6 +166,7 @@ func (tv *TreeView) SetModel(model TreeModel) error {
 	}
 
 	tv.model = model
+	tv.checkStates = make(map[TreeItem]CheckState)
 
 	if model != nil {
 		tv.lazyPopulation = model.LazyPopulation()
@@ -188,12 +217,26 @@ func (tv *TreeView) SetModel(model TreeModel) error {
 			if _, err := tv.insertItemAfter(item, hInsertAfter); err != nil {
 				return
 			}
+
+			if tv.CheckBoxes() {
+				if parent != nil && tv.checkStates[parent] == CheckChecked {
+					tv.applyCheckState(item, CheckChecked)
+				} else {
+					tv.publishCheckedChanged(tv.updateAncestorCheckStates(item, nil))
+				}
+			}
 		})
 
 		tv.itemRemovedEventHandlerHandle = model.ItemRemoved().Attach(func(item TreeItem) {
 			if err := tv.removeItem(item); err != nil {
 				return
 			}
+
+			if tv.CheckBoxes() {
+				delete(tv.checkStates, item)
+
+				tv.publishCheckedChanged(tv.updateAncestorCheckStates(item, nil))
+			}
 		})
 	}
 
@@ -375,6 +418,7 @@ func (tv *TreeView) insertItemAfter(item TreeItem, hInsertAfter win.HTREEITEM) (
 	tvi.CChildren = win.I_CHILDRENCALLBACK
 
 	tv.setTVITEMImageInfo(tvi, item)
+	tv.setTVITEMCheckState(tvi, item)
 
 	parent := item.Parent()
 
@@ -544,6 +588,487 @@ func (tv *TreeView) SetExpanded(item TreeItem, expanded bool) error {
 	return nil
 }
 
+// CheckBoxes returns whether the items have checkboxes.
+func (tv *TreeView) CheckBoxes() bool {
+	return tv.hasStyleBits(win.TVS_CHECKBOXES)
+}
+
+// SetCheckBoxes sets whether the items have checkboxes.
+//
+// Checking or unchecking an item does the same to all of its descendants. An
+// item with both checked and unchecked descendants is displayed as
+// indeterminate.
+func (tv *TreeView) SetCheckBoxes(checkBoxes bool) error {
+	if checkBoxes == tv.CheckBoxes() {
+		return nil
+	}
+
+	var exStyle uintptr
+	if checkBoxes {
+		exStyle = win.TVS_EX_PARTIALCHECKBOXES
+	}
+
+	if hr := win.HRESULT(tv.SendMessage(win.TVM_SETEXTENDEDSTYLE, win.TVS_EX_PARTIALCHECKBOXES, exStyle)); win.FAILED(hr) {
+		return errorFromHRESULT("TVM_SETEXTENDEDSTYLE", hr)
+	}
+
+	if err := tv.ensureStyleBits(win.TVS_CHECKBOXES, checkBoxes); err != nil {
+		return err
+	}
+
+	if !checkBoxes {
+		// The control does not dispose of the state image list it created.
+		if hIml := win.HIMAGELIST(tv.SendMessage(win.TVM_SETIMAGELIST, _TVSIL_STATE, 0)); hIml != 0 {
+			win.ImageList_Destroy(hIml)
+		}
+	}
+
+	return tv.resetItems()
+}
+
+// CheckState returns the check state of item.
+func (tv *TreeView) CheckState(item TreeItem) CheckState {
+	return tv.checkStates[item]
+}
+
+// Checked returns whether item is checked.
+func (tv *TreeView) Checked(item TreeItem) bool {
+	return tv.checkStates[item] == CheckChecked
+}
+
+// SetChecked checks or unchecks item and all of its descendants, and updates
+// the check state of its ancestors accordingly.
+func (tv *TreeView) SetChecked(item TreeItem, checked bool) error {
+	if item == nil || tv.model == nil {
+		return newError("invalid item")
+	}
+
+	state := CheckUnchecked
+	if checked {
+		state = CheckChecked
+	}
+
+	tv.applyCheckState(item, state)
+
+	return nil
+}
+
+// CheckedItems returns the checked items, in depth-first order.
+func (tv *TreeView) CheckedItems() []TreeItem {
+	if tv.model == nil {
+		return nil
+	}
+
+	var items []TreeItem
+
+	var collect func(item TreeItem)
+	collect = func(item TreeItem) {
+		switch tv.checkStates[item] {
+		case CheckUnchecked:
+			// Descendants of unchecked items are unchecked as well.
+			return
+
+		case CheckChecked:
+			items = append(items, item)
+		}
+
+		for i := 0; i < item.ChildCount(); i++ {
+			collect(item.ChildAt(i))
+		}
+	}
+
+	for i := 0; i < tv.model.RootCount(); i++ {
+		collect(tv.model.RootAt(i))
+	}
+
+	return items
+}
+
+// CheckedChanged returns the event that is published when the check state of
+// an item changes, whether by the user, by SetChecked or because the state of
+// a related item changed.
+func (tv *TreeView) CheckedChanged() *TreeItemEvent {
+	return tv.checkedChangedPublisher.Event()
+}
+
+func (tv *TreeView) applyCheckState(item TreeItem, state CheckState) {
+	var changed []TreeItem
+
+	var apply func(item TreeItem)
+	apply = func(item TreeItem) {
+		if tv.checkStates[item] != state {
+			tv.setItemCheckState(item, state)
+			changed = append(changed, item)
+		}
+
+		for i := 0; i < item.ChildCount(); i++ {
+			apply(item.ChildAt(i))
+		}
+	}
+
+	apply(item)
+
+	tv.publishCheckedChanged(tv.updateAncestorCheckStates(item, changed))
+}
+
+// updateAncestorCheckStates derives the check states of the ancestors of item
+// from their children and returns changed with the ancestors appended whose
+// state changed.
+func (tv *TreeView) updateAncestorCheckStates(item TreeItem, changed []TreeItem) []TreeItem {
+	for parent := item.Parent(); parent != nil; parent = parent.Parent() {
+		count := parent.ChildCount()
+		if count == 0 {
+			break
+		}
+
+		states := make([]CheckState, count)
+		for i := range states {
+			states[i] = tv.checkStates[parent.ChildAt(i)]
+		}
+
+		state := AggregateCheckState(states...)
+
+		if state == tv.checkStates[parent] {
+			break
+		}
+
+		tv.setItemCheckState(parent, state)
+		changed = append(changed, parent)
+	}
+
+	return changed
+}
+
+func (tv *TreeView) publishCheckedChanged(items []TreeItem) {
+	for _, item := range items {
+		tv.checkedChangedPublisher.Publish(item)
+	}
+}
+
+func (tv *TreeView) setItemCheckState(item TreeItem, state CheckState) {
+	if state == CheckUnchecked {
+		delete(tv.checkStates, item)
+	} else {
+		tv.checkStates[item] = state
+	}
+
+	info := tv.item2Info[item]
+	if info == nil || !tv.CheckBoxes() {
+		return
+	}
+
+	tvi := &win.TVITEM{HItem: info.handle}
+	tv.setTVITEMCheckState(tvi, item)
+
+	tv.updatingCheckStates = true
+	defer func() {
+		tv.updatingCheckStates = false
+	}()
+
+	tv.SendMessage(win.TVM_SETITEM, 0, uintptr(unsafe.Pointer(tvi)))
+}
+
+func (tv *TreeView) setTVITEMCheckState(tvi *win.TVITEM, item TreeItem) {
+	if !tv.CheckBoxes() {
+		return
+	}
+
+	// The state images of TVS_EX_PARTIALCHECKBOXES are unchecked, checked
+	// and partially checked, in this order.
+	var index uint32 = 1
+	switch tv.checkStates[item] {
+	case CheckChecked:
+		index = 2
+
+	case CheckIndeterminate:
+		index = 3
+	}
+
+	tvi.Mask |= win.TVIF_STATE
+	tvi.StateMask |= win.TVIS_STATEIMAGEMASK
+	tvi.State |= index << 12
+}
+
+// Reorderable returns whether the user can move items by drag and drop.
+func (tv *TreeView) Reorderable() bool {
+	return tv.reorderable
+}
+
+// SetReorderable sets whether the user can move items by drag and drop. This
+// requires the model to implement TreeItemMover.
+//
+// While dragging, items expand when hovered for a moment and the TreeView
+// scrolls when the cursor is near its top or bottom edge.
+func (tv *TreeView) SetReorderable(reorderable bool) {
+	tv.reorderable = reorderable
+}
+
+func (tv *TreeView) beginDrag(item TreeItem, p Point) {
+	info := tv.item2Info[item]
+	if info == nil {
+		return
+	}
+
+	var wr win.RECT
+	win.GetWindowRect(tv.hWnd, &wr)
+	var origin win.POINT
+	win.ClientToScreen(tv.hWnd, &origin)
+	tv.dragOffset = Point{int(origin.X - wr.Left), int(origin.Y - wr.Top)}
+
+	// TVM_CREATEDRAGIMAGE fails for items without image, in which case only
+	// the drop markers are displayed.
+	tv.hDragIml = win.HIMAGELIST(tv.SendMessage(win.TVM_CREATEDRAGIMAGE, 0, uintptr(info.handle)))
+	if tv.hDragIml != 0 {
+		imageList_BeginDrag(tv.hDragIml, 0, 0, 0)
+		imageList_DragEnter(tv.hWnd, int32(p.X+tv.dragOffset.X), int32(p.Y+tv.dragOffset.Y))
+	}
+
+	tv.dragItem = item
+	tv.dropTarget = nil
+	tv.dropValid = false
+
+	win.SetCapture(tv.hWnd)
+	win.SetTimer(tv.hWnd, treeViewDragTimerId, treeViewDragTimerElapse, 0)
+}
+
+func (tv *TreeView) endDrag(drop bool) {
+	item := tv.dragItem
+	if item == nil {
+		return
+	}
+
+	// ReleaseCapture sends WM_CAPTURECHANGED, which must not end the drag
+	// again.
+	tv.dragItem = nil
+
+	win.KillTimer(tv.hWnd, treeViewDragTimerId)
+
+	if tv.hDragIml != 0 {
+		imageList_DragLeave(tv.hWnd)
+		imageList_EndDrag()
+		win.ImageList_Destroy(tv.hDragIml)
+		tv.hDragIml = 0
+	}
+
+	tv.SendMessage(win.TVM_SETINSERTMARK, 0, 0)
+	tv.SendMessage(win.TVM_SELECTITEM, _TVGN_DROPHILITE, 0)
+
+	win.ReleaseCapture()
+
+	if !drop || !tv.dropValid {
+		return
+	}
+
+	mover, ok := tv.model.(TreeItemMover)
+	if !ok {
+		return
+	}
+
+	parent, index := tv.dropDestination(tv.dropTarget, tv.dropPosition)
+
+	if parent == item.Parent() {
+		// Dropping an item next to itself does not move it.
+		if current := tv.indexOfItem(item); index == current || index == current+1 {
+			return
+		}
+	}
+
+	if err := mover.MoveItem(item, parent, index); err != nil {
+		return
+	}
+
+	tv.SetCurrentItem(item)
+}
+
+// dragTo updates the drag image and the drop target for the cursor at p.
+func (tv *TreeView) dragTo(p Point) {
+	if tv.hDragIml != 0 {
+		imageList_DragMove(int32(p.X+tv.dragOffset.X), int32(p.Y+tv.dragOffset.Y))
+	}
+
+	target, position := tv.dropTargetAt(p)
+
+	valid := false
+	if target != nil {
+		parent, index := tv.dropDestination(target, position)
+
+		valid = true
+		for ancestor := parent; ancestor != nil; ancestor = ancestor.Parent() {
+			if ancestor == tv.dragItem {
+				// An item can't become its own descendant.
+				valid = false
+				break
+			}
+		}
+
+		if valid {
+			if mover, ok := tv.model.(TreeItemMover); ok {
+				valid = mover.CanMoveItem(tv.dragItem, parent, index)
+			} else {
+				valid = false
+			}
+		}
+	}
+
+	if target != tv.dropTarget {
+		tv.dropHoverStart = time.Now()
+	}
+
+	if target != tv.dropTarget || position != tv.dropPosition || valid != tv.dropValid {
+		tv.dropTarget, tv.dropPosition, tv.dropValid = target, position, valid
+
+		tv.withDragImageHidden(tv.updateDropMarker)
+	}
+
+	if valid {
+		win.SetCursor(CursorArrow().handle())
+	} else {
+		win.SetCursor(CursorNo().handle())
+	}
+}
+
+// dropTargetAt returns the item below p and where relative to it a dragged
+// item would be dropped.
+func (tv *TreeView) dropTargetAt(p Point) (TreeItem, treeViewDropPosition) {
+	hti := win.TVHITTESTINFO{Pt: p.toPOINT()}
+	tv.SendMessage(win.TVM_HITTEST, 0, uintptr(unsafe.Pointer(&hti)))
+
+	item := tv.handle2Item[hti.HItem]
+	if item == nil {
+		return nil, treeViewDropInto
+	}
+
+	var rc win.RECT
+	*(*win.HTREEITEM)(unsafe.Pointer(&rc)) = hti.HItem
+	if 0 == tv.SendMessage(win.TVM_GETITEMRECT, 0, uintptr(unsafe.Pointer(&rc))) {
+		return nil, treeViewDropInto
+	}
+
+	// The upper and lower quarter of an item insert next to it, the rest
+	// inserts into it.
+	height := int(rc.Bottom - rc.Top)
+	y := p.Y - int(rc.Top)
+
+	switch {
+	case y < height/4:
+		return item, treeViewDropBefore
+
+	case y >= height-height/4:
+		if item.ChildCount() > 0 && tv.Expanded(item) {
+			// Below an expanded item its first child is displayed.
+			return item.ChildAt(0), treeViewDropBefore
+		}
+		return item, treeViewDropAfter
+	}
+
+	return item, treeViewDropInto
+}
+
+// dropDestination returns the parent and index to move a dragged item to.
+func (tv *TreeView) dropDestination(target TreeItem, position treeViewDropPosition) (parent TreeItem, index int) {
+	if position == treeViewDropInto {
+		return target, target.ChildCount()
+	}
+
+	index = tv.indexOfItem(target)
+	if position == treeViewDropAfter {
+		index++
+	}
+
+	return target.Parent(), index
+}
+
+// indexOfItem returns the index of item among its siblings.
+func (tv *TreeView) indexOfItem(item TreeItem) int {
+	if parent := item.Parent(); parent != nil {
+		for i := parent.ChildCount() - 1; i >= 0; i-- {
+			if parent.ChildAt(i) == item {
+				return i
+			}
+		}
+	} else {
+		for i := tv.model.RootCount() - 1; i >= 0; i-- {
+			if tv.model.RootAt(i) == item {
+				return i
+			}
+		}
+	}
+
+	return -1
+}
+
+func (tv *TreeView) updateDropMarker() {
+	tv.SendMessage(win.TVM_SETINSERTMARK, 0, 0)
+	tv.SendMessage(win.TVM_SELECTITEM, _TVGN_DROPHILITE, 0)
+
+	if !tv.dropValid {
+		return
+	}
+
+	handle := tv.item2Info[tv.dropTarget].handle
+
+	switch tv.dropPosition {
+	case treeViewDropBefore:
+		tv.SendMessage(win.TVM_SETINSERTMARK, 0, uintptr(handle))
+
+	case treeViewDropInto:
+		tv.SendMessage(win.TVM_SELECTITEM, _TVGN_DROPHILITE, uintptr(handle))
+
+	case treeViewDropAfter:
+		tv.SendMessage(win.TVM_SETINSERTMARK, 1, uintptr(handle))
+	}
+}
+
+// withDragImageHidden calls f with the drag image hidden, so it does not
+// leave traces when f repaints.
+func (tv *TreeView) withDragImageHidden(f func()) {
+	if tv.hDragIml != 0 {
+		imageList_DragShowNolock(false)
+		defer imageList_DragShowNolock(true)
+	}
+
+	f()
+}
+
+// onDragTimer scrolls near the edges and expands hovered items while
+// dragging.
+func (tv *TreeView) onDragTimer() {
+	var pt win.POINT
+	win.GetCursorPos(&pt)
+	win.ScreenToClient(tv.hWnd, &pt)
+	p := Point{int(pt.X), int(pt.Y)}
+
+	margin := tv.ItemHeight()
+	height := tv.ClientBoundsPixels().Height
+
+	var scroll uintptr = ^uintptr(0)
+	if p.Y < margin {
+		scroll = win.SB_LINEUP
+	} else if p.Y >= height-margin {
+		scroll = win.SB_LINEDOWN
+	}
+
+	if scroll != ^uintptr(0) {
+		tv.withDragImageHidden(func() {
+			tv.SendMessage(win.WM_VSCROLL, scroll, 0)
+			win.UpdateWindow(tv.hWnd)
+		})
+
+		tv.dragTo(p)
+	}
+
+	if target := tv.dropTarget; target != nil && tv.dropPosition == treeViewDropInto &&
+		time.Since(tv.dropHoverStart) >= treeViewDragAutoExpandDelay && !tv.Expanded(target) {
+
+		tv.withDragImageHidden(func() {
+			tv.SetExpanded(target, true)
+			win.UpdateWindow(tv.hWnd)
+		})
+
+		tv.dragTo(p)
+	}
+}
+
 func (tv *TreeView) ExpandedChanged() *TreeItemEvent {
 	return tv.expandedChangedPublisher.Event()
 }
@@ -563,10 +1088,44 @@ func (tv *TreeView) WndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) u
 			return win.DLGC_WANTALLKEYS
 		}
 
+	case win.WM_MOUSEMOVE:
+		if tv.dragItem != nil {
+			tv.dragTo(Point{int(win.GET_X_LPARAM(lParam)), int(win.GET_Y_LPARAM(lParam))})
+		}
+
+	case win.WM_LBUTTONUP:
+		if tv.dragItem != nil {
+			tv.endDrag(true)
+		}
+
+	case win.WM_CAPTURECHANGED:
+		tv.endDrag(false)
+
+	case win.WM_KEYDOWN:
+		if wParam == win.VK_ESCAPE && tv.dragItem != nil {
+			tv.endDrag(false)
+			return 0
+		}
+
+	case win.WM_TIMER:
+		if wParam == treeViewDragTimerId {
+			tv.onDragTimer()
+			return 0
+		}
+
 	case win.WM_NOTIFY:
 		nmhdr := (*win.NMHDR)(unsafe.Pointer(lParam))
 
 		switch nmhdr.Code {
+		case win.TVN_BEGINDRAG:
+			nmtv := (*win.NMTREEVIEW)(unsafe.Pointer(lParam))
+
+			if _, ok := tv.model.(TreeItemMover); ok && tv.reorderable {
+				if item := tv.handle2Item[nmtv.ItemNew.HItem]; item != nil {
+					tv.beginDrag(item, Point{int(nmtv.PtDrag.X), int(nmtv.PtDrag.Y)})
+				}
+			}
+
 		case win.TVN_GETDISPINFO:
 			nmtvdi := (*win.NMTVDISPINFO)(unsafe.Pointer(lParam))
 			item := tv.handle2Item[nmtvdi.Item.HItem]
@@ -629,6 +1188,19 @@ func (tv *TreeView) WndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) u
 				tv.itemActivatedPublisher.Publish()
 			}
 
+		case win.TVN_ITEMCHANGING:
+			nmtvic := (*nmTVItemChange)(unsafe.Pointer(lParam))
+
+			if !tv.updatingCheckStates && nmtvic.uChanged&win.TVIF_STATE != 0 && (nmtvic.uStateNew^nmtvic.uStateOld)&win.TVIS_STATEIMAGEMASK != 0 {
+				// The control would cycle through the partially checked
+				// state as well, so we take over toggling.
+				if item := tv.handle2Item[nmtvic.hItem]; item != nil {
+					tv.SetChecked(item, tv.checkStates[item] != CheckChecked)
+				}
+
+				return 1
+			}
+
 		case win.TVN_SELCHANGED:
 			nmtv := (*win.NMTREEVIEW)(unsafe.Pointer(lParam))
 
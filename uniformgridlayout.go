@@ -0,0 +1,261 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+// UniformGridLayout divides its space into cells of equal size and places
+// its items in them row by row.
+//
+// The number of rows and columns can be fixed. If neither is set, as many
+// columns as fit the available width are used, each at least MinCellSize
+// wide, and rows keep their minimum height.
+type UniformGridLayout struct {
+	LayoutBase
+	rows             int
+	columns          int
+	minCellSize96dpi Size
+}
+
+func NewUniformGridLayout() *UniformGridLayout {
+	l := &UniformGridLayout{
+		LayoutBase: LayoutBase{
+			margins96dpi: Margins{9, 9, 9, 9},
+			spacing96dpi: 6,
+		},
+	}
+	l.layout = l
+
+	return l
+}
+
+// Rows returns the fixed number of rows, or 0 if it depends on the number of
+// items.
+func (l *UniformGridLayout) Rows() int {
+	return l.rows
+}
+
+// SetRows sets the fixed number of rows. 0 derives it from the number of
+// items and columns. If both rows and columns are fixed, items beyond
+// Rows * Columns are not laid out.
+func (l *UniformGridLayout) SetRows(rows int) error {
+	return l.setCount(&l.rows, rows)
+}
+
+// Columns returns the fixed number of columns, or 0 if it depends on the
+// number of items or the available width.
+func (l *UniformGridLayout) Columns() int {
+	return l.columns
+}
+
+// SetColumns sets the fixed number of columns. 0 derives it from the number
+// of items and rows, or from the available width if Rows is 0 as well.
+func (l *UniformGridLayout) SetColumns(columns int) error {
+	return l.setCount(&l.columns, columns)
+}
+
+func (l *UniformGridLayout) setCount(count *int, value int) error {
+	if value == *count {
+		return nil
+	}
+
+	if value < 0 {
+		return newError("count cannot be negative")
+	}
+
+	*count = value
+
+	if l.container != nil {
+		l.container.RequestLayout()
+	}
+
+	return nil
+}
+
+// MinCellSize returns the minimum size of a cell in 1/96" units.
+func (l *UniformGridLayout) MinCellSize() Size {
+	return l.minCellSize96dpi
+}
+
+// SetMinCellSize sets the minimum size of a cell in 1/96" units. Cells are
+// never smaller than the largest minimum size of the items.
+func (l *UniformGridLayout) SetMinCellSize(size Size) error {
+	if size == l.minCellSize96dpi {
+		return nil
+	}
+
+	if size.Width < 0 || size.Height < 0 {
+		return newError("size cannot be negative")
+	}
+
+	l.minCellSize96dpi = size
+
+	if l.container != nil {
+		l.container.RequestLayout()
+	}
+
+	return nil
+}
+
+func (l *UniformGridLayout) CreateLayoutItem(ctx *LayoutContext) ContainerLayoutItem {
+	return &uniformGridLayoutItem{
+		rows:             l.rows,
+		columns:          l.columns,
+		minCellSize96dpi: l.minCellSize96dpi,
+	}
+}
+
+type uniformGridLayoutItem struct {
+	ContainerLayoutItemBase
+	rows             int
+	columns          int
+	minCellSize96dpi Size
+}
+
+// autoFit returns whether the number of columns depends on the available
+// width.
+func (li *uniformGridLayoutItem) autoFit() bool {
+	return li.rows == 0 && li.columns == 0
+}
+
+func (li *uniformGridLayoutItem) LayoutFlags() LayoutFlags {
+	flags := ShrinkableHorz | ShrinkableVert | GrowableHorz | GrowableVert
+
+	for _, item := range itemsToLayout(li.children) {
+		flags |= item.LayoutFlags() & (GreedyHorz | GreedyVert)
+	}
+
+	return flags
+}
+
+// minCellSize returns the minimum size of a cell in native pixels.
+func (li *uniformGridLayoutItem) minCellSize(items []LayoutItem) Size {
+	size := SizeFrom96DPI(li.minCellSize96dpi, li.ctx.dpi)
+
+	for _, item := range items {
+		size = maxSize(size, li.MinSizeEffectiveForChild(item))
+	}
+
+	return size
+}
+
+// dimensions returns the number of rows and columns for count items and an
+// available width in native pixels.
+func (li *uniformGridLayoutItem) dimensions(count, width, cellWidth int) (rows, columns int) {
+	spacing := IntFrom96DPI(li.spacing96dpi, li.ctx.dpi)
+
+	rows, columns = li.rows, li.columns
+
+	switch {
+	case columns > 0 && rows == 0:
+		rows = (count + columns - 1) / columns
+
+	case rows > 0 && columns == 0:
+		columns = (count + rows - 1) / rows
+
+	case rows == 0 && columns == 0:
+		columns = 1
+		if cellWidth > 0 {
+			columns = maxi(1, (width+spacing)/(cellWidth+spacing))
+		}
+		columns = mini(columns, maxi(1, count))
+
+		rows = (count + columns - 1) / columns
+	}
+
+	return maxi(1, rows), maxi(1, columns)
+}
+
+func (li *uniformGridLayoutItem) MinSize() Size {
+	return li.MinSizeForSize(li.geometry.ClientSize)
+}
+
+func (li *uniformGridLayoutItem) HasHeightForWidth() bool {
+	return li.autoFit() || li.ContainerLayoutItemBase.HasHeightForWidth()
+}
+
+func (li *uniformGridLayoutItem) HeightForWidth(width int) int {
+	return li.MinSizeForSize(Size{width, li.geometry.ClientSize.Height}).Height
+}
+
+func (li *uniformGridLayoutItem) MinSizeForSize(size Size) Size {
+	margins := MarginsFrom96DPI(li.margins96dpi, li.ctx.dpi)
+	spacing := IntFrom96DPI(li.spacing96dpi, li.ctx.dpi)
+
+	items := itemsToLayout(li.children)
+	cell := li.minCellSize(items)
+
+	rows, columns := li.dimensions(len(items), size.Width-margins.HNear-margins.HFar, cell.Width)
+
+	s := Size{
+		Width:  margins.HNear + margins.HFar,
+		Height: margins.VNear + margins.VFar + rows*cell.Height + (rows-1)*spacing,
+	}
+
+	if li.autoFit() {
+		s.Width += cell.Width
+	} else {
+		s.Width += columns*cell.Width + (columns-1)*spacing
+	}
+
+	return s
+}
+
+func (li *uniformGridLayoutItem) PerformLayout() []LayoutResultItem {
+	margins := MarginsFrom96DPI(li.margins96dpi, li.ctx.dpi)
+	spacing := IntFrom96DPI(li.spacing96dpi, li.ctx.dpi)
+
+	items := itemsToLayout(li.children)
+	if len(items) == 0 {
+		return nil
+	}
+
+	minCell := li.minCellSize(items)
+
+	width := li.geometry.ClientSize.Width - margins.HNear - margins.HFar
+	height := li.geometry.ClientSize.Height - margins.VNear - margins.VFar
+
+	rows, columns := li.dimensions(len(items), width, minCell.Width)
+
+	cell := Size{
+		Width:  maxi(minCell.Width, (width-(columns-1)*spacing)/columns),
+		Height: minCell.Height,
+	}
+	if !li.autoFit() {
+		cell.Height = maxi(minCell.Height, (height-(rows-1)*spacing)/rows)
+	}
+
+	results := make([]LayoutResultItem, 0, len(items))
+
+	for i, item := range items {
+		if i >= rows*columns {
+			break
+		}
+
+		row, column := i/columns, i%columns
+
+		bounds := Rectangle{
+			X:      margins.HNear + column*(cell.Width+spacing),
+			Y:      margins.VNear + row*(cell.Height+spacing),
+			Width:  cell.Width,
+			Height: cell.Height,
+		}
+
+		// Items that cannot grow as large as their cell are centered in it.
+		if max := item.Geometry().MaxSize; max.Width > 0 && max.Width < bounds.Width {
+			bounds.X += (bounds.Width - max.Width) / 2
+			bounds.Width = max.Width
+		}
+		if max := item.Geometry().MaxSize; max.Height > 0 && max.Height < bounds.Height {
+			bounds.Y += (bounds.Height - max.Height) / 2
+			bounds.Height = max.Height
+		}
+
+		results = append(results, LayoutResultItem{Item: item, Bounds: bounds})
+	}
+
+	return results
+}
@@ -0,0 +1,334 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"unsafe"
+
+	"github.com/tailscale/win"
+)
+
+// UpDownAcceleration specifies that once the user has held down a spin button
+// for Seconds, each step changes the value by Increment.
+type UpDownAcceleration struct {
+	Seconds   int
+	Increment int
+}
+
+// UpDown is a pair of spin buttons, using the msctls_updown32 common control,
+// that steps an integer value. It can be paired with a LineEdit as buddy,
+// which then displays the value and accepts the Up and Down keys and the mouse
+// wheel.
+//
+// For fractional values, see NumberEdit.
+type UpDown struct {
+	WidgetBase
+	buddy                  *LineEdit
+	buddyTextChangedHandle int
+	buddyWheelHandle       int
+	value                  int
+	valueChangedPublisher  EventPublisher
+}
+
+// NewUpDown returns a new UpDown widget with a range of 0 to 100 as child of
+// parent.
+func NewUpDown(parent Container) (*UpDown, error) {
+	ud := new(UpDown)
+
+	if err := InitWidget(
+		ud,
+		parent,
+		"msctls_updown32",
+		win.WS_VISIBLE|win.UDS_ARROWKEYS|win.UDS_HOTTRACK|win.UDS_SETBUDDYINT|win.UDS_NOTHOUSANDS,
+		0); err != nil {
+		return nil, err
+	}
+
+	ud.SendMessage(win.UDM_SETRANGE32, 0, 100)
+
+	ud.MustRegisterProperty("Value", NewProperty(
+		func() interface{} {
+			return ud.Value()
+		},
+		func(v interface{}) error {
+			return ud.SetValue(assertIntOr(v, 0))
+		},
+		ud.valueChangedPublisher.Event()))
+
+	return ud, nil
+}
+
+// Buddy returns the LineEdit that displays the value, if any.
+func (ud *UpDown) Buddy() *LineEdit {
+	return ud.buddy
+}
+
+// SetBuddy sets the LineEdit that displays the value. The UpDown should be
+// placed next to it, e.g. in a HBox with zero spacing.
+func (ud *UpDown) SetBuddy(buddy *LineEdit) error {
+	if buddy == ud.buddy {
+		return nil
+	}
+
+	if ud.buddy != nil {
+		ud.buddy.TextChanged().Detach(ud.buddyTextChangedHandle)
+		ud.buddy.MouseWheel().Detach(ud.buddyWheelHandle)
+	}
+
+	ud.buddy = buddy
+
+	var hwndBuddy win.HWND
+	if buddy != nil {
+		hwndBuddy = buddy.hWnd
+
+		ud.buddyTextChangedHandle = buddy.TextChanged().Attach(ud.updateValue)
+		ud.buddyWheelHandle = buddy.MouseWheel().Attach(func(x, y int, button MouseButton) {
+			ud.step(MouseWheelEventDelta(button) / 120)
+		})
+	}
+
+	ud.SendMessage(win.UDM_SETBUDDY, uintptr(hwndBuddy), 0)
+
+	if buddy != nil {
+		// Let the buddy display the current value.
+		ud.SendMessage(win.UDM_SETPOS32, 0, uintptr(ud.Value()))
+	}
+
+	return nil
+}
+
+// MinValue returns the minimum value.
+func (ud *UpDown) MinValue() int {
+	var min int32
+	ud.SendMessage(win.UDM_GETRANGE32, uintptr(unsafe.Pointer(&min)), 0)
+
+	return int(min)
+}
+
+// MaxValue returns the maximum value.
+func (ud *UpDown) MaxValue() int {
+	var max int32
+	ud.SendMessage(win.UDM_GETRANGE32, 0, uintptr(unsafe.Pointer(&max)))
+
+	return int(max)
+}
+
+// SetRange sets the minimum and maximum value. If the current value is out of
+// this range, it will be adjusted.
+func (ud *UpDown) SetRange(min, max int) error {
+	if min > max {
+		return newError("min must not be greater than max")
+	}
+
+	ud.SendMessage(win.UDM_SETRANGE32, uintptr(min), uintptr(max))
+
+	if value := ud.Value(); value < min {
+		return ud.SetValue(min)
+	} else if value > max {
+		return ud.SetValue(max)
+	}
+
+	return nil
+}
+
+// Value returns the current value. If a buddy is set, the value is parsed from
+// its text.
+func (ud *UpDown) Value() int {
+	var failed win.BOOL
+	value := int32(ud.SendMessage(win.UDM_GETPOS32, 0, uintptr(unsafe.Pointer(&failed))))
+	if failed != 0 {
+		return ud.value
+	}
+
+	return int(value)
+}
+
+// SetValue sets the current value.
+func (ud *UpDown) SetValue(value int) error {
+	if min, max := ud.MinValue(), ud.MaxValue(); value < min || value > max {
+		return newError("value out of range")
+	}
+
+	ud.SendMessage(win.UDM_SETPOS32, 0, uintptr(value))
+
+	ud.updateValue()
+
+	return nil
+}
+
+// ValueChanged returns the event that is published when the value changes.
+func (ud *UpDown) ValueChanged() *Event {
+	return ud.valueChangedPublisher.Event()
+}
+
+// Increment returns the amount by which a single step changes the value.
+func (ud *UpDown) Increment() int {
+	if accels := ud.Accelerations(); len(accels) > 0 {
+		return accels[0].Increment
+	}
+
+	return 1
+}
+
+// SetIncrement sets the amount by which a single step changes the value. Any
+// accelerations are replaced.
+func (ud *UpDown) SetIncrement(increment int) error {
+	return ud.SetAccelerations([]UpDownAcceleration{{0, increment}})
+}
+
+// Accelerations returns how the step size grows while a spin button is held
+// down.
+func (ud *UpDown) Accelerations() []UpDownAcceleration {
+	n := int(ud.SendMessage(win.UDM_GETACCEL, 0, 0))
+	if n == 0 {
+		return nil
+	}
+
+	accels := make([]win.UDACCEL, n)
+	ud.SendMessage(win.UDM_GETACCEL, uintptr(n), uintptr(unsafe.Pointer(&accels[0])))
+
+	ret := make([]UpDownAcceleration, n)
+	for i, a := range accels {
+		ret[i] = UpDownAcceleration{int(a.NSec), int(a.NInc)}
+	}
+
+	return ret
+}
+
+// SetAccelerations sets how the step size grows while a spin button is held
+// down. accelerations must be sorted by Seconds, and the first one should have
+// a Seconds of 0.
+func (ud *UpDown) SetAccelerations(accelerations []UpDownAcceleration) error {
+	if len(accelerations) == 0 {
+		return newError("accelerations must not be empty")
+	}
+
+	accels := make([]win.UDACCEL, len(accelerations))
+	for i, a := range accelerations {
+		if a.Seconds < 0 || a.Increment <= 0 || i > 0 && a.Seconds < accelerations[i-1].Seconds {
+			return newError("invalid acceleration")
+		}
+
+		accels[i] = win.UDACCEL{NSec: uint32(a.Seconds), NInc: uint32(a.Increment)}
+	}
+
+	if 0 == ud.SendMessage(win.UDM_SETACCEL, uintptr(len(accels)), uintptr(unsafe.Pointer(&accels[0]))) {
+		return newError("UDM_SETACCEL failed")
+	}
+
+	return nil
+}
+
+// Wrap returns whether the value wraps around when stepping beyond the range.
+func (ud *UpDown) Wrap() bool {
+	return ud.hasStyleBits(win.UDS_WRAP)
+}
+
+// SetWrap sets whether the value wraps around when stepping beyond the range.
+func (ud *UpDown) SetWrap(wrap bool) error {
+	return ud.ensureStyleBits(win.UDS_WRAP, wrap)
+}
+
+// Hexadecimal returns whether the buddy displays the value in hexadecimal.
+func (ud *UpDown) Hexadecimal() bool {
+	return ud.SendMessage(win.UDM_GETBASE, 0, 0) == 16
+}
+
+// SetHexadecimal sets whether the buddy displays the value in hexadecimal.
+// Hexadecimal values are displayed with "0x" prefix and are never negative.
+func (ud *UpDown) SetHexadecimal(hexadecimal bool) error {
+	base := uintptr(10)
+	if hexadecimal {
+		base = 16
+	}
+
+	if 0 == ud.SendMessage(win.UDM_SETBASE, base, 0) {
+		return newError("UDM_SETBASE failed")
+	}
+
+	// Redisplay the value in the new base.
+	ud.SendMessage(win.UDM_SETPOS32, 0, uintptr(ud.Value()))
+
+	return nil
+}
+
+// step changes the value by delta times the increment, honoring Wrap.
+func (ud *UpDown) step(delta int) {
+	if delta == 0 || !ud.Enabled() || ud.buddy != nil && ud.buddy.ReadOnly() {
+		return
+	}
+
+	min, max := ud.MinValue(), ud.MaxValue()
+	value := ud.Value() + delta*ud.Increment()
+
+	if value < min {
+		if ud.Wrap() {
+			value = max
+		} else {
+			value = min
+		}
+	} else if value > max {
+		if ud.Wrap() {
+			value = min
+		} else {
+			value = max
+		}
+	}
+
+	ud.SetValue(value)
+}
+
+func (ud *UpDown) updateValue() {
+	if value := ud.Value(); value != ud.value {
+		ud.value = value
+		ud.valueChangedPublisher.Publish()
+	}
+}
+
+func (ud *UpDown) WndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) uintptr {
+	switch msg {
+	case win.WM_HSCROLL, win.WM_VSCROLL:
+		// Forwarded by the parent after the position has changed.
+		if win.HWND(lParam) == ud.hWnd {
+			ud.updateValue()
+			return 0
+		}
+
+	case win.WM_MOUSEWHEEL:
+		ud.step(int(int16(win.HIWORD(uint32(wParam)))) / 120)
+		return 0
+	}
+
+	return ud.WidgetBase.WndProc(hwnd, msg, wParam, lParam)
+}
+
+func (ud *UpDown) CreateLayoutItem(ctx *LayoutContext) LayoutItem {
+	return &upDownLayoutItem{
+		size: Size{
+			int(win.GetSystemMetricsForDpi(win.SM_CXVSCROLL, uint32(ctx.dpi))),
+			ud.dialogBaseUnitsToPixels(Size{0, 12}).Height,
+		},
+	}
+}
+
+type upDownLayoutItem struct {
+	LayoutItemBase
+	size Size // in native pixels
+}
+
+func (*upDownLayoutItem) LayoutFlags() LayoutFlags {
+	return 0
+}
+
+func (li *upDownLayoutItem) IdealSize() Size {
+	return li.size
+}
+
+func (li *upDownLayoutItem) MinSize() Size {
+	return li.size
+}
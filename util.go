@@ -296,6 +296,7 @@ var seenInApplyDPIToDescendantsDuringDPIChange map[*WindowBase]bool
 
 func applyDPIToDescendants(window Window, dpi int) {
 	wb := window.AsWindowBase()
+	wb.discardThemeData()
 	wb.ApplyDPI(dpi)
 
 	walkDescendants(window, func(w Window) bool {
@@ -311,6 +312,8 @@ func applyDPIToDescendants(window Window, dpi int) {
 			seenInApplyDPIToDescendantsDuringDPIChange[wb] = true
 		}
 
+		// Theme data is opened for the DPI of the window at that time.
+		w.AsWindowBase().discardThemeData()
 		w.(ApplyDPIer).ApplyDPI(dpi)
 
 		return true
@@ -150,3 +150,38 @@ func (selectionRequiredValidator) Validate(v interface{}) error {
 
 	return nil
 }
+
+type NetworkAddressValidator struct {
+	kind NetworkAddressKind
+}
+
+func NewNetworkAddressValidator(kind NetworkAddressKind) *NetworkAddressValidator {
+	return &NetworkAddressValidator{kind}
+}
+
+func (nav *NetworkAddressValidator) Kind() NetworkAddressKind {
+	return nav.kind
+}
+
+func (nav *NetworkAddressValidator) Validate(v interface{}) error {
+	var text string
+
+	switch val := v.(type) {
+	case string:
+		text = val
+
+	case fmt.Stringer:
+		text = val.String()
+
+	default:
+		panic("Unsupported type")
+	}
+
+	if _, err := parseNetworkAddress(text, nav.kind); err != nil {
+		return NewValidationError(
+			tr("Invalid Address", "walk"),
+			fmt.Sprintf(tr("Please enter an address like %s.", "walk"), nav.kind.example()))
+	}
+
+	return nil
+}
@@ -0,0 +1,615 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"unsafe"
+
+	"github.com/tailscale/win"
+)
+
+const virtualListWindowClass = `\o/ Walk_VirtualList_Class \o/`
+
+func init() {
+	AppendToWalkInit(func() {
+		MustRegisterWindowClass(virtualListWindowClass)
+	})
+}
+
+// VirtualListRowCreator creates the widget of a row of a VirtualList as a
+// child of parent.
+type VirtualListRowCreator func(parent Container) (Widget, error)
+
+// VirtualListRowBinder updates the widget of a row of a VirtualList to
+// display the item at index.
+type VirtualListRowBinder func(row Widget, index int)
+
+type virtualListRow struct {
+	widget Widget
+	index  int // -1 if the row is currently unused
+}
+
+// VirtualList displays a vertically scrolling list of rows of equal height.
+//
+// Unlike a ScrollView, VirtualList only creates widgets for the rows inside
+// its viewport. When the list is scrolled, rows leaving the viewport are
+// recycled for the rows entering it and bound to their new item again.
+type VirtualList struct {
+	WidgetBase
+	canvas         *Composite
+	createRow      VirtualListRowCreator
+	bindRow        VirtualListRowBinder
+	rows           []*virtualListRow
+	itemCount      int
+	rowHeight96dpi int
+	scrollPos      int // in native pixels
+}
+
+// NewVirtualList creates a VirtualList that calls createRow to create row
+// widgets and bindRow to bind them to items.
+func NewVirtualList(parent Container, createRow VirtualListRowCreator, bindRow VirtualListRowBinder) (*VirtualList, error) {
+	if createRow == nil || bindRow == nil {
+		return nil, newError("createRow and bindRow are required")
+	}
+
+	vl := &VirtualList{
+		createRow:      createRow,
+		bindRow:        bindRow,
+		rowHeight96dpi: 24,
+	}
+
+	if err := InitWidget(
+		vl,
+		parent,
+		virtualListWindowClass,
+		win.WS_CHILD|win.WS_VISIBLE|win.WS_VSCROLL,
+		win.WS_EX_CONTROLPARENT); err != nil {
+		return nil, err
+	}
+
+	succeeded := false
+	defer func() {
+		if !succeeded {
+			vl.Dispose()
+		}
+	}()
+
+	var err error
+	if vl.canvas, err = NewComposite(vl); err != nil {
+		return nil, err
+	}
+
+	if err := vl.canvas.SetLayout(newVirtualListRowLayout(vl)); err != nil {
+		return nil, err
+	}
+
+	vl.SetBackground(NullBrush())
+
+	vl.updateScrollBar()
+
+	succeeded = true
+
+	return vl, nil
+}
+
+func (vl *VirtualList) AsContainerBase() *ContainerBase {
+	if vl.canvas == nil {
+		return nil
+	}
+
+	return vl.canvas.AsContainerBase()
+}
+
+func (vl *VirtualList) Children() *WidgetList {
+	if vl.canvas == nil {
+		// Without this we would get into trouble in NewComposite.
+		return nil
+	}
+
+	return vl.canvas.Children()
+}
+
+func (vl *VirtualList) Layout() Layout {
+	if vl.canvas == nil {
+		return nil
+	}
+
+	return vl.canvas.Layout()
+}
+
+func (vl *VirtualList) SetLayout(value Layout) error {
+	return newError("VirtualList lays out its rows itself")
+}
+
+func (vl *VirtualList) DataBinder() *DataBinder {
+	return vl.canvas.DataBinder()
+}
+
+func (vl *VirtualList) SetDataBinder(dataBinder *DataBinder) {
+	vl.canvas.SetDataBinder(dataBinder)
+}
+
+func (vl *VirtualList) SetSuspended(suspend bool) {
+	vl.canvas.SetSuspended(suspend)
+	vl.WidgetBase.SetSuspended(suspend)
+	vl.Invalidate()
+}
+
+func (vl *VirtualList) ApplyDPI(dpi int) {
+	vl.WidgetBase.ApplyDPI(dpi)
+	vl.canvas.ApplyDPI(dpi)
+
+	vl.updateRows()
+}
+
+func (vl *VirtualList) applyEnabled(enabled bool) {
+	vl.WidgetBase.applyEnabled(enabled)
+
+	applyEnabledToDescendants(vl.canvas, enabled)
+}
+
+func (vl *VirtualList) applyFont(font *Font) {
+	vl.WidgetBase.applyFont(font)
+
+	applyFontToDescendants(vl.canvas, font)
+}
+
+// ItemCount returns the number of items of the VirtualList.
+func (vl *VirtualList) ItemCount() int {
+	return vl.itemCount
+}
+
+// SetItemCount sets the number of items of the VirtualList and rebinds all
+// rows inside the viewport.
+func (vl *VirtualList) SetItemCount(count int) error {
+	if count < 0 {
+		return newError("count cannot be negative")
+	}
+
+	vl.itemCount = count
+
+	vl.updateScrollBar()
+	vl.Refresh()
+
+	return nil
+}
+
+// RowHeight returns the height of each row in 1/96" units.
+func (vl *VirtualList) RowHeight() int {
+	return vl.rowHeight96dpi
+}
+
+// SetRowHeight sets the height of each row in 1/96" units.
+func (vl *VirtualList) SetRowHeight(height int) error {
+	if height == vl.rowHeight96dpi {
+		return nil
+	}
+
+	if height < 1 {
+		return newError("height must be >= 1")
+	}
+
+	vl.rowHeight96dpi = height
+
+	vl.updateScrollBar()
+	vl.updateRows()
+	vl.RequestLayout()
+
+	return nil
+}
+
+// FirstVisibleIndex returns the index of the first item inside the viewport.
+func (vl *VirtualList) FirstVisibleIndex() int {
+	if vl.itemCount == 0 {
+		return -1
+	}
+
+	return vl.scrollPos / vl.rowHeightPixels()
+}
+
+// EnsureVisible scrolls the VirtualList so the item at index is inside the
+// viewport.
+func (vl *VirtualList) EnsureVisible(index int) {
+	if index < 0 || index >= vl.itemCount {
+		return
+	}
+
+	rowHeight := vl.rowHeightPixels()
+	viewport := vl.ClientBoundsPixels().Height
+
+	top := index * rowHeight
+	switch {
+	case top < vl.scrollPos:
+		vl.scrollTo(top)
+
+	case top+rowHeight > vl.scrollPos+viewport:
+		vl.scrollTo(top + rowHeight - viewport)
+	}
+}
+
+// RowWidget returns the widget currently bound to the item at index, or nil if
+// the item is outside the viewport.
+func (vl *VirtualList) RowWidget(index int) Widget {
+	for _, row := range vl.rows {
+		if row.index == index {
+			return row.widget
+		}
+	}
+
+	return nil
+}
+
+// Refresh binds all rows inside the viewport to their items again.
+func (vl *VirtualList) Refresh() {
+	for _, row := range vl.rows {
+		row.index = -1
+	}
+
+	vl.updateRows()
+}
+
+// RefreshItem binds the row displaying the item at index to it again, if the
+// item is inside the viewport.
+func (vl *VirtualList) RefreshItem(index int) {
+	for _, row := range vl.rows {
+		if row.index == index {
+			vl.bindRow(row.widget, index)
+			return
+		}
+	}
+}
+
+func (vl *VirtualList) rowHeightPixels() int {
+	return maxi(1, vl.IntFrom96DPI(vl.rowHeight96dpi))
+}
+
+// maxScrollPos returns the maximum scroll position in native pixels.
+func (vl *VirtualList) maxScrollPos() int {
+	return maxi(0, vl.itemCount*vl.rowHeightPixels()-vl.ClientBoundsPixels().Height)
+}
+
+func (vl *VirtualList) scrollTo(pos int) {
+	if pos > vl.maxScrollPos() {
+		pos = vl.maxScrollPos()
+	}
+	if pos < 0 {
+		pos = 0
+	}
+
+	if pos == vl.scrollPos {
+		return
+	}
+
+	vl.scrollPos = pos
+
+	vl.updateScrollBar()
+	vl.updateRows()
+}
+
+func (vl *VirtualList) updateScrollBar() {
+	if vl.scrollPos > vl.maxScrollPos() {
+		vl.scrollPos = vl.maxScrollPos()
+	}
+
+	var si win.SCROLLINFO
+	si.CbSize = uint32(unsafe.Sizeof(si))
+	si.FMask = win.SIF_PAGE | win.SIF_POS | win.SIF_RANGE | win.SIF_DISABLENOSCROLL
+	si.NMax = int32(vl.itemCount*vl.rowHeightPixels() - 1)
+	si.NPage = uint32(maxi(0, vl.ClientBoundsPixels().Height))
+	si.NPos = int32(vl.scrollPos)
+
+	win.SetScrollInfo(vl.hWnd, win.SB_VERT, &si, true)
+}
+
+// updateRows binds the rows to the items inside the viewport, creating rows as
+// needed, and positions them.
+func (vl *VirtualList) updateRows() {
+	if vl.canvas == nil {
+		return
+	}
+
+	rowHeight := vl.rowHeightPixels()
+	viewport := vl.ClientBoundsPixels()
+
+	first := vl.scrollPos / rowHeight
+	last := mini(vl.itemCount, (vl.scrollPos+viewport.Height+rowHeight-1)/rowHeight) - 1
+
+	var free []*virtualListRow
+	bound := make(map[int]bool)
+
+	for _, row := range vl.rows {
+		if row.index >= first && row.index <= last {
+			bound[row.index] = true
+		} else {
+			free = append(free, row)
+		}
+	}
+
+	created := false
+
+	for index := first; index <= last; index++ {
+		if bound[index] {
+			continue
+		}
+
+		var row *virtualListRow
+		if len(free) > 0 {
+			row, free = free[0], free[1:]
+		} else {
+			widget, err := vl.createRow(vl.canvas)
+			if err != nil {
+				wrapError(err)
+				break
+			}
+
+			row = &virtualListRow{widget: widget}
+			vl.rows = append(vl.rows, row)
+			created = true
+		}
+
+		row.index = index
+		vl.bindRow(row.widget, index)
+	}
+
+	for _, row := range free {
+		row.index = -1
+	}
+
+	for _, row := range vl.rows {
+		if row.index < 0 {
+			row.widget.SetVisible(false)
+			continue
+		}
+
+		row.widget.SetBoundsPixels(Rectangle{
+			Y:      row.index*rowHeight - vl.scrollPos,
+			Width:  viewport.Width,
+			Height: rowHeight,
+		})
+		row.widget.SetVisible(true)
+	}
+
+	if created {
+		vl.RequestLayout()
+	}
+}
+
+func (vl *VirtualList) WndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) uintptr {
+	if vl.canvas != nil {
+		switch msg {
+		case win.WM_VSCROLL:
+			vl.onScroll(win.LOWORD(uint32(wParam)))
+			return 0
+
+		case win.WM_MOUSEWHEEL:
+			delta := int(int16(win.HIWORD(uint32(wParam))))
+
+			n := uint32(3)
+			win.SystemParametersInfo(_SPI_GETWHEELSCROLLLINES, 0, unsafe.Pointer(&n), 0)
+
+			var notch int
+			if n == _WHEEL_PAGESCROLL {
+				notch = vl.ClientBoundsPixels().Height
+			} else {
+				notch = int(n) * vl.rowHeightPixels()
+			}
+
+			// Rotating the wheel forward scrolls up.
+			vl.scrollTo(vl.scrollPos - delta*notch/_WHEEL_DELTA)
+
+			return 0
+
+		case win.WM_COMMAND, win.WM_NOTIFY:
+			vl.canvas.WndProc(hwnd, msg, wParam, lParam)
+
+		case win.WM_WINDOWPOSCHANGED:
+			wp := (*win.WINDOWPOS)(unsafe.Pointer(lParam))
+
+			if wp.Flags&win.SWP_NOSIZE != 0 {
+				break
+			}
+
+			vl.updateScrollBar()
+			vl.updateRows()
+		}
+	}
+
+	return vl.WidgetBase.WndProc(hwnd, msg, wParam, lParam)
+}
+
+func (vl *VirtualList) onScroll(cmd uint16) {
+	var si win.SCROLLINFO
+	si.CbSize = uint32(unsafe.Sizeof(si))
+	si.FMask = win.SIF_PAGE | win.SIF_POS | win.SIF_TRACKPOS
+
+	win.GetScrollInfo(vl.hWnd, win.SB_VERT, &si)
+
+	pos := int(si.NPos)
+
+	switch cmd {
+	case win.SB_LINEUP:
+		pos -= vl.rowHeightPixels()
+
+	case win.SB_LINEDOWN:
+		pos += vl.rowHeightPixels()
+
+	case win.SB_PAGEUP:
+		pos -= int(si.NPage)
+
+	case win.SB_PAGEDOWN:
+		pos += int(si.NPage)
+
+	case win.SB_TOP:
+		pos = 0
+
+	case win.SB_BOTTOM:
+		pos = vl.maxScrollPos()
+
+	case win.SB_THUMBTRACK, win.SB_THUMBPOSITION:
+		pos = int(si.NTrackPos)
+
+	default:
+		return
+	}
+
+	vl.scrollTo(pos)
+}
+
+func (vl *VirtualList) CreateLayoutItem(ctx *LayoutContext) LayoutItem {
+	rowHeight := IntFrom96DPI(vl.rowHeight96dpi, ctx.dpi)
+
+	li := &virtualListLayoutItem{
+		idealHeight: rowHeight * maxi(1, mini(vl.itemCount, virtualListIdealRowCount)),
+	}
+
+	cli := CreateLayoutItemsForContainerWithContext(vl.canvas, ctx)
+	cli.AsLayoutItemBase().parent = li
+	li.children = append(li.children, cli)
+
+	return li
+}
+
+// virtualListIdealRowCount is the number of rows a VirtualList would like to
+// display at once.
+const virtualListIdealRowCount = 8
+
+type virtualListLayoutItem struct {
+	ContainerLayoutItemBase
+	idealHeight int // in native pixels
+}
+
+func (li *virtualListLayoutItem) canvas() ContainerLayoutItem {
+	return li.children[0].(ContainerLayoutItem)
+}
+
+func (*virtualListLayoutItem) LayoutFlags() LayoutFlags {
+	return ShrinkableHorz | ShrinkableVert | GrowableHorz | GrowableVert | GreedyHorz | GreedyVert
+}
+
+func (li *virtualListLayoutItem) scrollBarWidth() int {
+	return int(win.GetSystemMetricsForDpi(win.SM_CXVSCROLL, uint32(li.ctx.dpi)))
+}
+
+func (li *virtualListLayoutItem) MinSize() Size {
+	min := li.canvas().MinSize()
+	min.Width += li.scrollBarWidth()
+
+	return min
+}
+
+func (li *virtualListLayoutItem) MinSizeForSize(size Size) Size {
+	return li.MinSize()
+}
+
+func (li *virtualListLayoutItem) IdealSize() Size {
+	min := li.MinSize()
+
+	return Size{min.Width, maxi(min.Height, li.idealHeight)}
+}
+
+func (li *virtualListLayoutItem) HasHeightForWidth() bool {
+	return false
+}
+
+func (li *virtualListLayoutItem) HeightForWidth(width int) int {
+	return li.MinSize().Height
+}
+
+func (li *virtualListLayoutItem) PerformLayout() []LayoutResultItem {
+	size := li.geometry.ClientSize
+
+	return []LayoutResultItem{
+		{
+			Item:   li.canvas(),
+			Bounds: Rectangle{Width: size.Width, Height: size.Height},
+		},
+	}
+}
+
+// virtualListRowLayout lays out the rows of a VirtualList at the positions of
+// the items they are bound to.
+type virtualListRowLayout struct {
+	LayoutBase
+	list *VirtualList
+}
+
+func newVirtualListRowLayout(list *VirtualList) *virtualListRowLayout {
+	l := &virtualListRowLayout{list: list}
+	l.layout = l
+
+	return l
+}
+
+func (l *virtualListRowLayout) CreateLayoutItem(ctx *LayoutContext) ContainerLayoutItem {
+	li := &virtualListRowLayoutItem{
+		rowHeight:  maxi(1, IntFrom96DPI(l.list.rowHeight96dpi, ctx.dpi)),
+		scrollPos:  l.list.scrollPos,
+		hwnd2Index: make(map[win.HWND]int),
+	}
+
+	for _, row := range l.list.rows {
+		if row.index >= 0 {
+			li.hwnd2Index[row.widget.Handle()] = row.index
+		}
+	}
+
+	return li
+}
+
+type virtualListRowLayoutItem struct {
+	ContainerLayoutItemBase
+	rowHeight  int // in native pixels
+	scrollPos  int // in native pixels
+	hwnd2Index map[win.HWND]int
+}
+
+func (*virtualListRowLayoutItem) LayoutFlags() LayoutFlags {
+	return ShrinkableHorz | ShrinkableVert | GrowableHorz | GrowableVert | GreedyHorz | GreedyVert
+}
+
+func (li *virtualListRowLayoutItem) MinSize() Size {
+	var width int
+	for _, item := range li.children {
+		if shouldLayoutItem(item) {
+			width = maxi(width, li.MinSizeEffectiveForChild(item).Width)
+		}
+	}
+
+	return Size{width, li.rowHeight}
+}
+
+func (li *virtualListRowLayoutItem) MinSizeForSize(size Size) Size {
+	return li.MinSize()
+}
+
+func (li *virtualListRowLayoutItem) HasHeightForWidth() bool {
+	return false
+}
+
+func (li *virtualListRowLayoutItem) HeightForWidth(width int) int {
+	return li.rowHeight
+}
+
+func (li *virtualListRowLayoutItem) PerformLayout() []LayoutResultItem {
+	width := li.geometry.ClientSize.Width
+
+	var items []LayoutResultItem
+	for _, item := range li.children {
+		index, ok := li.hwnd2Index[item.Handle()]
+		if !ok || !shouldLayoutItem(item) {
+			continue
+		}
+
+		items = append(items, LayoutResultItem{
+			Item:   item,
+			Bounds: Rectangle{Y: index*li.rowHeight - li.scrollPos, Width: width, Height: li.rowHeight},
+		})
+	}
+
+	return items
+}
@@ -76,6 +76,14 @@ type Widget interface {
 	// is not visible.
 	SetAlwaysConsumeSpace(b bool) error
 
+	// SetMaxSize sets the maximum outer size of the Widget in 1/96" units,
+	// which all layouts honor.
+	SetMaxSize(max Size) error
+
+	// SetMinSize sets the minimum outer size of the Widget in 1/96" units,
+	// which all layouts honor.
+	SetMinSize(min Size) error
+
 	// SetParent sets the parent of the Widget and adds the Widget to the
 	// Children list of the Container.
 	SetParent(value Container) error
@@ -130,7 +138,7 @@ func InitWidget(widget Widget, parent Window, className string, style, exStyle u
 func (wb *WidgetBase) init(widget Widget) error {
 	wb.graphicsEffects = newWidgetGraphicsEffectList(wb)
 
-	tt := App().toolTip()
+	tt := App().ToolTip()
 	if err := tt.AddTool(wb.window.(Widget)); err != nil {
 		return err
 	}
@@ -159,7 +167,7 @@ func (wb *WidgetBase) Dispose() {
 		wb.SetParent(nil)
 	}
 
-	if tt := App().toolTip(); tt != nil {
+	if tt := App().ToolTip(); tt != nil {
 		tt.RemoveTool(wb.window.(Widget))
 	}
 
@@ -270,6 +278,22 @@ func (wb *WidgetBase) SetMinMaxSize(min, max Size) (err error) {
 	return
 }
 
+// SetMinSize sets the minimum outer size of the *WidgetBase in 1/96" units,
+// keeping its maximum size. All layouts honor it.
+//
+// Use walk.Size{} to remove the limit.
+func (wb *WidgetBase) SetMinSize(min Size) error {
+	return wb.SetMinMaxSize(min, wb.maxSize96dpi)
+}
+
+// SetMaxSize sets the maximum outer size of the *WidgetBase in 1/96" units,
+// keeping its minimum size. All layouts honor it.
+//
+// Use 0 for a dimension that should not be limited.
+func (wb *WidgetBase) SetMaxSize(max Size) error {
+	return wb.SetMinMaxSize(wb.minSize96dpi, max)
+}
+
 // AlwaysConsumeSpace returns if the Widget should consume space even if it is
 // not visible.
 func (wb *WidgetBase) AlwaysConsumeSpace() bool {
@@ -392,7 +416,7 @@ func (wb *WidgetBase) ForEachAncestor(f func(window Window) bool) {
 
 // ToolTipText returns the tool tip text of the WidgetBase.
 func (wb *WidgetBase) ToolTipText() string {
-	if tt := App().toolTip(); tt != nil {
+	if tt := App().ToolTip(); tt != nil {
 		return tt.Text(wb.window.(Widget))
 	}
 	return ""
@@ -400,7 +424,7 @@ func (wb *WidgetBase) ToolTipText() string {
 
 // SetToolTipText sets the tool tip text of the WidgetBase.
 func (wb *WidgetBase) SetToolTipText(s string) error {
-	if tt := App().toolTip(); tt != nil {
+	if tt := App().ToolTip(); tt != nil {
 		if err := tt.SetText(wb.window.(Widget), s); err != nil {
 			return err
 		}
@@ -411,6 +435,27 @@ func (wb *WidgetBase) SetToolTipText(s string) error {
 	return nil
 }
 
+// SetToolTipTitle sets a title and optional icon to display above the tool tip
+// text of the WidgetBase.
+func (wb *WidgetBase) SetToolTipTitle(title string, icon *Icon) error {
+	if tt := App().ToolTip(); tt != nil {
+		return tt.SetToolTitle(wb.window.(Widget), title, icon)
+	}
+
+	return nil
+}
+
+// SetToolTipTextFunc sets a function that provides the tool tip text of the
+// WidgetBase right before it is displayed. Passing nil reverts to the text set
+// using SetToolTipText.
+func (wb *WidgetBase) SetToolTipTextFunc(f func() string) error {
+	if tt := App().ToolTip(); tt != nil {
+		return tt.SetTextFunc(wb.window.(Widget), f)
+	}
+
+	return nil
+}
+
 // GraphicsEffects returns a list of WidgetGraphicsEffects that are applied to the WidgetBase.
 func (wb *WidgetBase) GraphicsEffects() *WidgetGraphicsEffectList {
 	return wb.graphicsEffects
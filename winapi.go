@@ -0,0 +1,1284 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"syscall"
+	"unsafe"
+
+	"github.com/tailscale/win"
+	"golang.org/x/sys/windows"
+)
+
+// This file contains Win32 definitions that are not (yet) provided by
+// github.com/tailscale/win.
+
+const (
+	_AC_SRC_OVER = 0x00
+
+	_BS_COMMANDLINK    = 0x0000000E
+	_BS_DEFCOMMANDLINK = 0x0000000F
+	_BS_TYPEMASK       = 0x0000000F
+
+	_CF_SCREENFONTS         = 0x00000001
+	_CF_ENABLEHOOK          = 0x00000008
+	_CF_INITTOLOGFONTSTRUCT = 0x00000040
+	_CF_EFFECTS             = 0x00000100
+	_CF_APPLY               = 0x00000200
+	_CF_LIMITSIZE           = 0x00002000
+	_CF_FIXEDPITCHONLY      = 0x00004000
+	_CF_FORCEFONTEXIST      = 0x00010000
+	_CF_SELECTSCRIPT        = 0x00400000
+	_CF_NOSCRIPTSEL         = 0x00800000
+	_CF_NOVERTFONTS         = 0x01000000
+
+	_CMB4 = 0x0473
+
+	_DBT_DEVNODES_CHANGED = 0x0007
+
+	_DRAGDROP_S_DROP              = 0x00040100
+	_DRAGDROP_S_CANCEL            = 0x00040101
+	_DRAGDROP_S_USEDEFAULTCURSORS = 0x00040102
+
+	_DROPEFFECT_NONE = 0
+	_DROPEFFECT_COPY = 1
+	_DROPEFFECT_MOVE = 2
+	_DROPEFFECT_LINK = 4
+
+	_DVASPECT_CONTENT = 1
+
+	_DWMNCRP_USEWINDOWSTYLE = 0
+	_DWMNCRP_DISABLED       = 1
+	_DWMNCRP_ENABLED        = 2
+
+	_DWMWCP_DEFAULT    = 0
+	_DWMWCP_DONOTROUND = 1
+	_DWMWCP_ROUND      = 2
+	_DWMWCP_ROUNDSMALL = 3
+
+	_EC_LEFTMARGIN = 0x0001
+
+	_EVENT_OBJECT_LIVEREGIONCHANGED = 0x8019
+
+	_ENUM_CURRENT_SETTINGS = 0xFFFFFFFF
+
+	_FLASHW_STOP      = 0
+	_FLASHW_CAPTION   = 0x00000001
+	_FLASHW_TRAY      = 0x00000002
+	_FLASHW_ALL       = _FLASHW_CAPTION | _FLASHW_TRAY
+	_FLASHW_TIMERNOFG = 0x0000000C
+
+	_FR_DOWN      = 0x00000001
+	_FR_WHOLEWORD = 0x00000002
+	_FR_MATCHCASE = 0x00000004
+
+	_GLPS_CLOSED = 1
+	_GLPS_OPENED = 2
+
+	_GMR_DAYSTATE = 1
+
+	_HKCOMB_NONE = 0x0001
+	_HKCOMB_S    = 0x0002
+	_HKCOMB_C    = 0x0004
+	_HKCOMB_A    = 0x0008
+	_HKCOMB_SC   = 0x0010
+	_HKCOMB_SA   = 0x0020
+	_HKCOMB_CA   = 0x0040
+	_HKCOMB_SCA  = 0x0080
+
+	_HKM_SETHOTKEY = win.WM_USER + 1
+	_HKM_GETHOTKEY = win.WM_USER + 2
+	_HKM_SETRULES  = win.WM_USER + 3
+
+	_HOTKEYF_SHIFT   = 0x01
+	_HOTKEYF_CONTROL = 0x02
+	_HOTKEYF_ALT     = 0x04
+	_HOTKEYF_EXT     = 0x08
+
+	_IPM_CLEARADDRESS = win.WM_USER + 100
+	_IPM_SETADDRESS   = win.WM_USER + 101
+	_IPM_GETADDRESS   = win.WM_USER + 102
+	_IPM_SETRANGE     = win.WM_USER + 103
+	_IPM_SETFOCUS     = win.WM_USER + 104
+	_IPM_ISBLANK      = win.WM_USER + 105
+
+	_LVCFMT_SPLITBUTTON = 0x01000000
+
+	_LVGA_HEADER_LEFT = 0x00000001
+
+	_LVGF_HEADER   = 0x00000001
+	_LVGF_STATE    = 0x00000004
+	_LVGF_ALIGN    = 0x00000008
+	_LVGF_GROUPID  = 0x00000010
+	_LVGF_SUBTITLE = 0x00000100
+	_LVGF_TASK     = 0x00000200
+	_LVGF_ITEMS    = 0x00004000
+
+	_LVGS_COLLAPSED   = 0x00000001
+	_LVGS_COLLAPSIBLE = 0x00000008
+
+	_LVHT_EX_GROUP_HEADER   = 0x10000000
+	_LVHT_EX_GROUP_FOOTER   = 0x20000000
+	_LVHT_EX_GROUP_COLLAPSE = 0x40000000
+
+	_LVIM_AFTER = 0x00000001
+
+	_LVM_SETOWNERDATACALLBACK = win.LVM_FIRST + 187
+
+	_LVN_COLUMNDROPDOWN = ^uint32(163) // LVN_FIRST - 64
+	_LVN_LINKCLICK      = ^uint32(183) // LVN_FIRST - 84
+
+	_LWA_ALPHA = 0x00000002
+
+	_LiveSetting_Polite    = 1
+	_LiveSetting_Assertive = 2
+
+	_MA_NOACTIVATE = 3
+
+	_MCM_FIRST            = 0x1000
+	_MCM_GETCURSEL        = _MCM_FIRST + 1
+	_MCM_SETCURSEL        = _MCM_FIRST + 2
+	_MCM_GETMAXSELCOUNT   = _MCM_FIRST + 3
+	_MCM_SETMAXSELCOUNT   = _MCM_FIRST + 4
+	_MCM_GETSELRANGE      = _MCM_FIRST + 5
+	_MCM_SETSELRANGE      = _MCM_FIRST + 6
+	_MCM_GETMONTHRANGE    = _MCM_FIRST + 7
+	_MCM_SETDAYSTATE      = _MCM_FIRST + 8
+	_MCM_GETMINREQRECT    = _MCM_FIRST + 9
+	_MCM_GETRANGE         = _MCM_FIRST + 17
+	_MCM_SETRANGE         = _MCM_FIRST + 18
+	_MCM_GETMAXTODAYWIDTH = _MCM_FIRST + 21
+
+	_MCN_GETDAYSTATE = ^uint32(746) // MCN_FIRST - 1
+	_MCN_SELCHANGE   = ^uint32(748) // MCN_FIRST - 3
+	_MCN_VIEWCHANGE  = ^uint32(749) // MCN_FIRST - 4
+
+	_MCS_DAYSTATE    = 0x0001
+	_MCS_MULTISELECT = 0x0002
+	_MCS_WEEKNUMBERS = 0x0004
+	_MCS_NOTODAY     = 0x0010
+
+	_MDITILE_VERTICAL   = 0x0000
+	_MDITILE_HORIZONTAL = 0x0001
+
+	_MK_ALT = 0x0020
+
+	_NavigateDirection_Parent          = 0
+	_NavigateDirection_NextSibling     = 1
+	_NavigateDirection_PreviousSibling = 2
+	_NavigateDirection_FirstChild      = 3
+	_NavigateDirection_LastChild       = 4
+
+	_NotificationKind_Other = 4
+
+	_NotificationProcessing_ImportantMostRecent = 1
+	_NotificationProcessing_MostRecent          = 3
+
+	_PBM_SETSTATE = win.WM_USER + 16
+	_PBM_GETSTATE = win.WM_USER + 17
+
+	_PBT_APMSUSPEND         = 0x0004
+	_PBT_APMRESUMEAUTOMATIC = 0x0012
+	_PBT_POWERSETTINGCHANGE = 0x8013
+
+	_POWER_REQUEST_CONTEXT_VERSION       = 0
+	_POWER_REQUEST_CONTEXT_SIMPLE_STRING = 0x00000001
+
+	_POWER_REQUEST_DISPLAY_REQUIRED = 0
+	_POWER_REQUEST_SYSTEM_REQUIRED  = 1
+
+	_PSH3 = 0x0402
+
+	_ProviderOptions_ServerSideProvider = 0x0001
+	_ProviderOptions_UseComThreading    = 0x0020
+
+	_RB_DELETEBAND   = win.WM_USER + 2
+	_RB_INSERTBAND   = win.WM_USER + 10
+	_RB_SETBANDINFO  = win.WM_USER + 11
+	_RB_GETBANDCOUNT = win.WM_USER + 12
+	_RB_GETROWCOUNT  = win.WM_USER + 13
+	_RB_IDTOINDEX    = win.WM_USER + 16
+	_RB_GETBARHEIGHT = win.WM_USER + 27
+	_RB_GETBANDINFO  = win.WM_USER + 28
+	_RB_MINIMIZEBAND = win.WM_USER + 30
+	_RB_MAXIMIZEBAND = win.WM_USER + 31
+	_RB_SHOWBAND     = win.WM_USER + 35
+	_RB_MOVEBAND     = win.WM_USER + 39
+	_RB_SETBANDWIDTH = win.WM_USER + 44
+
+	_RBBIM_STYLE     = 0x00000001
+	_RBBIM_TEXT      = 0x00000004
+	_RBBIM_CHILD     = 0x00000010
+	_RBBIM_CHILDSIZE = 0x00000020
+	_RBBIM_SIZE      = 0x00000040
+	_RBBIM_ID        = 0x00000100
+	_RBBIM_IDEALSIZE = 0x00000200
+
+	_RBBS_BREAK      = 0x00000001
+	_RBBS_CHILDEDGE  = 0x00000004
+	_RBBS_HIDDEN     = 0x00000008
+	_RBBS_NOGRIPPER  = 0x00000100
+	_RBBS_USECHEVRON = 0x00000200
+
+	_RBN_HEIGHTCHANGE = ^uint32(830) // RBN_FIRST - 0
+	_RBN_LAYOUTCHANGE = ^uint32(832) // RBN_FIRST - 2
+
+	_RBS_VARHEIGHT    = 0x00000200
+	_RBS_BANDBORDERS  = 0x00000400
+	_RBS_DBLCLKTOGGLE = 0x00008000
+
+	_SM_CXPADDEDBORDER = 92
+
+	_SMTO_ABORTIFHUNG = 0x0002
+
+	_SPI_GETCLIENTAREAANIMATION = 0x1042
+	_SPI_SETWORKAREA            = 0x002F
+	_SPI_GETWHEELSCROLLLINES    = 0x0068
+	_SPI_GETWHEELSCROLLCHARS    = 0x006C
+
+	_StructureChangeType_ChildAdded   = 0
+	_StructureChangeType_ChildRemoved = 1
+
+	_TA_BASELINE = 24
+
+	_TBM_CLEARTICS      = win.WM_USER + 9
+	_TBM_SETTICFREQ     = win.WM_USER + 20
+	_TBM_GETTHUMBRECT   = win.WM_USER + 25
+	_TBM_GETCHANNELRECT = win.WM_USER + 26
+	_TBM_SETTHUMBLENGTH = win.WM_USER + 27
+
+	_TBN_BEGINADJUST   = ^uint32(702) // TBN_FIRST - 3
+	_TBN_ENDADJUST     = ^uint32(703) // TBN_FIRST - 4
+	_TBN_RESET         = ^uint32(704) // TBN_FIRST - 5
+	_TBN_QUERYINSERT   = ^uint32(705) // TBN_FIRST - 6
+	_TBN_QUERYDELETE   = ^uint32(706) // TBN_FIRST - 7
+	_TBN_TOOLBARCHANGE = ^uint32(707) // TBN_FIRST - 8
+	_TBN_GETBUTTONINFO = ^uint32(719) // TBN_FIRST - 20
+	_TBN_INITCUSTOMIZE = ^uint32(722) // TBN_FIRST - 23
+
+	_TBNRF_HIDEHELP = 0x00000001
+
+	_THB_ICON    = 0x00000002
+	_THB_TOOLTIP = 0x00000004
+	_THB_FLAGS   = 0x00000008
+
+	_THBF_DISABLED       = 0x00000001
+	_THBF_NOBACKGROUND   = 0x00000004
+	_THBF_HIDDEN         = 0x00000008
+	_THBF_NONINTERACTIVE = 0x00000010
+
+	_THBN_CLICKED = 0x1800
+
+	_TBS_AUTOTICKS   = 0x0001
+	_TBS_FIXEDLENGTH = 0x0040
+
+	_TKP_TRACK       = 1
+	_TKP_THUMBBOTTOM = 4
+
+	_TRS_NORMAL = 1
+
+	_TTDT_RESHOW  = 1
+	_TTDT_AUTOPOP = 2
+	_TTDT_INITIAL = 3
+
+	_TTN_GETDISPINFO = ^uint32(529) // TTN_FIRST - 10
+	_TTN_POP         = ^uint32(521) // TTN_FIRST - 2
+
+	_TUBS_NORMAL   = 1
+	_TUBS_HOT      = 2
+	_TUBS_PRESSED  = 3
+	_TUBS_FOCUSED  = 4
+	_TUBS_DISABLED = 5
+
+	_TVGN_DROPHILITE = 0x0008
+
+	_TVSIL_STATE = 2
+
+	_TYMED_HGLOBAL = 1
+
+	_UIA_E_ELEMENTNOTENABLED   = 0x80040200
+	_UIA_E_ELEMENTNOTAVAILABLE = 0x80040201
+	_UIA_E_INVALIDOPERATION    = 0x80131509
+
+	_UIA_InvokePatternId        = 10000
+	_UIA_SelectionPatternId     = 10001
+	_UIA_ValuePatternId         = 10002
+	_UIA_SelectionItemPatternId = 10010
+	_UIA_TogglePatternId        = 10015
+
+	_UIA_ToolTipOpenedEventId                             = 20000
+	_UIA_ToolTipClosedEventId                             = 20001
+	_UIA_MenuOpenedEventId                                = 20003
+	_UIA_AutomationFocusChangedEventId                    = 20005
+	_UIA_MenuClosedEventId                                = 20007
+	_UIA_LayoutInvalidatedEventId                         = 20008
+	_UIA_Invoke_InvokedEventId                            = 20009
+	_UIA_SelectionItem_ElementAddedToSelectionEventId     = 20010
+	_UIA_SelectionItem_ElementRemovedFromSelectionEventId = 20011
+	_UIA_SelectionItem_ElementSelectedEventId             = 20012
+	_UIA_Selection_InvalidatedEventId                     = 20013
+
+	_UIA_ControlTypePropertyId             = 30003
+	_UIA_LocalizedControlTypePropertyId    = 30004
+	_UIA_NamePropertyId                    = 30005
+	_UIA_HasKeyboardFocusPropertyId        = 30008
+	_UIA_IsKeyboardFocusablePropertyId     = 30009
+	_UIA_IsEnabledPropertyId               = 30010
+	_UIA_AutomationIdPropertyId            = 30011
+	_UIA_HelpTextPropertyId                = 30013
+	_UIA_ValueValuePropertyId              = 30045
+	_UIA_SelectionItemIsSelectedPropertyId = 30079
+	_UIA_ToggleToggleStatePropertyId       = 30086
+
+	_UIA_ButtonControlTypeId = 50000
+
+	_UiaAppendRuntimeId = 3
+	_UiaRootObjectId    = -25
+
+	_ULW_COLORKEY = 0x00000001
+	_ULW_ALPHA    = 0x00000002
+	_ULW_OPAQUE   = 0x00000004
+
+	_WHEEL_DELTA      = 120
+	_WHEEL_PAGESCROLL = ^uint32(0)
+
+	_WM_CHOOSEFONT_GETLOGFONT = win.WM_USER + 1
+
+	_WM_MOUSEHWHEEL = 0x020E
+)
+
+// chooseFont mirrors CHOOSEFONTW.
+type chooseFont struct {
+	lStructSize    uint32
+	hwndOwner      win.HWND
+	hDC            win.HDC
+	lpLogFont      *win.LOGFONT
+	iPointSize     int32
+	flags          uint32
+	rgbColors      win.COLORREF
+	lCustData      uintptr
+	lpfnHook       uintptr
+	lpTemplateName *uint16
+	hInstance      win.HINSTANCE
+	lpszStyle      *uint16
+	nFontType      uint16
+	_              uint16
+	nSizeMin       int32
+	nSizeMax       int32
+}
+
+// copyDataStruct mirrors COPYDATASTRUCT.
+type copyDataStruct struct {
+	dwData uintptr
+	cbData uint32
+	lpData unsafe.Pointer
+}
+
+// displayDevice mirrors DISPLAY_DEVICEW.
+type displayDevice struct {
+	cb           uint32
+	deviceName   [32]uint16
+	deviceString [128]uint16
+	stateFlags   uint32
+	deviceID     [128]uint16
+	deviceKey    [128]uint16
+}
+
+// dropFiles mirrors DROPFILES.
+type dropFiles struct {
+	pFiles uint32
+	pt     win.POINT
+	fNC    win.BOOL
+	fWide  win.BOOL
+}
+
+// findTextEx mirrors FINDTEXTEXW, whose fields are unexported in
+// github.com/tailscale/win.
+type findTextEx struct {
+	chrg      win.CHARRANGE
+	lpstrText *uint16
+	chrgText  win.CHARRANGE
+}
+
+// lItem mirrors LITEM.
+type lItem struct {
+	mask      uint32
+	iLink     int32
+	state     uint32
+	stateMask uint32
+	szID      [48]uint16
+	szUrl     [2084]uint16
+}
+
+// lvGroup mirrors LVGROUP.
+type lvGroup struct {
+	cbSize               uint32
+	mask                 uint32
+	pszHeader            *uint16
+	cchHeader            int32
+	pszFooter            *uint16
+	cchFooter            int32
+	iGroupId             int32
+	stateMask            uint32
+	state                uint32
+	uAlign               uint32
+	pszSubtitle          *uint16
+	cchSubtitle          uint32
+	pszTask              *uint16
+	cchTask              uint32
+	pszDescriptionTop    *uint16
+	cchDescriptionTop    uint32
+	pszDescriptionBottom *uint16
+	cchDescriptionBottom uint32
+	iTitleImage          int32
+	iExtendedImage       int32
+	iFirstItem           int32
+	cItems               uint32
+	pszSubsetTitle       *uint16
+	cchSubsetTitle       uint32
+}
+
+// lvInsertMark mirrors LVINSERTMARK.
+type lvInsertMark struct {
+	cbSize     uint32
+	dwFlags    uint32
+	iItem      int32
+	dwReserved uint32
+}
+
+// nmDayState mirrors NMDAYSTATE.
+type nmDayState struct {
+	nmhdr       win.NMHDR
+	stStart     win.SYSTEMTIME
+	cDayState   int32
+	prgDayState *uint32
+}
+
+// nmLVLink mirrors NMLVLINK.
+type nmLVLink struct {
+	hdr      win.NMHDR
+	link     lItem
+	iItem    int32
+	iSubItem int32
+}
+
+// nmTVItemChange mirrors NMTVITEMCHANGE.
+type nmTVItemChange struct {
+	hdr       win.NMHDR
+	uChanged  uint32
+	hItem     win.HTREEITEM
+	uStateNew uint32
+	uStateOld uint32
+	lParam    uintptr
+}
+
+// formatEtc mirrors FORMATETC.
+type formatEtc struct {
+	cfFormat uint16
+	ptd      uintptr
+	dwAspect uint32
+	lindex   int32
+	tymed    uint32
+}
+
+// uiaRect mirrors UiaRect.
+type uiaRect struct {
+	left   float64
+	top    float64
+	width  float64
+	height float64
+}
+
+// shDragImage mirrors SHDRAGIMAGE.
+type shDragImage struct {
+	sizeDragImage win.SIZE
+	ptOffset      win.POINT
+	hbmpDragImage win.HBITMAP
+	crColorKey    win.COLORREF
+}
+
+// stgMedium mirrors STGMEDIUM, with handle holding the member of the union
+// selected by tymed.
+type stgMedium struct {
+	tymed          uint32
+	handle         uintptr
+	pUnkForRelease uintptr
+}
+
+// monitorInfoEx mirrors MONITORINFOEXW.
+type monitorInfoEx struct {
+	win.MONITORINFO
+	szDevice [win.CCHDEVICENAME]uint16
+}
+
+// thumbButton mirrors THUMBBUTTON.
+type thumbButton struct {
+	dwMask  uint32
+	iId     uint32
+	iBitmap uint32
+	hIcon   win.HICON
+	szTip   [260]uint16
+	dwFlags uint32
+}
+
+// flashWInfo mirrors FLASHWINFO.
+type flashWInfo struct {
+	cbSize    uint32
+	hwnd      win.HWND
+	dwFlags   uint32
+	uCount    uint32
+	dwTimeout uint32
+}
+
+// systemPowerStatus mirrors SYSTEM_POWER_STATUS.
+type systemPowerStatus struct {
+	acLineStatus        byte
+	batteryFlag         byte
+	batteryLifePercent  byte
+	systemStatusFlag    byte
+	batteryLifeTime     uint32
+	batteryFullLifeTime uint32
+}
+
+// powerBroadcastSetting mirrors POWERBROADCAST_SETTING.
+type powerBroadcastSetting struct {
+	powerSetting syscall.GUID
+	dataLength   uint32
+	data         [1]byte
+}
+
+// reasonContext mirrors REASON_CONTEXT with a simple reason string.
+type reasonContext struct {
+	version            uint32
+	flags              uint32
+	simpleReasonString *uint16
+}
+
+// ncCalcSizeParams mirrors NCCALCSIZE_PARAMS.
+type ncCalcSizeParams struct {
+	rgrc  [3]win.RECT
+	lppos *win.WINDOWPOS
+}
+
+// nmTTDispInfo mirrors NMTTDISPINFOW.
+type nmTTDispInfo struct {
+	hdr      win.NMHDR
+	lpszText *uint16
+	szText   [80]uint16
+	hinst    win.HINSTANCE
+	uFlags   uint32
+	lParam   uintptr
+}
+
+// rebarBandInfo mirrors REBARBANDINFOW.
+type rebarBandInfo struct {
+	cbSize            uint32
+	fMask             uint32
+	fStyle            uint32
+	clrFore           win.COLORREF
+	clrBack           win.COLORREF
+	lpText            *uint16
+	cch               uint32
+	iImage            int32
+	hwndChild         win.HWND
+	cxMinChild        uint32
+	cyMinChild        uint32
+	cx                uint32
+	hbmBack           win.HBITMAP
+	wID               uint32
+	cyChild           uint32
+	cyMaxChild        uint32
+	cyIntegral        uint32
+	cxIdeal           uint32
+	lParam            uintptr
+	cxHeader          uint32
+	rcChevronLocation win.RECT
+	uChevronState     uint32
+}
+
+var (
+	libcomctl32 = windows.NewLazySystemDLL("comctl32.dll")
+	libcomdlg32 = windows.NewLazySystemDLL("comdlg32.dll")
+	libdwmapi   = windows.NewLazySystemDLL("dwmapi.dll")
+	libgdi32    = windows.NewLazySystemDLL("gdi32.dll")
+	libkernel32 = windows.NewLazySystemDLL("kernel32.dll")
+	libmsftedit = windows.NewLazySystemDLL("msftedit.dll")
+	libole32    = windows.NewLazySystemDLL("ole32.dll")
+	liboleaut32 = windows.NewLazySystemDLL("oleaut32.dll")
+	libshell32  = windows.NewLazySystemDLL("shell32.dll")
+	libshcore   = windows.NewLazySystemDLL("shcore.dll")
+	libuser32   = windows.NewLazySystemDLL("user32.dll")
+
+	libuiautomationcore = windows.NewLazySystemDLL("uiautomationcore.dll")
+
+	procAllowSetForegroundWindow              = libuser32.NewProc("AllowSetForegroundWindow")
+	procApplicationRecoveryFinished           = libkernel32.NewProc("ApplicationRecoveryFinished")
+	procApplicationRecoveryInProgress         = libkernel32.NewProc("ApplicationRecoveryInProgress")
+	procCascadeWindows                        = libuser32.NewProc("CascadeWindows")
+	procChooseFont                            = libcomdlg32.NewProc("ChooseFontW")
+	procCoTaskMemAlloc                        = libole32.NewProc("CoTaskMemAlloc")
+	procDoDragDrop                            = libole32.NewProc("DoDragDrop")
+	procDwmExtendFrameIntoClientArea          = libdwmapi.NewProc("DwmExtendFrameIntoClientArea")
+	procEnumDisplayDevices                    = libuser32.NewProc("EnumDisplayDevicesW")
+	procEnumDisplayMonitors                   = libuser32.NewProc("EnumDisplayMonitors")
+	procEnumDisplaySettings                   = libuser32.NewProc("EnumDisplaySettingsW")
+	procEnumFontFamiliesEx                    = libgdi32.NewProc("EnumFontFamiliesExW")
+	procImageList_BeginDrag                   = libcomctl32.NewProc("ImageList_BeginDrag")
+	procImageList_DragEnter                   = libcomctl32.NewProc("ImageList_DragEnter")
+	procImageList_DragLeave                   = libcomctl32.NewProc("ImageList_DragLeave")
+	procImageList_DragMove                    = libcomctl32.NewProc("ImageList_DragMove")
+	procImageList_DragShowNolock              = libcomctl32.NewProc("ImageList_DragShowNolock")
+	procImageList_EndDrag                     = libcomctl32.NewProc("ImageList_EndDrag")
+	procFindWindowEx                          = libuser32.NewProc("FindWindowExW")
+	procFlashWindowEx                         = libuser32.NewProc("FlashWindowEx")
+	procGetDpiForMonitor                      = libshcore.NewProc("GetDpiForMonitor")
+	procGlobalSize                            = libkernel32.NewProc("GlobalSize")
+	procGetSystemPowerStatus                  = libkernel32.NewProc("GetSystemPowerStatus")
+	procGetMonitorInfo                        = libuser32.NewProc("GetMonitorInfoW")
+	procMonitorFromPoint                      = libuser32.NewProc("MonitorFromPoint")
+	procMonitorFromRect                       = libuser32.NewProc("MonitorFromRect")
+	procPolygon                               = libgdi32.NewProc("Polygon")
+	procPowerClearRequest                     = libkernel32.NewProc("PowerClearRequest")
+	procPowerCreateRequest                    = libkernel32.NewProc("PowerCreateRequest")
+	procPowerSetRequest                       = libkernel32.NewProc("PowerSetRequest")
+	procRegisterDragDrop                      = libole32.NewProc("RegisterDragDrop")
+	procReleaseStgMedium                      = libole32.NewProc("ReleaseStgMedium")
+	procRevokeDragDrop                        = libole32.NewProc("RevokeDragDrop")
+	procRegisterClipboardFormat               = libuser32.NewProc("RegisterClipboardFormatW")
+	procShutdownBlockReasonCreate             = libuser32.NewProc("ShutdownBlockReasonCreate")
+	procShutdownBlockReasonDestroy            = libuser32.NewProc("ShutdownBlockReasonDestroy")
+	procShowCursor                            = libuser32.NewProc("ShowCursor")
+	procRegisterPowerSettingNotification      = libuser32.NewProc("RegisterPowerSettingNotification")
+	procRegisterApplicationRecoveryCallback   = libkernel32.NewProc("RegisterApplicationRecoveryCallback")
+	procRegisterApplicationRestart            = libkernel32.NewProc("RegisterApplicationRestart")
+	procSafeArrayCreateVector                 = liboleaut32.NewProc("SafeArrayCreateVector")
+	procSafeArrayPutElement                   = liboleaut32.NewProc("SafeArrayPutElement")
+	procSendMessageTimeout                    = libuser32.NewProc("SendMessageTimeoutW")
+	procSHAddToRecentDocs                     = libshell32.NewProc("SHAddToRecentDocs")
+	procSHCreateDataObject                    = libshell32.NewProc("SHCreateDataObject")
+	procSHCreateItemFromParsingName           = libshell32.NewProc("SHCreateItemFromParsingName")
+	procSetLayeredWindowAttributes            = libuser32.NewProc("SetLayeredWindowAttributes")
+	procSetWindowRgn                          = libuser32.NewProc("SetWindowRgn")
+	procTileWindows                           = libuser32.NewProc("TileWindows")
+	procUiaClientsAreListening                = libuiautomationcore.NewProc("UiaClientsAreListening")
+	procUiaDisconnectProvider                 = libuiautomationcore.NewProc("UiaDisconnectProvider")
+	procUiaHostProviderFromHwnd               = libuiautomationcore.NewProc("UiaHostProviderFromHwnd")
+	procUiaRaiseAutomationEvent               = libuiautomationcore.NewProc("UiaRaiseAutomationEvent")
+	procUiaRaiseAutomationPropertyChanged     = libuiautomationcore.NewProc("UiaRaiseAutomationPropertyChangedEvent")
+	procUiaRaiseNotificationEvent             = libuiautomationcore.NewProc("UiaRaiseNotificationEvent")
+	procUiaRaiseStructureChangedEvent         = libuiautomationcore.NewProc("UiaRaiseStructureChangedEvent")
+	procUiaReturnRawElementProvider           = libuiautomationcore.NewProc("UiaReturnRawElementProvider")
+	procUnregisterApplicationRecoveryCallback = libkernel32.NewProc("UnregisterApplicationRecoveryCallback")
+	procUnregisterApplicationRestart          = libkernel32.NewProc("UnregisterApplicationRestart")
+	procUpdateLayeredWindow                   = libuser32.NewProc("UpdateLayeredWindow")
+	procVariantClear                          = liboleaut32.NewProc("VariantClear")
+)
+
+func allowSetForegroundWindow(processID uint32) bool {
+	ret, _, _ := syscall.SyscallN(procAllowSetForegroundWindow.Addr(),
+		uintptr(processID))
+
+	return ret != 0
+}
+
+func applicationRecoveryFinished(success bool) {
+	syscall.SyscallN(procApplicationRecoveryFinished.Addr(),
+		uintptr(win.BoolToBOOL(success)))
+}
+
+func applicationRecoveryInProgress(cancelled *win.BOOL) win.HRESULT {
+	ret, _, _ := syscall.SyscallN(procApplicationRecoveryInProgress.Addr(),
+		uintptr(unsafe.Pointer(cancelled)))
+
+	return win.HRESULT(ret)
+}
+
+func cascadeWindows(hwnds []win.HWND) uint16 {
+	if len(hwnds) == 0 {
+		return 0
+	}
+
+	ret, _, _ := syscall.SyscallN(procCascadeWindows.Addr(),
+		0,
+		0,
+		0,
+		uintptr(len(hwnds)),
+		uintptr(unsafe.Pointer(&hwnds[0])))
+
+	return uint16(ret)
+}
+
+// coTaskMemAlloc allocates memory that COM may free using CoTaskMemFree.
+func coTaskMemAlloc(size uintptr) unsafe.Pointer {
+	ret, _, _ := syscall.SyscallN(procCoTaskMemAlloc.Addr(),
+		size)
+
+	return unsafe.Pointer(ret)
+}
+
+func doDragDrop(dataObject *win.IDataObject, dropSource unsafe.Pointer, okEffects uint32, effect *uint32) win.HRESULT {
+	ret, _, _ := syscall.SyscallN(procDoDragDrop.Addr(),
+		uintptr(unsafe.Pointer(dataObject)),
+		uintptr(dropSource),
+		uintptr(okEffects),
+		uintptr(unsafe.Pointer(effect)))
+
+	return win.HRESULT(ret)
+}
+
+func dwmExtendFrameIntoClientArea(hwnd win.HWND, margins *win.MARGINS) win.HRESULT {
+	ret, _, _ := syscall.SyscallN(procDwmExtendFrameIntoClientArea.Addr(),
+		uintptr(hwnd),
+		uintptr(unsafe.Pointer(margins)))
+
+	return win.HRESULT(ret)
+}
+
+func enumDisplayDevices(device *uint16, devNum uint32, dd *displayDevice, flags uint32) bool {
+	ret, _, _ := syscall.SyscallN(procEnumDisplayDevices.Addr(),
+		uintptr(unsafe.Pointer(device)),
+		uintptr(devNum),
+		uintptr(unsafe.Pointer(dd)),
+		uintptr(flags))
+
+	return ret != 0
+}
+
+// enumDisplayMonitors calls proc for each monitor, passing it lParam, until
+// proc returns 0.
+func enumDisplayMonitors(proc, lParam uintptr) bool {
+	ret, _, _ := syscall.SyscallN(procEnumDisplayMonitors.Addr(),
+		0,
+		0,
+		proc,
+		lParam)
+
+	return ret != 0
+}
+
+func enumDisplaySettings(deviceName *uint16, modeNum uint32, devMode *win.DEVMODE) bool {
+	ret, _, _ := syscall.SyscallN(procEnumDisplaySettings.Addr(),
+		uintptr(unsafe.Pointer(deviceName)),
+		uintptr(modeNum),
+		uintptr(unsafe.Pointer(devMode)))
+
+	return ret != 0
+}
+
+// enumFontFamiliesEx calls proc for each font matching lf, passing it
+// lParam, until proc returns 0.
+func enumFontFamiliesEx(hdc win.HDC, lf *win.LOGFONT, proc, lParam uintptr) int32 {
+	ret, _, _ := syscall.SyscallN(procEnumFontFamiliesEx.Addr(),
+		uintptr(hdc),
+		uintptr(unsafe.Pointer(lf)),
+		proc,
+		lParam,
+		0)
+
+	return int32(ret)
+}
+
+func imageList_BeginDrag(himlTrack win.HIMAGELIST, iTrack, dxHotspot, dyHotspot int32) bool {
+	ret, _, _ := syscall.SyscallN(procImageList_BeginDrag.Addr(),
+		uintptr(himlTrack),
+		uintptr(iTrack),
+		uintptr(dxHotspot),
+		uintptr(dyHotspot))
+
+	return ret != 0
+}
+
+func imageList_DragEnter(hwndLock win.HWND, x, y int32) bool {
+	ret, _, _ := syscall.SyscallN(procImageList_DragEnter.Addr(),
+		uintptr(hwndLock),
+		uintptr(x),
+		uintptr(y))
+
+	return ret != 0
+}
+
+func imageList_DragLeave(hwndLock win.HWND) bool {
+	ret, _, _ := syscall.SyscallN(procImageList_DragLeave.Addr(),
+		uintptr(hwndLock))
+
+	return ret != 0
+}
+
+func imageList_DragMove(x, y int32) bool {
+	ret, _, _ := syscall.SyscallN(procImageList_DragMove.Addr(),
+		uintptr(x),
+		uintptr(y))
+
+	return ret != 0
+}
+
+func imageList_DragShowNolock(show bool) bool {
+	var fShow uintptr
+	if show {
+		fShow = 1
+	}
+
+	ret, _, _ := syscall.SyscallN(procImageList_DragShowNolock.Addr(),
+		fShow)
+
+	return ret != 0
+}
+
+func imageList_EndDrag() {
+	syscall.SyscallN(procImageList_EndDrag.Addr())
+}
+
+func polygon(hdc win.HDC, points *win.POINT, count int32) bool {
+	ret, _, _ := syscall.SyscallN(procPolygon.Addr(),
+		uintptr(hdc),
+		uintptr(unsafe.Pointer(points)),
+		uintptr(count))
+
+	return ret != 0
+}
+
+func globalSize(hMem win.HGLOBAL) uintptr {
+	ret, _, _ := syscall.SyscallN(procGlobalSize.Addr(),
+		uintptr(hMem))
+
+	return ret
+}
+
+func findWindowEx(hwndParent, hwndChildAfter win.HWND, className, windowName *uint16) win.HWND {
+	ret, _, _ := syscall.SyscallN(procFindWindowEx.Addr(),
+		uintptr(hwndParent),
+		uintptr(hwndChildAfter),
+		uintptr(unsafe.Pointer(className)),
+		uintptr(unsafe.Pointer(windowName)))
+
+	return win.HWND(ret)
+}
+
+func flashWindowEx(fwi *flashWInfo) bool {
+	fwi.cbSize = uint32(unsafe.Sizeof(*fwi))
+
+	ret, _, _ := syscall.SyscallN(procFlashWindowEx.Addr(),
+		uintptr(unsafe.Pointer(fwi)))
+
+	return ret != 0
+}
+
+func thumbBarAddButtons(tl *win.ITaskbarList3, hwnd win.HWND, buttons []thumbButton) win.HRESULT {
+	ret, _, _ := syscall.SyscallN(tl.LpVtbl.ThumbBarAddButtons,
+		uintptr(unsafe.Pointer(tl)),
+		uintptr(hwnd),
+		uintptr(len(buttons)),
+		uintptr(unsafe.Pointer(&buttons[0])))
+
+	return win.HRESULT(ret)
+}
+
+func thumbBarUpdateButtons(tl *win.ITaskbarList3, hwnd win.HWND, buttons []thumbButton) win.HRESULT {
+	ret, _, _ := syscall.SyscallN(tl.LpVtbl.ThumbBarUpdateButtons,
+		uintptr(unsafe.Pointer(tl)),
+		uintptr(hwnd),
+		uintptr(len(buttons)),
+		uintptr(unsafe.Pointer(&buttons[0])))
+
+	return win.HRESULT(ret)
+}
+
+func getSystemPowerStatus(sps *systemPowerStatus) bool {
+	ret, _, _ := syscall.SyscallN(procGetSystemPowerStatus.Addr(),
+		uintptr(unsafe.Pointer(sps)))
+
+	return ret != 0
+}
+
+// registerClipboardFormat returns the id of the clipboard format name,
+// registering it if necessary, or 0 on failure.
+func registerClipboardFormat(name string) uint32 {
+	ret, _, _ := syscall.SyscallN(procRegisterClipboardFormat.Addr(),
+		uintptr(unsafe.Pointer(syscall.StringToUTF16Ptr(name))))
+
+	return uint32(ret)
+}
+
+// getDpiForMonitor returns the effective DPI of hMonitor, or 0 on failure.
+func getDpiForMonitor(hMonitor win.HMONITOR) int {
+	var dpiX, dpiY uint32
+	ret, _, _ := syscall.SyscallN(procGetDpiForMonitor.Addr(),
+		uintptr(hMonitor),
+		0, // MDT_EFFECTIVE_DPI
+		uintptr(unsafe.Pointer(&dpiX)),
+		uintptr(unsafe.Pointer(&dpiY)))
+
+	if win.FAILED(win.HRESULT(ret)) {
+		return 0
+	}
+
+	return int(dpiX)
+}
+
+// getMonitorInfoEx is GetMonitorInfo, additionally retrieving the device
+// name of hMonitor.
+func getMonitorInfoEx(hMonitor win.HMONITOR, mi *monitorInfoEx) bool {
+	mi.CbSize = uint32(unsafe.Sizeof(*mi))
+
+	ret, _, _ := syscall.SyscallN(procGetMonitorInfo.Addr(),
+		uintptr(hMonitor),
+		uintptr(unsafe.Pointer(mi)))
+
+	return ret != 0
+}
+
+func monitorFromPoint(pt win.POINT, flags uint32) win.HMONITOR {
+	// POINT is passed by value, which takes two stack slots on 386.
+	var ret uintptr
+	if unsafe.Sizeof(uintptr(0)) == 4 {
+		ret, _, _ = syscall.SyscallN(procMonitorFromPoint.Addr(),
+			uintptr(pt.X),
+			uintptr(pt.Y),
+			uintptr(flags))
+	} else {
+		ret, _, _ = syscall.SyscallN(procMonitorFromPoint.Addr(),
+			uintptr(uint64(uint32(pt.X))|uint64(uint32(pt.Y))<<32),
+			uintptr(flags))
+	}
+
+	return win.HMONITOR(ret)
+}
+
+func monitorFromRect(rc *win.RECT, flags uint32) win.HMONITOR {
+	ret, _, _ := syscall.SyscallN(procMonitorFromRect.Addr(),
+		uintptr(unsafe.Pointer(rc)),
+		uintptr(flags))
+
+	return win.HMONITOR(ret)
+}
+
+// showCursor increments or decrements the display counter of the cursor of
+// the calling thread and returns the new counter.
+func showCursor(show bool) int32 {
+	ret, _, _ := syscall.SyscallN(procShowCursor.Addr(),
+		uintptr(win.BoolToBOOL(show)))
+
+	return int32(ret)
+}
+
+func powerClearRequest(request windows.Handle, requestType uint32) bool {
+	ret, _, _ := syscall.SyscallN(procPowerClearRequest.Addr(),
+		uintptr(request),
+		uintptr(requestType))
+
+	return ret != 0
+}
+
+func powerCreateRequest(context *reasonContext) windows.Handle {
+	ret, _, _ := syscall.SyscallN(procPowerCreateRequest.Addr(),
+		uintptr(unsafe.Pointer(context)))
+
+	return windows.Handle(ret)
+}
+
+func powerSetRequest(request windows.Handle, requestType uint32) bool {
+	ret, _, _ := syscall.SyscallN(procPowerSetRequest.Addr(),
+		uintptr(request),
+		uintptr(requestType))
+
+	return ret != 0
+}
+
+func registerApplicationRecoveryCallback(callback, param uintptr, pingIntervalMilliseconds, flags uint32) win.HRESULT {
+	ret, _, _ := syscall.SyscallN(procRegisterApplicationRecoveryCallback.Addr(),
+		callback,
+		param,
+		uintptr(pingIntervalMilliseconds),
+		uintptr(flags))
+
+	return win.HRESULT(ret)
+}
+
+func registerApplicationRestart(commandLine *uint16, flags uint32) win.HRESULT {
+	ret, _, _ := syscall.SyscallN(procRegisterApplicationRestart.Addr(),
+		uintptr(unsafe.Pointer(commandLine)),
+		uintptr(flags))
+
+	return win.HRESULT(ret)
+}
+
+func registerDragDrop(hwnd win.HWND, dropTarget unsafe.Pointer) win.HRESULT {
+	ret, _, _ := syscall.SyscallN(procRegisterDragDrop.Addr(),
+		uintptr(hwnd),
+		uintptr(dropTarget))
+
+	return win.HRESULT(ret)
+}
+
+func releaseStgMedium(medium *stgMedium) {
+	syscall.SyscallN(procReleaseStgMedium.Addr(),
+		uintptr(unsafe.Pointer(medium)))
+}
+
+func revokeDragDrop(hwnd win.HWND) win.HRESULT {
+	ret, _, _ := syscall.SyscallN(procRevokeDragDrop.Addr(),
+		uintptr(hwnd))
+
+	return win.HRESULT(ret)
+}
+
+func registerPowerSettingNotification(hwnd win.HWND, powerSetting *syscall.GUID) uintptr {
+	ret, _, _ := syscall.SyscallN(procRegisterPowerSettingNotification.Addr(),
+		uintptr(hwnd),
+		uintptr(unsafe.Pointer(powerSetting)),
+		0) // DEVICE_NOTIFY_WINDOW_HANDLE
+
+	return ret
+}
+
+func sendMessageTimeout(hwnd win.HWND, msg uint32, wParam, lParam uintptr, flags, timeoutMilliseconds uint32, result *uintptr) bool {
+	ret, _, _ := syscall.SyscallN(procSendMessageTimeout.Addr(),
+		uintptr(hwnd),
+		uintptr(msg),
+		wParam,
+		lParam,
+		uintptr(flags),
+		uintptr(timeoutMilliseconds),
+		uintptr(unsafe.Pointer(result)))
+
+	return ret != 0
+}
+
+func shutdownBlockReasonCreate(hwnd win.HWND, reason *uint16) bool {
+	ret, _, _ := syscall.SyscallN(procShutdownBlockReasonCreate.Addr(),
+		uintptr(hwnd),
+		uintptr(unsafe.Pointer(reason)))
+
+	return ret != 0
+}
+
+func shutdownBlockReasonDestroy(hwnd win.HWND) bool {
+	ret, _, _ := syscall.SyscallN(procShutdownBlockReasonDestroy.Addr(),
+		uintptr(hwnd))
+
+	return ret != 0
+}
+
+func shAddToRecentDocs(flags uint32, pv unsafe.Pointer) {
+	syscall.SyscallN(procSHAddToRecentDocs.Addr(),
+		uintptr(flags),
+		uintptr(pv))
+}
+
+func setLayeredWindowAttributes(hwnd win.HWND, crKey win.COLORREF, alpha byte, flags uint32) bool {
+	ret, _, _ := syscall.SyscallN(procSetLayeredWindowAttributes.Addr(),
+		uintptr(hwnd),
+		uintptr(crKey),
+		uintptr(alpha),
+		uintptr(flags))
+
+	return ret != 0
+}
+
+// setWindowRgn sets the window region of hwnd. The system owns hRgn
+// afterwards; pass 0 to remove the region.
+func setWindowRgn(hwnd win.HWND, hRgn win.HRGN, redraw bool) bool {
+	ret, _, _ := syscall.SyscallN(procSetWindowRgn.Addr(),
+		uintptr(hwnd),
+		uintptr(hRgn),
+		uintptr(win.BoolToBOOL(redraw)))
+
+	return ret != 0
+}
+
+// shCreateDataObject creates an empty data object that stores the data set on
+// it, in any format.
+func shCreateDataObject(riid *win.IID, ppv *unsafe.Pointer) win.HRESULT {
+	ret, _, _ := syscall.SyscallN(procSHCreateDataObject.Addr(),
+		0,
+		0,
+		0,
+		0,
+		uintptr(unsafe.Pointer(riid)),
+		uintptr(unsafe.Pointer(ppv)))
+
+	return win.HRESULT(ret)
+}
+
+// shCreateItemFromParsingName creates the shell item for path, which is
+// returned through ppv as interface riid.
+func shCreateItemFromParsingName(path *uint16, riid *win.IID, ppv *unsafe.Pointer) win.HRESULT {
+	ret, _, _ := syscall.SyscallN(procSHCreateItemFromParsingName.Addr(),
+		uintptr(unsafe.Pointer(path)),
+		0,
+		uintptr(unsafe.Pointer(riid)),
+		uintptr(unsafe.Pointer(ppv)))
+
+	return win.HRESULT(ret)
+}
+
+// showChooseFont displays the common font dialog described by cf.
+func showChooseFont(cf *chooseFont) bool {
+	ret, _, _ := syscall.SyscallN(procChooseFont.Addr(),
+		uintptr(unsafe.Pointer(cf)))
+
+	return ret != 0
+}
+
+func tileWindows(how uint32, hwnds []win.HWND) uint16 {
+	if len(hwnds) == 0 {
+		return 0
+	}
+
+	ret, _, _ := syscall.SyscallN(procTileWindows.Addr(),
+		0,
+		uintptr(how),
+		0,
+		uintptr(len(hwnds)),
+		uintptr(unsafe.Pointer(&hwnds[0])))
+
+	return uint16(ret)
+}
+
+func unregisterApplicationRecoveryCallback() win.HRESULT {
+	ret, _, _ := syscall.SyscallN(procUnregisterApplicationRecoveryCallback.Addr())
+
+	return win.HRESULT(ret)
+}
+
+func unregisterApplicationRestart() win.HRESULT {
+	ret, _, _ := syscall.SyscallN(procUnregisterApplicationRestart.Addr())
+
+	return win.HRESULT(ret)
+}
+
+func updateLayeredWindow(hwnd win.HWND, hdcDst win.HDC, pptDst *win.POINT, psize *win.SIZE, hdcSrc win.HDC, pptSrc *win.POINT, crKey win.COLORREF, pblend *win.BLENDFUNCTION, flags uint32) bool {
+	ret, _, _ := syscall.SyscallN(procUpdateLayeredWindow.Addr(),
+		uintptr(hwnd),
+		uintptr(hdcDst),
+		uintptr(unsafe.Pointer(pptDst)),
+		uintptr(unsafe.Pointer(psize)),
+		uintptr(hdcSrc),
+		uintptr(unsafe.Pointer(pptSrc)),
+		uintptr(crKey),
+		uintptr(unsafe.Pointer(pblend)),
+		uintptr(flags))
+
+	return ret != 0
+}
+
+// safeArrayCreateVector creates a one-dimensional SAFEARRAY of count elements
+// of type vt.
+func safeArrayCreateVector(vt win.VARTYPE, count int) *win.SAFEARRAY {
+	ret, _, _ := syscall.SyscallN(procSafeArrayCreateVector.Addr(),
+		uintptr(vt),
+		0,
+		uintptr(count))
+
+	return (*win.SAFEARRAY)(unsafe.Pointer(ret))
+}
+
+// safeArrayPutElement stores the element at index of psa. For interface
+// pointers and BSTRs, pv is the pointer itself, which is AddRef'ed or copied.
+func safeArrayPutElement(psa *win.SAFEARRAY, index int32, pv unsafe.Pointer) win.HRESULT {
+	ret, _, _ := syscall.SyscallN(procSafeArrayPutElement.Addr(),
+		uintptr(unsafe.Pointer(psa)),
+		uintptr(unsafe.Pointer(&index)),
+		uintptr(pv))
+
+	return win.HRESULT(ret)
+}
+
+func uiaClientsAreListening() bool {
+	ret, _, _ := syscall.SyscallN(procUiaClientsAreListening.Addr())
+
+	return ret != 0
+}
+
+// uiaDisconnectProvider releases the references UI Automation holds to
+// provider. It requires Windows 8 and does nothing on older versions.
+func uiaDisconnectProvider(provider unsafe.Pointer) {
+	if procUiaDisconnectProvider.Find() != nil {
+		return
+	}
+
+	syscall.SyscallN(procUiaDisconnectProvider.Addr(),
+		uintptr(provider))
+}
+
+func uiaHostProviderFromHwnd(hwnd win.HWND, provider *unsafe.Pointer) win.HRESULT {
+	ret, _, _ := syscall.SyscallN(procUiaHostProviderFromHwnd.Addr(),
+		uintptr(hwnd),
+		uintptr(unsafe.Pointer(provider)))
+
+	return win.HRESULT(ret)
+}
+
+func uiaRaiseAutomationEvent(provider unsafe.Pointer, id int32) win.HRESULT {
+	ret, _, _ := syscall.SyscallN(procUiaRaiseAutomationEvent.Addr(),
+		uintptr(provider),
+		uintptr(id))
+
+	return win.HRESULT(ret)
+}
+
+func uiaRaiseAutomationPropertyChangedEvent(provider unsafe.Pointer, id int32, oldValue, newValue *win.VARIANT) win.HRESULT {
+	args := []uintptr{uintptr(provider), uintptr(id)}
+
+	if unsafe.Sizeof(uintptr(0)) == 4 {
+		// The VARIANTs are passed by value, i.e. on the stack, on 386.
+		words := unsafe.Sizeof(win.VARIANT{}) / unsafe.Sizeof(uintptr(0))
+		args = append(args, unsafe.Slice((*uintptr)(unsafe.Pointer(oldValue)), words)...)
+		args = append(args, unsafe.Slice((*uintptr)(unsafe.Pointer(newValue)), words)...)
+	} else {
+		// Elsewhere, they are too large for registers and passed by reference.
+		args = append(args, uintptr(unsafe.Pointer(oldValue)), uintptr(unsafe.Pointer(newValue)))
+	}
+
+	ret, _, _ := syscall.SyscallN(procUiaRaiseAutomationPropertyChanged.Addr(), args...)
+
+	return win.HRESULT(ret)
+}
+
+func uiaRaiseStructureChangedEvent(provider unsafe.Pointer, changeType int32, runtimeId []int32) win.HRESULT {
+	ret, _, _ := syscall.SyscallN(procUiaRaiseStructureChangedEvent.Addr(),
+		uintptr(provider),
+		uintptr(changeType),
+		uintptr(unsafe.Pointer(&runtimeId[0])),
+		uintptr(len(runtimeId)))
+
+	return win.HRESULT(ret)
+}
+
+// uiaReturnRawElementProvider returns the response to a WM_GETOBJECT message
+// for provider. A nil provider tells UI Automation that hwnd is going away.
+func uiaReturnRawElementProvider(hwnd win.HWND, wParam, lParam uintptr, provider unsafe.Pointer) uintptr {
+	ret, _, _ := syscall.SyscallN(procUiaReturnRawElementProvider.Addr(),
+		uintptr(hwnd),
+		wParam,
+		lParam,
+		uintptr(provider))
+
+	return ret
+}
+
+// variantClear frees the contents of v, e.g. a BSTR, and makes it empty.
+func variantClear(v *win.VARIANT) win.HRESULT {
+	ret, _, _ := syscall.SyscallN(procVariantClear.Addr(),
+		uintptr(unsafe.Pointer(v)))
+
+	return win.HRESULT(ret)
+}
+
+// uiaRaiseNotificationEvent asks screen readers to announce text. It requires
+// Windows 10 version 1709.
+func uiaRaiseNotificationEvent(provider unsafe.Pointer, kind, processing int32, text, activityID *uint16) win.HRESULT {
+	ret, _, _ := syscall.SyscallN(procUiaRaiseNotificationEvent.Addr(),
+		uintptr(provider),
+		uintptr(kind),
+		uintptr(processing),
+		uintptr(unsafe.Pointer(text)),
+		uintptr(unsafe.Pointer(activityID)))
+
+	return win.HRESULT(ret)
+}
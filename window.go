@@ -420,6 +420,15 @@ type WindowBase struct {
 	disposables                 []Disposable
 	disposingPublisher          EventPublisher
 	dropFilesPublisher          DropFilesEventPublisher
+	dropTarget                  *dropTarget
+	dropEnabled                 bool
+	dragEnteredPublisher        GenericEventPublisher[*DropInfo]
+	dragMovedPublisher          GenericEventPublisher[*DropInfo]
+	dragLeftPublisher           EventPublisher
+	droppedPublisher            GenericEventPublisher[*DropInfo]
+	fileDropEnabled             bool
+	fileDropAccept              func(drop *FileDrop) bool
+	filesDroppedPublisher       GenericEventPublisher[*FileDrop]
 	keyDownPublisher            KeyEventPublisher
 	keyPressPublisher           KeyEventPublisher
 	keyUpPublisher              KeyEventPublisher
@@ -440,12 +449,14 @@ type WindowBase struct {
 	visibleChangedPublisher     EventPublisher
 	focusedProperty             Property
 	focusedChangedPublisher     EventPublisher
+	keyboardCuesPublisher       EventPublisher
 	calcTextSizeInfo2TextSize   map[calcTextSizeInfo]Size // in native pixels
 	suspended                   bool
 	visible                     bool
 	enabled                     bool
 	acc                         *Accessibility
 	themes                      map[string]*Theme
+	imagePins                   iconCachePins // cached images in use by the window
 	menuSharedMetricsInitialDPI *menuSharedMetrics
 	// onHelp is the possibly nil func passed to WindowBase.SetHelp.
 	onHelp func(hwnd win.HWND, wb *WindowBase, hi *win.HELPINFO) (handled bool)
@@ -958,6 +969,10 @@ func (wb *WindowBase) disposeInternal(hwnd win.HWND) {
 	}
 	wb.disposables = nil
 
+	wb.releaseDropTarget()
+
+	iconCache.unpin(&wb.imagePins)
+
 	if wb.background != nil {
 		wb.background.detachWindow(wb)
 		wb.background = nil
@@ -1656,6 +1671,40 @@ func (wb *WindowBase) dialogBaseUnits() Size {
 	return s
 }
 
+var fontInfoAndDPI2Ascent = make(map[fontInfoAndDPI]int)
+
+// fontAscent returns the ascent of the font of the *WindowBase in native
+// pixels.
+func (wb *WindowBase) fontAscent() int {
+	font := wb.window.Font()
+	fi := fontInfoAndDPI{
+		fontInfo: fontInfo{
+			family:    font.Family(),
+			pointSize: font.PointSize(),
+			style:     font.Style(),
+		},
+		dpi: wb.DPI()}
+	if ascent, ok := fontInfoAndDPI2Ascent[fi]; ok {
+		return ascent
+	}
+
+	hdc := win.GetDC(wb.hWnd)
+	defer win.ReleaseDC(wb.hWnd, hdc)
+
+	hFont := font.handleForDPI(wb.DPI())
+	hFontOld := win.SelectObject(hdc, win.HGDIOBJ(hFont))
+	defer win.SelectObject(hdc, win.HGDIOBJ(hFontOld))
+
+	var tm win.TEXTMETRIC
+	if !win.GetTextMetrics(hdc, &tm) {
+		newError("GetTextMetrics failed")
+	}
+
+	fontInfoAndDPI2Ascent[fi] = int(tm.TmAscent)
+
+	return int(tm.TmAscent)
+}
+
 // dialogBaseUnitsToPixels returns size in dialog based units in native pixels.
 func (wb *WindowBase) dialogBaseUnitsToPixels(dlus Size) (pixels Size) {
 	base := wb.dialogBaseUnits()
@@ -2010,11 +2059,7 @@ func (wb *WindowBase) RequestLayout() {
 		return
 	}
 
-	if fb := form.AsFormBase(); activeForm != fb {
-		fb.startLayout()
-	} else {
-		fb.layoutScheduled = true
-	}
+	form.AsFormBase().scheduleLayout()
 }
 
 // RightToLeftReading returns whether the reading order of the Window
@@ -2399,6 +2444,16 @@ func (wb *WindowBase) handleWMCTLCOLOR(wParam, lParam uintptr) uintptr {
 		win.SetTextColor(hdc, win.COLORREF(color))
 	}
 
+	type ctlColorBrusher interface {
+		ctlColorBrush(hdc win.HDC) win.HBRUSH
+	}
+
+	if ccb, ok := wnd.(ctlColorBrusher); ok {
+		if hBrush := ccb.ctlColorBrush(hdc); hBrush != 0 {
+			return uintptr(hBrush)
+		}
+	}
+
 	if bg, wnd := wnd.AsWindowBase().backgroundEffective(); bg != nil {
 		wb.prepareDCForBackground(hdc, hwnd, wnd)
 
@@ -2434,6 +2489,20 @@ func (wb *WindowBase) handleWMCTLCOLOR(wParam, lParam uintptr) uintptr {
 	return 0
 }
 
+// discardThemeData closes all cached theme handles and metrics of wb. They are
+// reloaded lazily when needed next.
+func (wb *WindowBase) discardThemeData() {
+	if wb.menuSharedMetricsInitialDPI != nil {
+		dpicache.Delete(wb.menuSharedMetricsInitialDPI)
+		wb.menuSharedMetricsInitialDPI = nil
+	}
+
+	for _, v := range wb.themes {
+		v.close()
+	}
+	clear(wb.themes)
+}
+
 // menuSharedMetrics obtains menuSharedMetrics associated with wb, scaled to
 // wb's current DPI.
 func (wb *WindowBase) menuSharedMetrics() *menuSharedMetrics {
@@ -2451,6 +2520,12 @@ func (wb *WindowBase) WndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr)
 	window := windowFromHandle(hwnd)
 
 	switch msg {
+	case win.WM_NCHITTEST:
+		if wb.passesHitTestToForm(Point{int(win.GET_X_LPARAM(lParam)), int(win.GET_Y_LPARAM(lParam))}) {
+			hit := int32(win.HTTRANSPARENT)
+			return uintptr(hit)
+		}
+
 	case win.WM_HELP:
 		if wb.onHelp != nil && wb.onHelp(hwnd, wb, (*win.HELPINFO)(unsafe.Pointer(lParam))) {
 			return win.TRUE
@@ -2487,6 +2562,13 @@ func (wb *WindowBase) WndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr)
 			return window.WndProc(hwnd, msg, wParam, lParam)
 		}
 
+	case win.WM_NOTIFY:
+		nmh := (*win.NMHDR)(unsafe.Pointer(lParam))
+		if tt, ok := windowFromHandle(nmh.HwndFrom).(*ToolTip); ok {
+			// Tool tips notify the tools, which may be any widget.
+			return tt.WndProc(hwnd, msg, wParam, lParam)
+		}
+
 	case win.WM_LBUTTONDOWN, win.WM_MBUTTONDOWN, win.WM_RBUTTONDOWN:
 		if msg == win.WM_LBUTTONDOWN && wb.origWndProcPtr == 0 {
 			// Only call SetCapture if this is no subclassed control.
@@ -2641,19 +2723,14 @@ func (wb *WindowBase) WndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr)
 			}
 		}
 
+	case win.WM_UPDATEUISTATE:
+		return wb.handleUpdateUIState(hwnd, msg, wParam, lParam)
+
 	case win.WM_SETTINGCHANGE, win.WM_THEMECHANGED:
 		// Destroy any cached theme information. The new information will be
 		// reloaded lazily.
 
-		if wb.menuSharedMetricsInitialDPI != nil {
-			dpicache.Delete(wb.menuSharedMetricsInitialDPI)
-			wb.menuSharedMetricsInitialDPI = nil
-		}
-
-		for _, v := range wb.themes {
-			v.close()
-		}
-		clear(wb.themes)
+		wb.discardThemeData()
 
 		wb.window.(ApplySysColorser).ApplySysColors()
 
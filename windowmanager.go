@@ -0,0 +1,446 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+import (
+	"fmt"
+
+	"github.com/tailscale/win"
+)
+
+// managedWindow is a document window tracked by a WindowManager.
+type managedWindow struct {
+	form               Form
+	key                string
+	save               func() error
+	action             *Action
+	activatingHandle   int
+	titleChangedHandle int
+	closingHandle      int
+	disposingHandle    int
+}
+
+// WindowManager tracks the document windows of a multi-window application.
+//
+// It maintains the window lists of any number of "Window" menus, arranges the
+// windows, closes or saves all of them at once and remembers the placement of
+// each document in App().Settings().
+//
+// Windows that are closed are removed from the WindowManager and disposed.
+type WindowManager struct {
+	windows                      []*managedWindow
+	active                       *managedWindow
+	menus                        []*Menu
+	menuActions                  []*Action
+	windowsChangedPublisher      EventPublisher
+	activeWindowChangedPublisher EventPublisher
+	closingAllPublisher          ProceedEventPublisher
+	savingAllPublisher           ProceedEventPublisher
+}
+
+// NewWindowManager returns a new WindowManager without windows.
+func NewWindowManager() *WindowManager {
+	wm := new(WindowManager)
+
+	cascadeAction := NewAction()
+	cascadeAction.SetText(tr("&Cascade", "walk"))
+	cascadeAction.Triggered().Attach(wm.Cascade)
+
+	tileHorizontallyAction := NewAction()
+	tileHorizontallyAction.SetText(tr("Tile &Horizontally", "walk"))
+	tileHorizontallyAction.Triggered().Attach(wm.TileHorizontally)
+
+	tileVerticallyAction := NewAction()
+	tileVerticallyAction.SetText(tr("Tile &Vertically", "walk"))
+	tileVerticallyAction.Triggered().Attach(wm.TileVertically)
+
+	closeAllAction := NewAction()
+	closeAllAction.SetText(tr("C&lose All", "walk"))
+	closeAllAction.Triggered().Attach(func() {
+		wm.CloseAll()
+	})
+
+	wm.menuActions = []*Action{
+		cascadeAction,
+		tileHorizontallyAction,
+		tileVerticallyAction,
+		NewSeparatorAction(),
+		closeAllAction,
+	}
+
+	return wm
+}
+
+// Add starts tracking form as a document window.
+//
+// If key is not empty, it identifies the document shown by form, e.g. its file
+// path, and the placement form had when the document was closed last time is
+// restored. save is called by SaveAll and may be nil.
+func (wm *WindowManager) Add(form Form, key string, save func() error) error {
+	if form == nil {
+		return newError("form cannot be nil")
+	}
+
+	if wm.indexOf(form) >= 0 {
+		return newError("form is managed already")
+	}
+
+	mw := &managedWindow{
+		form: form,
+		key:  key,
+		save: save,
+	}
+
+	if key != "" && App().Settings() != nil {
+		if err := form.AsFormBase().restoreWindowPlacement(wm.placementKey(key)); err != nil {
+			return err
+		}
+	}
+
+	mw.action = NewAction()
+	mw.action.SetCheckable(true)
+	mw.action.Triggered().Attach(func() {
+		activateForeground(form.AsFormBase())
+	})
+
+	mw.activatingHandle = form.Activating().Attach(func() {
+		wm.setActive(mw)
+	})
+	mw.titleChangedHandle = form.TitleChanged().Attach(wm.updateWindowActions)
+	mw.closingHandle = form.Closing().Attach(func(canceled *bool, reason CloseReason) {
+		// Later handlers may still cancel, so check once they all ran.
+		form.Synchronize(func() {
+			wm.checkClosed(mw)
+		})
+	})
+	mw.disposingHandle = form.Disposing().Attach(func() {
+		wm.remove(mw)
+	})
+
+	wm.updateMenus(func() {
+		wm.windows = append(wm.windows, mw)
+	})
+
+	if win.GetActiveWindow() == form.Handle() {
+		wm.setActive(mw)
+	}
+
+	wm.windowsChangedPublisher.Publish()
+
+	return nil
+}
+
+// Remove stops tracking form without closing it.
+func (wm *WindowManager) Remove(form Form) {
+	if i := wm.indexOf(form); i >= 0 {
+		wm.remove(wm.windows[i])
+	}
+}
+
+// Windows returns the managed windows in the order they were added.
+func (wm *WindowManager) Windows() []Form {
+	forms := make([]Form, len(wm.windows))
+	for i, mw := range wm.windows {
+		forms[i] = mw.form
+	}
+
+	return forms
+}
+
+// ActiveWindow returns the managed window that was active most recently, or
+// nil if there is none.
+func (wm *WindowManager) ActiveWindow() Form {
+	if wm.active == nil {
+		return nil
+	}
+
+	return wm.active.form
+}
+
+// WindowsChanged returns the event that is published when a window was added
+// to or removed from the WindowManager.
+func (wm *WindowManager) WindowsChanged() *Event {
+	return wm.windowsChangedPublisher.Event()
+}
+
+// ActiveWindowChanged returns the event that is published when another
+// managed window became active.
+func (wm *WindowManager) ActiveWindowChanged() *Event {
+	return wm.activeWindowChangedPublisher.Event()
+}
+
+// ClosingAll returns the event that is published before CloseAll closes the
+// windows. If a handler returns false, no window is closed.
+func (wm *WindowManager) ClosingAll() *ProceedEvent {
+	return wm.closingAllPublisher.Event()
+}
+
+// SavingAll returns the event that is published before SaveAll saves the
+// documents. If a handler returns false, no document is saved.
+func (wm *WindowManager) SavingAll() *ProceedEvent {
+	return wm.savingAllPublisher.Event()
+}
+
+// AddWindowMenu appends the actions of the WindowManager to menu, which is
+// typically the "Window" menu of each managed window: commands to arrange and
+// close the windows, followed by a list of the windows that activates the
+// chosen one.
+func (wm *WindowManager) AddWindowMenu(menu *Menu) error {
+	for _, m := range wm.menus {
+		if m == menu {
+			return newError("menu is added already")
+		}
+	}
+
+	if err := wm.addActions(menu); err != nil {
+		wm.removeActions(menu)
+		return err
+	}
+
+	wm.menus = append(wm.menus, menu)
+
+	return nil
+}
+
+// RemoveWindowMenu removes the actions of the WindowManager from menu.
+func (wm *WindowManager) RemoveWindowMenu(menu *Menu) {
+	for i, m := range wm.menus {
+		if m == menu {
+			wm.removeActions(menu)
+			wm.menus = append(wm.menus[:i], wm.menus[i+1:]...)
+			return
+		}
+	}
+}
+
+// Cascade arranges the visible managed windows in a cascade, starting at the
+// top left corner of the work area.
+func (wm *WindowManager) Cascade() {
+	cascadeWindows(wm.arrangeableHandles())
+}
+
+// TileHorizontally arranges the visible managed windows one above the other.
+func (wm *WindowManager) TileHorizontally() {
+	tileWindows(_MDITILE_HORIZONTAL, wm.arrangeableHandles())
+}
+
+// TileVertically arranges the visible managed windows side by side.
+func (wm *WindowManager) TileVertically() {
+	tileWindows(_MDITILE_VERTICAL, wm.arrangeableHandles())
+}
+
+// CloseAll closes all managed windows, the most recently added one first, and
+// returns whether all of them were closed. It stops as soon as a handler of
+// ClosingAll or of the Closing event of a window vetoes.
+func (wm *WindowManager) CloseAll() bool {
+	if !wm.closingAllPublisher.Publish() {
+		return false
+	}
+
+	for i := len(wm.windows) - 1; i >= 0; i-- {
+		if i >= len(wm.windows) {
+			continue
+		}
+
+		mw := wm.windows[i]
+
+		mw.form.AsFormBase().Close()
+
+		if !wm.checkClosed(mw) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// SaveAll calls the save funcs passed to Add for all managed windows and
+// returns whether it got to do so. It stops at the first error, which it
+// returns. A handler of SavingAll can veto saving.
+func (wm *WindowManager) SaveAll() (bool, error) {
+	if !wm.savingAllPublisher.Publish() {
+		return false, nil
+	}
+
+	for _, mw := range append([]*managedWindow(nil), wm.windows...) {
+		if mw.save == nil {
+			continue
+		}
+
+		if err := mw.save(); err != nil {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+// checkClosed removes and disposes mw if its form was closed, and returns
+// whether it was.
+func (wm *WindowManager) checkClosed(mw *managedWindow) bool {
+	form := mw.form
+
+	if !form.IsDisposed() && form.AsFormBase().Running() {
+		return false
+	}
+
+	if wm.indexOf(form) < 0 {
+		return true
+	}
+
+	if mw.key != "" && App().Settings() != nil && !form.IsDisposed() {
+		form.AsFormBase().saveWindowPlacement(wm.placementKey(mw.key))
+	}
+
+	wm.remove(mw)
+
+	// The form may be closed from one of its own message handlers, like that
+	// of a menu action, so it is only disposed once that returned.
+	form.SetVisible(false)
+	form.Synchronize(form.Dispose)
+
+	return true
+}
+
+func (wm *WindowManager) remove(mw *managedWindow) {
+	i := wm.indexOf(mw.form)
+	if i < 0 {
+		return
+	}
+
+	form := mw.form
+	form.Activating().Detach(mw.activatingHandle)
+	form.TitleChanged().Detach(mw.titleChangedHandle)
+	form.Closing().Detach(mw.closingHandle)
+	form.Disposing().Detach(mw.disposingHandle)
+
+	wm.updateMenus(func() {
+		wm.windows = append(wm.windows[:i], wm.windows[i+1:]...)
+	})
+
+	if wm.active == mw {
+		wm.active = nil
+		wm.activeWindowChangedPublisher.Publish()
+	}
+
+	wm.windowsChangedPublisher.Publish()
+}
+
+func (wm *WindowManager) setActive(mw *managedWindow) {
+	if wm.active == mw {
+		return
+	}
+
+	wm.active = mw
+
+	wm.updateWindowActions()
+
+	wm.activeWindowChangedPublisher.Publish()
+}
+
+// updateMenus rebuilds the window lists of all menus around change, which
+// modifies the managed windows.
+func (wm *WindowManager) updateMenus(change func()) {
+	for _, menu := range wm.menus {
+		wm.removeActions(menu)
+	}
+
+	change()
+
+	for _, menu := range wm.menus {
+		wm.addActions(menu)
+	}
+
+	wm.updateWindowActions()
+}
+
+func (wm *WindowManager) addActions(menu *Menu) error {
+	actions := menu.Actions()
+
+	for _, action := range wm.menuActions {
+		if err := actions.Add(action); err != nil {
+			return err
+		}
+	}
+
+	if len(wm.windows) > 0 {
+		if err := actions.Add(NewSeparatorAction()); err != nil {
+			return err
+		}
+
+		for _, mw := range wm.windows {
+			if err := actions.Add(mw.action); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// removeActions removes the actions of the WindowManager from menu, including
+// the separator in front of the window list.
+func (wm *WindowManager) removeActions(menu *Menu) {
+	actions := menu.Actions()
+
+	i := actions.Index(wm.menuActions[0])
+	if i < 0 {
+		return
+	}
+
+	n := len(wm.menuActions)
+	if i+n < actions.Len() && actions.At(i+n).IsSeparator() {
+		n += 1 + len(wm.windows)
+	}
+
+	for ; n > 0 && i < actions.Len(); n-- {
+		actions.RemoveAt(i)
+	}
+}
+
+// updateWindowActions updates the text and checked state of the window list.
+func (wm *WindowManager) updateWindowActions() {
+	for i, mw := range wm.windows {
+		text := mw.form.Title()
+		if i < 9 {
+			text = fmt.Sprintf("&%d %s", i+1, text)
+		}
+
+		mw.action.SetText(text)
+		mw.action.SetChecked(mw == wm.active)
+	}
+}
+
+// arrangeableHandles returns the handles of the managed windows that are
+// visible and not minimized.
+func (wm *WindowManager) arrangeableHandles() []win.HWND {
+	var hwnds []win.HWND
+	for _, mw := range wm.windows {
+		if hwnd := mw.form.Handle(); mw.form.Visible() && !win.IsIconic(hwnd) {
+			hwnds = append(hwnds, hwnd)
+		}
+	}
+
+	return hwnds
+}
+
+func (wm *WindowManager) indexOf(form Form) int {
+	for i, mw := range wm.windows {
+		if mw.form == form {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// placementKey returns the settings key the placement of the document
+// identified by key is stored under.
+func (wm *WindowManager) placementKey(key string) string {
+	return "WindowManager/" + key + ":placement"
+}
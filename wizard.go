@@ -0,0 +1,683 @@
+// Copyright (c) Tailscale Inc. and AUTHORS
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package walk
+
+// WizardPage is a page of a Wizard, holding the widgets of one step. It is
+// created with a VBoxLayout.
+type WizardPage struct {
+	*Composite
+	wizard                   *Wizard
+	title                    string
+	description              string
+	complete                 bool
+	nextPageFunc             func() *WizardPage
+	enteredPublisher         EventPublisher
+	validatingPublisher      CancelEventPublisher
+	completeChangedPublisher EventPublisher
+}
+
+func newWizardPage(wizard *Wizard) (*WizardPage, error) {
+	composite, err := NewComposite(wizard.body)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &WizardPage{
+		Composite: composite,
+		wizard:    wizard,
+		complete:  true,
+	}
+
+	succeeded := false
+	defer func() {
+		if !succeeded {
+			page.Dispose()
+		}
+	}()
+
+	if err := InitWrapperWindow(page); err != nil {
+		return nil, err
+	}
+
+	layout := NewVBoxLayout()
+	layout.SetMargins(Margins{})
+	if err := page.SetLayout(layout); err != nil {
+		return nil, err
+	}
+
+	page.SetVisible(false)
+
+	succeeded = true
+
+	return page, nil
+}
+
+// Wizard returns the Wizard the page belongs to.
+func (page *WizardPage) Wizard() *Wizard {
+	return page.wizard
+}
+
+// Title returns the title displayed in the header while the page is current.
+func (page *WizardPage) Title() string {
+	return page.title
+}
+
+func (page *WizardPage) SetTitle(title string) error {
+	page.title = title
+
+	if page == page.wizard.current {
+		return page.wizard.updateHeader()
+	}
+
+	return nil
+}
+
+// Description returns the text displayed below the title while the page is
+// current.
+func (page *WizardPage) Description() string {
+	return page.description
+}
+
+func (page *WizardPage) SetDescription(description string) error {
+	page.description = description
+
+	if page == page.wizard.current {
+		return page.wizard.updateHeader()
+	}
+
+	return nil
+}
+
+// Complete returns whether the user may leave the page with the Next or
+// Finish button. Pages are complete by default.
+func (page *WizardPage) Complete() bool {
+	return page.complete
+}
+
+// SetComplete sets whether the user may leave the page with the Next or
+// Finish button, e.g. to disable them until required input was made.
+func (page *WizardPage) SetComplete(complete bool) {
+	if complete == page.complete {
+		return
+	}
+
+	page.complete = complete
+
+	if page == page.wizard.current {
+		page.wizard.updateButtons()
+	}
+
+	page.completeChangedPublisher.Publish()
+}
+
+// CompleteChanged returns the event that is published when the page became
+// complete or incomplete.
+func (page *WizardPage) CompleteChanged() *Event {
+	return page.completeChangedPublisher.Event()
+}
+
+// SetNextPageFunc sets the function returning the page that follows the page,
+// or nil if the page is the last one, e.g. to skip pages depending on the
+// input made. Without such a function, the page is followed by the one added
+// after it.
+func (page *WizardPage) SetNextPageFunc(f func() *WizardPage) {
+	page.nextPageFunc = f
+
+	if page == page.wizard.current {
+		page.wizard.updateButtons()
+	}
+}
+
+// Entered returns the event that is published when the page became the
+// current one.
+func (page *WizardPage) Entered() *Event {
+	return page.enteredPublisher.Event()
+}
+
+// Validating returns the event that is published before the user leaves the
+// page with the Next or Finish button. Handlers can prevent leaving the page
+// by setting canceled to true.
+func (page *WizardPage) Validating() *CancelEvent {
+	return page.validatingPublisher.Event()
+}
+
+// validate returns whether the page may be left forward. The DataBinder of
+// the page, if any, must be able to submit.
+func (page *WizardPage) validate() bool {
+	if db := page.DataBinder(); db != nil {
+		if !db.CanSubmit() {
+			return false
+		}
+
+		if err := db.Submit(); err != nil {
+			return false
+		}
+	}
+
+	var canceled bool
+	page.validatingPublisher.Publish(&canceled)
+
+	return !canceled
+}
+
+// Wizard is a Dialog guiding the user through a sequence of pages with Back,
+// Next, Finish and Cancel buttons. A header displays the title and the
+// description of the current page, along with an optional glyph.
+//
+// Run returns DlgCmdOK if the user finished the Wizard and DlgCmdCancel
+// otherwise.
+type Wizard struct {
+	*Dialog
+	header                      *Composite
+	titleLabel                  *Label
+	descriptionLabel            *TextLabel
+	glyphView                   *ImageView
+	headerSeparator             *Separator
+	body                        *Composite
+	backButton                  *PushButton
+	nextButton                  *PushButton
+	finishButton                *PushButton
+	cancelButton                *PushButton
+	pages                       []*WizardPage
+	history                     []*WizardPage
+	current                     *WizardPage
+	taskDialogStyle             bool
+	currentPageChangedPublisher EventPublisher
+}
+
+// NewWizard creates a new Wizard without pages, owned by owner.
+func NewWizard(owner Form) (*Wizard, error) {
+	dlg, err := NewDialog(owner)
+	if err != nil {
+		return nil, err
+	}
+
+	wz := &Wizard{Dialog: dlg}
+
+	succeeded := false
+	defer func() {
+		if !succeeded {
+			wz.Dispose()
+		}
+	}()
+
+	if err := InitWrapperWindow(wz); err != nil {
+		return nil, err
+	}
+
+	layout := NewVBoxLayout()
+	layout.SetMargins(Margins{})
+	layout.SetSpacing(0)
+	if err := wz.SetLayout(layout); err != nil {
+		return nil, err
+	}
+
+	if err := wz.createHeader(); err != nil {
+		return nil, err
+	}
+
+	if wz.headerSeparator, err = NewHSeparator(wz); err != nil {
+		return nil, err
+	}
+
+	if wz.body, err = NewComposite(wz); err != nil {
+		return nil, err
+	}
+	wz.body.name = "body"
+	if err := wz.body.SetLayout(newWizardPageLayout()); err != nil {
+		return nil, err
+	}
+
+	if _, err := NewHSeparator(wz); err != nil {
+		return nil, err
+	}
+
+	if err := wz.createButtons(); err != nil {
+		return nil, err
+	}
+
+	if err := wz.applyStyle(); err != nil {
+		return nil, err
+	}
+
+	wz.updateButtons()
+
+	succeeded = true
+
+	return wz, nil
+}
+
+func (wz *Wizard) createHeader() error {
+	var err error
+
+	if wz.header, err = NewComposite(wz); err != nil {
+		return err
+	}
+	wz.header.name = "header"
+
+	headerLayout := NewHBoxLayout()
+	headerLayout.SetMargins(Margins{12, 9, 12, 9})
+	if err := wz.header.SetLayout(headerLayout); err != nil {
+		return err
+	}
+
+	texts, err := NewComposite(wz.header)
+	if err != nil {
+		return err
+	}
+
+	textsLayout := NewVBoxLayout()
+	textsLayout.SetMargins(Margins{})
+	textsLayout.SetSpacing(2)
+	if err := texts.SetLayout(textsLayout); err != nil {
+		return err
+	}
+
+	if wz.titleLabel, err = NewLabel(texts); err != nil {
+		return err
+	}
+
+	if wz.descriptionLabel, err = NewTextLabel(texts); err != nil {
+		return err
+	}
+
+	if wz.glyphView, err = NewImageView(wz.header); err != nil {
+		return err
+	}
+	wz.glyphView.SetVisible(false)
+
+	return nil
+}
+
+func (wz *Wizard) createButtons() error {
+	buttons, err := NewComposite(wz)
+	if err != nil {
+		return err
+	}
+	buttons.name = "buttons"
+
+	if err := buttons.SetLayout(NewHBoxLayout()); err != nil {
+		return err
+	}
+
+	if _, err := NewHSpacer(buttons); err != nil {
+		return err
+	}
+
+	newButton := func(text string, handler func()) (*PushButton, error) {
+		pb, err := NewPushButton(buttons)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := pb.SetText(text); err != nil {
+			return nil, err
+		}
+
+		pb.Clicked().Attach(handler)
+
+		return pb, nil
+	}
+
+	if wz.backButton, err = newButton(tr("< &Back", "walk"), func() { wz.Back() }); err != nil {
+		return err
+	}
+
+	if wz.nextButton, err = newButton(tr("&Next >", "walk"), func() { wz.Next() }); err != nil {
+		return err
+	}
+
+	if wz.finishButton, err = newButton(tr("&Finish", "walk"), func() { wz.Finish() }); err != nil {
+		return err
+	}
+
+	if wz.cancelButton, err = newButton(tr("Cancel", "walk"), func() { wz.Cancel() }); err != nil {
+		return err
+	}
+
+	return wz.SetCancelButton(wz.cancelButton)
+}
+
+// AddPage adds a new page with title to the end of the pages.
+func (wz *Wizard) AddPage(title string) (*WizardPage, error) {
+	page, err := newWizardPage(wz)
+	if err != nil {
+		return nil, err
+	}
+
+	page.title = title
+
+	wz.pages = append(wz.pages, page)
+
+	if wz.current != nil {
+		wz.updateButtons()
+	}
+
+	return page, nil
+}
+
+// Pages returns the pages in the order they were added.
+func (wz *Wizard) Pages() []*WizardPage {
+	return wz.pages
+}
+
+// CurrentPage returns the page displayed, or nil before the Wizard is run.
+func (wz *Wizard) CurrentPage() *WizardPage {
+	return wz.current
+}
+
+// CurrentPageChanged returns the event that is published when another page
+// became the current one.
+func (wz *Wizard) CurrentPageChanged() *Event {
+	return wz.currentPageChangedPublisher.Event()
+}
+
+// BackButton returns the button navigating to the previous page.
+func (wz *Wizard) BackButton() *PushButton {
+	return wz.backButton
+}
+
+// NextButton returns the button navigating to the next page.
+func (wz *Wizard) NextButton() *PushButton {
+	return wz.nextButton
+}
+
+// FinishButton returns the button finishing the Wizard on the last page.
+func (wz *Wizard) FinishButton() *PushButton {
+	return wz.finishButton
+}
+
+// Glyph returns the image displayed in the header, or nil.
+func (wz *Wizard) Glyph() Image {
+	return wz.glyphView.Image()
+}
+
+// SetGlyph sets the image displayed in the header. nil removes it.
+func (wz *Wizard) SetGlyph(glyph Image) error {
+	if err := wz.glyphView.SetImage(glyph); err != nil {
+		return err
+	}
+
+	wz.glyphView.SetVisible(glyph != nil)
+
+	return nil
+}
+
+// TaskDialogStyle returns whether the Wizard is styled like a task dialog,
+// with the header and the pages on the window color and a large title.
+func (wz *Wizard) TaskDialogStyle() bool {
+	return wz.taskDialogStyle
+}
+
+// SetTaskDialogStyle sets whether the Wizard is styled like a task dialog,
+// with the header and the pages on the window color and a large title, like
+// the Aero wizards of Windows.
+func (wz *Wizard) SetTaskDialogStyle(taskDialogStyle bool) error {
+	if taskDialogStyle == wz.taskDialogStyle {
+		return nil
+	}
+
+	wz.taskDialogStyle = taskDialogStyle
+
+	return wz.applyStyle()
+}
+
+func (wz *Wizard) applyStyle() error {
+	windowBrush, err := NewSystemColorBrush(SysColorWindow)
+	if err != nil {
+		return err
+	}
+
+	wz.header.SetBackground(windowBrush)
+	wz.headerSeparator.SetVisible(!wz.taskDialogStyle)
+
+	font := wz.Font()
+	if wz.taskDialogStyle {
+		wz.body.SetBackground(windowBrush)
+
+		title, err := NewFont(font.Family(), 12, font.Style())
+		if err != nil {
+			return err
+		}
+		wz.titleLabel.SetFont(title)
+		wz.titleLabel.SetTextColor(RGB(0x00, 0x33, 0x99))
+	} else {
+		wz.body.SetBackground(NullBrush())
+
+		title, err := NewFont(font.Family(), font.PointSize(), font.Style()|FontBold)
+		if err != nil {
+			return err
+		}
+		wz.titleLabel.SetFont(title)
+		wz.titleLabel.SetTextColor(Color(0))
+	}
+
+	wz.Invalidate()
+
+	return nil
+}
+
+func (wz *Wizard) updateHeader() error {
+	var title, description string
+	if wz.current != nil {
+		title, description = wz.current.title, wz.current.description
+	}
+
+	if err := wz.titleLabel.SetText(title); err != nil {
+		return err
+	}
+
+	if err := wz.descriptionLabel.SetText(description); err != nil {
+		return err
+	}
+
+	wz.descriptionLabel.SetVisible(description != "")
+
+	return nil
+}
+
+// nextPage returns the page following page, or nil if it is the last one.
+func (wz *Wizard) nextPage(page *WizardPage) *WizardPage {
+	if page.nextPageFunc != nil {
+		return page.nextPageFunc()
+	}
+
+	for i, p := range wz.pages {
+		if p == page && i+1 < len(wz.pages) {
+			return wz.pages[i+1]
+		}
+	}
+
+	return nil
+}
+
+func (wz *Wizard) updateButtons() {
+	hasNext := wz.current != nil && wz.nextPage(wz.current) != nil
+	complete := wz.current != nil && wz.current.complete
+
+	wz.backButton.SetEnabled(len(wz.history) > 0)
+	wz.nextButton.SetVisible(hasNext)
+	wz.nextButton.SetEnabled(complete)
+	wz.finishButton.SetVisible(!hasNext)
+	wz.finishButton.SetEnabled(complete)
+
+	if hasNext {
+		wz.SetDefaultButton(wz.nextButton)
+	} else {
+		wz.SetDefaultButton(wz.finishButton)
+	}
+}
+
+func (wz *Wizard) setCurrentPage(page *WizardPage) {
+	if wz.current != nil {
+		wz.current.SetVisible(false)
+	}
+
+	wz.current = page
+	page.SetVisible(true)
+
+	wz.updateHeader()
+	wz.updateButtons()
+
+	// Focus the first widget of the page, like a dialog does when shown.
+	if focusable := firstFocusableDescendant(page); focusable != nil {
+		focusable.SetFocus()
+	}
+
+	page.enteredPublisher.Publish()
+	wz.currentPageChangedPublisher.Publish()
+}
+
+// Back returns to the page that was current before the current one.
+func (wz *Wizard) Back() {
+	if len(wz.history) == 0 {
+		return
+	}
+
+	page := wz.history[len(wz.history)-1]
+	wz.history = wz.history[:len(wz.history)-1]
+
+	wz.setCurrentPage(page)
+}
+
+// Next validates the current page and makes the page following it the
+// current one. It returns whether it did.
+func (wz *Wizard) Next() bool {
+	if wz.current == nil || !wz.current.complete {
+		return false
+	}
+
+	next := wz.nextPage(wz.current)
+	if next == nil || !wz.current.validate() {
+		return false
+	}
+
+	wz.history = append(wz.history, wz.current)
+	wz.setCurrentPage(next)
+
+	return true
+}
+
+// Finish validates the current page and closes the Wizard with DlgCmdOK. It
+// returns whether it did.
+func (wz *Wizard) Finish() bool {
+	if wz.current == nil || !wz.current.complete || !wz.current.validate() {
+		return false
+	}
+
+	wz.Accept()
+
+	return true
+}
+
+// Run displays the first page and runs the Wizard modally.
+func (wz *Wizard) Run() int {
+	if wz.current == nil && len(wz.pages) > 0 {
+		wz.setCurrentPage(wz.pages[0])
+	}
+
+	return wz.Dialog.Run()
+}
+
+// wizardPageLayout lays out all pages of a Wizard on top of each other, so the
+// size of the Wizard does not change while navigating.
+type wizardPageLayout struct {
+	LayoutBase
+}
+
+func newWizardPageLayout() *wizardPageLayout {
+	l := &wizardPageLayout{
+		LayoutBase: LayoutBase{
+			margins96dpi: Margins{12, 12, 12, 12},
+		},
+	}
+	l.layout = l
+
+	return l
+}
+
+func (l *wizardPageLayout) CreateLayoutItem(ctx *LayoutContext) ContainerLayoutItem {
+	return new(wizardPageLayoutItem)
+}
+
+type wizardPageLayoutItem struct {
+	ContainerLayoutItemBase
+}
+
+func (li *wizardPageLayoutItem) LayoutFlags() LayoutFlags {
+	flags := GrowableHorz | GrowableVert | ShrinkableHorz | ShrinkableVert
+	for _, page := range li.children {
+		flags |= page.LayoutFlags()
+	}
+
+	return flags
+}
+
+func (li *wizardPageLayoutItem) grow(size Size) Size {
+	margins := MarginsFrom96DPI(li.margins96dpi, li.ctx.dpi)
+
+	return Size{size.Width + margins.HNear + margins.HFar, size.Height + margins.VNear + margins.VFar}
+}
+
+func (li *wizardPageLayoutItem) MinSize() Size {
+	var min Size
+	for _, page := range li.children {
+		min = maxSize(min, minSizeEffective(page))
+	}
+
+	return li.grow(min)
+}
+
+func (li *wizardPageLayoutItem) MinSizeForSize(size Size) Size {
+	return li.MinSize()
+}
+
+func (li *wizardPageLayoutItem) IdealSize() Size {
+	var ideal Size
+	for _, page := range li.children {
+		if is, ok := page.(IdealSizer); ok {
+			ideal = maxSize(ideal, is.IdealSize())
+		}
+	}
+
+	return maxSize(li.grow(ideal), li.MinSize())
+}
+
+func (li *wizardPageLayoutItem) HeightForWidth(width int) int {
+	margins := MarginsFrom96DPI(li.margins96dpi, li.ctx.dpi)
+
+	var height int
+	for _, page := range li.children {
+		if hfw, ok := page.(HeightForWidther); ok && hfw.HasHeightForWidth() {
+			height = maxi(height, hfw.HeightForWidth(width-margins.HNear-margins.HFar))
+		} else {
+			height = maxi(height, minSizeEffective(page).Height)
+		}
+	}
+
+	return height + margins.VNear + margins.VFar
+}
+
+func (li *wizardPageLayoutItem) PerformLayout() []LayoutResultItem {
+	margins := MarginsFrom96DPI(li.margins96dpi, li.ctx.dpi)
+	size := li.geometry.ClientSize
+
+	bounds := Rectangle{
+		X:      margins.HNear,
+		Y:      margins.VNear,
+		Width:  size.Width - margins.HNear - margins.HFar,
+		Height: size.Height - margins.VNear - margins.VFar,
+	}
+
+	items := make([]LayoutResultItem, len(li.children))
+	for i, page := range li.children {
+		items[i] = LayoutResultItem{Item: page, Bounds: bounds}
+	}
+
+	return items
+}